@@ -0,0 +1,193 @@
+package wo
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// MediaRange is a media range of the Accept header.
+//
+// See: https://www.rfc-editor.org/rfc/rfc9110#section-12.5.1
+type MediaRange struct {
+	// Type is the lower-case type, "*" for any.
+	Type string
+
+	// Subtype is the lower-case subtype, "*" for any.
+	Subtype string
+
+	// Params are the media type parameters (the keys are lower-case), except the q-value.
+	Params map[string]string
+
+	// Q is the quality value (weight) within [0, 1].
+	Q float64
+}
+
+// MediaType returns the media range without parameters, ex. "text/html" or "text/*".
+func (m MediaRange) MediaType() string {
+	return m.Type + "/" + m.Subtype
+}
+
+func (m MediaRange) specificity() int {
+	switch {
+	case m.Type == "*":
+		return 0
+	case m.Subtype == "*":
+		return 1
+	default:
+		return 2 + len(m.Params)
+	}
+}
+
+// match reports whether the media range matches the offered media type. The wildcards
+// of both are honored, the parameters of the media range must be present in the offer.
+func (m MediaRange) match(offer MediaRange) bool {
+	if m.Type != "*" && offer.Type != "*" && m.Type != offer.Type {
+		return false
+	}
+	if m.Subtype != "*" && offer.Subtype != "*" && m.Subtype != offer.Subtype {
+		return false
+	}
+	for k, v := range m.Params {
+		if ov, ok := offer.Params[k]; !ok || !strings.EqualFold(ov, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseAccept parses the Accept header into media ranges ordered by preference: by q-value,
+// then by specificity (`text/html;level=1` > `text/html` > `text/*` > `*/*`), then by the header
+// order. The ranges with q=0 (not acceptable) are kept, since they exclude the less specific
+// ranges, see [NegotiateMediaType]. Malformed ranges are skipped.
+func ParseAccept(header string) []MediaRange {
+	elements := splitQuoted(header, ',')
+	ranges := make([]MediaRange, 0, len(elements))
+
+	for _, element := range elements {
+		if r, ok := parseMediaRange(element); ok {
+			ranges = append(ranges, r)
+		}
+	}
+
+	slices.SortStableFunc(ranges, func(a, b MediaRange) int {
+		if c := cmp.Compare(b.Q, a.Q); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.specificity(), a.specificity())
+	})
+
+	return ranges
+}
+
+func parseMediaRange(s string) (MediaRange, bool) {
+	parts := splitQuoted(s, ';')
+
+	mediaType := strings.ToLower(strings.TrimSpace(parts[0]))
+	if mediaType == "*" {
+		mediaType = "*/*"
+	}
+
+	typ, subtype, ok := strings.Cut(mediaType, "/")
+	if !ok || typ == "" || subtype == "" || (typ == "*" && subtype != "*") {
+		return MediaRange{}, false
+	}
+
+	r := MediaRange{Type: typ, Subtype: subtype, Q: 1}
+
+	qSeen := false
+	for _, param := range parts[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		if name == "q" {
+			// the parameters after the weight are accept extensions
+			if !qSeen {
+				r.Q = parseQuality(param)
+				qSeen = true
+			}
+			continue
+		}
+		if qSeen {
+			continue
+		}
+		if r.Params == nil {
+			r.Params = make(map[string]string)
+		}
+		r.Params[name] = value
+	}
+
+	return r, true
+}
+
+// NegotiateMediaType returns the offered media type which is the most acceptable according
+// to the media ranges (see [ParseAccept]) or an empty string if none is acceptable. The quality
+// of an offer is the one of the most specific matching media range; among the offers of equal
+// quality the one matching the preferred media range wins, then the first offered.
+// If there are no media ranges, the first offer is returned.
+func NegotiateMediaType(ranges []MediaRange, offered ...string) string {
+	if len(offered) == 0 {
+		panic("negotiateFormat: you must provide at least one offer")
+	}
+
+	if len(ranges) == 0 {
+		return offered[0]
+	}
+
+	var (
+		best      string
+		bestQ     float64
+		bestIndex = len(ranges)
+	)
+
+	for _, offer := range offered {
+		o, ok := parseMediaRange(offer)
+		if !ok {
+			continue
+		}
+
+		index, specificity := -1, -1
+		for i, r := range ranges {
+			if s := r.specificity(); s > specificity && r.match(o) {
+				index, specificity = i, s
+			}
+		}
+		if index < 0 {
+			continue
+		}
+
+		if q := ranges[index].Q; q > bestQ || (q == bestQ && q > 0 && index < bestIndex) {
+			best, bestQ, bestIndex = offer, q, index
+		}
+	}
+	return best
+}
+
+// splitQuoted splits s by sep outside of quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var (
+		parts  []string
+		start  int
+		quoted bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,86 @@
+package wo
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccept(t *testing.T) {
+	ranges := ParseAccept(`text/*;q=0.3, text/html;q=0.7, TEXT/HTML;Level=1, text/html;level=2;q=0.4, */*;q=0.5, application/json;q=0.5;ext="a,b", invalid, */json`)
+
+	require.Len(t, ranges, 6)
+
+	assert.Equal(t, MediaRange{Type: "text", Subtype: "html", Params: map[string]string{"level": "1"}, Q: 1}, ranges[0])
+	assert.Equal(t, "text/html", ranges[1].MediaType())
+	assert.InDelta(t, 0.7, ranges[1].Q, 0)
+	assert.Equal(t, "application/json", ranges[2].MediaType())
+	assert.Empty(t, ranges[2].Params, "accept extensions are not media type parameters")
+	assert.Equal(t, "*/*", ranges[3].MediaType())
+	assert.Equal(t, "text/html", ranges[4].MediaType())
+	assert.Equal(t, "text/*", ranges[5].MediaType())
+
+	assert.Empty(t, ParseAccept(""))
+	assert.Equal(t, "*/*", ParseAccept("*")[0].MediaType())
+}
+
+func TestNegotiateMediaType(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		offered  []string
+		expected string
+	}{
+		{"no accept", "", []string{MIMEApplicationJSON, MIMETextHTML}, MIMEApplicationJSON},
+		{"quality", "application/json;q=0.5, text/html", []string{MIMEApplicationJSON, MIMETextHTML}, MIMETextHTML},
+		{"header order on equal quality", "text/html, application/json", []string{MIMEApplicationJSON, MIMETextHTML}, MIMETextHTML},
+		{"offer order on equal range", "*/*", []string{MIMEApplicationJSON, MIMETextHTML}, MIMEApplicationJSON},
+		{"subtype wildcard", "text/*, */*;q=0.1", []string{MIMEApplicationJSON, MIMETextPlain}, MIMETextPlain},
+		{"not acceptable", "text/html;q=0, */*", []string{MIMETextHTML}, ""},
+		{"more specific exclusion", "text/*;q=0, text/plain", []string{MIMETextHTML, MIMETextPlain}, MIMETextPlain},
+		{"offer parameters", "text/html", []string{MIMETextHTMLCharsetUTF8}, MIMETextHTMLCharsetUTF8},
+		{"range parameters", "text/html;level=1, application/json;q=0.5", []string{MIMETextHTML, MIMEApplicationJSON}, MIMEApplicationJSON},
+		{"range parameters match", "text/html;level=1, application/json;q=0.5", []string{"text/html;level=1", MIMEApplicationJSON}, "text/html;level=1"},
+		{"no match", "image/png", []string{MIMEApplicationJSON}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NegotiateMediaType(ParseAccept(tt.accept), tt.offered...))
+		})
+	}
+
+	assert.Panics(t, func() {
+		NegotiateMediaType(nil)
+	})
+}
+
+func FuzzAcceptHeader(f *testing.F) {
+	f.Add(`text/*;q=0.3, text/html;q=0.7, TEXT/HTML;Level=1, */*;q=0.5`, `en-US,en;q=0.9,*;q=0.1`)
+	f.Add(`application/json;q=0.5;ext="a,b", invalid, */json`, `fr-CH, fr;q=0.9, de;q=0.7`)
+	f.Add(`*;q=NaN, text/plain;q=1e400, a/b;q=-1, "x/y"`, `;q=NaN, ,en;q=Inf`)
+
+	offers := []string{MIMEApplicationJSON, MIMETextHTMLCharsetUTF8, MIMETextPlain, "image/*"}
+	languages := []string{"en", "en-US", "fr", "de"}
+
+	f.Fuzz(func(t *testing.T, accept, acceptLanguage string) {
+		for _, r := range ParseAccept(accept) {
+			if r.Type == "" || r.Subtype == "" {
+				t.Fatalf("empty media range %#v of %q", r, accept)
+			}
+			if !(r.Q >= 0 && r.Q <= 1) {
+				t.Fatalf("q-value %v of %q is out of range", r.Q, accept)
+			}
+		}
+
+		if offer := NegotiateMediaType(ParseAccept(accept), offers...); offer != "" && !slices.Contains(offers, offer) {
+			t.Fatalf("negotiated %q is not offered", offer)
+		}
+
+		if lang := AcceptLanguageBest(ParseAcceptLanguageHeader(acceptLanguage), languages...); lang != "" && !slices.Contains(languages, lang) {
+			t.Fatalf("negotiated language %q is not offered", lang)
+		}
+	})
+}
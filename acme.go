@@ -0,0 +1,157 @@
+package wo
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// ACMEHTTP01Prefix is the path prefix of the ACME HTTP-01 challenge requests, RFC 8555 section 8.3.
+	ACMEHTTP01Prefix = "/.well-known/acme-challenge/"
+
+	// ACMETLSALPNProto is the ALPN protocol of the ACME TLS-ALPN-01 challenge handshakes, RFC 8737.
+	ACMETLSALPNProto = "acme-tls/1"
+)
+
+// ACMEChallenges holds the pending ACME challenges, so an external certificate manager
+// (ex. lego or autocert) solves them through the application server: the HTTP-01 key
+// authorizations are served by the router, see [Router.SetACMEChallenges], and the
+// TLS-ALPN-01 certificates by the TLS config, see [ACMEChallenges.ConfigureTLS].
+//
+// The challenges are registered and unregistered at runtime, the router isn't rebuilt.
+// The zero value is ready to use.
+//
+//	acme := wo.NewACMEChallenges()
+//	r.SetACMEChallenges(acme)
+//	acme.ConfigureTLS(srv.TLSConfig)
+//
+//	// on the challenge presented by the certificate manager
+//	acme.RegisterHTTP01(token, keyAuth)
+//	defer acme.UnregisterHTTP01(token)
+type ACMEChallenges struct {
+	mu     sync.RWMutex
+	tokens map[string]string
+	certs  map[string]*tls.Certificate
+}
+
+func NewACMEChallenges() *ACMEChallenges {
+	return &ACMEChallenges{
+		tokens: make(map[string]string),
+		certs:  make(map[string]*tls.Certificate),
+	}
+}
+
+// RegisterHTTP01 serves the key authorization at "/.well-known/acme-challenge/{token}".
+func (c *ACMEChallenges) RegisterHTTP01(token, keyAuth string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tokens == nil {
+		c.tokens = make(map[string]string)
+	}
+	c.tokens[token] = keyAuth
+}
+
+// UnregisterHTTP01 removes the HTTP-01 challenge of the token.
+func (c *ACMEChallenges) UnregisterHTTP01(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tokens, token)
+}
+
+// RegisterTLSALPN01 serves the challenge certificate to the "acme-tls/1" handshakes
+// of the domain, the certificate carries the acmeIdentifier extension, RFC 8737 section 3.
+func (c *ACMEChallenges) RegisterTLSALPN01(domain string, cert *tls.Certificate) {
+	if cert == nil {
+		panic("acme: the challenge certificate is nil")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.certs == nil {
+		c.certs = make(map[string]*tls.Certificate)
+	}
+	c.certs[strings.ToLower(domain)] = cert
+}
+
+// UnregisterTLSALPN01 removes the TLS-ALPN-01 challenge of the domain.
+func (c *ACMEChallenges) UnregisterTLSALPN01(domain string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.certs, strings.ToLower(domain))
+}
+
+// GetCertificate returns the tls.Config.GetCertificate serving the TLS-ALPN-01 challenge
+// certificates to the "acme-tls/1" handshakes and calling next otherwise, the certificates
+// of the config are used if next is nil.
+func (c *ACMEChallenges) GetCertificate(next func(*tls.ClientHelloInfo) (*tls.Certificate, error)) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if slices.Contains(hello.SupportedProtos, ACMETLSALPNProto) {
+			c.mu.RLock()
+			cert := c.certs[strings.ToLower(hello.ServerName)]
+			c.mu.RUnlock()
+
+			if cert == nil {
+				return nil, fmt.Errorf("acme: no tls-alpn-01 challenge for %q", hello.ServerName)
+			}
+			return cert, nil
+		}
+
+		if next == nil {
+			return nil, nil
+		}
+		return next(hello)
+	}
+}
+
+// ConfigureTLS wraps the GetCertificate of the config, see [ACMEChallenges.GetCertificate],
+// and adds the "acme-tls/1" protocol to its NextProtos.
+func (c *ACMEChallenges) ConfigureTLS(cfg *tls.Config) {
+	cfg.GetCertificate = c.GetCertificate(cfg.GetCertificate)
+	if !slices.Contains(cfg.NextProtos, ACMETLSALPNProto) {
+		cfg.NextProtos = append(cfg.NextProtos, ACMETLSALPNProto)
+	}
+}
+
+// serveHTTP01 writes the key authorization of the HTTP-01 challenge request,
+// it reports whether the request is the registered challenge.
+func (c *ACMEChallenges) serveHTTP01(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	token, ok := strings.CutPrefix(r.URL.Path, ACMEHTTP01Prefix)
+	if !ok || token == "" {
+		return false
+	}
+
+	c.mu.RLock()
+	keyAuth, ok := c.tokens[token]
+	c.mu.RUnlock()
+
+	if !ok {
+		return false
+	}
+
+	w.Header().Set(HeaderContentType, MIMETextPlain)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte(keyAuth))
+	}
+	return true
+}
+
+// SetACMEChallenges serves the HTTP-01 challenges of the [ACMEChallenges] before the
+// middlewares, so the redirects, the authentication or the host checks don't break
+// the validation, it must be called before [Router.Build]. The requests of the
+// unregistered tokens are routed as usual.
+func (r *Router[T]) SetACMEChallenges(c *ACMEChallenges) {
+	r.acme = c
+}
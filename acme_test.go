@@ -0,0 +1,117 @@
+package wo
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_SetACMEChallenges(t *testing.T) {
+	acme := NewACMEChallenges()
+
+	r := New[*Event](func(w http.ResponseWriter, r *http.Request) (*Event, EventCleanupFunc) {
+		e := new(Event)
+		e.Reset(w, r)
+		return e, nil
+	}, ErrorHandler[*Event](nil, nil, nil))
+	r.SetACMEChallenges(acme)
+	r.PreFunc(func(*Event) error {
+		return ErrUnauthorized
+	})
+
+	h, err := r.Build(nil)
+	require.NoError(t, err)
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/.well-known/acme-challenge/token").Code)
+
+	acme.RegisterHTTP01("token", "token.thumbprint")
+
+	rec := serve(http.MethodGet, "/.well-known/acme-challenge/token")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, MIMETextPlain, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, "token.thumbprint", rec.Body.String())
+
+	rec = serve(http.MethodHead, "/.well-known/acme-challenge/token")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/.well-known/acme-challenge/token").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/.well-known/acme-challenge/other").Code)
+
+	acme.UnregisterHTTP01("token")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/.well-known/acme-challenge/token").Code)
+}
+
+func TestACMEChallenges_ConfigureTLS(t *testing.T) {
+	acme := NewACMEChallenges()
+	challenge, fallback := new(tls.Certificate), new(tls.Certificate)
+
+	cfg := &tls.Config{
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return fallback, nil
+		},
+	}
+	acme.ConfigureTLS(cfg)
+	assert.Equal(t, []string{"h2", "http/1.1", ACMETLSALPNProto}, cfg.NextProtos)
+
+	alpn := &tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{ACMETLSALPNProto}}
+
+	_, err := cfg.GetCertificate(alpn)
+	assert.Error(t, err)
+
+	acme.RegisterTLSALPN01("Example.com", challenge)
+
+	cert, err := cfg.GetCertificate(alpn)
+	require.NoError(t, err)
+	assert.Same(t, challenge, cert)
+
+	cert, err = cfg.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{"h2"}})
+	require.NoError(t, err)
+	assert.Same(t, fallback, cert)
+
+	acme.UnregisterTLSALPN01("example.com")
+	_, err = cfg.GetCertificate(alpn)
+	assert.Error(t, err)
+
+	cert, err = acme.GetCertificate(nil)(&tls.ClientHelloInfo{ServerName: "example.com"})
+	assert.NoError(t, err)
+	assert.Nil(t, cert)
+}
+
+func TestACMEChallenges_Zero(t *testing.T) {
+	var acme ACMEChallenges
+
+	serve := func() (*httptest.ResponseRecorder, bool) {
+		rec := httptest.NewRecorder()
+		ok := acme.serveHTTP01(rec, httptest.NewRequest(http.MethodGet, "/.well-known/acme-challenge/token", nil))
+		return rec, ok
+	}
+
+	_, ok := serve()
+	assert.False(t, ok)
+	acme.UnregisterHTTP01("token")
+	acme.UnregisterTLSALPN01("example.com")
+
+	acme.RegisterHTTP01("token", "token.thumbprint")
+	rec, ok := serve()
+	require.True(t, ok)
+	assert.Equal(t, "token.thumbprint", rec.Body.String())
+
+	challenge := new(tls.Certificate)
+	acme.RegisterTLSALPN01("example.com", challenge)
+
+	cert, err := acme.GetCertificate(nil)(&tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{ACMETLSALPNProto}})
+	require.NoError(t, err)
+	assert.Same(t, challenge, cert)
+}
@@ -0,0 +1,351 @@
+// Package admin mounts the runtime introspection pages on the router: the route table, the
+// middleware stacks, the active sessions count, the rate limit states, the health checks and
+// the recently reported errors, as JSON or, for the browsers, as the minimal HTML pages:
+//
+//	recent := admin.NewRecentErrors(100)
+//	reporter := report.Multi(webhook, recent)
+//
+//	admin.MountAdmin(r.Group("/_"), admin.Config[*wo.Event]{
+//		Router: r,
+//		Errors: recent,
+//		Checks: map[string]admin.Check{"db": db.PingContext},
+//		Allow:  isStaff,
+//	}, basicAuth)
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo"
+)
+
+// Check is the health check, ex. the database ping.
+type Check func(ctx context.Context) error
+
+// RouteSource provides the route table and the middleware stacks, ex. [wo.Router].
+type RouteSource interface {
+	Routes() []wo.RouteInfo
+	Stacks() []string
+}
+
+// RateLimiter is the rate limiter whose state of the identifier is inspected, ex. middleware.RateLimit.
+type RateLimiter interface {
+	Remaining(ctx context.Context, id string) (int, error)
+	ResetAt(ctx context.Context, id string) (time.Time, error)
+}
+
+type Config[T wo.Resolver] struct {
+	// Prefix is the path prefix of the pages within the group.
+	//
+	// Default: "/admin"
+	Prefix string `env:"PREFIX" json:"prefix,omitempty" yaml:"prefix,omitempty"`
+
+	// Allow reports whether the request may access the pages, the denied requests
+	// result in [wo.ErrNotFound], so the pages are not disclosed.
+	//
+	// Default: nil (only the debug requests, see [wo.Debug])
+	Allow func(T) bool `json:"-" yaml:"-"`
+
+	// Router provides the route table and the middleware stacks, the router the pages
+	// are mounted on usually. The routes are the ones of its last build.
+	Router RouteSource `json:"-" yaml:"-"`
+
+	// Sessions returns the number of the active sessions, ex. of session.MemoryStore.Stats.
+	Sessions func(ctx context.Context) (int64, error) `json:"-" yaml:"-"`
+
+	// RateLimiters are the rate limiters by their names, the states of the identifier
+	// of the "id" query parameter are shown.
+	RateLimiters map[string]RateLimiter `json:"-" yaml:"-"`
+
+	// Checks are the health checks by their names, run concurrently.
+	Checks map[string]Check `json:"-" yaml:"-"`
+
+	// CheckTimeout is the timeout of the health checks.
+	//
+	// Default: 5s
+	CheckTimeout time.Duration `env:"CHECK_TIMEOUT" json:"checkTimeout,omitempty,format:units" yaml:"checkTimeout,omitempty"`
+
+	// Errors keeps the recently reported errors.
+	Errors *RecentErrors `json:"-" yaml:"-"`
+}
+
+func (c *Config[T]) SetDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "/admin"
+	}
+	if c.Allow == nil {
+		c.Allow = func(e T) bool {
+			return wo.Debug(e.Request().Context())
+		}
+	}
+	if c.CheckTimeout == 0 {
+		c.CheckTimeout = 5 * time.Second
+	}
+}
+
+// MountAdmin registers the admin pages under the prefix: the index, "/routes", "/stacks",
+// "/sessions", "/ratelimits", "/health" and "/errors". The pages of the sources which aren't
+// configured aren't registered. The middlewares, ex. an authentication, are bound to the
+// returned group before the Allow check.
+func MountAdmin[T wo.Resolver](group *wo.RouterGroup[T], cfg Config[T], middlewares ...func(T) error) *wo.RouterGroup[T] {
+	if group == nil {
+		panic("admin: the provided router group is nil")
+	}
+
+	cfg.SetDefaults()
+
+	// the middlewares run first, so Allow sees the authenticated request
+	g := group.Group(cfg.Prefix)
+	g.BindFunc(middlewares...)
+	g.BindFunc(func(e T) error {
+		if !cfg.Allow(e) {
+			return wo.ErrNotFound
+		}
+		return e.Next()
+	})
+
+	pages := make([]string, 0, 6)
+	page := func(name string, handler func(e T) error) {
+		pages = append(pages, name)
+		g.GET("/"+name, handler)
+	}
+
+	if cfg.Router != nil {
+		page("routes", func(e T) error {
+			return routesPage(e, cfg.Router.Routes())
+		})
+		page("stacks", func(e T) error {
+			return stacksPage(e, cfg.Router.Stacks())
+		})
+	}
+	if cfg.Sessions != nil {
+		page("sessions", func(e T) error {
+			active, err := cfg.Sessions(e.Request().Context())
+			if err != nil {
+				return err
+			}
+			return render(e, http.StatusOK, "Sessions", map[string]int64{"active": active},
+				[]string{"Active"}, [][]string{{strconv.FormatInt(active, 10)}})
+		})
+	}
+	if len(cfg.RateLimiters) > 0 {
+		page("ratelimits", func(e T) error {
+			return rateLimitsPage(e, cfg.RateLimiters)
+		})
+	}
+	if len(cfg.Checks) > 0 {
+		page("health", func(e T) error {
+			return healthPage(e, cfg.Checks, cfg.CheckTimeout)
+		})
+	}
+	if cfg.Errors != nil {
+		page("errors", func(e T) error {
+			return errorsPage(e, cfg.Errors.Events())
+		})
+	}
+
+	g.GET("/{$}", func(e T) error {
+		rows := make([][]string, len(pages))
+		for i, name := range pages {
+			rows[i] = []string{name}
+		}
+		return render(e, http.StatusOK, "Admin", map[string][]string{"pages": pages}, []string{"Page"}, rows)
+	})
+	return g
+}
+
+type route struct {
+	Pattern     string   `json:"pattern"`
+	Name        string   `json:"name,omitempty"`
+	Stacks      []string `json:"stacks,omitempty"`
+	Middlewares []string `json:"middlewares,omitempty"`
+	Handler     string   `json:"handler,omitempty"`
+	Budget      string   `json:"budget,omitempty"`
+}
+
+func routesPage[T wo.Resolver](e T, infos []wo.RouteInfo) error {
+	routes := make([]route, len(infos))
+	rows := make([][]string, len(infos))
+	for i, info := range infos {
+		routes[i] = route{
+			Pattern:     info.Pattern,
+			Name:        info.Name,
+			Stacks:      info.Stacks,
+			Middlewares: info.Middlewares,
+			Handler:     info.Handler,
+		}
+		if info.Budget > 0 {
+			routes[i].Budget = info.Budget.String()
+		}
+		rows[i] = []string{info.Pattern, info.Name, join(info.Stacks), join(info.Middlewares), info.Handler, routes[i].Budget}
+	}
+	return render(e, http.StatusOK, "Routes", routes, []string{"Pattern", "Name", "Stacks", "Middlewares", "Handler", "Budget"}, rows)
+}
+
+func stacksPage[T wo.Resolver](e T, stacks []string) error {
+	rows := make([][]string, len(stacks))
+	for i, name := range stacks {
+		rows[i] = []string{name}
+	}
+	return render(e, http.StatusOK, "Middleware stacks", map[string][]string{"stacks": stacks}, []string{"Stack"}, rows)
+}
+
+type rateLimitState struct {
+	Name      string     `json:"name"`
+	Remaining *int       `json:"remaining,omitempty"`
+	ResetAt   *time.Time `json:"resetAt,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+func rateLimitsPage[T wo.Resolver](e T, limiters map[string]RateLimiter) error {
+	ctx := e.Request().Context()
+	id := e.Request().URL.Query().Get("id")
+
+	names := make([]string, 0, len(limiters))
+	for name := range limiters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	states := make([]rateLimitState, len(names))
+	rows := make([][]string, len(names))
+	for i, name := range names {
+		states[i].Name = name
+		rows[i] = []string{name, "", "", ""}
+		if id == "" {
+			continue
+		}
+
+		remaining, err := limiters[name].Remaining(ctx, id)
+		var resetAt time.Time
+		if err == nil {
+			resetAt, err = limiters[name].ResetAt(ctx, id)
+		}
+		if err != nil {
+			states[i].Error = err.Error()
+			rows[i][3] = err.Error()
+			continue
+		}
+
+		states[i].Remaining = &remaining
+		rows[i][1] = strconv.Itoa(remaining)
+		if !resetAt.IsZero() {
+			states[i].ResetAt = &resetAt
+			rows[i][2] = resetAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return render(e, http.StatusOK, "Rate limits "+id, states, []string{"Name", "Remaining", "Reset at", "Error"}, rows)
+}
+
+type checkResult struct {
+	Name     string `json:"name"`
+	Healthy  bool   `json:"healthy"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// healthPage runs the checks concurrently, the status is 503 if any of them fails.
+func healthPage[T wo.Resolver](e T, checks map[string]Check, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(e.Request().Context(), timeout)
+	defer cancel()
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	results := make([]checkResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Go(func() {
+			start := time.Now()
+			err := checks[name](ctx)
+			results[i] = checkResult{Name: name, Healthy: err == nil, Duration: time.Since(start).String()}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		})
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	rows := make([][]string, len(results))
+	for i, res := range results {
+		if !res.Healthy {
+			status = http.StatusServiceUnavailable
+		}
+		rows[i] = []string{res.Name, strconv.FormatBool(res.Healthy), res.Duration, res.Error}
+	}
+	return render(e, status, "Health", results, []string{"Check", "Healthy", "Duration", "Error"}, rows)
+}
+
+func errorsPage[T wo.Resolver](e T, events []ReportedError) error {
+	rows := make([][]string, len(events))
+	for i, event := range events {
+		kind := "message"
+		if event.Error {
+			kind = "error"
+		}
+		tags := make([]string, 0, len(event.Tags))
+		for k, v := range event.Tags {
+			tags = append(tags, k+"="+v)
+		}
+		slices.Sort(tags)
+		rows[i] = []string{event.Time.UTC().Format(time.RFC3339), kind, event.Message, join(tags)}
+	}
+	return render(e, http.StatusOK, "Recent errors", events, []string{"Time", "Kind", "Message", "Tags"}, rows)
+}
+
+var pageTpl = template.Must(template.New("admin").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>body{font-family:sans-serif;margin:2em}table{border-collapse:collapse}th,td{border:1px solid #ccc;padding:.3em .6em;text-align:left;vertical-align:top}</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// render writes the data as JSON, or the rows as the HTML table if the client prefers HTML.
+func render[T wo.Resolver](e T, status int, title string, data any, columns []string, rows [][]string) error {
+	w := e.Response()
+	w.Header().Set(wo.HeaderCacheControl, "no-store")
+	w.Header().Add(wo.HeaderVary, wo.HeaderAccept)
+
+	if wo.NegotiateMediaType(wo.ParseAccept(e.Request().Header.Get(wo.HeaderAccept)), wo.MIMEApplicationJSON, wo.MIMETextHTML) == wo.MIMETextHTML {
+		w.Header().Set(wo.HeaderContentType, wo.MIMETextHTMLCharsetUTF8)
+		w.WriteHeader(status)
+		return pageTpl.Execute(w, struct {
+			Title   string
+			Columns []string
+			Rows    [][]string
+		}{title, columns, rows})
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return wo.ErrInternalServerError.WithInternal(err)
+	}
+	w.Header().Set(wo.HeaderContentType, wo.MIMEApplicationJSON)
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+	return err
+}
+
+func join(values []string) string {
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,176 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func newTestRouter() *wo.Router[*wo.Event] {
+	return wo.New[*wo.Event](
+		func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+			e := new(wo.Event)
+			e.Reset(w, r)
+			return e, nil
+		},
+		func(e *wo.Event, err error) {
+			e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+		},
+	)
+}
+
+func serve(t *testing.T, h http.Handler, target, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if accept != "" {
+		req.Header.Set(wo.HeaderAccept, accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+type fakeRateLimiter struct {
+	err error
+}
+
+func (l fakeRateLimiter) Remaining(_ context.Context, id string) (int, error) {
+	return len(id), l.err
+}
+
+func (l fakeRateLimiter) ResetAt(context.Context, string) (time.Time, error) {
+	return time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), l.err
+}
+
+func TestMountAdmin(t *testing.T) {
+	recent := NewRecentErrors(10)
+	recent.CaptureException(context.Background(), errors.New("db is down"), map[string]string{"route": "/users"})
+
+	r := newTestRouter()
+	r.StackFunc("api", func(e *wo.Event) error { return e.Next() })
+	r.GET("/users", func(e *wo.Event) error { return nil }).Named("users")
+
+	MountAdmin(r.RouterGroup, Config[*wo.Event]{
+		Allow:    func(*wo.Event) bool { return true },
+		Router:   r,
+		Sessions: func(context.Context) (int64, error) { return 3, nil },
+		RateLimiters: map[string]RateLimiter{
+			"api":    fakeRateLimiter{},
+			"broken": fakeRateLimiter{err: errors.New("storage error")},
+		},
+		Checks: map[string]Check{
+			"db":    func(context.Context) error { return errors.New("connection refused") },
+			"cache": func(context.Context) error { return nil },
+		},
+		Errors: recent,
+	})
+
+	h, err := r.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		target         string
+		accept         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "index", target: "/admin/", expectedStatus: http.StatusOK, expectedBody: `{"pages":["routes","stacks","sessions","ratelimits","health","errors"]}`},
+		{name: "routes", target: "/admin/routes", expectedStatus: http.StatusOK, expectedBody: `"pattern":"GET /users","name":"users"`},
+		{name: "stacks", target: "/admin/stacks", expectedStatus: http.StatusOK, expectedBody: `{"stacks":["api"]}`},
+		{name: "sessions", target: "/admin/sessions", expectedStatus: http.StatusOK, expectedBody: `{"active":3}`},
+		{name: "rate limits", target: "/admin/ratelimits", expectedStatus: http.StatusOK, expectedBody: `[{"name":"api"},{"name":"broken"}]`},
+		{
+			name:           "rate limits of identifier",
+			target:         "/admin/ratelimits?id=abc",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"name":"api","remaining":3,"resetAt":"2024-01-01T00:00:00Z"},{"name":"broken","error":"storage error"}]`,
+		},
+		{name: "health", target: "/admin/health", expectedStatus: http.StatusServiceUnavailable, expectedBody: `"name":"db","healthy":false`},
+		{name: "errors", target: "/admin/errors", expectedStatus: http.StatusOK, expectedBody: `"message":"db is down","error":true,"tags":{"route":"/users"}`},
+		{name: "html", target: "/admin/errors", accept: "text/html", expectedStatus: http.StatusOK, expectedBody: "<td>db is down</td>"},
+		{name: "html preferred", target: "/admin/routes", accept: "text/html,application/json;q=0.9", expectedStatus: http.StatusOK, expectedBody: "<td>GET /users</td>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, h, tt.target, tt.accept)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			assert.Equal(t, "no-store", rec.Header().Get(wo.HeaderCacheControl))
+		})
+	}
+}
+
+func TestMountAdmin_Unconfigured(t *testing.T) {
+	r := newTestRouter()
+	MountAdmin(r.RouterGroup, Config[*wo.Event]{Prefix: "/_", Allow: func(*wo.Event) bool { return true }})
+
+	h, err := r.Build(nil)
+	require.NoError(t, err)
+
+	rec := serve(t, h, "/_/", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"pages":[]}`, rec.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, serve(t, h, "/_/routes", "").Code)
+}
+
+func TestMountAdmin_Denied(t *testing.T) {
+	r := newTestRouter()
+	MountAdmin(r.RouterGroup, Config[*wo.Event]{Sessions: func(context.Context) (int64, error) { return 0, nil }})
+	MountAdmin(r.RouterGroup, Config[*wo.Event]{Prefix: "/auth", Allow: func(*wo.Event) bool { return true }}, func(*wo.Event) error {
+		return wo.ErrUnauthorized
+	})
+
+	h, err := r.Build(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, serve(t, h, "/admin/sessions", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(t, h, "/auth/", "").Code)
+}
+
+func TestMountAdmin_MiddlewaresBeforeAllow(t *testing.T) {
+	type userKey struct{}
+
+	r := newTestRouter()
+	MountAdmin(r.RouterGroup, Config[*wo.Event]{
+		Allow:    func(e *wo.Event) bool { return e.Value(userKey{}) == "staff" },
+		Sessions: func(context.Context) (int64, error) { return 3, nil },
+	}, func(e *wo.Event) error {
+		e.SetValue(userKey{}, "staff")
+		return e.Next()
+	})
+
+	h, err := r.Build(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serve(t, h, "/admin/sessions", "").Code)
+}
+
+func TestRecentErrors(t *testing.T) {
+	ctx := context.Background()
+	r := NewRecentErrors(2)
+	assert.Empty(t, r.Events())
+
+	r.CaptureMessage(ctx, "first", nil)
+	r.CaptureException(ctx, errors.New("second"), nil)
+	r.CaptureMessage(ctx, "third", nil)
+
+	events := r.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "third", events[0].Message)
+	assert.False(t, events[0].Error)
+	assert.Equal(t, "second", events[1].Message)
+	assert.True(t, events[1].Error)
+}
@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo/report"
+)
+
+var _ report.ErrorReporter = (*RecentErrors)(nil)
+
+// ReportedError is the error or the message captured by [RecentErrors].
+type ReportedError struct {
+	Time    time.Time   `json:"time"`
+	Message string      `json:"message"`
+	Error   bool        `json:"error"`
+	Tags    report.Tags `json:"tags,omitempty"`
+}
+
+// RecentErrors is the [report.ErrorReporter] keeping the recently reported errors and messages in
+// the ring buffer, for the admin errors page. Plug it in next to the error aggregation service,
+// ex. with [report.Multi].
+type RecentErrors struct {
+	mu     sync.Mutex
+	events []ReportedError
+	next   int
+	full   bool
+}
+
+// NewRecentErrors returns the [RecentErrors] keeping the last size events, 100 if size is not positive.
+func NewRecentErrors(size int) *RecentErrors {
+	if size <= 0 {
+		size = 100
+	}
+	return &RecentErrors{events: make([]ReportedError, size)}
+}
+
+func (r *RecentErrors) CaptureException(_ context.Context, err error, tags report.Tags) {
+	r.add(ReportedError{Time: time.Now(), Message: err.Error(), Error: true, Tags: tags})
+}
+
+func (r *RecentErrors) CaptureMessage(_ context.Context, msg string, tags report.Tags) {
+	r.add(ReportedError{Time: time.Now(), Message: msg, Tags: tags})
+}
+
+// Events returns the kept events, the most recent first.
+func (r *RecentErrors) Events() []ReportedError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := slices.Clone(r.events[:r.next])
+	if r.full {
+		events = append(slices.Clone(r.events[r.next:]), events...)
+	}
+	slices.Reverse(events)
+	return events
+}
+
+func (r *RecentErrors) add(event ReportedError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[r.next] = event
+	r.next++
+	if r.next == len(r.events) {
+		r.next, r.full = 0, true
+	}
+}
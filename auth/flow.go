@@ -0,0 +1,317 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/session"
+)
+
+const (
+	stateKey    = session.InternalKeyPrefix + "oauthState"
+	nonceKey    = session.InternalKeyPrefix + "oauthNonce"
+	verifierKey = session.InternalKeyPrefix + "oauthVerifier"
+	providerKey = session.InternalKeyPrefix + "oauthProvider"
+)
+
+// Token is the token endpoint response.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// LoginFunc is called once the user is authenticated by the provider. It should find or create
+// the local user, store it in the session (renewing the session token) and write the response,
+// usually a redirect.
+type LoginFunc[T wo.Resolver] func(e T, provider *Provider, user *User, token *Token) error
+
+// Flow implements the login and the callback handlers of the authorization code flow with PKCE.
+// The state, the nonce and the PKCE code verifier are kept in the session between the requests,
+// so the session middleware must be in front of the handlers.
+type Flow[T wo.Resolver] struct {
+	// ProviderParam is the path wildcard of the provider name.
+	//
+	// Default: "provider"
+	ProviderParam string
+
+	registry *Registry
+	session  *session.Session
+	onLogin  LoginFunc[T]
+}
+
+func NewFlow[T wo.Resolver](registry *Registry, s *session.Session, onLogin LoginFunc[T]) *Flow[T] {
+	if registry == nil {
+		panic("auth: registry is nil")
+	}
+	if s == nil {
+		panic("auth: session is nil")
+	}
+	if onLogin == nil {
+		panic("auth: login func is nil")
+	}
+
+	return &Flow[T]{
+		ProviderParam: "provider",
+		registry:      registry,
+		session:       s,
+		onLogin:       onLogin,
+	}
+}
+
+// Login redirects to the authorization endpoint of the provider.
+func (f *Flow[T]) Login(e T) error {
+	p, err := f.provider(e)
+	if err != nil {
+		return err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return err
+	}
+
+	ctx := e.Request().Context()
+	// the flow state is written also by the GET requests of the read-only sessions
+	f.session.SetReadOnly(ctx, false)
+	f.session.Put(ctx, providerKey, p.Name)
+	f.session.Put(ctx, stateKey, state)
+	f.session.Put(ctx, verifierKey, verifier)
+	if p.Issuer != "" {
+		f.session.Put(ctx, nonceKey, nonce)
+	}
+
+	http.Redirect(e.Response(), e.Request(), p.AuthCodeURL(state, nonce, verifier), http.StatusFound)
+	return nil
+}
+
+// Callback completes the flow: it validates the state, exchanges the code, validates the ID token,
+// fetches the user info and calls the login func.
+func (f *Flow[T]) Callback(e T) error {
+	p, err := f.provider(e)
+	if err != nil {
+		return err
+	}
+
+	ctx := e.Request().Context()
+	// the flow state is single-use, it's consumed also by the read-only sessions
+	f.session.SetReadOnly(ctx, false)
+	provider := f.session.PopString(ctx, providerKey)
+	state := f.session.PopString(ctx, stateKey)
+	verifier := f.session.PopString(ctx, verifierKey)
+	nonce := f.session.PopString(ctx, nonceKey)
+
+	query := e.Request().URL.Query()
+
+	if code := query.Get("error"); code != "" {
+		return wo.ErrUnauthorized.WithInternal(fmt.Errorf("auth: %s: %s %s", p.Name, code, query.Get("error_description")))
+	}
+
+	if state == "" || provider != p.Name ||
+		subtle.ConstantTimeCompare([]byte(state), []byte(query.Get("state"))) != 1 {
+		return wo.ErrBadRequest.WithInternal(errors.New("auth: invalid state"))
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return wo.ErrBadRequest.WithInternal(errors.New("auth: missing code"))
+	}
+
+	token, err := p.Exchange(ctx, code, verifier)
+	if err != nil {
+		return wo.ErrUnauthorized.WithInternal(err)
+	}
+
+	user, err := p.User(ctx, token, nonce)
+	if err != nil {
+		return wo.ErrUnauthorized.WithInternal(err)
+	}
+
+	return f.onLogin(e, p, user, token)
+}
+
+func (f *Flow[T]) provider(e T) (*Provider, error) {
+	name := e.Request().PathValue(f.ProviderParam)
+	p, ok := f.registry.Get(name)
+	if !ok {
+		return nil, wo.ErrNotFound.WithInternal(fmt.Errorf("auth: provider %q is not registered", name))
+	}
+	return p, nil
+}
+
+// AuthCodeURL returns the authorization endpoint URL with the PKCE S256 challenge of the verifier.
+// The nonce is sent to the OpenID Connect providers only.
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	challenge := sha256.Sum256([]byte(verifier))
+
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if p.RedirectURL != "" {
+		v.Set("redirect_uri", p.RedirectURL)
+	}
+	if len(p.Scopes) > 0 {
+		v.Set("scope", strings.Join(p.Scopes, " "))
+	}
+	if p.Issuer != "" && nonce != "" {
+		v.Set("nonce", nonce)
+	}
+	for k, value := range p.AuthParams {
+		v.Set(k, value)
+	}
+
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.AuthURL + sep + v.Encode()
+}
+
+// Exchange exchanges the authorization code for the token.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*Token, error) {
+	v := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	if p.RedirectURL != "" {
+		v.Set("redirect_uri", p.RedirectURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(wo.HeaderContentType, wo.MIMEApplicationForm)
+
+	var token Token
+	if err = doJSON(p.client(), req, &token); err != nil {
+		return nil, fmt.Errorf("auth: %s: token exchange: %w", p.Name, err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("auth: %s: token exchange: missing access token", p.Name)
+	}
+	return &token, nil
+}
+
+// User returns the user mapped from the ID token claims merged with the user info response.
+// The ID token is required and validated against the nonce if the provider has an issuer.
+func (p *Provider) User(ctx context.Context, token *Token, nonce string) (*User, error) {
+	claims := make(map[string]any)
+
+	if p.Issuer != "" {
+		idClaims, err := p.validateIDToken(token.IDToken, nonce)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(claims, idClaims)
+	}
+
+	if p.UserInfoURL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.UserInfoURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(wo.HeaderAuthorization, "Bearer "+token.AccessToken)
+
+		info := make(map[string]any)
+		if err = doJSON(p.client(), req, &info); err != nil {
+			return nil, fmt.Errorf("auth: %s: user info: %w", p.Name, err)
+		}
+		// the user info must belong to the subject of the ID token
+		if sub, ok := claims["sub"]; ok && info["sub"] != nil && info["sub"] != sub {
+			return nil, fmt.Errorf("auth: %s: user info: subject mismatch", p.Name)
+		}
+		maps.Copy(claims, info)
+	}
+
+	return p.mapUser(claims)
+}
+
+func (p *Provider) validateIDToken(idToken, nonce string) (map[string]any, error) {
+	if idToken == "" {
+		return nil, fmt.Errorf("auth: %s: missing id token", p.Name)
+	}
+
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("auth: %s: malformed id token", p.Name)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("auth: %s: malformed id token: %w", p.Name, err)
+	}
+
+	claims := make(map[string]any)
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("auth: %s: malformed id token: %w", p.Name, err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != p.Issuer {
+		return nil, fmt.Errorf("auth: %s: id token issuer %q doesn't match", p.Name, iss)
+	}
+
+	var aud []string
+	switch v := claims["aud"].(type) {
+	case string:
+		aud = []string{v}
+	case []any:
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+	}
+	if !slices.Contains(aud, p.ClientID) {
+		return nil, fmt.Errorf("auth: %s: id token audience doesn't match", p.Name)
+	}
+
+	exp, _ := claims["exp"].(float64)
+	if time.Now().Unix() >= int64(exp) {
+		return nil, fmt.Errorf("auth: %s: id token expired", p.Name)
+	}
+
+	if got, _ := claims["nonce"].(string); nonce == "" || subtle.ConstantTimeCompare([]byte(got), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("auth: %s: id token nonce doesn't match", p.Name)
+	}
+
+	return claims, nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/session"
+)
+
+type nopStore struct{}
+
+func (nopStore) Delete(context.Context, string) error { return nil }
+
+func (nopStore) Find(context.Context, string) ([]byte, bool, error) { return nil, false, nil }
+
+func (nopStore) Commit(context.Context, string, []byte, time.Time) error { return nil }
+
+// testIDP is the OpenID Connect provider, which issues the ID token with the nonce
+// of the last authorization request.
+type testIDP struct {
+	*httptest.Server
+	nonce    string
+	verifier string
+	claims   map[string]any
+}
+
+func newTestIDP(t *testing.T) *testIDP {
+	t.Helper()
+
+	idp := &testIDP{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"userinfo_endpoint":      idp.URL + "/userinfo",
+			"scopes_supported":       []string{"openid", "email"},
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code" || r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		idp.verifier = r.FormValue("code_verifier")
+
+		claims := map[string]any{
+			"iss":   idp.URL,
+			"aud":   "client",
+			"sub":   "42",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": idp.nonce,
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		payload, _ := json.Marshal(claims)
+
+		_ = json.NewEncoder(w).Encode(Token{
+			AccessToken: "access",
+			TokenType:   "Bearer",
+			IDToken:     "e30." + base64.RawURLEncoding.EncodeToString(payload) + ".sig",
+		})
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(wo.HeaderAuthorization) != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"sub":            "42",
+			"email":          "john@example.com",
+			"email_verified": true,
+			"name":           "John",
+		})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func newFlowTestEvent(t *testing.T, ctx context.Context, target string) (*wo.Event, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	r := httptest.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	r.SetPathValue("provider", "oidc")
+	w := httptest.NewRecorder()
+
+	e := new(wo.Event)
+	e.Reset(w, r)
+	return e, w
+}
+
+func setupFlow(t *testing.T, idp *testIDP) (*Flow[*wo.Event], *Provider, context.Context, *User) {
+	t.Helper()
+
+	p, err := Discover(context.Background(), "oidc", idp.URL, "client", "secret", "https://app.example.com/callback")
+	require.NoError(t, err)
+
+	s := session.New(session.Config{}, nopStore{})
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	user := new(User)
+	flow := NewFlow(NewRegistry(p), s, func(e *wo.Event, _ *Provider, u *User, _ *Token) error {
+		*user = *u
+		return e.Redirect(http.StatusFound, "/")
+	})
+	return flow, p, ctx, user
+}
+
+func login(t *testing.T, flow *Flow[*wo.Event], idp *testIDP, ctx context.Context) url.Values {
+	t.Helper()
+
+	e, w := newFlowTestEvent(t, ctx, "/auth/oidc")
+	require.NoError(t, flow.Login(e))
+	require.Equal(t, http.StatusFound, w.Code)
+
+	location, err := url.Parse(w.Header().Get(wo.HeaderLocation))
+	require.NoError(t, err)
+	assert.Equal(t, idp.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+
+	query := location.Query()
+	idp.nonce = query.Get("nonce")
+	return query
+}
+
+func TestFlow(t *testing.T) {
+	idp := newTestIDP(t)
+	flow, _, ctx, user := setupFlow(t, idp)
+
+	query := login(t, flow, idp, ctx)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "client", query.Get("client_id"))
+	assert.Equal(t, "openid email", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "https://app.example.com/callback", query.Get("redirect_uri"))
+	assert.NotEmpty(t, query.Get("nonce"))
+
+	e, w := newFlowTestEvent(t, ctx, "/auth/oidc/callback?code=code&state="+url.QueryEscape(query.Get("state")))
+	require.NoError(t, flow.Callback(e))
+	assert.Equal(t, http.StatusFound, w.Code)
+
+	challenge := sha256.Sum256([]byte(idp.verifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(challenge[:]), query.Get("code_challenge"))
+
+	assert.Equal(t, "42", user.ID)
+	assert.Equal(t, "john@example.com", user.Email)
+	assert.True(t, user.EmailVerified)
+	assert.Equal(t, "John", user.Name)
+
+	// the state is single-use
+	e, _ = newFlowTestEvent(t, ctx, "/auth/oidc/callback?code=code&state="+url.QueryEscape(query.Get("state")))
+	assert.Equal(t, http.StatusBadRequest, wo.AsHTTPError(flow.Callback(e)).Status)
+}
+
+func TestFlow_ReadOnlySession(t *testing.T) {
+	idp := newTestIDP(t)
+	flow, _, ctx, user := setupFlow(t, idp)
+	flow.session.SetReadOnly(ctx, true)
+
+	query := login(t, flow, idp, ctx)
+
+	flow.session.SetReadOnly(ctx, true)
+	e, w := newFlowTestEvent(t, ctx, "/auth/oidc/callback?code=code&state="+url.QueryEscape(query.Get("state")))
+	require.NoError(t, flow.Callback(e))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "42", user.ID)
+
+	// the state is consumed
+	flow.session.SetReadOnly(ctx, true)
+	e, _ = newFlowTestEvent(t, ctx, "/auth/oidc/callback?code=code&state="+url.QueryEscape(query.Get("state")))
+	assert.Equal(t, http.StatusBadRequest, wo.AsHTTPError(flow.Callback(e)).Status)
+}
+
+func TestFlow_Callback_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         func(state string) string
+		claims         map[string]any
+		expectedStatus int
+	}{
+		{
+			name:           "invalid state",
+			target:         func(string) string { return "/callback?code=code&state=invalid" },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing code",
+			target:         func(state string) string { return "/callback?state=" + url.QueryEscape(state) },
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "provider error",
+			target:         func(string) string { return "/callback?error=access_denied" },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid code",
+			target:         func(state string) string { return "/callback?code=invalid&state=" + url.QueryEscape(state) },
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid audience",
+			target:         func(state string) string { return "/callback?code=code&state=" + url.QueryEscape(state) },
+			claims:         map[string]any{"aud": []string{"other"}},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid nonce",
+			target:         func(state string) string { return "/callback?code=code&state=" + url.QueryEscape(state) },
+			claims:         map[string]any{"nonce": "invalid"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "expired id token",
+			target:         func(state string) string { return "/callback?code=code&state=" + url.QueryEscape(state) },
+			claims:         map[string]any{"exp": time.Now().Add(-time.Minute).Unix()},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIDP(t)
+			idp.claims = tt.claims
+			flow, _, ctx, _ := setupFlow(t, idp)
+
+			query := login(t, flow, idp, ctx)
+
+			e, _ := newFlowTestEvent(t, ctx, tt.target(query.Get("state")))
+			err := flow.Callback(e)
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedStatus, wo.AsHTTPError(err).Status)
+		})
+	}
+}
+
+func TestFlow_UnknownProvider(t *testing.T) {
+	idp := newTestIDP(t)
+	flow, _, ctx, _ := setupFlow(t, idp)
+
+	e, _ := newFlowTestEvent(t, ctx, "/auth/unknown")
+	e.SetParam("provider", "unknown")
+
+	assert.Equal(t, http.StatusNotFound, wo.AsHTTPError(flow.Login(e)).Status)
+	assert.Equal(t, http.StatusNotFound, wo.AsHTTPError(flow.Callback(e)).Status)
+}
+
+func TestNewFlow_Panics(t *testing.T) {
+	s := session.New(session.Config{}, nopStore{})
+	onLogin := func(*wo.Event, *Provider, *User, *Token) error { return nil }
+
+	assert.Panics(t, func() { NewFlow[*wo.Event](nil, s, onLogin) })
+	assert.Panics(t, func() { NewFlow[*wo.Event](NewRegistry(), nil, onLogin) })
+	assert.Panics(t, func() { NewFlow[*wo.Event](NewRegistry(), s, nil) })
+}
@@ -0,0 +1,279 @@
+// Package auth implements the OAuth 2.0 authorization code flow with PKCE (RFC 7636) and
+// the OpenID Connect login, so the social login is a few lines of setup:
+//
+//	providers := auth.NewRegistry(
+//		auth.Google(clientID, clientSecret, "https://example.com/auth/google/callback"),
+//		auth.GitHub(ghClientID, ghClientSecret, "https://example.com/auth/github/callback"),
+//	)
+//	flow := auth.NewFlow(providers, sessions, func(e *wo.Event, p *auth.Provider, u *auth.User, t *auth.Token) error {
+//		// find or create the user, store it in the session
+//		return e.Redirect(http.StatusFound, "/")
+//	})
+//	r.GET("/auth/{provider}", flow.Login)
+//	r.GET("/auth/{provider}/callback", flow.Callback)
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// User is the user information mapped from the provider claims.
+type User struct {
+	// ID is the stable user identifier of the provider, ex. the OIDC subject.
+	ID            string
+	Email         string
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+
+	// Claims are the raw ID token claims merged with the user info response.
+	Claims map[string]any
+}
+
+// Provider is the OAuth 2.0 (or OpenID Connect) provider configuration.
+type Provider struct {
+	// Name identifies the provider in the registry and the login routes.
+	Name string
+
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+
+	// Issuer enables the OpenID Connect ID token validation (issuer, audience, expiry and nonce).
+	// The ID token signature is not verified: the token is received directly from the token
+	// endpoint over TLS, which the OpenID Connect Core 1.0 (section 3.1.3.7) permits.
+	Issuer string
+
+	Scopes []string
+
+	// AuthParams are the additional authorization request parameters, ex. "prompt".
+	AuthParams map[string]string
+
+	// MapUser maps the claims to the user.
+	//
+	// Default: the standard OpenID Connect claims (sub, email, email_verified, name, picture)
+	MapUser func(claims map[string]any) (*User, error)
+
+	// Client is the HTTP client of the token and the user info requests.
+	//
+	// Default: the client with the 10s timeout
+	Client *http.Client
+}
+
+func (p *Provider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return defaultClient
+}
+
+func (p *Provider) mapUser(claims map[string]any) (*User, error) {
+	if p.MapUser != nil {
+		return p.MapUser(claims)
+	}
+	return MapOIDCUser(claims)
+}
+
+// MapOIDCUser maps the standard OpenID Connect claims to the user.
+func MapOIDCUser(claims map[string]any) (*User, error) {
+	u := &User{
+		ID:        claimString(claims, "sub"),
+		Email:     claimString(claims, "email"),
+		Name:      claimString(claims, "name"),
+		AvatarURL: claimString(claims, "picture"),
+		Claims:    claims,
+	}
+	u.EmailVerified, _ = claims["email_verified"].(bool)
+
+	if u.ID == "" {
+		return nil, errors.New("auth: the subject claim is missing")
+	}
+	return u, nil
+}
+
+// Google returns the Google OpenID Connect provider.
+func Google(clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name:         "google",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		Issuer:       "https://accounts.google.com",
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+}
+
+// GitHub returns the GitHub OAuth app provider. The email is empty if the user keeps it private.
+func GitHub(clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name:         "github",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      "https://github.com/login/oauth/authorize",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		UserInfoURL:  "https://api.github.com/user",
+		Scopes:       []string{"read:user", "user:email"},
+		MapUser: func(claims map[string]any) (*User, error) {
+			u := &User{
+				ID:        claimString(claims, "id"),
+				Email:     claimString(claims, "email"),
+				Name:      claimString(claims, "name"),
+				AvatarURL: claimString(claims, "avatar_url"),
+				Claims:    claims,
+			}
+			if u.Name == "" {
+				u.Name = claimString(claims, "login")
+			}
+			if u.ID == "" {
+				return nil, errors.New("auth: the github user id is missing")
+			}
+			return u, nil
+		},
+	}
+}
+
+// Discover returns the OpenID Connect provider configured from the issuer discovery document.
+//
+// See: https://openid.net/specs/openid-connect-discovery-1_0.html
+func Discover(ctx context.Context, name, issuer, clientID, clientSecret, redirectURL string) (*Provider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc struct {
+		Issuer           string   `json:"issuer"`
+		AuthEndpoint     string   `json:"authorization_endpoint"`
+		TokenEndpoint    string   `json:"token_endpoint"`
+		UserInfoEndpoint string   `json:"userinfo_endpoint"`
+		Scopes           []string `json:"scopes_supported"`
+	}
+	if err = doJSON(defaultClient, req, &doc); err != nil {
+		return nil, fmt.Errorf("auth: discover %q: %w", issuer, err)
+	}
+	if doc.Issuer != issuer {
+		return nil, fmt.Errorf("auth: discover %q: the issuer %q doesn't match", issuer, doc.Issuer)
+	}
+	if doc.AuthEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, fmt.Errorf("auth: discover %q: the endpoints are missing", issuer)
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range []string{"email", "profile"} {
+		if len(doc.Scopes) == 0 || slices.Contains(doc.Scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &Provider{
+		Name:         name,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		AuthURL:      doc.AuthEndpoint,
+		TokenURL:     doc.TokenEndpoint,
+		UserInfoURL:  doc.UserInfoEndpoint,
+		Issuer:       doc.Issuer,
+		Scopes:       scopes,
+	}, nil
+}
+
+// Registry holds the providers by name.
+type Registry struct {
+	providers map[string]*Provider
+	mu        sync.RWMutex
+}
+
+func NewRegistry(providers ...*Provider) *Registry {
+	r := &Registry{providers: make(map[string]*Provider)}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds the provider, a provider with the same name replaces the previously registered one.
+func (r *Registry) Register(p *Provider) {
+	if p == nil {
+		panic("auth: provider is nil")
+	}
+	if p.Name == "" {
+		panic("auth: provider must have a name")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers[p.Name] = p
+}
+
+// Get returns the provider with the name.
+func (r *Registry) Get(name string) (*Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns the sorted names of the registered providers.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func claimString(claims map[string]any, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+func doJSON(client *http.Client, req *http.Request, dst any) error {
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(dst)
+}
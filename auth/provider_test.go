@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscover(t *testing.T) {
+	idp := newTestIDP(t)
+
+	p, err := Discover(context.Background(), "oidc", idp.URL+"/", "client", "secret", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "oidc", p.Name)
+	assert.Equal(t, idp.URL, p.Issuer)
+	assert.Equal(t, idp.URL+"/authorize", p.AuthURL)
+	assert.Equal(t, idp.URL+"/token", p.TokenURL)
+	assert.Equal(t, idp.URL+"/userinfo", p.UserInfoURL)
+	assert.Equal(t, []string{"openid", "email"}, p.Scopes)
+}
+
+func TestDiscover_Error(t *testing.T) {
+	idp := newTestIDP(t)
+
+	_, err := Discover(context.Background(), "oidc", idp.URL+"/unknown", "client", "secret", "")
+	assert.Error(t, err)
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(Google("id", "secret", ""), GitHub("id", "secret", ""))
+
+	assert.Equal(t, []string{"github", "google"}, r.Names())
+
+	p, ok := r.Get("google")
+	require.True(t, ok)
+	assert.Equal(t, "https://accounts.google.com", p.Issuer)
+
+	_, ok = r.Get("unknown")
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { r.Register(nil) })
+	assert.Panics(t, func() { r.Register(&Provider{}) })
+}
+
+func TestMapUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    *Provider
+		claims      map[string]any
+		expected    User
+		expectError bool
+	}{
+		{
+			name:     "oidc",
+			provider: Google("id", "secret", ""),
+			claims:   map[string]any{"sub": "42", "email": "john@example.com", "email_verified": true, "picture": "https://example.com/a.png"},
+			expected: User{ID: "42", Email: "john@example.com", EmailVerified: true, AvatarURL: "https://example.com/a.png"},
+		},
+		{
+			name:        "oidc without subject",
+			provider:    Google("id", "secret", ""),
+			claims:      map[string]any{"email": "john@example.com"},
+			expectError: true,
+		},
+		{
+			name:     "github",
+			provider: GitHub("id", "secret", ""),
+			claims:   map[string]any{"id": float64(1234567), "login": "john", "avatar_url": "https://example.com/a.png"},
+			expected: User{ID: "1234567", Name: "john", AvatarURL: "https://example.com/a.png"},
+		},
+		{
+			name:        "github without id",
+			provider:    GitHub("id", "secret", ""),
+			claims:      map[string]any{"login": "john"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := tt.provider.mapUser(tt.claims)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			tt.expected.Claims = tt.claims
+			assert.Equal(t, tt.expected, *u)
+		})
+	}
+}
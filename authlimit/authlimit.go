@@ -0,0 +1,219 @@
+// Package authlimit protects the login (and the other credential checking) handlers from
+// the brute-force attacks by tracking the failed attempts per identifier, ex. the username
+// and the client IP, with an exponential backoff and a lockout window.
+//
+//	if err := limiter.Check(ctx, key); err != nil {
+//		return err // 429 Too Many Requests, see LockedError
+//	}
+//	if !valid {
+//		_, _ = limiter.RecordFailure(ctx, key)
+//		return wo.ErrUnauthorized
+//	}
+//	_ = limiter.Reset(ctx, key)
+package authlimit
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/kv"
+)
+
+// LockedError is the internal error of the [wo.ErrTooManyRequests] returned by [Limiter.Check]
+// for the locked identifier.
+type LockedError struct {
+	// RetryAfter is the time left until the next attempt is allowed.
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("authlimit: too many failed attempts, retry after %s", e.RetryAfter)
+}
+
+type Config struct {
+	// FreeAttempts is the number of the failed attempts allowed without a delay.
+	//
+	// Default: 5
+	FreeAttempts int `env:"FREE_ATTEMPTS" json:"freeAttempts,omitempty" yaml:"freeAttempts,omitempty"`
+
+	// BaseDelay is the delay after the first failed attempt beyond FreeAttempts,
+	// it doubles with each next failed attempt up to MaxDelay.
+	//
+	// Default: 1s
+	BaseDelay time.Duration `env:"BASE_DELAY" json:"baseDelay,omitempty,format:units" yaml:"baseDelay,omitempty"`
+
+	// MaxDelay is the maximum backoff delay.
+	//
+	// Default: 5m
+	MaxDelay time.Duration `env:"MAX_DELAY" json:"maxDelay,omitempty,format:units" yaml:"maxDelay,omitempty"`
+
+	// LockoutAttempts is the number of the failed attempts which lock the identifier out
+	// for LockoutDuration.
+	//
+	// Default: 20
+	LockoutAttempts int `env:"LOCKOUT_ATTEMPTS" json:"lockoutAttempts,omitempty" yaml:"lockoutAttempts,omitempty"`
+
+	// LockoutDuration is the duration of the lockout.
+	//
+	// Default: 1h
+	LockoutDuration time.Duration `env:"LOCKOUT_DURATION" json:"lockoutDuration,omitempty,format:units" yaml:"lockoutDuration,omitempty"`
+
+	// Window is the time after the first failed attempt when the failed attempts are forgotten.
+	//
+	// Default: 24h
+	Window time.Duration `env:"WINDOW" json:"window,omitempty,format:units" yaml:"window,omitempty"`
+
+	// TimeFunc returns the current time.
+	//
+	// Default: time.Now
+	TimeFunc func() time.Time `json:"-" yaml:"-"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.FreeAttempts == 0 {
+		c.FreeAttempts = 5
+	}
+	if c.BaseDelay == 0 {
+		c.BaseDelay = time.Second
+	}
+	if c.MaxDelay == 0 {
+		c.MaxDelay = 5 * time.Minute
+	}
+	if c.LockoutAttempts == 0 {
+		c.LockoutAttempts = 20
+	}
+	if c.LockoutDuration == 0 {
+		c.LockoutDuration = time.Hour
+	}
+	if c.Window == 0 {
+		c.Window = 24 * time.Hour
+	}
+	if c.TimeFunc == nil {
+		c.TimeFunc = time.Now
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.FreeAttempts < 0 {
+		return errors.New("authlimit: free attempts must not be negative")
+	}
+	if c.LockoutAttempts <= c.FreeAttempts {
+		return errors.New("authlimit: lockout attempts must be greater than free attempts")
+	}
+	if c.BaseDelay <= 0 || c.MaxDelay <= 0 || c.LockoutDuration <= 0 || c.Window <= 0 {
+		return errors.New("authlimit: base delay, max delay, lockout duration and window must be positive")
+	}
+	if c.MaxDelay < c.BaseDelay {
+		return errors.New("authlimit: max delay must not be less than base delay")
+	}
+	return nil
+}
+
+// Limiter tracks the failed attempts per identifier in the [kv.Store], the failures are
+// counted with [kv.Store.Incr], so the concurrent attempts of the identifier are all counted
+// also across the instances sharing the store. The key of the identifier holds the counter,
+// the key with the ":lock" suffix the time until the next attempt is allowed.
+type Limiter struct {
+	config Config
+	store  kv.Store
+}
+
+func New(cfg Config, store kv.Store) *Limiter {
+	if store == nil {
+		panic("authlimit: store is nil")
+	}
+
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &Limiter{config: cfg, store: store}
+}
+
+// Key returns the identifier of the attempts of the username from the IP address.
+// The username is case-insensitive.
+func Key(username, ip string) string {
+	return strings.ToLower(strings.TrimSpace(username)) + "|" + ip
+}
+
+// Check returns [wo.ErrTooManyRequests] with the [LockedError] internal error
+// if the attempts of the identifier are delayed or locked out.
+func (l *Limiter) Check(ctx context.Context, key string) error {
+	b, found, err := l.store.Get(ctx, lockKey(key))
+	if err != nil || !found {
+		return err
+	}
+	if len(b) != 8 {
+		return errors.New("authlimit: invalid lock")
+	}
+
+	lockedUntil := time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+	if retryAfter := lockedUntil.Sub(l.config.TimeFunc()); retryAfter > 0 {
+		return wo.ErrTooManyRequests.WithInternal(&LockedError{RetryAfter: retryAfter})
+	}
+	return nil
+}
+
+// RecordFailure records the failed attempt of the identifier and returns the delay
+// until the next attempt is allowed, zero if there is none.
+func (l *Limiter) RecordFailure(ctx context.Context, key string) (time.Duration, error) {
+	n, err := l.store.Incr(ctx, key, 1, l.config.Window)
+	if err != nil {
+		return 0, err
+	}
+	failures := int(min(n, int64(l.config.LockoutAttempts)))
+
+	var delay time.Duration
+	switch {
+	case failures >= l.config.LockoutAttempts:
+		delay = l.config.LockoutDuration
+	case failures > l.config.FreeAttempts:
+		delay = l.config.BaseDelay
+		for i := l.config.FreeAttempts + 1; i < failures && delay < l.config.MaxDelay; i++ {
+			delay *= 2
+		}
+		delay = min(delay, l.config.MaxDelay)
+	default:
+		return 0, nil
+	}
+
+	lockedUntil := l.config.TimeFunc().Add(delay)
+	if err = l.store.Set(ctx, lockKey(key), binary.BigEndian.AppendUint64(nil, uint64(lockedUntil.UnixNano())), delay); err != nil {
+		return 0, err
+	}
+	return delay, nil
+}
+
+// Reset forgets the failed attempts of the identifier, ex. after the successful login.
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	if err := l.store.Delete(ctx, lockKey(key)); err != nil {
+		return err
+	}
+	return l.store.Delete(ctx, key)
+}
+
+// Failures returns the number of the failed attempts of the identifier within the window.
+func (l *Limiter) Failures(ctx context.Context, key string) (int, error) {
+	b, found, err := l.store.Get(ctx, key)
+	if err != nil || !found {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(string(b))
+	if err != nil {
+		return 0, kv.ErrNotInteger
+	}
+	return n, nil
+}
+
+func lockKey(key string) string {
+	return key + ":lock"
+}
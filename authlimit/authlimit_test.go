@@ -0,0 +1,176 @@
+package authlimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/kv"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(cfg Config) (*Limiter, *testClock) {
+	clock := &testClock{now: time.Unix(1_700_000_000, 0)}
+	cfg.TimeFunc = clock.Now
+	return New(cfg, newTestStore()), clock
+}
+
+func newTestStore() *kv.MemoryStore {
+	return kv.NewMemoryStore(kv.MemoryStoreConfig{CleanupInterval: -1})
+}
+
+func TestConfig_SetDefaults(t *testing.T) {
+	var cfg Config
+	cfg.SetDefaults()
+
+	assert.Equal(t, 5, cfg.FreeAttempts)
+	assert.Equal(t, time.Second, cfg.BaseDelay)
+	assert.Equal(t, 5*time.Minute, cfg.MaxDelay)
+	assert.Equal(t, 20, cfg.LockoutAttempts)
+	assert.Equal(t, time.Hour, cfg.LockoutDuration)
+	assert.Equal(t, 24*time.Hour, cfg.Window)
+	assert.NotNil(t, cfg.TimeFunc)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "negative free attempts", config: Config{FreeAttempts: -1}},
+		{name: "lockout not beyond free attempts", config: Config{FreeAttempts: 5, LockoutAttempts: 5}},
+		{name: "negative lockout attempts", config: Config{LockoutAttempts: -1}},
+		{name: "negative base delay", config: Config{BaseDelay: -time.Second}},
+		{name: "negative max delay", config: Config{MaxDelay: -time.Second}},
+		{name: "max delay less than base delay", config: Config{BaseDelay: time.Minute, MaxDelay: time.Second}},
+		{name: "negative lockout duration", config: Config{LockoutDuration: -time.Hour}},
+		{name: "negative window", config: Config{Window: -time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.SetDefaults()
+			assert.Error(t, tt.config.Validate())
+			assert.Panics(t, func() { New(tt.config, newTestStore()) })
+		})
+	}
+}
+
+func TestLimiter_RecordFailure_Backoff(t *testing.T) {
+	l, _ := newTestLimiter(Config{FreeAttempts: 2, BaseDelay: time.Second, MaxDelay: 5 * time.Second, LockoutAttempts: 8, LockoutDuration: time.Hour})
+
+	expected := []time.Duration{
+		0, 0, // free attempts
+		time.Second, 2 * time.Second, 4 * time.Second,
+		5 * time.Second, 5 * time.Second, // capped
+		time.Hour, // lockout
+	}
+
+	for i, want := range expected {
+		delay, err := l.RecordFailure(context.Background(), "key")
+		require.NoError(t, err)
+		assert.Equal(t, want, delay, "attempt %d", i+1)
+	}
+
+	failures, err := l.Failures(context.Background(), "key")
+	require.NoError(t, err)
+	assert.Equal(t, len(expected), failures)
+}
+
+func TestLimiter_RecordFailure_Concurrent(t *testing.T) {
+	l, _ := newTestLimiter(Config{FreeAttempts: 5, LockoutAttempts: 100})
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			_, err := l.RecordFailure(context.Background(), "key")
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	failures, err := l.Failures(context.Background(), "key")
+	require.NoError(t, err)
+	assert.Equal(t, 50, failures)
+	assert.Error(t, l.Check(context.Background(), "key"))
+}
+
+func TestLimiter_Check(t *testing.T) {
+	ctx := context.Background()
+	l, clock := newTestLimiter(Config{FreeAttempts: 1, BaseDelay: 10 * time.Second})
+
+	require.NoError(t, l.Check(ctx, "key"))
+
+	_, _ = l.RecordFailure(ctx, "key")
+	require.NoError(t, l.Check(ctx, "key"))
+
+	_, _ = l.RecordFailure(ctx, "key")
+	err := l.Check(ctx, "key")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, wo.AsHTTPError(err).Status)
+
+	var locked *LockedError
+	require.True(t, errors.As(err, &locked))
+	assert.Equal(t, 10*time.Second, locked.RetryAfter)
+
+	// the other identifiers are not affected
+	require.NoError(t, l.Check(ctx, "other"))
+
+	clock.now = clock.now.Add(10 * time.Second)
+	require.NoError(t, l.Check(ctx, "key"))
+}
+
+func TestLimiter_Reset(t *testing.T) {
+	ctx := context.Background()
+	l, _ := newTestLimiter(Config{FreeAttempts: 1})
+
+	_, _ = l.RecordFailure(ctx, "key")
+	_, _ = l.RecordFailure(ctx, "key")
+	require.Error(t, l.Check(ctx, "key"))
+
+	require.NoError(t, l.Reset(ctx, "key"))
+	require.NoError(t, l.Check(ctx, "key"))
+
+	failures, err := l.Failures(ctx, "key")
+	require.NoError(t, err)
+	assert.Zero(t, failures)
+}
+
+func TestLimiter_InvalidEntry(t *testing.T) {
+	store := newTestStore()
+	require.NoError(t, store.Set(context.Background(), "key", []byte("invalid"), 0))
+	require.NoError(t, store.Set(context.Background(), "key:lock", []byte("invalid"), 0))
+
+	l := New(Config{}, store)
+	assert.Error(t, l.Check(context.Background(), "key"))
+
+	_, err := l.Failures(context.Background(), "key")
+	assert.ErrorIs(t, err, kv.ErrNotInteger)
+
+	_, err = l.RecordFailure(context.Background(), "key")
+	assert.Error(t, err)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "alice|192.0.2.1", Key(" Alice ", "192.0.2.1"))
+	assert.NotEqual(t, Key("alice", "192.0.2.1"), Key("alice", "192.0.2.2"))
+}
+
+func TestNew_NilStore(t *testing.T) {
+	assert.Panics(t, func() { New(Config{}, nil) })
+}
@@ -3,8 +3,11 @@ package wo
 import (
 	"encoding"
 	"errors"
+	"fmt"
+	"maps"
 	"mime/multipart"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -50,6 +53,9 @@ func BindData(dst any, data map[string][]string, tag string, dataFiles map[strin
 			val.Set(reflect.MakeMap(typ))
 		}
 		for k, v := range data {
+			if len(v) == 0 && !isElemSliceOfStrings {
+				continue
+			}
 			if isElemString {
 				val.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v[0]))
 			} else if isElemInterface {
@@ -127,7 +133,23 @@ func BindData(dst any, data map[string][]string, tag string, dataFiles map[strin
 			}
 		}
 
+		if !exists && isSliceField(structField) {
+			// the repeated keys with the brackets, ex. `tags[]=a&tags[]=b`
+			inputValue, exists = data[inputFieldName+"[]"]
+		}
+
 		if !exists {
+			// the bracketed and the dotted keys, ex. `filter[status]=active&filter[age][gte]=30`
+			// or `items.0.name=a`, are bound into the nested structs, maps and slices
+			if nested := nestedData(data, inputFieldName); nested != nil {
+				if err := bindNested(structField, nested, tag); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if len(inputValue) == 0 {
 			continue
 		}
 
@@ -169,13 +191,250 @@ func BindData(dst any, data map[string][]string, tag string, dataFiles map[strin
 			continue
 		}
 
-		if err := setWithProperType(structFieldKind, inputValue[0], structField); err != nil {
+		value := inputValue[0]
+		if structFieldKind == reflect.Bool {
+			// the last value wins, so the checkbox overrides the hidden input of the same name
+			// sent before it, ex. `agree=false&agree=on`
+			value = inputValue[len(inputValue)-1]
+		}
+		if err := setWithProperType(structFieldKind, value, structField); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func isSliceField(field reflect.Value) bool {
+	typ := field.Type()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Slice
+}
+
+// nestedData returns the data of the bracketed or dotted keys of the prefix with the first brackets
+// or dot removed, ex. `filter[age][gte]` of the prefix `filter` becomes `age[gte]` and `items.0.name`
+// of the prefix `items` becomes `0.name`. It returns nil if there are none.
+func nestedData(data map[string][]string, prefix string) map[string][]string {
+	var nested map[string][]string
+	for k, v := range data {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+
+		var key string
+		switch rest[0] {
+		case '[':
+			if key, rest, ok = strings.Cut(rest[1:], "]"); !ok {
+				continue
+			}
+		case '.':
+			key, rest = rest[1:], ""
+		default:
+			continue
+		}
+		if key == "" {
+			continue
+		}
+
+		if nested == nil {
+			nested = make(map[string][]string)
+		}
+		nested[key+rest] = v
+	}
+	return nested
+}
+
+// maxBindSliceIndex is the maximum index of the indexed keys bound into the slices, ex. `items.0.name`.
+const maxBindSliceIndex = 1000
+
+// bindSlice binds the indexed data, ex. `0.name` or `0[name]` and `1`, into the slice of the structs,
+// maps or scalars. The elements are ordered by the index, the missing indexes are skipped.
+func bindSlice(field reflect.Value, data map[string][]string, tag string) error {
+	type item struct {
+		values []string
+		nested map[string][]string
+	}
+
+	items := make(map[int]*item)
+	for k, v := range data {
+		end := strings.IndexAny(k, ".[")
+		if end < 0 {
+			end = len(k)
+		}
+		index, err := strconv.Atoi(k[:end])
+		if err != nil || index < 0 {
+			continue
+		}
+		if index > maxBindSliceIndex {
+			return fmt.Errorf("binding slice index %d is out of range", index)
+		}
+
+		it, ok := items[index]
+		if !ok {
+			it = &item{nested: make(map[string][]string)}
+			items[index] = it
+		}
+
+		rest := k[end:]
+		switch {
+		case rest == "":
+			it.values = v
+		case rest[0] == '.':
+			it.nested[rest[1:]] = v
+		default:
+			key, after, ok := strings.Cut(rest[1:], "]")
+			if ok && key != "" {
+				it.nested[key+after] = v
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	elemType := field.Type().Elem()
+	slice := reflect.MakeSlice(field.Type(), 0, len(items))
+
+	for _, index := range slices.Sorted(maps.Keys(items)) {
+		it := items[index]
+		elem := reflect.New(elemType).Elem()
+
+		target, kind := elem, elemType.Kind()
+		if kind == reflect.Pointer {
+			target = reflect.New(elemType.Elem())
+			elem.Set(target)
+			target, kind = target.Elem(), elemType.Elem().Kind()
+		}
+
+		switch {
+		case kind == reflect.Struct && len(it.nested) > 0:
+			if err := BindData(target.Addr().Interface(), it.nested, tag, nil); err != nil {
+				return err
+			}
+		case kind == reflect.Map && len(it.nested) > 0:
+			if err := bindMap(target, it.nested, tag); err != nil {
+				return err
+			}
+		case len(it.values) > 0:
+			if err := setWithProperType(kind, it.values[0], target); err != nil {
+				return err
+			}
+		default:
+			continue
+		}
+		slice = reflect.Append(slice, elem)
+	}
+
+	field.Set(slice)
+	return nil
+}
+
+// bindNested binds the nested data into the struct or map field, the other types are ignored.
+func bindNested(field reflect.Value, data map[string][]string, tag string) error {
+	if field.Kind() == reflect.Pointer {
+		if k := field.Type().Elem().Kind(); k != reflect.Struct && k != reflect.Map && k != reflect.Slice {
+			return nil
+		}
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Struct:
+		switch field.Addr().Interface().(type) {
+		case BindUnmarshaler, encoding.TextUnmarshaler:
+			return nil
+		}
+		return BindData(field.Addr().Interface(), data, tag, nil)
+	case reflect.Map:
+		return bindMap(field, data, tag)
+	case reflect.Slice:
+		return bindSlice(field, data, tag)
+	default:
+		return nil
+	}
+}
+
+// bindMap binds the data into the map with string keys, the bracketed keys are bound into the nested
+// map or struct values, or into map[string]any values for the interface values.
+func bindMap(m reflect.Value, data map[string][]string, tag string) error {
+	typ := m.Type()
+	if typ.Key().Kind() != reflect.String {
+		return nil
+	}
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(typ))
+	}
+	elemType := typ.Elem()
+
+	for k, values := range data {
+		if strings.Contains(k, "[") || len(values) == 0 {
+			continue
+		}
+
+		elem := reflect.New(elemType).Elem()
+		switch elemType.Kind() {
+		case reflect.Interface:
+			// the first value like for the map[string]any destinations of BindData
+			elem.Set(reflect.ValueOf(values[0]))
+		case reflect.Slice:
+			slice := reflect.MakeSlice(elemType, len(values), len(values))
+			for i, value := range values {
+				if err := setWithProperType(elemType.Elem().Kind(), value, slice.Index(i)); err != nil {
+					return err
+				}
+			}
+			elem.Set(slice)
+		case reflect.Struct, reflect.Map:
+			continue
+		default:
+			if err := setWithProperType(elemType.Kind(), values[0], elem); err != nil {
+				return err
+			}
+		}
+		m.SetMapIndex(reflect.ValueOf(k).Convert(typ.Key()), elem)
+	}
+
+	done := make(map[string]struct{})
+	for k := range data {
+		key, _, ok := strings.Cut(k, "[")
+		if !ok || key == "" {
+			continue
+		}
+		if _, ok := done[key]; ok {
+			continue
+		}
+		done[key] = struct{}{}
+
+		nested := nestedData(data, key)
+		if nested == nil {
+			continue
+		}
+
+		elem := reflect.New(elemType).Elem()
+		if elemType.Kind() == reflect.Interface {
+			values := make(map[string]any)
+			if err := bindMap(reflect.ValueOf(&values).Elem(), nested, tag); err != nil {
+				return err
+			}
+			elem.Set(reflect.ValueOf(values))
+		} else {
+			if err := bindNested(elem, nested, tag); err != nil {
+				return err
+			}
+			if elem.IsZero() {
+				continue
+			}
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), elem)
+	}
+	return nil
+}
+
 func setWithProperType(valueKind reflect.Kind, val string, structField reflect.Value) error {
 	// But also call it here, in case we're dealing with an array of BindUnmarshalers
 	if ok, err := unmarshalInputToField(valueKind, val, structField); ok {
@@ -277,8 +536,12 @@ func setUintField(value string, bitSize int, field reflect.Value) error {
 }
 
 func setBoolField(value string, field reflect.Value) error {
-	if value == "" {
+	switch strings.ToLower(value) {
+	case "", "off":
 		value = "false"
+	case "on":
+		// the value of the checked checkbox without the value attribute
+		value = "true"
 	}
 	boolVal, err := strconv.ParseBool(value)
 	if err == nil {
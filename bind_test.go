@@ -4,6 +4,7 @@ import (
 	"errors"
 	"mime/multipart"
 	"net/textproto"
+	"net/url"
 	"reflect"
 	"strconv"
 	"testing"
@@ -287,6 +288,123 @@ func TestBindData_NestedStruct(t *testing.T) {
 	assert.Equal(t, "Acme Corp", result.Company)
 }
 
+func TestBindData_Bracketed(t *testing.T) {
+	type Range struct {
+		Gte int `query:"gte"`
+		Lte int `query:"lte"`
+	}
+	type Filter struct {
+		Status string `query:"status"`
+		Age    Range  `query:"age"`
+	}
+	type Query struct {
+		Filter  Filter            `query:"filter"`
+		Ptr     *Filter           `query:"ptr"`
+		Sort    map[string]string `query:"sort"`
+		Ranges  map[string]Range  `query:"range"`
+		Counts  map[string]int    `query:"count"`
+		Any     map[string]any    `query:"any"`
+		Ignored int               `query:"ignored"`
+	}
+
+	data := map[string][]string{
+		"filter[status]":    {"active"},
+		"filter[age][gte]":  {"30"},
+		"filter[age][lte]":  {"40"},
+		"ptr[status]":       {"new"},
+		"sort[name]":        {"asc"},
+		"range[price][gte]": {"10"},
+		"count[a]":          {"1"},
+		"any[x]":            {"1"},
+		"any[y][z]":         {"2"},
+		"ignored[x]":        {"1"},
+		"filter[]":          {"skipped"},
+	}
+
+	var result Query
+	require.NoError(t, BindData(&result, data, "query", nil))
+
+	assert.Equal(t, Filter{Status: "active", Age: Range{Gte: 30, Lte: 40}}, result.Filter)
+	require.NotNil(t, result.Ptr)
+	assert.Equal(t, "new", result.Ptr.Status)
+	assert.Equal(t, map[string]string{"name": "asc"}, result.Sort)
+	assert.Equal(t, map[string]Range{"price": {Gte: 10}}, result.Ranges)
+	assert.Equal(t, map[string]int{"a": 1}, result.Counts)
+	assert.Equal(t, map[string]any{"x": "1", "y": map[string]any{"z": "2"}}, result.Any)
+	assert.Zero(t, result.Ignored)
+
+	err := BindData(&result, map[string][]string{"filter[age][gte]": {"old"}}, "query", nil)
+	assert.Error(t, err)
+}
+
+func TestBindData_FormConventions(t *testing.T) {
+	type Address struct {
+		City string `form:"city"`
+	}
+	type Item struct {
+		Name    string   `form:"name"`
+		Qty     int      `form:"qty"`
+		Gift    bool     `form:"gift"`
+		Address *Address `form:"address"`
+		Tags    []string `form:"tags"`
+	}
+	type Form struct {
+		Agree     bool                `form:"agree"`
+		Subscribe bool                `form:"subscribe"`
+		Remember  bool                `form:"remember"`
+		Colors    []string            `form:"colors"`
+		IDs       *[]int              `form:"ids"`
+		Items     []Item              `form:"items"`
+		Ptrs      []*Item             `form:"ptrs"`
+		Scores    []int               `form:"scores"`
+		Meta      []map[string]string `form:"meta"`
+	}
+
+	data := map[string][]string{
+		"agree":                {"on"},
+		"subscribe":            {"false", "on"},
+		"remember":             {"off"},
+		"colors[]":             {"red", "green"},
+		"ids[]":                {"1", "2"},
+		"items.0.name":         {"pen"},
+		"items.0.qty":          {"2"},
+		"items.0.gift":         {"on"},
+		"items.0.address.city": {"Kyiv"},
+		"items.0.tags[]":       {"a", "b"},
+		"items.2.name":         {"ink"},
+		"items[1][name]":       {"pad"},
+		"items[1][qty]":        {"5"},
+		"ptrs.0.name":          {"cap"},
+		"scores.1":             {"20"},
+		"scores[0]":            {"10"},
+		"meta.0.k":             {"v"},
+	}
+
+	var result Form
+	require.NoError(t, BindData(&result, data, "form", nil))
+
+	assert.True(t, result.Agree)
+	assert.True(t, result.Subscribe)
+	assert.False(t, result.Remember)
+	assert.Equal(t, []string{"red", "green"}, result.Colors)
+	require.NotNil(t, result.IDs)
+	assert.Equal(t, []int{1, 2}, *result.IDs)
+	assert.Equal(t, []Item{
+		{Name: "pen", Qty: 2, Gift: true, Address: &Address{City: "Kyiv"}, Tags: []string{"a", "b"}},
+		{Name: "pad", Qty: 5},
+		{Name: "ink"},
+	}, result.Items)
+	assert.Equal(t, []*Item{{Name: "cap"}}, result.Ptrs)
+	assert.Equal(t, []int{10, 20}, result.Scores)
+	assert.Equal(t, []map[string]string{{"k": "v"}}, result.Meta)
+
+	err := BindData(&result, map[string][]string{"items.1001.name": {"x"}}, "form", nil)
+	require.EqualError(t, err, "binding slice index 1001 is out of range")
+
+	err = BindData(&result, map[string][]string{"scores.0": {"x"}}, "form", nil)
+	require.Error(t, err)
+}
+
 // TestBindData_CustomUnmarshaler tests custom unmarshaler implementations
 func TestBindData_CustomUnmarshaler(t *testing.T) {
 	customTime := "2023-12-01T10:00:00Z"
@@ -1087,6 +1205,9 @@ func TestSetBoolField(t *testing.T) {
 		{"false", "false", false, false},
 		{"False", "False", false, false},
 		{"0", "0", false, false},
+		{"on", "on", true, false},
+		{"ON", "ON", true, false},
+		{"off", "off", false, false},
 		{"empty", "", false, false},
 		{"invalid", "not-a-bool", false, true},
 	}
@@ -1237,3 +1358,56 @@ func TestBindData_ComplexScenarios(t *testing.T) {
 		assert.Equal(t, "value", *result.NotNilPtr)
 	})
 }
+
+type fuzzBindNested struct {
+	Status string `query:"status"`
+	Min    *int   `query:"min"`
+}
+
+type fuzzBindTarget struct {
+	String   string                    `query:"s"`
+	Int      int                       `query:"i"`
+	Int8     int8                      `query:"i8"`
+	Uint     uint                      `query:"u"`
+	Float    float32                   `query:"f"`
+	Bool     bool                      `query:"b"`
+	Time     time.Time                 `query:"t"`
+	Duration time.Duration             `query:"d"`
+	Ptr      *int                      `query:"p"`
+	Ints     []int                     `query:"ints"`
+	PtrSlice *[]string                 `query:"ps"`
+	Strings  []*string                 `query:"strs"`
+	Nested   fuzzBindNested            `query:"n"`
+	NestedP  *fuzzBindNested           `query:"np"`
+	Map      map[string]int            `query:"m"`
+	Any      map[string]any            `query:"a"`
+	Filters  map[string]fuzzBindNested `query:"filter"`
+	Embedded fuzzBindNested
+}
+
+func FuzzBindData(f *testing.F) {
+	f.Add("s=a&i=1&i8=2&u=3&f=1.5&b=true&t=2024-01-02T03:04:05Z&d=1s&p=4&ints=1&ints=2&ps=x&strs=y")
+	f.Add("n[status]=active&np[min]=1&m[a]=1&a[b][c]=d&filter[x][status]=on&filter[y][min]=2&status=top")
+	f.Add("i=99999999999999999999&i8=300&u=-1&f=NaN&b=maybe&t=yesterday&d=forever&ints=x")
+	f.Add("n[=1&n]=2&n[]=3&[a]=4&m[[x]]=5&a[&a[b]c]=6&filter[][status]=7")
+
+	f.Fuzz(func(t *testing.T, query string) {
+		data, err := url.ParseQuery(query)
+		if err != nil {
+			return
+		}
+
+		// the binder is given the data of the untrusted request, it must return an error
+		// for the malformed values instead of panicking
+		_ = BindData(new(fuzzBindTarget), data, "query", nil)
+		_ = BindData(new(map[string]string), data, "query", nil)
+		_ = BindData(new(map[string][]string), data, "query", nil)
+
+		// the keys without values, which are not produced by the query parser
+		for k := range data {
+			data[k] = nil
+		}
+		_ = BindData(new(fuzzBindTarget), data, "query", nil)
+		_ = BindData(new(map[string]any), data, "query", nil)
+	})
+}
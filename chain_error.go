@@ -0,0 +1,113 @@
+package wo
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gowool/hook"
+)
+
+type ctxChainErrorsKey struct{}
+
+// MiddlewareError attributes an error to the middleware (or the route action) which returned it.
+// The ID is the middleware handler ID or the route pattern in case of the route action.
+type MiddlewareError struct {
+	ID  string
+	Err error
+}
+
+func (e *MiddlewareError) Error() string {
+	return fmt.Sprintf("middleware %q: %v", e.ID, e.Err)
+}
+
+func (e *MiddlewareError) Unwrap() error {
+	return e.Err
+}
+
+// MiddlewareErrors returns the attributed errors from err, which is usually the error passed to
+// the error handler when several middlewares of the chain failed.
+func MiddlewareErrors(err error) []*MiddlewareError {
+	var result []*MiddlewareError
+
+	var walk func(error)
+	walk = func(err error) {
+		switch v := err.(type) {
+		case nil:
+		case *MiddlewareError:
+			result = append(result, v)
+		case interface{ Unwrap() []error }:
+			for _, e := range v.Unwrap() {
+				walk(e)
+			}
+		case interface{ Unwrap() error }:
+			walk(v.Unwrap())
+		}
+	}
+	walk(err)
+
+	return result
+}
+
+// chainErrors records the errors returned by the middlewares of a request chain,
+// so the failures which were replaced on the way up are not lost.
+type chainErrors struct {
+	errs []*MiddlewareError
+	mu   sync.Mutex
+}
+
+func (c *chainErrors) record(id string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, recorded := range c.errs {
+		// the error is propagated or wrapped by an outer middleware
+		if errors.Is(err, recorded.Err) {
+			return
+		}
+	}
+	c.errs = append(c.errs, &MiddlewareError{ID: id, Err: err})
+}
+
+// join returns err as is when it covers all recorded errors, otherwise an attributed
+// multi-error of err followed by the recorded errors it doesn't wrap.
+func (c *chainErrors) join(err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	first := err
+	dropped := make([]error, 0, len(c.errs))
+	for _, recorded := range c.errs {
+		if errors.Is(err, recorded.Err) {
+			if first == err {
+				first = &MiddlewareError{ID: recorded.ID, Err: err}
+			}
+			continue
+		}
+		dropped = append(dropped, recorded)
+	}
+
+	if len(dropped) == 0 {
+		return err
+	}
+	return errors.Join(append([]error{first}, dropped...)...)
+}
+
+func recordChainError[T Resolver](e T, id string, err error) {
+	if c, ok := e.Request().Context().Value(ctxChainErrorsKey{}).(*chainErrors); ok {
+		c.record(id, err)
+	}
+}
+
+// attributed returns a copy of the middleware handler which records its errors.
+func attributed[T Resolver](h *hook.Handler[T]) *hook.Handler[T] {
+	a := &hook.Handler[T]{ID: h.ID, Priority: h.Priority}
+	a.Func = func(e T) error {
+		err := h.Func(e)
+		if err != nil {
+			recordChainError(e, a.ID, err)
+		}
+		return err
+	}
+	return a
+}
@@ -0,0 +1,93 @@
+package wo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveChain(t *testing.T, setup func(*Router[*Event])) error {
+	t.Helper()
+
+	var handled error
+	router := New[*Event](eventFactory, func(_ *Event, err error) { handled = err })
+	setup(router)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	return handled
+}
+
+func TestRouter_ChainErrors(t *testing.T) {
+	actionErr := errors.New("action failed")
+	cleanupErr := errors.New("cleanup failed")
+
+	t.Run("single error is passed as is", func(t *testing.T) {
+		err := serveChain(t, func(r *Router[*Event]) {
+			r.Bind(&hook.Handler[*Event]{ID: "auth", Func: func(e *Event) error { return e.Next() }})
+			r.GET("/", func(*Event) error { return actionErr })
+		})
+		assert.Same(t, actionErr, err)
+	})
+
+	t.Run("wrapped error is not duplicated", func(t *testing.T) {
+		err := serveChain(t, func(r *Router[*Event]) {
+			r.Bind(&hook.Handler[*Event]{ID: "tx", Func: func(e *Event) error {
+				if err := e.Next(); err != nil {
+					return fmt.Errorf("tx: %w", err)
+				}
+				return nil
+			}})
+			r.GET("/", func(*Event) error { return actionErr })
+		})
+		assert.EqualError(t, err, "tx: action failed")
+		assert.Empty(t, MiddlewareErrors(err))
+	})
+
+	t.Run("replaced errors are aggregated", func(t *testing.T) {
+		err := serveChain(t, func(r *Router[*Event]) {
+			r.Pre(&hook.Handler[*Event]{ID: "session", Func: func(e *Event) error {
+				_ = e.Next()
+				return cleanupErr
+			}})
+			r.Bind(&hook.Handler[*Event]{ID: "tx", Func: func(e *Event) error {
+				_ = e.Next()
+				return ErrConflict
+			}})
+			r.GET("/", func(*Event) error { return actionErr })
+		})
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, cleanupErr)
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.ErrorIs(t, err, actionErr)
+
+		errs := MiddlewareErrors(err)
+		require.Len(t, errs, 3)
+		assert.Equal(t, "session", errs[0].ID)
+		assert.Same(t, cleanupErr, errs[0].Err)
+		assert.Equal(t, "GET /", errs[1].ID)
+		assert.Same(t, actionErr, errs[1].Err)
+		assert.Equal(t, "tx", errs[2].ID)
+		assert.Equal(t, `middleware "session": cleanup failed`, errs[0].Error())
+	})
+
+	t.Run("handled errors are not reported", func(t *testing.T) {
+		err := serveChain(t, func(r *Router[*Event]) {
+			r.Bind(&hook.Handler[*Event]{ID: "recover", Func: func(e *Event) error {
+				_ = e.Next()
+				return nil
+			}})
+			r.GET("/", func(*Event) error { return actionErr })
+		})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// CircuitOpenError is returned when the circuit breaker of the host is open, the request
+// is not sent.
+type CircuitOpenError struct {
+	Host string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return "client: circuit breaker of " + e.Host + " is open"
+}
+
+type BreakerConfig struct {
+	// Threshold is the number of the consecutive failures, the transport errors and the server
+	// error responses (5xx), which open the circuit breaker of the host. If less than 0, the
+	// circuit breakers are disabled.
+	//
+	// Default: 5
+	Threshold int `env:"THRESHOLD" json:"threshold,omitempty" yaml:"threshold,omitempty"`
+
+	// Cooldown is the time the circuit breaker stays open, then a single probe request is let
+	// through: its success closes the circuit breaker, its failure opens it again.
+	//
+	// Default: 30s
+	Cooldown time.Duration `env:"COOLDOWN" json:"cooldown,omitempty,format:units" yaml:"cooldown,omitempty"`
+
+	// TimeFunc returns the current time.
+	//
+	// Default: time.Now
+	TimeFunc func() time.Time `json:"-" yaml:"-"`
+}
+
+func (c *BreakerConfig) SetDefaults() {
+	if c.Threshold == 0 {
+		c.Threshold = 5
+	}
+	if c.Cooldown == 0 {
+		c.Cooldown = 30 * time.Second
+	}
+	if c.TimeFunc == nil {
+		c.TimeFunc = time.Now
+	}
+}
+
+func (c *BreakerConfig) Validate() error {
+	if c.Cooldown < 0 {
+		return errors.New("client: breaker cooldown must not be negative")
+	}
+	return nil
+}
+
+type breakerState uint8
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breaker struct {
+	cfg      BreakerConfig
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newBreaker(cfg BreakerConfig) *breaker {
+	return &breaker{cfg: cfg}
+}
+
+// allow reports whether the request may be sent.
+func (b *breaker) allow() bool {
+	if b.cfg.Threshold < 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.cfg.TimeFunc().Sub(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		// let the probe through
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// the probe is in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// record records the result of the sent request.
+func (b *breaker) record(success bool) {
+	if b.cfg.Threshold < 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state, b.failures = breakerClosed, 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.cfg.Threshold {
+		b.state, b.openedAt = breakerOpen, b.cfg.TimeFunc()
+	}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	b := newBreaker(BreakerConfig{Threshold: 2, Cooldown: time.Minute, TimeFunc: func() time.Time { return now }})
+
+	// the success resets the consecutive failures
+	assert.True(t, b.allow())
+	b.record(false)
+	b.record(true)
+	b.record(false)
+	assert.True(t, b.allow())
+
+	b.record(false)
+	assert.False(t, b.allow())
+
+	// a single probe is let through after the cooldown, its failure opens the breaker again
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+	assert.False(t, b.allow())
+	b.record(false)
+	assert.False(t, b.allow())
+
+	// the successful probe closes the breaker
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow())
+	b.record(true)
+	assert.True(t, b.allow())
+	assert.True(t, b.allow())
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := newBreaker(BreakerConfig{Threshold: -1})
+	for range 10 {
+		b.record(false)
+	}
+	assert.True(t, b.allow())
+}
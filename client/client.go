@@ -0,0 +1,194 @@
+// Package client provides the outbound HTTP client for the service-to-service calls, which
+// inherit the metadata of the incoming request: the request ID and the trace headers are
+// forwarded and the deadline is respected. The failed idempotent requests are retried with
+// the exponential backoff, and the failing hosts are cut off by the circuit breakers:
+//
+//	c := client.New(client.Config{})
+//
+//	r.GET("/orders/{id}", func(e *wo.Event) error {
+//		req, err := http.NewRequest(http.MethodGet, "http://billing/invoices/"+e.Param("id"), nil)
+//		if err != nil {
+//			return err
+//		}
+//		res, err := c.Do(e.Context(), req)
+//		...
+//	})
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo"
+)
+
+type Config struct {
+	// Timeout is the timeout of a request, the retries included. The deadline of the
+	// request context applies too.
+	//
+	// Default: 30s
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// ForwardHeaders are the headers of the incoming request (see [wo.IncomingRequest]) copied to
+	// the outbound requests, unless they are already set.
+	//
+	// Default: [X-Request-Id, Traceparent, Tracestate, Baggage]
+	ForwardHeaders []string `env:"FORWARD_HEADERS" json:"forwardHeaders,omitempty" yaml:"forwardHeaders,omitempty"`
+
+	// DeadlineHeader is the header of the outbound requests carrying the time left until the
+	// deadline of the request context in milliseconds, so the downstream services can give up
+	// in time. If empty, the header is not sent.
+	DeadlineHeader string `env:"DEADLINE_HEADER" json:"deadlineHeader,omitempty" yaml:"deadlineHeader,omitempty"`
+
+	// Retry is the retry policy.
+	Retry RetryConfig `envPrefix:"RETRY_" json:"retry,omitempty" yaml:"retry,omitempty"`
+
+	// Breaker is the policy of the per-host circuit breakers.
+	Breaker BreakerConfig `envPrefix:"BREAKER_" json:"breaker,omitempty" yaml:"breaker,omitempty"`
+
+	// Transport is the transport sending the requests.
+	//
+	// Default: http.DefaultTransport
+	Transport http.RoundTripper `json:"-" yaml:"-"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.Timeout == 0 {
+		c.Timeout = 30 * time.Second
+	}
+	if len(c.ForwardHeaders) == 0 {
+		c.ForwardHeaders = []string{wo.HeaderXRequestID, "Traceparent", "Tracestate", "Baggage"}
+	}
+	if c.Transport == nil {
+		c.Transport = http.DefaultTransport
+	}
+	c.Retry.SetDefaults()
+	c.Breaker.SetDefaults()
+}
+
+func (c *Config) Validate() error {
+	if c.Timeout < 0 {
+		return errors.New("client: timeout must not be negative")
+	}
+	if err := c.Retry.Validate(); err != nil {
+		return err
+	}
+	return c.Breaker.Validate()
+}
+
+// Client sends the outbound requests, it's safe for the concurrent use.
+type Client struct {
+	cfg      Config
+	client   *http.Client
+	breakers sync.Map // host -> *breaker
+}
+
+// New returns the client, it panics if the config is invalid.
+func New(cfg Config) *Client {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	c := &Client{cfg: cfg}
+	c.client = &http.Client{Transport: transport{c}, Timeout: cfg.Timeout}
+	return c
+}
+
+// HTTPClient returns the [http.Client] sending the requests through the client, ex. for the
+// SDKs accepting a custom client. The metadata is taken from the request context.
+func (c *Client) HTTPClient() *http.Client {
+	return c.client
+}
+
+// Do sends the request with ctx, ex. [wo.Event.Context], as its context.
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	return c.client.Do(req.WithContext(ctx))
+}
+
+// Get sends the GET request to the URL with ctx as its context.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.Do(req)
+}
+
+type transport struct {
+	c *Client
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = t.c.propagate(req)
+	b := t.c.breaker(req.URL.Host)
+
+	for attempt := 0; ; attempt++ {
+		if !b.allow() {
+			return nil, &CircuitOpenError{Host: req.URL.Host}
+		}
+
+		res, err := t.c.cfg.Transport.RoundTrip(req)
+		b.record(err == nil && res.StatusCode < http.StatusInternalServerError)
+
+		delay, retry := t.c.cfg.Retry.next(req, res, err, attempt)
+		if !retry {
+			return res, err
+		}
+
+		next, rerr := rewind(req)
+		if rerr != nil {
+			// the body can't be sent again, the last result is returned
+			return res, err
+		}
+		if res != nil {
+			drain(res)
+		}
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		req = next
+	}
+}
+
+// propagate returns the copy of the request with the metadata of the incoming request.
+func (c *Client) propagate(req *http.Request) *http.Request {
+	ctx := req.Context()
+	in, hasIn := wo.IncomingRequest(ctx)
+	deadline, hasDeadline := ctx.Deadline()
+
+	if !hasIn && (!hasDeadline || c.cfg.DeadlineHeader == "") {
+		return req
+	}
+
+	out := req.Clone(ctx)
+	if hasIn {
+		for _, name := range c.cfg.ForwardHeaders {
+			if out.Header.Get(name) != "" {
+				continue
+			}
+			if values := in.Header.Values(name); len(values) > 0 {
+				out.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+			}
+		}
+	}
+	if hasDeadline && c.cfg.DeadlineHeader != "" {
+		left := max(time.Until(deadline).Milliseconds(), 0)
+		out.Header.Set(c.cfg.DeadlineHeader, strconv.FormatInt(left, 10))
+	}
+	return out
+}
+
+func (c *Client) breaker(host string) *breaker {
+	if b, ok := c.breakers.Load(host); ok {
+		return b.(*breaker)
+	}
+	b, _ := c.breakers.LoadOrStore(host, newBreaker(c.cfg.Breaker))
+	return b.(*breaker)
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestClient_Propagation(t *testing.T) {
+	var got http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	c := New(Config{DeadlineHeader: "X-Request-Timeout"})
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, func(e *wo.Event, err error) {
+		e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+	})
+	router.GET("/orders", func(e *wo.Event) error {
+		ctx, cancel := context.WithTimeout(e.Context(), time.Minute)
+		defer cancel()
+
+		req, err := http.NewRequest(http.MethodGet, upstream.URL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Baggage", "own")
+
+		res, err := c.Do(ctx, req)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(wo.HeaderXRequestID, "req-1")
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("Baggage", "incoming")
+	req.Header.Set(wo.HeaderAuthorization, "Bearer secret")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Equal(t, "req-1", got.Get(wo.HeaderXRequestID))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", got.Get("Traceparent"))
+	assert.Equal(t, "own", got.Get("Baggage"))
+	assert.Empty(t, got.Get(wo.HeaderAuthorization))
+	assert.NotEmpty(t, got.Get("X-Request-Timeout"))
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		header   http.Header
+		statuses []int
+		attempts int32
+		status   int
+	}{
+		{name: "retried", method: http.MethodGet, statuses: []int{503, 502, 200}, attempts: 3, status: 200},
+		{name: "exhausted", method: http.MethodGet, statuses: []int{503, 503, 503, 200}, attempts: 3, status: 503},
+		{name: "not retried status", method: http.MethodGet, statuses: []int{500, 200}, attempts: 1, status: 500},
+		{name: "not idempotent", method: http.MethodPost, body: "a", statuses: []int{503, 200}, attempts: 1, status: 503},
+		{name: "idempotency key", method: http.MethodPost, body: "a", header: http.Header{"Idempotency-Key": {"k"}}, statuses: []int{503, 200}, attempts: 2, status: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := attempts.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, tt.body, string(body))
+				if tt.statuses[n-1] == http.StatusServiceUnavailable {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			c := New(Config{Retry: RetryConfig{Backoff: time.Millisecond, MaxBackoff: time.Millisecond}})
+
+			req, err := http.NewRequest(tt.method, srv.URL, strings.NewReader(tt.body))
+			require.NoError(t, err)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+
+			res, err := c.Do(context.Background(), req)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			assert.Equal(t, tt.status, res.StatusCode)
+			assert.Equal(t, tt.attempts, attempts.Load())
+		})
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := New(Config{Breaker: BreakerConfig{Threshold: 2, Cooldown: time.Hour}})
+
+	for range 2 {
+		res, err := c.Get(context.Background(), srv.URL)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	}
+
+	_, err := c.Get(context.Background(), srv.URL)
+	var circuitOpen *CircuitOpenError
+	require.ErrorAs(t, err, &circuitOpen)
+	assert.Equal(t, strings.TrimPrefix(srv.URL, "http://"), circuitOpen.Host)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "defaults"},
+		{name: "timeout", cfg: Config{Timeout: -1}, err: "client: timeout must not be negative"},
+		{name: "attempts", cfg: Config{Retry: RetryConfig{MaxAttempts: -1}}, err: "client: max attempts must be at least 1"},
+		{name: "backoff", cfg: Config{Retry: RetryConfig{Backoff: time.Second, MaxBackoff: time.Millisecond}}, err: "client: invalid backoff"},
+		{name: "cooldown", cfg: Config{Breaker: BreakerConfig{Cooldown: -1}}, err: "client: breaker cooldown must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.SetDefaults()
+			err := tt.cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+			assert.Panics(t, func() { New(tt.cfg) })
+		})
+	}
+}
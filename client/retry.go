@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of the attempts of a request, 1 disables the retries.
+	//
+	// Default: 3
+	MaxAttempts int `env:"MAX_ATTEMPTS" json:"maxAttempts,omitempty" yaml:"maxAttempts,omitempty"`
+
+	// Backoff is the base delay of the exponential backoff, the delay before the n-th retry is
+	// picked at random up to Backoff * 2^(n-1), capped by MaxBackoff.
+	//
+	// Default: 100ms
+	Backoff time.Duration `env:"BACKOFF" json:"backoff,omitempty,format:units" yaml:"backoff,omitempty"`
+
+	// MaxBackoff is the maximum delay before a retry, the Retry-After delays included.
+	//
+	// Default: 2s
+	MaxBackoff time.Duration `env:"MAX_BACKOFF" json:"maxBackoff,omitempty,format:units" yaml:"maxBackoff,omitempty"`
+
+	// Statuses are the response statuses which are retried.
+	//
+	// Default: [429, 502, 503, 504]
+	Statuses []int `env:"STATUSES" json:"statuses,omitempty" yaml:"statuses,omitempty"`
+
+	// Methods are the request methods which are retried, the requests with the Idempotency-Key
+	// header are retried regardless of the method.
+	//
+	// Default: [GET, HEAD, OPTIONS, PUT, DELETE]
+	Methods []string `env:"METHODS" json:"methods,omitempty" yaml:"methods,omitempty"`
+}
+
+func (c *RetryConfig) SetDefaults() {
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 3
+	}
+	if c.Backoff == 0 {
+		c.Backoff = 100 * time.Millisecond
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = 2 * time.Second
+	}
+	if len(c.Statuses) == 0 {
+		c.Statuses = []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}
+	}
+	if len(c.Methods) == 0 {
+		c.Methods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete}
+	}
+}
+
+func (c *RetryConfig) Validate() error {
+	if c.MaxAttempts < 1 {
+		return errors.New("client: max attempts must be at least 1")
+	}
+	if c.Backoff < 0 || c.MaxBackoff < c.Backoff {
+		return errors.New("client: invalid backoff")
+	}
+	return nil
+}
+
+// next reports whether the attempt is retried, and the delay before the retry.
+func (c *RetryConfig) next(req *http.Request, res *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt+1 >= c.MaxAttempts || req.Context().Err() != nil {
+		return 0, false
+	}
+	if !slices.Contains(c.Methods, req.Method) && req.Header.Get("Idempotency-Key") == "" {
+		return 0, false
+	}
+
+	switch {
+	case err != nil:
+	case slices.Contains(c.Statuses, res.StatusCode):
+		if delay, ok := retryAfter(res); ok {
+			return min(delay, c.MaxBackoff), true
+		}
+	default:
+		return 0, false
+	}
+
+	ceiling := c.MaxBackoff
+	if attempt < 32 && c.Backoff <= c.MaxBackoff>>attempt {
+		ceiling = c.Backoff << attempt
+	}
+	if ceiling <= 0 {
+		return 0, true
+	}
+	return rand.N(ceiling + 1), true
+}
+
+// retryAfter parses the Retry-After header, the seconds or the HTTP date.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// rewind returns the copy of the request with the body reset for the retry.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("client: request body can't be rewound")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	out := *req
+	out.Body = body
+	return &out, nil
+}
+
+// drain reads the rest of the response body, so the connection can be reused.
+func drain(res *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	_ = res.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,81 @@
+// Package clock provides the clock of the time-dependent subsystems, ex. the session expiry,
+// so the tests control the time deterministically with the [Mock] clock:
+//
+//	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+//	s := session.New(session.Config{Lifetime: time.Hour, Clock: clk}, store)
+//	...
+//	clk.Add(2 * time.Hour) // the session is expired
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock returns the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Func is an adapter to allow the use of ordinary functions as [Clock].
+type Func func() time.Time
+
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// System is the clock of the system time.
+var System Clock = Func(time.Now)
+
+// Or returns the clock, or [System] if the clock is nil.
+func Or(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+	return c
+}
+
+// Since returns the time elapsed since t by the clock.
+func Since(c Clock, t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Until returns the duration until t by the clock.
+func Until(c Clock, t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Mock is the clock which time is set manually, it's safe for concurrent use.
+type Mock struct {
+	now time.Time
+	mu  sync.RWMutex
+}
+
+// NewMock returns the mock clock set to now.
+func NewMock(now time.Time) *Mock {
+	return &Mock{now: now}
+}
+
+func (m *Mock) Now() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.now
+}
+
+// Set sets the time of the clock.
+func (m *Mock) Set(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.now = now
+}
+
+// Add advances the time of the clock by d and returns the new time.
+func (m *Mock) Add(d time.Duration) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.now = m.now.Add(d)
+	return m.now
+}
@@ -0,0 +1,29 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMock(start)
+
+	assert.Equal(t, start, m.Now())
+	assert.Equal(t, start.Add(time.Hour), m.Add(time.Hour))
+	assert.Equal(t, time.Hour, Since(m, start))
+	assert.Equal(t, -time.Hour, Until(m, start))
+
+	m.Set(start)
+	assert.Equal(t, start, m.Now())
+}
+
+func TestOr(t *testing.T) {
+	m := NewMock(time.Time{})
+
+	assert.Same(t, m, Or(m))
+	assert.NotNil(t, Or(nil))
+	assert.WithinDuration(t, time.Now(), Or(nil).Now(), time.Second)
+}
@@ -0,0 +1,304 @@
+// Package config loads the configs of the middlewares and the server from YAML, JSON and the
+// environment variables, then calls SetDefaults and Validate on every nested config uniformly:
+//
+//	var cfg struct {
+//		Server   server.Config              `envPrefix:"SERVER_" json:"server" yaml:"server"`
+//		CORS     middleware.CORSConfig      `envPrefix:"CORS_" json:"cors" yaml:"cors"`
+//		Compress middleware.CompressConfig  `envPrefix:"COMPRESS_" json:"compress" yaml:"compress"`
+//	}
+//
+//	err := config.Load(&cfg, config.File("config.yaml"), config.Env("APP_"))
+//
+// The sources are applied in order, so the later ones override the earlier ones. The validation
+// errors are reported with the field paths, ex. "server.transport.maxHeaderBytes: must be no less than 0".
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/validation"
+	"gopkg.in/yaml.v3"
+)
+
+// Source fills the config, dst is the non-nil pointer to the config struct.
+type Source func(dst any) error
+
+// FieldError is the error of the config field.
+type FieldError struct {
+	// Path is the dot separated path of the field, ex. "server.listeners[0].address",
+	// empty for the root config.
+	Path string
+
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+type defaulter interface {
+	SetDefaults()
+}
+
+type validator interface {
+	Validate() error
+}
+
+// Load applies the sources to dst in order, then prepares it, see [Prepare].
+func Load(dst any, sources ...Source) error {
+	if err := checkTarget(dst); err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		if err := source(dst); err != nil {
+			return err
+		}
+	}
+	return Prepare(dst)
+}
+
+// Prepare calls SetDefaults and then Validate of dst and its nested configs. SetDefaults is called
+// on the parents before the children, so a parent can override the children defaults. Validate is
+// called on the outermost configs only, the nested configs are expected to be validated by their
+// parents, ex. with [validation.ValidateStruct]. The errors are joined [FieldError]s sorted by path.
+func Prepare(dst any) error {
+	if err := checkTarget(dst); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(dst).Elem()
+	setDefaults(v)
+
+	var errs []error
+	validate(v, "", &errs)
+	slices.SortStableFunc(errs, func(a, b error) int {
+		return strings.Compare(a.(*FieldError).Path, b.(*FieldError).Path)
+	})
+	return errors.Join(errs...)
+}
+
+// YAML decodes the YAML document, the unknown fields are errors.
+func YAML(data []byte) Source {
+	return func(dst any) error {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(dst); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config: yaml: %w", err)
+		}
+		return nil
+	}
+}
+
+// JSON decodes the JSON document, the unknown fields are errors.
+func JSON(data []byte) Source {
+	return func(dst any) error {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(dst); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("config: json: %w", err)
+		}
+		return nil
+	}
+}
+
+// File decodes the YAML (.yaml, .yml) or JSON (.json) file by its extension.
+func File(name string) Source {
+	return func(dst any) error {
+		var source func([]byte) Source
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".yaml", ".yml":
+			source = YAML
+		case ".json":
+			source = JSON
+		default:
+			return fmt.Errorf("config: unsupported file format %q", name)
+		}
+
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+
+		if err = source(data)(dst); err != nil {
+			return fmt.Errorf("%w (%s)", err, name)
+		}
+		return nil
+	}
+}
+
+func checkTarget(dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: %T is not a pointer to struct", dst)
+	}
+	return nil
+}
+
+func setDefaults(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			setDefaults(v.Elem())
+		}
+	case reflect.Struct:
+		if d, ok := v.Addr().Interface().(defaulter); ok {
+			d.SetDefaults()
+		}
+		for _, f := range fields(v.Type()) {
+			setDefaults(v.Field(f.Index))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			setDefaults(v.Index(i))
+		}
+	case reflect.Map:
+		if !hasStruct(v.Type().Elem()) {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			setDefaults(elem)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	default:
+	}
+}
+
+func validate(v reflect.Value, path string, errs *[]error) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			validate(v.Elem(), path, errs)
+		}
+	case reflect.Struct:
+		if val, ok := v.Addr().Interface().(validator); ok {
+			if err := val.Validate(); err != nil {
+				fieldErrors(err, path, errs)
+			}
+			return
+		}
+		for _, f := range fields(v.Type()) {
+			validate(v.Field(f.Index), join(path, f.Name), errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			validate(v.Index(i), path+"["+strconv.Itoa(i)+"]", errs)
+		}
+	case reflect.Map:
+		if !hasStruct(v.Type().Elem()) {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			validate(elem, join(path, fmt.Sprint(iter.Key().Interface())), errs)
+		}
+	default:
+	}
+}
+
+// fieldErrors flattens the nested [validation.Errors] to the field errors.
+func fieldErrors(err error, path string, errs *[]error) {
+	var verrs validation.Errors
+	if !errors.As(err, &verrs) || len(verrs) == 0 {
+		*errs = append(*errs, &FieldError{Path: path, Err: err})
+		return
+	}
+
+	for key, err := range verrs {
+		if _, err1 := strconv.Atoi(key); err1 == nil {
+			fieldErrors(err, path+"["+key+"]", errs)
+		} else {
+			fieldErrors(err, join(path, key), errs)
+		}
+	}
+}
+
+func join(path, name string) string {
+	if name == "" {
+		return path
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+type field struct {
+	Name  string
+	Index int
+}
+
+// fields returns the exported fields of the struct, the embedded structs have no name, so their
+// fields are reported as the parent ones. The name is taken from the json tag, then from the yaml
+// tag, the fields ignored by both are skipped.
+func fields(t reflect.Type) []field {
+	result := make([]field, 0, t.NumField())
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, ignored := tagName(f)
+		if ignored {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		if f.Anonymous && name == f.Name {
+			name = ""
+		}
+		result = append(result, field{Name: name, Index: i})
+	}
+	return result
+}
+
+func tagName(f reflect.StructField) (string, bool) {
+	jsonName, jsonOK := f.Tag.Lookup("json")
+	yamlName, yamlOK := f.Tag.Lookup("yaml")
+
+	jsonName, _, _ = strings.Cut(jsonName, ",")
+	yamlName, _, _ = strings.Cut(yamlName, ",")
+
+	if jsonOK && jsonName == "-" && (!yamlOK || yamlName == "-") {
+		return "", true
+	}
+	if jsonName != "" && jsonName != "-" {
+		return jsonName, false
+	}
+	if yamlName != "" && yamlName != "-" {
+		return yamlName, false
+	}
+	return "", false
+}
+
+func hasStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
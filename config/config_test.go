@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/server"
+)
+
+type appConfig struct {
+	Server   server.Config     `envPrefix:"SERVER_" json:"server" yaml:"server"`
+	Compress compressConfig    `envPrefix:"COMPRESS_" json:"compress" yaml:"compress"`
+	Backends []backendConfig   `envPrefix:"BACKENDS_" json:"backends" yaml:"backends"`
+	Cache    *cacheConfig      `envPrefix:"CACHE_" json:"cache,omitempty" yaml:"cache,omitempty"`
+	Tags     map[string]string `env:"TAGS" json:"tags,omitempty" yaml:"tags,omitempty"`
+	Ignored  func()            `json:"-" yaml:"-"`
+}
+
+type compressConfig struct {
+	Level     int `env:"LEVEL" json:"level,omitempty" yaml:"level,omitempty"`
+	MinLength int `env:"MIN_LENGTH" json:"minLength,omitempty" yaml:"minLength,omitempty"`
+}
+
+func (c *compressConfig) SetDefaults() {
+	if c.Level == 0 {
+		c.Level = -1
+	}
+	if c.MinLength <= 0 {
+		c.MinLength = 1024
+	}
+}
+
+func (c *compressConfig) Validate() error {
+	if c.Level < -2 || c.Level > 9 {
+		return errors.New("invalid gzip level")
+	}
+	return nil
+}
+
+type backendConfig struct {
+	URL     string        `env:"URL" json:"url" yaml:"url"`
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout" yaml:"timeout"`
+}
+
+func (c *backendConfig) SetDefaults() {
+	if c.Timeout == 0 {
+		c.Timeout = 5 * time.Second
+	}
+}
+
+func (c backendConfig) Validate() error {
+	if c.URL == "" {
+		return errors.New("url is required")
+	}
+	return nil
+}
+
+type cacheConfig struct {
+	Size  int      `env:"SIZE" json:"size" yaml:"size"`
+	Hosts []string `env:"HOSTS" json:"hosts" yaml:"hosts"`
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("APP_SERVER_TRANSPORT_IDLE_TIMEOUT", "30s")
+	t.Setenv("APP_COMPRESS_LEVEL", "5")
+	t.Setenv("APP_BACKENDS_1_URL", "http://b")
+	t.Setenv("APP_CACHE_HOSTS", "a, b")
+	t.Setenv("APP_TAGS", "env:prod,team:core")
+
+	var cfg appConfig
+	err := Load(&cfg,
+		YAML([]byte(`
+server:
+  address: ":9000"
+compress:
+  level: 1
+backends:
+  - url: http://a
+    timeout: 1s
+`)),
+		Env("APP_"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9000", cfg.Server.Address)
+	assert.Equal(t, 30*time.Second, cfg.Server.Transport.IdleTimeout)
+	assert.Equal(t, 10*time.Second, cfg.Server.Transport.ReadHeaderTimeout)
+	assert.Equal(t, uint(250), cfg.Server.HTTP2.MaxConcurrentStreams)
+	assert.Equal(t, 5, cfg.Compress.Level)
+	assert.Equal(t, 1024, cfg.Compress.MinLength)
+	assert.Equal(t, []backendConfig{
+		{URL: "http://a", Timeout: time.Second},
+		{URL: "http://b", Timeout: 5 * time.Second},
+	}, cfg.Backends)
+	require.NotNil(t, cfg.Cache)
+	assert.Equal(t, []string{"a", "b"}, cfg.Cache.Hosts)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "core"}, cfg.Tags)
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	var cfg appConfig
+	err := Load(&cfg, JSON([]byte(`{
+		"server": {"transport": {"maxHeaderBytes": -1}, "listeners": [{"network": "unix"}]},
+		"compress": {"level": 10},
+		"backends": [{"url": "http://a"}, {}]
+	}`)))
+	require.Error(t, err)
+
+	assert.Equal(t, "backends[1]: url is required\n"+
+		"compress: invalid gzip level\n"+
+		"server.listeners[0].address: cannot be blank\n"+
+		"server.transport.maxHeaderBytes: must be no less than 0", err.Error())
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "backends[1]", fieldErr.Path)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(yamlFile, []byte("unknown: 1\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		dst     any
+		sources []Source
+		err     string
+	}{
+		{
+			name: "not a pointer",
+			dst:  appConfig{},
+			err:  "config: config.appConfig is not a pointer to struct",
+		},
+		{
+			name:    "unknown yaml field",
+			dst:     &appConfig{},
+			sources: []Source{File(yamlFile)},
+			err:     "field unknown not found",
+		},
+		{
+			name:    "unknown json field",
+			dst:     &appConfig{},
+			sources: []Source{JSON([]byte(`{"unknown": 1}`))},
+			err:     `config: json: json: unknown field "unknown"`,
+		},
+		{
+			name:    "unsupported file",
+			dst:     &appConfig{},
+			sources: []Source{File("config.toml")},
+			err:     `config: unsupported file format "config.toml"`,
+		},
+		{
+			name:    "missing file",
+			dst:     &appConfig{},
+			sources: []Source{File(filepath.Join(dir, "missing.json"))},
+			err:     "no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, Load(tt.dst, tt.sources...), tt.err)
+		})
+	}
+}
+
+func TestEnv_InvalidValue(t *testing.T) {
+	t.Setenv("APP_COMPRESS_LEVEL", "fast")
+
+	var cfg appConfig
+	err := Env("APP_")(&cfg)
+	assert.ErrorContains(t, err, "config: env APP_COMPRESS_LEVEL: ")
+}
+
+func TestEnv_NilPointerIsKept(t *testing.T) {
+	var cfg appConfig
+	require.NoError(t, Env("APP_")(&cfg))
+
+	assert.Nil(t, cfg.Cache)
+	assert.Nil(t, cfg.Server.TLS)
+	assert.Empty(t, cfg.Backends)
+}
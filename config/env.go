@@ -0,0 +1,209 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// Env sets the fields tagged with `env:"NAME"` from the environment variables named prefix+NAME.
+// The nested configs tagged with `envPrefix:"NESTED_"` are set from prefix+NESTED_NAME, the nil
+// pointers are allocated only if any of their variables is set, and the slices of configs are set
+// from prefix+NESTED_0_NAME, prefix+NESTED_1_NAME and so on.
+//
+// The slices and the maps of the scalars are comma separated, ex. "a,b" and "k1:v1,k2:v2".
+// The values implementing [encoding.TextUnmarshaler] and [time.Duration] are supported.
+func Env(prefix string) Source {
+	return func(dst any) error {
+		if err := checkTarget(dst); err != nil {
+			return err
+		}
+		_, err := loadEnv(reflect.ValueOf(dst).Elem(), prefix)
+		return err
+	}
+}
+
+// loadEnv sets the struct fields and reports whether any of them was set.
+func loadEnv(v reflect.Value, prefix string) (bool, error) {
+	var set bool
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+
+		if name, ok := f.Tag.Lookup("env"); ok && name != "" && name != "-" {
+			key := prefix + name
+			value, ok := os.LookupEnv(key)
+			if !ok {
+				continue
+			}
+			if err := setValue(fv, value); err != nil {
+				return set, fmt.Errorf("config: env %s: %w", key, err)
+			}
+			set = true
+			continue
+		}
+
+		nested, ok := f.Tag.Lookup("envPrefix")
+		if !ok {
+			if !f.Anonymous {
+				continue
+			}
+			nested = ""
+		}
+
+		ok, err := loadEnvNested(fv, prefix+nested)
+		if err != nil {
+			return set, err
+		}
+		set = set || ok
+	}
+	return set, nil
+}
+
+func loadEnvNested(v reflect.Value, prefix string) (bool, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		return loadEnv(v, prefix)
+	case reflect.Pointer:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return false, nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			elem.Elem().Set(v.Elem())
+		}
+		set, err := loadEnv(elem.Elem(), prefix)
+		if set && err == nil {
+			v.Set(elem)
+		}
+		return set, err
+	case reflect.Slice:
+		var set bool
+		for i := 0; ; i++ {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if i < v.Len() {
+				elem.Set(v.Index(i))
+			}
+			ok, err := loadEnvNested(elem, prefix+strconv.Itoa(i)+"_")
+			if err != nil {
+				return set, err
+			}
+			if !ok {
+				if i >= v.Len() {
+					return set, nil
+				}
+				continue
+			}
+			set = true
+			if i < v.Len() {
+				v.Index(i).Set(elem)
+			} else {
+				v.Set(reflect.Append(v, elem))
+			}
+		}
+	default:
+		return false, nil
+	}
+}
+
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), value)
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeFor[time.Duration]() {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		n, err := strconv.ParseInt(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		items := split(value)
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(s.Index(i), item); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Map:
+		items := split(value)
+		m := reflect.MakeMapWithSize(v.Type(), len(items))
+		for _, item := range items {
+			k, val, ok := strings.Cut(item, ":")
+			if !ok {
+				return fmt.Errorf("invalid map item %q", item)
+			}
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := setValue(key, strings.TrimSpace(k)); err != nil {
+				return err
+			}
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, strings.TrimSpace(val)); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, elem)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func split(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
@@ -6,6 +6,7 @@ const (
 	IndexPage           = "index.html"
 	StaticWildcardParam = "path"
 	DefaultMaxMemory    = 32 << 20 // 32mb
+	DefaultMaxBodyBytes = 10 << 20 // 10mb, see Event.BodyBytes
 	keyPretty           = "&pretty="
 	defaultIndent       = "  "
 )
@@ -31,6 +32,10 @@ const (
 	MIMEOctetStream                      = "application/octet-stream"
 	MIMEEventStream                      = "text/event-stream"
 	MIMEApplicationZip                   = "application/zip"
+	MIMEApplicationNDJSON                = "application/x-ndjson"
+	MIMETextCSV                          = "text/csv"
+	MIMETextCSVCharsetUTF8               = MIMETextCSV + "; " + CharsetUTF8
+	MIMEApplicationXLSX                  = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
 )
 
 // Headers
@@ -46,18 +51,23 @@ const (
 	HeaderAuthorization       = "Authorization"
 	HeaderContentDisposition  = "Content-Disposition"
 	HeaderContentEncoding     = "Content-Encoding"
+	HeaderContentLanguage     = "Content-Language"
 	HeaderContentLength       = "Content-Length"
 	HeaderContentType         = "Content-Type"
 	HeaderCookie              = "Cookie"
 	HeaderSetCookie           = "Set-Cookie"
 	HeaderIfModifiedSince     = "If-Modified-Since"
 	HeaderLastModified        = "Last-Modified"
+	HeaderTransferEncoding    = "Transfer-Encoding"
+	HeaderETag                = "ETag"
+	HeaderLink                = "Link"
 	HeaderLocation            = "Location"
 	HeaderRetryAfter          = "Retry-After"
 	HeaderUpgrade             = "Upgrade"
 	HeaderVary                = "Vary"
 	HeaderWWWAuthenticate     = "WWW-Authenticate"
 	HeaderXForwardedFor       = "X-Forwarded-For"
+	HeaderXForwardedHost      = "X-Forwarded-Host"
 	HeaderXForwardedProto     = "X-Forwarded-Proto"
 	HeaderXForwardedProtocol  = "X-Forwarded-Protocol"
 	HeaderXForwardedSsl       = "X-Forwarded-Ssl"
@@ -68,6 +78,7 @@ const (
 	HeaderXCorrelationID      = "X-Correlation-Id"
 	HeaderXRequestedWith      = "X-Requested-With"
 	HeaderServer              = "Server"
+	HeaderServerTiming        = "Server-Timing"
 	HeaderOrigin              = "Origin"
 	HeaderCacheControl        = "Cache-Control"
 	HeaderConnection          = "Connection"
@@ -75,6 +86,16 @@ const (
 	HeaderXRateLimitLimit     = "X-RateLimit-Limit"
 	HeaderXRateLimitRemaining = "X-RateLimit-Remaining"
 	HeaderXRateLimitReset     = "X-RateLimit-Reset"
+	HeaderXRateLimitPolicy    = "X-RateLimit-Policy"
+	HeaderXRateLimitCost      = "X-RateLimit-Cost"
+	HeaderRateLimit           = "RateLimit"
+	HeaderRateLimitLimit      = "RateLimit-Limit"
+	HeaderRateLimitRemaining  = "RateLimit-Remaining"
+	HeaderRateLimitReset      = "RateLimit-Reset"
+	HeaderRateLimitPolicy     = "RateLimit-Policy"
+	HeaderXQuotaLimit         = "X-Quota-Limit"
+	HeaderXQuotaRemaining     = "X-Quota-Remaining"
+	HeaderXQuotaReset         = "X-Quota-Reset"
 
 	// Access control
 	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
@@ -1,10 +1,15 @@
 package wo
 
-import "context"
+import (
+	"context"
+	"net/http"
+)
 
 type (
 	ctxRequestLoggedKey struct{}
 	ctxDebugKey         struct{}
+	ctxLocaleKey        struct{}
+	ctxEnvelopeKey      struct{}
 )
 
 func WithDebug(ctx context.Context, debug bool) context.Context {
@@ -24,3 +29,22 @@ func RequestLogged(ctx context.Context) bool {
 	logged, _ := ctx.Value(ctxRequestLoggedKey{}).(bool)
 	return logged
 }
+
+// WithLocale returns a copy of ctx which carries the resolved locale (language tag).
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, ctxLocaleKey{}, locale)
+}
+
+// Locale returns the locale stored in ctx or an empty string.
+func Locale(ctx context.Context) string {
+	locale, _ := ctx.Value(ctxLocaleKey{}).(string)
+	return locale
+}
+
+// IncomingRequest returns the request handled by the router (see [Router.Build]) which ctx
+// derives from, ex. to forward its metadata to the outbound requests. The request is the one
+// the router received, the changes made by the middlewares via SetRequest are not reflected.
+func IncomingRequest(ctx context.Context) (*http.Request, bool) {
+	r, ok := ctx.Value(ctxIncomingKey{}).(*http.Request)
+	return r, ok && r != nil
+}
@@ -0,0 +1,86 @@
+// Package debug mounts the runtime profiling (net/http/pprof) and the exported variables
+// (expvar) endpoints on the router, so they share its middlewares, ex. logging and access control.
+package debug
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/gowool/wo"
+)
+
+type Config[T wo.Resolver] struct {
+	// Prefix is the path prefix of the endpoints within the group.
+	//
+	// Default: "/debug/pprof" for MountPprof and "/debug/vars" for MountExpvar
+	Prefix string `env:"PREFIX" json:"prefix,omitempty" yaml:"prefix,omitempty"`
+
+	// Allow reports whether the request may access the endpoints, the denied requests
+	// result in [wo.ErrNotFound], so the endpoints are not disclosed.
+	//
+	// Default: nil (only the debug requests, see [wo.Debug])
+	Allow func(T) bool `json:"-" yaml:"-"`
+}
+
+func (c *Config[T]) setDefaults(prefix string) {
+	if c.Prefix == "" {
+		c.Prefix = prefix
+	}
+	if c.Allow == nil {
+		c.Allow = func(e T) bool {
+			return wo.Debug(e.Request().Context())
+		}
+	}
+}
+
+// MountPprof registers the net/http/pprof endpoints (the index, cmdline, profile, symbol, trace
+// and the named profiles, ex. heap or goroutine) under the prefix. The middlewares, ex. an
+// authentication, are bound to the returned group before the Allow check.
+//
+//	debug.MountPprof(r.Group("/_"), debug.Config[*wo.Event]{Allow: isStaff}, basicAuth)
+func MountPprof[T wo.Resolver](group *wo.RouterGroup[T], cfg Config[T], middlewares ...func(T) error) *wo.RouterGroup[T] {
+	cfg.setDefaults("/debug/pprof")
+
+	g := mount(group, cfg, middlewares)
+	g.GET("/{$}", wo.WrapHandler[T](http.HandlerFunc(pprof.Index)))
+	g.GET("/cmdline", wo.WrapHandler[T](http.HandlerFunc(pprof.Cmdline)))
+	g.GET("/profile", wo.WrapHandler[T](http.HandlerFunc(pprof.Profile)))
+	g.GET("/symbol", wo.WrapHandler[T](http.HandlerFunc(pprof.Symbol)))
+	g.POST("/symbol", wo.WrapHandler[T](http.HandlerFunc(pprof.Symbol)))
+	g.GET("/trace", wo.WrapHandler[T](http.HandlerFunc(pprof.Trace)))
+	g.GET("/{name}", func(e T) error {
+		// pprof.Index resolves the profile name only under the "/debug/pprof/" path
+		pprof.Handler(e.Request().PathValue("name")).ServeHTTP(e.Response(), e.Request())
+		return nil
+	})
+	return g
+}
+
+// MountExpvar registers the expvar handler, which serves the exported variables in JSON,
+// under the prefix. The middlewares, ex. an authentication, are bound to the returned group
+// before the Allow check.
+func MountExpvar[T wo.Resolver](group *wo.RouterGroup[T], cfg Config[T], middlewares ...func(T) error) *wo.RouterGroup[T] {
+	cfg.setDefaults("/debug/vars")
+
+	g := mount(group, cfg, middlewares)
+	g.GET("", wo.WrapHandler[T](expvar.Handler()))
+	return g
+}
+
+func mount[T wo.Resolver](group *wo.RouterGroup[T], cfg Config[T], middlewares []func(T) error) *wo.RouterGroup[T] {
+	if group == nil {
+		panic("debug: the provided router group is nil")
+	}
+
+	// the middlewares run first, so Allow sees the authenticated request
+	g := group.Group(cfg.Prefix)
+	g.BindFunc(middlewares...)
+	g.BindFunc(func(e T) error {
+		if !cfg.Allow(e) {
+			return wo.ErrNotFound
+		}
+		return e.Next()
+	})
+	return g
+}
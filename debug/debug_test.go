@@ -0,0 +1,160 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func newTestRouter() *wo.Router[*wo.Event] {
+	return wo.New[*wo.Event](
+		func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+			e := new(wo.Event)
+			e.Reset(w, r)
+			return e, nil
+		},
+		func(e *wo.Event, err error) {
+			e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+		},
+	)
+}
+
+func serve(t *testing.T, r *wo.Router[*wo.Event], method, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	h, err := r.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestMountPprof(t *testing.T) {
+	allow := func(*wo.Event) bool { return true }
+
+	tests := []struct {
+		name           string
+		cfg            Config[*wo.Event]
+		target         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "index",
+			cfg:            Config[*wo.Event]{Allow: allow},
+			target:         "/debug/pprof/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "goroutine",
+		},
+		{
+			name:           "named profile",
+			cfg:            Config[*wo.Event]{Allow: allow},
+			target:         "/debug/pprof/goroutine?debug=1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "goroutine profile",
+		},
+		{
+			name:           "unknown profile",
+			cfg:            Config[*wo.Event]{Allow: allow},
+			target:         "/debug/pprof/unknown",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "cmdline",
+			cfg:            Config[*wo.Event]{Allow: allow},
+			target:         "/debug/pprof/cmdline",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "custom prefix",
+			cfg:            Config[*wo.Event]{Prefix: "/_/pprof", Allow: allow},
+			target:         "/_/pprof/heap?debug=1",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "heap profile",
+		},
+		{
+			name:           "denied by default",
+			target:         "/debug/pprof/",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "denied",
+			cfg:            Config[*wo.Event]{Allow: func(*wo.Event) bool { return false }},
+			target:         "/debug/pprof/goroutine",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter()
+			MountPprof(r.RouterGroup, tt.cfg)
+
+			rec := serve(t, r, http.MethodGet, tt.target)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestMountPprof_DebugRequest(t *testing.T) {
+	r := newTestRouter()
+	r.BindFunc(func(e *wo.Event) error {
+		e.SetDebug(true)
+		return e.Next()
+	})
+	MountPprof(r.RouterGroup, Config[*wo.Event]{})
+
+	rec := serve(t, r, http.MethodGet, "/debug/pprof/")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestMountPprof_Middlewares(t *testing.T) {
+	r := newTestRouter()
+	MountPprof(r.RouterGroup, Config[*wo.Event]{Allow: func(*wo.Event) bool { return true }}, func(e *wo.Event) error {
+		return wo.ErrUnauthorized
+	})
+
+	rec := serve(t, r, http.MethodGet, "/debug/pprof/")
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestMountPprof_MiddlewaresBeforeAllow(t *testing.T) {
+	type userKey struct{}
+
+	r := newTestRouter()
+	MountPprof(r.RouterGroup, Config[*wo.Event]{Allow: func(e *wo.Event) bool {
+		return e.Value(userKey{}) == "staff"
+	}}, func(e *wo.Event) error {
+		e.SetValue(userKey{}, "staff")
+		return e.Next()
+	})
+
+	rec := serve(t, r, http.MethodGet, "/debug/pprof/")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestMountExpvar(t *testing.T) {
+	r := newTestRouter()
+	MountExpvar(r.RouterGroup, Config[*wo.Event]{Allow: func(*wo.Event) bool { return true }})
+
+	rec := serve(t, r, http.MethodGet, "/debug/vars")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"memstats"`)
+}
+
+func TestMount_NilGroup(t *testing.T) {
+	assert.Panics(t, func() { MountPprof[*wo.Event](nil, Config[*wo.Event]{}) })
+	assert.Panics(t, func() { MountExpvar[*wo.Event](nil, Config[*wo.Event]{}) })
+}
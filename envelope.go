@@ -0,0 +1,145 @@
+package wo
+
+import (
+	"context"
+	"net/http"
+)
+
+// EnvelopeConfig configures the JSON envelope of the API response helpers ([Event.OK], [Event.Created]
+// and [Event.Error]), see [Router.SetEnvelope]:
+//
+//	{"data": {...}}
+//	{"error": {"code": 404, "message": "user not found", "details": {...}}}
+type EnvelopeConfig struct {
+	// Unwrapped writes the data and the error objects without the envelope.
+	//
+	// Default: false
+	Unwrapped bool `env:"UNWRAPPED" json:"unwrapped,omitempty" yaml:"unwrapped,omitempty"`
+
+	// DataKey is the envelope key of the response data.
+	//
+	// Default: data
+	DataKey string `env:"DATA_KEY" json:"dataKey,omitempty" yaml:"dataKey,omitempty"`
+
+	// ErrorKey is the envelope key of the error object.
+	//
+	// Default: error
+	ErrorKey string `env:"ERROR_KEY" json:"errorKey,omitempty" yaml:"errorKey,omitempty"`
+
+	// CodeKey is the error object key of the error code.
+	//
+	// Default: code
+	CodeKey string `env:"CODE_KEY" json:"codeKey,omitempty" yaml:"codeKey,omitempty"`
+
+	// MessageKey is the error object key of the error message.
+	//
+	// Default: message
+	MessageKey string `env:"MESSAGE_KEY" json:"messageKey,omitempty" yaml:"messageKey,omitempty"`
+
+	// DetailsKey is the error object key of the error details, omitted when there are none.
+	//
+	// Default: details
+	DetailsKey string `env:"DETAILS_KEY" json:"detailsKey,omitempty" yaml:"detailsKey,omitempty"`
+}
+
+func (c *EnvelopeConfig) SetDefaults() {
+	if c.DataKey == "" {
+		c.DataKey = "data"
+	}
+	if c.ErrorKey == "" {
+		c.ErrorKey = "error"
+	}
+	if c.CodeKey == "" {
+		c.CodeKey = "code"
+	}
+	if c.MessageKey == "" {
+		c.MessageKey = "message"
+	}
+	if c.DetailsKey == "" {
+		c.DetailsKey = "details"
+	}
+}
+
+var defaultEnvelope = func() *EnvelopeConfig {
+	cfg := new(EnvelopeConfig)
+	cfg.SetDefaults()
+	return cfg
+}()
+
+// WithEnvelope returns a copy of ctx which carries the envelope configuration of the API response helpers.
+func WithEnvelope(ctx context.Context, cfg EnvelopeConfig) context.Context {
+	cfg.SetDefaults()
+	return context.WithValue(ctx, ctxEnvelopeKey{}, &cfg)
+}
+
+// Envelope returns the envelope configuration stored in ctx or the default one.
+func Envelope(ctx context.Context) EnvelopeConfig {
+	return *envelope(ctx)
+}
+
+func envelope(ctx context.Context) *EnvelopeConfig {
+	if cfg, ok := ctx.Value(ctxEnvelopeKey{}).(*EnvelopeConfig); ok {
+		return cfg
+	}
+	if rc, ok := ctx.Value(ctxRouterKey{}).(*routerContext); ok && rc.envelope != nil {
+		return rc.envelope
+	}
+	return defaultEnvelope
+}
+
+// SetEnvelope sets the envelope configuration of the API response helpers of all routes,
+// it must be called before [Router.Build].
+func (r *Router[T]) SetEnvelope(cfg EnvelopeConfig) {
+	cfg.SetDefaults()
+	r.envelope = &cfg
+}
+
+// OK sends the data in the JSON envelope with the status 200 OK.
+func (e *Event) OK(data any) error {
+	return e.JSON(http.StatusOK, e.wrap(data))
+}
+
+// Created sends the data in the JSON envelope with the status 201 Created,
+// the location of the created resource is sent in the Location header unless empty.
+func (e *Event) Created(location string, data any) error {
+	if location != "" {
+		e.response.Header().Set(HeaderLocation, location)
+	}
+	return e.JSON(http.StatusCreated, e.wrap(data))
+}
+
+// NoContent204 sends the status 204 No Content.
+func (e *Event) NoContent204() error {
+	return e.NoContent(http.StatusNoContent)
+}
+
+// Error sends the error object in the JSON envelope with the status, the details are omitted
+// when nil. The empty message defaults to the status text.
+func (e *Event) Error(status int, message string, details any) error {
+	cfg := envelope(e.Context())
+
+	if message == "" {
+		message = http.StatusText(status)
+	}
+
+	obj := map[string]any{
+		cfg.CodeKey:    status,
+		cfg.MessageKey: message,
+	}
+	if details != nil {
+		obj[cfg.DetailsKey] = details
+	}
+
+	if cfg.Unwrapped {
+		return e.JSON(status, obj)
+	}
+	return e.JSON(status, map[string]any{cfg.ErrorKey: obj})
+}
+
+func (e *Event) wrap(data any) any {
+	cfg := envelope(e.Context())
+	if cfg.Unwrapped {
+		return data
+	}
+	return map[string]any{cfg.DataKey: data}
+}
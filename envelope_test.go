@@ -0,0 +1,121 @@
+package wo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent_EnvelopeHelpers(t *testing.T) {
+	tests := []struct {
+		name             string
+		handler          func(e *Event) error
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			name:           "ok",
+			handler:        func(e *Event) error { return e.OK(map[string]int{"id": 1}) },
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"id":1}}`,
+		},
+		{
+			name:             "created",
+			handler:          func(e *Event) error { return e.Created("/users/1", map[string]int{"id": 1}) },
+			expectedStatus:   http.StatusCreated,
+			expectedBody:     `{"data":{"id":1}}`,
+			expectedLocation: "/users/1",
+		},
+		{
+			name:           "no content",
+			handler:        func(e *Event) error { return e.NoContent204() },
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "error",
+			handler:        func(e *Event) error { return e.Error(http.StatusNotFound, "user not found", map[string]int{"id": 1}) },
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":{"code":404,"details":{"id":1},"message":"user not found"}}`,
+		},
+		{
+			name:           "error without message and details",
+			handler:        func(e *Event) error { return e.Error(http.StatusConflict, "", nil) },
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"error":{"code":409,"message":"Conflict"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			event := new(Event)
+			event.Reset(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			require.NoError(t, tt.handler(event))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedLocation, rec.Header().Get(HeaderLocation))
+			if tt.expectedBody == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouter_SetEnvelope(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          EnvelopeConfig
+		expectedOK   string
+		expectedFail string
+	}{
+		{
+			name:         "custom keys",
+			cfg:          EnvelopeConfig{DataKey: "result", ErrorKey: "err", MessageKey: "msg"},
+			expectedOK:   `{"result":[1,2]}`,
+			expectedFail: `{"err":{"code":400,"msg":"bad"}}`,
+		},
+		{
+			name:         "unwrapped",
+			cfg:          EnvelopeConfig{Unwrapped: true},
+			expectedOK:   `[1,2]`,
+			expectedFail: `{"code":400,"message":"bad"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New[*Event](eventFactory, errorHandler)
+			router.SetEnvelope(tt.cfg)
+			router.GET("/ok", func(e *Event) error { return e.OK([]int{1, 2}) })
+			router.GET("/fail", func(e *Event) error { return e.Error(http.StatusBadRequest, "bad", nil) })
+
+			h, err := router.Build(nil)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+			assert.JSONEq(t, tt.expectedOK, rec.Body.String())
+
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+			assert.JSONEq(t, tt.expectedFail, rec.Body.String())
+		})
+	}
+}
+
+func TestEnvelope_Context(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, "data", Envelope(req.Context()).DataKey)
+
+	ctx := WithEnvelope(req.Context(), EnvelopeConfig{DataKey: "items"})
+	cfg := Envelope(ctx)
+	assert.Equal(t, "items", cfg.DataKey)
+	assert.Equal(t, "error", cfg.ErrorKey)
+}
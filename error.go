@@ -1,9 +1,13 @@
 package wo
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"net"
 	"net/http"
+	"time"
 )
 
 var (
@@ -11,6 +15,10 @@ var (
 	_ error = (*RedirectError)(nil)
 )
 
+// StatusClientClosedRequest is the non-standard status, introduced by nginx, of the requests the
+// client closed before the response. The error handler logs it, the response isn't written.
+const StatusClientClosedRequest = 499
+
 var (
 	ErrBadRequest                    = NewHTTPError(http.StatusBadRequest)                    // HTTP 400 Bad Request
 	ErrUnauthorized                  = NewHTTPError(http.StatusUnauthorized)                  // HTTP 401 Unauthorized
@@ -53,8 +61,11 @@ var (
 	ErrNotExtended                   = NewHTTPError(http.StatusNotExtended)                   // HTTP 510 Not Extended
 	ErrNetworkAuthenticationRequired = NewHTTPError(http.StatusNetworkAuthenticationRequired) // HTTP 511 Network Authentication Required
 
+	ErrClientClosedRequest = NewHTTPError(StatusClientClosedRequest, "Client Closed Request") // HTTP 499 Client Closed Request
+
 	ErrRendererNotRegistered = errors.New("renderer not registered")
 	ErrInvalidRedirectCode   = errors.New("invalid redirect Status code")
+	ErrPollerNotRegistered   = errors.New("poller not registered")
 )
 
 func AsHTTPError(err error) *HTTPError {
@@ -67,12 +78,48 @@ func AsHTTPError(err error) *HTTPError {
 	return he
 }
 
+// MapError maps the standard errors to [HTTPError]: [context.Canceled] to [ErrClientClosedRequest],
+// [context.DeadlineExceeded] and the net timeouts to [ErrGatewayTimeout], [http.MaxBytesError]
+// to [ErrStatusRequestEntityTooLarge] and the others to [ErrInternalServerError]. The error handler
+// uses it when the mapper returns nil, so the mapper overrides it, and maps [ErrClientClosedRequest]
+// to [ErrInternalServerError] when the request context isn't canceled, ex. for the canceled
+// upstream call, so the waiting client gets the response.
+func MapError(err error) *HTTPError {
+	var (
+		maxBytesErr *http.MaxBytesError
+		netErr      net.Error
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrClientClosedRequest.WithInternal(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrGatewayTimeout.WithInternal(err)
+	case errors.As(err, &maxBytesErr):
+		return ErrStatusRequestEntityTooLarge.WithInternal(err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrGatewayTimeout.WithInternal(err)
+	default:
+		return ErrInternalServerError.WithInternal(err)
+	}
+}
+
 // HTTPError represents an error that occurred while handling a request.
 type HTTPError struct {
 	Internal error
 	Message  any
-	Status   int
-	Debug    bool
+
+	// Fields are the details of the invalid fields, ex. {"email": "must be a valid email address"}.
+	Fields map[string]any
+
+	// Code is the application error code, ex. "user_not_found", the clients handle the
+	// errors by it instead of the message.
+	Code string
+
+	// RetryAfter is sent as the Retry-After header, ex. with 429 or 503.
+	RetryAfter time.Duration
+
+	Status int
+	Debug  bool
 }
 
 // NewHTTPError creates a new HTTPError instance.
@@ -92,11 +139,9 @@ func (he *HTTPError) SetInternal(err error) *HTTPError {
 
 // WithInternal returns clone of HTTPError with err set to HTTPError.Internal field
 func (he *HTTPError) WithInternal(err error) *HTTPError {
-	return &HTTPError{
-		Status:   he.Status,
-		Message:  he.Message,
-		Internal: err,
-	}
+	c := he.clone()
+	c.Internal = err
+	return c
 }
 
 // SetMessage sets message to HTTPError.Message
@@ -107,10 +152,59 @@ func (he *HTTPError) SetMessage(message any) *HTTPError {
 
 // WithMessage returns clone of HTTPError with message set to HTTPError.Message field
 func (he *HTTPError) WithMessage(message any) *HTTPError {
+	c := he.clone()
+	c.Message = message
+	return c
+}
+
+// WithDetail returns clone of HTTPError with detail set to HTTPError.Message field,
+// rendered as the detail of the payload. The empty detail keeps the message.
+//
+//	return wo.ErrNotFound.WithDetail("user " + id + " not found").WithCode("user_not_found")
+func (he *HTTPError) WithDetail(detail string) *HTTPError {
+	c := he.clone()
+	if detail != "" {
+		c.Message = detail
+	}
+	return c
+}
+
+// WithField returns clone of HTTPError with the detail of the invalid field added to
+// HTTPError.Fields field.
+//
+//	return wo.ErrUnprocessableEntity.WithField("email", "must be a valid email address")
+func (he *HTTPError) WithField(name string, detail any) *HTTPError {
+	c := he.clone()
+	if c.Fields == nil {
+		c.Fields = make(map[string]any, 1)
+	}
+	c.Fields[name] = detail
+	return c
+}
+
+// WithCode returns clone of HTTPError with code set to HTTPError.Code field
+func (he *HTTPError) WithCode(code string) *HTTPError {
+	c := he.clone()
+	c.Code = code
+	return c
+}
+
+// WithRetryAfter returns clone of HTTPError with d set to HTTPError.RetryAfter field
+func (he *HTTPError) WithRetryAfter(d time.Duration) *HTTPError {
+	c := he.clone()
+	c.RetryAfter = d
+	return c
+}
+
+// clone returns the copy of HTTPError without the Debug flag, the error handler sets it per request.
+func (he *HTTPError) clone() *HTTPError {
 	return &HTTPError{
-		Status:   he.Status,
-		Internal: he.Internal,
-		Message:  message,
+		Internal:   he.Internal,
+		Message:    he.Message,
+		Fields:     maps.Clone(he.Fields),
+		Code:       he.Code,
+		RetryAfter: he.RetryAfter,
+		Status:     he.Status,
 	}
 }
 
@@ -137,6 +231,14 @@ func (he *HTTPError) ToMap() map[string]any {
 		data["detail"] = detail
 	}
 
+	if he.Code != "" {
+		data["code"] = he.Code
+	}
+
+	if len(he.Fields) > 0 {
+		data["fields"] = he.Fields
+	}
+
 	if internal := he.internal(); internal != "" {
 		data["internal"] = internal
 	}
@@ -145,10 +247,12 @@ func (he *HTTPError) ToMap() map[string]any {
 }
 
 type errData struct {
-	Status   int    `json:"status"`
-	Title    string `json:"title"`
-	Detail   any    `json:"detail,omitempty"`
-	Internal string `json:"internal,omitempty"`
+	Status   int            `json:"status"`
+	Title    string         `json:"title"`
+	Detail   any            `json:"detail,omitempty"`
+	Code     string         `json:"code,omitempty"`
+	Fields   map[string]any `json:"fields,omitempty"`
+	Internal string         `json:"internal,omitempty"`
 }
 
 func (he *HTTPError) title() string {
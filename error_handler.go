@@ -7,9 +7,12 @@ import (
 	"html/template"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gowool/wo/internal/convert"
 	"github.com/gowool/wo/internal/encode"
+	"github.com/gowool/wo/report"
 )
 
 const errorTemplate = `<!DOCTYPE html>
@@ -49,6 +52,10 @@ var errorTpl = template.Must(template.New("error_template").Parse(errorTemplate)
 
 type HTTPErrorHandler[T Resolver] func(T, error)
 
+// ErrorHandler returns the error handler writing the error response negotiated per the Accept
+// header, or the one of render, if given. The mapper maps the errors to [HTTPError], the nil result
+// falls back to [MapError]. The client closed requests (499) are logged only, if the request
+// context is canceled.
 func ErrorHandler[T Resolver](render func(T, *HTTPError), mapper func(error) *HTTPError, logger *slog.Logger) HTTPErrorHandler[T] {
 	if logger == nil {
 		logger = slog.New(slog.DiscardHandler)
@@ -78,9 +85,28 @@ func ErrorHandler[T Resolver](render func(T, *HTTPError), mapper func(error) *HT
 
 		httpErr := mapper(err)
 		if httpErr == nil {
+			httpErr = MapError(err)
+		}
+
+		if httpErr.Status == StatusClientClosedRequest && req.Context().Err() == nil {
+			// the client is still waiting, ex. the upstream call is canceled
 			httpErr = ErrInternalServerError.WithInternal(err)
 		}
 
+		if httpErr.Status == StatusClientClosedRequest {
+			// the client is gone, nobody reads the response
+			res.Status = httpErr.Status
+			if !RequestLogged(req.Context()) {
+				logger.LogAttrs(
+					context.Background(),
+					slog.LevelInfo,
+					"request canceled",
+					RequestLoggerAttrs[T](e, httpErr.Status, err)...,
+				)
+			}
+			return
+		}
+
 		defer func() {
 			if !RequestLogged(e.Request().Context()) {
 				logger.LogAttrs(
@@ -92,6 +118,10 @@ func ErrorHandler[T Resolver](render func(T, *HTTPError), mapper func(error) *HT
 			}
 		}()
 
+		if httpErr.RetryAfter > 0 {
+			res.Header().Set(HeaderRetryAfter, strconv.FormatInt(int64((httpErr.RetryAfter+time.Second-1)/time.Second), 10))
+		}
+
 		if req.Method == http.MethodHead {
 			res.WriteHeader(httpErr.Status)
 			return
@@ -108,8 +138,8 @@ func ErrorHandler[T Resolver](render func(T, *HTTPError), mapper func(error) *HT
 			req = e.Request()
 		}
 
-		contentType := NegotiateFormat(
-			ParseAcceptHeader(req.Header.Get(HeaderAccept)),
+		contentType := NegotiateMediaType(
+			ParseAccept(req.Header.Get(HeaderAccept)),
 			MIMETextPlainCharsetUTF8,
 			MIMETextHTMLCharsetUTF8,
 			MIMEApplicationJSON,
@@ -139,3 +169,32 @@ func ErrorHandler[T Resolver](render func(T, *HTTPError), mapper func(error) *HT
 		}
 	}
 }
+
+// ReportErrors wraps the error handler reporting the errors which result in the server error
+// responses (5xx) to the reporter, ex. to Sentry. The errors marked by [report.Reported], ex. the
+// panics reported by the Recover middleware, are not reported again.
+func ReportErrors[T Resolver](h HTTPErrorHandler[T], reporter report.ErrorReporter) HTTPErrorHandler[T] {
+	if h == nil {
+		panic("wo: error handler is nil")
+	}
+	if reporter == nil {
+		return h
+	}
+
+	return func(e T, err error) {
+		h(e, err)
+
+		status := MustUnwrapResponse(e.Response()).Status
+		if status < http.StatusInternalServerError || report.IsReported(err) {
+			return
+		}
+
+		req := e.Request()
+		reporter.CaptureException(req.Context(), err, report.Tags{
+			"source":  "router",
+			"method":  req.Method,
+			"pattern": req.Pattern,
+			"status":  strconv.Itoa(status),
+		})
+	}
+}
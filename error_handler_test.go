@@ -2,16 +2,23 @@ package wo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/gowool/hook"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/report"
 )
 
 // ErrorHandlerTestEvent is a simple implementation of hook.Resolver for testing
@@ -159,6 +166,81 @@ func TestErrorHandler_HTTPErrorMapperReturnsNil(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 }
 
+func TestErrorHandler_MaxBytesError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	event := NewErrorHandlerTestEvent(req, &Response{ResponseWriter: rec})
+
+	handler := ErrorHandler[*ErrorHandlerTestEvent](nil, nil, nil)
+	handler(event, fmt.Errorf("read body: %w", &http.MaxBytesError{Limit: 10}))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestErrorHandler_StandardErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		mapper         func(error) *HTTPError
+		clientGone     bool
+		expectedStatus int
+		expectedCode   int
+	}{
+		{name: "canceled", err: fmt.Errorf("query: %w", context.Canceled), clientGone: true, expectedStatus: StatusClientClosedRequest, expectedCode: http.StatusOK},
+		{name: "canceled upstream", err: fmt.Errorf("query: %w", context.Canceled), expectedStatus: http.StatusInternalServerError},
+		{name: "deadline exceeded", err: fmt.Errorf("query: %w", context.DeadlineExceeded), expectedStatus: http.StatusGatewayTimeout},
+		{name: "net timeout", err: &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, expectedStatus: http.StatusGatewayTimeout},
+		{name: "net error", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, expectedStatus: http.StatusInternalServerError},
+		{
+			name: "override",
+			err:  context.DeadlineExceeded,
+			mapper: func(err error) *HTTPError {
+				if errors.Is(err, context.DeadlineExceeded) {
+					return ErrServiceUnavailable.WithRetryAfter(time.Second)
+				}
+				return nil
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.clientGone {
+				cancel()
+			}
+
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			res := &Response{ResponseWriter: rec}
+			event := NewErrorHandlerTestEvent(req, res)
+
+			var logBuffer bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+
+			ErrorHandler[*ErrorHandlerTestEvent](nil, tt.mapper, logger)(event, tt.err)
+
+			expectedCode := tt.expectedCode
+			if expectedCode == 0 {
+				expectedCode = tt.expectedStatus
+			}
+			assert.Equal(t, tt.expectedStatus, res.Status)
+			assert.Equal(t, expectedCode, rec.Code)
+
+			if tt.expectedStatus == StatusClientClosedRequest {
+				assert.False(t, res.Written)
+				assert.Empty(t, rec.Body.String())
+				assert.Contains(t, logBuffer.String(), `"level":"INFO","msg":"request canceled"`)
+			} else {
+				assert.True(t, res.Written)
+				assert.Contains(t, logBuffer.String(), `"level":"ERROR","msg":"request failed"`)
+			}
+		})
+	}
+}
+
 func TestErrorHandler_WithCustomRender(t *testing.T) {
 	renderCalled := false
 	customRender := func(e *ErrorHandlerTestEvent, httpErr *HTTPError) {
@@ -275,6 +357,37 @@ func TestErrorHandler_JSONResponse(t *testing.T) {
 	}
 }
 
+func TestErrorHandler_StructuredError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderAccept, MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	event := NewErrorHandlerTestEvent(req, &Response{ResponseWriter: rec})
+
+	base := ErrUnprocessableEntity.WithCode("invalid_user")
+	httpErr := base.
+		WithDetail("the user is invalid").
+		WithField("email", "must be a valid email address").
+		WithField("age", "must be at least 18").
+		WithRetryAfter(1500 * time.Millisecond)
+
+	ErrorHandler[*ErrorHandlerTestEvent](nil, nil, nil)(event, httpErr)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(HeaderRetryAfter))
+	assert.JSONEq(t, `{
+		"status": 422,
+		"title": "Unprocessable Entity",
+		"detail": "the user is invalid",
+		"code": "invalid_user",
+		"fields": {"email": "must be a valid email address", "age": "must be at least 18"}
+	}`, rec.Body.String())
+
+	assert.Empty(t, base.Fields, "the builders must not modify the receiver")
+	assert.Equal(t, http.StatusText(http.StatusUnprocessableEntity), base.Message)
+	assert.Equal(t, "Unprocessable Entity", ErrUnprocessableEntity.WithDetail("").Message)
+	assert.Empty(t, ErrUnprocessableEntity.Code)
+}
+
 func TestErrorHandler_HTMLResponse(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set(HeaderAccept, MIMETextHTMLCharsetUTF8)
@@ -548,3 +661,56 @@ func TestErrorHandler_Integration(t *testing.T) {
 		assert.Equal(t, true, debug)
 	}
 }
+
+type testReporter struct {
+	errs []error
+	tags []report.Tags
+}
+
+func (r *testReporter) CaptureException(_ context.Context, err error, tags report.Tags) {
+	r.errs = append(r.errs, err)
+	r.tags = append(r.tags, tags)
+}
+
+func (r *testReporter) CaptureMessage(context.Context, string, report.Tags) {}
+
+func TestReportErrors(t *testing.T) {
+	panicked := ErrInternalServerError.WithInternal(report.Reported(errors.New("panic")))
+
+	tests := []struct {
+		name     string
+		err      error
+		reported bool
+	}{
+		{name: "server error", err: errors.New("db down"), reported: true},
+		{name: "client error", err: ErrNotFound},
+		{name: "already reported", err: panicked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+			req.Pattern = "GET /items/{id}"
+			event := NewErrorHandlerTestEvent(req, &Response{ResponseWriter: httptest.NewRecorder()})
+
+			reporter := new(testReporter)
+			ReportErrors(ErrorHandler[*ErrorHandlerTestEvent](nil, nil, nil), reporter)(event, tt.err)
+
+			if !tt.reported {
+				assert.Empty(t, reporter.errs)
+				return
+			}
+			assert.Equal(t, []error{tt.err}, reporter.errs)
+			assert.Equal(t, []report.Tags{{
+				"source":  "router",
+				"method":  http.MethodGet,
+				"pattern": "GET /items/{id}",
+				"status":  "500",
+			}}, reporter.tags)
+		})
+	}
+
+	h := ErrorHandler[*ErrorHandlerTestEvent](nil, nil, nil)
+	assert.NotNil(t, ReportErrors(h, nil))
+	assert.Panics(t, func() { ReportErrors[*ErrorHandlerTestEvent](nil, report.Nop) })
+}
@@ -9,6 +9,8 @@ func (he *HTTPError) MarshalJSON() ([]byte, error) {
 		Status:   he.Status,
 		Title:    he.title(),
 		Detail:   he.detail(),
+		Code:     he.Code,
+		Fields:   he.Fields,
 		Internal: he.internal(),
 	})
 }
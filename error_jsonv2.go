@@ -12,6 +12,8 @@ func (he *HTTPError) MarshalJSONTo(enc *jsontext.Encoder) error {
 		Status:   he.Status,
 		Title:    he.title(),
 		Detail:   he.detail(),
+		Code:     he.Code,
+		Fields:   he.Fields,
 		Internal: he.internal(),
 	})
 }
@@ -1,21 +1,26 @@
 package wo
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"mime/multipart"
 	"net"
 	"net/http"
 	"net/netip"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gowool/hook"
 
+	"github.com/gowool/wo/fingerprint"
+	"github.com/gowool/wo/flags"
 	"github.com/gowool/wo/internal/convert"
 	"github.com/gowool/wo/internal/encode"
 )
@@ -28,10 +33,16 @@ type Event struct {
 	request    *http.Request
 
 	query     url.Values
+	queryErr  error
 	start     time.Time
 	remoteIP  string
 	accepted  []string
+	ranges    []MediaRange
 	languages []string
+	body      []byte
+	store     Store
+	links     *Links
+	bodyIn    *countingReader
 }
 
 func (e *Event) Reset(w http.ResponseWriter, r *http.Request) {
@@ -42,13 +53,35 @@ func (e *Event) Reset(w http.ResponseWriter, r *http.Request) {
 	}
 	e.response = e.woResponse
 	e.request = r
+	e.bodyIn = newCountingReader(r)
 	e.remoteIP = ""
 	e.query = nil
+	e.queryErr = nil
 	e.accepted = nil
+	e.ranges = nil
 	e.languages = nil
+	e.body = nil
+	e.store.Reset()
+	e.links = nil
 	e.start = time.Now()
 }
 
+// BytesIn returns the number of the request body bytes read so far.
+func (e *Event) BytesIn() int64 {
+	if e.bodyIn == nil {
+		return 0
+	}
+	return e.bodyIn.n
+}
+
+// BytesOut returns the number of the response body bytes written so far, see [Response.BytesWritten].
+func (e *Event) BytesOut() int64 {
+	if res, err := UnwrapResponse(e.response); err == nil {
+		return res.BytesWritten()
+	}
+	return 0
+}
+
 func (e *Event) SetRequest(r *http.Request) {
 	e.request = r
 }
@@ -82,6 +115,11 @@ func (e *Event) Value(key any) any {
 	return e.Context().Value(key)
 }
 
+// Store returns the per-request value bag, see [Set] and [Get].
+func (e *Event) Store() *Store {
+	return &e.store
+}
+
 func (e *Event) Debug() bool {
 	return Debug(e.Context())
 }
@@ -114,9 +152,9 @@ func (e *Event) IsAjax() bool {
 	return e.Request().Header.Get(HeaderXRequestedWith) == XMLHTTPRequest
 }
 
-// NegotiateFormat returns an acceptable Accept format.
+// NegotiateFormat returns the most acceptable offered format, see [NegotiateMediaType].
 func (e *Event) NegotiateFormat(offered ...string) string {
-	return NegotiateFormat(e.Accepted(), offered...)
+	return NegotiateMediaType(e.AcceptMediaRanges(), offered...)
 }
 
 // Accept returns the value of the Accept header.
@@ -124,19 +162,35 @@ func (e *Event) Accept() string {
 	return e.request.Header.Get(HeaderAccept)
 }
 
+// Accepted returns the acceptable media ranges of the Accept header ordered by preference.
 func (e *Event) Accepted() []string {
 	if e.accepted == nil {
-		e.accepted = ParseAcceptHeader(e.Accept())
+		ranges := e.AcceptMediaRanges()
+		e.accepted = make([]string, 0, len(ranges))
+		for _, r := range ranges {
+			if r.Q > 0 {
+				e.accepted = append(e.accepted, r.MediaType())
+			}
+		}
 	}
 	return e.accepted
 }
 
+// AcceptMediaRanges returns the parsed media ranges of the Accept header ordered by preference,
+// see [ParseAccept].
+func (e *Event) AcceptMediaRanges() []MediaRange {
+	if e.ranges == nil {
+		e.ranges = ParseAccept(e.Accept())
+	}
+	return e.ranges
+}
+
 // AcceptLanguage returns the value of the Accept-Language header.
 func (e *Event) AcceptLanguage() string {
 	return e.request.Header.Get(HeaderAcceptLanguage)
 }
 
-// Languages returns a slice of accepted languages from the Accept-Language header.
+// Languages returns a slice of accepted languages from the Accept-Language header ordered by preference.
 func (e *Event) Languages() []string {
 	if e.languages == nil {
 		e.languages = ParseAcceptLanguageHeader(e.AcceptLanguage())
@@ -144,6 +198,89 @@ func (e *Event) Languages() []string {
 	return e.languages
 }
 
+// AcceptLanguageBest returns the offered language which matches best the Accept-Language
+// header or an empty string, see [AcceptLanguageBest].
+func (e *Event) AcceptLanguageBest(offered ...string) string {
+	return AcceptLanguageBest(e.Languages(), offered...)
+}
+
+// Translate translates the message key for the request locale, see [TranslateFuncFromContext].
+func (e *Event) Translate(key string, args ...any) string {
+	return TranslateFuncFromContext(e.Context())(key, args...)
+}
+
+// Timing records a Server-Timing metric for the request, see [ServerTiming].
+func (e *Event) Timing(name string, dur time.Duration, desc string) {
+	st := ServerTimingFromContext(e.Context())
+	if st == nil {
+		st = new(ServerTiming)
+		e.SetContext(WithServerTiming(e.Context(), st))
+	}
+	st.Add(name, dur, desc)
+}
+
+// Locale returns the locale resolved for the request, see [WithLocale].
+func (e *Event) Locale() string {
+	return Locale(e.Context())
+}
+
+// SetLocale stores the resolved locale in the request context and emits it as Content-Language.
+func (e *Event) SetLocale(tag string) {
+	e.SetContext(WithLocale(e.Context(), tag))
+	e.SetContentLanguage(tag)
+}
+
+// SetContentLanguage sets the Content-Language response header and adds Accept-Language to Vary.
+func (e *Event) SetContentLanguage(tag string) {
+	SetContentLanguage(e.Response(), tag)
+}
+
+// EarlyHints sends a 103 (Early Hints) informational response with the given links,
+// so the client can start preloading resources while the final response is prepared.
+// A link is either a complete Link header value (`</app.css>; rel=preload; as=style`)
+// or an URI reference which is preloaded (`/app.css`).
+//
+// Only the Link headers are sent with the 103 response; the headers already set for the
+// final response are kept intact. It returns [http.ErrNotSupported] for HTTP/1.0 clients
+// and for buffered responses.
+func (e *Event) EarlyHints(links ...string) error {
+	if len(links) == 0 {
+		return nil
+	}
+	if !e.request.ProtoAtLeast(1, 1) {
+		return http.ErrNotSupported
+	}
+
+	res, err := UnwrapResponse(e.Response())
+	if err != nil {
+		return err
+	}
+	if res.Written {
+		return errors.New("early hints: response is already written")
+	}
+	if res.Buffering {
+		return http.ErrNotSupported
+	}
+
+	header := res.Header()
+	saved := header.Clone()
+	clear(header)
+
+	for _, link := range links {
+		if !strings.HasPrefix(link, "<") {
+			link = "<" + link + ">; rel=preload"
+		}
+		header.Add(HeaderLink, link)
+	}
+
+	res.WriteHeader(http.StatusEarlyHints)
+
+	clear(header)
+	maps.Copy(header, saved)
+
+	return nil
+}
+
 // Scheme returns the HTTP protocol scheme, `http` or `https`.
 func (e *Event) Scheme() string {
 	// Can't use `r.Request.URL.Scheme`
@@ -168,20 +305,66 @@ func (e *Event) Scheme() string {
 
 // QueryParam returns the query param for the provided name.
 func (e *Event) QueryParam(name string) string {
-	if e.query == nil {
-		e.query = e.request.URL.Query()
-	}
-	return e.query.Get(name)
+	return e.QueryParams().Get(name)
 }
 
-// QueryParams returns the query parameters as `url.Values`.
+// QueryParams returns the query parameters as `url.Values`. The malformed pairs
+// are silently dropped, see [Event.QueryParamsE].
 func (e *Event) QueryParams() url.Values {
 	if e.query == nil {
-		e.query = e.request.URL.Query()
+		e.query, e.queryErr = url.ParseQuery(e.request.URL.RawQuery)
 	}
 	return e.query
 }
 
+// QueryParamsE returns the query parameters as `url.Values` and [ErrBadRequest]
+// if the query string is malformed, in which case the valid pairs are still returned.
+func (e *Event) QueryParamsE() (url.Values, error) {
+	query := e.QueryParams()
+	if e.queryErr != nil {
+		return query, ErrBadRequest.WithMessage("invalid query string").WithInternal(e.queryErr)
+	}
+	return query, nil
+}
+
+// QueryInt returns the query param for the provided name as an int, or def if it's
+// missing or empty. The malformed value and query string return [ErrBadRequest].
+func (e *Event) QueryInt(name string, def int) (int, error) {
+	return queryValue(e, name, def, strconv.Atoi)
+}
+
+// QueryBool returns the query param for the provided name as a bool (see [strconv.ParseBool]),
+// or def if it's missing or empty. The malformed value and query string return [ErrBadRequest].
+func (e *Event) QueryBool(name string, def bool) (bool, error) {
+	return queryValue(e, name, def, strconv.ParseBool)
+}
+
+// QueryTime returns the query param for the provided name parsed with the layout, ex. [time.RFC3339],
+// or def if it's missing or empty. The malformed value and query string return [ErrBadRequest].
+func (e *Event) QueryTime(name, layout string, def time.Time) (time.Time, error) {
+	return queryValue(e, name, def, func(value string) (time.Time, error) {
+		return time.Parse(layout, value)
+	})
+}
+
+func queryValue[V any](e *Event, name string, def V, parse func(string) (V, error)) (V, error) {
+	query, err := e.QueryParamsE()
+	if err != nil {
+		return def, err
+	}
+
+	raw := query.Get(name)
+	if raw == "" {
+		return def, nil
+	}
+
+	value, err := parse(raw)
+	if err != nil {
+		return def, ErrBadRequest.WithMessage(fmt.Sprintf("invalid query param %q", name)).WithInternal(err)
+	}
+	return value, nil
+}
+
 // QueryString returns the URL query string.
 func (e *Event) QueryString() string {
 	return e.request.URL.RawQuery
@@ -206,6 +389,24 @@ func (e *Event) FormParams() (url.Values, error) {
 	return e.request.Form, nil
 }
 
+// FormParamsE returns the form parameters as `url.Values` like [Event.FormParams],
+// but the malformed body and query string return [ErrBadRequest], the oversized body
+// returns [ErrStatusRequestEntityTooLarge].
+func (e *Event) FormParamsE() (url.Values, error) {
+	form, err := e.FormParams()
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, ErrStatusRequestEntityTooLarge.WithInternal(err)
+		}
+		if he := AsHTTPError(err); he != nil {
+			return nil, he
+		}
+		return nil, ErrBadRequest.WithMessage("invalid form").WithInternal(err)
+	}
+	return form, nil
+}
+
 // FormFile returns the multipart form file for the provided name.
 func (e *Event) FormFile(name string) (*multipart.FileHeader, error) {
 	f, fh, err := e.request.FormFile(name)
@@ -273,10 +474,28 @@ func (e *Event) RemoteIP() string {
 	return e.remoteIP
 }
 
+// Fingerprint returns the stable fingerprint of the request attributes, ex. to detect
+// the session anomalies, see [fingerprint.Of].
+func (e *Event) Fingerprint(components fingerprint.Component) string {
+	return fingerprint.Of(e.request, components)
+}
+
+// Flag reports whether the feature flag evaluated for the request is on, see [flags.Enabled].
+func (e *Event) Flag(name string) bool {
+	return flags.Enabled(e.Context(), name)
+}
+
+// FlagString returns the string value of the feature flag evaluated for the request,
+// ex. the variant of the A/B test, see [flags.String].
+func (e *Event) FlagString(name string) string {
+	return flags.String(e.Context(), name)
+}
+
 // Response writers
 // -------------------------------------------------------------------
 
-// Negotiate calls different Render according to acceptable Accept format
+// Negotiate calls different Render according to acceptable Accept format, the renderers
+// registered by [Router.SetRenderer] take precedence over the built-in ones.
 func (e *Event) Negotiate(status int, data any, offered ...string) error {
 	ct := e.NegotiateFormat(offered...)
 
@@ -290,6 +509,10 @@ func (e *Event) Negotiate(status int, data any, offered ...string) error {
 			return e.Stream(status, ct, data)
 		}
 	default:
+		if renderer, ok := rendererOf(e.Context(), ct); ok {
+			return e.render(status, ct, renderer, data)
+		}
+
 		switch ct {
 		case MIMEApplicationJSON:
 			return e.JSON(status, data)
@@ -430,14 +653,35 @@ func (e *Event) Blob(status int, contentType string, b []byte) error {
 	return err
 }
 
-// Stream streams the specified reader into the response.
+// Stream streams the specified reader into the response, the copy is aborted on the client disconnect.
 func (e *Event) Stream(status int, contentType string, reader io.Reader) error {
+	return e.StreamFlush(status, contentType, reader, 0)
+}
+
+// StreamFlush sends a streaming response like [Event.Stream] flushing the data to the client
+// per the flush interval (see [CopyStream]), the copy is aborted on the client disconnect.
+func (e *Event) StreamFlush(status int, contentType string, reader io.Reader, flushInterval time.Duration) error {
 	SetHeaderIfMissing(e.response, HeaderContentType, contentType)
 	e.response.WriteHeader(status)
-	_, err := io.Copy(e.response, reader)
+	_, err := CopyStream(e.Context(), e.response, reader, flushInterval)
 	return err
 }
 
+// StreamFunc sends a streaming response written by fn, ex. the progress of a long-running
+// operation. The writes block while the client doesn't keep up, and fail once the client is
+// gone, which fn can also watch via [StreamWriter.ClientGone] to stop producing the data.
+// The pending data is flushed when fn returns.
+func (e *Event) StreamFunc(status int, contentType string, fn func(w *StreamWriter) error) error {
+	SetHeaderIfMissing(e.response, HeaderContentType, contentType)
+	e.response.WriteHeader(status)
+
+	sw := newStreamWriter(e.Context(), e.response)
+	if err := fn(sw); err != nil {
+		return err
+	}
+	return sw.Flush()
+}
+
 // NoContent writes a response with no body (ex. 204).
 func (e *Event) NoContent(status int) error {
 	e.response.WriteHeader(status)
@@ -455,9 +699,83 @@ func (e *Event) Redirect(status int, url string) error {
 	return nil
 }
 
+// Errors
+// -------------------------------------------------------------------
+
+// BadRequestError returns [ErrBadRequest] with the detail, the empty one keeps the status text.
+func (e *Event) BadRequestError(detail string) *HTTPError {
+	return ErrBadRequest.WithDetail(detail)
+}
+
+// UnauthorizedError returns [ErrUnauthorized] with the detail, the empty one keeps the status text.
+func (e *Event) UnauthorizedError(detail string) *HTTPError {
+	return ErrUnauthorized.WithDetail(detail)
+}
+
+// ForbiddenError returns [ErrForbidden] with the detail, the empty one keeps the status text.
+func (e *Event) ForbiddenError(detail string) *HTTPError {
+	return ErrForbidden.WithDetail(detail)
+}
+
+// NotFoundError returns [ErrNotFound] with the detail, the empty one keeps the status text.
+//
+//	return e.NotFoundError("user not found").WithCode("user_not_found")
+func (e *Event) NotFoundError(detail string) *HTTPError {
+	return ErrNotFound.WithDetail(detail)
+}
+
+// ConflictError returns [ErrConflict] with the detail, the empty one keeps the status text.
+func (e *Event) ConflictError(detail string) *HTTPError {
+	return ErrConflict.WithDetail(detail)
+}
+
+// ValidationError returns [ErrUnprocessableEntity] with the details of the invalid fields.
+//
+//	return e.ValidationError(map[string]any{"email": "must be a valid email address"})
+func (e *Event) ValidationError(fields map[string]any) *HTTPError {
+	he := ErrUnprocessableEntity.WithDetail("")
+	he.Fields = maps.Clone(fields)
+	return he
+}
+
+// TooManyRequestsError returns [ErrTooManyRequests] with the Retry-After delay.
+func (e *Event) TooManyRequestsError(retryAfter time.Duration) *HTTPError {
+	return ErrTooManyRequests.WithRetryAfter(retryAfter)
+}
+
+// InternalServerError returns [ErrInternalServerError] with err, which is not exposed
+// to the client unless the debug is on.
+func (e *Event) InternalServerError(err error) *HTTPError {
+	return ErrInternalServerError.WithInternal(err)
+}
+
 // Binders
 // -------------------------------------------------------------------
 
+// BindPathParams binds the path values of the matched route to the struct fields tagged with `param`.
+func (e *Event) BindPathParams(dst any) error {
+	if err := bindPathValues(e.request, dst); err != nil {
+		return ErrBadRequest.WithInternal(err)
+	}
+	return nil
+}
+
+// Bind binds the path values, the query params, the headers and the body (see [Event.BindBody])
+// to the bindable object in that order, so the later sources take precedence when a field is
+// tagged for several of them. The empty body is skipped.
+func (e *Event) Bind(dst any) error {
+	if err := e.BindPathParams(dst); err != nil {
+		return err
+	}
+	if err := e.BindQueryParams(dst); err != nil {
+		return err
+	}
+	if err := e.BindHeaders(dst); err != nil {
+		return err
+	}
+	return e.BindBody(dst)
+}
+
 // BindQueryParams binds query params to bindable object
 func (e *Event) BindQueryParams(dst any) error {
 	if err := BindData(dst, e.QueryParams(), "query", nil); err != nil {
@@ -474,11 +792,41 @@ func (e *Event) BindHeaders(dst any) error {
 	return nil
 }
 
+// BodyBytes reads the request body (up to [DefaultMaxBodyBytes]) and caches it. The request
+// body is rewound on each call, so the body can be consumed again, ex. by a signature
+// verification middleware and then by [Event.BindBody].
+func (e *Event) BodyBytes() ([]byte, error) {
+	if e.body == nil {
+		if e.request.Body == nil || e.request.Body == http.NoBody {
+			return nil, nil
+		}
+
+		b, err := io.ReadAll(io.LimitReader(e.request.Body, DefaultMaxBodyBytes+1))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				return nil, ErrStatusRequestEntityTooLarge.WithInternal(err)
+			}
+			return nil, err
+		}
+		if len(b) > DefaultMaxBodyBytes {
+			return nil, ErrStatusRequestEntityTooLarge
+		}
+		_ = e.request.Body.Close()
+
+		e.body = b
+	}
+
+	e.request.Body = io.NopCloser(bytes.NewReader(e.body))
+	return e.body, nil
+}
+
 // BindBody binds request body contents to bindable object
 // NB: then binding forms take note that this implementation uses standard library form parsing
 // which parses form data from BOTH URL and BODY if content type is not MIMEMultipartForm
 // See non-MIMEMultipartForm: https://golang.org/pkg/net/http/#Request.ParseForm
 // See MIMEMultipartForm: https://golang.org/pkg/net/http/#Request.ParseMultipartForm
+// The NDJSON body is bound to a pointer to iter.Seq2[V, error] decoding it lazily, see [BindNDJSON].
 func (e *Event) BindBody(dst any) error {
 	if e.request.ContentLength == 0 {
 		return nil
@@ -501,7 +849,10 @@ func (e *Event) BindBody(dst any) error {
 			body.Reread()
 		}
 	case MIMEApplicationXML, MIMETextXML:
-		if err := xml.NewDecoder(e.request.Body).Decode(dst); err != nil {
+		if err := decodeXML(e.request.Body, dst, xmlDecoderOptions(e.Context())); err != nil {
+			if AsHTTPError(err) != nil {
+				return err
+			}
 			var ute *xml.UnsupportedTypeError
 			if errors.As(err, &ute) {
 				return ErrBadRequest.WithInternal(err).SetMessage(fmt.Sprintf("Unsupported type error: type=%v, error=%v", ute.Type, ute.Error()))
@@ -512,6 +863,8 @@ func (e *Event) BindBody(dst any) error {
 			}
 			return ErrBadRequest.WithInternal(err)
 		}
+	case MIMEApplicationNDJSON:
+		return e.bindNDJSON(dst)
 	case MIMEApplicationForm:
 		params, err := e.FormParams()
 		if err != nil {
@@ -534,6 +887,30 @@ func (e *Event) BindBody(dst any) error {
 	return nil
 }
 
+// countingReader counts the bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+// newCountingReader wraps the body of the request, the empty bodies are kept as is and nil
+// is returned. The reader is allocated per request, since the body may outlive the pooled event,
+// ex. read by the goroutine of the handler.
+func newCountingReader(r *http.Request) *countingReader {
+	if r == nil || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	c := &countingReader{ReadCloser: r.Body}
+	r.Body = c
+	return c
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 func indent(r *http.Request) string {
 	if strings.Contains("&"+r.URL.RawQuery, keyPretty) {
 		return defaultIndent
@@ -2,7 +2,6 @@ package wo
 
 import (
 	"errors"
-	"fmt"
 	"io"
 	"io/fs"
 	"net/http"
@@ -20,10 +19,8 @@ func (e *Event) Inline(fsys fs.FS, file, name string) error {
 	return e.contentDisposition(fsys, file, name, "inline")
 }
 
-var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
-
 func (e *Event) contentDisposition(fsys fs.FS, file, name, dispositionType string) error {
-	e.response.Header().Set(HeaderContentDisposition, fmt.Sprintf(`%s; filename="%s"`, dispositionType, quoteEscaper.Replace(name)))
+	SetContentDisposition(e.response, dispositionType, name)
 	return e.FileFS(fsys, file)
 }
 
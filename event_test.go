@@ -6,10 +6,13 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
 	"net/url"
 	"strings"
 	"testing"
@@ -17,6 +20,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/fingerprint"
+	"github.com/gowool/wo/flags"
 )
 
 // Test structs for binding tests
@@ -94,6 +100,52 @@ func TestEvent_Reset(t *testing.T) {
 	assert.True(t, event.start.Before(time.Now().Add(time.Second)) && event.start.After(time.Now().Add(-time.Second)))
 }
 
+func TestEvent_BytesInOut(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello world"))
+
+	event := new(Event)
+	event.Reset(rec, req)
+	assert.Equal(t, int64(0), event.BytesIn())
+
+	body, err := event.BodyBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(body))
+	assert.Equal(t, int64(11), event.BytesIn())
+
+	// the cached body is not counted again
+	_, err = event.BodyBytes()
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), event.BytesIn())
+
+	require.NoError(t, event.String(http.StatusOK, "ok"))
+	assert.Equal(t, int64(2), event.BytesOut())
+
+	event.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, int64(0), event.BytesIn())
+	assert.Equal(t, int64(0), event.BytesOut())
+}
+
+func TestEvent_BytesIn_Reused(t *testing.T) {
+	first := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("first"))
+	second := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("second body"))
+
+	event := new(Event)
+	event.Reset(httptest.NewRecorder(), first)
+	event.Reset(httptest.NewRecorder(), second)
+
+	// the body of the previous request is read by the handler which outlived it
+	body, err := io.ReadAll(first.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(body))
+	assert.Equal(t, int64(0), event.BytesIn())
+
+	body, err = io.ReadAll(second.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "second body", string(body))
+	assert.Equal(t, int64(11), event.BytesIn())
+}
+
 func TestEvent_SettersAndGetters(t *testing.T) {
 	event, _, _ := newTestEventForEventTest()
 
@@ -324,7 +376,8 @@ func TestEvent_AcceptAndAccepted(t *testing.T) {
 	}{
 		{"single accept", "application/json", []string{"application/json"}},
 		{"multiple accepts", "application/json, text/html", []string{"application/json", "text/html"}},
-		{"with quality", "application/json;q=0.8, text/html;q=0.9", []string{"application/json", "text/html"}},
+		{"with quality", "application/json;q=0.8, text/html;q=0.9", []string{"text/html", "application/json"}},
+		{"with specificity", "*/*;q=0.5, text/*, text/html, image/png;q=0", []string{"text/html", "text/*", "*/*"}},
 		{"empty accept", "", []string{}},
 	}
 
@@ -350,7 +403,8 @@ func TestEvent_AcceptLanguageAndLanguages(t *testing.T) {
 	}{
 		{"single language", "en-US", []string{"en-US"}},
 		{"multiple languages", "en-US, fr-FR", []string{"en-US", "fr-FR"}},
-		{"with quality", "en-US;q=0.8, fr-FR;q=0.9", []string{"en-US", "fr-FR"}},
+		{"with quality", "en-US;q=0.8, fr-FR;q=0.9", []string{"fr-FR", "en-US"}},
+		{"with default quality", "de;q=0.5, en-US, fr-FR;q=0", []string{"en-US", "de"}},
 		{"empty accept language", "", []string{}},
 	}
 
@@ -436,6 +490,129 @@ func TestEvent_QueryParamAndParams(t *testing.T) {
 	assert.Equal(t, "foo=bar&baz=qux&foo=second", event.QueryString())
 }
 
+func TestEvent_QueryParamsE(t *testing.T) {
+	event, _, _ := newTestEventForEventTest()
+
+	event.SetRequest(httptest.NewRequest(http.MethodGet, "/test?foo=bar", nil))
+	params, err := event.QueryParamsE()
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{"foo": {"bar"}}, params)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.URL.RawQuery = "foo=bar&baz=%zz"
+	event.Reset(httptest.NewRecorder(), req)
+
+	params, err = event.QueryParamsE()
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, AsHTTPError(err).Status)
+	assert.Equal(t, url.Values{"foo": {"bar"}}, params)
+
+	// the valid pairs are still available
+	assert.Equal(t, "bar", event.QueryParam("foo"))
+}
+
+func TestEvent_QueryTyped(t *testing.T) {
+	def := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedInt    int
+		expectedBool   bool
+		expectedTime   time.Time
+		expectedStatus int
+	}{
+		{
+			name:         "defaults",
+			query:        "",
+			expectedInt:  10,
+			expectedBool: true,
+			expectedTime: def,
+		},
+		{
+			name:         "values",
+			query:        "v=1",
+			expectedInt:  1,
+			expectedBool: true,
+			expectedTime: def,
+		},
+		{
+			name:           "malformed value",
+			query:          "v=abc",
+			expectedInt:    10,
+			expectedBool:   true,
+			expectedTime:   def,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "malformed query",
+			query:          "v=%zz",
+			expectedInt:    10,
+			expectedBool:   true,
+			expectedTime:   def,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.URL.RawQuery = tt.query
+			event, _, _ := newTestEventForEventTest()
+			event.SetRequest(req)
+
+			i, errInt := event.QueryInt("v", 10)
+			b, errBool := event.QueryBool("v", true)
+			tm, errTime := event.QueryTime("v", time.DateOnly, def)
+
+			assert.Equal(t, tt.expectedInt, i)
+			assert.Equal(t, tt.expectedBool, b)
+			assert.Equal(t, tt.expectedTime, tm)
+
+			if tt.expectedStatus == 0 {
+				require.NoError(t, errInt)
+				require.NoError(t, errBool)
+				return
+			}
+			for _, err := range []error{errInt, errBool, errTime} {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedStatus, AsHTTPError(err).Status)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?at=2024-05-06", nil)
+	event, _, _ := newTestEventForEventTest()
+	event.SetRequest(req)
+	tm, err := event.QueryTime("at", time.DateOnly, def)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.May, 6, 0, 0, 0, 0, time.UTC), tm)
+}
+
+func TestEvent_FormParamsE(t *testing.T) {
+	event, _, req := newTestEventWithBody(http.MethodPost, "/", strings.NewReader("name=%zz"), MIMEApplicationForm)
+	event.SetRequest(req)
+
+	_, err := event.FormParamsE()
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, AsHTTPError(err).Status)
+
+	event, _, req = newTestEventWithBody(http.MethodPost, "/", strings.NewReader("name=John"), MIMEApplicationForm)
+	req.Body = http.MaxBytesReader(httptest.NewRecorder(), req.Body, 2)
+	event.SetRequest(req)
+
+	_, err = event.FormParamsE()
+	require.Error(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, AsHTTPError(err).Status)
+
+	event, _, req = newTestEventWithBody(http.MethodPost, "/", strings.NewReader("name=John"), MIMEApplicationForm)
+	event.SetRequest(req)
+
+	form, err := event.FormParamsE()
+	require.NoError(t, err)
+	assert.Equal(t, "John", form.Get("name"))
+}
+
 func TestEvent_FormValueAndParams(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -822,6 +999,39 @@ func TestEvent_NoContent(t *testing.T) {
 	assert.Equal(t, 0, len(MustUnwrapResponse(resp).Buffer()))
 }
 
+func TestEvent_Errors(t *testing.T) {
+	event, _, _ := newTestEventForEventTest()
+	internal := errors.New("database is down")
+
+	tests := []struct {
+		name            string
+		err             *HTTPError
+		expectedStatus  int
+		expectedMessage any
+	}{
+		{name: "bad request", err: event.BadRequestError("invalid cursor"), expectedStatus: http.StatusBadRequest, expectedMessage: "invalid cursor"},
+		{name: "unauthorized", err: event.UnauthorizedError(""), expectedStatus: http.StatusUnauthorized, expectedMessage: "Unauthorized"},
+		{name: "forbidden", err: event.ForbiddenError("not an owner"), expectedStatus: http.StatusForbidden, expectedMessage: "not an owner"},
+		{name: "not found", err: event.NotFoundError("user not found"), expectedStatus: http.StatusNotFound, expectedMessage: "user not found"},
+		{name: "conflict", err: event.ConflictError("email is taken"), expectedStatus: http.StatusConflict, expectedMessage: "email is taken"},
+		{name: "validation", err: event.ValidationError(nil), expectedStatus: http.StatusUnprocessableEntity, expectedMessage: "Unprocessable Entity"},
+		{name: "too many requests", err: event.TooManyRequestsError(time.Minute), expectedStatus: http.StatusTooManyRequests, expectedMessage: "Too Many Requests"},
+		{name: "internal", err: event.InternalServerError(internal), expectedStatus: http.StatusInternalServerError, expectedMessage: "Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedStatus, tt.err.Status)
+			assert.Equal(t, tt.expectedMessage, tt.err.Message)
+		})
+	}
+
+	fields := map[string]any{"email": "is required"}
+	assert.Equal(t, fields, event.ValidationError(fields).Fields)
+	assert.Equal(t, time.Minute, event.TooManyRequestsError(time.Minute).RetryAfter)
+	assert.ErrorIs(t, event.InternalServerError(internal), internal)
+}
+
 func TestEvent_Redirect(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -927,6 +1137,60 @@ func TestEvent_BindQueryParams(t *testing.T) {
 	assert.Equal(t, 1, query.Page)
 }
 
+func TestEvent_BindPathParams(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.SetPathValue("id", "42")
+	event, _, _ := newTestEventForEventTest()
+	event.SetRequest(req)
+
+	var dst struct {
+		ID int `param:"id"`
+	}
+	require.NoError(t, event.BindPathParams(&dst))
+	assert.Equal(t, 42, dst.ID)
+
+	req.SetPathValue("id", "abc")
+	err := event.BindPathParams(&dst)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, AsHTTPError(err).Status)
+}
+
+func TestEvent_Bind(t *testing.T) {
+	type request struct {
+		ID     int    `param:"id"`
+		Page   int    `query:"page"`
+		Token  string `header:"X-Token"`
+		Name   string `json:"name"`
+		Source string `query:"source" json:"source"`
+	}
+
+	event, _, req := newTestEventWithBody(http.MethodPost, "/users/42?page=2&source=query",
+		strings.NewReader(`{"name":"John","source":"body"}`), MIMEApplicationJSON)
+	req.SetPathValue("id", "42")
+	req.Header.Set("X-Token", "secret")
+	event.SetRequest(req)
+
+	var dst request
+	require.NoError(t, event.Bind(&dst))
+
+	assert.Equal(t, request{ID: 42, Page: 2, Token: "secret", Name: "John", Source: "body"}, dst)
+
+	// the empty body is skipped
+	req = httptest.NewRequest(http.MethodGet, "/users/7?page=3", nil)
+	req.SetPathValue("id", "7")
+	event.Reset(httptest.NewRecorder(), req)
+
+	dst = request{}
+	require.NoError(t, event.Bind(&dst))
+	assert.Equal(t, request{ID: 7, Page: 3}, dst)
+
+	req = httptest.NewRequest(http.MethodGet, "/users/7?page=x", nil)
+	event.Reset(httptest.NewRecorder(), req)
+	err := event.Bind(&dst)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, AsHTTPError(err).Status)
+}
+
 func TestEvent_BindHeaders(t *testing.T) {
 	event, _, req := newTestEventForEventTest()
 	req.Header.Set("Authorization", "Bearer token123")
@@ -1166,3 +1430,150 @@ func BenchmarkEvent_SetValue(b *testing.B) {
 		e.SetValue("benchmarkKey", "benchmarkValue")
 	}
 }
+
+func TestEvent_Locale(t *testing.T) {
+	event, res, _ := newTestEventForEventTest()
+
+	assert.Empty(t, event.Locale())
+
+	event.SetLocale("fr-CA")
+
+	assert.Equal(t, "fr-CA", event.Locale())
+	assert.Equal(t, "fr-CA", Locale(event.Context()))
+	assert.Equal(t, "fr-CA", res.Header().Get(HeaderContentLanguage))
+	assert.Equal(t, HeaderAcceptLanguage, res.Header().Get(HeaderVary))
+
+	event.SetContentLanguage("fr")
+
+	assert.Equal(t, "fr-CA", event.Locale())
+	assert.Equal(t, "fr", res.Header().Get(HeaderContentLanguage))
+	assert.Len(t, res.Header().Values(HeaderVary), 1)
+}
+
+func TestEvent_Fingerprint(t *testing.T) {
+	e, _, r := newTestEventForEventTest()
+	r.RemoteAddr = "192.0.2.1:1234"
+	r.Header.Set("User-Agent", "Mozilla/5.0")
+
+	assert.Equal(t, fingerprint.Of(r, fingerprint.All), e.Fingerprint(fingerprint.All))
+	assert.NotEqual(t, e.Fingerprint(fingerprint.All), e.Fingerprint(fingerprint.IP))
+}
+
+func TestEvent_Flag(t *testing.T) {
+	e, _, _ := newTestEventForEventTest()
+
+	assert.False(t, e.Flag("checkout"))
+	assert.Empty(t, e.FlagString("checkout"))
+
+	e.SetContext(flags.WithValues(e.Context(), flags.Values{"checkout": {Enabled: true, Variant: "v2"}}))
+
+	assert.True(t, e.Flag("checkout"))
+	assert.Equal(t, "v2", e.FlagString("checkout"))
+}
+
+func TestEvent_EarlyHints(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/", func(e *Event) error {
+		e.Response().Header().Set(HeaderCacheControl, "no-store")
+
+		if err := e.EarlyHints("/app.css", "</app.js>; rel=preload; as=script"); err != nil {
+			return err
+		}
+		return e.String(http.StatusOK, "ok")
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	var hints []http.Header
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = append(hints, http.Header(header).Clone())
+			}
+			return nil
+		},
+	}
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	res, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	defer func() { _ = res.Body.Close() }()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, "no-store", res.Header.Get(HeaderCacheControl))
+
+	require.Len(t, hints, 1)
+	assert.Equal(t, []string{"</app.css>; rel=preload", "</app.js>; rel=preload; as=script"}, hints[0].Values(HeaderLink))
+	assert.Empty(t, hints[0].Get(HeaderCacheControl))
+}
+
+func TestEvent_EarlyHints_NotSupported(t *testing.T) {
+	e, _, r := newTestEventForEventTest()
+
+	r.ProtoMajor, r.ProtoMinor = 1, 0
+	assert.ErrorIs(t, e.EarlyHints("/app.css"), http.ErrNotSupported)
+
+	r.ProtoMajor, r.ProtoMinor = 1, 1
+	MustUnwrapResponse(e.Response()).Buffering = true
+	assert.ErrorIs(t, e.EarlyHints("/app.css"), http.ErrNotSupported)
+
+	MustUnwrapResponse(e.Response()).Buffering = false
+	e.Response().WriteHeader(http.StatusOK)
+	assert.Error(t, e.EarlyHints("/app.css"))
+}
+
+func TestEvent_BodyBytes(t *testing.T) {
+	t.Run("rewinds the body", func(t *testing.T) {
+		e, _, _ := newTestEventWithBody(http.MethodPost, "/", strings.NewReader(`{"name":"John","age":30}`), MIMEApplicationJSON)
+
+		b, err := e.BodyBytes()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"John","age":30}`, string(b))
+
+		var user TestUser
+		require.NoError(t, e.BindBody(&user))
+		assert.Equal(t, TestUser{Name: "John", Age: 30}, user)
+
+		b2, err := e.BodyBytes()
+		require.NoError(t, err)
+		assert.Equal(t, b, b2)
+
+		rest, err := io.ReadAll(e.Request().Body)
+		require.NoError(t, err)
+		assert.Equal(t, b, rest)
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		e, _, _ := newTestEventForEventTest()
+
+		b, err := e.BodyBytes()
+		require.NoError(t, err)
+		assert.Empty(t, b)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		e, _, _ := newTestEventWithBody(http.MethodPost, "/", bytes.NewReader(make([]byte, DefaultMaxBodyBytes+1)), MIMEOctetStream)
+
+		_, err := e.BodyBytes()
+		assert.ErrorIs(t, err, ErrStatusRequestEntityTooLarge)
+	})
+
+	t.Run("body limit", func(t *testing.T) {
+		e, _, r := newTestEventWithBody(http.MethodPost, "/", strings.NewReader("0123456789"), MIMEOctetStream)
+		r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 5)
+
+		_, err := e.BodyBytes()
+		require.Error(t, err)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, AsHTTPError(err).Status)
+	})
+}
@@ -0,0 +1,194 @@
+package wo
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"iter"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// exportCheckEvery is the number of the rows between the checks of the client disconnect.
+const exportCheckEvery = 256
+
+// CSV streams the rows as the CSV attachment, the header row is written first unless empty.
+// The Content-Disposition defaults to the "export.csv" attachment, set it beforehand for
+// another name, see [SetContentDisposition]. The cells starting with the formula characters
+// (=, +, -, @, tab, carriage return), which aren't numbers, are prefixed with a quote, so the
+// spreadsheets don't evaluate them. The export is aborted on the client disconnect.
+func (e *Event) CSV(status int, header []string, rows iter.Seq[[]string]) error {
+	SetHeaderIfMissing(e.response, HeaderContentType, MIMETextCSVCharsetUTF8)
+	if e.response.Header().Get(HeaderContentDisposition) == "" {
+		SetContentDisposition(e.response, "attachment", "export.csv")
+	}
+	e.response.WriteHeader(status)
+
+	w := csv.NewWriter(e.response)
+	record := make([]string, 0, len(header))
+
+	write := func(row []string) error {
+		record = record[:0]
+		for _, cell := range row {
+			record = append(record, escapeFormula(cell))
+		}
+		return w.Write(record)
+	}
+
+	if len(header) > 0 {
+		if err := write(header); err != nil {
+			return err
+		}
+	}
+
+	n := 0
+	for row := range rows {
+		if n++; n%exportCheckEvery == 0 {
+			if err := e.Context().Err(); err != nil {
+				return err
+			}
+		}
+		if err := write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// XLSX streams the rows as the single sheet Excel workbook attachment like [Event.CSV], the
+// Content-Disposition defaults to the "export.xlsx" attachment. The cells holding the numbers
+// in the canonical form are written as the numbers, the others as the strings.
+func (e *Event) XLSX(status int, sheet string, header []string, rows iter.Seq[[]string]) error {
+	SetHeaderIfMissing(e.response, HeaderContentType, MIMEApplicationXLSX)
+	if e.response.Header().Get(HeaderContentDisposition) == "" {
+		SetContentDisposition(e.response, "attachment", "export.xlsx")
+	}
+	e.response.WriteHeader(status)
+
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	zw := zip.NewWriter(e.response)
+	for _, part := range xlsxParts(sheet) {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriterSize(f, 32<<10)
+	_, _ = bw.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	n := 0
+	if len(header) > 0 {
+		n++
+		writeXLSXRow(bw, n, header)
+	}
+	for row := range rows {
+		if n++; n%exportCheckEvery == 0 {
+			if err = e.Context().Err(); err != nil {
+				return err
+			}
+		}
+		writeXLSXRow(bw, n, row)
+	}
+
+	_, _ = bw.WriteString(`</sheetData></worksheet>`)
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+type xlsxPart struct {
+	name    string
+	content string
+}
+
+func xlsxParts(sheet string) []xlsxPart {
+	var name strings.Builder
+	_ = xml.EscapeText(&name, []byte(xlsxSheetName(sheet)))
+
+	return []xlsxPart{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+}
+
+// xlsxSheetName returns the sheet name without the characters Excel rejects, cut to 31 runes.
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/?*[]:`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	return name
+}
+
+func writeXLSXRow(w *bufio.Writer, n int, row []string) {
+	_, _ = w.WriteString(`<row r="`)
+	_, _ = w.WriteString(strconv.Itoa(n))
+	_, _ = w.WriteString(`">`)
+	for _, cell := range row {
+		if isCanonicalNumber(cell) {
+			_, _ = w.WriteString(`<c><v>`)
+			_, _ = w.WriteString(cell)
+			_, _ = w.WriteString(`</v></c>`)
+			continue
+		}
+		_, _ = w.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		_ = xml.EscapeText(w, []byte(cell))
+		_, _ = w.WriteString(`</t></is></c>`)
+	}
+	_, _ = w.WriteString(`</row>`)
+}
+
+// escapeFormula prefixes the cell starting with a formula character with a quote, unless
+// it's a number, ex. -5.
+func escapeFormula(cell string) string {
+	if cell == "" || !strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return cell
+	}
+	if _, err := strconv.ParseFloat(cell, 64); err == nil {
+		return cell
+	}
+	return "'" + cell
+}
+
+// isCanonicalNumber reports whether the cell is the number written as Go formats it, so
+// the number cell shows the same text, ex. "0012" or "1e3" stay the strings.
+func isCanonicalNumber(cell string) bool {
+	f, err := strconv.ParseFloat(cell, 64)
+	return err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) && strconv.FormatFloat(f, 'f', -1, 64) == cell
+}
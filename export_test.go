@@ -0,0 +1,116 @@
+package wo
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent_CSV(t *testing.T) {
+	rec := httptest.NewRecorder()
+	e := new(Event)
+	e.Reset(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	rows := slices.Values([][]string{
+		{"1", "Ann", "-5", "=HYPERLINK(\"http://evil\")"},
+		{"2", "Dan \"the man\"", "+1.5", "@SUM(A1:A2)"},
+		{"3", "line\nbreak", "-", "plain"},
+	})
+	require.NoError(t, e.CSV(http.StatusOK, []string{"id", "name", "delta", "note"}, rows))
+
+	assert.Equal(t, MIMETextCSVCharsetUTF8, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `attachment; filename="export.csv"`, rec.Header().Get(HeaderContentDisposition))
+	assert.Equal(t, "id,name,delta,note\n"+
+		"1,Ann,-5,\"'=HYPERLINK(\"\"http://evil\"\")\"\n"+
+		"2,\"Dan \"\"the man\"\"\",+1.5,'@SUM(A1:A2)\n"+
+		"3,\"line\nbreak\",'-,plain\n", rec.Body.String())
+}
+
+func TestEvent_CSV_ContentDisposition(t *testing.T) {
+	rec := httptest.NewRecorder()
+	e := new(Event)
+	e.Reset(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	SetContentDisposition(rec, "attachment", "orders.csv")
+	require.NoError(t, e.CSV(http.StatusOK, nil, slices.Values([][]string{{"1"}})))
+
+	assert.Equal(t, `attachment; filename="orders.csv"`, rec.Header().Get(HeaderContentDisposition))
+	assert.Equal(t, "1\n", rec.Body.String())
+}
+
+func TestEvent_CSV_ClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e := new(Event)
+	e.Reset(httptest.NewRecorder(), httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+
+	produced := 0
+	rows := func(yield func([]string) bool) {
+		for {
+			if produced++; produced == 10 {
+				cancel()
+			}
+			if !yield([]string{"row"}) {
+				return
+			}
+		}
+	}
+
+	require.ErrorIs(t, e.CSV(http.StatusOK, nil, rows), context.Canceled)
+	assert.Equal(t, exportCheckEvery, produced)
+}
+
+func TestEvent_XLSX(t *testing.T) {
+	rec := httptest.NewRecorder()
+	e := new(Event)
+	e.Reset(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	rows := slices.Values([][]string{
+		{"1", "Ann & <Co>", "0012"},
+		{"2.5", "Dan", "-3"},
+	})
+	require.NoError(t, e.XLSX(http.StatusOK, "Orders [2024]", []string{"id", "name", "code"}, rows))
+
+	assert.Equal(t, MIMEApplicationXLSX, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `attachment; filename="export.xlsx"`, rec.Header().Get(HeaderContentDisposition))
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[f.Name] = string(b)
+	}
+
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files, "_rels/.rels")
+	require.Contains(t, files, "xl/_rels/workbook.xml.rels")
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Orders _2024_" sheetId="1" r:id="rId1"/>`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<row r="1"><c t="inlineStr"><is><t xml:space="preserve">id</t></is></c>`)
+	assert.Contains(t, sheet, `<row r="2"><c><v>1</v></c><c t="inlineStr"><is><t xml:space="preserve">Ann &amp; &lt;Co&gt;</t></is></c>`+
+		`<c t="inlineStr"><is><t xml:space="preserve">0012</t></is></c></row>`)
+	assert.Contains(t, sheet, `<row r="3"><c><v>2.5</v></c>`)
+	assert.Contains(t, sheet, `<c><v>-3</v></c></row></sheetData></worksheet>`)
+}
+
+func TestIsCanonicalNumber(t *testing.T) {
+	for cell, expected := range map[string]bool{
+		"0": true, "42": true, "-3.25": true, "0012": false, "1e3": false,
+		"1.50": false, "NaN": false, "Inf": false, "": false, "12abc": false,
+	} {
+		assert.Equal(t, expected, isCanonicalNumber(cell), cell)
+	}
+}
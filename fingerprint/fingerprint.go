@@ -0,0 +1,93 @@
+// Package fingerprint computes stable request fingerprints from the client IP, the User-Agent,
+// the Accept-Language and the TLS parameters, ex. to identify the clients for the rate limiting
+// or to detect the session anomalies (a session token replayed by another client).
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+)
+
+// Component is a set of the request attributes included into the fingerprint.
+type Component uint8
+
+const (
+	// IP is the client IP address of the request (the remote address without the port).
+	IP Component = 1 << iota
+
+	// UserAgent is the User-Agent header.
+	UserAgent
+
+	// AcceptLanguage is the Accept-Language header.
+	AcceptLanguage
+
+	// TLS is the JA3 fingerprint of the TLS client hello when recorded (see [ClientHelloRecorder]),
+	// otherwise the negotiated TLS version, cipher suite, application protocol and server name.
+	TLS
+
+	// Client are the attributes of the client software, which don't change when the client
+	// changes the network, ex. a mobile device switching between Wi-Fi and cellular.
+	Client = UserAgent | AcceptLanguage | TLS
+
+	// All are all attributes.
+	All = IP | Client
+)
+
+// Of returns the hex-encoded SHA-256 fingerprint of the request attributes.
+// The fingerprint is stable for the same attributes.
+func Of(r *http.Request, components Component) string {
+	h := sha256.New()
+
+	if components&IP != 0 {
+		write(h, 'i', RemoteIP(r))
+	}
+	if components&UserAgent != 0 {
+		write(h, 'u', r.UserAgent())
+	}
+	if components&AcceptLanguage != 0 {
+		write(h, 'l', r.Header.Get("Accept-Language"))
+	}
+	if components&TLS != 0 {
+		write(h, 't', tlsParams(r))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RemoteIP returns the normalized IP address of the request remote address.
+func RemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.Unmap().WithZone("").String()
+	}
+	return host
+}
+
+func tlsParams(r *http.Request) string {
+	if ja3 := JA3FromContext(r.Context()); ja3 != "" {
+		return ja3
+	}
+	if r.TLS == nil {
+		return ""
+	}
+	return strconv.Itoa(int(r.TLS.Version)) + "," +
+		strconv.Itoa(int(r.TLS.CipherSuite)) + "," +
+		r.TLS.NegotiatedProtocol + "," +
+		r.TLS.ServerName
+}
+
+// write writes the tagged, length-prefixed value, so the values can't be shifted between the components.
+func write(h hash.Hash, tag byte, value string) {
+	_, _ = h.Write([]byte{tag})
+	_, _ = h.Write(strconv.AppendInt(nil, int64(len(value)), 10))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(value))
+}
@@ -0,0 +1,88 @@
+package fingerprint
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRequest(remoteAddr, ua, lang string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = remoteAddr
+	r.Header.Set("User-Agent", ua)
+	r.Header.Set("Accept-Language", lang)
+	return r
+}
+
+func TestOf(t *testing.T) {
+	base := newRequest("192.0.2.1:1234", "Mozilla/5.0", "en-US")
+
+	tests := []struct {
+		name       string
+		other      *http.Request
+		components Component
+		equal      bool
+	}{
+		{"same attributes", newRequest("192.0.2.1:1234", "Mozilla/5.0", "en-US"), All, true},
+		{"other port", newRequest("192.0.2.1:4321", "Mozilla/5.0", "en-US"), All, true},
+		{"mapped IPv4", newRequest("[::ffff:192.0.2.1]:1234", "Mozilla/5.0", "en-US"), All, true},
+		{"other IP", newRequest("192.0.2.2:1234", "Mozilla/5.0", "en-US"), All, false},
+		{"other IP without IP component", newRequest("192.0.2.2:1234", "Mozilla/5.0", "en-US"), Client, true},
+		{"other user agent", newRequest("192.0.2.1:1234", "curl/8.0", "en-US"), All, false},
+		{"other language", newRequest("192.0.2.1:1234", "Mozilla/5.0", "fr"), All, false},
+		{"other language without language component", newRequest("192.0.2.1:1234", "Mozilla/5.0", "fr"), IP | UserAgent, true},
+		{"shifted values", newRequest("192.0.2.1:1234", "Mozilla/5.0en-US", ""), All, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := Of(base, tt.components)
+			assert.Len(t, expected, 64)
+
+			if tt.equal {
+				assert.Equal(t, expected, Of(tt.other, tt.components))
+			} else {
+				assert.NotEqual(t, expected, Of(tt.other, tt.components))
+			}
+		})
+	}
+}
+
+func TestOf_TLS(t *testing.T) {
+	plain := newRequest("192.0.2.1:1234", "Mozilla/5.0", "en-US")
+
+	secure := newRequest("192.0.2.1:1234", "Mozilla/5.0", "en-US")
+	secure.TLS = &tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256}
+
+	other := newRequest("192.0.2.1:1234", "Mozilla/5.0", "en-US")
+	other.TLS = &tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}
+
+	assert.Equal(t, Of(plain, IP|UserAgent), Of(secure, IP|UserAgent))
+	assert.NotEqual(t, Of(plain, All), Of(secure, All))
+	assert.NotEqual(t, Of(secure, All), Of(other, All))
+}
+
+func TestRemoteIP(t *testing.T) {
+	tests := []struct {
+		remoteAddr string
+		expected   string
+	}{
+		{"192.0.2.1:1234", "192.0.2.1"},
+		{"[2001:db8::1]:1234", "2001:db8::1"},
+		{"[::ffff:192.0.2.1]:1234", "192.0.2.1"},
+		{"[fe80::1%eth0]:1234", "fe80::1"},
+		{"192.0.2.1", "192.0.2.1"},
+		{"invalid", "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			assert.Equal(t, tt.expected, RemoteIP(r))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package fingerprint
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // JA3 is defined as MD5
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type ctxClientHelloKey struct{}
+
+// JA3 returns the JA3-like fingerprint of the TLS client hello: the MD5 hash of the TLS version,
+// the cipher suites, the extensions, the elliptic curves and the point formats with the GREASE
+// values removed. Unlike JA3, the version is the highest supported one instead of the legacy
+// record version, which the [tls.ClientHelloInfo] doesn't expose.
+//
+// See: https://github.com/salesforce/ja3
+func JA3(hello *tls.ClientHelloInfo) string {
+	var version uint16
+	for _, v := range hello.SupportedVersions {
+		if !isGREASE(v) && v > version {
+			version = v
+		}
+	}
+
+	curves := make([]uint16, len(hello.SupportedCurves))
+	for i, c := range hello.SupportedCurves {
+		curves[i] = uint16(c)
+	}
+	points := make([]uint16, len(hello.SupportedPoints))
+	for i, p := range hello.SupportedPoints {
+		points[i] = uint16(p)
+	}
+
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(int(version)))
+	for _, values := range [][]uint16{hello.CipherSuites, hello.Extensions, curves, points} {
+		b.WriteByte(',')
+		writeValues(&b, values)
+	}
+
+	sum := md5.Sum([]byte(b.String())) //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
+
+func writeValues(b *strings.Builder, values []uint16) {
+	first := true
+	for _, v := range values {
+		if isGREASE(v) {
+			continue
+		}
+		if !first {
+			b.WriteByte('-')
+		}
+		b.WriteString(strconv.Itoa(int(v)))
+		first = false
+	}
+}
+
+// isGREASE reports whether the value is reserved by RFC 8701 to prevent the extensibility failures.
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+// JA3FromContext returns the JA3 fingerprint recorded by the [ClientHelloRecorder]
+// for the connection of the request context, or an empty string.
+func JA3FromContext(ctx context.Context) string {
+	if h, ok := ctx.Value(ctxClientHelloKey{}).(*clientHello); ok {
+		if ja3 := h.ja3.Load(); ja3 != nil {
+			return *ja3
+		}
+	}
+	return ""
+}
+
+type clientHello struct {
+	ja3 atomic.Pointer[string]
+}
+
+// ClientHelloRecorder records the JA3 fingerprints (see [JA3]) of the TLS connections,
+// so they are available to the request handlers, see [JA3FromContext] and [TLS].
+//
+//	var rec fingerprint.ClientHelloRecorder
+//	srv := &http.Server{
+//		TLSConfig:   rec.TLSConfig(tlsConfig),
+//		ConnContext: rec.ConnContext,
+//		ConnState:   rec.ConnState,
+//	}
+type ClientHelloRecorder struct {
+	conns sync.Map // net.Conn -> *clientHello
+}
+
+// TLSConfig returns a clone of the TLS config recording the client hello of the connections.
+// The GetConfigForClient function of the config is preserved.
+func (rec *ClientHelloRecorder) TLSConfig(cfg *tls.Config) *tls.Config {
+	cfg = cfg.Clone()
+	if cfg == nil {
+		cfg = new(tls.Config)
+	}
+
+	getConfigForClient := cfg.GetConfigForClient
+	cfg.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+		if h, ok := rec.conns.LoadAndDelete(hello.Conn); ok {
+			ja3 := JA3(hello)
+			h.(*clientHello).ja3.Store(&ja3)
+		}
+		if getConfigForClient != nil {
+			return getConfigForClient(hello)
+		}
+		return nil, nil
+	}
+	return cfg
+}
+
+// ConnContext prepares the recording of the TLS connection client hello, it must be used
+// as (or called by) the [http.Server] ConnContext.
+func (rec *ClientHelloRecorder) ConnContext(ctx context.Context, c net.Conn) context.Context {
+	tc, ok := c.(*tls.Conn)
+	if !ok {
+		return ctx
+	}
+
+	h := new(clientHello)
+	rec.conns.Store(tc.NetConn(), h)
+	return context.WithValue(ctx, ctxClientHelloKey{}, h)
+}
+
+// ConnState releases the connections which never completed the handshake, it must be used
+// as (or called by) the [http.Server] ConnState.
+func (rec *ClientHelloRecorder) ConnState(c net.Conn, state http.ConnState) {
+	if state == http.StateNew {
+		return
+	}
+	if tc, ok := c.(*tls.Conn); ok {
+		rec.conns.Delete(tc.NetConn())
+	}
+}
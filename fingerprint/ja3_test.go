@@ -0,0 +1,85 @@
+package fingerprint
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJA3(t *testing.T) {
+	hello := &tls.ClientHelloInfo{
+		SupportedVersions: []uint16{0x1a1a, tls.VersionTLS13, tls.VersionTLS12},
+		CipherSuites:      []uint16{0x2a2a, 4865, 4866},
+		Extensions:        []uint16{0x3a3a, 0, 10, 11},
+		SupportedCurves:   []tls.CurveID{0x4a4a, tls.X25519, tls.CurveP256},
+		SupportedPoints:   []uint8{0},
+	}
+
+	sum := md5.Sum([]byte("772,4865-4866,0-10-11,29-23,0")) //nolint:gosec
+	assert.Equal(t, hex.EncodeToString(sum[:]), JA3(hello))
+
+	empty := md5.Sum([]byte("0,,,,")) //nolint:gosec
+	assert.Equal(t, hex.EncodeToString(empty[:]), JA3(&tls.ClientHelloInfo{}))
+}
+
+func TestIsGREASE(t *testing.T) {
+	assert.True(t, isGREASE(0x0a0a))
+	assert.True(t, isGREASE(0xfafa))
+	assert.False(t, isGREASE(0x0a1a))
+	assert.False(t, isGREASE(tls.VersionTLS13))
+}
+
+func TestClientHelloRecorder(t *testing.T) {
+	var rec ClientHelloRecorder
+
+	getConfigForClientCalled := false
+
+	var (
+		ja3         string
+		fingerprint string
+	)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ja3 = JA3FromContext(r.Context())
+		fingerprint = Of(r, TLS)
+	}))
+	srv.TLS = rec.TLSConfig(&tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			getConfigForClientCalled = true
+			return nil, nil
+		},
+	})
+	srv.Config.ConnContext = rec.ConnContext
+	srv.Config.ConnState = rec.ConnState
+	srv.StartTLS()
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	assert.True(t, getConfigForClientCalled)
+	assert.Len(t, ja3, 32)
+	assert.Equal(t, Of(newJA3Request(ja3), TLS), fingerprint)
+
+	count := 0
+	rec.conns.Range(func(any, any) bool {
+		count++
+		return true
+	})
+	assert.Zero(t, count)
+}
+
+func newJA3Request(ja3 string) *http.Request {
+	h := new(clientHello)
+	h.ja3.Store(&ja3)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	return r.WithContext(context.WithValue(r.Context(), ctxClientHelloKey{}, h))
+}
@@ -0,0 +1,234 @@
+// Package flags evaluates the feature flags for the progressive delivery: the boolean flags,
+// the string ones with the variants, and the percentage rollouts sticky to the identity, ex.
+// the user ID, so the user keeps the decision across the requests and the instances. The flags
+// evaluated by the middleware are carried in the request context:
+//
+//	provider := flags.NewStaticProvider(
+//		flags.Flag{Name: "new-checkout", Enabled: true, Percentage: 10},
+//		flags.Flag{Name: "theme", Enabled: true, Percentage: flags.All, Variants: []flags.Variant{{Value: "light", Weight: 1}, {Value: "dark", Weight: 1}}},
+//	)
+//
+//	if e.Flag("new-checkout") {
+//		return newCheckout(e)
+//	}
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// buckets is the resolution of the percentage rollouts, 0.01%.
+const buckets = 10000
+
+// Percentage is the share of the identities of the rollout, from 0 to 100. The configurations
+// may set it to "all", which is [All].
+type Percentage float64
+
+// All is the percentage of everyone.
+const All Percentage = 100
+
+func (p *Percentage) UnmarshalJSON(data []byte) error {
+	var all string
+	if json.Unmarshal(data, &all) == nil {
+		return p.parse(all)
+	}
+	return json.Unmarshal(data, (*float64)(p))
+}
+
+func (p *Percentage) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode && value.Tag == "!!str" {
+		return p.parse(value.Value)
+	}
+	return value.Decode((*float64)(p))
+}
+
+func (p *Percentage) parse(s string) error {
+	if s != "all" {
+		return fmt.Errorf("flags: invalid percentage %q", s)
+	}
+	*p = All
+	return nil
+}
+
+// Flag is the definition of the feature flag.
+type Flag struct {
+	// Name is the name of the flag.
+	Name string `json:"name" yaml:"name"`
+
+	// Enabled is the kill switch of the flag, the disabled flag is off for everyone.
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// Percentage is the share of the identities the enabled flag is on for, from 0 to 100,
+	// zero is nobody except Allow and [All] is everyone. The identities are bucketed by the hash
+	// of the flag name and the identity, so the rollouts of the flags are independent and
+	// increasing the percentage keeps the identities already included.
+	Percentage Percentage `json:"percentage,omitempty" yaml:"percentage,omitempty"`
+
+	// Allow are the identities the enabled flag is always on for, ex. the staff.
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+
+	// Deny are the identities the flag is always off for.
+	Deny []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+
+	// Value is the string value of the flag which is on, unless it has the variants.
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// Default is the string value of the flag which is off.
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Variants are the weighted string values of the flag which is on, ex. for the A/B test,
+	// the identities are assigned to the variants by the hash, like to the rollout.
+	Variants []Variant `json:"variants,omitempty" yaml:"variants,omitempty"`
+}
+
+// Variant is the weighted string value of the flag.
+type Variant struct {
+	Value  string `json:"value" yaml:"value"`
+	Weight int    `json:"weight" yaml:"weight"`
+}
+
+// Value is the result of the flag evaluation.
+type Value struct {
+	// Enabled reports whether the flag is on.
+	Enabled bool `json:"enabled"`
+
+	// Variant is the string value of the flag, see Flag.Value, Flag.Default and Flag.Variants.
+	Variant string `json:"variant,omitempty"`
+}
+
+// Evaluate evaluates the flag for the identity. The partial rollouts and the variants require
+// the identity, the flag is off for the empty one.
+func Evaluate(f Flag, identity string) Value {
+	off := Value{Variant: f.Default}
+
+	if !f.Enabled || (identity != "" && slices.Contains(f.Deny, identity)) {
+		return off
+	}
+
+	if identity == "" || !slices.Contains(f.Allow, identity) {
+		if f.Percentage < All && (f.Percentage <= 0 || identity == "" || float64(bucket(f.Name, identity)) >= float64(f.Percentage)*buckets/100) {
+			return off
+		}
+	}
+
+	if len(f.Variants) == 0 {
+		return Value{Enabled: true, Variant: f.Value}
+	}
+
+	var total int
+	for _, v := range f.Variants {
+		total += max(0, v.Weight)
+	}
+	if total == 0 || identity == "" {
+		return off
+	}
+
+	n := int(bucket(f.Name+":variant", identity) % uint64(total))
+	for _, v := range f.Variants {
+		if n < max(0, v.Weight) {
+			return Value{Enabled: true, Variant: v.Value}
+		}
+		n -= max(0, v.Weight)
+	}
+	return off
+}
+
+// bucket returns the stable bucket of the identity for the flag.
+func bucket(name, identity string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(identity))
+	return h.Sum64() % buckets
+}
+
+// Provider provides the flag definitions, ex. from the configuration, the database or
+// the remote flag service.
+type Provider interface {
+	// Flag returns the definition of the flag, found is false if it doesn't exist.
+	Flag(ctx context.Context, name string) (f Flag, found bool, err error)
+}
+
+var _ Provider = (*StaticProvider)(nil)
+
+// StaticProvider is the in-memory [Provider], the flags can be changed at runtime, ex. by
+// the admin endpoint or the configuration reload.
+type StaticProvider struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+func NewStaticProvider(flags ...Flag) *StaticProvider {
+	p := &StaticProvider{flags: make(map[string]Flag, len(flags))}
+	for _, f := range flags {
+		p.flags[f.Name] = f
+	}
+	return p
+}
+
+func (p *StaticProvider) Flag(_ context.Context, name string) (Flag, bool, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	f, ok := p.flags[name]
+	return f, ok, nil
+}
+
+// Set adds or replaces the flag.
+func (p *StaticProvider) Set(f Flag) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.flags[f.Name] = f
+}
+
+// Delete deletes the flag.
+func (p *StaticProvider) Delete(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.flags, name)
+}
+
+// Values are the evaluated flags of the request by their names.
+type Values map[string]Value
+
+// Enabled reports whether the flag is evaluated and on.
+func (v Values) Enabled(name string) bool {
+	return v[name].Enabled
+}
+
+// String returns the string value of the evaluated flag, empty if it isn't evaluated.
+func (v Values) String(name string) string {
+	return v[name].Variant
+}
+
+type contextKey struct{}
+
+// WithValues returns a copy of ctx carrying the evaluated flags.
+func WithValues(ctx context.Context, values Values) context.Context {
+	return context.WithValue(ctx, contextKey{}, values)
+}
+
+// FromContext returns the evaluated flags carried by ctx, nil if there are none.
+func FromContext(ctx context.Context) Values {
+	values, _ := ctx.Value(contextKey{}).(Values)
+	return values
+}
+
+// Enabled reports whether the flag carried by ctx is on, the flags which aren't evaluated are off.
+func Enabled(ctx context.Context, name string) bool {
+	return FromContext(ctx).Enabled(name)
+}
+
+// String returns the string value of the flag carried by ctx, empty if it isn't evaluated.
+func String(ctx context.Context, name string) string {
+	return FromContext(ctx).String(name)
+}
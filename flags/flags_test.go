@@ -0,0 +1,155 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     Flag
+		identity string
+		expected Value
+	}{
+		{name: "disabled", flag: Flag{Name: "f", Default: "old"}, identity: "u1", expected: Value{Variant: "old"}},
+		{name: "enabled", flag: Flag{Name: "f", Enabled: true, Percentage: All, Value: "new"}, identity: "u1", expected: Value{Enabled: true, Variant: "new"}},
+		{name: "enabled without identity", flag: Flag{Name: "f", Enabled: true, Percentage: All}, expected: Value{Enabled: true}},
+		{name: "no rollout", flag: Flag{Name: "f", Enabled: true, Default: "old"}, identity: "u1", expected: Value{Variant: "old"}},
+		{name: "full rollout", flag: Flag{Name: "f", Enabled: true, Percentage: 100}, identity: "u1", expected: Value{Enabled: true}},
+		{name: "partial rollout without identity", flag: Flag{Name: "f", Enabled: true, Percentage: 50}, expected: Value{}},
+		{name: "denied", flag: Flag{Name: "f", Enabled: true, Percentage: All, Deny: []string{"u1"}}, identity: "u1", expected: Value{}},
+		{name: "allowed", flag: Flag{Name: "f", Enabled: true, Percentage: 0.01, Allow: []string{"u1"}}, identity: "u1", expected: Value{Enabled: true}},
+		{name: "allowed without rollout", flag: Flag{Name: "f", Enabled: true, Allow: []string{"u1"}}, identity: "u1", expected: Value{Enabled: true}},
+		{name: "allowed but disabled", flag: Flag{Name: "f", Allow: []string{"u1"}}, identity: "u1", expected: Value{}},
+		{
+			name:     "single variant",
+			flag:     Flag{Name: "f", Enabled: true, Percentage: All, Default: "a", Variants: []Variant{{Value: "b", Weight: 1}, {Value: "c"}}},
+			identity: "u1",
+			expected: Value{Enabled: true, Variant: "b"},
+		},
+		{
+			name:     "variants without identity",
+			flag:     Flag{Name: "f", Enabled: true, Percentage: All, Default: "a", Variants: []Variant{{Value: "b", Weight: 1}}},
+			expected: Value{Variant: "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Evaluate(tt.flag, tt.identity))
+		})
+	}
+}
+
+func TestEvaluate_Rollout(t *testing.T) {
+	flag := Flag{Name: "rollout", Enabled: true, Percentage: 25}
+
+	enabled := make(map[string]bool)
+	for i := range 10000 {
+		identity := "user-" + strconv.Itoa(i)
+		enabled[identity] = Evaluate(flag, identity).Enabled
+
+		// the evaluation is sticky
+		assert.Equal(t, enabled[identity], Evaluate(flag, identity).Enabled)
+	}
+
+	var n int
+	for _, on := range enabled {
+		if on {
+			n++
+		}
+	}
+	assert.InDelta(t, 2500, n, 250)
+
+	// increasing the percentage keeps the identities already included
+	flag.Percentage = 50
+	for identity, on := range enabled {
+		if on {
+			assert.True(t, Evaluate(flag, identity).Enabled, identity)
+		}
+	}
+}
+
+func TestPercentage_Unmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		yaml     string
+		expected Percentage
+		err      bool
+	}{
+		{name: "number", json: `12.5`, yaml: `12.5`, expected: 12.5},
+		{name: "all", json: `"all"`, yaml: `all`, expected: All},
+		{name: "invalid", json: `"half"`, yaml: `half`, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromJSON, fromYAML Percentage
+
+			jsonErr := json.Unmarshal([]byte(tt.json), &fromJSON)
+			yamlErr := yaml.Unmarshal([]byte(tt.yaml), &fromYAML)
+			if tt.err {
+				assert.Error(t, jsonErr)
+				assert.Error(t, yamlErr)
+				return
+			}
+			require.NoError(t, jsonErr)
+			require.NoError(t, yamlErr)
+			assert.Equal(t, tt.expected, fromJSON)
+			assert.Equal(t, tt.expected, fromYAML)
+		})
+	}
+}
+
+func TestEvaluate_Variants(t *testing.T) {
+	flag := Flag{Name: "theme", Enabled: true, Percentage: All, Variants: []Variant{{Value: "light", Weight: 3}, {Value: "dark", Weight: 1}}}
+
+	counts := make(map[string]int)
+	for i := range 10000 {
+		counts[Evaluate(flag, "user-"+strconv.Itoa(i)).Variant]++
+	}
+	assert.Len(t, counts, 2)
+	assert.InDelta(t, 7500, counts["light"], 300)
+	assert.InDelta(t, 2500, counts["dark"], 300)
+}
+
+func TestStaticProvider(t *testing.T) {
+	ctx := context.Background()
+	p := NewStaticProvider(Flag{Name: "a", Enabled: true})
+
+	f, ok, err := p.Flag(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, f.Enabled)
+
+	p.Set(Flag{Name: "a"})
+	f, ok, err = p.Flag(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, f.Enabled)
+
+	p.Delete("a")
+	_, ok, err = p.Flag(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, FromContext(ctx))
+	assert.False(t, Enabled(ctx, "a"))
+	assert.Empty(t, String(ctx, "a"))
+
+	ctx = WithValues(ctx, Values{"a": {Enabled: true, Variant: "v"}})
+	assert.True(t, Enabled(ctx, "a"))
+	assert.Equal(t, "v", String(ctx, "a"))
+	assert.False(t, Enabled(ctx, "b"))
+}
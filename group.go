@@ -29,6 +29,8 @@ import (
 type RouterGroup[T hook.Resolver] struct {
 	excludedMiddlewares map[string]struct{}
 	children            []any // Route or Group
+	stacks              []string
+	maxBodySize         int64
 
 	Prefix      string
 	Middlewares []*hook.Handler[T]
@@ -83,6 +85,22 @@ func (group *RouterGroup[T]) Bind(middlewares ...*hook.Handler[T]) *RouterGroup[
 	return group
 }
 
+// SetMaxRequestBodySize limits the size of the request bodies of the group routes, including
+// the ones of the child groups unless they set their own limit. The body is limited before
+// the route middlewares run, the requests declaring the larger Content-Length are rejected
+// at once, the larger bodies fail to read, with [http.MaxBytesError] which the [ErrorHandler]
+// maps to 413. The negative size removes the inherited limit, the zero one inherits it.
+//
+// The pre middlewares (see [Router.Pre]) run before the routing, so they are limited by
+// the largest limit of the routes, unless some route is unlimited.
+//
+// Set it on the router to limit all routes, it's a defense-in-depth independent of the body
+// limit middleware, which may be unbound from some routes.
+func (group *RouterGroup[T]) SetMaxRequestBodySize(size int64) *RouterGroup[T] {
+	group.maxBodySize = size
+	return group
+}
+
 // Unbind removes one or more middlewares with the specified id(s)
 // from the current group and its children (if any).
 //
@@ -0,0 +1,164 @@
+package wo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/gowool/hook"
+)
+
+// StaticConfig describes a directory served under a group.
+type StaticConfig struct {
+	// Path is the mount path relative to the group prefix, e.g. "/assets".
+	Path string `env:"PATH" json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Dir is the served directory on the local filesystem.
+	Dir string `env:"DIR" json:"dir,omitempty" yaml:"dir,omitempty"`
+
+	// IndexFallback serves the root index.html for missing files (SPA mode).
+	IndexFallback bool `env:"INDEX_FALLBACK" json:"indexFallback,omitempty" yaml:"indexFallback,omitempty"`
+}
+
+func (c StaticConfig) Validate() error {
+	if c.Dir == "" {
+		return errors.New("static: dir is required")
+	}
+	return nil
+}
+
+// GroupConfig declares a route group. Routes are registered from code on the
+// groups returned by [Router.ApplyGroups] and looked up by Name.
+type GroupConfig struct {
+	// Name identifies the group in the result of [Router.ApplyGroups].
+	// Optional, unnamed groups are not returned.
+	Name string `env:"NAME" json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Host restricts the group to the given host. Only top level groups may declare a host.
+	Host string `env:"HOST" json:"host,omitempty" yaml:"host,omitempty"`
+
+	// Prefix is the group path prefix.
+	Prefix string `env:"PREFIX" json:"prefix,omitempty" yaml:"prefix,omitempty"`
+
+	// Middlewares lists IDs of middlewares registered with [Router.RegisterMiddleware]
+	// to bind to the group.
+	Middlewares []string `env:"MIDDLEWARES" json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+
+	// Stacks lists names of middleware stacks registered with [Router.Stack]
+	// to use in the group, see [RouterGroup.UseStack].
+	Stacks []string `env:"STACKS" json:"stacks,omitempty" yaml:"stacks,omitempty"`
+
+	// Exclude lists IDs of middlewares inherited from the parent groups to unbind.
+	Exclude []string `env:"EXCLUDE" json:"exclude,omitempty" yaml:"exclude,omitempty"`
+
+	// MaxRequestBodySize limits the size of the request bodies of the group routes,
+	// see [RouterGroup.SetMaxRequestBodySize].
+	MaxRequestBodySize int64 `env:"MAX_REQUEST_BODY_SIZE" json:"maxRequestBodySize,omitempty" yaml:"maxRequestBodySize,omitempty"`
+
+	// Static lists directories served under the group.
+	Static []StaticConfig `json:"static,omitempty" yaml:"static,omitempty"`
+
+	// Groups lists the child groups.
+	Groups []GroupConfig `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// RegisterMiddleware makes middlewares available to [Router.ApplyGroups] by their ID.
+// A middleware with the same ID replaces the previously registered one.
+func (r *Router[T]) RegisterMiddleware(middlewares ...*hook.Handler[T]) {
+	for _, m := range middlewares {
+		if m.ID == "" {
+			panic("wo: registered middleware must have an ID")
+		}
+		r.middlewares[m.ID] = m
+	}
+}
+
+// Middleware returns the registered middleware with the specified ID.
+func (r *Router[T]) Middleware(id string) (*hook.Handler[T], bool) {
+	m, ok := r.middlewares[id]
+	return m, ok
+}
+
+// ApplyGroups creates the configured groups, binds their middlewares and static mounts.
+// It returns the named groups, so that routes can be registered on them.
+func (r *Router[T]) ApplyGroups(configs ...GroupConfig) (map[string]*RouterGroup[T], error) {
+	groups := make(map[string]*RouterGroup[T])
+
+	for _, cfg := range configs {
+		if err := r.applyGroup(r.RouterGroup, cfg, groups, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return groups, nil
+}
+
+func (r *Router[T]) applyGroup(parent *RouterGroup[T], cfg GroupConfig, groups map[string]*RouterGroup[T], top bool) error {
+	if cfg.Host != "" && !top {
+		return fmt.Errorf("group %q: only top level groups may declare a host", cfg.Name)
+	}
+	if strings.ContainsAny(cfg.Host, "/ ") {
+		return fmt.Errorf("group %q: invalid host %q", cfg.Name, cfg.Host)
+	}
+
+	group := parent.Group(cfg.Host + cfg.Prefix)
+
+	if cfg.Name != "" {
+		if _, ok := groups[cfg.Name]; ok {
+			return fmt.Errorf("group %q: duplicate group name", cfg.Name)
+		}
+		groups[cfg.Name] = group
+	}
+
+	group.Unbind(cfg.Exclude...)
+	group.SetMaxRequestBodySize(cfg.MaxRequestBodySize)
+
+	for _, name := range cfg.Stacks {
+		if _, ok := r.stacks[name]; !ok {
+			return fmt.Errorf("group %q: middleware stack %q is not registered", cfg.Name, name)
+		}
+	}
+	group.UseStack(cfg.Stacks...)
+
+	for _, id := range cfg.Middlewares {
+		m, ok := r.middlewares[id]
+		if !ok {
+			return fmt.Errorf("group %q: middleware %q is not registered", cfg.Name, id)
+		}
+		group.Bind(m)
+	}
+
+	for _, static := range cfg.Static {
+		if err := static.Validate(); err != nil {
+			return fmt.Errorf("group %q: %w", cfg.Name, err)
+		}
+
+		action, err := staticAction[T](os.DirFS(static.Dir), static.IndexFallback)
+		if err != nil {
+			return fmt.Errorf("group %q: %w", cfg.Name, err)
+		}
+
+		group.GET(strings.TrimSuffix(static.Path, "/")+"/{"+StaticWildcardParam+"...}", action)
+	}
+
+	for _, child := range cfg.Groups {
+		if err := r.applyGroup(group, child, groups, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func staticAction[T Resolver](fsys fs.FS, indexFallback bool) (func(T) error, error) {
+	var zero T
+	if _, ok := any(zero).(interface{ StaticFS(fs.FS, bool) error }); !ok {
+		return nil, fmt.Errorf("static: event type %T does not implement StaticFS", zero)
+	}
+
+	return func(e T) error {
+		return any(e).(interface{ StaticFS(fs.FS, bool) error }).StaticFS(fsys, indexFallback)
+	}, nil
+}
@@ -0,0 +1,136 @@
+package wo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterRegisterMiddleware(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+
+	assert.Panics(t, func() {
+		router.RegisterMiddleware(&hook.Handler[*Event]{Func: func(e *Event) error { return e.Next() }})
+	})
+
+	m := &hook.Handler[*Event]{ID: "auth", Func: func(e *Event) error { return e.Next() }}
+	router.RegisterMiddleware(m)
+
+	actual, ok := router.Middleware("auth")
+	assert.True(t, ok)
+	assert.Same(t, m, actual)
+
+	_, ok = router.Middleware("missing")
+	assert.False(t, ok)
+}
+
+func TestRouterApplyGroups(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0o600))
+
+	var raw = `[
+		{
+			"name": "api",
+			"prefix": "/api",
+			"middlewares": ["tag"],
+			"groups": [
+				{"name": "public", "prefix": "/public", "exclude": ["tag"]}
+			]
+		},
+		{
+			"prefix": "/web",
+			"static": [{"path": "/assets", "dir": "` + filepath.ToSlash(dir) + `"}]
+		}
+	]`
+
+	var configs []GroupConfig
+	require.NoError(t, json.Unmarshal([]byte(raw), &configs))
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.RegisterMiddleware(&hook.Handler[*Event]{ID: "tag", Func: func(e *Event) error {
+		e.Response().Header().Set("X-Tag", "1")
+		return e.Next()
+	}})
+
+	groups, err := router.ApplyGroups(configs...)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	groups["api"].GET("/users", func(e *Event) error { return e.NoContent(http.StatusNoContent) })
+	groups["public"].GET("/ping", func(e *Event) error { return e.NoContent(http.StatusNoContent) })
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-Tag"))
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/public/ping", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("X-Tag"))
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/web/assets/app.js", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "console.log(1)", rec.Body.String())
+}
+
+func TestRouterApplyGroups_Host(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+
+	groups, err := router.ApplyGroups(GroupConfig{Name: "admin", Host: "admin.example.com", Prefix: "/"})
+	require.NoError(t, err)
+
+	groups["admin"].GET("{$}", func(e *Event) error { return e.String(http.StatusOK, "admin") })
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://admin.example.com/", nil))
+	assert.Equal(t, "admin", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRouterApplyGroups_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config GroupConfig
+	}{
+		{"unknown middleware", GroupConfig{Name: "a", Middlewares: []string{"missing"}}},
+		{"unknown stack", GroupConfig{Name: "a", Stacks: []string{"missing"}}},
+		{"nested host", GroupConfig{Name: "a", Groups: []GroupConfig{{Host: "example.com"}}}},
+		{"invalid host", GroupConfig{Name: "a", Host: "example.com/x"}},
+		{"duplicate name", GroupConfig{Name: "a", Groups: []GroupConfig{{Name: "a"}}}},
+		{"static without dir", GroupConfig{Name: "a", Static: []StaticConfig{{Path: "/assets"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New[*Event](eventFactory, errorHandler)
+
+			_, err := router.ApplyGroups(tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRouterApplyGroups_StaticRequiresStaticFS(t *testing.T) {
+	router := New[*ErrorHandlerTestEvent](nil, nil)
+
+	_, err := router.ApplyGroups(GroupConfig{Static: []StaticConfig{{Path: "/assets", Dir: "."}}})
+	assert.ErrorContains(t, err, "does not implement StaticFS")
+}
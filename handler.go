@@ -3,16 +3,24 @@ package wo
 import (
 	"io/fs"
 	"net/http"
+
+	"github.com/gowool/hook"
 )
 
-func WrapMiddleware[T Resolver](m func(http.Handler) http.Handler) func(T) error {
+// Middleware is a middleware handler function of the router chains.
+type Middleware[T Resolver] = func(T) error
+
+// WrapMiddleware adapts the standard library compatible middleware to a [Middleware], so it can
+// be used within the router groups. The request and the response writer passed down by the
+// middleware replace the event ones; a response writer which doesn't wrap (see [RWUnwrapper])
+// the [Response] is wrapped into a new one.
+func WrapMiddleware[T Resolver](m func(http.Handler) http.Handler) Middleware[T] {
 	return func(e T) (err error) {
 		m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			e.SetRequest(r)
-			switch resp := w.(type) {
-			case *Response:
-				e.SetResponse(resp)
-			default:
+			if _, err := UnwrapResponse(w); err == nil {
+				e.SetResponse(w)
+			} else {
 				e.SetResponse(NewResponse(w))
 			}
 			err = e.Next()
@@ -21,6 +29,54 @@ func WrapMiddleware[T Resolver](m func(http.Handler) http.Handler) func(T) error
 	}
 }
 
+// UnwrapMiddleware adapts the middlewares to a standard library compatible middleware, so they
+// can be reused in plain net/http servers. The event of each request is created by the event factory,
+// the errors returned by the middlewares are handled by the error handler and the next handler
+// is served with the event request and response.
+//
+//	mw := wo.UnwrapMiddleware(eventFactory, errorHandler, middleware.Security[*wo.Event](cfg))
+//	http.ListenAndServe(":8080", mw(mux))
+func UnwrapMiddleware[T Resolver](eventFactory EventFactoryFunc[T], errorHandler HTTPErrorHandler[T], middlewares ...Middleware[T]) func(http.Handler) http.Handler {
+	if eventFactory == nil {
+		panic("UnwrapMiddleware: the provided event factory is nil")
+	}
+	if errorHandler == nil {
+		panic("UnwrapMiddleware: the provided error handler is nil")
+	}
+
+	h := new(hook.Hook[T])
+	for _, m := range middlewares {
+		if m == nil {
+			panic("UnwrapMiddleware: the provided middleware is nil")
+		}
+		h.BindFunc(m)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resp := NewResponse(w)
+
+			event, cleanupFunc := eventFactory(resp, r)
+			if cleanupFunc != nil {
+				defer cleanupFunc()
+			}
+
+			if err := h.Trigger(event, func(e T) error {
+				next.ServeHTTP(e.Response(), e.Request())
+				return nil
+			}); err != nil {
+				errorHandler(event, err)
+			}
+
+			// the event factory may wrap resp into its own Response
+			if res, err := UnwrapResponse(event.Response()); err == nil && res != resp {
+				res.Complete()
+			}
+			resp.Complete()
+		})
+	}
+}
+
 func WrapHandler[T Resolver](h http.Handler) func(T) error {
 	return func(e T) error {
 		h.ServeHTTP(e.Response(), e.Request())
@@ -181,6 +181,119 @@ func TestWrapMiddlewareWithNonResponseWriter(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.Code)
 }
 
+// TestWrapMiddlewareWithWrappedResponse tests WrapMiddleware keeps the writer which wraps the Response
+func TestWrapMiddlewareWithWrappedResponse(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	resp := httptest.NewRecorder()
+	event := newTestEvent(req, NewResponse(resp))
+
+	var wrapped http.ResponseWriter
+	middleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wrapped = &testWrappedWriter{ResponseWriter: w}
+			next.ServeHTTP(wrapped, r)
+		})
+	}
+
+	err := WrapMiddleware[*Event](middleware)(event)
+
+	assert.NoError(t, err)
+	assert.Same(t, wrapped, event.Response())
+}
+
+type testWrappedWriter struct {
+	http.ResponseWriter
+}
+
+func (w *testWrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// TestUnwrapMiddleware tests the UnwrapMiddleware function
+func TestUnwrapMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(*Response)
+		assert.True(t, ok, "Expected Response type in the next handler")
+		w.Header().Set("X-Value", r.Header.Get("X-Value"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("next"))
+	})
+
+	tests := []struct {
+		name           string
+		middlewares    []Middleware[*Event]
+		expectedStatus int
+		expectedBody   string
+		expectedHeader string
+	}{
+		{
+			name:           "no middlewares",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "next",
+		},
+		{
+			name: "middlewares run in order",
+			middlewares: []Middleware[*Event]{
+				func(e *Event) error {
+					e.Request().Header.Set("X-Value", "first")
+					return e.Next()
+				},
+				func(e *Event) error {
+					e.Request().Header.Set("X-Value", e.Request().Header.Get("X-Value")+",second")
+					return e.Next()
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "next",
+			expectedHeader: "first,second",
+		},
+		{
+			name: "middleware short-circuits",
+			middlewares: []Middleware[*Event]{
+				func(e *Event) error {
+					return e.String(http.StatusAccepted, "stop")
+				},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   "stop",
+		},
+		{
+			name: "middleware error is handled",
+			middlewares: []Middleware[*Event]{
+				func(e *Event) error {
+					return ErrForbidden
+				},
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "handled",
+		},
+	}
+
+	handleError := func(e *Event, err error) {
+		_ = e.String(AsHTTPError(err).Status, "handled")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := UnwrapMiddleware(eventFactory, handleError, tt.middlewares...)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+			assert.Equal(t, tt.expectedHeader, rec.Header().Get("X-Value"))
+		})
+	}
+}
+
+func TestUnwrapMiddlewarePanics(t *testing.T) {
+	assert.Panics(t, func() { UnwrapMiddleware[*Event](nil, errorHandler) })
+	assert.Panics(t, func() { UnwrapMiddleware[*Event](eventFactory, nil) })
+	assert.Panics(t, func() { UnwrapMiddleware[*Event](eventFactory, errorHandler, nil) })
+}
+
 // TestWrapHandler tests the WrapHandler function
 func TestWrapHandler(t *testing.T) {
 	tests := []struct {
@@ -1,77 +1,221 @@
 package wo
 
 import (
+	"cmp"
+	"fmt"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// SetContentDisposition sets the Content-Disposition header of the disposition type, ex.
+// "attachment", and the file name. The non-ASCII name is also sent as the RFC 5987 encoded
+// filename* parameter, the plain filename keeps its ASCII fallback.
+func SetContentDisposition(res http.ResponseWriter, dispositionType, name string) {
+	value := fmt.Sprintf(`%s; filename="%s"`, dispositionType, quoteEscaper.Replace(asciiFallback(name)))
+	if !isASCII(name) {
+		value += "; filename*=UTF-8''" + extValueEscape(name)
+	}
+	res.Header().Set(HeaderContentDisposition, value)
+}
+
+func asciiFallback(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= utf8.RuneSelf || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// extValueEscape percent-encodes the value except the RFC 5987 attr-chars.
+func extValueEscape(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := range len(s) {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+	return b.String()
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf || s[i] < 0x20 {
+			return false
+		}
+	}
+	return true
+}
+
 func SetHeaderIfMissing(res http.ResponseWriter, key string, value string) {
 	if res.Header().Get(key) == "" {
 		res.Header().Set(key, value)
 	}
 }
 
+// SetContentLanguage sets the Content-Language header and adds Accept-Language to Vary,
+// since the representation depends on the language negotiated from the request.
+func SetContentLanguage(res http.ResponseWriter, tag string) {
+	res.Header().Set(HeaderContentLanguage, tag)
+	AddVary(res.Header(), HeaderAcceptLanguage)
+}
+
+// AddVary adds the request header names to the Vary header unless they are already listed
+// (case-insensitive, in any of the Vary values), so the middlewares which depend on the same
+// request header don't produce duplicates or clobber each other. The wildcard "*" replaces
+// all names, and nothing is added once Vary is "*".
+func AddVary(h http.Header, names ...string) {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || HasVary(h, name) {
+			continue
+		}
+		if name == "*" {
+			h.Set(HeaderVary, "*")
+			return
+		}
+		h.Add(HeaderVary, name)
+	}
+}
+
+// HasVary reports whether the Vary header lists the request header name or is "*".
+func HasVary(h http.Header, name string) bool {
+	for _, v := range h.Values(HeaderVary) {
+		for item := range strings.SplitSeq(v, ",") {
+			item = strings.TrimSpace(item)
+			if item == "*" || strings.EqualFold(item, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseAcceptLanguageHeader returns the language ranges of the Accept-Language header ordered
+// by preference (q-value). The ranges with equal preference keep the header order and the
+// ranges with q=0 (not acceptable) are omitted.
 func ParseAcceptLanguageHeader(languageHeader string) []string {
 	if languageHeader == "" {
 		return make([]string, 0)
 	}
 
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
 	options := strings.Split(languageHeader, ",")
-	l := len(options)
-	languages := make([]string, l)
+	ranges := make([]weighted, 0, len(options))
 
-	for i := 0; i < l; i++ {
-		locale := strings.SplitN(options[i], ";", 2)
-		languages[i] = strings.Trim(locale[0], " ")
+	for _, option := range options {
+		tag, params, _ := strings.Cut(option, ";")
+		if tag = strings.TrimSpace(tag); tag == "" {
+			continue
+		}
+		if q := parseQuality(params); q > 0 {
+			ranges = append(ranges, weighted{tag: tag, q: q})
+		}
 	}
 
+	slices.SortStableFunc(ranges, func(a, b weighted) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	languages := make([]string, len(ranges))
+	for i, r := range ranges {
+		languages[i] = r.tag
+	}
 	return languages
 }
 
-func ParseAcceptHeader(acceptHeader string) []string {
-	if acceptHeader == "" {
-		return make([]string, 0)
+// AcceptLanguageBest returns the offered language which matches best the accepted
+// language ranges (see [ParseAcceptLanguageHeader]) or an empty string. A range matches
+// an offer exactly (case-insensitive) or by the primary language subtag, ex. "en-US"
+// matches "en" and "en" matches "en-GB". The wildcard "*" matches the first offer.
+func AcceptLanguageBest(accepted []string, offered ...string) string {
+	if len(offered) == 0 {
+		return ""
 	}
 
-	parts := strings.Split(acceptHeader, ",")
-	out := make([]string, 0, len(parts))
-	for _, part := range parts {
-		if i := strings.IndexByte(part, ';'); i > 0 {
-			part = part[:i]
+	for _, a := range accepted {
+		if a == "*" {
+			return offered[0]
 		}
-		if part = strings.TrimSpace(part); part != "" {
-			out = append(out, part)
+		for _, offer := range offered {
+			if strings.EqualFold(a, offer) {
+				return offer
+			}
+		}
+		primary := primaryLanguage(a)
+		for _, offer := range offered {
+			if strings.EqualFold(primary, primaryLanguage(offer)) {
+				return offer
+			}
 		}
 	}
-	return out
+	return ""
 }
 
-func NegotiateFormat(accepted []string, offered ...string) string {
-	if len(offered) == 0 {
-		panic("negotiateFormat: you must provide at least one offer")
+func primaryLanguage(tag string) string {
+	if i := strings.IndexAny(tag, "-_"); i > 0 {
+		return tag[:i]
 	}
+	return tag
+}
+
+// parseQuality returns the q-value of the header element parameters, which is 1 if absent or malformed.
+func parseQuality(params string) float64 {
+	for param := range strings.SplitSeq(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || q > 1 || math.IsNaN(q) {
+			return 1
+		}
+		return max(q, 0)
+	}
+	return 1
+}
 
-	if len(accepted) == 0 {
-		return offered[0]
+// ParseAcceptHeader returns the acceptable media ranges of the Accept header (without
+// parameters) ordered by preference, see [ParseAccept].
+func ParseAcceptHeader(acceptHeader string) []string {
+	ranges := ParseAccept(acceptHeader)
+	out := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Q > 0 {
+			out = append(out, r.MediaType())
+		}
 	}
+	return out
+}
 
+// NegotiateFormat returns the offered media type which is the most acceptable according to
+// the accepted media ranges ordered by preference, see [NegotiateMediaType].
+func NegotiateFormat(accepted []string, offered ...string) string {
+	ranges := make([]MediaRange, 0, len(accepted))
 	for _, a := range accepted {
-		for _, offer := range offered {
-			// According to RFC 2616 and RFC 2396, non-ASCII characters are not allowed in headers,
-			// therefore we can just iterate over the string without casting it into []rune
-			i := 0
-			for ; i < len(a) && i < len(offer); i++ {
-				if a[i] == '*' || offer[i] == '*' {
-					return offer
-				}
-				if a[i] != offer[i] {
-					break
-				}
-			}
-			if i == len(a) {
-				return offer
-			}
+		if r, ok := parseMediaRange(a); ok {
+			ranges = append(ranges, r)
 		}
 	}
-	return ""
+	return NegotiateMediaType(ranges, offered...)
 }
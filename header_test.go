@@ -1,6 +1,8 @@
 package wo
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -34,3 +36,89 @@ func TestNegotiationFormatWithWildcardAccept(t *testing.T) {
 	assert.Empty(t, NegotiateFormat(accepted, MIMEApplicationXML))
 	assert.Equal(t, MIMETextHTML, NegotiateFormat(accepted, MIMETextHTML))
 }
+
+func TestSetContentLanguage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Add(HeaderVary, HeaderAcceptEncoding)
+
+	SetContentLanguage(rec, "de-DE")
+	SetContentLanguage(rec, "en")
+
+	assert.Equal(t, "en", rec.Header().Get(HeaderContentLanguage))
+	assert.Equal(t, []string{HeaderAcceptEncoding, HeaderAcceptLanguage}, rec.Header().Values(HeaderVary))
+}
+
+func TestAddVary(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		add      []string
+		expected []string
+	}{
+		{name: "empty", add: []string{HeaderOrigin}, expected: []string{HeaderOrigin}},
+		{name: "duplicate", existing: []string{HeaderOrigin}, add: []string{HeaderOrigin, HeaderCookie}, expected: []string{HeaderOrigin, HeaderCookie}},
+		{name: "case insensitive", existing: []string{"accept-encoding"}, add: []string{HeaderAcceptEncoding}, expected: []string{"accept-encoding"}},
+		{name: "comma separated", existing: []string{"Origin, Cookie"}, add: []string{HeaderCookie, HeaderAcceptLanguage}, expected: []string{"Origin, Cookie", HeaderAcceptLanguage}},
+		{name: "blank", add: []string{" ", ""}, expected: nil},
+		{name: "wildcard replaces", existing: []string{HeaderOrigin}, add: []string{"*", HeaderCookie}, expected: []string{"*"}},
+		{name: "wildcard absorbs", existing: []string{"*"}, add: []string{HeaderOrigin}, expected: []string{"*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := make(http.Header)
+			for _, v := range tt.existing {
+				h.Add(HeaderVary, v)
+			}
+
+			AddVary(h, tt.add...)
+
+			assert.Equal(t, tt.expected, h.Values(HeaderVary))
+		})
+	}
+}
+
+func TestAcceptLanguageBest(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		offered  []string
+		expected string
+	}{
+		{"exact", "fr-FR, en;q=0.8", []string{"en", "fr-FR"}, "fr-FR"},
+		{"case insensitive", "EN-us", []string{"de", "en-US"}, "en-US"},
+		{"quality order", "en;q=0.5, de;q=0.9", []string{"en", "de"}, "de"},
+		{"primary subtag of range", "en-US", []string{"de", "en"}, "en"},
+		{"primary subtag of offer", "en", []string{"de", "en-GB"}, "en-GB"},
+		{"wildcard", "es, *;q=0.1", []string{"de", "en"}, "de"},
+		{"not acceptable", "en;q=0", []string{"en"}, ""},
+		{"no match", "es", []string{"de", "en"}, ""},
+		{"no offers", "en", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AcceptLanguageBest(ParseAcceptLanguageHeader(tt.header), tt.offered...))
+		})
+	}
+}
+
+func TestSetContentDisposition(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "report.csv", expected: `attachment; filename="report.csv"`},
+		{name: `say "hi".txt`, expected: `attachment; filename="say \"hi\".txt"`},
+		{name: "отчёт.csv", expected: `attachment; filename="_____.csv"; filename*=UTF-8''%D0%BE%D1%82%D1%87%D1%91%D1%82.csv`},
+		{name: "l'été (1).csv", expected: `attachment; filename="l'_t_ (1).csv"; filename*=UTF-8''l%27%C3%A9t%C3%A9%20%281%29.csv`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			SetContentDisposition(rec, "attachment", tt.name)
+			assert.Equal(t, tt.expected, rec.Header().Get(HeaderContentDisposition))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package wo
+
+import (
+	"context"
+	"fmt"
+)
+
+type ctxTranslateKey struct{}
+
+// Translator translates the message keys, ex. backed by a message catalog.
+type Translator interface {
+	Translate(locale, key string, args ...any) string
+}
+
+// TranslatorFunc is an adapter to allow the use of ordinary functions as [Translator].
+type TranslatorFunc func(locale, key string, args ...any) string
+
+func (f TranslatorFunc) Translate(locale, key string, args ...any) string {
+	return f(locale, key, args...)
+}
+
+// TranslateFunc translates the message key for the locale it is bound to.
+type TranslateFunc func(key string, args ...any) string
+
+// WithTranslateFunc returns a copy of ctx which carries the translate func of the request locale.
+func WithTranslateFunc(ctx context.Context, fn TranslateFunc) context.Context {
+	return context.WithValue(ctx, ctxTranslateKey{}, fn)
+}
+
+// TranslateFuncFromContext returns the translate func stored in ctx, so renderers (ex. template funcs)
+// can translate for the request locale. Without a translator the key is formatted with the args.
+func TranslateFuncFromContext(ctx context.Context) TranslateFunc {
+	if fn, ok := ctx.Value(ctxTranslateKey{}).(TranslateFunc); ok && fn != nil {
+		return fn
+	}
+	return defaultTranslate
+}
+
+func defaultTranslate(key string, args ...any) string {
+	if len(args) == 0 {
+		return key
+	}
+	return fmt.Sprintf(key, args...)
+}
@@ -0,0 +1,22 @@
+package wo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateFuncFromContext(t *testing.T) {
+	fn := TranslateFuncFromContext(context.Background())
+	assert.Equal(t, "hello", fn("hello"))
+	assert.Equal(t, "hello world", fn("hello %s", "world"))
+
+	translator := TranslatorFunc(func(locale, key string, _ ...any) string {
+		return locale + ":" + key
+	})
+	ctx := WithTranslateFunc(context.Background(), func(key string, args ...any) string {
+		return translator.Translate("fr", key, args...)
+	})
+	assert.Equal(t, "fr:hello", TranslateFuncFromContext(ctx)("hello"))
+}
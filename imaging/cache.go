@@ -0,0 +1,156 @@
+package imaging
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskCache keeps the rendered variants in the directory, the least recently used ones are
+// removed once the total size exceeds the maximum.
+type diskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	lru   *list.List // of *cacheEntry, the most recently used in front
+	items map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key  string
+	size int64
+}
+
+// newDiskCache returns the cache of the directory, the files left by the previous runs are
+// reused in the order of their modification time.
+func newDiskCache(dir string, maxBytes int64) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	c := &diskCache{dir: dir, maxBytes: maxBytes, lru: list.New(), items: make(map[string]*list.Element)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type file struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+	files := make([]file, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			// the leftover of an interrupted write
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{name: entry.Name(), size: fi.Size(), modTime: fi.ModTime()})
+	}
+	slices.SortFunc(files, func(a, b file) int { return a.modTime.Compare(b.modTime) })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, f := range files {
+		c.items[f.name] = c.lru.PushFront(&cacheEntry{key: f.name, size: f.size})
+		c.size += f.size
+	}
+	c.evict()
+
+	return c, nil
+}
+
+// open returns the cached variant, it reports false when it's missing.
+func (c *diskCache) open(key string) (*os.File, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	f, err := os.Open(filepath.Join(c.dir, key))
+	if err != nil {
+		// removed behind our back
+		c.remove(el)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	// the modification time keeps the order over the restarts
+	now := time.Now()
+	_ = os.Chtimes(f.Name(), now, now)
+	return f, true
+}
+
+// put caches the variant, the file is written aside and renamed, so the readers never see
+// it partially written.
+func (c *diskCache) put(key string, data []byte) error {
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err = os.Rename(tmp.Name(), filepath.Join(c.dir, key)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		c.size += int64(len(data)) - entry.size
+		entry.size = int64(len(data))
+		c.lru.MoveToFront(el)
+	} else {
+		c.items[key] = c.lru.PushFront(&cacheEntry{key: key, size: int64(len(data))})
+		c.size += int64(len(data))
+	}
+	c.evict()
+
+	return nil
+}
+
+// evict removes the least recently used variants beyond the maximum size, except the most
+// recent one.
+func (c *diskCache) evict() {
+	for c.size > c.maxBytes && c.lru.Len() > 1 {
+		el := c.lru.Back()
+		_ = os.Remove(filepath.Join(c.dir, el.Value.(*cacheEntry).key))
+		c.remove(el)
+	}
+}
+
+func (c *diskCache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*cacheEntry)
+	delete(c.items, entry.key)
+	c.size -= entry.size
+}
@@ -0,0 +1,408 @@
+// Package imaging serves the resized and cropped variants of the stored images. The variant
+// is requested with the query parameters of the signed URL, so the clients can't make the
+// server render arbitrary sizes, and the rendered variants are kept in the LRU disk cache:
+//
+//	signer := signedurl.New(signedurl.Config{}, signedurl.Key{ID: "2024", Secret: secret})
+//	images, err := imaging.New(os.DirFS("./uploads"), signer, imaging.Config{})
+//
+//	router.GET("/images/{path...}", images.Serve)
+//
+//	link, _ := images.URL("/images/avatars/42.png", imaging.Options{Width: 64, Height: 64, Fit: imaging.FitCover}, time.Hour)
+//
+// The requests without the variant parameters are served as is, like [wo.Event.StaticFS] does.
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // the gif decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/signedurl"
+)
+
+// The query parameters of the variant.
+const (
+	ParamWidth  = "w"
+	ParamHeight = "h"
+	ParamFit    = "fit"
+	ParamFormat = "fmt"
+)
+
+// Fit is how the image is fitted to the requested size.
+type Fit string
+
+const (
+	// FitContain scales the image to fit within the size, keeping the aspect ratio.
+	FitContain Fit = "contain"
+
+	// FitCover scales the image to cover the size, keeping the aspect ratio, and crops
+	// the overflow around the center.
+	FitCover Fit = "cover"
+
+	// FitFill stretches the image to the size.
+	FitFill Fit = "fill"
+)
+
+// Format is the encoding of the variant.
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+)
+
+// Options are the parameters of the variant, at least one of the sizes is required, the
+// missing one follows the aspect ratio. The format defaults to JPEG for the JPEG images
+// and to PNG for the others.
+type Options struct {
+	Width  int
+	Height int
+	Fit    Fit
+	Format Format
+}
+
+// Query returns the query parameters of the options.
+func (o Options) Query() url.Values {
+	query := make(url.Values)
+	if o.Width > 0 {
+		query.Set(ParamWidth, strconv.Itoa(o.Width))
+	}
+	if o.Height > 0 {
+		query.Set(ParamHeight, strconv.Itoa(o.Height))
+	}
+	if o.Fit != "" {
+		query.Set(ParamFit, string(o.Fit))
+	}
+	if o.Format != "" {
+		query.Set(ParamFormat, string(o.Format))
+	}
+	return query
+}
+
+type Config struct {
+	// MaxWidth is the maximum width of the variant.
+	//
+	// Default: 2048
+	MaxWidth int `env:"MAX_WIDTH" json:"maxWidth,omitempty" yaml:"maxWidth,omitempty"`
+
+	// MaxHeight is the maximum height of the variant.
+	//
+	// Default: 2048
+	MaxHeight int `env:"MAX_HEIGHT" json:"maxHeight,omitempty" yaml:"maxHeight,omitempty"`
+
+	// MaxSourcePixels is the maximum number of the pixels of the source image, the larger
+	// ones are rejected before decoding, so the decompression bombs don't exhaust the memory.
+	//
+	// Default: 25000000
+	MaxSourcePixels int `env:"MAX_SOURCE_PIXELS" json:"maxSourcePixels,omitempty" yaml:"maxSourcePixels,omitempty"`
+
+	// Quality is the JPEG quality of the variants, 1 to 100.
+	//
+	// Default: 85
+	Quality int `env:"QUALITY" json:"quality,omitempty" yaml:"quality,omitempty"`
+
+	// CacheDir is the directory of the rendered variants.
+	//
+	// Default: os.TempDir()/wo-imaging
+	CacheDir string `env:"CACHE_DIR" json:"cacheDir,omitempty" yaml:"cacheDir,omitempty"`
+
+	// CacheMaxBytes is the maximum total size of the cached variants, the least recently
+	// used ones are removed beyond it.
+	//
+	// Default: 256mb
+	CacheMaxBytes int64 `env:"CACHE_MAX_BYTES" json:"cacheMaxBytes,omitempty" yaml:"cacheMaxBytes,omitempty"`
+
+	// CacheControl is the Cache-Control header of the variants.
+	//
+	// Default: "public, max-age=86400"
+	CacheControl string `env:"CACHE_CONTROL" json:"cacheControl,omitempty" yaml:"cacheControl,omitempty"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.MaxWidth == 0 {
+		c.MaxWidth = 2048
+	}
+	if c.MaxHeight == 0 {
+		c.MaxHeight = 2048
+	}
+	if c.MaxSourcePixels == 0 {
+		c.MaxSourcePixels = 25_000_000
+	}
+	if c.Quality == 0 {
+		c.Quality = 85
+	}
+	if c.CacheDir == "" {
+		c.CacheDir = filepath.Join(os.TempDir(), "wo-imaging")
+	}
+	if c.CacheMaxBytes == 0 {
+		c.CacheMaxBytes = 256 << 20
+	}
+	if c.CacheControl == "" {
+		c.CacheControl = "public, max-age=86400"
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.MaxWidth < 1 || c.MaxHeight < 1 {
+		return errors.New("imaging: max width and height must be positive")
+	}
+	if c.MaxSourcePixels < 1 {
+		return errors.New("imaging: max source pixels must be positive")
+	}
+	if c.Quality < 1 || c.Quality > 100 {
+		return errors.New("imaging: quality must be between 1 and 100")
+	}
+	if c.CacheMaxBytes < 1 {
+		return errors.New("imaging: cache max bytes must be positive")
+	}
+	return nil
+}
+
+// Imager serves the images of the file system and their variants.
+type Imager struct {
+	config Config
+	fsys   fs.FS
+	signer *signedurl.Signer
+	cache  *diskCache
+	group  singleflight.Group
+}
+
+// New returns the imager of the images of fsys, the variant URLs are signed with the signer.
+// The cache directory is created unless it exists, the variants cached by the previous runs
+// are reused.
+func New(fsys fs.FS, signer *signedurl.Signer, cfg Config) (*Imager, error) {
+	if fsys == nil {
+		panic("imaging: fs is nil")
+	}
+	if signer == nil {
+		panic("imaging: signer is nil")
+	}
+
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	cache, err := newDiskCache(cfg.CacheDir, cfg.CacheMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Imager{config: cfg, fsys: fsys, signer: signer, cache: cache}, nil
+}
+
+// URL returns the signed URL of the variant of the image served at urlPath, valid for the ttl.
+func (im *Imager) URL(urlPath string, o Options, ttl time.Duration) (string, error) {
+	u, err := url.Parse(urlPath)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for key, values := range o.Query() {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+
+	return im.signer.Sign(http.MethodGet, u.String(), ttl)
+}
+
+// Serve serves the image of the {path...} route parameter, or its variant when the request
+// has the variant parameters. The variant URL must be signed, see [Imager.URL].
+func (im *Imager) Serve(e *wo.Event) error {
+	r := e.Request()
+	query := r.URL.Query()
+	if !hasOptions(query) {
+		return e.StaticFS(im.fsys, false)
+	}
+
+	if err := im.signer.VerifyRequest(r); err != nil {
+		return wo.ErrForbidden.WithInternal(err)
+	}
+
+	name, ok := cleanPath(e.Param(wo.StaticWildcardParam))
+	if !ok {
+		return wo.ErrNotFound.WithMessage("file not found")
+	}
+
+	o, err := im.parseOptions(query, name)
+	if err != nil {
+		return err
+	}
+
+	fi, err := fs.Stat(im.fsys, name)
+	if err != nil {
+		return wo.ErrNotFound.WithInternal(err)
+	}
+	if fi.IsDir() {
+		return wo.ErrNotFound.WithMessage("file not found")
+	}
+
+	key := im.key(name, fi, o)
+
+	var content io.ReadSeeker
+	if f, ok := im.cache.open(key); ok {
+		defer func() {
+			_ = f.Close()
+		}()
+		content = f
+	} else {
+		v, err, _ := im.group.Do(key, func() (any, error) {
+			data, err := im.render(name, o)
+			if err != nil {
+				return nil, err
+			}
+			// the variant is served anyway, it's rendered again on the next request
+			_ = im.cache.put(key, data)
+			return data, nil
+		})
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(v.([]byte))
+	}
+
+	h := e.Response().Header()
+	h.Set(wo.HeaderContentType, "image/"+string(o.Format))
+	h.Set(wo.HeaderCacheControl, im.config.CacheControl)
+	h.Set(wo.HeaderETag, `"`+key+`"`)
+
+	http.ServeContent(e.Response(), r, "", fi.ModTime(), content)
+	return nil
+}
+
+func (im *Imager) parseOptions(query url.Values, name string) (Options, error) {
+	var o Options
+
+	for _, p := range []struct {
+		param string
+		max   int
+		dst   *int
+	}{
+		{ParamWidth, im.config.MaxWidth, &o.Width},
+		{ParamHeight, im.config.MaxHeight, &o.Height},
+	} {
+		value := query.Get(p.param)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > p.max {
+			return o, wo.ErrBadRequest.WithMessage(fmt.Sprintf("%s must be between 1 and %d", p.param, p.max))
+		}
+		*p.dst = n
+	}
+	if o.Width == 0 && o.Height == 0 {
+		return o, wo.ErrBadRequest.WithMessage("width or height is required")
+	}
+
+	switch o.Fit = Fit(query.Get(ParamFit)); o.Fit {
+	case "":
+		o.Fit = FitContain
+	case FitContain, FitCover, FitFill:
+	default:
+		return o, wo.ErrBadRequest.WithMessage("unknown fit " + strconv.Quote(string(o.Fit)))
+	}
+
+	switch o.Format = Format(query.Get(ParamFormat)); o.Format {
+	case "":
+		o.Format = FormatPNG
+		if ext := strings.ToLower(path.Ext(name)); ext == ".jpg" || ext == ".jpeg" {
+			o.Format = FormatJPEG
+		}
+	case FormatJPEG, FormatPNG:
+	default:
+		return o, wo.ErrBadRequest.WithMessage("unknown format " + strconv.Quote(string(o.Format)))
+	}
+
+	return o, nil
+}
+
+// key returns the cache key of the variant, the source file changes invalidate it.
+func (im *Imager) key(name string, fi fs.FileInfo, o Options) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\n%d\n%d\n%d\n%d\n%s\n%s\n%d",
+		name, fi.Size(), fi.ModTime().UnixNano(), o.Width, o.Height, o.Fit, o.Format, im.config.Quality)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// render decodes the image, transforms and encodes it per the options.
+func (im *Imager) render(name string, o Options) ([]byte, error) {
+	cfg, err := decodeConfig(im.fsys, name)
+	if err != nil {
+		return nil, wo.ErrUnprocessableEntity.WithInternal(err)
+	}
+	if cfg.Width*cfg.Height > im.config.MaxSourcePixels {
+		return nil, wo.ErrUnprocessableEntity.WithMessage("image is too large")
+	}
+
+	f, err := im.fsys.Open(name)
+	if err != nil {
+		return nil, wo.ErrNotFound.WithInternal(err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return nil, wo.ErrUnprocessableEntity.WithInternal(err)
+	}
+
+	dst := Transform(src, o)
+
+	var buf bytes.Buffer
+	switch o.Format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: im.config.Quality})
+	default:
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeConfig(fsys fs.FS, name string) (image.Config, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	cfg, _, err := image.DecodeConfig(f)
+	return cfg, err
+}
+
+func hasOptions(query url.Values) bool {
+	return query.Has(ParamWidth) || query.Has(ParamHeight) || query.Has(ParamFit) || query.Has(ParamFormat)
+}
+
+// cleanPath returns the slash separated relative path of the route parameter, it reports
+// false for the paths escaping the root.
+func cleanPath(p string) (string, bool) {
+	p = path.Clean("/" + strings.ReplaceAll(p, `\`, "/"))[1:]
+	return p, p != "" && fs.ValidPath(p)
+}
@@ -0,0 +1,263 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/signedurl"
+	"github.com/gowool/wo/wotest"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func newTestImager(t *testing.T, cfg Config) *Imager {
+	t.Helper()
+
+	fsys := fstest.MapFS{
+		"photos/wide.png": {Data: testPNG(t, 200, 100), ModTime: time.Unix(1700000000, 0)},
+		"notes.txt":       {Data: []byte("not an image")},
+	}
+	signer := signedurl.New(signedurl.Config{}, signedurl.Key{ID: "1", Secret: []byte("secret")})
+
+	if cfg.CacheDir == "" {
+		cfg.CacheDir = t.TempDir()
+	}
+	im, err := New(fsys, signer, cfg)
+	require.NoError(t, err)
+	return im
+}
+
+func serve(t *testing.T, im *Imager, target string) (*http.Response, error) {
+	t.Helper()
+
+	u, err := http.NewRequest(http.MethodGet, target, nil)
+	require.NoError(t, err)
+
+	e, rec := wotest.NewEvent(http.MethodGet, target,
+		wotest.WithPathValue(wo.StaticWildcardParam, path.Base(path.Dir(u.URL.Path))+"/"+path.Base(u.URL.Path)))
+	err = im.Serve(e)
+	return rec.Result(), err
+}
+
+func TestImager_Serve(t *testing.T) {
+	im := newTestImager(t, Config{MaxWidth: 500, MaxHeight: 500})
+
+	sign := func(o Options) string {
+		link, err := im.URL("/images/photos/wide.png", o, time.Hour)
+		require.NoError(t, err)
+		return link
+	}
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedType   string
+		expectedSize   image.Point
+	}{
+		{name: "original", target: "/images/photos/wide.png", expectedType: "image/png", expectedSize: image.Pt(200, 100)},
+		{name: "unsigned", target: "/images/photos/wide.png?w=50", expectedStatus: http.StatusForbidden},
+		{name: "tampered", target: sign(Options{Width: 50}) + "&h=10", expectedStatus: http.StatusForbidden},
+		{name: "contain width", target: sign(Options{Width: 50}), expectedType: "image/png", expectedSize: image.Pt(50, 25)},
+		{name: "contain box", target: sign(Options{Width: 50, Height: 50}), expectedType: "image/png", expectedSize: image.Pt(50, 25)},
+		{name: "cover", target: sign(Options{Width: 40, Height: 40, Fit: FitCover}), expectedType: "image/png", expectedSize: image.Pt(40, 40)},
+		{name: "fill", target: sign(Options{Width: 30, Height: 60, Fit: FitFill}), expectedType: "image/png", expectedSize: image.Pt(30, 60)},
+		{name: "jpeg", target: sign(Options{Height: 10, Format: FormatJPEG}), expectedType: "image/jpeg", expectedSize: image.Pt(20, 10)},
+		{name: "too wide", target: sign(Options{Width: 501}), expectedStatus: http.StatusBadRequest},
+		{name: "unknown fit", target: sign(Options{Width: 10, Fit: "zoom"}), expectedStatus: http.StatusBadRequest},
+		{name: "unknown format", target: sign(Options{Width: 10, Format: "webp"}), expectedStatus: http.StatusBadRequest},
+		{name: "format only", target: sign(Options{Format: FormatJPEG}), expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := serve(t, im, tt.target)
+			if tt.expectedStatus != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedStatus, wo.AsHTTPError(err).Status)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, tt.expectedType, res.Header.Get(wo.HeaderContentType))
+
+			cfg, _, err := image.DecodeConfig(res.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSize, image.Pt(cfg.Width, cfg.Height))
+		})
+	}
+}
+
+func TestImager_Serve_Cache(t *testing.T) {
+	im := newTestImager(t, Config{})
+
+	link, err := im.URL("/images/photos/wide.png", Options{Width: 20}, time.Hour)
+	require.NoError(t, err)
+
+	res, err := serve(t, im, link)
+	require.NoError(t, err)
+	etag := res.Header.Get(wo.HeaderETag)
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=86400", res.Header.Get(wo.HeaderCacheControl))
+
+	entries, err := os.ReadDir(im.config.CacheDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// served from the cache
+	res, err = serve(t, im, link)
+	require.NoError(t, err)
+	assert.Equal(t, etag, res.Header.Get(wo.HeaderETag))
+	cfg, err := png.DecodeConfig(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Width)
+
+	// the cache of the previous run is reused
+	reopened, err := New(im.fsys, im.signer, im.config)
+	require.NoError(t, err)
+	f, ok := reopened.cache.open(entries[0].Name())
+	require.True(t, ok)
+	require.NoError(t, f.Close())
+}
+
+func TestImager_Serve_NotImage(t *testing.T) {
+	im := newTestImager(t, Config{})
+
+	link, err := im.URL("/images/x/notes.txt", Options{Width: 20}, time.Hour)
+	require.NoError(t, err)
+
+	e, _ := wotest.NewEvent(http.MethodGet, link, wotest.WithPathValue(wo.StaticWildcardParam, "notes.txt"))
+	err = im.Serve(e)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, wo.AsHTTPError(err).Status)
+}
+
+func TestImager_Serve_TooManyPixels(t *testing.T) {
+	im := newTestImager(t, Config{MaxSourcePixels: 100})
+
+	link, err := im.URL("/images/photos/wide.png", Options{Width: 20}, time.Hour)
+	require.NoError(t, err)
+
+	_, err = serve(t, im, link)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, wo.AsHTTPError(err).Status)
+}
+
+func TestImager_Serve_Traversal(t *testing.T) {
+	im := newTestImager(t, Config{})
+
+	link, err := im.URL("/images/x", Options{Width: 20}, time.Hour)
+	require.NoError(t, err)
+
+	for _, p := range []string{"../../notes.txt/../etc/passwd", "", "photos"} {
+		e, _ := wotest.NewEvent(http.MethodGet, link, wotest.WithPathValue(wo.StaticWildcardParam, p))
+		err = im.Serve(e)
+		require.Error(t, err, p)
+		assert.Equal(t, http.StatusNotFound, wo.AsHTTPError(err).Status, p)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	// the left half is red, the right half is transparent
+	for y := range 2 {
+		for x := range 2 {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	dst := Transform(src, Options{Width: 2})
+	assert.Equal(t, image.Rect(0, 0, 2, 1), dst.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, dst.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{}, dst.RGBAAt(1, 0))
+
+	dst = Transform(src, Options{Width: 1})
+	assert.Equal(t, color.RGBA{R: 128, A: 128}, dst.RGBAAt(0, 0))
+
+	// the transparency is flattened on white for jpeg
+	dst = Transform(src, Options{Width: 2, Format: FormatJPEG})
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, dst.RGBAAt(1, 0))
+
+	// the cover crops the center
+	dst = Transform(src, Options{Width: 2, Height: 2, Fit: FitCover})
+	assert.Equal(t, image.Rect(0, 0, 2, 2), dst.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, dst.RGBAAt(0, 1))
+	assert.Equal(t, color.RGBA{}, dst.RGBAAt(1, 1))
+
+	// upscaling picks the nearest pixel
+	dst = Transform(src, Options{Width: 8, Height: 2, Fit: FitFill})
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, dst.RGBAAt(3, 0))
+	assert.Equal(t, color.RGBA{}, dst.RGBAAt(4, 0))
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, dst, nil))
+}
+
+func TestDiskCache_Evict(t *testing.T) {
+	c, err := newDiskCache(t.TempDir(), 10)
+	require.NoError(t, err)
+
+	require.NoError(t, c.put("a", []byte("12345")))
+	require.NoError(t, c.put("b", []byte("12345")))
+
+	f, ok := c.open("a")
+	require.True(t, ok)
+	require.NoError(t, f.Close())
+
+	// b is the least recently used one
+	require.NoError(t, c.put("c", []byte("123")))
+	_, ok = c.open("b")
+	assert.False(t, ok)
+	assert.Equal(t, int64(8), c.size)
+
+	// the entry larger than the maximum is kept alone
+	require.NoError(t, c.put("d", []byte("12345678901")))
+	assert.Equal(t, 1, c.lru.Len())
+	entries, err := os.ReadDir(c.dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// removed behind the cache's back
+	require.NoError(t, os.Remove(c.dir+"/d"))
+	_, ok = c.open("d")
+	assert.False(t, ok)
+	assert.Zero(t, c.size)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, cfg := range []Config{
+		{MaxWidth: -1},
+		{MaxSourcePixels: -1},
+		{Quality: 101},
+		{CacheMaxBytes: -1},
+	} {
+		cfg.SetDefaults()
+		assert.Error(t, cfg.Validate())
+	}
+}
@@ -0,0 +1,111 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Transform returns the variant of the image per the sizes and the fit of the options.
+// The format of the options is used to flatten the transparency on white for JPEG.
+func Transform(src image.Image, o Options) *image.RGBA {
+	sb := src.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	w, h := o.Width, o.Height
+	crop := sb
+
+	switch {
+	case sw == 0 || sh == 0:
+		w, h = max(w, 1), max(h, 1)
+	case w == 0:
+		w = scaled(sw, h, sh)
+	case h == 0:
+		h = scaled(sh, w, sw)
+	case o.Fit == FitCover:
+		// crop the center of the source to the aspect ratio of the size
+		if sw*h > sh*w {
+			cw := scaled(sh, w, h)
+			crop.Min.X += (sw - cw) / 2
+			crop.Max.X = crop.Min.X + cw
+		} else {
+			ch := scaled(sw, h, w)
+			crop.Min.Y += (sh - ch) / 2
+			crop.Max.Y = crop.Min.Y + ch
+		}
+	case o.Fit == FitFill:
+	default:
+		scale := min(float64(w)/float64(sw), float64(h)/float64(sh))
+		w = max(1, int(math.Round(float64(sw)*scale)))
+		h = max(1, int(math.Round(float64(sh)*scale)))
+	}
+
+	rgba := image.NewRGBA(image.Rect(0, 0, crop.Dx(), crop.Dy()))
+	if o.Format == FormatJPEG {
+		draw.Draw(rgba, rgba.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.Draw(rgba, rgba.Bounds(), src, crop.Min, draw.Over)
+	} else {
+		draw.Draw(rgba, rgba.Bounds(), src, crop.Min, draw.Src)
+	}
+
+	return resample(rgba, w, h)
+}
+
+// scaled returns n scaled by num/den, at least 1.
+func scaled(n, num, den int) int {
+	return max(1, int(math.Round(float64(n)*float64(num)/float64(den))))
+}
+
+// resample resizes the image with the box filter: each pixel is the average of the source
+// pixels it covers, or the nearest one when upscaling. The premultiplied colors are averaged,
+// so the transparent pixels don't bleed.
+func resample(src *image.RGBA, w, h int) *image.RGBA {
+	sb := src.Bounds()
+	if sb.Dx() == w && sb.Dy() == h {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if sb.Empty() {
+		return dst
+	}
+
+	xs := spans(sb.Dx(), w)
+	ys := spans(sb.Dy(), h)
+
+	for y, ySpan := range ys {
+		for x, xSpan := range xs {
+			var r, g, b, a uint64
+			for sy := ySpan[0]; sy < ySpan[1]; sy++ {
+				i := src.PixOffset(sb.Min.X+xSpan[0], sb.Min.Y+sy)
+				for sx := xSpan[0]; sx < xSpan[1]; sx++ {
+					r += uint64(src.Pix[i])
+					g += uint64(src.Pix[i+1])
+					b += uint64(src.Pix[i+2])
+					a += uint64(src.Pix[i+3])
+					i += 4
+				}
+			}
+
+			n := uint64((ySpan[1] - ySpan[0]) * (xSpan[1] - xSpan[0]))
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8((r + n/2) / n)
+			dst.Pix[j+1] = uint8((g + n/2) / n)
+			dst.Pix[j+2] = uint8((b + n/2) / n)
+			dst.Pix[j+3] = uint8((a + n/2) / n)
+		}
+	}
+	return dst
+}
+
+// spans returns the source ranges covered by each of the n destination pixels.
+func spans(size, n int) [][2]int {
+	out := make([][2]int, n)
+	for i := range out {
+		s0, s1 := i*size/n, (i+1)*size/n
+		if s1 <= s0 {
+			s1 = s0 + 1
+		}
+		out[i] = [2]int{s0, s1}
+	}
+	return out
+}
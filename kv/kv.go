@@ -0,0 +1,53 @@
+// Package kv provides the key-value [Store] shared by the middlewares needing the state across
+// the requests, ex. the rate limiter, with the in-memory, the Redis and the memcached implementations.
+package kv
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidKey is returned for the keys the store doesn't support, ex. the memcached keys
+	// with spaces or longer than 250 bytes.
+	ErrInvalidKey = errors.New("kv: invalid key")
+
+	// ErrNotInteger is returned by [Store.Incr] when the value isn't an integer.
+	ErrNotInteger = errors.New("kv: value is not an integer")
+)
+
+// Store is the key-value store with the expiring keys.
+type Store interface {
+	// Get returns the value of the key, found is false if the key doesn't exist or is expired.
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+
+	// Set sets the value of the key which expires after the ttl, the ttl less than or equal
+	// to 0 means no expiry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete deletes the key, it's a no-op if the key doesn't exist.
+	Delete(ctx context.Context, key string) error
+
+	// Incr increments the integer value of the key by the delta and returns the new value,
+	// the key which doesn't exist is set to the delta with the ttl, the ttl of the existing key
+	// is kept. The integer values are stored as the decimal strings. The negative delta may not
+	// be supported, see [MemcachedStore.Incr].
+	Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+}
+
+// AtomicStore is the [Store] with the conditional writes, ex. for the [Locker].
+type AtomicStore interface {
+	Store
+
+	// Add sets the value of the key only if it doesn't exist, it reports whether the value is set.
+	Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+
+	// CompareAndSwap sets the value of the key and its ttl only if the current value is the old one,
+	// it reports whether the value is swapped.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error)
+
+	// CompareAndDelete deletes the key only if its value is the old one, it reports whether
+	// the key is deleted.
+	CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error)
+}
@@ -0,0 +1,365 @@
+package kv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo/clock"
+)
+
+var (
+	// ErrNotAcquired is returned by [Locker.TryAcquire] when the lock is held by another owner.
+	ErrNotAcquired = errors.New("kv: lock is held by another owner")
+
+	// ErrLockLost is the cause of the canceled [Lock.Context] and is returned by [Lock.Refresh] and
+	// [Lock.Release] once the lock has expired, ex. after the failed heartbeats, or it's been
+	// acquired by another owner.
+	ErrLockLost = errors.New("kv: lock is lost")
+)
+
+type LockerConfig struct {
+	// Prefix is the prefix of the store keys of the locks.
+	//
+	// Default: lock:
+	Prefix string `env:"PREFIX" json:"prefix,omitempty" yaml:"prefix,omitempty"`
+
+	// TTL is the time the lock is held for without the heartbeat, ex. after the owner crashed.
+	//
+	// Default: 30s
+	TTL time.Duration `env:"TTL" json:"ttl,omitempty,format:units" yaml:"ttl,omitempty"`
+
+	// RefreshInterval is the interval of the heartbeat renewing the held locks for the TTL.
+	//
+	// Default: TTL / 3
+	RefreshInterval time.Duration `env:"REFRESH_INTERVAL" json:"refreshInterval,omitempty,format:units" yaml:"refreshInterval,omitempty"`
+
+	// RetryInterval is the interval of the attempts of [Locker.Acquire] while the lock is held.
+	//
+	// Default: 100ms
+	RetryInterval time.Duration `env:"RETRY_INTERVAL" json:"retryInterval,omitempty,format:units" yaml:"retryInterval,omitempty"`
+
+	// FenceTTL is the time the counter of the fencing tokens of the lock is kept for. The expired
+	// counter restarts from the current time in microseconds, so the tokens keep increasing.
+	//
+	// Default: 24h
+	FenceTTL time.Duration `env:"FENCE_TTL" json:"fenceTTL,omitempty,format:units" yaml:"fenceTTL,omitempty"`
+
+	// Clock is the clock the fencing token counter restarts from.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+func (c *LockerConfig) SetDefaults() {
+	if c.Prefix == "" {
+		c.Prefix = "lock:"
+	}
+	if c.TTL == 0 {
+		c.TTL = 30 * time.Second
+	}
+	if c.RefreshInterval == 0 {
+		c.RefreshInterval = c.TTL / 3
+	}
+	if c.RetryInterval == 0 {
+		c.RetryInterval = 100 * time.Millisecond
+	}
+	if c.FenceTTL == 0 {
+		c.FenceTTL = 24 * time.Hour
+	}
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+}
+
+func (c *LockerConfig) Validate() error {
+	if c.TTL < 0 || c.RetryInterval < 0 || c.FenceTTL < 0 {
+		return errors.New("kv: lock durations must not be negative")
+	}
+	if c.RefreshInterval <= 0 || c.RefreshInterval >= c.TTL {
+		return errors.New("kv: lock refresh interval must be positive and less than the ttl")
+	}
+	return nil
+}
+
+// Locker is the distributed lock of the instances sharing the [AtomicStore], ex. the Redis one.
+// The held locks are renewed by the heartbeat until they are released, and carry the fencing
+// tokens increasing with each acquisition, so the resource can reject the writes of the owner
+// which lost the lock unknowingly, ex. after a long GC pause:
+//
+//	locker := kv.NewLocker(kv.LockerConfig{}, store)
+//
+//	err := locker.Do(ctx, "report:"+id, func(ctx context.Context, token uint64) error {
+//		return generateReport(ctx, id, token) // ctx is canceled once the lock is lost
+//	})
+type Locker struct {
+	config LockerConfig
+	store  AtomicStore
+}
+
+func NewLocker(cfg LockerConfig, store AtomicStore) *Locker {
+	if store == nil {
+		panic("kv: locker store is nil")
+	}
+
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &Locker{config: cfg, store: store}
+}
+
+// TryAcquire acquires the lock of the name once, [ErrNotAcquired] is returned if it's held.
+// The acquired lock must be released.
+func (l *Locker) TryAcquire(ctx context.Context, name string) (*Lock, error) {
+	key := l.config.Prefix + name
+
+	// the fencing token is taken before the lock, so the later owner never gets the lower token
+	token, err := l.fence(ctx, key+":fence")
+	if err != nil {
+		return nil, err
+	}
+
+	owner := make([]byte, 16)
+	if _, err = rand.Read(owner); err != nil {
+		return nil, err
+	}
+	value := []byte(strconv.FormatInt(token, 10) + ":" + hex.EncodeToString(owner))
+
+	acquired, err := l.store.Add(ctx, key, value, l.config.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrNotAcquired
+	}
+
+	lockCtx, cancel := context.WithCancelCause(context.Background())
+	lock := &Lock{
+		locker: l,
+		key:    key,
+		value:  value,
+		token:  uint64(token),
+		ctx:    lockCtx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go lock.heartbeat()
+	return lock, nil
+}
+
+// fence returns the next fencing token of the counter, which is started from the current time
+// in microseconds, so it's greater than the tokens of the expired counter.
+func (l *Locker) fence(ctx context.Context, key string) (int64, error) {
+	for {
+		start := strconv.FormatInt(l.config.Clock.Now().UnixMicro(), 10)
+		if _, err := l.store.Add(ctx, key, []byte(start), l.config.FenceTTL); err != nil {
+			return 0, err
+		}
+
+		token, err := l.store.Incr(ctx, key, 1, l.config.FenceTTL)
+		if err != nil || token > math.MaxUint32 {
+			return token, err
+		}
+
+		// the counter expired between Add and Incr, or it's been started from 0
+		// by the former version, so it's started again
+		if err = l.store.Delete(ctx, key); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Acquire acquires the lock of the name, waiting for it while it's held until the context is done.
+// The acquired lock must be released.
+func (l *Locker) Acquire(ctx context.Context, name string) (*Lock, error) {
+	for {
+		lock, err := l.TryAcquire(ctx, name)
+		if !errors.Is(err, ErrNotAcquired) {
+			return lock, err
+		}
+
+		timer := time.NewTimer(l.config.RetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Do runs the function holding the lock of the name, see Acquire. The context of the function
+// is canceled once the lock is lost.
+func (l *Locker) Do(ctx context.Context, name string, fn func(ctx context.Context, token uint64) error) error {
+	lock, err := l.Acquire(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	fnCtx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(lock.Context(), func() {
+		cancel(context.Cause(lock.Context()))
+	})
+
+	err = fn(fnCtx, lock.Token())
+
+	stop()
+	cancel(nil)
+
+	return errors.Join(err, lock.Release(context.WithoutCancel(ctx)))
+}
+
+// Leader returns the function reporting whether the instance is the leader holding the lock of
+// the name, ex. for session.JanitorConfig.Leader. The leadership is kept by the heartbeat until
+// the lock is lost, then it's tried to be acquired again on the next call. The lock is released,
+// and the heartbeat is stopped, once the context of the call which acquired it is done, so the
+// context must span the leadership, ex. the one canceled by session.Janitor.Stop.
+func (l *Locker) Leader(name string) func(ctx context.Context) (bool, error) {
+	var (
+		mu   sync.Mutex
+		lock *Lock
+		stop = func() bool { return false }
+	)
+
+	return func(ctx context.Context) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if lock != nil && lock.Context().Err() == nil {
+			return true, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		acquired, err := l.TryAcquire(ctx, name)
+		if err != nil {
+			if errors.Is(err, ErrNotAcquired) {
+				err = nil
+			}
+			return false, err
+		}
+
+		stop()
+		lock = acquired
+		stop = context.AfterFunc(ctx, func() {
+			_ = acquired.Release(context.WithoutCancel(ctx))
+		})
+		return true, nil
+	}
+}
+
+// Lock is the lock held by the [Locker].
+type Lock struct {
+	locker *Locker
+	key    string
+	value  []byte
+	token  uint64
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// Token returns the fencing token of the lock, which is greater than the ones of the locks of
+// the name acquired before. The resource guarded by the lock should reject the token lower than
+// the highest one it's seen.
+func (l *Lock) Token() uint64 {
+	return l.token
+}
+
+// Context returns the context canceled once the lock is lost, with [ErrLockLost] as the cause,
+// or released.
+func (l *Lock) Context() context.Context {
+	return l.ctx
+}
+
+// Refresh renews the lock for the TTL, it's called by the heartbeat.
+func (l *Lock) Refresh(ctx context.Context) error {
+	if err := l.ctx.Err(); err != nil {
+		return context.Cause(l.ctx)
+	}
+
+	refreshed, err := l.locker.store.CompareAndSwap(ctx, l.key, l.value, l.value, l.locker.config.TTL)
+	if err != nil {
+		return err
+	}
+	if !refreshed {
+		l.cancel(ErrLockLost)
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release stops the heartbeat and releases the lock, [ErrLockLost] is returned if it's
+// been lost before. It's safe to call Release more than once.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.done
+
+	if l.ctx.Err() != nil {
+		if cause := context.Cause(l.ctx); errors.Is(cause, ErrLockLost) {
+			return cause
+		}
+		return nil
+	}
+
+	released, err := l.locker.store.CompareAndDelete(ctx, l.key, l.value)
+	if err != nil {
+		return err
+	}
+	if !released {
+		l.cancel(ErrLockLost)
+		return ErrLockLost
+	}
+	l.cancel(context.Canceled)
+	return nil
+}
+
+// heartbeat refreshes the lock until it's released, the lock is lost if it isn't refreshed
+// within the TTL, ex. while the store is unavailable.
+func (l *Lock) heartbeat() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.locker.config.RefreshInterval)
+	defer ticker.Stop()
+
+	refreshed := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(l.ctx, l.locker.config.RefreshInterval)
+		err := l.Refresh(ctx)
+		cancel()
+
+		switch {
+		case err == nil:
+			refreshed = time.Now()
+		case errors.Is(err, ErrLockLost):
+			return
+		case time.Since(refreshed) >= l.locker.config.TTL:
+			l.cancel(ErrLockLost)
+			return
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+func TestLocker(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	locker := NewLocker(LockerConfig{}, store)
+
+	lock, err := locker.TryAcquire(ctx, "name")
+	require.NoError(t, err)
+	token := lock.Token()
+	assert.Greater(t, token, uint64(time.Now().Add(-time.Minute).UnixMicro()))
+
+	_, err = locker.TryAcquire(ctx, "name")
+	assert.ErrorIs(t, err, ErrNotAcquired)
+
+	other, err := locker.TryAcquire(ctx, "other")
+	require.NoError(t, err)
+	require.NoError(t, other.Release(ctx))
+
+	require.NoError(t, lock.Refresh(ctx))
+	require.NoError(t, lock.Release(ctx))
+	require.NoError(t, lock.Release(ctx))
+	assert.ErrorIs(t, lock.Context().Err(), context.Canceled)
+
+	lock, err = locker.TryAcquire(ctx, "name")
+	require.NoError(t, err)
+	assert.Equal(t, token+2, lock.Token())
+	require.NoError(t, lock.Release(ctx))
+}
+
+func TestLocker_Fence(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	defer store.Stop()
+
+	locker := NewLocker(LockerConfig{FenceTTL: time.Hour, Clock: clk}, store)
+
+	acquire := func() uint64 {
+		lock, err := locker.TryAcquire(ctx, "name")
+		require.NoError(t, err)
+		require.NoError(t, lock.Release(ctx))
+		return lock.Token()
+	}
+
+	assert.Equal(t, uint64(1_700_000_000_000_001), acquire())
+	assert.Equal(t, uint64(1_700_000_000_000_002), acquire())
+
+	// the counter expires and restarts from the current time
+	clk.Add(time.Hour)
+	assert.Equal(t, uint64(1_700_003_600_000_001), acquire())
+
+	// the counter started from 0 is started again
+	require.NoError(t, store.Set(ctx, "lock:name:fence", []byte("7"), 0))
+	assert.Equal(t, uint64(1_700_003_600_000_001), acquire())
+}
+
+func TestLocker_Lost(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	locker := NewLocker(LockerConfig{}, store)
+
+	lock, err := locker.TryAcquire(ctx, "name")
+	require.NoError(t, err)
+
+	// the lock is expired and acquired by another owner
+	require.NoError(t, store.Set(ctx, "lock:name", []byte("another"), 0))
+
+	assert.ErrorIs(t, lock.Refresh(ctx), ErrLockLost)
+	assert.ErrorIs(t, context.Cause(lock.Context()), ErrLockLost)
+	assert.ErrorIs(t, lock.Release(ctx), ErrLockLost)
+
+	value, _, err := store.Get(ctx, "lock:name")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("another"), value)
+}
+
+func TestLocker_Heartbeat(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	locker := NewLocker(LockerConfig{TTL: 60 * time.Millisecond, RefreshInterval: 10 * time.Millisecond}, store)
+
+	lock, err := locker.TryAcquire(ctx, "name")
+	require.NoError(t, err)
+
+	time.Sleep(150 * time.Millisecond)
+
+	require.NoError(t, lock.Context().Err())
+	_, err = locker.TryAcquire(ctx, "name")
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	require.NoError(t, lock.Release(ctx))
+}
+
+func TestLocker_Do(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	locker := NewLocker(LockerConfig{RetryInterval: time.Millisecond}, store)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			err := locker.Do(ctx, "counter", func(ctx context.Context, _ uint64) error {
+				value, _, err := store.Get(ctx, "counter")
+				if err != nil {
+					return err
+				}
+				n, _ := strconv.Atoi(string(value))
+				return store.Set(ctx, "counter", []byte(strconv.Itoa(n+1)), 0)
+			})
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	value, _, err := store.Get(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("10"), value)
+
+	// the function is canceled once the lock is lost
+	heartbeat := NewLocker(LockerConfig{TTL: time.Minute, RefreshInterval: 10 * time.Millisecond}, store)
+	err = heartbeat.Do(ctx, "name", func(ctx context.Context, _ uint64) error {
+		require.NoError(t, store.Delete(ctx, "lock:name"))
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+	assert.ErrorIs(t, err, ErrLockLost)
+
+	// the acquisition is canceled with the context
+	lock, err := locker.TryAcquire(ctx, "held")
+	require.NoError(t, err)
+	defer func() {
+		_ = lock.Release(ctx)
+	}()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err = locker.Do(timeoutCtx, "held", func(context.Context, uint64) error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLocker_Leader(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	locker := NewLocker(LockerConfig{}, store)
+	a, b := locker.Leader("leader"), locker.Leader("leader")
+
+	leader, err := a(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+
+	leader, err = a(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+
+	leader, err = b(ctx)
+	require.NoError(t, err)
+	assert.False(t, leader)
+}
+
+func TestLocker_Leader_Resign(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	locker := NewLocker(LockerConfig{}, store)
+	a, b := locker.Leader("leader"), locker.Leader("leader")
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	leader, err := a(leaderCtx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+
+	// the lock is released once the context is done
+	cancel()
+	assert.Eventually(t, func() bool {
+		_, found, _ := store.Get(ctx, "lock:leader")
+		return !found
+	}, time.Second, time.Millisecond)
+
+	leader, err = a(leaderCtx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, leader)
+
+	leader, err = b(ctx)
+	require.NoError(t, err)
+	assert.True(t, leader)
+}
@@ -0,0 +1,349 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+var _ AtomicStore = (*MemcachedStore)(nil)
+
+// ErrNegativeDelta is returned by [MemcachedStore.Incr] for the negative delta, the memcached
+// counters are unsigned and the decrements floor at zero, unlike the [Store.Incr] contract.
+var ErrNegativeDelta = errors.New("kv: memcached: negative delta")
+
+// memcachedRelativeLimit is the maximum relative expiration time of memcached, the longer ones
+// are sent as the unix time.
+const memcachedRelativeLimit = 30 * 24 * time.Hour
+
+// MemcachedError is the error reply of the memcached server.
+type MemcachedError string
+
+func (e MemcachedError) Error() string {
+	return "kv: memcached: " + string(e)
+}
+
+type MemcachedConfig struct {
+	// Addr is the host:port address of the memcached server.
+	//
+	// Default: localhost:11211
+	Addr string `env:"ADDR" json:"addr,omitempty" yaml:"addr,omitempty"`
+
+	// DialTimeout is the timeout of establishing the connection.
+	//
+	// Default: 5s
+	DialTimeout time.Duration `env:"DIAL_TIMEOUT" json:"dialTimeout,omitempty,format:units" yaml:"dialTimeout,omitempty"`
+
+	// Timeout is the timeout of a command if the context has no deadline.
+	//
+	// Default: 3s
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// PoolSize is the maximum number of the idle connections kept.
+	//
+	// Default: 10
+	PoolSize int `env:"POOL_SIZE" json:"poolSize,omitempty" yaml:"poolSize,omitempty"`
+
+	// Dialer dials the connections.
+	//
+	// Default: net.Dialer with DialTimeout
+	Dialer func(ctx context.Context, network, addr string) (net.Conn, error) `json:"-" yaml:"-"`
+}
+
+func (c *MemcachedConfig) SetDefaults() {
+	if c.Addr == "" {
+		c.Addr = "localhost:11211"
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = 5 * time.Second
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 3 * time.Second
+	}
+	if c.PoolSize == 0 {
+		c.PoolSize = 10
+	}
+	if c.Dialer == nil {
+		c.Dialer = (&net.Dialer{Timeout: c.DialTimeout}).DialContext
+	}
+}
+
+func (c *MemcachedConfig) Validate() error {
+	if c.PoolSize < 0 {
+		return errors.New("kv: memcached pool size must not be negative")
+	}
+	return nil
+}
+
+// MemcachedStore is the [Store] of the memcached server, speaking the text protocol over the
+// pooled connections. The keys are limited to 250 bytes without spaces and control characters,
+// the ttls are rounded up to seconds. Memcached counters are unsigned: Incr with the negative
+// delta stops at 0. Close the store when it's no longer used.
+type MemcachedStore struct {
+	pool *pool
+}
+
+func NewMemcachedStore(cfg MemcachedConfig) *MemcachedStore {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return cfg.Dialer(ctx, "tcp", cfg.Addr)
+	}
+
+	return &MemcachedStore{pool: newPool(cfg.PoolSize, cfg.Timeout, dial, nil)}
+}
+
+func (s *MemcachedStore) Get(ctx context.Context, key string) (value []byte, found bool, err error) {
+	if !validMemcachedKey(key) {
+		return nil, false, ErrInvalidKey
+	}
+
+	err = s.do(ctx, func(c *conn) error {
+		value, _, found, err = s.get(c, "get", key)
+		return err
+	})
+	return value, found, err
+}
+
+func (s *MemcachedStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if !validMemcachedKey(key) {
+		return ErrInvalidKey
+	}
+
+	return s.do(ctx, func(c *conn) error {
+		_, err := s.store(c, "set", key, value, memcachedExptime(ttl))
+		return err
+	})
+}
+
+func (s *MemcachedStore) Add(ctx context.Context, key string, value []byte, ttl time.Duration) (stored bool, err error) {
+	if !validMemcachedKey(key) {
+		return false, ErrInvalidKey
+	}
+
+	err = s.do(ctx, func(c *conn) error {
+		stored, err = s.store(c, "add", key, value, memcachedExptime(ttl))
+		return err
+	})
+	return stored, err
+}
+
+// CompareAndSwap reads the value with its cas unique by gets and swaps it by cas.
+func (s *MemcachedStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	return s.cas(ctx, key, old, value, memcachedExptime(ttl))
+}
+
+// CompareAndDelete expires the key by cas with the negative expiration time, as memcached
+// has no conditional delete.
+func (s *MemcachedStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	return s.cas(ctx, key, old, nil, -1)
+}
+
+func (s *MemcachedStore) Delete(ctx context.Context, key string) error {
+	if !validMemcachedKey(key) {
+		return ErrInvalidKey
+	}
+
+	return s.do(ctx, func(c *conn) error {
+		_, _ = fmt.Fprintf(c.w, "delete %s\r\n", key)
+		if err := c.w.Flush(); err != nil {
+			return err
+		}
+
+		line, err := readLine(c)
+		if err != nil {
+			return err
+		}
+		if string(line) != "DELETED" && string(line) != "NOT_FOUND" {
+			return memcachedReplyError(line)
+		}
+		return nil
+	})
+}
+
+// Incr increments the key by incr, the key which doesn't exist is added with the ttl, and
+// incremented again if it's been added concurrently. The negative delta isn't supported,
+// it returns [ErrNegativeDelta].
+func (s *MemcachedStore) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (n int64, err error) {
+	if !validMemcachedKey(key) {
+		return 0, ErrInvalidKey
+	}
+	if delta < 0 {
+		return 0, ErrNegativeDelta
+	}
+
+	err = s.do(ctx, func(c *conn) error {
+		for range 2 {
+			_, _ = fmt.Fprintf(c.w, "incr %s %d\r\n", key, delta)
+			if err := c.w.Flush(); err != nil {
+				return err
+			}
+
+			line, err := readLine(c)
+			if err != nil {
+				return err
+			}
+			if string(line) != "NOT_FOUND" {
+				u, err := strconv.ParseUint(string(line), 10, 64)
+				if err != nil {
+					if bytes.Contains(line, []byte("non-numeric")) {
+						return ErrNotInteger
+					}
+					return memcachedReplyError(line)
+				}
+				n = int64(u)
+				return nil
+			}
+
+			n = delta
+			stored, err := s.store(c, "add", key, strconv.AppendInt(nil, n, 10), memcachedExptime(ttl))
+			if err != nil || stored {
+				return err
+			}
+		}
+		return errors.New("kv: memcached: key is deleted concurrently")
+	})
+	return n, err
+}
+
+func (s *MemcachedStore) cas(ctx context.Context, key string, old, value []byte, exptime int64) (swapped bool, err error) {
+	if !validMemcachedKey(key) {
+		return false, ErrInvalidKey
+	}
+
+	err = s.do(ctx, func(c *conn) error {
+		current, unique, found, err := s.get(c, "gets", key)
+		if err != nil || !found || !bytes.Equal(current, old) {
+			return err
+		}
+
+		swapped, err = s.store(c, "cas", key, value, exptime, unique)
+		return err
+	})
+	return swapped, err
+}
+
+// Close closes the idle connections.
+func (s *MemcachedStore) Close() error {
+	return s.pool.close()
+}
+
+func (s *MemcachedStore) do(ctx context.Context, fn func(c *conn) error) error {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.pool.put(c)
+
+	if err = fn(c); err != nil {
+		var replyErr MemcachedError
+		if !errors.Is(err, ErrNotInteger) && !errors.As(err, &replyErr) {
+			c.broken = true
+		}
+	}
+	return err
+}
+
+// get sends the retrieval command, get or gets, and returns the value with its cas unique.
+func (s *MemcachedStore) get(c *conn, cmd, key string) (value []byte, unique string, found bool, err error) {
+	_, _ = fmt.Fprintf(c.w, "%s %s\r\n", cmd, key)
+	if err = c.w.Flush(); err != nil {
+		return nil, "", false, err
+	}
+
+	for {
+		line, err := readLine(c)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if string(line) == "END" {
+			return value, unique, found, nil
+		}
+
+		// VALUE <key> <flags> <bytes> [<cas unique>]
+		fields := bytes.Fields(line)
+		if len(fields) < 4 || string(fields[0]) != "VALUE" {
+			return nil, "", false, memcachedReplyError(line)
+		}
+		n, err := strconv.Atoi(string(fields[3]))
+		if err != nil || n < 0 {
+			return nil, "", false, fmt.Errorf("kv: memcached: unexpected reply %q", line)
+		}
+		if len(fields) > 4 {
+			unique = string(fields[4])
+		}
+
+		b := make([]byte, n+2)
+		if _, err = io.ReadFull(c.r, b); err != nil {
+			return nil, "", false, err
+		}
+		value, found = b[:n], true
+	}
+}
+
+// store sends the storage command, set, add or cas with the cas unique, and reports whether
+// the value is stored.
+func (s *MemcachedStore) store(c *conn, cmd, key string, value []byte, exptime int64, unique ...string) (bool, error) {
+	_, _ = fmt.Fprintf(c.w, "%s %s 0 %d %d", cmd, key, exptime, len(value))
+	for _, u := range unique {
+		_, _ = c.w.WriteString(" " + u)
+	}
+	_, _ = c.w.WriteString("\r\n")
+	_, _ = c.w.Write(value)
+	_, _ = c.w.WriteString("\r\n")
+	if err := c.w.Flush(); err != nil {
+		return false, err
+	}
+
+	line, err := readLine(c)
+	if err != nil {
+		return false, err
+	}
+	switch string(line) {
+	case "STORED":
+		return true, nil
+	case "NOT_STORED", "EXISTS", "NOT_FOUND":
+		return false, nil
+	default:
+		return false, memcachedReplyError(line)
+	}
+}
+
+// memcachedExptime returns the expiration time of the ttl: 0 is no expiry, up to 30 days
+// it's relative in seconds, above it's the unix time.
+func memcachedExptime(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+
+	sec := int64((ttl + time.Second - 1) / time.Second)
+	if ttl > memcachedRelativeLimit {
+		return time.Now().Unix() + sec
+	}
+	return sec
+}
+
+func memcachedReplyError(line []byte) error {
+	return MemcachedError(line)
+}
+
+func validMemcachedKey(key string) bool {
+	if key == "" || len(key) > 250 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
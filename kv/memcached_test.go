@@ -0,0 +1,272 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMemcached is the text protocol server of the commands used by the MemcachedStore,
+// the exptimes are recorded but not applied.
+type fakeMemcached struct {
+	mu       sync.Mutex
+	data     map[string]string
+	exptimes map[string]string
+	uniques  map[string]int
+	unique   int
+}
+
+func newFakeMemcached(t *testing.T) (*fakeMemcached, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+
+	f := &fakeMemcached{data: make(map[string]string), exptimes: make(map[string]string), uniques: make(map[string]int)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeMemcached) serve(c net.Conn) {
+	defer func() {
+		_ = c.Close()
+	}()
+
+	r := bufio.NewReader(c)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+
+		var value string
+		if args[0] == "set" || args[0] == "add" || args[0] == "cas" {
+			size, _ := strconv.Atoi(args[4])
+			b := make([]byte, size+2)
+			if _, err = io.ReadFull(r, b); err != nil {
+				return
+			}
+			value = string(b[:size])
+		}
+
+		_, _ = io.WriteString(c, f.exec(args, value))
+	}
+}
+
+func (f *fakeMemcached) exec(args []string, value string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch args[0] {
+	case "get", "gets":
+		v, ok := f.data[args[1]]
+		if !ok {
+			return "END\r\n"
+		}
+		if args[0] == "gets" {
+			return fmt.Sprintf("VALUE %s 0 %d %d\r\n%s\r\nEND\r\n", args[1], len(v), f.uniques[args[1]], v)
+		}
+		return fmt.Sprintf("VALUE %s 0 %d\r\n%s\r\nEND\r\n", args[1], len(v), v)
+	case "set", "add", "cas":
+		_, ok := f.data[args[1]]
+		if ok && args[0] == "add" {
+			return "NOT_STORED\r\n"
+		}
+		if args[0] == "cas" {
+			if !ok {
+				return "NOT_FOUND\r\n"
+			}
+			if strconv.Itoa(f.uniques[args[1]]) != args[5] {
+				return "EXISTS\r\n"
+			}
+			if args[3] == "-1" {
+				delete(f.data, args[1])
+				return "STORED\r\n"
+			}
+		}
+		f.unique++
+		f.data[args[1]] = value
+		f.exptimes[args[1]] = args[3]
+		f.uniques[args[1]] = f.unique
+		return "STORED\r\n"
+	case "delete":
+		if _, ok := f.data[args[1]]; !ok {
+			return "NOT_FOUND\r\n"
+		}
+		delete(f.data, args[1])
+		return "DELETED\r\n"
+	case "incr", "decr":
+		v, ok := f.data[args[1]]
+		if !ok {
+			return "NOT_FOUND\r\n"
+		}
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n"
+		}
+		delta, _ := strconv.ParseUint(args[2], 10, 64)
+		if args[0] == "incr" {
+			n += delta
+		} else {
+			n -= min(n, delta)
+		}
+		f.data[args[1]] = strconv.FormatUint(n, 10)
+		return f.data[args[1]] + "\r\n"
+	default:
+		return "ERROR\r\n"
+	}
+}
+
+func TestMemcachedStore(t *testing.T) {
+	ctx := context.Background()
+	f, addr := newFakeMemcached(t)
+
+	s := NewMemcachedStore(MemcachedConfig{Addr: addr})
+	defer func() {
+		_ = s.Close()
+	}()
+
+	_, ok, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.Set(ctx, "key", []byte("value\r\n"), 1500*time.Millisecond))
+	value, ok, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value\r\n"), value)
+
+	require.NoError(t, s.Delete(ctx, "key"))
+	require.NoError(t, s.Delete(ctx, "key"))
+	_, ok, err = s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assert.Equal(t, "2", f.exptimes["key"])
+}
+
+func TestMemcachedStore_Incr(t *testing.T) {
+	ctx := context.Background()
+	f, addr := newFakeMemcached(t)
+
+	s := NewMemcachedStore(MemcachedConfig{Addr: addr})
+	defer func() {
+		_ = s.Close()
+	}()
+
+	n, err := s.Incr(ctx, "counter", 2, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	n, err = s.Incr(ctx, "counter", 3, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	_, err = s.Incr(ctx, "counter", -1, time.Minute)
+	assert.ErrorIs(t, err, ErrNegativeDelta)
+
+	require.NoError(t, s.Set(ctx, "text", []byte("text"), 0))
+	_, err = s.Incr(ctx, "text", 1, 0)
+	assert.ErrorIs(t, err, ErrNotInteger)
+
+	// the connection is reused after the error reply
+	n, err = s.Incr(ctx, "counter", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assert.Equal(t, "60", f.exptimes["counter"])
+}
+
+func TestMemcachedStore_Atomic(t *testing.T) {
+	ctx := context.Background()
+	f, addr := newFakeMemcached(t)
+
+	s := NewMemcachedStore(MemcachedConfig{Addr: addr})
+	defer func() {
+		_ = s.Close()
+	}()
+
+	added, err := s.Add(ctx, "key", []byte("a"), time.Second)
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	added, err = s.Add(ctx, "key", []byte("b"), time.Second)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	swapped, err := s.CompareAndSwap(ctx, "key", []byte("b"), []byte("c"), time.Second)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = s.CompareAndSwap(ctx, "key", []byte("a"), []byte("c"), 2*time.Second)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	f.mu.Lock()
+	assert.Equal(t, "c", f.data["key"])
+	assert.Equal(t, "2", f.exptimes["key"])
+	f.mu.Unlock()
+
+	deleted, err := s.CompareAndDelete(ctx, "key", []byte("a"))
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = s.CompareAndDelete(ctx, "key", []byte("c"))
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, ok, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	deleted, err = s.CompareAndDelete(ctx, "key", []byte("c"))
+	require.NoError(t, err)
+	assert.False(t, deleted)
+}
+
+func TestMemcachedStore_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemcachedStore(MemcachedConfig{Addr: "127.0.0.1:0"})
+
+	for _, key := range []string{"", "with space", "with\nnewline", strings.Repeat("k", 251)} {
+		_, _, err := s.Get(ctx, key)
+		assert.ErrorIs(t, err, ErrInvalidKey)
+		assert.ErrorIs(t, s.Set(ctx, key, nil, 0), ErrInvalidKey)
+		assert.ErrorIs(t, s.Delete(ctx, key), ErrInvalidKey)
+		_, err = s.Incr(ctx, key, 1, 0)
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	}
+}
+
+func TestMemcachedExptime(t *testing.T) {
+	assert.Equal(t, int64(0), memcachedExptime(0))
+	assert.Equal(t, int64(1), memcachedExptime(time.Millisecond))
+	assert.Equal(t, int64(30*24*3600), memcachedExptime(memcachedRelativeLimit))
+	assert.Greater(t, memcachedExptime(memcachedRelativeLimit+time.Second), time.Now().Unix())
+}
@@ -0,0 +1,253 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"hash/maphash"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo/clock"
+	"github.com/gowool/wo/internal/arr"
+)
+
+var _ AtomicStore = (*MemoryStore)(nil)
+
+type MemoryStoreConfig struct {
+	// Shards is the number of the independently locked shards the keys are spread across.
+	//
+	// Default: 32
+	Shards int `env:"SHARDS" json:"shards,omitempty" yaml:"shards,omitempty"`
+
+	// CleanupInterval is the interval of the janitor deleting the expired keys.
+	// If less than 0, the janitor is not started and the expired keys are deleted
+	// only when they are found, or by Cleanup.
+	//
+	// Default: 1m
+	CleanupInterval time.Duration `env:"CLEANUP_INTERVAL" json:"cleanupInterval,omitempty,format:units" yaml:"cleanupInterval,omitempty"`
+
+	// Clock is the clock of the expiry.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+func (c *MemoryStoreConfig) SetDefaults() {
+	if c.Shards <= 0 {
+		c.Shards = 32
+	}
+	if c.CleanupInterval == 0 {
+		c.CleanupInterval = time.Minute
+	}
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+}
+
+type memItem struct {
+	value  []byte
+	expiry time.Time // zero is no expiry
+}
+
+type memShard struct {
+	mu    sync.Mutex
+	items map[string]memItem
+}
+
+// MemoryStore is the in-memory [Store] for a single instance deployment, the keys are lost on restart.
+// Stop the janitor when the store is no longer used.
+type MemoryStore struct {
+	cfg    MemoryStoreConfig
+	seed   maphash.Seed
+	shards []*memShard
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func NewMemoryStore(cfg MemoryStoreConfig) *MemoryStore {
+	cfg.SetDefaults()
+
+	s := &MemoryStore{
+		cfg:    cfg,
+		seed:   maphash.MakeSeed(),
+		shards: make([]*memShard, cfg.Shards),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i := range s.shards {
+		s.shards[i] = &memShard{items: make(map[string]memItem)}
+	}
+
+	if cfg.CleanupInterval > 0 {
+		go s.janitor(cfg.CleanupInterval)
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	item, ok := s.lookup(sh, key)
+	if !ok {
+		return nil, false, nil
+	}
+	return arr.Copy(item.value), true, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	sh.items[key] = memItem{value: arr.Copy(value), expiry: s.expiry(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	delete(sh.items, key)
+	return nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	item, ok := s.lookup(sh, key)
+	if !ok {
+		item = memItem{value: []byte("0"), expiry: s.expiry(ttl)}
+	}
+
+	n, err := strconv.ParseInt(string(item.value), 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+	n += delta
+
+	item.value = strconv.AppendInt(nil, n, 10)
+	sh.items[key] = item
+	return n, nil
+}
+
+func (s *MemoryStore) Add(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if _, ok := s.lookup(sh, key); ok {
+		return false, nil
+	}
+	sh.items[key] = memItem{value: arr.Copy(value), expiry: s.expiry(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) CompareAndSwap(_ context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if item, ok := s.lookup(sh, key); !ok || !bytes.Equal(item.value, old) {
+		return false, nil
+	}
+	sh.items[key] = memItem{value: arr.Copy(value), expiry: s.expiry(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) CompareAndDelete(_ context.Context, key string, old []byte) (bool, error) {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if item, ok := s.lookup(sh, key); !ok || !bytes.Equal(item.value, old) {
+		return false, nil
+	}
+	delete(sh.items, key)
+	return true, nil
+}
+
+// Cleanup deletes the expired keys, it's called by the janitor periodically, see
+// MemoryStoreConfig.CleanupInterval.
+func (s *MemoryStore) Cleanup(ctx context.Context) error {
+	now := s.cfg.Clock.Now()
+
+	for _, sh := range s.shards {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		sh.mu.Lock()
+		for key, item := range sh.items {
+			if !item.expiry.IsZero() && !now.Before(item.expiry) {
+				delete(sh.items, key)
+			}
+		}
+		sh.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop stops the janitor and waits for it to return. It's safe to call Stop more than once,
+// the store remains usable after it.
+func (s *MemoryStore) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+}
+
+func (s *MemoryStore) janitor(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			_ = s.Cleanup(context.Background())
+		}
+	}
+}
+
+func (s *MemoryStore) shard(key string) *memShard {
+	return s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+}
+
+// lookup returns the unexpired item of the key, the expired one is deleted.
+func (s *MemoryStore) lookup(sh *memShard, key string) (memItem, bool) {
+	item, ok := sh.items[key]
+	if !ok {
+		return memItem{}, false
+	}
+	if !item.expiry.IsZero() && !s.cfg.Clock.Now().Before(item.expiry) {
+		delete(sh.items, key)
+		return memItem{}, false
+	}
+	return item, true
+}
+
+func (s *MemoryStore) expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return s.cfg.Clock.Now().Add(ttl)
+}
@@ -0,0 +1,89 @@
+package kv
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+
+	s := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	defer s.Stop()
+
+	value := []byte("value")
+	require.NoError(t, s.Set(ctx, "key", value, time.Minute))
+	value[0] = 'V'
+
+	found, ok, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), found)
+
+	require.NoError(t, s.Delete(ctx, "key"))
+	require.NoError(t, s.Delete(ctx, "key"))
+	_, ok, err = s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+
+	s := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	defer s.Stop()
+
+	require.NoError(t, s.Set(ctx, "a", []byte("a"), time.Minute))
+	require.NoError(t, s.Set(ctx, "b", []byte("b"), 0))
+
+	clk.Add(time.Minute)
+
+	_, ok, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.Cleanup(ctx))
+	_, ok, err = s.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestMemoryStore_Incr(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+
+	s := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	defer s.Stop()
+
+	n, err := s.Incr(ctx, "counter", 2, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	clk.Add(30 * time.Second)
+
+	n, err = s.Incr(ctx, "counter", -5, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-3), n)
+
+	value, _, err := s.Get(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("-3"), value)
+
+	// the ttl of the existing key is kept
+	clk.Add(30 * time.Second)
+	n, err = s.Incr(ctx, "counter", 1, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	require.NoError(t, s.Set(ctx, "text", []byte("text"), 0))
+	_, err = s.Incr(ctx, "text", 1, 0)
+	assert.ErrorIs(t, err, ErrNotInteger)
+}
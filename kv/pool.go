@@ -0,0 +1,89 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"time"
+)
+
+// conn is the pooled connection of the text protocol clients.
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+
+	// broken is set after an I/O or a protocol error, the connection isn't reused.
+	broken bool
+}
+
+// pool is the pool of the idle connections of the Redis and the memcached clients.
+type pool struct {
+	dial    func(ctx context.Context) (net.Conn, error)
+	init    func(c *conn) error
+	idle    chan *conn
+	timeout time.Duration
+}
+
+func newPool(size int, timeout time.Duration, dial func(ctx context.Context) (net.Conn, error), init func(c *conn) error) *pool {
+	return &pool{dial: dial, init: init, idle: make(chan *conn, size), timeout: timeout}
+}
+
+// get returns an idle connection or dials a new one, the deadline of the connection is the one
+// of the context or the timeout.
+func (p *pool) get(ctx context.Context) (*conn, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(p.timeout)
+	}
+
+	var c *conn
+	select {
+	case c = <-p.idle:
+	default:
+		nc, err := p.dial(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c = &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+		_ = c.SetDeadline(deadline)
+		if p.init != nil {
+			if err = p.init(c); err != nil {
+				_ = nc.Close()
+				return nil, err
+			}
+		}
+	}
+
+	if err := c.SetDeadline(deadline); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// put returns the connection to the pool, the broken ones and the ones above the pool size are closed.
+func (p *pool) put(c *conn) {
+	if c.broken {
+		_ = c.Close()
+		return
+	}
+
+	select {
+	case p.idle <- c:
+	default:
+		_ = c.Close()
+	}
+}
+
+// close closes the idle connections.
+func (p *pool) close() error {
+	for {
+		select {
+		case c := <-p.idle:
+			_ = c.Close()
+		default:
+			return nil
+		}
+	}
+}
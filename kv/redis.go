@@ -0,0 +1,372 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ AtomicStore = (*RedisStore)(nil)
+
+const (
+	// redisCompareAndSwap sets KEYS[1] to ARGV[2] with the ttl ARGV[3] in milliseconds (0 is no expiry)
+	// if its value is ARGV[1].
+	redisCompareAndSwap = `if redis.call('GET', KEYS[1]) ~= ARGV[1] then return 0 end
+if ARGV[3] == '0' then redis.call('SET', KEYS[1], ARGV[2]) else redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3]) end
+return 1`
+
+	// redisCompareAndDelete deletes KEYS[1] if its value is ARGV[1].
+	redisCompareAndDelete = `if redis.call('GET', KEYS[1]) ~= ARGV[1] then return 0 end
+return redis.call('DEL', KEYS[1])`
+)
+
+// RedisError is the error reply of the Redis server.
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "kv: redis: " + string(e)
+}
+
+type RedisConfig struct {
+	// Addr is the host:port address of the Redis server.
+	//
+	// Default: localhost:6379
+	Addr string `env:"ADDR" json:"addr,omitempty" yaml:"addr,omitempty"`
+
+	// Username is the ACL username, the "default" user is used if empty.
+	Username string `env:"USERNAME" json:"username,omitempty" yaml:"username,omitempty"`
+
+	// Password is the password of the AUTH command, if set.
+	Password string `env:"PASSWORD" json:"password,omitempty" yaml:"password,omitempty"`
+
+	// DB is the database selected on connect.
+	DB int `env:"DB" json:"db,omitempty" yaml:"db,omitempty"`
+
+	// DialTimeout is the timeout of establishing the connection.
+	//
+	// Default: 5s
+	DialTimeout time.Duration `env:"DIAL_TIMEOUT" json:"dialTimeout,omitempty,format:units" yaml:"dialTimeout,omitempty"`
+
+	// Timeout is the timeout of a command if the context has no deadline.
+	//
+	// Default: 3s
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// PoolSize is the maximum number of the idle connections kept.
+	//
+	// Default: 10
+	PoolSize int `env:"POOL_SIZE" json:"poolSize,omitempty" yaml:"poolSize,omitempty"`
+
+	// Dialer dials the connections, ex. the TLS ones with tls.Dialer.
+	//
+	// Default: net.Dialer with DialTimeout
+	Dialer func(ctx context.Context, network, addr string) (net.Conn, error) `json:"-" yaml:"-"`
+}
+
+func (c *RedisConfig) SetDefaults() {
+	if c.Addr == "" {
+		c.Addr = "localhost:6379"
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = 5 * time.Second
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 3 * time.Second
+	}
+	if c.PoolSize == 0 {
+		c.PoolSize = 10
+	}
+	if c.Dialer == nil {
+		c.Dialer = (&net.Dialer{Timeout: c.DialTimeout}).DialContext
+	}
+}
+
+func (c *RedisConfig) Validate() error {
+	if c.DB < 0 {
+		return errors.New("kv: redis db must not be negative")
+	}
+	if c.PoolSize < 0 {
+		return errors.New("kv: redis pool size must not be negative")
+	}
+	return nil
+}
+
+// RedisStore is the [Store] of the Redis server, speaking the RESP protocol over the pooled
+// connections. Close it when the store is no longer used.
+type RedisStore struct {
+	pool *pool
+}
+
+func NewRedisStore(cfg RedisConfig) *RedisStore {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return cfg.Dialer(ctx, "tcp", cfg.Addr)
+	}
+
+	init := func(c *conn) error {
+		if cfg.Password != "" {
+			args := []string{"AUTH", cfg.Password}
+			if cfg.Username != "" {
+				args = []string{"AUTH", cfg.Username, cfg.Password}
+			}
+			if _, err := redisDo(c, args...); err != nil {
+				return err
+			}
+		}
+		if cfg.DB > 0 {
+			if _, err := redisDo(c, "SELECT", strconv.Itoa(cfg.DB)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return &RedisStore{pool: newPool(cfg.PoolSize, cfg.Timeout, dial, init)}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := s.do(ctx, "GET", key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("kv: redis: unexpected GET reply %T", reply)
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", redisMillis(ttl))
+	}
+
+	_, err := s.do(ctx, args...)
+	return err
+}
+
+func (s *RedisStore) Add(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	args := []string{"SET", key, string(value), "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", redisMillis(ttl))
+	}
+
+	reply, err := s.do(ctx, args...)
+	return reply != nil, err
+}
+
+func (s *RedisStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	px := "0"
+	if ttl > 0 {
+		px = redisMillis(ttl)
+	}
+
+	reply, err := s.do(ctx, "EVAL", redisCompareAndSwap, "1", key, string(old), string(value), px)
+	if err != nil {
+		return false, err
+	}
+	n, err := redisInt(reply)
+	return n == 1, err
+}
+
+func (s *RedisStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	reply, err := s.do(ctx, "EVAL", redisCompareAndDelete, "1", key, string(old))
+	if err != nil {
+		return false, err
+	}
+	n, err := redisInt(reply)
+	return n == 1, err
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	_, err := s.do(ctx, "DEL", key)
+	return err
+}
+
+// Incr increments the key by INCRBY, the ttl of the created key is set in the same transaction.
+func (s *RedisStore) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	incr := []string{"INCRBY", key, strconv.FormatInt(delta, 10)}
+
+	if ttl <= 0 {
+		reply, err := s.do(ctx, incr...)
+		if err != nil {
+			return 0, redisIncrError(err)
+		}
+		return redisInt(reply)
+	}
+
+	replies, err := s.pipeline(ctx,
+		[]string{"MULTI"},
+		[]string{"SET", key, "0", "PX", redisMillis(ttl), "NX"},
+		incr,
+		[]string{"EXEC"},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	exec, ok := replies[3].([]any)
+	if !ok || len(exec) != 2 {
+		return 0, fmt.Errorf("kv: redis: unexpected EXEC reply %v", replies[3])
+	}
+	if err, ok = exec[1].(error); ok {
+		return 0, redisIncrError(err)
+	}
+	return redisInt(exec[1])
+}
+
+// Close closes the idle connections.
+func (s *RedisStore) Close() error {
+	return s.pool.close()
+}
+
+func (s *RedisStore) do(ctx context.Context, args ...string) (any, error) {
+	replies, err := s.pipeline(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return replies[0], nil
+}
+
+// pipeline sends the commands at once and reads their replies, the error replies of the
+// queued commands of the transaction are returned in the EXEC reply.
+func (s *RedisStore) pipeline(ctx context.Context, cmds ...[]string) ([]any, error) {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.pool.put(c)
+
+	for _, args := range cmds {
+		writeRedisCommand(c.w, args)
+	}
+	if err = c.w.Flush(); err != nil {
+		c.broken = true
+		return nil, err
+	}
+
+	replies := make([]any, len(cmds))
+	var replyErr error
+	for i := range cmds {
+		reply, err := readRedisReply(c)
+		if err != nil {
+			c.broken = true
+			return nil, err
+		}
+		if err, ok := reply.(RedisError); ok && replyErr == nil {
+			replyErr = err
+		}
+		replies[i] = reply
+	}
+	return replies, replyErr
+}
+
+func redisDo(c *conn, args ...string) (any, error) {
+	writeRedisCommand(c.w, args)
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	reply, err := readRedisReply(c)
+	if err != nil {
+		return nil, err
+	}
+	if err, ok := reply.(RedisError); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func writeRedisCommand(w io.Writer, args []string) {
+	_, _ = fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		_, _ = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+}
+
+// readRedisReply reads the RESP reply: the simple strings as string, the errors as RedisError,
+// the integers as int64, the bulk strings as []byte and the arrays as []any, the null ones as nil.
+func readRedisReply(c *conn) (any, error) {
+	line, err := readLine(c)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("kv: redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return RedisError(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		b := make([]byte, n+2)
+		if _, err = io.ReadFull(c.r, b); err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		arr := make([]any, n)
+		for i := range arr {
+			if arr[i], err = readRedisReply(c); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("kv: redis: unexpected reply %q", line)
+	}
+}
+
+// readLine reads the CRLF terminated line without the CRLF.
+func readLine(c *conn) ([]byte, error) {
+	line, err := c.r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(line[:len(line)-1], []byte{'\r'}), nil
+}
+
+func redisMillis(ttl time.Duration) string {
+	return strconv.FormatInt(max(1, ttl.Milliseconds()), 10)
+}
+
+func redisInt(reply any) (int64, error) {
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("kv: redis: unexpected integer reply %T", reply)
+	}
+	return n, nil
+}
+
+func redisIncrError(err error) error {
+	var redisErr RedisError
+	if errors.As(err, &redisErr) && strings.Contains(string(redisErr), "not an integer") {
+		return ErrNotInteger
+	}
+	return err
+}
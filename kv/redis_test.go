@@ -0,0 +1,297 @@
+package kv
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is the RESP server of the commands used by the RedisStore, the ttls are recorded
+// but not applied.
+type fakeRedis struct {
+	mu       sync.Mutex
+	data     map[string]string
+	ttls     map[string]string
+	commands []string
+}
+
+func newFakeRedis(t *testing.T) (*fakeRedis, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+
+	f := &fakeRedis{data: make(map[string]string), ttls: make(map[string]string)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return f, ln.Addr().String()
+}
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer func() {
+		_ = c.Close()
+	}()
+
+	r := bufio.NewReader(c)
+	var queued [][]string
+	multi := false
+
+	for {
+		args, err := readFakeRedisCommand(r)
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		f.mu.Unlock()
+
+		switch {
+		case args[0] == "MULTI":
+			multi = true
+			_, _ = io.WriteString(c, "+OK\r\n")
+		case args[0] == "EXEC":
+			reply := "*" + strconv.Itoa(len(queued)) + "\r\n"
+			for _, q := range queued {
+				reply += f.exec(q)
+			}
+			queued, multi = nil, false
+			_, _ = io.WriteString(c, reply)
+		case multi:
+			queued = append(queued, args)
+			_, _ = io.WriteString(c, "+QUEUED\r\n")
+		default:
+			_, _ = io.WriteString(c, f.exec(args))
+		}
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch args[0] {
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		v, ok := f.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+	case "SET":
+		if slices.Contains(args, "NX") {
+			if _, ok := f.data[args[1]]; ok {
+				return "$-1\r\n"
+			}
+		}
+		f.data[args[1]] = args[2]
+		delete(f.ttls, args[1])
+		if i := slices.Index(args, "PX"); i > 0 {
+			f.ttls[args[1]] = args[i+1]
+		}
+		return "+OK\r\n"
+	case "EVAL":
+		key := args[3]
+		if v, ok := f.data[key]; !ok || v != args[4] {
+			return ":0\r\n"
+		}
+		if args[1] == redisCompareAndDelete {
+			delete(f.data, key)
+			return ":1\r\n"
+		}
+		f.data[key] = args[5]
+		delete(f.ttls, key)
+		if args[6] != "0" {
+			f.ttls[key] = args[6]
+		}
+		return ":1\r\n"
+	case "DEL":
+		delete(f.data, args[1])
+		return ":1\r\n"
+	case "INCRBY":
+		n, err := strconv.ParseInt(f.data[args[1]], 10, 64)
+		if _, ok := f.data[args[1]]; ok && err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+		delta, _ := strconv.ParseInt(args[2], 10, 64)
+		n += delta
+		f.data[args[1]] = strconv.FormatInt(n, 10)
+		return ":" + strconv.FormatInt(n, 10) + "\r\n"
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+func readFakeRedisCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		args[i] = string(b[:size])
+	}
+	return args, nil
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	f, addr := newFakeRedis(t)
+
+	s := NewRedisStore(RedisConfig{Addr: addr, Username: "user", Password: "secret", DB: 2})
+	defer func() {
+		_ = s.Close()
+	}()
+
+	_, ok, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.Set(ctx, "key", []byte("value\r\n"), time.Minute))
+	value, ok, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value\r\n"), value)
+
+	require.NoError(t, s.Delete(ctx, "key"))
+	_, ok, err = s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assert.Equal(t, []string{
+		"AUTH user secret",
+		"SELECT 2",
+		"GET key",
+		"SET key value\r\n PX 60000",
+		"GET key",
+		"DEL key",
+		"GET key",
+	}, f.commands)
+}
+
+func TestRedisStore_Incr(t *testing.T) {
+	ctx := context.Background()
+	f, addr := newFakeRedis(t)
+
+	s := NewRedisStore(RedisConfig{Addr: addr})
+	defer func() {
+		_ = s.Close()
+	}()
+
+	n, err := s.Incr(ctx, "counter", 2, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	n, err = s.Incr(ctx, "counter", -3, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), n)
+
+	n, err = s.Incr(ctx, "forever", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	require.NoError(t, s.Set(ctx, "text", []byte("text"), 0))
+	_, err = s.Incr(ctx, "text", 1, 0)
+	assert.ErrorIs(t, err, ErrNotInteger)
+	_, err = s.Incr(ctx, "text", 1, time.Second)
+	assert.ErrorIs(t, err, ErrNotInteger)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	assert.Equal(t, map[string]string{"counter": "1000"}, f.ttls)
+}
+
+func TestRedisStore_Error(t *testing.T) {
+	ctx := context.Background()
+	_, addr := newFakeRedis(t)
+
+	s := NewRedisStore(RedisConfig{Addr: addr})
+	defer func() {
+		_ = s.Close()
+	}()
+
+	_, err := s.do(ctx, "UNKNOWN")
+	var redisErr RedisError
+	require.ErrorAs(t, err, &redisErr)
+	assert.Equal(t, RedisError("ERR unknown command"), redisErr)
+
+	// the connection is reused after the error reply
+	_, _, err = s.Get(ctx, "key")
+	assert.NoError(t, err)
+}
+
+func TestRedisStore_Atomic(t *testing.T) {
+	ctx := context.Background()
+	f, addr := newFakeRedis(t)
+
+	s := NewRedisStore(RedisConfig{Addr: addr})
+	defer func() {
+		_ = s.Close()
+	}()
+
+	added, err := s.Add(ctx, "key", []byte("a"), time.Second)
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	added, err = s.Add(ctx, "key", []byte("b"), time.Second)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	swapped, err := s.CompareAndSwap(ctx, "key", []byte("b"), []byte("c"), time.Second)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	swapped, err = s.CompareAndSwap(ctx, "key", []byte("a"), []byte("c"), 2*time.Second)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	f.mu.Lock()
+	assert.Equal(t, "c", f.data["key"])
+	assert.Equal(t, "2000", f.ttls["key"])
+	f.mu.Unlock()
+
+	deleted, err := s.CompareAndDelete(ctx, "key", []byte("a"))
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = s.CompareAndDelete(ctx, "key", []byte("c"))
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, ok, err := s.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
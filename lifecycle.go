@@ -0,0 +1,80 @@
+package wo
+
+import (
+	"github.com/gowool/hook"
+)
+
+// ErrorEvent is the event of the [Router.OnError] hook.
+type ErrorEvent[T Resolver] struct {
+	hook.Event
+
+	// RequestEvent is the request event which failed.
+	RequestEvent T
+
+	// Error is the error passed to the router error handler once the hook chain completes.
+	// Handlers may replace it before calling Next.
+	Error error
+}
+
+// PanicEvent is the event of the [Router.OnPanic] hook.
+type PanicEvent[T Resolver] struct {
+	hook.Event
+
+	// RequestEvent is the request event which panicked.
+	RequestEvent T
+
+	// Value is the value passed to panic.
+	Value any
+}
+
+// OnRequest returns the hook triggered at the start of each request, before the pre middlewares.
+// Handlers must call e.Next() to continue processing the request.
+func (r *Router[T]) OnRequest() *hook.Hook[T] {
+	return r.onRequest
+}
+
+// OnResponse returns the hook triggered right before the response status and headers are written.
+func (r *Router[T]) OnResponse() *hook.Hook[T] {
+	return r.onResponse
+}
+
+// OnComplete returns the hook triggered after the request has been handled, including errors.
+func (r *Router[T]) OnComplete() *hook.Hook[T] {
+	return r.onComplete
+}
+
+// OnError returns the hook triggered when the request handling returns an error.
+// The router error handler is executed at the end of the hook chain.
+func (r *Router[T]) OnError() *hook.Hook[*ErrorEvent[T]] {
+	return r.onError
+}
+
+// OnPanic returns the hook triggered when the request handling panics.
+// The panic is propagated once the hook chain completes.
+func (r *Router[T]) OnPanic() *hook.Hook[*PanicEvent[T]] {
+	return r.onPanic
+}
+
+func (r *Router[T]) handleError(event T, err error) {
+	if r.onError.Length() == 0 {
+		if r.errorHandler != nil {
+			r.errorHandler(event, err)
+		}
+		return
+	}
+
+	_ = r.onError.Trigger(&ErrorEvent[T]{RequestEvent: event, Error: err}, func(e *ErrorEvent[T]) error {
+		if e.Error != nil && r.errorHandler != nil {
+			r.errorHandler(e.RequestEvent, e.Error)
+		}
+		return nil
+	})
+}
+
+func (r *Router[T]) handlePanic(event T, value any) {
+	if r.onPanic.Length() == 0 {
+		return
+	}
+
+	_ = r.onPanic.Trigger(&PanicEvent[T]{RequestEvent: event, Value: value})
+}
@@ -0,0 +1,145 @@
+package wo
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterLifecycleHooks_Order(t *testing.T) {
+	var calls []string
+
+	router := New[*Event](eventFactory, func(e *Event, err error) {
+		calls = append(calls, "errorHandler:"+err.Error())
+	})
+
+	router.OnRequest().BindFunc(func(e *Event) error {
+		calls = append(calls, "request")
+		return e.Next()
+	})
+	router.OnResponse().BindFunc(func(e *Event) error {
+		calls = append(calls, "response")
+		return e.Next()
+	})
+	router.OnComplete().BindFunc(func(e *Event) error {
+		calls = append(calls, "complete")
+		return e.Next()
+	})
+	router.OnError().BindFunc(func(e *ErrorEvent[*Event]) error {
+		calls = append(calls, "error")
+		return e.Next()
+	})
+	router.PreFunc(func(e *Event) error {
+		calls = append(calls, "pre")
+		return e.Next()
+	})
+
+	router.GET("/ok", func(e *Event) error {
+		calls = append(calls, "action")
+		return e.String(http.StatusOK, "ok")
+	})
+	router.GET("/fail", func(e *Event) error {
+		return errors.New("failed")
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, []string{"request", "pre", "action", "response", "complete"}, calls)
+
+	calls = nil
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, []string{"request", "pre", "error", "errorHandler:failed", "complete"}, calls)
+}
+
+func TestRouterOnRequest_ShortCircuit(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+
+	router.OnRequest().Bind(&hook.Handler[*Event]{
+		ID: "maintenance",
+		Func: func(e *Event) error {
+			return e.String(http.StatusServiceUnavailable, "maintenance")
+		},
+	})
+	router.GET("/", func(e *Event) error {
+		t.Fatal("action must not be called")
+		return nil
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestRouterOnError_ReplaceError(t *testing.T) {
+	var handled error
+
+	router := New[*Event](eventFactory, func(e *Event, err error) {
+		handled = err
+	})
+	router.OnError().BindFunc(func(e *ErrorEvent[*Event]) error {
+		e.Error = ErrNotFound
+		return e.Next()
+	})
+	router.GET("/", func(e *Event) error {
+		return errors.New("missing")
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, ErrNotFound, handled)
+}
+
+func TestRouterOnPanic(t *testing.T) {
+	var recovered any
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.OnPanic().BindFunc(func(e *PanicEvent[*Event]) error {
+		recovered = e.Value
+		assert.NotNil(t, e.RequestEvent)
+		return e.Next()
+	})
+	router.GET("/", func(e *Event) error {
+		panic("boom")
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.Equal(t, "boom", recovered)
+}
+
+func TestRouterOnPanic_IgnoresAbortHandler(t *testing.T) {
+	called := false
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.OnPanic().BindFunc(func(e *PanicEvent[*Event]) error {
+		called = true
+		return e.Next()
+	})
+	router.GET("/", func(e *Event) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	assert.Panics(t, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	assert.False(t, called)
+}
@@ -0,0 +1,114 @@
+package wo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The common link relations, see https://www.iana.org/assignments/link-relations.
+const (
+	RelSelf    = "self"
+	RelNext    = "next"
+	RelPrev    = "prev"
+	RelFirst   = "first"
+	RelLast    = "last"
+	RelRelated = "related"
+)
+
+// Link is the RFC 8288 web link.
+type Link struct {
+	URL string
+	Rel string
+
+	// Params are the target attributes as name and value pairs, ex. "title", "Next page".
+	Params []string
+}
+
+// String returns the link in the Link header format, ex. `</users?page=2>; rel="next"`.
+func (l Link) String() string {
+	var b strings.Builder
+	b.WriteByte('<')
+	b.WriteString(l.URL)
+	b.WriteString(">; rel=")
+	b.WriteString(strconv.Quote(l.Rel))
+	for i := 0; i+1 < len(l.Params); i += 2 {
+		b.WriteString("; ")
+		b.WriteString(l.Params[i])
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l.Params[i+1]))
+	}
+	return b.String()
+}
+
+// Links accumulates the links of the response, which are sent in the Link header
+// just before the response is written, see [Event.Links].
+type Links struct {
+	event *Event
+	links []Link
+}
+
+// Add adds the link of the relation, the params are the target attributes as name and value pairs.
+func (l *Links) Add(rel, url string, params ...string) *Links {
+	l.links = append(l.links, Link{URL: url, Rel: rel, Params: params})
+	return l
+}
+
+// Route adds the link of the relation to the route of the name (see [Event.URL]), the query
+// is appended to the route path unless empty, ex. "page=2".
+func (l *Links) Route(rel, name, query string, params ...string) error {
+	u, err := l.event.URL(name, params...)
+	if err != nil {
+		return err
+	}
+	if query != "" {
+		u += "?" + query
+	}
+	l.Add(rel, u)
+	return nil
+}
+
+func (l *Links) Self(url string) *Links {
+	return l.Add(RelSelf, url)
+}
+
+func (l *Links) Next(url string) *Links {
+	return l.Add(RelNext, url)
+}
+
+func (l *Links) Prev(url string) *Links {
+	return l.Add(RelPrev, url)
+}
+
+func (l *Links) Related(url string) *Links {
+	return l.Add(RelRelated, url)
+}
+
+// All returns the accumulated links.
+func (l *Links) All() []Link {
+	return l.links
+}
+
+// Len returns the number of the accumulated links.
+func (l *Links) Len() int {
+	return len(l.links)
+}
+
+// Links returns the links of the response, they are sent in the Link header just before
+// the response is written:
+//
+//	e.Links().Self("/users?page=2").Prev("/users?page=1").Next("/users?page=3")
+//	_ = e.Links().Route(wo.RelRelated, "user", "", "id", "42")
+func (e *Event) Links() *Links {
+	if e.links == nil {
+		e.links = &Links{event: e}
+		if res, err := UnwrapResponse(e.response); err == nil {
+			links := e.links
+			res.Before(func() {
+				for _, link := range links.links {
+					res.Header().Add(HeaderLink, link.String())
+				}
+			})
+		}
+	}
+	return e.links
+}
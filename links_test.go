@@ -0,0 +1,56 @@
+package wo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLink_String(t *testing.T) {
+	tests := []struct {
+		link     Link
+		expected string
+	}{
+		{Link{URL: "/users?page=2", Rel: RelNext}, `</users?page=2>; rel="next"`},
+		{Link{URL: "/docs", Rel: "help", Params: []string{"title", `The "docs"`}}, `</docs>; rel="help"; title="The \"docs\""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.link.String())
+		})
+	}
+}
+
+func TestEvent_Links(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/users", func(e *Event) error {
+		e.Links().Self("/users?page=2").Prev("/users?page=1").Next("/users?page=3")
+		if err := e.Links().Route(RelRelated, "user", "expand=posts", "id", "42"); err != nil {
+			return err
+		}
+		return e.NoContent(http.StatusOK)
+	})
+	router.GET("/users/{id}", func(e *Event) error { return nil }).Named("user")
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	assert.Equal(t, []string{
+		`</users?page=2>; rel="self"`,
+		`</users?page=1>; rel="prev"`,
+		`</users?page=3>; rel="next"`,
+		`</users/42?expand=posts>; rel="related"`,
+	}, rec.Header().Values(HeaderLink))
+
+	event := new(Event)
+	event.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.ErrorIs(t, event.Links().Route(RelRelated, "user", ""), ErrRouteNotFound)
+	assert.Equal(t, 0, event.Links().Len())
+}
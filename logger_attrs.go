@@ -17,6 +17,10 @@ func RequestLoggerAttrs[T Resolver](e T, status int, err error) []slog.Attr {
 	}
 
 	n := 11
+	bytesIn, hasBytesIn := any(e).(interface{ BytesIn() int64 })
+	if hasBytesIn {
+		n++
+	}
 	if err != nil {
 		n++
 	}
@@ -40,6 +44,10 @@ func RequestLoggerAttrs[T Resolver](e T, status int, err error) []slog.Attr {
 		slog.Int64("response_size", MustUnwrapResponse(res).Size),
 	)
 
+	if hasBytesIn {
+		attributes = append(attributes, slog.Int64("request_size", bytesIn.BytesIn()))
+	}
+
 	if id != "" {
 		attributes = append(attributes, slog.String("request_id", id))
 	}
@@ -0,0 +1,244 @@
+// Package longpoll provides the long-polling for the clients which can't use the streaming
+// responses. The events are published to the topics, each topic buffers the recent ones, and
+// the clients poll with the cursor of the last event they have seen:
+//
+//	broker := longpoll.New(longpoll.Config{})
+//
+//	router.BindFunc(middleware.Poller[*wo.Event](broker))
+//	router.GET("/orders/events", func(e *wo.Event) error {
+//		since, _ := strconv.ParseUint(e.QueryParam("since"), 10, 64)
+//		return e.LongPoll("orders", since, 30*time.Second)
+//	})
+//
+//	broker.Publish("orders", order)
+package longpoll
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo"
+)
+
+var _ wo.Poller = (*Broker)(nil)
+
+type Config struct {
+	// BufferSize is the number of the recent events buffered per topic, the clients lagging
+	// behind more than that miss the older events, see [Response.Missed].
+	//
+	// Default: 128
+	BufferSize int `env:"BUFFER_SIZE" json:"bufferSize,omitempty" yaml:"bufferSize,omitempty"`
+
+	// DefaultTimeout is the poll timeout used when the requested one isn't positive.
+	//
+	// Default: 30s
+	DefaultTimeout time.Duration `env:"DEFAULT_TIMEOUT" json:"defaultTimeout,omitempty,format:units" yaml:"defaultTimeout,omitempty"`
+
+	// MaxTimeout caps the requested poll timeout, it should stay below the write timeout of
+	// the server and the idle timeouts of the proxies.
+	//
+	// Default: 1m
+	MaxTimeout time.Duration `env:"MAX_TIMEOUT" json:"maxTimeout,omitempty,format:units" yaml:"maxTimeout,omitempty"`
+
+	// TimeFunc returns the time of the published events.
+	//
+	// Default: time.Now
+	TimeFunc func() time.Time `json:"-" yaml:"-"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.BufferSize == 0 {
+		c.BufferSize = 128
+	}
+	if c.DefaultTimeout == 0 {
+		c.DefaultTimeout = 30 * time.Second
+	}
+	if c.MaxTimeout == 0 {
+		c.MaxTimeout = time.Minute
+	}
+	if c.TimeFunc == nil {
+		c.TimeFunc = time.Now
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.BufferSize < 1 {
+		return errors.New("longpoll: buffer size must be at least 1")
+	}
+	if c.DefaultTimeout < 0 || c.MaxTimeout < c.DefaultTimeout {
+		return errors.New("longpoll: invalid timeouts")
+	}
+	return nil
+}
+
+// Message is the published event.
+type Message struct {
+	// Cursor is the sequence number of the event within the topic, starting at 1.
+	Cursor uint64    `json:"cursor"`
+	Time   time.Time `json:"time"`
+	Data   any       `json:"data"`
+}
+
+// Response is the result of a poll.
+type Response struct {
+	Topic string `json:"topic"`
+
+	// Cursor is the cursor of the next poll, the one of the last returned event or the
+	// requested one when there are no events.
+	Cursor uint64 `json:"cursor"`
+
+	// Events are the events after the requested cursor, empty when the poll timed out.
+	Events []Message `json:"events"`
+
+	// Missed reports whether some events after the requested cursor were dropped from the
+	// buffer, or the cursor is unknown, ex. after the restart, so the client should resync.
+	Missed bool `json:"missed,omitempty"`
+}
+
+type topic struct {
+	seq    uint64
+	buf    []Message
+	notify chan struct{}
+}
+
+// after returns the response with the buffered events after the cursor since.
+func (t *topic) after(name string, since uint64) Response {
+	res := Response{Topic: name, Cursor: since}
+
+	if since > t.seq {
+		// the cursor of another topic generation, the client starts over
+		res.Cursor, res.Missed = 0, true
+		since = 0
+	}
+	if since == t.seq {
+		return res
+	}
+
+	first := t.buf[0].Cursor
+	if since+1 < first {
+		res.Missed = true
+		since = first - 1
+	}
+
+	res.Events = append([]Message(nil), t.buf[since+1-first:]...)
+	res.Cursor = t.seq
+	return res
+}
+
+// Broker is the topic-based publish-subscribe manager serving the long polls, it's safe for
+// the concurrent use. The topics are created on the first publish and live until [Broker.Remove],
+// the polls don't create them.
+type Broker struct {
+	cfg     Config
+	mu      sync.Mutex
+	topics  map[string]*topic
+	created chan struct{} // closed when a topic is created
+}
+
+// New returns the broker, it panics if the config is invalid.
+func New(cfg Config) *Broker {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &Broker{cfg: cfg, topics: make(map[string]*topic), created: make(chan struct{})}
+}
+
+// Publish appends the event to the topic, wakes up the waiting polls and returns the cursor
+// of the event.
+func (b *Broker) Publish(name string, data any) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topic(name)
+	t.seq++
+	if len(t.buf) == b.cfg.BufferSize {
+		t.buf[0] = Message{}
+		t.buf = t.buf[1:]
+	}
+	t.buf = append(t.buf, Message{Cursor: t.seq, Time: b.cfg.TimeFunc(), Data: data})
+
+	close(t.notify)
+	t.notify = make(chan struct{})
+
+	return t.seq
+}
+
+// Cursor returns the cursor of the last event of the topic, ex. for the clients which want
+// the events published from now on.
+func (b *Broker) Cursor(name string) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if t, ok := b.topics[name]; ok {
+		return t.seq
+	}
+	return 0
+}
+
+// Remove drops the topic along with its buffered events, the waiting polls time out.
+func (b *Broker) Remove(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.topics, name)
+}
+
+// Wait waits until the topic has the events after the cursor since, the timeout elapses or
+// ctx is done. The timeout isn't an error, the response has no events then.
+func (b *Broker) Wait(ctx context.Context, name string, since uint64, timeout time.Duration) (Response, error) {
+	if timeout <= 0 {
+		timeout = b.cfg.DefaultTimeout
+	}
+	timer := time.NewTimer(min(timeout, b.cfg.MaxTimeout))
+	defer timer.Stop()
+
+	for {
+		b.mu.Lock()
+		var (
+			res    Response
+			notify chan struct{}
+		)
+		if t, ok := b.topics[name]; ok {
+			res, notify = t.after(name, since), t.notify
+		} else {
+			// the unknown topic is waited for to be created by the publish
+			res, notify = new(topic).after(name, since), b.created
+		}
+		b.mu.Unlock()
+
+		if len(res.Events) > 0 {
+			return res, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return Response{}, ctx.Err()
+		case <-timer.C:
+			res.Events = []Message{}
+			return res, nil
+		case <-notify:
+		}
+	}
+}
+
+// Poll implements [wo.Poller], see [Broker.Wait].
+func (b *Broker) Poll(ctx context.Context, name string, since uint64, timeout time.Duration) (any, error) {
+	return b.Wait(ctx, name, since, timeout)
+}
+
+func (b *Broker) topic(name string) *topic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{buf: make([]Message, 0, b.cfg.BufferSize), notify: make(chan struct{})}
+		b.topics[name] = t
+
+		close(b.created)
+		b.created = make(chan struct{})
+	}
+	return t
+}
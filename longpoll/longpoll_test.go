@@ -0,0 +1,177 @@
+package longpoll
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/middleware"
+)
+
+func cursors(res Response) []uint64 {
+	out := make([]uint64, 0, len(res.Events))
+	for _, m := range res.Events {
+		out = append(out, m.Cursor)
+	}
+	return out
+}
+
+func TestBroker_Wait(t *testing.T) {
+	b := New(Config{BufferSize: 3})
+	for i := range 5 {
+		assert.Equal(t, uint64(i+1), b.Publish("orders", i))
+	}
+
+	tests := []struct {
+		name    string
+		since   uint64
+		cursor  uint64
+		cursors []uint64
+		missed  bool
+	}{
+		{name: "buffered", since: 3, cursor: 5, cursors: []uint64{4, 5}},
+		{name: "oldest buffered", since: 2, cursor: 5, cursors: []uint64{3, 4, 5}},
+		{name: "missed", since: 1, cursor: 5, cursors: []uint64{3, 4, 5}, missed: true},
+		{name: "from the beginning", since: 0, cursor: 5, cursors: []uint64{3, 4, 5}, missed: true},
+		{name: "unknown cursor", since: 10, cursor: 5, cursors: []uint64{3, 4, 5}, missed: true},
+		{name: "up to date", since: 5, cursor: 5, cursors: []uint64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := b.Wait(context.Background(), "orders", tt.since, time.Millisecond)
+			require.NoError(t, err)
+
+			assert.Equal(t, "orders", res.Topic)
+			assert.Equal(t, tt.cursor, res.Cursor)
+			assert.Equal(t, tt.cursors, cursors(res))
+			assert.Equal(t, tt.missed, res.Missed)
+		})
+	}
+}
+
+func TestBroker_WaitPublish(t *testing.T) {
+	b := New(Config{})
+	since := b.Cursor("orders")
+
+	done := make(chan Response, 1)
+	go func() {
+		res, err := b.Wait(context.Background(), "orders", since, time.Minute)
+		assert.NoError(t, err)
+		done <- res
+	}()
+
+	// the unrelated topic doesn't wake up the poll
+	b.Publish("users", "created")
+	time.Sleep(10 * time.Millisecond)
+	b.Publish("orders", "paid")
+
+	select {
+	case res := <-done:
+		assert.Equal(t, uint64(1), res.Cursor)
+		require.Len(t, res.Events, 1)
+		assert.Equal(t, "paid", res.Events[0].Data)
+	case <-time.After(time.Second):
+		t.Fatal("the poll was not woken up")
+	}
+}
+
+func TestBroker_WaitCanceled(t *testing.T) {
+	b := New(Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := b.Wait(ctx, "orders", 0, time.Minute)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBroker_WaitMaxTimeout(t *testing.T) {
+	b := New(Config{DefaultTimeout: time.Millisecond, MaxTimeout: 20 * time.Millisecond})
+
+	start := time.Now()
+	res, err := b.Wait(context.Background(), "orders", 0, time.Hour)
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.NotNil(t, res.Events)
+	assert.Empty(t, res.Events)
+}
+
+func TestBroker_WaitUnknownTopic(t *testing.T) {
+	b := New(Config{})
+
+	res, err := b.Wait(context.Background(), "orders", 3, time.Millisecond)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(0), res.Cursor)
+	assert.True(t, res.Missed)
+	assert.Empty(t, res.Events)
+	assert.Empty(t, b.topics)
+}
+
+func TestBroker_Remove(t *testing.T) {
+	b := New(Config{})
+	b.Publish("orders", 1)
+	b.Remove("orders")
+
+	assert.Equal(t, uint64(0), b.Cursor("orders"))
+	assert.Equal(t, uint64(1), b.Publish("orders", 2))
+}
+
+func TestBroker_LongPoll(t *testing.T) {
+	b := New(Config{})
+	b.Publish("orders", map[string]any{"id": 1})
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, func(e *wo.Event, err error) {
+		e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+	})
+	router.BindFunc(middleware.Poller[*wo.Event](b))
+	router.GET("/orders/events", func(e *wo.Event) error {
+		since, _ := strconv.ParseUint(e.QueryParam("since"), 10, 64)
+		return e.LongPoll("orders", since, 10*time.Millisecond)
+	})
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/events?since=0", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var res struct {
+		Topic  string `json:"topic"`
+		Cursor uint64 `json:"cursor"`
+		Events []struct {
+			Cursor uint64         `json:"cursor"`
+			Data   map[string]any `json:"data"`
+		} `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, "orders", res.Topic)
+	assert.Equal(t, uint64(1), res.Cursor)
+	require.Len(t, res.Events, 1)
+	assert.Equal(t, float64(1), res.Events[0].Data["id"])
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders/events?since=1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"topic":"orders","cursor":1,"events":[]}`, rec.Body.String())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.Panics(t, func() { New(Config{BufferSize: -1}) })
+	assert.Panics(t, func() { New(Config{DefaultTimeout: time.Minute, MaxTimeout: time.Second}) })
+	assert.NotPanics(t, func() { New(Config{}) })
+}
@@ -0,0 +1,500 @@
+package wo
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Matcher matches the requests to the handlers of the route patterns, [http.ServeMux]
+// is the default one, see [Router.SetMatcher].
+type Matcher interface {
+	http.Handler
+
+	// Handle registers the handler of the pattern, it panics if the pattern is invalid
+	// or conflicts with an already registered one.
+	Handle(pattern string, handler http.Handler)
+}
+
+// SetMatcher sets the constructor of the matcher the routes are registered in by [Router.Build]
+// when no mux is given, ex. the radix tree matcher:
+//
+//	r.SetMatcher(func() wo.Matcher { return wo.NewRadixMatcher() })
+func (r *Router[T]) SetMatcher(newMatcher func() Matcher) {
+	r.newMatcher = newMatcher
+}
+
+func newServeMux() Matcher {
+	return http.NewServeMux()
+}
+
+// RadixMatcher is the [Matcher] keeping the routes in a tree of the path segments. It isn't faster
+// than [http.ServeMux], which matches with a tree too, and setting the path values with
+// [http.Request.SetPathValue] allocates, see BenchmarkRouter_LargeTable.
+//
+// The patterns have the [http.ServeMux] syntax "[METHOD ][HOST]/[PATH]", with the wildcards
+// "{name}" matching a path segment, "{name...}" and the trailing slash matching the rest of the path
+// and "{$}" matching the end of the path after the trailing slash. Like in [http.ServeMux], the patterns
+// conflict if they match the same requests, or some requests while none is more specific, ex. "/a/{x}"
+// and "/{y}/b". Each path segment is matched by priority: the static segment first, then the wildcard,
+// then the rest of the path, so the most specific pattern wins.
+//
+// Like [http.ServeMux], the requests of the unclean paths, ex. "/a/../b" or "/a//b", are redirected
+// to the clean ones. The requests with the escaped dot segments, ex. "/a/%2e%2e/b", are rejected with
+// 400 Bad Request, so the wildcards never match "." or "..".
+//
+// The GET routes match the HEAD requests, the requests matching the path of some routes but none
+// of their methods are answered with 405 Method Not Allowed, and the requests without the trailing
+// slash matching only the routes with it are redirected.
+type RadixMatcher struct {
+	hosts  map[string]*radixNode
+	routes map[string][]*radixRoute // the routes per host, to detect the conflicts
+}
+
+// NewRadixMatcher returns the empty radix tree matcher.
+func NewRadixMatcher() *RadixMatcher {
+	return &RadixMatcher{hosts: make(map[string]*radixNode), routes: make(map[string][]*radixRoute)}
+}
+
+type radixNode struct {
+	static   map[string]*radixNode
+	param    *radixNode
+	routes   radixRoutes // the path ends at the node
+	catchAll radixRoutes // the rest of the path
+}
+
+// radixRoutes are the routes of the same path keyed by method, "" stands for any method.
+type radixRoutes map[string]*radixRoute
+
+type radixRoute struct {
+	pattern  string
+	handler  http.Handler
+	method   string
+	segments []radixSegment
+}
+
+// Handle registers the handler of the pattern, see [RadixMatcher] for the pattern syntax.
+// It panics if the pattern is invalid or conflicts with an already registered one.
+func (m *RadixMatcher) Handle(pattern string, handler http.Handler) {
+	if handler == nil {
+		panic(fmt.Sprintf("wo: nil handler of the pattern %q", pattern))
+	}
+
+	method, host, segments, err := parseRadixPattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("wo: parsing %q: %v", pattern, err))
+	}
+
+	route := &radixRoute{pattern: pattern, handler: handler, method: method, segments: segments}
+	for _, other := range m.routes[host] {
+		switch route.compare(other) {
+		case radixEquivalent:
+			panic(fmt.Sprintf("wo: pattern %q conflicts with pattern %q: both match the same requests", pattern, other.pattern))
+		case radixOverlaps:
+			panic(fmt.Sprintf("wo: pattern %q conflicts with pattern %q: both match some requests, neither is more specific", pattern, other.pattern))
+		default:
+		}
+	}
+	m.routes[host] = append(m.routes[host], route)
+
+	root, ok := m.hosts[host]
+	if !ok {
+		root = new(radixNode)
+		m.hosts[host] = root
+	}
+
+	n := root
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch {
+		case last && seg.rest:
+			n.catchAll = n.catchAll.add(method, route)
+			return
+		case seg.wildcard:
+			if n.param == nil {
+				n.param = new(radixNode)
+			}
+			n = n.param
+		default:
+			child, ok := n.static[seg.name]
+			if !ok {
+				if n.static == nil {
+					n.static = make(map[string]*radixNode)
+				}
+				child = new(radixNode)
+				n.static[seg.name] = child
+			}
+			n = child
+		}
+	}
+	n.routes = n.routes.add(method, route)
+}
+
+// HandleFunc registers the handler function of the pattern, see [RadixMatcher.Handle].
+func (m *RadixMatcher) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+func (m *RadixMatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+
+	// the CONNECT requests are not canonicalized, see http.ServeMux
+	if r.Method != http.MethodConnect && strings.HasPrefix(path, "/") {
+		if clean := cleanPath(path); clean != path {
+			target := clean
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			status := http.StatusPermanentRedirect
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+			http.Redirect(w, r, target, status)
+			return
+		}
+		if hasDotSegment(r.URL.Path) {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
+	route, allowed := m.match(r.Host, r.Method, path)
+	if route == nil {
+		if len(allowed) > 0 {
+			slices.Sort(allowed)
+			w.Header().Set(HeaderAllow, strings.Join(slices.Compact(allowed), ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if !strings.HasSuffix(path, "/") {
+			if route, _ = m.match(r.Host, r.Method, path+"/"); route != nil {
+				target := path + "/"
+				if r.URL.RawQuery != "" {
+					target += "?" + r.URL.RawQuery
+				}
+				http.Redirect(w, r, target, http.StatusMovedPermanently)
+				return
+			}
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	r.Pattern = route.pattern
+	route.setPathValues(r, path)
+	route.handler.ServeHTTP(w, r)
+}
+
+func (m *RadixMatcher) match(host, method, path string) (*radixRoute, []string) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, nil
+	}
+
+	var allowed []string
+	if host = stripHostPort(host); host != "" {
+		if root, ok := m.hosts[host]; ok {
+			if route := root.match(method, path[1:], &allowed); route != nil {
+				return route, nil
+			}
+		}
+	}
+	if root, ok := m.hosts[""]; ok {
+		if route := root.match(method, path[1:], &allowed); route != nil {
+			return route, nil
+		}
+	}
+	return nil, allowed
+}
+
+// match matches the path following a slash, the static segments take priority over
+// the wildcards and the wildcards over the rest of the path. The path values aren't
+// collected while backtracking, they are set from the path once the route is matched.
+func (n *radixNode) match(method, path string, allowed *[]string) *radixRoute {
+	seg, rest, more := strings.Cut(path, "/")
+
+	if child, ok := n.static[unescapeSegment(seg)]; ok {
+		if route := child.next(method, rest, more, allowed); route != nil {
+			return route
+		}
+	}
+	if n.param != nil && seg != "" {
+		if route := n.param.next(method, rest, more, allowed); route != nil {
+			return route
+		}
+	}
+	if n.catchAll != nil {
+		return n.catchAll.match(method, allowed)
+	}
+	return nil
+}
+
+func (n *radixNode) next(method, rest string, more bool, allowed *[]string) *radixRoute {
+	if more {
+		return n.match(method, rest, allowed)
+	}
+	return n.routes.match(method, allowed)
+}
+
+// setPathValues sets the values of the wildcards of the matched path.
+func (route *radixRoute) setPathValues(r *http.Request, path string) {
+	rest := path[1:]
+	for _, seg := range route.segments {
+		value, tail, _ := strings.Cut(rest, "/")
+		switch {
+		case seg.rest:
+			if seg.name != "" {
+				r.SetPathValue(seg.name, unescapeSegment(rest))
+			}
+			return
+		case seg.wildcard:
+			r.SetPathValue(seg.name, unescapeSegment(value))
+		}
+		rest = tail
+	}
+}
+
+func (routes radixRoutes) add(method string, route *radixRoute) radixRoutes {
+	if other, ok := routes[method]; ok {
+		panic(fmt.Sprintf("wo: pattern %q conflicts with pattern %q: both match the same requests", route.pattern, other.pattern))
+	}
+	if routes == nil {
+		routes = make(radixRoutes)
+	}
+	routes[method] = route
+	return routes
+}
+
+func (routes radixRoutes) match(method string, allowed *[]string) *radixRoute {
+	if len(routes) == 0 {
+		return nil
+	}
+	if route, ok := routes[method]; ok {
+		return route
+	}
+	if method == http.MethodHead {
+		if route, ok := routes[http.MethodGet]; ok {
+			return route
+		}
+	}
+	if route, ok := routes[""]; ok {
+		return route
+	}
+
+	for m := range routes {
+		*allowed = append(*allowed, m)
+		if m == http.MethodGet {
+			*allowed = append(*allowed, http.MethodHead)
+		}
+	}
+	return nil
+}
+
+// radixRelation is the relation of the sets of the requests two patterns match.
+type radixRelation int
+
+const (
+	radixEquivalent   radixRelation = iota // the same requests
+	radixMoreGeneral                       // a superset
+	radixMoreSpecific                      // a subset
+	radixDisjoint                          // no common requests
+	radixOverlaps                          // some common requests, neither is a subset
+)
+
+func (r radixRelation) inverse() radixRelation {
+	switch r {
+	case radixMoreGeneral:
+		return radixMoreSpecific
+	case radixMoreSpecific:
+		return radixMoreGeneral
+	default:
+		return r
+	}
+}
+
+// combine returns the relation of the patterns from the relations of their parts, see http.ServeMux.
+func (r radixRelation) combine(other radixRelation) radixRelation {
+	switch r {
+	case radixEquivalent:
+		return other
+	case radixDisjoint:
+		return radixDisjoint
+	case radixOverlaps:
+		if other == radixDisjoint {
+			return radixDisjoint
+		}
+		return radixOverlaps
+	default:
+		switch other {
+		case radixEquivalent:
+			return r
+		case r.inverse():
+			return radixOverlaps
+		default:
+			return other
+		}
+	}
+}
+
+// compare returns the relation of the requests the routes of the same host match.
+func (route *radixRoute) compare(other *radixRoute) radixRelation {
+	var rel radixRelation
+	switch {
+	case route.method == other.method:
+		rel = radixEquivalent
+	case route.method == "" || (route.method == http.MethodGet && other.method == http.MethodHead):
+		rel = radixMoreGeneral
+	case other.method == "" || (route.method == http.MethodHead && other.method == http.MethodGet):
+		rel = radixMoreSpecific
+	default:
+		return radixDisjoint
+	}
+
+	s1, s2 := route.segments, other.segments
+	for len(s1) > 0 && len(s2) > 0 {
+		if rel = rel.combine(s1[0].compare(s2[0])); rel == radixDisjoint {
+			return rel
+		}
+		s1, s2 = s1[1:], s2[1:]
+	}
+	switch {
+	case len(s1) == 0 && len(s2) == 0:
+		return rel
+	case len(s1) == 0 && route.segments[len(route.segments)-1].rest:
+		return rel.combine(radixMoreGeneral)
+	case len(s2) == 0 && other.segments[len(other.segments)-1].rest:
+		return rel.combine(radixMoreSpecific)
+	default:
+		return radixDisjoint
+	}
+}
+
+type radixSegment struct {
+	name     string
+	wildcard bool
+	rest     bool
+}
+
+func parseRadixPattern(pattern string) (method, host string, segments []radixSegment, err error) {
+	rest := strings.TrimLeft(pattern, " \t")
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		method, rest = rest[:i], strings.TrimLeft(rest[i+1:], " \t")
+	}
+
+	i := strings.IndexByte(rest, '/')
+	if i < 0 {
+		return "", "", nil, fmt.Errorf("host/path missing /")
+	}
+	host, rest = rest[:i], rest[i+1:]
+
+	seen := make(map[string]struct{})
+	for {
+		seg, tail, more := strings.Cut(rest, "/")
+
+		switch {
+		case seg == "" && !more:
+			// the trailing slash matches the rest of the path
+			return method, host, append(segments, radixSegment{rest: true}), nil
+		case seg == "{$}":
+			if more {
+				return "", "", nil, fmt.Errorf("{$} not at the end")
+			}
+			return method, host, append(segments, radixSegment{}), nil
+		case strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}"):
+			name, isRest := strings.CutSuffix(seg[1:len(seg)-1], "...")
+			if !isIdentifier(name) {
+				return "", "", nil, fmt.Errorf("bad wildcard name %q", name)
+			}
+			if _, ok := seen[name]; ok {
+				return "", "", nil, fmt.Errorf("duplicate wildcard name %q", name)
+			}
+			seen[name] = struct{}{}
+			if isRest && more {
+				return "", "", nil, fmt.Errorf("%s wildcard not at the end", seg)
+			}
+			segments = append(segments, radixSegment{name: name, wildcard: !isRest, rest: isRest})
+		case strings.ContainsAny(seg, "{}"):
+			return "", "", nil, fmt.Errorf("bad wildcard segment %q", seg)
+		default:
+			name, err := url.PathUnescape(seg)
+			if err != nil {
+				return "", "", nil, err
+			}
+			segments = append(segments, radixSegment{name: name})
+		}
+
+		if !more {
+			return method, host, segments, nil
+		}
+		rest = tail
+	}
+}
+
+// compare returns the relation of the path segments the segments match, the static
+// segment without the name is the end of the path after the trailing slash.
+func (s radixSegment) compare(other radixSegment) radixRelation {
+	switch {
+	case s.rest && other.rest:
+		return radixEquivalent
+	case s.rest:
+		return radixMoreGeneral
+	case other.rest:
+		return radixMoreSpecific
+	case s.wildcard && other.wildcard:
+		return radixEquivalent
+	case s.wildcard:
+		if other.name == "" {
+			return radixDisjoint
+		}
+		return radixMoreGeneral
+	case other.wildcard:
+		if s.name == "" {
+			return radixDisjoint
+		}
+		return radixMoreSpecific
+	case s.name == other.name:
+		return radixEquivalent
+	default:
+		return radixDisjoint
+	}
+}
+
+// hasDotSegment reports whether the path has the "." or ".." segment.
+func hasDotSegment(p string) bool {
+	for seg := range strings.SplitSeq(p, "/") {
+		if seg == "." || seg == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c != '_' && !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func unescapeSegment(s string) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+	if v, err := url.PathUnescape(s); err == nil {
+		return v
+	}
+	return s
+}
+
+func stripHostPort(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.Trim(host, "[]")
+}
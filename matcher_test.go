@@ -0,0 +1,211 @@
+package wo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRadixMatcher(t *testing.T) {
+	m := NewRadixMatcher()
+
+	for _, pattern := range []string{
+		"GET /users",
+		"GET /users/{id}",
+		"GET /users/new",
+		"DELETE /users/{id}",
+		"GET /users/{id}/posts/{post}",
+		"GET /files/{path...}",
+		"/static/",
+		"GET /docs/{$}",
+		"GET /{$}",
+		"GET api.example.com/users",
+	} {
+		m.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, "%s|%s|%s|%s", r.Pattern, r.PathValue("id"), r.PathValue("post"), r.PathValue("path"))
+		})
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		status   int
+		body     string
+		location string
+		allow    string
+	}{
+		{name: "static", method: http.MethodGet, target: "/users", status: http.StatusOK, body: "GET /users|||"},
+		{name: "param", method: http.MethodGet, target: "/users/42", status: http.StatusOK, body: "GET /users/{id}|42||"},
+		{name: "static over param", method: http.MethodGet, target: "/users/new", status: http.StatusOK, body: "GET /users/new|||"},
+		{name: "params", method: http.MethodGet, target: "/users/1/posts/2", status: http.StatusOK, body: "GET /users/{id}/posts/{post}|1|2|"},
+		{name: "escaped param", method: http.MethodGet, target: "/users/a%2Fb", status: http.StatusOK, body: "GET /users/{id}|a/b||"},
+		{name: "method", method: http.MethodDelete, target: "/users/42", status: http.StatusOK, body: "DELETE /users/{id}|42||"},
+		{name: "head", method: http.MethodHead, target: "/users", status: http.StatusOK},
+		{name: "catch-all", method: http.MethodGet, target: "/files/a/b.txt", status: http.StatusOK, body: "GET /files/{path...}|||a/b.txt"},
+		{name: "empty catch-all", method: http.MethodGet, target: "/files/", status: http.StatusOK, body: "GET /files/{path...}|||"},
+		{name: "subtree", method: http.MethodPost, target: "/static/css/app.css", status: http.StatusOK, body: "/static/|||"},
+		{name: "exact trailing slash", method: http.MethodGet, target: "/docs/", status: http.StatusOK, body: "GET /docs/{$}|||"},
+		{name: "root", method: http.MethodGet, target: "/", status: http.StatusOK, body: "GET /{$}|||"},
+		{name: "backtracking", method: http.MethodGet, target: "/users/new/posts/2", status: http.StatusOK, body: "GET /users/{id}/posts/{post}|new|2|"},
+		{name: "host", method: http.MethodGet, target: "http://api.example.com:8080/users", status: http.StatusOK, body: "GET api.example.com/users|||"},
+		{name: "method not allowed", method: http.MethodPost, target: "/users/42", status: http.StatusMethodNotAllowed, allow: "DELETE, GET, HEAD"},
+		{name: "redirect", method: http.MethodGet, target: "/static?v=1", status: http.StatusMovedPermanently, location: "/static/?v=1"},
+		{name: "not found", method: http.MethodGet, target: "/docs/intro", status: http.StatusNotFound},
+		{name: "empty segment", method: http.MethodGet, target: "/users//posts/2?v=1", status: http.StatusMovedPermanently, location: "/users/posts/2?v=1"},
+		{name: "dot segments", method: http.MethodPost, target: "/files/a/../../etc/passwd", status: http.StatusPermanentRedirect, location: "/etc/passwd"},
+		{name: "escaped dot segments", method: http.MethodGet, target: "/files/a/%2e%2e/%2E%2E/etc/passwd", status: http.StatusBadRequest},
+		{name: "escaped dot param", method: http.MethodGet, target: "/users/%2e%2e", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			m.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rec.Body.String())
+			}
+			assert.Equal(t, tt.location, rec.Header().Get(HeaderLocation))
+			assert.Equal(t, tt.allow, rec.Header().Get(HeaderAllow))
+		})
+	}
+}
+
+func TestRadixMatcher_InvalidPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		err     string
+	}{
+		{pattern: "users", err: `wo: parsing "users": host/path missing /`},
+		{pattern: "/{$}/users", err: `wo: parsing "/{$}/users": {$} not at the end`},
+		{pattern: "/{path...}/users", err: `wo: parsing "/{path...}/users": {path...} wildcard not at the end`},
+		{pattern: "/{1id}", err: `wo: parsing "/{1id}": bad wildcard name "1id"`},
+		{pattern: "/{id}/{id}", err: `wo: parsing "/{id}/{id}": duplicate wildcard name "id"`},
+		{pattern: "/user-{id}", err: `wo: parsing "/user-{id}": bad wildcard segment "user-{id}"`},
+		{pattern: "GET /users/{name}", err: `wo: pattern "GET /users/{name}" conflicts with pattern "GET /users/{id}": both match the same requests`},
+		{pattern: "GET /{section}/new", err: `wo: pattern "GET /{section}/new" conflicts with pattern "GET /users/{id}": both match some requests, neither is more specific`},
+		{pattern: "/users/new", err: `wo: pattern "/users/new" conflicts with pattern "GET /users/{id}": both match some requests, neither is more specific`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			m := NewRadixMatcher()
+			m.HandleFunc("GET /users/{id}", func(http.ResponseWriter, *http.Request) {})
+
+			assert.PanicsWithValue(t, tt.err, func() {
+				m.HandleFunc(tt.pattern, func(http.ResponseWriter, *http.Request) {})
+			})
+		})
+	}
+}
+
+func TestRadixMatcher_Conflicts(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		other    string
+		conflict bool
+	}{
+		{pattern: "GET /a/{x}", other: "GET /{y}/b", conflict: true},
+		{pattern: "GET /a/{x}", other: "/{y}/b", conflict: true},
+		{pattern: "GET /a/", other: "GET /{x}/b", conflict: true},
+		{pattern: "GET /a/{x...}", other: "GET /a/", conflict: true},
+		{pattern: "HEAD /a/{x}", other: "GET /{y}/b", conflict: true},
+		{pattern: "GET /a/{x}", other: "GET /a/b"},
+		{pattern: "GET /a/{x}", other: "/a/{y}"},
+		{pattern: "GET /{x...}", other: "GET /a/{y}"},
+		{pattern: "GET /a/{x}", other: "POST /{y}/b"},
+		{pattern: "GET /a/{$}", other: "GET /a/{x}"},
+		{pattern: "GET /{x}/b", other: "GET /a/b/c"},
+		{pattern: "GET example.com/a/{x}", other: "GET /{y}/b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.other, func(t *testing.T) {
+			for _, patterns := range [][2]string{{tt.pattern, tt.other}, {tt.other, tt.pattern}} {
+				m := NewRadixMatcher()
+				m.HandleFunc(patterns[0], func(http.ResponseWriter, *http.Request) {})
+
+				register := func() { m.HandleFunc(patterns[1], func(http.ResponseWriter, *http.Request) {}) }
+				if tt.conflict {
+					assert.Panics(t, register)
+				} else {
+					assert.NotPanics(t, register)
+				}
+			}
+		})
+	}
+}
+
+func TestRadixMatcher_Allocs(t *testing.T) {
+	m := NewRadixMatcher()
+	m.HandleFunc("GET /files/", func(http.ResponseWriter, *http.Request) {})
+	m.HandleFunc("GET /files/{dir}/readme", func(http.ResponseWriter, *http.Request) {})
+
+	w := &discardWriter{header: make(http.Header)}
+	req := httptest.NewRequest(http.MethodGet, "/files/docs/index.html", nil)
+
+	// the backtracking from the wildcard to the rest of the path doesn't allocate
+	allocs := testing.AllocsPerRun(100, func() {
+		m.ServeHTTP(w, req)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestRouter_SetMatcher(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.SetMatcher(func() Matcher { return NewRadixMatcher() })
+
+	router.GET("/users/{id}", func(*Event) error { return nil })
+	// overlapping patterns, neither is more specific
+	router.GET("/{section}/new", func(*Event) error { return nil })
+	router.GET("/users/{name}", func(*Event) error { return nil })
+
+	_, err := router.Build(nil)
+	var conflictErr *RouteConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	require.Len(t, conflictErr.Conflicts, 2)
+	assert.Equal(t, "GET /{section}/new", conflictErr.Conflicts[0].Route.Pattern)
+	assert.Equal(t, "GET /users/{id}", conflictErr.Conflicts[0].With.Pattern)
+	assert.Equal(t, "GET /users/{name}", conflictErr.Conflicts[1].Route.Pattern)
+	assert.Equal(t, "GET /users/{id}", conflictErr.Conflicts[1].With.Pattern)
+
+	router = New[*Event](eventFactory, errorHandler)
+	router.SetMatcher(func() Matcher { return NewRadixMatcher() })
+	router.GET("/users/{id}", func(e *Event) error {
+		return e.String(http.StatusOK, "user "+e.Param("id"))
+	})
+	router.GET("/users/new", func(e *Event) error {
+		return e.String(http.StatusOK, "new user")
+	})
+	router.Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("legacy " + r.URL.Path))
+	}))
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		target string
+		body   string
+	}{
+		{target: "/users/42", body: "user 42"},
+		{target: "/users/new", body: "new user"},
+		{target: "/legacy/a/b", body: "legacy /a/b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.body, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/session"
+)
+
+// Policy decides whether the subject is allowed to perform the action on the resource.
+type Policy interface {
+	Allow(ctx context.Context, subject, action, resource string) bool
+}
+
+// PolicyFunc is an adapter to allow the use of ordinary functions as [Policy].
+type PolicyFunc func(ctx context.Context, subject, action, resource string) bool
+
+func (f PolicyFunc) Allow(ctx context.Context, subject, action, resource string) bool {
+	return f(ctx, subject, action, resource)
+}
+
+type AuthorizeConfig[T wo.Resolver] struct {
+	// Subject returns the authenticated subject of the request, ok is false if the request
+	// is not authenticated, in which case [wo.ErrUnauthorized] is returned.
+	// See SessionSubject and ClaimsSubject.
+	Subject func(e T) (subject string, ok bool) `json:"-" yaml:"-"`
+
+	// Action returns the action of the request.
+	//
+	// Default: DefaultAction
+	Action func(e T) string `json:"-" yaml:"-"`
+
+	// Resource returns the resource of the request.
+	//
+	// Default: DefaultResource
+	Resource func(e T) string `json:"-" yaml:"-"`
+
+	// DenyHandler is called when the policy denies the request.
+	//
+	// Default: returns wo.ErrForbidden
+	DenyHandler func(e T, subject, action, resource string) error `json:"-" yaml:"-"`
+}
+
+func (c *AuthorizeConfig[T]) SetDefaults() {
+	if c.Action == nil {
+		c.Action = DefaultAction[T]
+	}
+	if c.Resource == nil {
+		c.Resource = DefaultResource[T]
+	}
+	if c.DenyHandler == nil {
+		c.DenyHandler = func(_ T, subject, action, resource string) error {
+			return wo.ErrForbidden.WithInternal(fmt.Errorf("authorize: %q is not allowed to %s %q", subject, action, resource))
+		}
+	}
+}
+
+// Authorize allows the request only if the policy allows the subject to perform the action
+// on the resource of the request. It must run after the middleware which authenticates the subject.
+func Authorize[T wo.Resolver](cfg AuthorizeConfig[T], policy Policy, skippers ...Skipper[T]) func(T) error {
+	if policy == nil {
+		panic("authorize middleware: policy is nil")
+	}
+	if cfg.Subject == nil {
+		panic("authorize middleware: subject func is nil")
+	}
+
+	cfg.SetDefaults()
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		subject, ok := cfg.Subject(e)
+		if !ok {
+			return wo.ErrUnauthorized
+		}
+
+		action := cfg.Action(e)
+		resource := cfg.Resource(e)
+
+		if !policy.Allow(e.Request().Context(), subject, action, resource) {
+			return cfg.DenyHandler(e, subject, action, resource)
+		}
+		return e.Next()
+	}
+}
+
+// DefaultAction returns the action of the request method:
+// "read" for GET and HEAD, "create" for POST, "update" for PUT and PATCH, "delete" for DELETE
+// and the lowercase method otherwise.
+func DefaultAction[T wo.Resolver](e T) string {
+	switch method := e.Request().Method; method {
+	case http.MethodGet, http.MethodHead:
+		return "read"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return strings.ToLower(method)
+	}
+}
+
+// DefaultResource returns the path of the route pattern which matched the request, ex. "/users/{id}",
+// so the policy rules don't depend on the path values. It falls back to the request path.
+func DefaultResource[T wo.Resolver](e T) string {
+	r := e.Request()
+
+	pattern := r.Pattern
+	if pattern == "" {
+		return r.URL.Path
+	}
+	// strip the method and the host
+	if _, p, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimLeft(p, " \t")
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+	return pattern
+}
+
+// SessionSubject returns the subject stored in the session under the key, ex. the user ID.
+// It must run after the [Session] middleware.
+func SessionSubject[T wo.Resolver](s *session.Session, key string) func(e T) (string, bool) {
+	if s == nil {
+		panic("authorize middleware: session is nil")
+	}
+
+	return func(e T) (string, bool) {
+		subject := s.GetString(e.Request().Context(), key)
+		return subject, subject != ""
+	}
+}
+
+// ClaimsSubject returns the claim of the verified token claims (ex. of a JWT) as the subject,
+// the claim defaults to "sub". The claims func returns nil if the request has no verified token.
+func ClaimsSubject[T wo.Resolver](claims func(e T) map[string]any, claim string) func(e T) (string, bool) {
+	if claims == nil {
+		panic("authorize middleware: claims func is nil")
+	}
+	if claim == "" {
+		claim = "sub"
+	}
+
+	return func(e T) (string, bool) {
+		switch v := claims(e)[claim].(type) {
+		case string:
+			return v, v != ""
+		case fmt.Stringer:
+			s := v.String()
+			return s, s != ""
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case int, int64:
+			return fmt.Sprint(v), true
+		default:
+			return "", false
+		}
+	}
+}
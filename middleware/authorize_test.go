@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/session"
+)
+
+func headerSubject(e *wo.Event) (string, bool) {
+	subject := e.Request().Header.Get("X-Subject")
+	return subject, subject != ""
+}
+
+func TestAuthorize(t *testing.T) {
+	policy := PolicyFunc(func(_ context.Context, subject, action, resource string) bool {
+		return subject == "admin" || (action == "read" && resource == "/users/{id}")
+	})
+
+	tests := []struct {
+		name           string
+		method         string
+		pattern        string
+		subject        string
+		expectedStatus int
+	}{
+		{
+			name:    "admin",
+			method:  http.MethodDelete,
+			pattern: "DELETE /users/{id}",
+			subject: "admin",
+		},
+		{
+			name:    "read allowed by pattern",
+			method:  http.MethodGet,
+			pattern: "GET /users/{id}",
+			subject: "john",
+		},
+		{
+			name:           "delete denied",
+			method:         http.MethodDelete,
+			pattern:        "DELETE /users/{id}",
+			subject:        "john",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unauthenticated",
+			method:         http.MethodGet,
+			pattern:        "GET /users/{id}",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSessionTestEvent(tt.method, "/users/42", map[string]string{"X-Subject": tt.subject})
+			e.Request().Pattern = tt.pattern
+
+			mw := Authorize(AuthorizeConfig[*wo.Event]{Subject: headerSubject}, policy)
+
+			err := mw(e)
+			if tt.expectedStatus == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedStatus, wo.AsHTTPError(err).Status)
+		})
+	}
+}
+
+func TestAuthorize_DenyHandler(t *testing.T) {
+	errDenied := errors.New("denied")
+	var got [3]string
+
+	mw := Authorize(AuthorizeConfig[*wo.Event]{
+		Subject: headerSubject,
+		Action:  func(*wo.Event) string { return "approve" },
+		DenyHandler: func(_ *wo.Event, subject, action, resource string) error {
+			got = [3]string{subject, action, resource}
+			return errDenied
+		},
+	}, PolicyFunc(func(context.Context, string, string, string) bool { return false }))
+
+	e := newSessionTestEvent(http.MethodPost, "/orders/1", map[string]string{"X-Subject": "john"})
+
+	assert.ErrorIs(t, mw(e), errDenied)
+	assert.Equal(t, [3]string{"john", "approve", "/orders/1"}, got)
+}
+
+func TestAuthorize_Skipper(t *testing.T) {
+	mw := Authorize(
+		AuthorizeConfig[*wo.Event]{Subject: headerSubject},
+		PolicyFunc(func(context.Context, string, string, string) bool { return false }),
+		func(*wo.Event) bool { return true },
+	)
+
+	assert.NoError(t, mw(newSessionTestEvent(http.MethodGet, "/", nil)))
+}
+
+func TestAuthorize_Panics(t *testing.T) {
+	policy := PolicyFunc(func(context.Context, string, string, string) bool { return true })
+
+	assert.Panics(t, func() { Authorize(AuthorizeConfig[*wo.Event]{Subject: headerSubject}, nil) })
+	assert.Panics(t, func() { Authorize(AuthorizeConfig[*wo.Event]{}, policy) })
+}
+
+func TestDefaultAction(t *testing.T) {
+	tests := map[string]string{
+		http.MethodGet:     "read",
+		http.MethodHead:    "read",
+		http.MethodPost:    "create",
+		http.MethodPut:     "update",
+		http.MethodPatch:   "update",
+		http.MethodDelete:  "delete",
+		http.MethodOptions: "options",
+	}
+
+	for method, expected := range tests {
+		t.Run(method, func(t *testing.T) {
+			assert.Equal(t, expected, DefaultAction(newSessionTestEvent(method, "/", nil)))
+		})
+	}
+}
+
+func TestDefaultResource(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{name: "no pattern", expected: "/users/42"},
+		{name: "path", pattern: "/users/{id}", expected: "/users/{id}"},
+		{name: "method", pattern: "GET /users/{id}", expected: "/users/{id}"},
+		{name: "method and host", pattern: "GET example.com/users/{id}", expected: "/users/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSessionTestEvent(http.MethodGet, "/users/42", nil)
+			e.Request().Pattern = tt.pattern
+
+			assert.Equal(t, tt.expected, DefaultResource(e))
+		})
+	}
+}
+
+func TestClaimsSubject(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     map[string]any
+		claim      string
+		expected   string
+		expectedOK bool
+	}{
+		{name: "sub", claims: map[string]any{"sub": "42"}, expected: "42", expectedOK: true},
+		{name: "custom claim", claims: map[string]any{"uid": float64(42)}, claim: "uid", expected: "42", expectedOK: true},
+		{name: "missing", claims: map[string]any{}, expectedOK: false},
+		{name: "no claims", expectedOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject := ClaimsSubject(func(*wo.Event) map[string]any { return tt.claims }, tt.claim)
+
+			got, ok := subject(newSessionTestEvent(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestSessionSubject(t *testing.T) {
+	store := &mockStore{}
+	s := session.New(session.Config{}, store)
+
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+	s.Put(ctx, "userID", "42")
+
+	e := newSessionTestEvent(http.MethodGet, "/", nil)
+	e.SetRequest(e.Request().WithContext(ctx))
+
+	got, ok := SessionSubject[*wo.Event](s, "userID")(e)
+	assert.True(t, ok)
+	assert.Equal(t, "42", got)
+
+	_, ok = SessionSubject[*wo.Event](s, "unknown")(e)
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { SessionSubject[*wo.Event](nil, "userID") })
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gowool/wo"
+)
+
+var errBodyTooSlow = errors.New("request body is read below the minimum rate")
+
+type MinBodyRateConfig struct {
+	// MinBytesPerSecond is the minimum average rate of the request body, the bodies trickling
+	// slower, ex. by the slowloris clients holding the handlers, are aborted.
+	//
+	// Default: 1024
+	MinBytesPerSecond int64 `env:"MIN_BYTES_PER_SECOND" json:"minBytesPerSecond,omitempty" yaml:"minBytesPerSecond,omitempty"`
+
+	// Grace is the time the body may be read in at any rate, ex. for the slow start of the
+	// mobile clients, the rate applies past it.
+	//
+	// Default: 5s
+	Grace time.Duration `env:"GRACE" json:"grace,omitempty,format:units" yaml:"grace,omitempty"`
+
+	// TimeFunc returns the current time, ex. for the tests.
+	//
+	// Default: time.Now
+	TimeFunc func() time.Time `json:"-" yaml:"-"`
+}
+
+func (c *MinBodyRateConfig) SetDefaults() {
+	if c.MinBytesPerSecond == 0 {
+		c.MinBytesPerSecond = 1024
+	}
+	if c.Grace == 0 {
+		c.Grace = 5 * time.Second
+	}
+	if c.TimeFunc == nil {
+		c.TimeFunc = time.Now
+	}
+}
+
+func (c *MinBodyRateConfig) Validate() error {
+	if c.MinBytesPerSecond < 0 {
+		return errors.New("min body rate: min bytes per second must not be negative")
+	}
+	if c.Grace < 0 {
+		return errors.New("min body rate: grace must not be negative")
+	}
+	return nil
+}
+
+// MinBodyRate aborts the requests whose body is read below the minimum rate with
+// [wo.ErrRequestTimeout], protecting the handlers from the clients trickling the body. The
+// read deadline of the connection follows the rate, so the stalled reads are interrupted too,
+// while the server ReadHeaderTimeout protects the headers.
+//
+//	r.Group("/upload").BindFunc(middleware.MinBodyRate[*wo.Event](middleware.MinBodyRateConfig{
+//		MinBytesPerSecond: 16 << 10,
+//	}))
+func MinBodyRate[T wo.Resolver](cfg MinBodyRateConfig, skippers ...Skipper[T]) func(T) error {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		r := e.Request()
+		if skip(e) || r.Body == nil || r.Body == http.NoBody {
+			return e.Next()
+		}
+
+		body := &rateReader{
+			ReadCloser: r.Body,
+			rc:         http.NewResponseController(e.Response()),
+			now:        cfg.TimeFunc,
+			start:      cfg.TimeFunc(),
+			grace:      cfg.Grace,
+			rate:       cfg.MinBytesPerSecond,
+		}
+		r.Body = body
+		defer body.resetDeadline()
+
+		err := e.Next()
+		if body.tooSlow && err != nil {
+			return wo.ErrRequestTimeout.WithInternal(errBodyTooSlow)
+		}
+		return err
+	}
+}
+
+type rateReader struct {
+	io.ReadCloser
+	rc       *http.ResponseController
+	now      func() time.Time
+	start    time.Time
+	grace    time.Duration
+	rate     int64
+	read     int64
+	deadline bool
+	tooSlow  bool
+}
+
+// allowed returns the time the n bytes of the body must be read by.
+func (r *rateReader) allowed(n int64) time.Time {
+	return r.start.Add(r.grace + time.Duration(n)*time.Second/time.Duration(r.rate))
+}
+
+func (r *rateReader) Read(b []byte) (int, error) {
+	if r.tooSlow {
+		return 0, wo.ErrRequestTimeout.WithInternal(errBodyTooSlow)
+	}
+
+	// the next byte must arrive in time, the stalled read is interrupted by the deadline
+	if err := r.rc.SetReadDeadline(r.allowed(r.read + 1)); err == nil {
+		r.deadline = true
+	}
+
+	n, err := r.ReadCloser.Read(b)
+	r.read += int64(n)
+
+	if errors.Is(err, os.ErrDeadlineExceeded) || (err == nil && r.now().After(r.allowed(r.read))) {
+		r.tooSlow = true
+		return n, wo.ErrRequestTimeout.WithInternal(errBodyTooSlow)
+	}
+	if err == io.EOF {
+		r.resetDeadline()
+	}
+	return n, err
+}
+
+// resetDeadline resets the read deadline, unless the body was too slow, so the server doesn't
+// wait for the rest of it before the response, and closes the connection.
+func (r *rateReader) resetDeadline() {
+	if r.deadline && !r.tooSlow {
+		r.deadline = false
+		_ = r.rc.SetReadDeadline(time.Time{})
+	}
+}
+
+func (r *rateReader) Reread() {
+	if rr, ok := r.ReadCloser.(interface{ Reread() }); ok {
+		rr.Reread()
+	}
+}
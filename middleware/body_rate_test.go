@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+// clockReader advances the clock by the step on each read of a single byte.
+type clockReader struct {
+	r    io.Reader
+	now  *time.Time
+	step time.Duration
+}
+
+func (r *clockReader) Read(b []byte) (int, error) {
+	*r.now = r.now.Add(r.step)
+	return r.r.Read(b[:1])
+}
+
+func newMinBodyRateHandler(t *testing.T, cfg MinBodyRateConfig) http.Handler {
+	t.Helper()
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+	router.BindFunc(MinBodyRate[*wo.Event](cfg))
+	router.POST("/upload", func(e *wo.Event) error {
+		if _, err := io.ReadAll(e.Request().Body); err != nil {
+			// the handler's own error is replaced
+			return wo.ErrBadRequest.WithInternal(err)
+		}
+		return e.NoContent(http.StatusNoContent)
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+	return h
+}
+
+func TestMinBodyRate(t *testing.T) {
+	tests := []struct {
+		name           string
+		step           time.Duration
+		expectedStatus int
+	}{
+		{name: "fast", step: time.Millisecond, expectedStatus: http.StatusNoContent},
+		{name: "slow within the grace", step: 100 * time.Millisecond, expectedStatus: http.StatusNoContent},
+		{name: "slow", step: time.Second, expectedStatus: http.StatusRequestTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			h := newMinBodyRateHandler(t, MinBodyRateConfig{
+				MinBytesPerSecond: 10,
+				Grace:             2 * time.Second,
+				TimeFunc:          func() time.Time { return now },
+			})
+
+			body := &clockReader{r: strings.NewReader(strings.Repeat("x", 20)), now: &now, step: tt.step}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/upload", body))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestMinBodyRate_StalledConnection(t *testing.T) {
+	srv := httptest.NewServer(newMinBodyRateHandler(t, MinBodyRateConfig{MinBytesPerSecond: 1 << 20, Grace: 50 * time.Millisecond}))
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// the body is never sent past the first bytes
+	_, err = io.WriteString(conn, "POST /upload HTTP/1.1\r\nHost: example.com\r\nContent-Length: 1000\r\n\r\nxx")
+	require.NoError(t, err)
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusRequestTimeout, res.StatusCode)
+}
+
+func TestMinBodyRate_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() { MinBodyRate[*wo.Event](MinBodyRateConfig{MinBytesPerSecond: -1}) })
+	assert.Panics(t, func() { MinBodyRate[*wo.Event](MinBodyRateConfig{Grace: -time.Second}) })
+}
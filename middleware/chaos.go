@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gowool/wo"
+)
+
+const (
+	ChaosLatency = "latency"
+	ChaosError   = "error"
+	ChaosAbort   = "abort"
+)
+
+type ChaosConfig struct {
+	// Enabled enables the fault injection, the middleware does nothing unless it's explicitly enabled.
+	Enabled bool `env:"ENABLED" json:"enabled,omitempty" yaml:"enabled,omitempty"`
+
+	// LatencyProbability is the probability of the latency injected before the request is handled.
+	LatencyProbability float64 `env:"LATENCY_PROBABILITY" json:"latencyProbability,omitempty" yaml:"latencyProbability,omitempty"`
+
+	// Latency is the minimum injected latency.
+	//
+	// Default: 100ms
+	Latency time.Duration `env:"LATENCY" json:"latency,omitempty,format:units" yaml:"latency,omitempty"`
+
+	// LatencyJitter is the maximum random latency added to Latency.
+	LatencyJitter time.Duration `env:"LATENCY_JITTER" json:"latencyJitter,omitempty,format:units" yaml:"latencyJitter,omitempty"`
+
+	// ErrorProbability is the probability of the request failing with one of ErrorStatuses instead of being handled.
+	ErrorProbability float64 `env:"ERROR_PROBABILITY" json:"errorProbability,omitempty" yaml:"errorProbability,omitempty"`
+
+	// ErrorStatuses are the statuses of the injected errors, picked at random.
+	//
+	// Default: [500, 502, 503]
+	ErrorStatuses []int `env:"ERROR_STATUSES" json:"errorStatuses,omitempty" yaml:"errorStatuses,omitempty"`
+
+	// AbortProbability is the probability of the connection aborted instead of the request being handled.
+	AbortProbability float64 `env:"ABORT_PROBABILITY" json:"abortProbability,omitempty" yaml:"abortProbability,omitempty"`
+
+	// Patterns are the route patterns the faults are injected to, ex. "GET /api/orders/{id}".
+	// If empty, all routes are affected.
+	Patterns []string `env:"PATTERNS" json:"patterns,omitempty" yaml:"patterns,omitempty"`
+
+	// TriggerHeader is the request header which must be present for the faults to be injected.
+	// The header values "latency", "error" and "abort" force the fault regardless of its probability.
+	// If empty, all requests are affected.
+	TriggerHeader string `env:"TRIGGER_HEADER" json:"triggerHeader,omitempty" yaml:"triggerHeader,omitempty"`
+
+	// Rand returns the random number in [0, 1).
+	//
+	// Default: rand.Float64
+	Rand func() float64 `json:"-" yaml:"-"`
+}
+
+func (c *ChaosConfig) SetDefaults() {
+	if c.Latency <= 0 {
+		c.Latency = 100 * time.Millisecond
+	}
+	if len(c.ErrorStatuses) == 0 {
+		c.ErrorStatuses = []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}
+	}
+	if c.Rand == nil {
+		c.Rand = rand.Float64
+	}
+}
+
+func (c *ChaosConfig) Validate() error {
+	for _, p := range []float64{c.LatencyProbability, c.ErrorProbability, c.AbortProbability} {
+		if p < 0 || p > 1 {
+			return errors.New("chaos: probability must be within [0, 1]")
+		}
+	}
+	for _, status := range c.ErrorStatuses {
+		if status < http.StatusBadRequest || status > 599 {
+			return errors.New("chaos: error status must be within [400, 599]")
+		}
+	}
+	return nil
+}
+
+// Chaos injects the faults for the resilience testing: the latency, the errors with the configured
+// statuses and the aborted connections, each with its own probability. The faults are scoped by
+// the route patterns and the trigger header, so only the chosen routes and requests are affected:
+//
+//	r.BindFunc(middleware.Chaos[*wo.Event](middleware.ChaosConfig{
+//		Enabled:          os.Getenv("CHAOS") == "1",
+//		ErrorProbability: 0.1,
+//		TriggerHeader:    "X-Chaos",
+//	}))
+//
+// The middleware does nothing unless it's explicitly enabled.
+func Chaos[T wo.Resolver](cfg ChaosConfig, skippers ...Skipper[T]) func(T) error {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if !cfg.Enabled || skip(e) {
+			return e.Next()
+		}
+
+		r := e.Request()
+		if len(cfg.Patterns) > 0 && !slices.Contains(cfg.Patterns, r.Pattern) {
+			return e.Next()
+		}
+
+		var forced string
+		if cfg.TriggerHeader != "" {
+			values := r.Header.Values(cfg.TriggerHeader)
+			if len(values) == 0 {
+				return e.Next()
+			}
+			forced = strings.ToLower(strings.TrimSpace(values[0]))
+		}
+
+		inject := func(fault string, probability float64) bool {
+			return forced == fault || (probability > 0 && cfg.Rand() < probability)
+		}
+
+		if inject(ChaosLatency, cfg.LatencyProbability) {
+			latency := cfg.Latency
+			if cfg.LatencyJitter > 0 {
+				latency += time.Duration(cfg.Rand() * float64(cfg.LatencyJitter))
+			}
+
+			timer := time.NewTimer(latency)
+			select {
+			case <-r.Context().Done():
+				timer.Stop()
+				return r.Context().Err()
+			case <-timer.C:
+			}
+		}
+
+		if inject(ChaosAbort, cfg.AbortProbability) {
+			// the server closes the connection without a response
+			panic(http.ErrAbortHandler)
+		}
+
+		if inject(ChaosError, cfg.ErrorProbability) {
+			status := cfg.ErrorStatuses[int(cfg.Rand()*float64(len(cfg.ErrorStatuses)))%len(cfg.ErrorStatuses)]
+			return wo.NewHTTPError(status, "chaos: injected fault")
+		}
+
+		return e.Next()
+	}
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func newChaosHandler(t *testing.T, cfg ChaosConfig) http.Handler {
+	t.Helper()
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, func(e *wo.Event, err error) {
+		e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+	})
+	router.BindFunc(Chaos[*wo.Event](cfg))
+	router.GET("/orders/{id}", func(e *wo.Event) error {
+		return e.NoContent(http.StatusNoContent)
+	})
+	router.GET("/health", func(e *wo.Event) error {
+		return e.NoContent(http.StatusNoContent)
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+	return h
+}
+
+func TestChaos(t *testing.T) {
+	always := func() float64 { return 0 }
+
+	tests := []struct {
+		name    string
+		cfg     ChaosConfig
+		target  string
+		header  string
+		status  int
+		aborted bool
+		latency time.Duration
+	}{
+		{
+			name:   "disabled",
+			cfg:    ChaosConfig{ErrorProbability: 1},
+			target: "/orders/1",
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "error",
+			cfg:    ChaosConfig{Enabled: true, ErrorProbability: 1, ErrorStatuses: []int{http.StatusTeapot}},
+			target: "/orders/1",
+			status: http.StatusTeapot,
+		},
+		{
+			name:   "not injected",
+			cfg:    ChaosConfig{Enabled: true, ErrorProbability: 0.5, Rand: func() float64 { return 0.7 }},
+			target: "/orders/1",
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "other pattern",
+			cfg:    ChaosConfig{Enabled: true, ErrorProbability: 1, Patterns: []string{"GET /orders/{id}"}},
+			target: "/health",
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "pattern",
+			cfg:    ChaosConfig{Enabled: true, ErrorProbability: 1, Patterns: []string{"GET /orders/{id}"}, Rand: always},
+			target: "/orders/1",
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "missing trigger header",
+			cfg:    ChaosConfig{Enabled: true, ErrorProbability: 1, TriggerHeader: "X-Chaos"},
+			target: "/orders/1",
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "forced by trigger header",
+			cfg:    ChaosConfig{Enabled: true, TriggerHeader: "X-Chaos", ErrorStatuses: []int{http.StatusBadGateway}},
+			target: "/orders/1",
+			header: "error",
+			status: http.StatusBadGateway,
+		},
+		{
+			name:    "latency",
+			cfg:     ChaosConfig{Enabled: true, LatencyProbability: 1, Latency: 20 * time.Millisecond, Rand: always},
+			target:  "/orders/1",
+			status:  http.StatusNoContent,
+			latency: 20 * time.Millisecond,
+		},
+		{
+			name:    "abort",
+			cfg:     ChaosConfig{Enabled: true, TriggerHeader: "X-Chaos"},
+			target:  "/orders/1",
+			header:  "abort",
+			aborted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newChaosHandler(t, tt.cfg)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("X-Chaos", tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			if tt.aborted {
+				assert.PanicsWithValue(t, http.ErrAbortHandler, func() { h.ServeHTTP(rec, req) })
+				return
+			}
+
+			start := time.Now()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.GreaterOrEqual(t, time.Since(start), tt.latency)
+		})
+	}
+}
+
+func TestChaosConfig_Validate(t *testing.T) {
+	assert.EqualError(t, (&ChaosConfig{ErrorProbability: 1.5}).Validate(), "chaos: probability must be within [0, 1]")
+	assert.EqualError(t, (&ChaosConfig{ErrorStatuses: []int{302}}).Validate(), "chaos: error status must be within [400, 599]")
+	assert.NoError(t, (&ChaosConfig{AbortProbability: 0.1, ErrorStatuses: []int{503}}).Validate())
+}
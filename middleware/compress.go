@@ -83,7 +83,7 @@ func Compress[T wo.Resolver](cfg CompressConfig, skippers ...Skipper[T]) func(T)
 		}
 
 		res := e.Response()
-		res.Header().Add(wo.HeaderVary, wo.HeaderAcceptEncoding)
+		wo.AddVary(res.Header(), wo.HeaderAcceptEncoding)
 
 		if !strings.Contains(e.Request().Header.Get(wo.HeaderAcceptEncoding), gzipScheme) {
 			return e.Next()
@@ -107,7 +107,10 @@ func Compress[T wo.Resolver](cfg CompressConfig, skippers ...Skipper[T]) func(T)
 			// There are different reasons for cases when we have not yet written response to the client and now need to do so.
 			// a) handler response had only response code and no response body (ala 404 or redirects etc). Response code need to be written now.
 			// b) body is shorter than our minimum length threshold and being buffered currently and needs to be written
-			if !grw.wroteBody {
+			if grw.passthrough {
+				e.SetResponse(rw)
+				w.Reset(io.Discard)
+			} else if !grw.wroteBody {
 				if res.Header().Get(wo.HeaderContentEncoding) == gzipScheme {
 					res.Header().Del(wo.HeaderContentEncoding)
 				}
@@ -146,6 +149,7 @@ type gzipResponseWriter struct {
 	wroteHeader       bool
 	wroteBody         bool
 	minLengthExceeded bool
+	passthrough       bool
 }
 
 func (w *gzipResponseWriter) WriteHeader(code int) {
@@ -158,9 +162,22 @@ func (w *gzipResponseWriter) WriteHeader(code int) {
 }
 
 func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+
 	if w.Header().Get(wo.HeaderContentType) == "" {
 		w.Header().Set(wo.HeaderContentType, http.DetectContentType(b))
 	}
+
+	if !w.wroteBody && compressed(w.Header()) {
+		// the body is compressed already, ex. the zip archive, it's written as is
+		w.passthrough = true
+		if w.wroteHeader {
+			w.ResponseWriter.WriteHeader(w.code)
+		}
+		return w.ResponseWriter.Write(b)
+	}
 	w.wroteBody = true
 
 	if !w.minLengthExceeded {
@@ -185,6 +202,11 @@ func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 }
 
 func (w *gzipResponseWriter) Flush() {
+	if w.passthrough {
+		_ = http.NewResponseController(w.ResponseWriter).Flush()
+		return
+	}
+
 	if !w.minLengthExceeded {
 		// Enforce compression because we will not know how much more data will come
 		w.minLengthExceeded = true
@@ -200,6 +222,27 @@ func (w *gzipResponseWriter) Flush() {
 	_ = http.NewResponseController(w.ResponseWriter).Flush()
 }
 
+// compressed reports whether the response body is compressed already: it has the content
+// encoding set by the handler or the content type of a compressed format.
+func compressed(h http.Header) bool {
+	if h.Get(wo.HeaderContentEncoding) != "" {
+		return true
+	}
+
+	ct := h.Get(wo.HeaderContentType)
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	switch strings.TrimSpace(strings.ToLower(ct)) {
+	case wo.MIMEApplicationZip, wo.MIMEApplicationXLSX, "application/gzip", "application/x-gzip",
+		"application/zstd", "image/jpeg", "image/png", "image/gif", "image/webp", "image/avif",
+		"video/mp4", "audio/mpeg":
+		return true
+	default:
+		return false
+	}
+}
+
 func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
 	return w.ResponseWriter
 }
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/gowool/wo"
 )
@@ -201,6 +202,21 @@ func TestCompressConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestCompress_BytesOut(t *testing.T) {
+	body := strings.Repeat("compressible ", 1000)
+	event := &testCompressEventWithData{
+		Event:        newCompressTestEventWithHeaders(map[string]string{wo.HeaderAcceptEncoding: "gzip"}),
+		responseData: []byte(body),
+	}
+	rec := wo.MustUnwrapResponse(event.Response()).ResponseWriter.(*httptest.ResponseRecorder)
+
+	require.NoError(t, Compress[*testCompressEventWithData](CompressConfig{})(event))
+
+	// the compressed bytes on the wire are reported
+	assert.Equal(t, int64(rec.Body.Len()), event.BytesOut())
+	assert.Less(t, event.BytesOut(), int64(len(body)))
+}
+
 func TestCompress_AcceptEncoding_Header(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -1580,3 +1596,111 @@ func TestCompress_Write_After_Compression_Start(t *testing.T) {
 		assert.Equal(t, "gzip", event.Response().Header().Get(wo.HeaderContentEncoding))
 	})
 }
+
+func TestCompress_Vary_CombinedMiddlewares(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+	}{
+		{name: "compress first", order: []string{"compress", "cors", "i18n"}},
+		{name: "cors first", order: []string{"cors", "i18n", "compress"}},
+		{name: "i18n first", order: []string{"i18n", "compress", "cors"}},
+	}
+
+	translator := wo.TranslatorFunc(func(_, key string, _ ...any) string { return key })
+	middlewares := map[string]func(*wo.Event) error{
+		"compress": Compress[*wo.Event](CompressConfig{}),
+		"cors":     CORS[*wo.Event](CORSConfig{}),
+		"i18n":     I18n[*wo.Event](I18nConfig{Locales: []string{"en"}}, translator),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+				e := new(wo.Event)
+				e.Reset(w, r)
+				return e, nil
+			}, func(e *wo.Event, err error) {
+				e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+			})
+			for _, name := range tt.order {
+				router.BindFunc(middlewares[name])
+			}
+			router.GET("/", func(e *wo.Event) error {
+				// the handler adds the names the middlewares already added
+				wo.AddVary(e.Response().Header(), wo.HeaderOrigin, wo.HeaderAcceptEncoding)
+				e.SetContentLanguage("en")
+				return e.String(http.StatusOK, "ok")
+			})
+
+			h, err := router.Build(nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(wo.HeaderOrigin, "https://example.com")
+			req.Header.Set(wo.HeaderAcceptEncoding, "gzip")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			vary := rec.Header().Values(wo.HeaderVary)
+			assert.ElementsMatch(t, []string{wo.HeaderAcceptEncoding, wo.HeaderOrigin, wo.HeaderAcceptLanguage}, vary)
+		})
+	}
+}
+
+type testCompressedEvent struct {
+	*wo.Event
+	contentType string
+	encoding    string
+	body        []byte
+}
+
+func (e *testCompressedEvent) Next() error {
+	e.Response().Header().Set(wo.HeaderContentType, e.contentType)
+	if e.encoding != "" {
+		e.Response().Header().Set(wo.HeaderContentEncoding, e.encoding)
+	}
+	e.Response().WriteHeader(http.StatusCreated)
+	_, err := e.Response().Write(e.body)
+	return err
+}
+
+func TestCompress_Passthrough(t *testing.T) {
+	body := []byte(strings.Repeat("already compressed ", 200))
+
+	tests := []struct {
+		name        string
+		contentType string
+		encoding    string
+		compress    bool
+	}{
+		{name: "zip", contentType: wo.MIMEApplicationZip, encoding: "", compress: false},
+		{name: "xlsx", contentType: wo.MIMEApplicationXLSX, encoding: "", compress: false},
+		{name: "content type params", contentType: "image/PNG; q=1", encoding: "", compress: false},
+		{name: "encoded by handler", contentType: wo.MIMETextPlain, encoding: "br", compress: false},
+		{name: "compressible", contentType: wo.MIMETextCSVCharsetUTF8, encoding: "", compress: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &testCompressedEvent{
+				Event:       newCompressTestEventWithHeaders(map[string]string{wo.HeaderAcceptEncoding: "gzip"}),
+				contentType: tt.contentType,
+				encoding:    tt.encoding,
+				body:        body,
+			}
+			rec := wo.MustUnwrapResponse(event.Response()).ResponseWriter.(*httptest.ResponseRecorder)
+
+			require.NoError(t, Compress[*testCompressedEvent](CompressConfig{})(event))
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			if tt.compress {
+				assert.Equal(t, "gzip", rec.Header().Get(wo.HeaderContentEncoding))
+				assert.Less(t, rec.Body.Len(), len(body))
+				return
+			}
+			assert.Equal(t, tt.encoding, rec.Header().Get(wo.HeaderContentEncoding))
+			assert.Equal(t, body, rec.Body.Bytes())
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package middleware
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/gowool/wo"
 )
@@ -37,6 +39,19 @@ type CORSConfig struct {
 	// Optional.
 	AllowOriginFunc func(origin string) (bool, error) `json:"-" yaml:"-"`
 
+	// OriginProvider validates the origin at runtime, ex. [CORSOrigins] refreshed from the
+	// database for the multi-tenant platforms onboarding the origins at runtime. If an error
+	// is returned, it is returned by the handler. If this option is set, AllowOrigins and
+	// AllowOriginFunc are ignored.
+	//
+	// Optional.
+	OriginProvider CORSOriginProvider `json:"-" yaml:"-"`
+
+	// Metrics counts the allowed and the denied origins, ex. to publish them with expvar.
+	//
+	// Optional.
+	Metrics *CORSMetrics `json:"-" yaml:"-"`
+
 	// AllowMethods determines the value of the Access-Control-Allow-Methods
 	// response header.  This header specified the list of methods allowed when
 	// accessing the resource.  This is used in response to a preflight request.
@@ -103,6 +118,71 @@ type CORSConfig struct {
 	MaxAge int `env:"MAX_AGE" json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
 }
 
+// CORSOriginProvider validates the origins of the CORS requests at runtime, see CORSConfig.OriginProvider.
+type CORSOriginProvider interface {
+	AllowOrigin(ctx context.Context, origin string) (bool, error)
+}
+
+var _ CORSOriginProvider = (*CORSOrigins)(nil)
+
+// CORSOrigins is the [CORSOriginProvider] of the allowed origins replaced at runtime, ex. reloaded
+// from the database periodically. The origins are the exact ones or the patterns with the
+// wildcard characters '*' and '?', like CORSConfig.AllowOrigins, except the "*" one.
+// The zero value allows no origins.
+//
+//	origins := middleware.NewCORSOrigins(loadOrigins(ctx)...)
+//	cors := middleware.CORS[*wo.Event](middleware.CORSConfig{OriginProvider: origins, AllowCredentials: true})
+//	// on the tenant onboarding
+//	origins.Set(loadOrigins(ctx)...)
+type CORSOrigins struct {
+	origins atomic.Pointer[corsAllowOrigins]
+}
+
+func NewCORSOrigins(origins ...string) *CORSOrigins {
+	o := new(CORSOrigins)
+	o.Set(origins...)
+	return o
+}
+
+// Set replaces the allowed origins, the invalid patterns are ignored.
+func (o *CORSOrigins) Set(origins ...string) {
+	compiled := compileCORSOrigins(origins)
+	compiled.any = false // "*" isn't the provider origin
+	o.origins.Store(compiled)
+}
+
+// AllowOrigin reports whether the origin is allowed.
+func (o *CORSOrigins) AllowOrigin(_ context.Context, origin string) (bool, error) {
+	origins := o.origins.Load()
+	if origins == nil {
+		return false, nil
+	}
+	return origins.match(origin, false) != "", nil
+}
+
+// CORSStats are the counters of the [CORSMetrics].
+type CORSStats struct {
+	// Allowed is the number of the requests of the allowed origins.
+	Allowed uint64 `json:"allowed"`
+
+	// Denied is the number of the requests of the origins which aren't allowed.
+	Denied uint64 `json:"denied"`
+}
+
+// CORSMetrics counts the origins of the CORS middleware, see CORSConfig.Metrics.
+//
+//	metrics := new(middleware.CORSMetrics)
+//	expvar.Publish("cors", expvar.Func(func() any { return metrics.Stats() }))
+type CORSMetrics struct {
+	allowed atomic.Uint64
+	denied  atomic.Uint64
+}
+
+// Stats returns the current counters.
+func (m *CORSMetrics) Stats() CORSStats {
+	return CORSStats{Allowed: m.allowed.Load(), Denied: m.denied.Load()}
+}
+
 func (c *CORSConfig) SetDefaults() {
 	if len(c.AllowOrigins) == 0 {
 		c.AllowOrigins = []string{"*"}
@@ -125,26 +205,8 @@ func CORS[T wo.Resolver](cfg CORSConfig, skippers ...Skipper[T]) func(T) error {
 
 	skip := ChainSkipper[T](skippers...)
 
-	allowOriginPatterns := make([]*regexp.Regexp, 0, len(cfg.AllowOrigins))
-	for _, origin := range cfg.AllowOrigins {
-		if origin == "*" {
-			continue // "*" is handled differently and does not need regexp
-		}
-		pattern := regexp.QuoteMeta(origin)
-		pattern = strings.ReplaceAll(pattern, "\\*", ".*")
-		pattern = strings.ReplaceAll(pattern, "\\?", ".")
-		pattern = "^" + pattern + "$"
-
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			// this is to preserve previous behaviour - invalid patterns were just ignored.
-			// If we would turn this to panic, users with invalid patterns
-			// would have applications crashing in production due unrecovered panic.
-			log.Println("invalid AllowOrigins pattern", origin)
-			continue
-		}
-		allowOriginPatterns = append(allowOriginPatterns, re)
-	}
+	allowOrigins := compileCORSOrigins(cfg.AllowOrigins)
+	unsafeWildcard := cfg.AllowCredentials && cfg.UnsafeWildcardOriginWithAllowCredentials
 
 	allowMethods := strings.Join(cfg.AllowMethods, ",")
 	allowHeaders := strings.Join(cfg.AllowHeaders, ",")
@@ -165,7 +227,7 @@ func CORS[T wo.Resolver](cfg CORSConfig, skippers ...Skipper[T]) func(T) error {
 		origin := req.Header.Get(wo.HeaderOrigin)
 		allowOrigin := ""
 
-		res.Header().Add(wo.HeaderVary, wo.HeaderOrigin)
+		wo.AddVary(res.Header(), wo.HeaderOrigin)
 
 		// Preflight request is an OPTIONS request, using three HTTP request headers: Access-Control-Request-Method,
 		// Access-Control-Request-Headers, and the Origin header. See: https://developer.mozilla.org/en-US/docs/Glossary/Preflight_request
@@ -181,7 +243,15 @@ func CORS[T wo.Resolver](cfg CORSConfig, skippers ...Skipper[T]) func(T) error {
 			return nil
 		}
 
-		if cfg.AllowOriginFunc != nil {
+		if cfg.OriginProvider != nil {
+			allowed, err := cfg.OriginProvider.AllowOrigin(req.Context(), origin)
+			if err != nil {
+				return err
+			}
+			if allowed {
+				allowOrigin = origin
+			}
+		} else if cfg.AllowOriginFunc != nil {
 			allowed, err := cfg.AllowOriginFunc(origin)
 			if err != nil {
 				return err
@@ -190,36 +260,14 @@ func CORS[T wo.Resolver](cfg CORSConfig, skippers ...Skipper[T]) func(T) error {
 				allowOrigin = origin
 			}
 		} else {
-			// Check allowed origins
-			for _, o := range cfg.AllowOrigins {
-				if o == "*" && cfg.AllowCredentials && cfg.UnsafeWildcardOriginWithAllowCredentials {
-					allowOrigin = origin
-					break
-				}
-				if o == "*" || o == origin {
-					allowOrigin = o
-					break
-				}
-				if matchSubdomain(origin, o) {
-					allowOrigin = origin
-					break
-				}
-			}
+			allowOrigin = allowOrigins.match(origin, unsafeWildcard)
+		}
 
-			checkPatterns := false
+		if cfg.Metrics != nil {
 			if allowOrigin == "" {
-				// to avoid regex cost by invalid (long) domains (253 is domain name max limit)
-				if len(origin) <= (253+3+5) && strings.Contains(origin, "://") {
-					checkPatterns = true
-				}
-			}
-			if checkPatterns {
-				for _, re := range allowOriginPatterns {
-					if match := re.MatchString(origin); match {
-						allowOrigin = origin
-						break
-					}
-				}
+				cfg.Metrics.denied.Add(1)
+			} else {
+				cfg.Metrics.allowed.Add(1)
 			}
 		}
 
@@ -246,8 +294,7 @@ func CORS[T wo.Resolver](cfg CORSConfig, skippers ...Skipper[T]) func(T) error {
 		}
 
 		// Preflight request
-		res.Header().Add(wo.HeaderVary, wo.HeaderAccessControlRequestMethod)
-		res.Header().Add(wo.HeaderVary, wo.HeaderAccessControlRequestHeaders)
+		wo.AddVary(res.Header(), wo.HeaderAccessControlRequestMethod, wo.HeaderAccessControlRequestHeaders)
 		res.Header().Set(wo.HeaderAccessControlAllowMethods, allowMethods)
 
 		if allowHeaders != "" {
@@ -267,6 +314,125 @@ func CORS[T wo.Resolver](cfg CORSConfig, skippers ...Skipper[T]) func(T) error {
 	}
 }
 
+// corsAllowOrigins are the allowed origins compiled once, see CORSConfig.AllowOrigins.
+type corsAllowOrigins struct {
+	any        bool
+	exact      map[string]struct{}
+	subdomains []corsSubdomain
+	patterns   []*regexp.Regexp
+}
+
+// corsSubdomain is the origin pattern with the wildcard subdomain, ex. "https://*.example.com".
+type corsSubdomain struct {
+	scheme string
+	suffix string // with the leading dot, empty for any
+}
+
+// compileCORSOrigins compiles the origins: the exact ones are looked up in the set,
+// the ones with the wildcards are matched as the subdomains or the patterns.
+func compileCORSOrigins(origins []string) *corsAllowOrigins {
+	o := &corsAllowOrigins{exact: make(map[string]struct{}, len(origins))}
+	for _, origin := range origins {
+		if origin == "*" {
+			o.any = true
+			continue
+		}
+
+		if !strings.ContainsAny(origin, "*?") {
+			o.exact[origin] = struct{}{}
+			continue
+		}
+
+		if sub, ok := compileCORSSubdomain(origin); ok {
+			o.subdomains = append(o.subdomains, sub)
+		}
+
+		re, err := corsOriginPattern(origin)
+		if err != nil {
+			// this is to preserve previous behaviour - invalid patterns were just ignored.
+			// If we would turn this to panic, users with invalid patterns
+			// would have applications crashing in production due unrecovered panic.
+			log.Println("invalid AllowOrigins pattern", origin)
+			continue
+		}
+		o.patterns = append(o.patterns, re)
+	}
+	return o
+}
+
+// match returns the Access-Control-Allow-Origin value of the origin, empty if it isn't allowed.
+func (o *corsAllowOrigins) match(origin string, unsafeWildcard bool) string {
+	if _, ok := o.exact[origin]; ok {
+		return origin
+	}
+	if o.any {
+		if unsafeWildcard {
+			return origin
+		}
+		return "*"
+	}
+
+	for _, sub := range o.subdomains {
+		if sub.match(origin) {
+			return origin
+		}
+	}
+	// to avoid regex cost by invalid (long) domains (253 is domain name max limit)
+	if len(origin) > (253+3+5) || !strings.Contains(origin, "://") {
+		return ""
+	}
+	for _, re := range o.patterns {
+		if re.MatchString(origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// compileCORSSubdomain compiles the pattern as matchSubdomain matches it: the scheme and the
+// domain components after the rightmost wildcard one.
+func compileCORSSubdomain(pattern string) (corsSubdomain, bool) {
+	pidx := strings.Index(pattern, "://")
+	if pidx == -1 {
+		return corsSubdomain{}, false
+	}
+
+	comps := strings.Split(pattern[pidx+3:], ".")
+	i := -1
+	for j, comp := range comps {
+		if comp == "*" {
+			i = j
+		}
+	}
+	if i == -1 {
+		return corsSubdomain{}, false
+	}
+
+	sub := corsSubdomain{scheme: pattern[:strings.Index(pattern, ":")]}
+	if i < len(comps)-1 {
+		sub.suffix = "." + strings.Join(comps[i+1:], ".")
+	}
+	return sub, true
+}
+
+func (s corsSubdomain) match(origin string) bool {
+	didx := strings.Index(origin, "://")
+	if didx == -1 || origin[:strings.Index(origin, ":")] != s.scheme {
+		return false
+	}
+	auth := origin[didx+3:]
+	return len(auth) <= 253 && (s.suffix == "" || strings.HasSuffix(auth, s.suffix))
+}
+
+// corsOriginPattern compiles the origin pattern, the wildcard characters '*' and '?' are
+// converted to the regex fragments '.*' and '.'.
+func corsOriginPattern(origin string) (*regexp.Regexp, error) {
+	pattern := regexp.QuoteMeta(origin)
+	pattern = strings.ReplaceAll(pattern, "\\*", ".*")
+	pattern = strings.ReplaceAll(pattern, "\\?", ".")
+	return regexp.Compile("^" + pattern + "$")
+}
+
 func matchScheme(domain, pattern string) bool {
 	didx := strings.Index(domain, ":")
 	pidx := strings.Index(pattern, ":")
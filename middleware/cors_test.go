@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/gowool/wo"
 )
@@ -419,6 +422,67 @@ func TestCORS_AllowOriginFunc(t *testing.T) {
 	}
 }
 
+func TestCORS_OriginProvider(t *testing.T) {
+	origins := NewCORSOrigins("https://tenant1.example.com", "https://*.tenant2.example.com", "*")
+	metrics := new(CORSMetrics)
+	mw := CORS[*wo.Event](CORSConfig{
+		AllowOrigins:   []string{"*"},
+		OriginProvider: origins,
+		Metrics:        metrics,
+	})
+
+	allowOrigin := func(origin string) string {
+		e := newCORSTestEvent(http.MethodGet, "/", map[string]string{wo.HeaderOrigin: origin})
+		assert.NoError(t, mw(e))
+		return e.Response().Header().Get(wo.HeaderAccessControlAllowOrigin)
+	}
+
+	assert.Equal(t, "https://tenant1.example.com", allowOrigin("https://tenant1.example.com"))
+	assert.Equal(t, "https://app.tenant2.example.com", allowOrigin("https://app.tenant2.example.com"))
+	assert.Empty(t, allowOrigin("https://tenant3.example.com"), "the wildcard origin is ignored")
+
+	origins.Set("https://tenant3.example.com")
+
+	assert.Equal(t, "https://tenant3.example.com", allowOrigin("https://tenant3.example.com"))
+	assert.Empty(t, allowOrigin("https://tenant1.example.com"))
+
+	e := newCORSTestEvent(http.MethodGet, "/", nil)
+	assert.NoError(t, mw(e))
+
+	assert.Equal(t, CORSStats{Allowed: 3, Denied: 2}, metrics.Stats())
+}
+
+func TestCORSOrigins_Zero(t *testing.T) {
+	var origins CORSOrigins
+
+	allowed, err := origins.AllowOrigin(context.Background(), "https://example.com")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	origins.Set("https://example.com")
+	allowed, err = origins.AllowOrigin(context.Background(), "https://example.com")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+type corsOriginProviderFunc func(ctx context.Context, origin string) (bool, error)
+
+func (f corsOriginProviderFunc) AllowOrigin(ctx context.Context, origin string) (bool, error) {
+	return f(ctx, origin)
+}
+
+func TestCORS_OriginProviderError(t *testing.T) {
+	errProvider := errors.New("provider error")
+	mw := CORS[*wo.Event](CORSConfig{
+		OriginProvider: corsOriginProviderFunc(func(context.Context, string) (bool, error) {
+			return false, errProvider
+		}),
+	})
+
+	e := newCORSTestEvent(http.MethodGet, "/", map[string]string{wo.HeaderOrigin: "https://example.com"})
+	assert.ErrorIs(t, mw(e), errProvider)
+}
+
 func TestCORS_SubdomainMatching(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1096,3 +1160,76 @@ func TestCORS_DefaultBehaviorIntegration(t *testing.T) {
 	assert.Equal(t, "GET,HEAD,PUT,PATCH,POST,DELETE", event.Response().Header().Get(wo.HeaderAccessControlAllowMethods))
 	assert.Equal(t, http.StatusNoContent, wo.MustUnwrapResponse(event.Response()).Status)
 }
+
+func TestCompileCORSOrigins(t *testing.T) {
+	patterns := []string{"https://*.example.com", "http://*.local:8080", "https://example.*", "https://a.*.example.org"}
+	origins := []string{
+		"https://app.example.com",
+		"https://a.b.example.com",
+		"https://example.com",
+		"http://app.example.com",
+		"https://app.example.com.evil.com",
+		"http://api.local:8080",
+		"http://api.local",
+		"https://example.org",
+		"https://x.example.org",
+		"https://" + strings.Repeat("a", 254) + ".example.com",
+	}
+
+	for _, pattern := range patterns {
+		sub, ok := compileCORSSubdomain(pattern)
+		if !assert.True(t, ok, pattern) {
+			continue
+		}
+
+		for _, origin := range origins {
+			assert.Equal(t, matchSubdomain(origin, pattern), sub.match(origin), "%s %s", pattern, origin)
+		}
+	}
+
+	_, ok := compileCORSSubdomain("https://app?.example.com")
+	assert.False(t, ok)
+}
+
+func BenchmarkCORS(b *testing.B) {
+	cfg := CORSConfig{
+		AllowOrigins: []string{
+			"https://example.com",
+			"https://app.example.com",
+			"https://*.tenant.example.com",
+			"https://preview-??.example.org",
+		},
+		AllowHeaders:  []string{wo.HeaderContentType, wo.HeaderAuthorization},
+		ExposeHeaders: []string{wo.HeaderXRequestID},
+	}
+
+	benchmarks := []struct {
+		name   string
+		method string
+		origin string
+	}{
+		{name: "exact", method: http.MethodGet, origin: "https://app.example.com"},
+		{name: "subdomain", method: http.MethodGet, origin: "https://acme.tenant.example.com"},
+		{name: "pattern", method: http.MethodGet, origin: "https://preview-42.example.org"},
+		{name: "denied", method: http.MethodGet, origin: "https://evil.example.net"},
+		{name: "preflight", method: http.MethodOptions, origin: "https://app.example.com"},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			mw := CORS[*wo.Event](cfg)
+
+			req := httptest.NewRequest(bm.method, "/", nil)
+			req.Header.Set(wo.HeaderOrigin, bm.origin)
+			rec := httptest.NewRecorder()
+			e := new(wo.Event)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				clear(rec.Header())
+				e.Reset(rec, req)
+				_ = mw(e)
+			}
+		})
+	}
+}
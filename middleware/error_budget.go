@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/clock"
+)
+
+const errorBudgetBuckets = 10
+
+// ErrRouteShed is returned for the requests shed by the [ErrorBudget] middleware.
+var ErrRouteShed = wo.NewHTTPError(http.StatusServiceUnavailable, "route is temporarily unavailable")
+
+type ErrorBudgetConfig[T wo.Resolver] struct {
+	// ErrorRate is the share of the failed requests (5xx responses and panics) within Window
+	// above which the route starts shedding.
+	//
+	// Default: 0.5
+	ErrorRate float64 `env:"ERROR_RATE" json:"errorRate,omitempty" yaml:"errorRate,omitempty"`
+
+	// MinSamples is the minimum number of requests within Window required to start shedding.
+	//
+	// Default: 20
+	MinSamples int `env:"MIN_SAMPLES" json:"minSamples,omitempty" yaml:"minSamples,omitempty"`
+
+	// Window is the rolling window of the error rate.
+	//
+	// Default: 30s
+	Window time.Duration `env:"WINDOW" json:"window,omitempty,format:units" yaml:"window,omitempty"`
+
+	// ShedFraction is the share of the requests rejected while the route is shedding.
+	//
+	// Default: 0.5
+	ShedFraction float64 `env:"SHED_FRACTION" json:"shedFraction,omitempty" yaml:"shedFraction,omitempty"`
+
+	// ProbeInterval is the interval at which a request is let through as a recovery probe
+	// while the route is shedding, regardless of ShedFraction.
+	//
+	// Default: 1s
+	ProbeInterval time.Duration `env:"PROBE_INTERVAL" json:"probeInterval,omitempty,format:units" yaml:"probeInterval,omitempty"`
+
+	// RecoveryProbes is the number of the consecutive successful requests let through
+	// while the route is shedding which recover the route.
+	//
+	// Default: 5
+	RecoveryProbes int `env:"RECOVERY_PROBES" json:"recoveryProbes,omitempty" yaml:"recoveryProbes,omitempty"`
+
+	// RetryAfter is the Retry-After header value of the shed requests.
+	//
+	// Default: 5s
+	RetryAfter time.Duration `env:"RETRY_AFTER" json:"retryAfter,omitempty,format:units" yaml:"retryAfter,omitempty"`
+
+	// Key returns the key the error rate is tracked by, the requests of the empty key aren't
+	// tracked. The keys must be bounded, ex. not the request paths, as the state of each key is kept.
+	//
+	// Default: the route pattern, the requests which are not routed aren't tracked
+	Key func(e T) string `json:"-" yaml:"-"`
+
+	// Clock is the clock of the rolling window and the probes.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+func (c *ErrorBudgetConfig[T]) SetDefaults() {
+	if c.ErrorRate <= 0 {
+		c.ErrorRate = 0.5
+	}
+	if c.MinSamples <= 0 {
+		c.MinSamples = 20
+	}
+	if c.Window <= 0 {
+		c.Window = 30 * time.Second
+	}
+	if c.ShedFraction <= 0 {
+		c.ShedFraction = 0.5
+	}
+	if c.ProbeInterval <= 0 {
+		c.ProbeInterval = time.Second
+	}
+	if c.RecoveryProbes <= 0 {
+		c.RecoveryProbes = 5
+	}
+	if c.RetryAfter <= 0 {
+		c.RetryAfter = 5 * time.Second
+	}
+	if c.Key == nil {
+		c.Key = func(e T) string {
+			return e.Request().Pattern
+		}
+	}
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+}
+
+func (c *ErrorBudgetConfig[T]) Validate() error {
+	if c.ErrorRate > 1 {
+		return errors.New("error_budget: error rate must be within (0, 1]")
+	}
+	if c.ShedFraction > 1 {
+		return errors.New("error_budget: shed fraction must be within (0, 1]")
+	}
+	return nil
+}
+
+// ErrorBudget tracks the rolling error rate per route and sheds (with [ErrRouteShed], 503)
+// a fraction of the traffic to the route once the error rate exceeds the budget, protecting
+// the resources the failing route shares with the others. The requests let through while
+// shedding are the recovery probes: the route recovers after enough consecutive successes.
+func ErrorBudget[T wo.Resolver](cfg ErrorBudgetConfig[T], skippers ...Skipper[T]) func(T) error {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	skip := ChainSkipper[T](skippers...)
+	retryAfter := strconv.Itoa(max(1, int(cfg.RetryAfter.Round(time.Second).Seconds())))
+
+	policy := budgetPolicy{
+		errorRate:      cfg.ErrorRate,
+		minSamples:     cfg.MinSamples,
+		window:         cfg.Window,
+		shedFraction:   cfg.ShedFraction,
+		probeInterval:  cfg.ProbeInterval,
+		recoveryProbes: cfg.RecoveryProbes,
+	}
+
+	var routes sync.Map
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		key := cfg.Key(e)
+		if key == "" {
+			return e.Next()
+		}
+
+		v, _ := routes.LoadOrStore(key, new(routeBudget))
+		budget := v.(*routeBudget)
+
+		now := cfg.Clock.Now()
+		shedding, admit := budget.admit(policy, now)
+		if !admit {
+			e.Response().Header().Set(wo.HeaderRetryAfter, retryAfter)
+			return ErrRouteShed
+		}
+
+		failed := true
+		defer func() {
+			// a panic counts as a failure
+			if failed {
+				budget.observe(policy, cfg.Clock.Now(), shedding, false)
+			}
+		}()
+
+		err := e.Next()
+		failed = false
+		budget.observe(policy, cfg.Clock.Now(), shedding, responseStatus(e.Response(), err) < http.StatusInternalServerError)
+		return err
+	}
+}
+
+type budgetPolicy struct {
+	errorRate      float64
+	minSamples     int
+	window         time.Duration
+	shedFraction   float64
+	probeInterval  time.Duration
+	recoveryProbes int
+}
+
+type budgetBucket struct {
+	idx    int64
+	total  int
+	errors int
+}
+
+// routeBudget is the error budget state of a route.
+type routeBudget struct {
+	buckets   [errorBudgetBuckets]budgetBucket
+	shedding  bool
+	successes int
+	lastProbe time.Time
+	mu        sync.Mutex
+}
+
+// admit reports whether the route is shedding and whether the request is let through.
+func (b *routeBudget) admit(p budgetPolicy, now time.Time) (shedding, admit bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.shedding {
+		return false, true
+	}
+	if now.Sub(b.lastProbe) >= p.probeInterval {
+		b.lastProbe = now
+		return true, true
+	}
+	return true, rand.Float64() >= p.shedFraction //nolint:gosec // Not a concern
+}
+
+func (b *routeBudget) observe(p budgetPolicy, now time.Time, probe, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucketSize := max(1, int64(p.window/errorBudgetBuckets))
+	idx := now.UnixNano() / bucketSize
+	bucket := &b.buckets[idx%errorBudgetBuckets]
+	if bucket.idx != idx {
+		*bucket = budgetBucket{idx: idx}
+	}
+	bucket.total++
+	if !success {
+		bucket.errors++
+	}
+
+	if b.shedding {
+		// the requests admitted before the route started shedding aren't probes
+		if !probe {
+			return
+		}
+		if !success {
+			b.successes = 0
+			return
+		}
+		if b.successes++; b.successes >= p.recoveryProbes {
+			b.shedding = false
+			b.successes = 0
+			b.buckets = [errorBudgetBuckets]budgetBucket{}
+		}
+		return
+	}
+
+	var total, errs int
+	for _, bucket := range b.buckets {
+		if idx-bucket.idx < errorBudgetBuckets {
+			total += bucket.total
+			errs += bucket.errors
+		}
+	}
+	if total >= p.minSamples && float64(errs)/float64(total) > p.errorRate {
+		b.shedding = true
+		b.successes = 0
+		b.lastProbe = now
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/clock"
+)
+
+func newErrorBudgetHandler(t *testing.T, cfg ErrorBudgetConfig[*wo.Event], failing *atomic.Bool) http.Handler {
+	t.Helper()
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, func(e *wo.Event, err error) {
+		e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+	})
+	router.BindFunc(ErrorBudget(cfg))
+	router.GET("/items", func(e *wo.Event) error {
+		if failing.Load() {
+			return wo.ErrInternalServerError
+		}
+		return e.NoContent(http.StatusNoContent)
+	})
+	router.GET("/other", func(e *wo.Event) error {
+		return e.NoContent(http.StatusNoContent)
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+	return h
+}
+
+func serveErrorBudget(h http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestErrorBudget_ShedsFailingRoute(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+
+	h := newErrorBudgetHandler(t, ErrorBudgetConfig[*wo.Event]{
+		MinSamples:    4,
+		ShedFraction:  1,
+		ProbeInterval: time.Hour,
+		RetryAfter:    3 * time.Second,
+	}, &failing)
+
+	for range 4 {
+		assert.Equal(t, http.StatusInternalServerError, serveErrorBudget(h, "/items").Code)
+	}
+
+	rec := serveErrorBudget(h, "/items")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get(wo.HeaderRetryAfter))
+
+	// the other routes have their own budget
+	assert.Equal(t, http.StatusNoContent, serveErrorBudget(h, "/other").Code)
+}
+
+func TestErrorBudget_BelowThreshold(t *testing.T) {
+	var failing atomic.Bool
+
+	h := newErrorBudgetHandler(t, ErrorBudgetConfig[*wo.Event]{MinSamples: 4, ShedFraction: 1}, &failing)
+
+	for i := range 20 {
+		failing.Store(i%4 == 0)
+		serveErrorBudget(h, "/items")
+	}
+
+	failing.Store(false)
+	assert.Equal(t, http.StatusNoContent, serveErrorBudget(h, "/items").Code)
+}
+
+func TestErrorBudget_Recovery(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	h := newErrorBudgetHandler(t, ErrorBudgetConfig[*wo.Event]{
+		MinSamples:     2,
+		ShedFraction:   1,
+		ProbeInterval:  10 * time.Millisecond,
+		RecoveryProbes: 2,
+		Clock:          clk,
+	}, &failing)
+
+	for range 2 {
+		serveErrorBudget(h, "/items")
+	}
+	require.Equal(t, http.StatusServiceUnavailable, serveErrorBudget(h, "/items").Code)
+
+	// the failing probe keeps the route shedding
+	clk.Add(10 * time.Millisecond)
+	assert.Equal(t, http.StatusInternalServerError, serveErrorBudget(h, "/items").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, serveErrorBudget(h, "/items").Code)
+
+	failing.Store(false)
+	for range 2 {
+		clk.Add(10 * time.Millisecond)
+		assert.Equal(t, http.StatusNoContent, serveErrorBudget(h, "/items").Code)
+	}
+
+	// recovered
+	for range 5 {
+		assert.Equal(t, http.StatusNoContent, serveErrorBudget(h, "/items").Code)
+	}
+}
+
+func TestErrorBudget_EmptyKey(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+
+	h := newErrorBudgetHandler(t, ErrorBudgetConfig[*wo.Event]{
+		MinSamples:   2,
+		ShedFraction: 1,
+		Key:          func(*wo.Event) string { return "" },
+	}, &failing)
+
+	for range 4 {
+		assert.Equal(t, http.StatusInternalServerError, serveErrorBudget(h, "/items").Code)
+	}
+}
+
+func TestErrorBudget_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() { ErrorBudget(ErrorBudgetConfig[*wo.Event]{ErrorRate: 2}) })
+	assert.Panics(t, func() { ErrorBudget(ErrorBudgetConfig[*wo.Event]{ShedFraction: 2}) })
+}
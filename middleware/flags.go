@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/flags"
+	"github.com/gowool/wo/internal/security"
+)
+
+type FlagsConfig[T wo.Resolver] struct {
+	// Flags are the names of the flags evaluated for each request.
+	Flags []string `env:"FLAGS" json:"flags,omitempty" yaml:"flags,omitempty"`
+
+	// Identity returns the identity the rollouts and the variants are sticky to, ex. the user ID.
+	//
+	// Default: the random ID kept in the CookieName cookie
+	Identity func(e T) string `json:"-" yaml:"-"`
+
+	// CookieName is the cookie keeping the random identity of the default Identity, so the client
+	// keeps the decisions across the networks, unlike by its IP address.
+	//
+	// Default: flags_id
+	CookieName string `env:"COOKIE_NAME" json:"cookieName,omitempty" yaml:"cookieName,omitempty"`
+
+	// Logger logs the provider errors, the flags which failed to be provided are off.
+	//
+	// Default: nil
+	Logger ErrorLogger `json:"-" yaml:"-"`
+}
+
+func (c *FlagsConfig[T]) SetDefaults() {
+	if c.CookieName == "" {
+		c.CookieName = "flags_id"
+	}
+	if c.Identity == nil {
+		name := c.CookieName
+		c.Identity = func(e T) string {
+			if cookie, err := e.Request().Cookie(name); err == nil && cookie.Value != "" {
+				return cookie.Value
+			}
+
+			id, err := security.Token()
+			if err != nil {
+				return ""
+			}
+			http.SetCookie(e.Response(), &http.Cookie{
+				Name:     name,
+				Value:    id,
+				Path:     "/",
+				MaxAge:   int(flagsCookieMaxAge.Seconds()),
+				Secure:   e.Request().TLS != nil,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+			return id
+		}
+	}
+}
+
+// flagsCookieMaxAge is the max age of the identity cookie, the browsers cap it at 400 days.
+const flagsCookieMaxAge = 400 * 24 * time.Hour
+
+// Flags evaluates the configured feature flags of the provider for the request identity and carries
+// them in the request context, see [flags.FromContext] and [wo.Event.Flag]. The flags which don't
+// exist are off.
+func Flags[T wo.Resolver](cfg FlagsConfig[T], provider flags.Provider, skippers ...Skipper[T]) func(T) error {
+	if provider == nil {
+		panic("flags middleware: provider is nil")
+	}
+
+	cfg.SetDefaults()
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		ctx := e.Request().Context()
+		identity := cfg.Identity(e)
+
+		values := make(flags.Values, len(cfg.Flags))
+		for _, name := range cfg.Flags {
+			f, found, err := provider.Flag(ctx, name)
+			if err != nil && cfg.Logger != nil {
+				cfg.Logger.Error("failed to provide feature flag", "flag", name, "error", err)
+			}
+			if err != nil || !found {
+				values[name] = flags.Value{}
+				continue
+			}
+			values[name] = flags.Evaluate(f, identity)
+		}
+
+		e.SetRequest(e.Request().WithContext(flags.WithValues(ctx, values)))
+		return e.Next()
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/flags"
+)
+
+type errorFlagProvider struct{}
+
+func (errorFlagProvider) Flag(context.Context, string) (flags.Flag, bool, error) {
+	return flags.Flag{}, false, errors.New("provider error")
+}
+
+type recordingErrorLogger struct {
+	messages []string
+}
+
+func (l *recordingErrorLogger) Error(msg string, _ ...any) {
+	l.messages = append(l.messages, msg)
+}
+
+func TestFlags(t *testing.T) {
+	provider := flags.NewStaticProvider(
+		flags.Flag{Name: "on", Enabled: true, Percentage: flags.All, Value: "v2"},
+		flags.Flag{Name: "off", Default: "v1"},
+		flags.Flag{Name: "staff", Enabled: true, Percentage: 0.01, Allow: []string{"admin"}},
+	)
+
+	mw := Flags[*wo.Event](FlagsConfig[*wo.Event]{
+		Flags: []string{"on", "off", "staff", "missing"},
+		Identity: func(e *wo.Event) string {
+			return e.Request().Header.Get("X-User")
+		},
+	}, provider)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-User", "admin")
+	e := new(wo.Event)
+	e.Reset(httptest.NewRecorder(), req)
+
+	require.NoError(t, mw(e))
+	assert.True(t, e.Flag("on"))
+	assert.Equal(t, "v2", e.FlagString("on"))
+	assert.False(t, e.Flag("off"))
+	assert.Equal(t, "v1", e.FlagString("off"))
+	assert.True(t, e.Flag("staff"))
+	assert.False(t, e.Flag("missing"))
+	assert.False(t, e.Flag("unconfigured"))
+	assert.Len(t, flags.FromContext(e.Request().Context()), 4)
+}
+
+func TestFlags_DefaultIdentity(t *testing.T) {
+	provider := flags.NewStaticProvider(flags.Flag{Name: "rollout", Enabled: true, Percentage: 50})
+	mw := Flags[*wo.Event](FlagsConfig[*wo.Event]{Flags: []string{"rollout"}}, provider)
+
+	rec := httptest.NewRecorder()
+	e := new(wo.Event)
+	e.Reset(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, mw(e))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "flags_id", cookies[0].Name)
+	assert.NotEmpty(t, cookies[0].Value)
+	assert.True(t, cookies[0].HttpOnly)
+	enabled := e.Flag("rollout")
+
+	// the identity is sticky to the cookie, not to the remote address
+	for _, addr := range []string{"192.0.2.1:1234", "198.51.100.2:5678"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = addr
+		req.AddCookie(cookies[0])
+
+		rec := httptest.NewRecorder()
+		e := new(wo.Event)
+		e.Reset(rec, req)
+		require.NoError(t, mw(e))
+
+		assert.Equal(t, enabled, e.Flag("rollout"))
+		assert.Empty(t, rec.Result().Cookies())
+	}
+}
+
+func TestFlags_ProviderError(t *testing.T) {
+	logger := new(recordingErrorLogger)
+	mw := Flags[*wo.Event](FlagsConfig[*wo.Event]{Flags: []string{"a"}, Logger: logger}, errorFlagProvider{})
+
+	e := new(wo.Event)
+	e.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.NoError(t, mw(e))
+	assert.False(t, e.Flag("a"))
+	assert.Equal(t, []string{"failed to provide feature flag"}, logger.messages)
+}
+
+func TestFlags_Skipper(t *testing.T) {
+	mw := Flags[*wo.Event](FlagsConfig[*wo.Event]{Flags: []string{"a"}}, flags.NewStaticProvider(flags.Flag{Name: "a", Enabled: true}), func(*wo.Event) bool { return true })
+
+	e := new(wo.Event)
+	e.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.NoError(t, mw(e))
+	assert.Nil(t, flags.FromContext(e.Request().Context()))
+}
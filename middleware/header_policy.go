@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+
+	"github.com/gowool/wo"
+)
+
+type HeaderPolicyConfig struct {
+	// StripHopByHop removes the hop-by-hop headers, ex. Connection and Keep-Alive, and the ones
+	// the Connection header lists from the request and the response, so the handlers proxying
+	// them don't pass them on. The upgrade requests and the 101 Switching Protocols responses
+	// keep the Connection and Upgrade headers, the responses keep the Trailer one.
+	StripHopByHop bool `env:"STRIP_HOP_BY_HOP" json:"stripHopByHop,omitempty" yaml:"stripHopByHop,omitempty"`
+
+	// RemoveResponseHeaders are removed from the response just before it's written, ex. the
+	// ones disclosing the stack.
+	//
+	// Default: [Server, X-Powered-By]
+	RemoveResponseHeaders []string `env:"REMOVE_RESPONSE_HEADERS" json:"removeResponseHeaders,omitempty" yaml:"removeResponseHeaders,omitempty"`
+
+	// ResponseHeaders are set on the response overriding the ones of the handler,
+	// ex. {"Cache-Control": "no-store"}.
+	//
+	// Optional.
+	ResponseHeaders map[string]string `env:"RESPONSE_HEADERS" json:"responseHeaders,omitempty" yaml:"responseHeaders,omitempty"`
+
+	// RequiredRequestHeaders are the headers the requests must have, the others get
+	// [wo.ErrBadRequest], ex. "X-Request-Id" on the internal API group.
+	//
+	// Optional.
+	RequiredRequestHeaders []string `env:"REQUIRED_REQUEST_HEADERS" json:"requiredRequestHeaders,omitempty" yaml:"requiredRequestHeaders,omitempty"`
+}
+
+func (c *HeaderPolicyConfig) SetDefaults() {
+	if len(c.RemoveResponseHeaders) == 0 {
+		c.RemoveResponseHeaders = []string{wo.HeaderServer, "X-Powered-By"}
+	}
+}
+
+func (c *HeaderPolicyConfig) Validate() error {
+	for _, name := range slices.Concat(c.RemoveResponseHeaders, c.RequiredRequestHeaders) {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Errorf("header policy: invalid header name %q", name)
+		}
+	}
+	for name, value := range c.ResponseHeaders {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Errorf("header policy: invalid header name %q", name)
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return fmt.Errorf("header policy: invalid value of header %q", name)
+		}
+		if slices.ContainsFunc(c.RemoveResponseHeaders, func(removed string) bool { return strings.EqualFold(removed, name) }) {
+			return fmt.Errorf("header policy: header %q is both set and removed", name)
+		}
+	}
+	if c.StripHopByHop && slices.ContainsFunc(c.RequiredRequestHeaders, isHopHeader) {
+		return errors.New("header policy: the required hop-by-hop headers are stripped")
+	}
+	return nil
+}
+
+// HeaderPolicy enforces the declarative header policy of the requests and the responses, ex.
+// per the pipeline group: it strips the hop-by-hop headers, removes the headers disclosing the
+// stack, forces the response headers and requires the request headers.
+//
+//	api.BindFunc(middleware.HeaderPolicy[*wo.Event](middleware.HeaderPolicyConfig{
+//		StripHopByHop:          true,
+//		ResponseHeaders:        map[string]string{"Cache-Control": "no-store"},
+//		RequiredRequestHeaders: []string{"X-Request-Id"},
+//	}))
+func HeaderPolicy[T wo.Resolver](cfg HeaderPolicyConfig, skippers ...Skipper[T]) func(T) error {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	removed := make([]string, 0, len(cfg.RemoveResponseHeaders))
+	for _, name := range cfg.RemoveResponseHeaders {
+		removed = append(removed, http.CanonicalHeaderKey(name))
+	}
+
+	forced := make(http.Header, len(cfg.ResponseHeaders))
+	for name, value := range cfg.ResponseHeaders {
+		forced.Set(name, value)
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		r := e.Request()
+
+		if cfg.StripHopByHop {
+			if httpguts.HeaderValuesContainsToken(r.Header[wo.HeaderConnection], "upgrade") && r.Header.Get(wo.HeaderUpgrade) != "" {
+				stripHopHeaders(r.Header, wo.HeaderConnection, wo.HeaderUpgrade)
+			} else {
+				stripHopHeaders(r.Header)
+			}
+		}
+
+		res := wo.MustUnwrapResponse(e.Response())
+		res.Before(func() {
+			h := res.Header()
+
+			if cfg.StripHopByHop {
+				if res.Status == http.StatusSwitchingProtocols {
+					stripHopHeaders(h, wo.HeaderConnection, wo.HeaderUpgrade, "Trailer")
+				} else {
+					stripHopHeaders(h, "Trailer")
+				}
+			}
+			for _, name := range removed {
+				h.Del(name)
+			}
+			for name, values := range forced {
+				h[name] = values
+			}
+		})
+
+		for _, name := range cfg.RequiredRequestHeaders {
+			if r.Header.Get(name) == "" {
+				return wo.ErrBadRequest.WithMessage(fmt.Sprintf("missing required header %s", name))
+			}
+		}
+		return e.Next()
+	}
+}
+
+// stripHopHeaders removes the hop-by-hop headers and the ones the Connection header lists,
+// except the kept ones.
+func stripHopHeaders(h http.Header, keep ...string) {
+	for _, value := range h[wo.HeaderConnection] {
+		for name := range strings.SplitSeq(value, ",") {
+			if name = http.CanonicalHeaderKey(textproto.TrimString(name)); name != "" && !slices.Contains(keep, name) {
+				h.Del(name)
+			}
+		}
+	}
+	for _, name := range hopHeaders {
+		if !slices.Contains(keep, name) {
+			h.Del(name)
+		}
+	}
+}
+
+func isHopHeader(name string) bool {
+	return slices.Contains(hopHeaders, http.CanonicalHeaderKey(name))
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestHeaderPolicy(t *testing.T) {
+	var received http.Header
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+	router.Bind(&hook.Handler[*wo.Event]{Func: HeaderPolicy[*wo.Event](HeaderPolicyConfig{
+		StripHopByHop:          true,
+		ResponseHeaders:        map[string]string{"cache-control": "no-store"},
+		RequiredRequestHeaders: []string{wo.HeaderXRequestID},
+	})})
+	router.GET("/", func(e *wo.Event) error {
+		received = e.Request().Header.Clone()
+
+		h := e.Response().Header()
+		h.Set(wo.HeaderServer, "nginx")
+		h.Set("X-Powered-By", "PHP")
+		h.Set(wo.HeaderCacheControl, "max-age=60")
+		h.Set(wo.HeaderConnection, "close, X-Internal")
+		h.Set("X-Internal", "1")
+		h.Set("Keep-Alive", "timeout=5")
+		h.Set("Trailer", "X-Checksum")
+		return e.NoContent(http.StatusOK)
+	})
+	router.GET("/ws", func(e *wo.Event) error {
+		received = e.Request().Header.Clone()
+
+		e.Response().Header().Set(wo.HeaderConnection, "Upgrade")
+		e.Response().Header().Set(wo.HeaderUpgrade, "websocket")
+		return e.NoContent(http.StatusSwitchingProtocols)
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	t.Run("request and response", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(wo.HeaderXRequestID, "1")
+		req.Header.Set(wo.HeaderConnection, "keep-alive, X-Debug")
+		req.Header.Set("X-Debug", "1")
+		req.Header.Set("Proxy-Authorization", "Basic Zm9vOmJhcg==")
+		req.Header.Set(wo.HeaderUpgrade, "h2c")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", received.Get(wo.HeaderXRequestID))
+		for _, name := range []string{wo.HeaderConnection, "X-Debug", "Proxy-Authorization", wo.HeaderUpgrade} {
+			assert.Empty(t, received.Values(name), name)
+		}
+
+		assert.Equal(t, []string{"no-store"}, rec.Header().Values(wo.HeaderCacheControl))
+		assert.Equal(t, "X-Checksum", rec.Header().Get("Trailer"))
+		for _, name := range []string{wo.HeaderServer, "X-Powered-By", wo.HeaderConnection, "X-Internal", "Keep-Alive"} {
+			assert.Empty(t, rec.Header().Values(name), name)
+		}
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		req.Header.Set(wo.HeaderXRequestID, "1")
+		req.Header.Set(wo.HeaderConnection, "Upgrade")
+		req.Header.Set(wo.HeaderUpgrade, "websocket")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusSwitchingProtocols, rec.Code)
+		assert.Equal(t, "Upgrade", received.Get(wo.HeaderConnection))
+		assert.Equal(t, "websocket", received.Get(wo.HeaderUpgrade))
+		assert.Equal(t, "Upgrade", rec.Header().Get(wo.HeaderConnection))
+		assert.Equal(t, "websocket", rec.Header().Get(wo.HeaderUpgrade))
+	})
+
+	t.Run("missing required header", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get(wo.HeaderCacheControl))
+	})
+}
+
+func TestHeaderPolicy_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config HeaderPolicyConfig
+	}{
+		{name: "invalid removed name", config: HeaderPolicyConfig{RemoveResponseHeaders: []string{"X Powered"}}},
+		{name: "invalid required name", config: HeaderPolicyConfig{RequiredRequestHeaders: []string{"X:Id"}}},
+		{name: "invalid value", config: HeaderPolicyConfig{ResponseHeaders: map[string]string{"X-Frame-Options": "DENY\r\nX-Evil: 1"}}},
+		{name: "set and removed", config: HeaderPolicyConfig{ResponseHeaders: map[string]string{"server": "wo"}}},
+		{name: "required hop-by-hop", config: HeaderPolicyConfig{StripHopByHop: true, RequiredRequestHeaders: []string{"keep-alive"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Panics(t, func() { HeaderPolicy[*wo.Event](tt.config) })
+		})
+	}
+}
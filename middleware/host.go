@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gowool/wo"
+)
+
+// The policies of the duplicate single-value headers, see [HostConfig.DuplicateHeaders].
+const (
+	DuplicateHeadersReject = "reject"
+	DuplicateHeadersFirst  = "first"
+	DuplicateHeadersLast   = "last"
+	DuplicateHeadersAllow  = "allow"
+)
+
+type HostConfig struct {
+	// AllowedHosts are the hosts the requests may be addressed to, the exact ones, ex.
+	// "example.com", or the wildcard ones matching any subdomain, ex. "*.example.com", which
+	// doesn't match "example.com" itself. The hosts without the port match any port.
+	AllowedHosts []string `env:"ALLOWED_HOSTS" json:"allowedHosts,omitempty" yaml:"allowedHosts,omitempty"`
+
+	// ForwardedHost validates the X-Forwarded-Host header against the allowed hosts too, ex.
+	// behind the proxy passing it through, so the URLs built from it can be trusted.
+	ForwardedHost bool `env:"FORWARDED_HOST" json:"forwardedHost,omitempty" yaml:"forwardedHost,omitempty"`
+
+	// SingleValueHeaders are the headers which must have a single value, the duplicates
+	// are handled per DuplicateHeaders. The smuggled duplicates may be read differently by
+	// the proxies and the application, ex. the first by one and the last by the other.
+	//
+	// Default: [Content-Type, Authorization, Origin, Referer, X-Forwarded-Host, X-Forwarded-Proto, X-Real-Ip]
+	SingleValueHeaders []string `env:"SINGLE_VALUE_HEADERS" json:"singleValueHeaders,omitempty" yaml:"singleValueHeaders,omitempty"`
+
+	// DuplicateHeaders is the policy of the duplicate single-value headers: "reject" the
+	// request, keep the "first" or the "last" value, or "allow" them as is.
+	//
+	// Default: "reject"
+	DuplicateHeaders string `env:"DUPLICATE_HEADERS" json:"duplicateHeaders,omitempty" yaml:"duplicateHeaders,omitempty"`
+}
+
+func (c *HostConfig) SetDefaults() {
+	if len(c.SingleValueHeaders) == 0 {
+		c.SingleValueHeaders = []string{
+			wo.HeaderContentType,
+			wo.HeaderAuthorization,
+			wo.HeaderOrigin,
+			"Referer",
+			wo.HeaderXForwardedHost,
+			wo.HeaderXForwardedProto,
+			wo.HeaderXRealIP,
+		}
+	}
+	if c.DuplicateHeaders == "" {
+		c.DuplicateHeaders = DuplicateHeadersReject
+	}
+}
+
+func (c *HostConfig) Validate() error {
+	if len(c.AllowedHosts) == 0 {
+		return errors.New("host: allowed hosts are required")
+	}
+	for _, host := range c.AllowedHosts {
+		if _, err := parseHostPattern(host); err != nil {
+			return err
+		}
+	}
+	switch c.DuplicateHeaders {
+	case DuplicateHeadersReject, DuplicateHeadersFirst, DuplicateHeadersLast, DuplicateHeadersAllow:
+	default:
+		return fmt.Errorf("host: unknown duplicate headers policy %q", c.DuplicateHeaders)
+	}
+	return nil
+}
+
+// Host rejects the requests addressed to the hosts which aren't allowed, against the Host
+// header injection, ex. the password reset links built from the attacker's host. The malformed
+// hosts, ex. too long ones or the ones with the invalid characters, get [wo.ErrBadRequest],
+// the ones not allowed get [wo.ErrMisdirectedRequest]. The duplicates of the single-value
+// headers are rejected or normalized per the policy.
+//
+//	r.Pre(&hook.Handler[*wo.Event]{Func: middleware.Host[*wo.Event](middleware.HostConfig{
+//		AllowedHosts: []string{"example.com", "*.example.com", "localhost:8080"},
+//	})})
+func Host[T wo.Resolver](cfg HostConfig, skippers ...Skipper[T]) func(T) error {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	patterns := make([]hostPattern, 0, len(cfg.AllowedHosts))
+	for _, host := range cfg.AllowedHosts {
+		p, _ := parseHostPattern(host)
+		patterns = append(patterns, p)
+	}
+
+	headers := make([]string, 0, len(cfg.SingleValueHeaders))
+	for _, name := range cfg.SingleValueHeaders {
+		headers = append(headers, http.CanonicalHeaderKey(name))
+	}
+
+	check := func(hostport string) error {
+		name, port, ok := splitHost(strings.ToLower(hostport))
+		if !ok {
+			return wo.ErrBadRequest.WithMessage("invalid host")
+		}
+		for _, p := range patterns {
+			if p.match(name, port) {
+				return nil
+			}
+		}
+		return wo.ErrMisdirectedRequest.WithInternal(fmt.Errorf("host %q is not allowed", hostport))
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		r := e.Request()
+
+		if err := check(r.Host); err != nil {
+			return err
+		}
+
+		if cfg.DuplicateHeaders != DuplicateHeadersAllow {
+			for _, name := range headers {
+				values := r.Header[name]
+				if len(values) < 2 {
+					continue
+				}
+				switch cfg.DuplicateHeaders {
+				case DuplicateHeadersFirst:
+					r.Header[name] = values[:1]
+				case DuplicateHeadersLast:
+					r.Header[name] = values[len(values)-1:]
+				default:
+					return wo.ErrBadRequest.WithMessage("duplicate " + name + " header")
+				}
+			}
+		}
+
+		if cfg.ForwardedHost {
+			for _, value := range r.Header.Values(wo.HeaderXForwardedHost) {
+				for host := range strings.SplitSeq(value, ",") {
+					if err := check(strings.TrimSpace(host)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return e.Next()
+	}
+}
+
+type hostPattern struct {
+	name     string // without the "*." of the wildcard
+	port     string // empty for any
+	wildcard bool
+}
+
+func parseHostPattern(s string) (hostPattern, error) {
+	var p hostPattern
+
+	s = strings.ToLower(strings.TrimSpace(s))
+	if rest, ok := strings.CutPrefix(s, "*."); ok {
+		p.wildcard, s = true, rest
+	}
+
+	name, port, ok := splitHost(s)
+	if !ok {
+		return p, fmt.Errorf("host: invalid allowed host %q", s)
+	}
+	p.name, p.port = name, port
+	return p, nil
+}
+
+func (p hostPattern) match(name, port string) bool {
+	if p.port != "" && p.port != port {
+		return false
+	}
+	if p.wildcard {
+		return len(name) > len(p.name)+1 && strings.HasSuffix(name, p.name) && name[len(name)-len(p.name)-1] == '.'
+	}
+	return name == p.name
+}
+
+// maxHostLength is the maximum length of the DNS name.
+const maxHostLength = 253
+
+// splitHost returns the name and the port of the host, without the trailing dot of the fully
+// qualified name. It reports false for the malformed hosts: empty, too long, with the characters
+// not allowed in the DNS names or the IP addresses, or with the invalid port.
+func splitHost(host string) (name, port string, ok bool) {
+	name = host
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && i > strings.LastIndexByte(host, ']') {
+		name, port = host[:i], host[i+1:]
+		if port == "" || len(port) > 5 || strings.Trim(port, "0123456789") != "" {
+			return "", "", false
+		}
+	}
+
+	if strings.HasPrefix(name, "[") {
+		// IPv6
+		if len(name) < 3 || !strings.HasSuffix(name, "]") || strings.Trim(name[1:len(name)-1], "0123456789abcdef:.") != "" {
+			return "", "", false
+		}
+		return name, port, true
+	}
+
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > maxHostLength || strings.Contains(name, "..") {
+		return "", "", false
+	}
+	for i := range len(name) {
+		c := name[i]
+		if ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '.' || c == '_' {
+			continue
+		}
+		return "", "", false
+	}
+	return name, port, true
+}
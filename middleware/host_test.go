@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestHost(t *testing.T) {
+	mw := Host[*wo.Event](HostConfig{
+		AllowedHosts:  []string{"example.com", "*.example.org", "localhost:8080", "[::1]"},
+		ForwardedHost: true,
+	})
+
+	tests := []struct {
+		name           string
+		host           string
+		forwardedHost  string
+		expectedStatus int
+	}{
+		{name: "exact", host: "example.com"},
+		{name: "exact any port", host: "example.com:8443"},
+		{name: "case insensitive", host: "EXAMPLE.com"},
+		{name: "fully qualified", host: "example.com."},
+		{name: "wildcard", host: "api.example.org"},
+		{name: "wildcard nested", host: "a.b.example.org"},
+		{name: "wildcard apex", host: "example.org", expectedStatus: http.StatusMisdirectedRequest},
+		{name: "wildcard suffix only", host: "evilexample.org", expectedStatus: http.StatusMisdirectedRequest},
+		{name: "port", host: "localhost:8080"},
+		{name: "other port", host: "localhost:9090", expectedStatus: http.StatusMisdirectedRequest},
+		{name: "ipv6", host: "[::1]:443"},
+		{name: "not allowed", host: "evil.com", expectedStatus: http.StatusMisdirectedRequest},
+		{name: "empty", host: "", expectedStatus: http.StatusBadRequest},
+		{name: "invalid characters", host: "example.com/evil", expectedStatus: http.StatusBadRequest},
+		{name: "invalid port", host: "example.com:80a", expectedStatus: http.StatusBadRequest},
+		{name: "empty label", host: "a..example.org", expectedStatus: http.StatusBadRequest},
+		{name: "too long", host: string(make([]byte, 300)), expectedStatus: http.StatusBadRequest},
+		{name: "forwarded host", host: "example.com", forwardedHost: "api.example.org"},
+		{name: "forwarded host not allowed", host: "example.com", forwardedHost: "example.com, evil.com", expectedStatus: http.StatusMisdirectedRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tt.host
+			if tt.forwardedHost != "" {
+				req.Header.Set(wo.HeaderXForwardedHost, tt.forwardedHost)
+			}
+			e := new(wo.Event)
+			e.Reset(httptest.NewRecorder(), req)
+
+			err := mw(e)
+			if tt.expectedStatus == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedStatus, wo.AsHTTPError(err).Status)
+		})
+	}
+}
+
+func TestHost_DuplicateHeaders(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected []string
+		status   int
+	}{
+		{policy: DuplicateHeadersReject, status: http.StatusBadRequest},
+		{policy: DuplicateHeadersFirst, expected: []string{"text/plain"}},
+		{policy: DuplicateHeadersLast, expected: []string{"application/json"}},
+		{policy: DuplicateHeadersAllow, expected: []string{"text/plain", "application/json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			mw := Host[*wo.Event](HostConfig{AllowedHosts: []string{"example.com"}, DuplicateHeaders: tt.policy})
+
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/", nil)
+			req.Header.Add(wo.HeaderContentType, "text/plain")
+			req.Header.Add(wo.HeaderContentType, "application/json")
+			req.Header.Add(wo.HeaderAccept, "text/html")
+			req.Header.Add(wo.HeaderAccept, "application/json")
+			e := new(wo.Event)
+			e.Reset(httptest.NewRecorder(), req)
+
+			err := mw(e)
+			if tt.status != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.status, wo.AsHTTPError(err).Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req.Header.Values(wo.HeaderContentType))
+			assert.Len(t, req.Header.Values(wo.HeaderAccept), 2)
+		})
+	}
+}
+
+func TestHost_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() { Host[*wo.Event](HostConfig{}) })
+	assert.Panics(t, func() { Host[*wo.Event](HostConfig{AllowedHosts: []string{"exa mple.com"}}) })
+	assert.Panics(t, func() { Host[*wo.Event](HostConfig{AllowedHosts: []string{"example.com"}, DuplicateHeaders: "merge"}) })
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gowool/wo"
+)
+
+type I18nConfig struct {
+	// Locales are the supported locales (language tags), the first one is the default.
+	//
+	// Required.
+	Locales []string `env:"LOCALES" json:"locales,omitempty" yaml:"locales,omitempty"`
+
+	// QueryParam is the query parameter which overrides the negotiated locale, ex. "lang".
+	//
+	// Optional. Default value "" (disabled).
+	QueryParam string `env:"QUERY_PARAM" json:"queryParam,omitempty" yaml:"queryParam,omitempty"`
+
+	// CookieName is the cookie which overrides the negotiated locale, ex. "locale".
+	//
+	// Optional. Default value "" (disabled).
+	CookieName string `env:"COOKIE_NAME" json:"cookieName,omitempty" yaml:"cookieName,omitempty"`
+}
+
+func (c *I18nConfig) Validate() error {
+	if len(c.Locales) == 0 {
+		return errors.New("i18n: at least one locale is required")
+	}
+	return nil
+}
+
+// I18n selects the request locale from the query parameter, the cookie or the Accept-Language
+// header (in this order), stores it in the request context (see [wo.Locale]) along with the
+// translate func of the translator (see [wo.TranslateFuncFromContext]) and sets the
+// Content-Language response header. The translator is optional.
+func I18n[T wo.Resolver](cfg I18nConfig, translator wo.Translator, skippers ...Skipper[T]) func(T) error {
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	supported := func(locale string) string {
+		for _, l := range cfg.Locales {
+			if strings.EqualFold(l, locale) {
+				return l
+			}
+		}
+		return ""
+	}
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		r := e.Request()
+
+		var locale string
+		if cfg.QueryParam != "" {
+			locale = supported(r.URL.Query().Get(cfg.QueryParam))
+		}
+		if locale == "" && cfg.CookieName != "" {
+			if cookie, err := r.Cookie(cfg.CookieName); err == nil {
+				locale = supported(cookie.Value)
+			}
+		}
+		if locale == "" {
+			locale = wo.AcceptLanguageBest(wo.ParseAcceptLanguageHeader(r.Header.Get(wo.HeaderAcceptLanguage)), cfg.Locales...)
+		}
+		if locale == "" {
+			locale = cfg.Locales[0]
+		}
+
+		ctx := wo.WithLocale(r.Context(), locale)
+		if translator != nil {
+			ctx = wo.WithTranslateFunc(ctx, func(key string, args ...any) string {
+				return translator.Translate(locale, key, args...)
+			})
+		}
+
+		e.SetRequest(r.WithContext(ctx))
+		wo.SetContentLanguage(e.Response(), locale)
+
+		return e.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestI18n(t *testing.T) {
+	translator := wo.TranslatorFunc(func(locale, key string, _ ...any) string {
+		return fmt.Sprintf("%s:%s", locale, key)
+	})
+
+	cfg := I18nConfig{Locales: []string{"en", "fr-FR", "de"}, QueryParam: "lang", CookieName: "locale"}
+
+	tests := []struct {
+		name     string
+		target   string
+		cookie   string
+		header   string
+		expected string
+	}{
+		{name: "default", target: "/", expected: "en"},
+		{name: "accept language", target: "/", header: "es, fr-CH;q=0.9, de;q=0.8", expected: "fr-FR"},
+		{name: "accept language quality", target: "/", header: "de;q=0.5, fr-FR;q=0.7", expected: "fr-FR"},
+		{name: "cookie", target: "/", cookie: "DE", header: "fr", expected: "de"},
+		{name: "query", target: "/?lang=fr-fr", cookie: "de", expected: "fr-FR"},
+		{name: "unsupported query", target: "/?lang=es", header: "de", expected: "de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(wo.HeaderAcceptLanguage, tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "locale", Value: tt.cookie})
+			}
+
+			e := new(wo.Event)
+			e.Reset(httptest.NewRecorder(), req)
+
+			require.NoError(t, I18n[*wo.Event](cfg, translator)(e))
+
+			assert.Equal(t, tt.expected, e.Locale())
+			assert.Equal(t, tt.expected+":hello", e.Translate("hello"))
+			assert.Equal(t, tt.expected, e.Response().Header().Get(wo.HeaderContentLanguage))
+			assert.Equal(t, wo.HeaderAcceptLanguage, e.Response().Header().Get(wo.HeaderVary))
+		})
+	}
+}
+
+func TestI18n_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() {
+		I18n[*wo.Event](I18nConfig{}, nil)
+	})
+}
@@ -0,0 +1,347 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gowool/wo"
+)
+
+// HeaderXMirrored marks the mirrored requests, so the shadow upstream tells them apart and
+// doesn't mirror them again.
+const HeaderXMirrored = "X-Mirrored"
+
+// hopHeaders are the hop-by-hop headers which aren't mirrored, see [HeaderPolicyConfig.StripHopByHop] too.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+type MirrorConfig struct {
+	// Target is the base URL of the shadow upstream, the path and the query of the request
+	// are appended to its path. Either Target or Sink is required.
+	Target string `env:"TARGET" json:"target,omitempty" yaml:"target,omitempty"`
+
+	// Sink receives the mirrored requests instead of the shadow upstream, ex. to record them.
+	Sink func(ctx context.Context, r *http.Request) error `json:"-" yaml:"-"`
+
+	// Fraction is the share of the requests mirrored, from 0 to 1, so 0 mirrors no requests.
+	//
+	// Default: 1
+	Fraction *float64 `env:"FRACTION" json:"fraction,omitempty" yaml:"fraction,omitempty"`
+
+	// MaxBodySize is the maximum size of the mirrored request body, the requests with the larger
+	// bodies aren't mirrored. The body is buffered before the request is handled.
+	//
+	// Default: 1MB
+	MaxBodySize int64 `env:"MAX_BODY_SIZE" json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+
+	// Timeout is the timeout of a mirrored request.
+	//
+	// Default: 5s
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// QueueSize is the capacity of the queue of the requests waiting to be mirrored, the requests
+	// are dropped once it's full, so the slow shadow upstream never holds the primary ones.
+	//
+	// Default: 256
+	QueueSize int `env:"QUEUE_SIZE" json:"queueSize,omitempty" yaml:"queueSize,omitempty"`
+
+	// Concurrency is the number of the requests mirrored at once.
+	//
+	// Default: 4
+	Concurrency int `env:"CONCURRENCY" json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+
+	// Client sends the mirrored requests to the Target.
+	//
+	// Default: http.DefaultClient
+	Client *http.Client `json:"-" yaml:"-"`
+
+	// OnError is called when a request can't be mirrored or is dropped.
+	OnError func(err error) `json:"-" yaml:"-"`
+
+	// Rand returns the random number in [0, 1).
+	//
+	// Default: rand.Float64
+	Rand func() float64 `json:"-" yaml:"-"`
+}
+
+func (c *MirrorConfig) SetDefaults() {
+	if c.Fraction == nil {
+		fraction := 1.0
+		c.Fraction = &fraction
+	}
+	if c.MaxBodySize == 0 {
+		c.MaxBodySize = 1 << 20
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 256
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 4
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	if c.Rand == nil {
+		c.Rand = rand.Float64
+	}
+}
+
+func (c *MirrorConfig) Validate() error {
+	if c.Target == "" && c.Sink == nil {
+		return errors.New("mirror: target or sink is required")
+	}
+	if c.Target != "" {
+		if u, err := url.Parse(c.Target); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("mirror: invalid target %q", c.Target)
+		}
+	}
+	if c.Fraction != nil && (*c.Fraction < 0 || *c.Fraction > 1) {
+		return errors.New("mirror: fraction must be within [0, 1]")
+	}
+	if c.MaxBodySize < 0 {
+		return errors.New("mirror: max body size must not be negative")
+	}
+	return nil
+}
+
+// TrafficMirror duplicates the sampled requests to the shadow upstream or the sink in the
+// background, for the dark launches of the new services. The responses of the shadow upstream
+// are discarded and its failures never affect the primary requests. Close it to mirror the
+// queued requests on shutdown.
+//
+//	fraction := 0.1
+//	mirror := middleware.NewTrafficMirror(middleware.MirrorConfig{Target: "http://orders-v2.internal", Fraction: &fraction})
+//	defer mirror.Close(context.Background())
+//
+//	r.BindFunc(middleware.Mirror[*wo.Event](mirror))
+type TrafficMirror struct {
+	cfg     MirrorConfig
+	target  *url.URL
+	queue   chan *http.Request
+	dropped atomic.Uint64
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	closed  bool
+}
+
+func NewTrafficMirror(cfg MirrorConfig) *TrafficMirror {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	m := &TrafficMirror{cfg: cfg, queue: make(chan *http.Request, cfg.QueueSize)}
+	if cfg.Target != "" {
+		m.target, _ = url.Parse(cfg.Target)
+	}
+
+	for range cfg.Concurrency {
+		m.wg.Go(m.send)
+	}
+	return m
+}
+
+// Dropped returns the number of the requests dropped because the queue was full or the
+// mirror was closed.
+func (m *TrafficMirror) Dropped() uint64 {
+	return m.dropped.Load()
+}
+
+// Close stops accepting the requests and waits until the queued ones are mirrored.
+// If ctx is done before that, ctx error is returned.
+func (m *TrafficMirror) Close(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// mirror returns the copy of the request for the shadow upstream, with the body.
+func (m *TrafficMirror) mirror(r *http.Request, body []byte) *http.Request {
+	mr := r.Clone(context.Background())
+	mr.RequestURI = ""
+	mr.Body = http.NoBody
+	mr.GetBody = nil
+	mr.ContentLength = int64(len(body))
+	mr.TransferEncoding = nil
+	if len(body) > 0 {
+		mr.Body = io.NopCloser(bytes.NewReader(body))
+		mr.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	for _, h := range hopHeaders {
+		mr.Header.Del(h)
+	}
+	mr.Header.Set(HeaderXMirrored, "1")
+
+	if m.target != nil {
+		u := *m.target
+		u.Path = singleJoiningSlash(m.target.Path, r.URL.Path)
+		u.RawPath = ""
+		u.RawQuery = r.URL.RawQuery
+		mr.URL = &u
+		mr.Host = ""
+	}
+	return mr
+}
+
+func (m *TrafficMirror) enqueue(r *http.Request) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		m.drop(errors.New("mirror: mirror is closed"))
+		return
+	}
+
+	select {
+	case m.queue <- r:
+	default:
+		m.drop(errors.New("mirror: queue is full"))
+	}
+}
+
+func (m *TrafficMirror) drop(err error) {
+	m.dropped.Add(1)
+	if m.cfg.OnError != nil {
+		m.cfg.OnError(err)
+	}
+}
+
+func (m *TrafficMirror) send() {
+	for r := range m.queue {
+		if err := m.do(r); err != nil && m.cfg.OnError != nil {
+			m.cfg.OnError(err)
+		}
+	}
+}
+
+func (m *TrafficMirror) do(r *http.Request) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.Timeout)
+	defer cancel()
+
+	r = r.WithContext(ctx)
+	if m.cfg.Sink != nil {
+		return m.cfg.Sink(ctx, r)
+	}
+
+	res, err := m.cfg.Client.Do(r)
+	if err != nil {
+		return fmt.Errorf("mirror: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	_ = res.Body.Close()
+	return nil
+}
+
+// Mirror duplicates the sampled requests with their bodies by the mirror, see [TrafficMirror].
+// The request is mirrored once it's handled, the mirrored requests (see [HeaderXMirrored])
+// aren't mirrored again.
+func Mirror[T wo.Resolver](m *TrafficMirror, skippers ...Skipper[T]) func(T) error {
+	if m == nil {
+		panic("mirror middleware: mirror is nil")
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		r := e.Request()
+		if skip(e) || r.Header.Get(HeaderXMirrored) != "" || m.cfg.Rand() >= *m.cfg.Fraction {
+			return e.Next()
+		}
+
+		body, ok, err := bufferBody(r, m.cfg.MaxBodySize)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return e.Next()
+		}
+
+		mr := m.mirror(r, body)
+		err = e.Next()
+		m.enqueue(mr)
+		return err
+	}
+}
+
+// bufferBody reads the request body up to the limit and replaces it with the buffered one,
+// ok is false if the body is larger, the body is restored to be read in full then.
+func bufferBody(r *http.Request, limit int64) ([]byte, bool, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true, nil
+	}
+	if r.ContentLength > limit {
+		return nil, false, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil {
+		return nil, false, wo.ErrBadRequest.WithInternal(err)
+	}
+
+	if int64(len(body)) > limit {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		return nil, false, nil
+	}
+
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{bytes.NewReader(body), r.Body}
+	return body, true, nil
+}
+
+func singleJoiningSlash(a, b string) string {
+	aslash := len(a) > 0 && a[len(a)-1] == '/'
+	bslash := len(b) > 0 && b[0] == '/'
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
+}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+type mirroredRequest struct {
+	method string
+	uri    string
+	host   string
+	header http.Header
+	body   string
+}
+
+func newMirrorHandler(t *testing.T, m *TrafficMirror) http.Handler {
+	t.Helper()
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+	router.BindFunc(Mirror[*wo.Event](m))
+	router.POST("/orders", func(e *wo.Event) error {
+		body, err := io.ReadAll(e.Request().Body)
+		if err != nil {
+			return err
+		}
+		return e.String(http.StatusCreated, string(body))
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+	return h
+}
+
+func TestMirror(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		mirrored []mirroredRequest
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		mirrored = append(mirrored, mirroredRequest{method: r.Method, uri: r.RequestURI, host: r.Host, header: r.Header, body: string(body)})
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer upstream.Close()
+
+	m := NewTrafficMirror(MirrorConfig{Target: upstream.URL + "/shadow", MaxBodySize: 10})
+	h := newMirrorHandler(t, m)
+
+	tests := []struct {
+		name     string
+		body     string
+		header   http.Header
+		mirrored bool
+	}{
+		{name: "mirrored", body: "order", mirrored: true},
+		{name: "large body", body: "large order body"},
+		{name: "already mirrored", body: "order", header: http.Header{HeaderXMirrored: {"1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/orders?id=1", strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			req.Header.Set("Connection", "keep-alive")
+			req.Header.Set("X-Request-Id", "abc")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			// the primary response isn't affected
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, tt.body, rec.Body.String())
+		})
+	}
+
+	require.NoError(t, m.Close(context.Background()))
+
+	require.Len(t, mirrored, 1)
+	assert.Equal(t, http.MethodPost, mirrored[0].method)
+	assert.Equal(t, "/shadow/orders?id=1", mirrored[0].uri)
+	assert.Equal(t, strings.TrimPrefix(upstream.URL, "http://"), mirrored[0].host)
+	assert.Equal(t, "order", mirrored[0].body)
+	assert.Equal(t, "abc", mirrored[0].header.Get("X-Request-Id"))
+	assert.Equal(t, "1", mirrored[0].header.Get(HeaderXMirrored))
+	assert.Empty(t, mirrored[0].header.Get("Connection"))
+}
+
+func TestMirror_Sink(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		bodies []string
+	)
+
+	n, fraction := 0, 0.5
+	m := NewTrafficMirror(MirrorConfig{
+		Sink: func(_ context.Context, r *http.Request) error {
+			body, err := io.ReadAll(r.Body)
+			mu.Lock()
+			bodies = append(bodies, string(body))
+			mu.Unlock()
+			return err
+		},
+		Fraction: &fraction,
+		Rand: func() float64 {
+			n++
+			return float64(n%2) * 0.75 // 0.75, 0, 0.75, 0
+		},
+	})
+	h := newMirrorHandler(t, m)
+
+	for _, body := range []string{"a", "b", "c", "d"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body)))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	require.NoError(t, m.Close(context.Background()))
+	assert.ElementsMatch(t, []string{"b", "d"}, bodies)
+
+	// the requests are dropped once the mirror is closed
+	var dropped error
+	m.cfg.OnError = func(err error) { dropped = err }
+	n = 1
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("e")))
+	assert.EqualError(t, dropped, "mirror: mirror is closed")
+	assert.Equal(t, uint64(1), m.Dropped())
+}
+
+func TestMirror_NoFraction(t *testing.T) {
+	var mirrored atomic.Int32
+
+	fraction := 0.0
+	m := NewTrafficMirror(MirrorConfig{
+		Sink: func(context.Context, *http.Request) error {
+			mirrored.Add(1)
+			return nil
+		},
+		Fraction: &fraction,
+	})
+	h := newMirrorHandler(t, m)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("a")))
+
+	require.NoError(t, m.Close(context.Background()))
+	assert.Zero(t, mirrored.Load())
+}
+
+func TestMirrorConfig_SetDefaults(t *testing.T) {
+	var cfg MirrorConfig
+	cfg.SetDefaults()
+	require.NotNil(t, cfg.Fraction)
+	assert.InDelta(t, 1.0, *cfg.Fraction, 0)
+
+	fraction := 0.0
+	cfg = MirrorConfig{Fraction: &fraction}
+	cfg.SetDefaults()
+	assert.Zero(t, *cfg.Fraction)
+}
+
+func TestMirrorConfig_Validate(t *testing.T) {
+	fraction := 2.0
+
+	tests := []struct {
+		name string
+		cfg  MirrorConfig
+	}{
+		{name: "no target", cfg: MirrorConfig{}},
+		{name: "relative target", cfg: MirrorConfig{Target: "/shadow"}},
+		{name: "fraction", cfg: MirrorConfig{Target: "http://shadow", Fraction: &fraction}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.SetDefaults()
+			assert.Error(t, tt.cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gowool/hook"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/config"
+)
+
+// PipelineEntry is the middleware of the declarative pipeline, ex. loaded from the config file:
+//
+//	middlewares:
+//	  - name: recover
+//	  - name: body-limit
+//	    config: {limit: 1048576}
+//	    skip: ["POST /upload"]
+//	  - name: compress
+//	    config: {level: 5}
+type PipelineEntry struct {
+	// Name is the name of the registered middleware factory.
+	//
+	// Required.
+	Name string `env:"NAME" json:"name" yaml:"name"`
+
+	// ID is the ID of the middleware handler, which Unbind and the ordering constraints refer to.
+	//
+	// Default: Name
+	ID string `env:"ID" json:"id,omitempty" yaml:"id,omitempty"`
+
+	// Priority is the execution priority of the middleware handler, see [hook.Handler].
+	Priority int `env:"PRIORITY" json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// Skip are the path prefixes the middleware is skipped for, optionally with the method,
+	// see [PrefixPathSkipper].
+	Skip []string `env:"SKIP" json:"skip,omitempty" yaml:"skip,omitempty"`
+
+	// Config is decoded into the config of the middleware, the defaults are set and the config
+	// is validated before the middleware is created.
+	Config PipelineConfig `json:"config,omitzero" yaml:"config,omitempty"`
+}
+
+// PipelineConfig is the config of the [PipelineEntry] kept as it's loaded, YAML or JSON, so it's
+// decoded straight into the config of the middleware by the same rules as the config file,
+// ex. the durations like "30s", see [config.YAML] and [config.JSON].
+type PipelineConfig struct {
+	node *yaml.Node
+	data json.RawMessage
+}
+
+// NewPipelineConfig returns the config of the value, ex. map[string]any{"level": 5} or the config
+// of the middleware, for the entries made in code. It panics if the value can't be encoded to YAML.
+func NewPipelineConfig(v any) PipelineConfig {
+	node := new(yaml.Node)
+	if err := node.Encode(v); err != nil {
+		panic(fmt.Errorf("middleware: pipeline config: %w", err))
+	}
+	return PipelineConfig{node: node}
+}
+
+func (c PipelineConfig) IsZero() bool {
+	return c.node == nil && c.data == nil
+}
+
+func (c *PipelineConfig) UnmarshalYAML(node *yaml.Node) error {
+	c.node, c.data = node, nil
+	return nil
+}
+
+func (c *PipelineConfig) UnmarshalJSON(data []byte) error {
+	c.node, c.data = nil, slices.Clone(data)
+	return nil
+}
+
+func (c PipelineConfig) MarshalYAML() (any, error) {
+	if c.data != nil {
+		var v any
+		if err := json.Unmarshal(c.data, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return c.node, nil
+}
+
+func (c PipelineConfig) MarshalJSON() ([]byte, error) {
+	if c.node != nil {
+		var v any
+		if err := c.node.Decode(&v); err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	}
+	if c.data == nil {
+		return []byte("null"), nil
+	}
+	return c.data, nil
+}
+
+// decode decodes the config into cfg, then prepares cfg, see [config.Prepare].
+func (c PipelineConfig) decode(cfg any) error {
+	switch {
+	case c.node != nil:
+		data, err := yaml.Marshal(c.node)
+		if err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+		if err = config.YAML(data)(cfg); err != nil {
+			return err
+		}
+	case c.data != nil:
+		if err := config.JSON(c.data)(cfg); err != nil {
+			return err
+		}
+	}
+	return config.Prepare(cfg)
+}
+
+// Factory creates the middleware, decode decodes the entry config into the config of the
+// middleware, setting its defaults and validating it.
+type Factory[T wo.Resolver] func(decode func(cfg any) error, skippers ...Skipper[T]) (func(T) error, error)
+
+// Registry holds the middleware factories keyed by name, so the middleware pipeline is assembled
+// from the config at startup, ex. of the gateway-style deployments:
+//
+//	reg := middleware.NewRegistry[*wo.Event]()
+//	reg.Register("tenant", tenantFactory)
+//
+//	if err := reg.Bind(r.RouterGroup, cfg.Middlewares); err != nil {
+//		return err
+//	}
+//
+// The registry is not safe for concurrent use, the factories are meant to be registered at startup.
+type Registry[T wo.Resolver] struct {
+	factories map[string]Factory[T]
+}
+
+// NewRegistry returns the registry with the factories of the middlewares configurable from
+// the config only: recover, body-limit, body-rereadable, chaos, compress, cors, header-policy,
+// security and singleflight.
+func NewRegistry[T wo.Resolver]() *Registry[T] {
+	r := &Registry[T]{factories: make(map[string]Factory[T])}
+
+	RegisterFunc(r, "recover", Recover[T])
+	RegisterFunc(r, "body-limit", BodyLimit[T])
+	r.Register("body-rereadable", func(_ func(any) error, skippers ...Skipper[T]) (func(T) error, error) {
+		return BodyRereadable(skippers...), nil
+	})
+	RegisterFunc(r, "chaos", Chaos[T])
+	RegisterFunc(r, "compress", Compress[T])
+	RegisterFunc(r, "cors", CORS[T])
+	RegisterFunc(r, "header-policy", HeaderPolicy[T])
+	RegisterFunc(r, "security", Security[T])
+	RegisterFunc(r, "singleflight", Singleflight[T])
+
+	return r
+}
+
+// Register registers the middleware factory, replacing the previously registered one with the same name.
+func (r *Registry[T]) Register(name string, factory Factory[T]) {
+	if name == "" {
+		panic("middleware: factory must have a name")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("middleware: factory %q is nil", name))
+	}
+
+	r.factories[name] = factory
+}
+
+// RegisterFunc registers the middleware constructor taking the config of type C, ex. [Compress].
+func RegisterFunc[T wo.Resolver, C any](r *Registry[T], name string, fn func(cfg C, skippers ...Skipper[T]) func(T) error) {
+	if fn == nil {
+		panic(fmt.Sprintf("middleware: factory %q is nil", name))
+	}
+
+	r.Register(name, func(decode func(any) error, skippers ...Skipper[T]) (func(T) error, error) {
+		var cfg C
+		if err := decode(&cfg); err != nil {
+			return nil, err
+		}
+		return fn(cfg, skippers...), nil
+	})
+}
+
+// Names returns the names of the registered factories.
+func (r *Registry[T]) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Build creates the middleware handlers of the entries in order.
+func (r *Registry[T]) Build(entries []PipelineEntry) ([]*hook.Handler[T], error) {
+	handlers := make([]*hook.Handler[T], 0, len(entries))
+	for i, entry := range entries {
+		h, err := r.build(entry)
+		if err != nil {
+			return nil, fmt.Errorf("middleware: pipeline[%d] %q: %w", i, entry.Name, err)
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers, nil
+}
+
+// Bind creates the middleware handlers of the entries and binds them to the group,
+// ex. the root group of the router.
+func (r *Registry[T]) Bind(group *wo.RouterGroup[T], entries []PipelineEntry) error {
+	handlers, err := r.Build(entries)
+	if err != nil {
+		return err
+	}
+
+	group.Bind(handlers...)
+	return nil
+}
+
+func (r *Registry[T]) build(entry PipelineEntry) (*hook.Handler[T], error) {
+	factory, ok := r.factories[entry.Name]
+	if !ok {
+		return nil, errors.New("factory is not registered")
+	}
+
+	var skippers []Skipper[T]
+	if len(entry.Skip) > 0 {
+		skippers = append(skippers, PrefixPathSkipper[T](entry.Skip...))
+	}
+
+	fn, err := factory(entry.Config.decode, skippers...)
+	if err != nil {
+		return nil, err
+	}
+
+	id := entry.ID
+	if id == "" {
+		id = entry.Name
+	}
+	return &hook.Handler[T]{Func: fn, ID: id, Priority: entry.Priority}, nil
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gowool/wo"
+)
+
+func TestRegistry_Bind(t *testing.T) {
+	var entries []PipelineEntry
+	require.NoError(t, yaml.Unmarshal([]byte(`
+- name: recover
+- name: body-limit
+  id: limit
+  config: {limit: 4}
+  skip: ["POST /upload"]
+- name: security
+  config: {xFrameOptions: DENY}
+`), &entries))
+
+	reg := NewRegistry[*wo.Event]()
+	handlers, err := reg.Build(entries)
+	require.NoError(t, err)
+	require.Len(t, handlers, 3)
+	assert.Equal(t, "recover", handlers[0].ID)
+	assert.Equal(t, "limit", handlers[1].ID)
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, func(e *wo.Event, err error) {
+		if res := wo.MustUnwrapResponse(e.Response()); !res.Written {
+			e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+		}
+	})
+	require.NoError(t, reg.Bind(router.RouterGroup, entries))
+
+	read := func(e *wo.Event) error {
+		body, err := io.ReadAll(e.Request().Body)
+		if err != nil {
+			return err
+		}
+		return e.String(http.StatusOK, string(body))
+	}
+	router.POST("/upload", read)
+	router.POST("/items", read)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "limited", path: "/items", status: http.StatusRequestEntityTooLarge},
+		{name: "skipped", path: "/upload", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("too large")))
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader("ok")))
+	assert.Equal(t, "DENY", rec.Header().Get(wo.HeaderXFrameOptions))
+}
+
+func TestRegistry_BuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries string
+		err     string
+	}{
+		{
+			name:    "unknown factory",
+			entries: `[{name: recover}, {name: proxy}]`,
+			err:     `middleware: pipeline[1] "proxy": factory is not registered`,
+		},
+		{
+			name:    "invalid config",
+			entries: `[{name: compress, config: {level: 10}}]`,
+			err:     `middleware: pipeline[0] "compress": invalid gzip level`,
+		},
+		{
+			name:    "unknown config field",
+			entries: `[{name: cors, config: {origins: ["*"]}}]`,
+			err:     "middleware: pipeline[0] \"cors\": config: yaml: yaml: unmarshal errors:\n  line 1: field origins not found in type middleware.CORSConfig",
+		},
+	}
+
+	reg := NewRegistry[*wo.Event]()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []PipelineEntry
+			require.NoError(t, yaml.Unmarshal([]byte(tt.entries), &entries))
+
+			_, err := reg.Build(entries)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRegistry_Config(t *testing.T) {
+	var decoded ChaosConfig
+
+	reg := NewRegistry[*wo.Event]()
+	RegisterFunc(reg, "capture", func(cfg ChaosConfig, _ ...Skipper[*wo.Event]) func(*wo.Event) error {
+		decoded = cfg
+		return func(e *wo.Event) error { return e.Next() }
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var entries []PipelineEntry
+		require.NoError(t, yaml.Unmarshal([]byte(`[{name: capture, config: {latency: 30s, errorStatuses: [503]}}]`), &entries))
+
+		_, err := reg.Build(entries)
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, decoded.Latency)
+		assert.Equal(t, []int{http.StatusServiceUnavailable}, decoded.ErrorStatuses)
+
+		data, err := json.Marshal(entries[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"capture","config":{"latency":"30s","errorStatuses":[503]}}`, string(data))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var entries []PipelineEntry
+		require.NoError(t, json.Unmarshal([]byte(`[{"name":"capture","config":{"latencyProbability":0.5}}]`), &entries))
+
+		_, err := reg.Build(entries)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.5, decoded.LatencyProbability, 0)
+		assert.Equal(t, 100*time.Millisecond, decoded.Latency)
+
+		data, err := yaml.Marshal(entries[0])
+		require.NoError(t, err)
+		assert.Equal(t, "name: capture\nconfig:\n    latencyProbability: 0.5\n", string(data))
+	})
+
+	t.Run("value", func(t *testing.T) {
+		_, err := reg.Build([]PipelineEntry{{Name: "capture", Config: NewPipelineConfig(map[string]any{"latency": "30s"})}})
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Second, decoded.Latency)
+
+		_, err = reg.Build([]PipelineEntry{{Name: "capture", Config: NewPipelineConfig(ChaosConfig{Latency: time.Second})}})
+		require.NoError(t, err)
+		assert.Equal(t, time.Second, decoded.Latency)
+
+		assert.Panics(t, func() { NewPipelineConfig(func() {}) })
+	})
+
+	t.Run("no config", func(t *testing.T) {
+		_, err := reg.Build([]PipelineEntry{{Name: "capture"}})
+		require.NoError(t, err)
+		assert.Equal(t, 100*time.Millisecond, decoded.Latency)
+
+		data, err := json.Marshal(PipelineEntry{Name: "capture"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"capture"}`, string(data))
+	})
+}
+
+func TestRegistry_RecoverSkip(t *testing.T) {
+	var entries []PipelineEntry
+	require.NoError(t, yaml.Unmarshal([]byte(`[{name: recover, skip: ["/panic"]}]`), &entries))
+
+	handlers, err := NewRegistry[wo.Resolver]().Build(entries)
+	require.NoError(t, err)
+
+	e := &panickingEvent{Event: new(wo.Event), panicValue: "test panic"}
+	e.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.PanicsWithValue(t, "test panic", func() { _ = handlers[0].Func(e) })
+
+	e.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Error(t, handlers[0].Func(e))
+}
+
+func TestRegistry_Register(t *testing.T) {
+	reg := NewRegistry[*wo.Event]()
+
+	assert.Equal(t, []string{"body-limit", "body-rereadable", "chaos", "compress", "cors", "header-policy", "recover", "security", "singleflight"}, reg.Names())
+
+	RegisterFunc(reg, "tenant", Tenant[*wo.Event])
+	assert.Contains(t, reg.Names(), "tenant")
+
+	assert.Panics(t, func() { reg.Register("", nil) })
+	assert.Panics(t, func() { reg.Register("nil", nil) })
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/gowool/wo"
+)
+
+// Poller stores the poller in the request context, so the handlers can answer the long polls
+// with [wo.Event.LongPoll].
+func Poller[T wo.Resolver](poller wo.Poller, skippers ...Skipper[T]) func(T) error {
+	if poller == nil {
+		panic("poller middleware: poller is nil")
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		r := e.Request()
+		e.SetRequest(r.WithContext(wo.WithPoller(r.Context(), poller)))
+
+		return e.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/fingerprint"
+	"github.com/gowool/wo/quota"
+)
+
+type QuotaConfig[T wo.Resolver] struct {
+	// Subject returns the subject the usage is counted for, ex. the account or the API key.
+	//
+	// Default: the request remote IP, see [wo.Event.RemoteIP]
+	Subject func(e T) (string, error) `json:"-" yaml:"-"`
+
+	// Cost returns the usage of the request.
+	//
+	// Default: 1
+	Cost func(e T) int64 `json:"-" yaml:"-"`
+
+	// When set to true, the middleware will not include the quota headers (X-Quota-* and Retry-After) in the response.
+	//
+	// Default: false
+	DisableHeaders bool `env:"DISABLE_HEADERS" json:"disableHeaders,omitempty" yaml:"disableHeaders,omitempty"`
+}
+
+func (c *QuotaConfig[T]) SetDefaults() {
+	if c.Subject == nil {
+		c.Subject = func(e T) (string, error) {
+			if re, ok := any(e).(interface{ RemoteIP() string }); ok {
+				return re.RemoteIP(), nil
+			}
+			return fingerprint.RemoteIP(e.Request()), nil
+		}
+	}
+	if c.Cost == nil {
+		c.Cost = func(T) int64 { return 1 }
+	}
+}
+
+// Quota consumes the cost of the request from the quota of the subject and returns
+// [quota.ErrExceeded] once it's exhausted. The handlers may consume the quota directly
+// (see [quota.Quota.Consume]), ex. when the cost is known after the response.
+func Quota[T wo.Resolver](cfg QuotaConfig[T], q *quota.Quota, skippers ...Skipper[T]) func(T) error {
+	if q == nil {
+		panic("quota middleware: quota is nil")
+	}
+
+	cfg.SetDefaults()
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		subject, err := cfg.Subject(e)
+		if err != nil {
+			return ErrExtractorError.WithInternal(fmt.Errorf("quota: failed to extract subject: %w", err))
+		}
+
+		res, err := q.Consume(e.Request().Context(), subject, cfg.Cost(e))
+		exceeded := errors.Is(err, quota.ErrExceeded)
+		if err != nil && !exceeded {
+			return err
+		}
+
+		if !cfg.DisableHeaders {
+			res.SetHeaders(e.Response().Header())
+		}
+		if exceeded {
+			return err
+		}
+		return e.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/quota"
+)
+
+func TestQuota(t *testing.T) {
+	q := quota.New(quota.Config{Name: "api", Max: 5}, quota.NewMemoryStore())
+	mw := Quota(QuotaConfig[*wo.Event]{
+		Subject: func(e *wo.Event) (string, error) { return e.Request().Header.Get("X-Account"), nil },
+		Cost:    func(*wo.Event) int64 { return 2 },
+	}, q)
+
+	headers := map[string]string{"X-Account": "acme"}
+
+	for _, remaining := range []string{"3", "1"} {
+		e := newSessionTestEvent(http.MethodGet, "/", headers)
+		require.NoError(t, mw(e))
+		assert.Equal(t, "5", e.Response().Header().Get(wo.HeaderXQuotaLimit))
+		assert.Equal(t, remaining, e.Response().Header().Get(wo.HeaderXQuotaRemaining))
+	}
+
+	e := newSessionTestEvent(http.MethodGet, "/", headers)
+	err := mw(e)
+	require.ErrorIs(t, err, quota.ErrExceeded)
+	assert.Equal(t, "1", e.Response().Header().Get(wo.HeaderXQuotaRemaining))
+	assert.NotEmpty(t, e.Response().Header().Get(wo.HeaderRetryAfter))
+}
+
+func TestQuota_DefaultSubject(t *testing.T) {
+	q := quota.New(quota.Config{Name: "api", Max: 1}, quota.NewMemoryStore())
+	mw := Quota(QuotaConfig[*wo.Event]{}, q)
+
+	e := newSessionTestEvent(http.MethodGet, "/", nil)
+	e.Request().RemoteAddr = "192.0.2.1:1234"
+	require.NoError(t, mw(e))
+	assert.Equal(t, "0", e.Response().Header().Get(wo.HeaderXQuotaRemaining))
+	assert.Empty(t, e.Response().Header().Get(wo.HeaderRetryAfter))
+
+	e = newSessionTestEvent(http.MethodGet, "/", nil)
+	e.Request().RemoteAddr = "192.0.2.1:5678"
+	require.ErrorIs(t, mw(e), quota.ErrExceeded)
+}
+
+func TestQuota_DisableHeaders(t *testing.T) {
+	q := quota.New(quota.Config{Name: "api", Max: 5}, quota.NewMemoryStore())
+	mw := Quota(QuotaConfig[*wo.Event]{DisableHeaders: true}, q)
+
+	e := newSessionTestEvent(http.MethodGet, "/", nil)
+	require.NoError(t, mw(e))
+	assert.Empty(t, e.Response().Header().Get(wo.HeaderXQuotaLimit))
+}
+
+func TestQuota_SubjectError(t *testing.T) {
+	q := quota.New(quota.Config{Name: "api", Max: 5}, quota.NewMemoryStore())
+	mw := Quota(QuotaConfig[*wo.Event]{
+		Subject: func(*wo.Event) (string, error) { return "", errors.New("no account") },
+	}, q)
+
+	err := mw(newSessionTestEvent(http.MethodGet, "/", nil))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, wo.AsHTTPError(err).Status)
+}
+
+func TestQuota_NilQuota(t *testing.T) {
+	assert.Panics(t, func() { Quota[*wo.Event](QuotaConfig[*wo.Event]{}, nil) })
+}
@@ -1,13 +1,17 @@
 package middleware
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gowool/wo"
+	"github.com/gowool/wo/fingerprint"
+	"github.com/gowool/wo/kv"
 )
 
 // ErrRateLimitExceeded denotes an error raised when rate limit is exceeded
@@ -16,6 +20,14 @@ var ErrRateLimitExceeded = wo.ErrTooManyRequests.WithMessage("rate limit exceede
 // ErrExtractorError denotes an error raised when extractor function is unsuccessful
 var ErrExtractorError = wo.ErrForbidden.WithMessage("error while extracting identifier")
 
+// FingerprintIdentifier returns the rate limiter identifier extractor computing the request
+// fingerprint, so the clients behind the same IP address are told apart, see [fingerprint.Of].
+func FingerprintIdentifier[T wo.Resolver](components fingerprint.Component) func(T) (string, error) {
+	return func(e T) (string, error) {
+		return fingerprint.Of(e.Request(), components), nil
+	}
+}
+
 type RateLimiterStorage interface {
 	// Get gets the value for the given key with a context.
 	// `nil, nil` is returned when the key does not exist
@@ -25,8 +37,44 @@ type RateLimiterStorage interface {
 	Set(ctx context.Context, key string, value []byte, exp time.Duration) error
 }
 
+// KVStorage adapts the [kv.Store] to the [RateLimiterStorage], so the rate limiter shares
+// the store of the other middlewares, ex. the Redis one across the instances:
+//
+//	cfg := middleware.RateLimiterConfig[*wo.Event]{Storage: middleware.KVStorage(store)}
+func KVStorage(store kv.Store) RateLimiterStorage {
+	return kvStorage{store: store}
+}
+
+type kvStorage struct {
+	store kv.Store
+}
+
+func (s kvStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	value, found, err := s.store.Get(ctx, key)
+	if err != nil || !found {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (s kvStorage) Set(ctx context.Context, key string, value []byte, exp time.Duration) error {
+	return s.store.Set(ctx, key, value, exp)
+}
+
 type RateLimiterConfig[T wo.Resolver] struct {
-	// Storage is used to store the state of the middleware
+	// Name is the name of the limiter reported in the X-RateLimit-Policy header, ex. "burst"
+	// and "sustained" of the limiters stacked on the same route.
+	//
+	// Optional.
+	Name string `env:"NAME" json:"name,omitempty" yaml:"name,omitempty"`
+
+	// KeyPrefix is prepended to the identifiers to make the storage keys, so the limiters
+	// sharing the storage, ex. the stacked ones, don't collide.
+	//
+	// Default: Name + ":" if Name is set
+	KeyPrefix string `env:"KEY_PREFIX" json:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty"`
+
+	// Storage is used to store the state of the middleware, see KVStorage for the [kv.Store] ones
 	//
 	// Default: in memory storage
 	Storage RateLimiterStorage `json:"-" yaml:"-"`
@@ -58,6 +106,15 @@ type RateLimiterConfig[T wo.Resolver] struct {
 	// }
 	MaxFunc func(T) uint `json:"-" yaml:"-"`
 
+	// CostFunc returns the cost of the request, the number of the hits it consumes from the
+	// window, so the expensive endpoints consume more of Max than the cheap ones, ex. 5 for the
+	// search and 1 for the ping. The zero cost requests aren't limited, the cost over the max is
+	// always denied, and the denied requests don't consume the hits. The cost is reported
+	// in the X-RateLimit-Cost header, X-RateLimit-Remaining is the remaining cost.
+	//
+	// Default: nil (1 per request)
+	CostFunc func(T) uint `json:"-" yaml:"-"`
+
 	// Expiration is the time on how long to keep records of requests in memory
 	//
 	// Default: 1 * time.Minute
@@ -70,18 +127,49 @@ type RateLimiterConfig[T wo.Resolver] struct {
 	// }
 	ExpirationFunc func(T) time.Duration `json:"-" yaml:"-"`
 
-	// When set to true, the middleware will not include the rate limit headers (X-RateLimit-* and Retry-After) in the response.
+	// Health enables the adaptive mode: the max is multiplied by the health factor, which
+	// decreases while the downstream error rate or latency exceeds the thresholds and
+	// recovers as health is restored. The middleware reports its own responses to it.
+	//
+	// Default: nil
+	Health *RateLimiterHealth `json:"-" yaml:"-"`
+
+	// When set to true, the middleware will not include the rate limit headers (see Headers, and Retry-After) in the response.
+	// The stacked limiters report the most restrictive limit in X-RateLimit-Limit, X-RateLimit-Remaining
+	// and X-RateLimit-Reset, and each of them in X-RateLimit-Policy.
 	//
 	// Default: false
 	DisableHeaders bool `env:"DISABLE_HEADERS" json:"disableHeaders,omitempty" yaml:"disableHeaders,omitempty"`
 
+	// Headers is the format of the rate limit headers: "x" for X-RateLimit-*, "ietf" for the
+	// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit and RateLimit-Policy fields
+	// of draft-ietf-httpapi-ratelimit-headers, ex. for the API gateways, or "both".
+	//
+	// Default: "x"
+	Headers string `env:"HEADERS" json:"headers,omitempty" yaml:"headers,omitempty"`
+
 	// DisableValueRedaction turns off masking limiter keys in logs and error messages when set to true.
 	//
 	// Default: false
 	DisableValueRedaction bool `env:"DISABLE_VALUE_REDACTION" json:"disableValueRedaction,omitempty" yaml:"disableValueRedaction,omitempty"`
 }
 
+// The formats of the rate limit headers, see RateLimiterConfig.Headers.
+const (
+	RateLimitHeadersX    = "x"
+	RateLimitHeadersIETF = "ietf"
+	RateLimitHeadersBoth = "both"
+)
+
 func (c *RateLimiterConfig[T]) SetDefaults() {
+	if c.Headers == "" {
+		c.Headers = RateLimitHeadersX
+	}
+
+	if c.KeyPrefix == "" && c.Name != "" {
+		c.KeyPrefix = c.Name + ":"
+	}
+
 	if c.TimestampFunc == nil {
 		c.TimestampFunc = timestampFunc
 	}
@@ -115,10 +203,112 @@ func (c *RateLimiterConfig[T]) SetDefaults() {
 	}
 }
 
+func (c *RateLimiterConfig[T]) Validate() error {
+	switch c.Headers {
+	case RateLimitHeadersX, RateLimitHeadersIETF, RateLimitHeadersBoth:
+		return nil
+	default:
+		return fmt.Errorf("rate_limiter: unknown headers format %q", c.Headers)
+	}
+}
+
+// RateLimit is the sliding-window rate limiter, whose window state handlers can inspect
+// (see [RateLimit.Remaining] and [RateLimit.ResetAt]) to pre-check the quota, ex. to return
+// partial results or queue the work instead of failing at the middleware layer.
+//
+//	rl := middleware.NewRateLimit(cfg)
+//	r.Use(rl.Middleware())
+//
+//	if remaining, _ := rl.Remaining(ctx, id); remaining < len(batch) { ... }
+//
+// The limiters are stacked on the same route, ex. the burst and the sustained ones:
+//
+//	g.BindFunc(
+//		middleware.RateLimiter[*wo.Event](middleware.RateLimiterConfig[*wo.Event]{Name: "burst", Max: 10, Expiration: time.Second, Storage: storage}),
+//		middleware.RateLimiter[*wo.Event](middleware.RateLimiterConfig[*wo.Event]{Name: "sustained", Max: 600, Expiration: time.Hour, Storage: storage}),
+//	)
+type RateLimit[T wo.Resolver] struct {
+	cfg     RateLimiterConfig[T]
+	manager *rateLimiterManager
+	mux     sync.RWMutex
+}
+
+func NewRateLimit[T wo.Resolver](cfg RateLimiterConfig[T]) *RateLimit[T] {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &RateLimit[T]{
+		cfg:     cfg,
+		manager: newRateLimiterManager(cfg.Storage, !cfg.DisableValueRedaction),
+	}
+}
+
 // RateLimiter middleware implements the sliding-window rate limiting strategy
 func RateLimiter[T wo.Resolver](cfg RateLimiterConfig[T], skippers ...Skipper[T]) func(T) error {
-	cfg.SetDefaults()
+	return NewRateLimit(cfg).Middleware(skippers...)
+}
+
+// Remaining returns the number of the requests the identifier can make within the current window,
+// the remaining cost with CostFunc.
+// It's computed with Max (adjusted by Health) and Expiration, since MaxFunc and ExpirationFunc
+// depend on the request.
+func (l *RateLimit[T]) Remaining(ctx context.Context, id string) (int, error) {
+	rate, _, err := l.peek(ctx, id, l.expiration())
+	if err != nil {
+		return 0, err
+	}
+	return max(0, l.max()-rate), nil
+}
+
+// ResetAt returns the time the current window of the identifier ends, the zero time if the identifier
+// has no window. Like [RateLimit.Remaining] it's computed with Expiration.
+func (l *RateLimit[T]) ResetAt(ctx context.Context, id string) (time.Time, error) {
+	_, exp, err := l.peek(ctx, id, l.expiration())
+	if err != nil || exp == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(exp), 0), nil //nolint:gosec // Not a concern
+}
 
+// peek returns the rate and the window end of the identifier without recording a hit.
+func (l *RateLimit[T]) peek(ctx context.Context, id string, expiration uint64) (int, uint64, error) {
+	l.mux.RLock()
+	entry, err := l.manager.get(ctx, l.cfg.KeyPrefix+id)
+	l.mux.RUnlock()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer l.manager.release(entry)
+
+	if entry.exp == 0 {
+		return 0, 0, nil
+	}
+
+	ts := uint64(l.cfg.TimestampFunc())
+	entry.slide(ts, expiration)
+
+	weight := float64(entry.exp-ts) / float64(expiration)
+	return int(float64(entry.prevHits)*weight) + entry.currHits, entry.exp, nil
+}
+
+func (l *RateLimit[T]) max() int {
+	maxRequests := int(l.cfg.Max)
+	if l.cfg.Health != nil {
+		maxRequests = l.cfg.Health.limit(maxRequests)
+	}
+	return maxRequests
+}
+
+func (l *RateLimit[T]) expiration() uint64 {
+	return uint64(l.cfg.Expiration.Seconds())
+}
+
+// Middleware returns the rate limiter middleware.
+func (l *RateLimit[T]) Middleware(skippers ...Skipper[T]) func(T) error {
+	cfg := l.cfg
 	skip := ChainSkipper[T](skippers...)
 
 	maxFunc := func(t T) int {
@@ -135,9 +325,8 @@ func RateLimiter[T wo.Resolver](cfg RateLimiterConfig[T], skippers ...Skipper[T]
 		return uint64(cfg.Expiration.Seconds())
 	}
 
-	manager := newRateLimiterManager(cfg.Storage, !cfg.DisableValueRedaction)
-
-	mux := new(sync.RWMutex)
+	manager := l.manager
+	mux := &l.mux
 
 	return func(e T) error {
 		if skip(e) {
@@ -148,8 +337,25 @@ func RateLimiter[T wo.Resolver](cfg RateLimiterConfig[T], skippers ...Skipper[T]
 		if err != nil {
 			return ErrExtractorError.WithInternal(fmt.Errorf("rate_limiter: failed to extract identifier: %w", err))
 		}
+		key = cfg.KeyPrefix + key
+
+		var requestCost uint = 1
+		if cfg.CostFunc != nil {
+			if requestCost = cfg.CostFunc(e); requestCost == 0 {
+				return e.Next()
+			}
+		}
 
 		maxRequests := maxFunc(e)
+		if cfg.Health != nil {
+			maxRequests = cfg.Health.limit(maxRequests)
+		}
+
+		// the cost over the max is never allowed, it's denied without charging the hits
+		if requestCost > uint(max(0, maxRequests)) { //nolint:gosec // Not a concern
+			return ErrRateLimitExceeded
+		}
+		cost := int(requestCost) //nolint:gosec // Not a concern
 		expiration := expirationFunc(e)
 
 		// Lock entry
@@ -170,27 +376,12 @@ func RateLimiter[T wo.Resolver](cfg RateLimiterConfig[T], skippers ...Skipper[T]
 		// Set expiration if entry does not exist
 		if entry.exp == 0 {
 			entry.exp = ts + expiration
-		} else if ts >= entry.exp {
-			// The entry has expired, handle the expiration.
-			// Set the prevHits to the current hits and reset the hits to 0.
-			entry.prevHits = entry.currHits
-
-			// Reset the current hits to 0.
-			entry.currHits = 0
-
-			// Check how much into the current window it currently is and sets the
-			// expiry based on that; otherwise, this would only reset on
-			// the next request and not show the correct expiry.
-			elapsed := ts - entry.exp
-			if elapsed >= expiration {
-				entry.exp = ts + expiration
-			} else {
-				entry.exp = ts + expiration - elapsed
-			}
+		} else {
+			entry.slide(ts, expiration)
 		}
 
-		// Increment hits
-		entry.currHits++
+		// Increment hits by the cost
+		entry.currHits += cost
 
 		// Calculate when it resets in seconds
 		resetInSec := entry.exp - ts
@@ -204,6 +395,11 @@ func RateLimiter[T wo.Resolver](cfg RateLimiterConfig[T], skippers ...Skipper[T]
 		// Calculate how many hits can be made based on the current rate
 		remaining := maxRequests - rate
 
+		// the denied request doesn't consume the hits
+		if remaining < 0 {
+			entry.currHits -= cost
+		}
+
 		// Update storage. Garbage collect when the next window ends.
 		// |--------------------------|--------------------------|
 		//               ^            ^               ^          ^
@@ -235,15 +431,90 @@ func RateLimiter[T wo.Resolver](cfg RateLimiterConfig[T], skippers ...Skipper[T]
 		}
 
 		if !cfg.DisableHeaders {
-			e.Response().Header().Set(wo.HeaderXRateLimitLimit, strconv.Itoa(maxRequests))
-			e.Response().Header().Set(wo.HeaderXRateLimitRemaining, strconv.Itoa(remaining))
-			e.Response().Header().Set(wo.HeaderXRateLimitReset, strconv.FormatUint(resetInSec, 10))
+			h := e.Response().Header()
+			if cfg.Headers != RateLimitHeadersIETF {
+				setRateLimitHeaders(h, wo.HeaderXRateLimitLimit, wo.HeaderXRateLimitRemaining, wo.HeaderXRateLimitReset, maxRequests, remaining, resetInSec)
+				h.Add(wo.HeaderXRateLimitPolicy, rateLimitPolicy(cfg.Name, maxRequests, expiration))
+				if cfg.CostFunc != nil {
+					h.Set(wo.HeaderXRateLimitCost, strconv.Itoa(cost))
+				}
+			}
+			if cfg.Headers != RateLimitHeadersX {
+				setRateLimitHeaders(h, wo.HeaderRateLimitLimit, wo.HeaderRateLimitRemaining, wo.HeaderRateLimitReset, maxRequests, remaining, resetInSec)
+				name := strconv.Quote(cmp.Or(cfg.Name, "default"))
+				h.Add(wo.HeaderRateLimit, name+";r="+strconv.Itoa(remaining)+";t="+strconv.FormatUint(resetInSec, 10))
+				h.Add(wo.HeaderRateLimitPolicy, name+";q="+strconv.Itoa(maxRequests)+";w="+strconv.FormatUint(expiration, 10))
+			}
+		}
+
+		if cfg.Health == nil {
+			return e.Next()
 		}
 
-		return e.Next()
+		start := time.Now()
+		err = e.Next()
+		cfg.Health.Observe(responseStatus(e.Response(), err), time.Since(start))
+		return err
+	}
+}
+
+// setRateLimitHeaders sets the limit, the remaining and the reset headers, unless the stacked
+// limiter has set the more restrictive ones.
+func setRateLimitHeaders(h http.Header, limitKey, remainingKey, resetKey string, limit, remaining int, reset uint64) {
+	if current, err := strconv.Atoi(h.Get(remainingKey)); err == nil && current <= remaining {
+		return
+	}
+	h.Set(limitKey, strconv.Itoa(limit))
+	h.Set(remainingKey, strconv.Itoa(remaining))
+	h.Set(resetKey, strconv.FormatUint(reset, 10))
+}
+
+// rateLimitPolicy returns the X-RateLimit-Policy value of the limiter, ex. `10;w=1;name="burst"`.
+func rateLimitPolicy(name string, limit int, window uint64) string {
+	policy := strconv.Itoa(limit) + ";w=" + strconv.FormatUint(window, 10)
+	if name != "" {
+		policy += ";name=" + strconv.Quote(name)
 	}
+	return policy
+}
+
+// responseStatus returns the status of the written response or the one the error maps to.
+func responseStatus(w http.ResponseWriter, err error) int {
+	if err != nil {
+		if he := wo.AsHTTPError(err); he != nil {
+			return he.Status
+		}
+		return http.StatusInternalServerError
+	}
+	if res, err := wo.UnwrapResponse(w); err == nil && res.Status != 0 {
+		return res.Status
+	}
+	return http.StatusOK
 }
 
 func timestampFunc() uint32 {
 	return uint32(time.Now().Unix())
 }
+
+// slide moves the window forward once it has expired: the current hits become the previous ones.
+func (it *item) slide(ts, expiration uint64) {
+	if ts < it.exp {
+		return
+	}
+
+	// Set the prevHits to the current hits and reset the hits to 0.
+	it.prevHits = it.currHits
+
+	// Reset the current hits to 0.
+	it.currHits = 0
+
+	// Check how much into the current window it currently is and sets the
+	// expiry based on that; otherwise, this would only reset on
+	// the next request and not show the correct expiry.
+	elapsed := ts - it.exp
+	if elapsed >= expiration {
+		it.exp = ts + expiration
+	} else {
+		it.exp = ts + expiration - elapsed
+	}
+}
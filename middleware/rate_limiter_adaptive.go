@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type RateLimiterAdaptiveConfig struct {
+	// ErrorRate is the share of 5xx responses within Window above which the limits are tightened.
+	//
+	// Default: 0.1
+	ErrorRate float64 `env:"ERROR_RATE" json:"errorRate,omitempty" yaml:"errorRate,omitempty"`
+
+	// Latency is the average latency within Window above which the limits are tightened.
+	// Zero disables the latency check.
+	//
+	// Default: 0
+	Latency time.Duration `env:"LATENCY" json:"latency,omitempty,format:units" yaml:"latency,omitempty"`
+
+	// MinFactor is the lowest multiplier applied to the configured max.
+	//
+	// Default: 0.1
+	MinFactor float64 `env:"MIN_FACTOR" json:"minFactor,omitempty" yaml:"minFactor,omitempty"`
+
+	// Step is the amount the multiplier is decreased (unhealthy) or increased (healthy) by per Window.
+	//
+	// Default: 0.1
+	Step float64 `env:"STEP" json:"step,omitempty" yaml:"step,omitempty"`
+
+	// Window is the evaluation period.
+	//
+	// Default: 10s
+	Window time.Duration `env:"WINDOW" json:"window,omitempty,format:units" yaml:"window,omitempty"`
+
+	// MinSamples is the minimum number of observations within Window required to tighten the limits.
+	//
+	// Default: 20
+	MinSamples int `env:"MIN_SAMPLES" json:"minSamples,omitempty" yaml:"minSamples,omitempty"`
+}
+
+func (c *RateLimiterAdaptiveConfig) SetDefaults() {
+	if c.ErrorRate <= 0 {
+		c.ErrorRate = 0.1
+	}
+	if c.MinFactor <= 0 {
+		c.MinFactor = 0.1
+	}
+	if c.Step <= 0 {
+		c.Step = 0.1
+	}
+	if c.Window <= 0 {
+		c.Window = 10 * time.Second
+	}
+	if c.MinSamples <= 0 {
+		c.MinSamples = 20
+	}
+}
+
+func (c *RateLimiterAdaptiveConfig) Validate() error {
+	if c.ErrorRate > 1 {
+		return errors.New("rate_limiter: adaptive error rate must be within (0, 1]")
+	}
+	if c.MinFactor > 1 {
+		return errors.New("rate_limiter: adaptive min factor must be within (0, 1]")
+	}
+	return nil
+}
+
+// RateLimiterHealth tracks the downstream health and derives the multiplier applied
+// to the rate limiter max. The rate limiter observes its own responses, additional
+// sources (ex. a metrics middleware or a database client) may feed it via Observe.
+type RateLimiterHealth struct {
+	cfg         RateLimiterAdaptiveConfig
+	windowStart time.Time
+	requests    int
+	errors      int
+	latency     time.Duration
+	factor      float64
+	mu          sync.Mutex
+}
+
+func NewRateLimiterHealth(cfg RateLimiterAdaptiveConfig) *RateLimiterHealth {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &RateLimiterHealth{cfg: cfg, factor: 1, windowStart: time.Now()}
+}
+
+// Observe records the outcome of a request.
+func (h *RateLimiterHealth) Observe(status int, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.evaluate(time.Now())
+
+	h.requests++
+	h.latency += latency
+	if status >= http.StatusInternalServerError {
+		h.errors++
+	}
+}
+
+// Factor returns the current multiplier within [MinFactor, 1].
+func (h *RateLimiterHealth) Factor() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.evaluate(time.Now())
+
+	return h.factor
+}
+
+func (h *RateLimiterHealth) evaluate(now time.Time) {
+	if now.Sub(h.windowStart) < h.cfg.Window {
+		return
+	}
+
+	if h.unhealthy() {
+		h.factor = max(h.cfg.MinFactor, h.factor-h.cfg.Step)
+	} else {
+		h.factor = min(1, h.factor+h.cfg.Step)
+	}
+
+	h.windowStart = now
+	h.requests = 0
+	h.errors = 0
+	h.latency = 0
+}
+
+func (h *RateLimiterHealth) unhealthy() bool {
+	if h.requests < h.cfg.MinSamples {
+		return false
+	}
+	if float64(h.errors)/float64(h.requests) > h.cfg.ErrorRate {
+		return true
+	}
+	return h.cfg.Latency > 0 && h.latency/time.Duration(h.requests) > h.cfg.Latency
+}
+
+func (h *RateLimiterHealth) limit(maxRequests int) int {
+	return max(1, int(float64(maxRequests)*h.Factor()))
+}
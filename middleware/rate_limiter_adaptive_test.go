@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestRateLimiterAdaptiveConfig_SetDefaults(t *testing.T) {
+	cfg := RateLimiterAdaptiveConfig{}
+	cfg.SetDefaults()
+
+	assert.InDelta(t, 0.1, cfg.ErrorRate, 0)
+	assert.InDelta(t, 0.1, cfg.MinFactor, 0)
+	assert.InDelta(t, 0.1, cfg.Step, 0)
+	assert.Equal(t, 10*time.Second, cfg.Window)
+	assert.Equal(t, 20, cfg.MinSamples)
+	assert.Zero(t, cfg.Latency)
+}
+
+func TestRateLimiterAdaptiveConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&RateLimiterAdaptiveConfig{ErrorRate: 0.5, MinFactor: 0.5}).Validate())
+	assert.Error(t, (&RateLimiterAdaptiveConfig{ErrorRate: 1.5}).Validate())
+	assert.Error(t, (&RateLimiterAdaptiveConfig{MinFactor: 2}).Validate())
+
+	assert.Panics(t, func() {
+		NewRateLimiterHealth(RateLimiterAdaptiveConfig{ErrorRate: 2})
+	})
+}
+
+// expireWindow moves the evaluation window into the past, so the next call evaluates it.
+func expireWindow(h *RateLimiterHealth) {
+	h.mu.Lock()
+	h.windowStart = h.windowStart.Add(-h.cfg.Window)
+	h.mu.Unlock()
+}
+
+func TestRateLimiterHealth_ErrorRate(t *testing.T) {
+	h := NewRateLimiterHealth(RateLimiterAdaptiveConfig{MinSamples: 4, Step: 0.25, MinFactor: 0.5})
+	assert.InDelta(t, 1.0, h.Factor(), 0)
+
+	for range 2 {
+		h.Observe(http.StatusOK, time.Millisecond)
+		h.Observe(http.StatusBadGateway, time.Millisecond)
+	}
+	expireWindow(h)
+	assert.InDelta(t, 0.75, h.Factor(), 0.0001)
+
+	for range 4 {
+		h.Observe(http.StatusInternalServerError, time.Millisecond)
+	}
+	expireWindow(h)
+	assert.InDelta(t, 0.5, h.Factor(), 0.0001, "factor is bounded by MinFactor")
+
+	for range 4 {
+		h.Observe(http.StatusInternalServerError, time.Millisecond)
+	}
+	expireWindow(h)
+	assert.InDelta(t, 0.5, h.Factor(), 0.0001)
+
+	// healthy windows relax the limits
+	expireWindow(h)
+	assert.InDelta(t, 0.75, h.Factor(), 0.0001)
+	expireWindow(h)
+	expireWindow(h)
+	assert.InDelta(t, 1.0, h.Factor(), 0.0001)
+	assert.Equal(t, 1, h.limit(1))
+	assert.Equal(t, 10, h.limit(10))
+}
+
+func TestRateLimiterHealth_Latency(t *testing.T) {
+	h := NewRateLimiterHealth(RateLimiterAdaptiveConfig{MinSamples: 2, Latency: 100 * time.Millisecond})
+
+	h.Observe(http.StatusOK, 50*time.Millisecond)
+	h.Observe(http.StatusOK, 250*time.Millisecond)
+	expireWindow(h)
+
+	assert.InDelta(t, 0.9, h.Factor(), 0.0001)
+}
+
+func TestRateLimiterHealth_MinSamples(t *testing.T) {
+	h := NewRateLimiterHealth(RateLimiterAdaptiveConfig{MinSamples: 10})
+
+	h.Observe(http.StatusInternalServerError, time.Millisecond)
+	expireWindow(h)
+
+	assert.InDelta(t, 1.0, h.Factor(), 0)
+}
+
+func TestRateLimiter_Adaptive(t *testing.T) {
+	health := NewRateLimiterHealth(RateLimiterAdaptiveConfig{MinSamples: 1, Step: 0.5})
+
+	mw := RateLimiter[*wo.Event](RateLimiterConfig[*wo.Event]{Max: 4, Health: health})
+
+	e := newRLEvent()
+	err := mw(e)
+	require.NoError(t, err)
+	assert.Equal(t, "4", e.Response().Header().Get(wo.HeaderXRateLimitLimit))
+
+	health.Observe(http.StatusServiceUnavailable, time.Millisecond)
+	expireWindow(health)
+
+	e = newRLEvent()
+	err = mw(e)
+	require.NoError(t, err)
+	assert.Equal(t, "2", e.Response().Header().Get(wo.HeaderXRateLimitLimit))
+}
+
+func TestResponseStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, responseStatus(nil, wo.ErrNotFound))
+	assert.Equal(t, http.StatusInternalServerError, responseStatus(nil, errors.New("boom")))
+
+	e := newRLEvent()
+	assert.Equal(t, http.StatusOK, responseStatus(e.Response(), nil))
+
+	e.Response().WriteHeader(http.StatusCreated)
+	assert.Equal(t, http.StatusCreated, responseStatus(e.Response(), nil))
+}
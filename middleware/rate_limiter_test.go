@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"errors"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/gowool/wo"
+	"github.com/gowool/wo/fingerprint"
+	"github.com/gowool/wo/kv"
 )
 
 // MockRateLimiterStorage is a mock implementation of RateLimiterStorage
@@ -279,6 +282,24 @@ func TestRateLimiter_IdentifierExtractor(t *testing.T) {
 		require.NoError(t, err2)
 	})
 
+	t.Run("uses fingerprint identifier", func(t *testing.T) {
+		rl := RateLimiter(RateLimiterConfig[*wo.Event]{
+			Max:                 1,
+			Expiration:          1 * time.Second,
+			IdentifierExtractor: FingerprintIdentifier[*wo.Event](fingerprint.All),
+		})
+
+		newEvent := func(ua string) *wo.Event {
+			e := newRLEventWithRemoteAddr("127.0.0.1:1234")
+			e.Request().Header.Set("User-Agent", ua)
+			return e
+		}
+
+		require.NoError(t, rl(newEvent("client-a")))
+		require.NoError(t, rl(newEvent("client-b")))
+		require.Error(t, rl(newEvent("client-a")))
+	})
+
 	t.Run("handles identifier extractor error", func(t *testing.T) {
 		extractor := func(e *wo.Event) (string, error) {
 			return "", errors.New("extraction failed")
@@ -507,6 +528,19 @@ func TestRateLimiter_CustomStorage(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to persist state")
 	})
+
+	t.Run("uses kv store", func(t *testing.T) {
+		store := kv.NewMemoryStore(kv.MemoryStoreConfig{CleanupInterval: -1})
+		defer store.Stop()
+
+		rl := RateLimiter(RateLimiterConfig[*wo.Event]{
+			Max:     1,
+			Storage: KVStorage(store),
+		})
+
+		require.NoError(t, rl(newRLEvent()))
+		require.Equal(t, ErrRateLimitExceeded, rl(newRLEvent()))
+	})
 }
 
 func TestRateLimiter_CustomTimestampFunc(t *testing.T) {
@@ -560,6 +594,71 @@ func TestRateLimiter_SlidingWindow(t *testing.T) {
 	})
 }
 
+func TestRateLimit_RemainingAndResetAt(t *testing.T) {
+	t.Parallel()
+
+	ts := uint32(1700000000)
+	rl := NewRateLimit(RateLimiterConfig[*wo.Event]{
+		Max:           3,
+		Expiration:    10 * time.Second,
+		TimestampFunc: func() uint32 { return ts },
+	})
+	mw := rl.Middleware()
+	ctx := context.Background()
+	addr := "127.0.0.1:8080"
+
+	remaining, err := rl.Remaining(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, 3, remaining)
+
+	resetAt, err := rl.ResetAt(ctx, addr)
+	require.NoError(t, err)
+	require.True(t, resetAt.IsZero())
+
+	require.NoError(t, mw(newRLEventWithRemoteAddr(addr)))
+	require.NoError(t, mw(newRLEventWithRemoteAddr(addr)))
+
+	remaining, err = rl.Remaining(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, 1, remaining)
+
+	resetAt, err = rl.ResetAt(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(int64(ts)+10, 0), resetAt)
+
+	// peeking doesn't consume the quota
+	require.NoError(t, mw(newRLEventWithRemoteAddr(addr)))
+	remaining, err = rl.Remaining(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, 0, remaining)
+	require.ErrorIs(t, mw(newRLEventWithRemoteAddr(addr)), ErrRateLimitExceeded)
+
+	// the next window weights the previous hits, the denied request isn't counted
+	ts += 15
+	remaining, err = rl.Remaining(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, 2, remaining)
+
+	resetAt, err = rl.ResetAt(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(int64(ts)+5, 0), resetAt)
+}
+
+func TestRateLimit_Remaining_StorageError(t *testing.T) {
+	t.Parallel()
+
+	storage := new(MockRateLimiterStorage)
+	storage.On("Get", mock.Anything, "id").Return([]byte(nil), errors.New("storage error"))
+
+	rl := NewRateLimit(RateLimiterConfig[*wo.Event]{Storage: storage})
+
+	_, err := rl.Remaining(context.Background(), "id")
+	require.Error(t, err)
+
+	_, err = rl.ResetAt(context.Background(), "id")
+	require.Error(t, err)
+}
+
 func TestRateLimiter_EdgeCases(t *testing.T) {
 	t.Parallel()
 
@@ -850,3 +949,170 @@ func TestRateLimiter_MissingLinesCoverage(t *testing.T) {
 		}
 	})
 }
+
+func TestRateLimiter_Stacked(t *testing.T) {
+	t.Parallel()
+
+	storage := NewRateLimiterMemoryStorage(timestampFunc)
+	ts := func() uint32 { return 1_700_000_000 }
+
+	burst := NewRateLimit(RateLimiterConfig[*wo.Event]{Name: "burst", Max: 2, Expiration: 10 * time.Second, Storage: storage, TimestampFunc: ts})
+	sustained := NewRateLimit(RateLimiterConfig[*wo.Event]{Name: "sustained", Max: 3, Expiration: time.Hour, Storage: storage, TimestampFunc: ts})
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+	router.BindFunc(burst.Middleware(), sustained.Middleware())
+	router.GET("/", func(e *wo.Event) error {
+		return e.NoContent(http.StatusNoContent)
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	do := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "127.0.0.1"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do()
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "2", rec.Header().Get(wo.HeaderXRateLimitLimit))
+	require.Equal(t, "1", rec.Header().Get(wo.HeaderXRateLimitRemaining))
+	require.Equal(t, []string{`2;w=10;name="burst"`, `3;w=3600;name="sustained"`}, rec.Header().Values(wo.HeaderXRateLimitPolicy))
+
+	rec = do()
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "0", rec.Header().Get(wo.HeaderXRateLimitRemaining))
+
+	// the limiters sharing the storage count the hits under their own keys
+	remaining, err := sustained.Remaining(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, 1, remaining)
+
+	require.Equal(t, http.StatusTooManyRequests, do().Code)
+
+	remaining, err = sustained.Remaining(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, 1, remaining, "the request rejected by the burst limiter must not count")
+}
+
+func TestRateLimiterConfig_KeyPrefix(t *testing.T) {
+	cfg := RateLimiterConfig[*wo.Event]{Name: "burst"}
+	cfg.SetDefaults()
+	require.Equal(t, "burst:", cfg.KeyPrefix)
+
+	cfg = RateLimiterConfig[*wo.Event]{Name: "burst", KeyPrefix: "api:burst:"}
+	cfg.SetDefaults()
+	require.Equal(t, "api:burst:", cfg.KeyPrefix)
+}
+
+func TestRateLimiter_CostFunc(t *testing.T) {
+	t.Parallel()
+
+	rl := RateLimiter(RateLimiterConfig[*wo.Event]{
+		Max:           10,
+		Expiration:    time.Minute,
+		TimestampFunc: func() uint32 { return 1_700_000_000 },
+		CostFunc: func(e *wo.Event) uint {
+			switch e.Request().URL.Path {
+			case "/search":
+				return 5
+			case "/health":
+				return 0
+			case "/huge":
+				return math.MaxUint
+			default:
+				return 1
+			}
+		},
+	})
+
+	tests := []struct {
+		path              string
+		expectedErr       error
+		expectedCost      string
+		expectedRemaining string
+	}{
+		{path: "/ping", expectedCost: "1", expectedRemaining: "9"},
+		{path: "/search", expectedCost: "5", expectedRemaining: "4"},
+		{path: "/health"},
+		{path: "/search", expectedErr: ErrRateLimitExceeded},
+		{path: "/huge", expectedErr: ErrRateLimitExceeded},
+		// the denied requests don't consume the hits
+		{path: "/ping", expectedCost: "1", expectedRemaining: "3"},
+	}
+
+	for _, tt := range tests {
+		e := newRLEventWithPath(tt.path)
+		err := rl(e)
+		require.ErrorIs(t, err, tt.expectedErr, tt.path)
+		if tt.expectedErr == nil {
+			require.Equal(t, tt.expectedCost, e.Response().Header().Get(wo.HeaderXRateLimitCost), tt.path)
+			require.Equal(t, tt.expectedRemaining, e.Response().Header().Get(wo.HeaderXRateLimitRemaining), tt.path)
+		}
+	}
+}
+
+func TestRateLimiter_Headers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		headers  string
+		expected http.Header
+		absent   []string
+	}{
+		{
+			headers:  RateLimitHeadersX,
+			expected: http.Header{"X-Ratelimit-Limit": {"5"}, "X-Ratelimit-Remaining": {"4"}, "X-Ratelimit-Reset": {"60"}},
+			absent:   []string{wo.HeaderRateLimit, wo.HeaderRateLimitLimit},
+		},
+		{
+			headers: RateLimitHeadersIETF,
+			expected: http.Header{
+				"Ratelimit-Limit":     {"5"},
+				"Ratelimit-Remaining": {"4"},
+				"Ratelimit-Reset":     {"60"},
+				"Ratelimit":           {`"api";r=4;t=60`},
+				"Ratelimit-Policy":    {`"api";q=5;w=60`},
+			},
+			absent: []string{wo.HeaderXRateLimitLimit, wo.HeaderXRateLimitPolicy},
+		},
+		{
+			headers:  RateLimitHeadersBoth,
+			expected: http.Header{"X-Ratelimit-Remaining": {"4"}, "Ratelimit-Remaining": {"4"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.headers, func(t *testing.T) {
+			rl := RateLimiter(RateLimiterConfig[*wo.Event]{
+				Name:          "api",
+				Max:           5,
+				Expiration:    time.Minute,
+				Headers:       tt.headers,
+				TimestampFunc: func() uint32 { return 1_700_000_000 },
+			})
+
+			e := newRLEvent()
+			require.NoError(t, rl(e))
+
+			h := e.Response().Header()
+			for k, v := range tt.expected {
+				require.Equal(t, v, h.Values(k), k)
+			}
+			for _, k := range tt.absent {
+				require.Empty(t, h.Values(k), k)
+			}
+		})
+	}
+
+	require.Panics(t, func() {
+		RateLimiter(RateLimiterConfig[*wo.Event]{Headers: "draft"})
+	})
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/redact"
+)
+
+type RecordConfig struct {
+	// MaxBodySize is the maximum size of the recorded request body, the larger bodies are truncated.
+	// If MaxBodySize is less than 0, the bodies are not recorded.
+	//
+	// Default: 64KB
+	MaxBodySize int64 `env:"MAX_BODY_SIZE" json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+
+	// ScrubHeaders are the request headers the values of which are replaced with the Mask.
+	//
+	// Default: [Authorization, Cookie, Proxy-Authorization]
+	ScrubHeaders []string `env:"SCRUB_HEADERS" json:"scrubHeaders,omitempty" yaml:"scrubHeaders,omitempty"`
+
+	// ScrubQuery are the query parameters the values of which are replaced with the Mask.
+	ScrubQuery []string `env:"SCRUB_QUERY" json:"scrubQuery,omitempty" yaml:"scrubQuery,omitempty"`
+
+	// ScrubFields are the fields of the JSON and the form bodies the values of which are replaced
+	// with the Mask, by name at any depth or by the dotted path, see [redact.Config]. The bodies which
+	// can't be parsed, ex. the truncated JSON, and the bodies which can't be scrubbed by the fields,
+	// ex. multipart or XML, except the plain text ones, are not recorded if ScrubFields is set.
+	ScrubFields []string `env:"SCRUB_FIELDS" json:"scrubFields,omitempty" yaml:"scrubFields,omitempty"`
+
+	// ScrubPatterns are the regular expressions the matches of which are replaced with the Mask
+	// in the text bodies and the string values of the JSON and the form bodies, ex. [redact.Email].
+	ScrubPatterns []string `env:"SCRUB_PATTERNS" json:"scrubPatterns,omitempty" yaml:"scrubPatterns,omitempty"`
+
+	// Mask is the replacement of the scrubbed values.
+	//
+	// Default: [REDACTED]
+	Mask string `env:"MASK" json:"mask,omitempty" yaml:"mask,omitempty"`
+}
+
+func (c *RecordConfig) SetDefaults() {
+	if c.MaxBodySize == 0 {
+		c.MaxBodySize = 64 << 10
+	}
+	if len(c.ScrubHeaders) == 0 {
+		c.ScrubHeaders = []string{wo.HeaderAuthorization, wo.HeaderCookie, "Proxy-Authorization"}
+	}
+	if c.Mask == "" {
+		c.Mask = "[REDACTED]"
+	}
+}
+
+// RecordedRequest is the request captured by the [Record] middleware.
+type RecordedRequest struct {
+	Time       time.Time     `json:"time"`
+	Method     string        `json:"method"`
+	URL        string        `json:"url"`
+	Host       string        `json:"host"`
+	RemoteAddr string        `json:"remoteAddr,omitempty"`
+	Header     http.Header   `json:"header,omitempty"`
+	Body       []byte        `json:"body,omitempty"`
+	Truncated  bool          `json:"truncated,omitempty"`
+	Pattern    string        `json:"pattern,omitempty"`
+	Status     int           `json:"status,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// Request returns the new request replaying the recorded one.
+func (r *RecordedRequest) Request(ctx context.Context) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Host = r.Host
+	req.RemoteAddr = r.RemoteAddr
+	req.RequestURI = r.URL
+	return req, nil
+}
+
+// RecordSink stores the recorded requests, ex. in a file or a queue.
+type RecordSink interface {
+	Record(ctx context.Context, r *RecordedRequest) error
+}
+
+// RecordSinkFunc is an adapter to allow the use of ordinary functions as [RecordSink].
+type RecordSinkFunc func(ctx context.Context, r *RecordedRequest) error
+
+func (f RecordSinkFunc) Record(ctx context.Context, r *RecordedRequest) error {
+	return f(ctx, r)
+}
+
+// JSONLinesSink writes the recorded requests to w as JSON lines, see [Replay].
+func JSONLinesSink(w io.Writer) RecordSink {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+
+	return RecordSinkFunc(func(_ context.Context, r *RecordedRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return enc.Encode(r)
+	})
+}
+
+// Record captures the requests, their headers and bodies scrubbed as configured, and stores them
+// in the sink once they are handled, so the production issues can be reproduced locally with [Replay].
+// The failures of the sink are logged and don't affect the request.
+func Record[T wo.Resolver](cfg RecordConfig, sink RecordSink, logger ErrorLogger, skippers ...Skipper[T]) func(T) error {
+	if sink == nil {
+		panic("record middleware: sink is nil")
+	}
+
+	cfg.SetDefaults()
+
+	redactor := redact.New(redact.Config{
+		Mask:        cfg.Mask,
+		DenyHeaders: cfg.ScrubHeaders,
+		Query:       cfg.ScrubQuery,
+		Fields:      cfg.ScrubFields,
+		Patterns:    cfg.ScrubPatterns,
+	})
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		r := e.Request()
+		rec := &RecordedRequest{
+			Time:       time.Now(),
+			Method:     r.Method,
+			URL:        redactor.URL(r.URL),
+			Host:       r.Host,
+			RemoteAddr: r.RemoteAddr,
+			Header:     redactor.Header(r.Header),
+		}
+
+		if cfg.MaxBodySize >= 0 && r.Body != nil && r.Body != http.NoBody {
+			// the body is read ahead, so the unread parts are recorded too
+			body, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxBodySize+1))
+			if err != nil {
+				return err
+			}
+			r.Body = &recordedBody{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+
+			if int64(len(body)) > cfg.MaxBodySize {
+				body, rec.Truncated = body[:cfg.MaxBodySize], true
+			}
+			rec.Body = redactor.Body(r.Header.Get(wo.HeaderContentType), body)
+		}
+
+		err := e.Next()
+
+		rec.Duration = time.Since(rec.Time)
+		rec.Pattern = e.Request().Pattern
+		rec.Status = wo.MustUnwrapResponse(e.Response()).Status
+		if err != nil {
+			rec.Error = err.Error()
+		}
+
+		if serr := sink.Record(context.WithoutCancel(r.Context()), rec); serr != nil && logger != nil {
+			logger.Error("failed to record request", "error", serr)
+		}
+
+		return err
+	}
+}
+
+// Replay feeds the recorded requests read from src, written by the [JSONLinesSink], through the
+// handler, ex. the one built by [wo.Router.Build], calling fn with each request and its response.
+func Replay(h http.Handler, src io.Reader, fn func(rec *RecordedRequest, res *http.Response) error) error {
+	if h == nil {
+		return errors.New("replay: handler is nil")
+	}
+
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64<<10), 64<<20)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		rec := new(RecordedRequest)
+		if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return fmt.Errorf("replay: line %d: %w", line, err)
+		}
+
+		req, err := rec.Request(context.Background())
+		if err != nil {
+			return fmt.Errorf("replay: line %d: %w", line, err)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if fn != nil {
+			if err = fn(rec, w.Result()); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+type recordedBody struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/redact"
+)
+
+func newRecordRouter(t *testing.T, bind func(r *wo.Router[*wo.Event])) http.Handler {
+	t.Helper()
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, func(e *wo.Event, err error) {
+		if res := wo.MustUnwrapResponse(e.Response()); !res.Written {
+			e.Response().WriteHeader(wo.AsHTTPError(err).Status)
+		}
+	})
+	bind(router)
+
+	router.POST("/items/{id}", func(e *wo.Event) error {
+		body, err := io.ReadAll(e.Request().Body)
+		if err != nil {
+			return err
+		}
+		return e.String(http.StatusCreated, e.Param("id")+":"+string(body))
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+	return h
+}
+
+func TestRecord(t *testing.T) {
+	var buf bytes.Buffer
+	h := newRecordRouter(t, func(r *wo.Router[*wo.Event]) {
+		r.BindFunc(Record[*wo.Event](RecordConfig{ScrubQuery: []string{"token"}, ScrubFields: []string{"password"}}, JSONLinesSink(&buf), nil))
+	})
+
+	body := `{"user":{"name":"ann","password":"secret"},"n":1.50}`
+	req := httptest.NewRequest(http.MethodPost, "/items/1?token=abc&page=2", strings.NewReader(body))
+	req.Header.Set(wo.HeaderContentType, wo.MIMEApplicationJSON)
+	req.Header.Set(wo.HeaderAuthorization, "Bearer abc")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "1:"+body, rec.Body.String())
+
+	// the replayed requests are recorded again
+	recorded := bytes.NewReader(bytes.Clone(buf.Bytes()))
+
+	var replayed []string
+	err := Replay(h, recorded, func(r *RecordedRequest, res *http.Response) error {
+		assert.Equal(t, "/items/1?page=2&token=%5BREDACTED%5D", r.URL)
+		assert.Equal(t, "[REDACTED]", r.Header.Get(wo.HeaderAuthorization))
+		assert.Equal(t, "POST /items/{id}", r.Pattern)
+		assert.Equal(t, http.StatusCreated, r.Status)
+
+		data, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		replayed = append(replayed, string(data))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`1:{"n":1.50,"user":{"name":"ann","password":"[REDACTED]"}}`}, replayed)
+}
+
+func TestRecord_Body(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         RecordConfig
+		contentType string
+		body        string
+		recorded    string
+		truncated   bool
+	}{
+		{name: "as is", body: "plain text", recorded: "plain text"},
+		{name: "truncated", cfg: RecordConfig{MaxBodySize: 5}, body: "plain text", recorded: "plain", truncated: true},
+		{name: "truncated scrubbed", cfg: RecordConfig{MaxBodySize: 5, ScrubFields: []string{"password"}}, contentType: wo.MIMEApplicationJSON, body: `{"password":"x"}`, truncated: true},
+		{name: "not recorded", cfg: RecordConfig{MaxBodySize: -1}, body: "plain text"},
+		{name: "form", cfg: RecordConfig{ScrubFields: []string{"password"}}, contentType: wo.MIMEApplicationForm, body: "user=ann&password=x", recorded: "password=%5BREDACTED%5D&user=ann"},
+		{name: "patterns", cfg: RecordConfig{ScrubPatterns: []string{redact.Email}}, contentType: wo.MIMETextPlain, body: "from ann@example.com", recorded: "from [REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recorded *RecordedRequest
+			sink := RecordSinkFunc(func(_ context.Context, r *RecordedRequest) error {
+				recorded = r
+				return nil
+			})
+			h := newRecordRouter(t, func(r *wo.Router[*wo.Event]) {
+				r.BindFunc(Record[*wo.Event](tt.cfg, sink, nil))
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set(wo.HeaderContentType, tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			// the handler reads the whole body
+			assert.Equal(t, "1:"+tt.body, rec.Body.String())
+			require.NotNil(t, recorded)
+			assert.Equal(t, tt.recorded, string(recorded.Body))
+			assert.Equal(t, tt.truncated, recorded.Truncated)
+		})
+	}
+}
+
+type recordErrorLogger struct {
+	msgs []string
+}
+
+func (l *recordErrorLogger) Error(msg string, _ ...any) {
+	l.msgs = append(l.msgs, msg)
+}
+
+func TestRecord_SinkError(t *testing.T) {
+	logger := new(recordErrorLogger)
+	sink := RecordSinkFunc(func(context.Context, *RecordedRequest) error {
+		return errors.New("disk full")
+	})
+	h := newRecordRouter(t, func(r *wo.Router[*wo.Event]) {
+		r.BindFunc(Record[*wo.Event](RecordConfig{}, sink, logger))
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/1", nil))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, []string{"failed to record request"}, logger.msgs)
+}
+
+func TestReplay_Errors(t *testing.T) {
+	h := http.NotFoundHandler()
+
+	assert.EqualError(t, Replay(nil, strings.NewReader(""), nil), "replay: handler is nil")
+	assert.ErrorContains(t, Replay(h, strings.NewReader("\n{"), nil), "replay: line 2: ")
+	assert.ErrorContains(t, Replay(h, strings.NewReader(`{"method":"GET","url":"::"}`), nil), "replay: line 1: ")
+}
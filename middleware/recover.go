@@ -7,24 +7,38 @@ import (
 	"runtime"
 
 	"github.com/gowool/wo"
+	"github.com/gowool/wo/report"
 )
 
 type RecoverConfig struct {
 	// Size of the stack to be printed.
 	// Optional. Default value 2KB.
 	StackSize int `env:"STACK_SIZE" json:"stackSize,omitempty" yaml:"stackSize,omitempty"`
+
+	// Reporter reports the recovered panics, ex. to Sentry.
+	// Optional. Default value report.Nop.
+	Reporter report.ErrorReporter `json:"-" yaml:"-"`
 }
 
 func (c *RecoverConfig) SetDefaults() {
 	if c.StackSize == 0 {
 		c.StackSize = 2 << 10 // 2KB
 	}
+	if c.Reporter == nil {
+		c.Reporter = report.Nop
+	}
 }
 
-func Recover[T wo.Resolver](cfg RecoverConfig) func(T) error {
+func Recover[T wo.Resolver](cfg RecoverConfig, skippers ...Skipper[T]) func(T) error {
 	cfg.SetDefaults()
 
+	skip := ChainSkipper[T](skippers...)
+
 	return func(e T) (err error) {
+		if skip(e) {
+			return e.Next()
+		}
+
 		defer func() {
 			if r := recover(); r != nil {
 				recoverErr, ok := r.(error)
@@ -38,7 +52,16 @@ func Recover[T wo.Resolver](cfg RecoverConfig) func(T) error {
 				stack := make([]byte, cfg.StackSize)
 				length := runtime.Stack(stack, true)
 				internal := fmt.Errorf("[PANIC RECOVER] %w %s", recoverErr, stack[:length])
-				err = wo.ErrInternalServerError.WithInternal(internal)
+
+				req := e.Request()
+				cfg.Reporter.CaptureException(req.Context(), internal, report.Tags{
+					"source":  "recover",
+					"method":  req.Method,
+					"pattern": req.Pattern,
+				})
+
+				// the panic is reported, so the error handler doesn't report it again
+				err = wo.ErrInternalServerError.WithInternal(report.Reported(internal))
 			}
 		}()
 
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/gowool/wo"
+	"github.com/gowool/wo/report"
 )
 
 func newRecoverEvent() *wo.Event {
@@ -106,6 +108,17 @@ func Test_Recover_PanicRecovery_Error(t *testing.T) {
 	require.Contains(t, err.Error(), "[PANIC RECOVER]")
 }
 
+func Test_Recover_Skipped(t *testing.T) {
+	middleware := Recover[wo.Resolver](RecoverConfig{}, func(wo.Resolver) bool { return true })
+
+	panicHandler := &panickingEvent{
+		Event:      newRecoverEvent(),
+		panicValue: "test panic",
+	}
+
+	require.PanicsWithValue(t, "test panic", func() { _ = middleware(panicHandler) })
+}
+
 func Test_Recover_PanicRecovery_String(t *testing.T) {
 	cfg := RecoverConfig{StackSize: 1024}
 	middleware := Recover[wo.Resolver](cfg)
@@ -509,3 +522,30 @@ func Benchmark_Recover_StackTraceCollection(b *testing.B) {
 		_ = middleware(panicHandler)
 	}
 }
+
+type recoverReporter struct {
+	errs []error
+	tags []report.Tags
+}
+
+func (r *recoverReporter) CaptureException(_ context.Context, err error, tags report.Tags) {
+	r.errs = append(r.errs, err)
+	r.tags = append(r.tags, tags)
+}
+
+func (r *recoverReporter) CaptureMessage(context.Context, string, report.Tags) {}
+
+func Test_Recover_Reporter(t *testing.T) {
+	reporter := new(recoverReporter)
+	middleware := Recover[wo.Resolver](RecoverConfig{Reporter: reporter})
+
+	e := newRecoverEvent()
+	e.Request().Pattern = "GET /"
+	err := middleware(&panickingEvent{Event: e, panicValue: "boom"})
+
+	require.Error(t, err)
+	require.True(t, report.IsReported(err))
+	require.Len(t, reporter.errs, 1)
+	require.ErrorContains(t, reporter.errs[0], "[PANIC RECOVER] boom")
+	require.Equal(t, []report.Tags{{"source": "recover", "method": http.MethodGet, "pattern": "GET /"}}, reporter.tags)
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/session"
+)
+
+type RememberMeConfig[T wo.Resolver] struct {
+	// SessionKey is the session key of the authenticated user ID.
+	//
+	// Default: "userID"
+	SessionKey string `env:"SESSION_KEY" json:"sessionKey,omitempty" yaml:"sessionKey,omitempty"`
+
+	// OnRestore is called once the session of the user is re-created, ex. to load the user.
+	//
+	// Default: nil
+	OnRestore func(e T, userID string) error `json:"-" yaml:"-"`
+}
+
+func (c *RememberMeConfig[T]) SetDefaults() {
+	if c.SessionKey == "" {
+		c.SessionKey = "userID"
+	}
+}
+
+// RememberMe re-creates the authenticated session from the remember-me cookie (see [session.Remember])
+// when the session has no user ID, ex. because it expired. The session token is renewed and the used
+// remember-me token is rotated. The invalid remember-me cookies are removed and the request continues
+// unauthenticated. It must run after the [Session] middleware.
+func RememberMe[T wo.Resolver](cfg RememberMeConfig[T], s *session.Session, rm *session.Remember, skippers ...Skipper[T]) func(T) error {
+	if s == nil {
+		panic("remember me middleware: session is nil")
+	}
+	if rm == nil {
+		panic("remember me middleware: remember is nil")
+	}
+
+	cfg.SetDefaults()
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		ctx := e.Request().Context()
+		if s.Has(ctx, cfg.SessionKey) {
+			return e.Next()
+		}
+
+		userID, err := rm.Consume(ctx, e.Response(), e.Request())
+		if errors.Is(err, http.ErrNoCookie) || errors.Is(err, session.ErrRememberTokenInvalid) {
+			return e.Next()
+		}
+		if err != nil {
+			return err
+		}
+
+		// the restored session is written even for the read-only requests
+		readOnly := s.IsReadOnly(ctx)
+		s.SetReadOnly(ctx, false)
+		if err = s.RenewToken(ctx); err == nil {
+			s.Put(ctx, cfg.SessionKey, userID)
+		}
+		s.SetReadOnly(ctx, readOnly)
+
+		if err != nil {
+			return err
+		}
+
+		if cfg.OnRestore != nil {
+			if err = cfg.OnRestore(e, userID); err != nil {
+				return err
+			}
+		}
+
+		return e.Next()
+	}
+}
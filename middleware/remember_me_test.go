@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/session"
+)
+
+// memoryRememberStore is a minimal in-memory session.RememberStore.
+type memoryRememberStore struct {
+	tokens map[string]session.RememberToken
+	mu     sync.Mutex
+}
+
+func (s *memoryRememberStore) Save(_ context.Context, token session.RememberToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tokens == nil {
+		s.tokens = make(map[string]session.RememberToken)
+	}
+	s.tokens[token.Selector] = token
+	return nil
+}
+
+func (s *memoryRememberStore) Find(_ context.Context, selector string) (session.RememberToken, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[selector]
+	return token, ok, nil
+}
+
+func (s *memoryRememberStore) Delete(_ context.Context, selector string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, selector)
+	return nil
+}
+
+func (s *memoryRememberStore) DeleteUser(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for selector, token := range s.tokens {
+		if token.UserID == userID {
+			delete(s.tokens, selector)
+		}
+	}
+	return nil
+}
+
+func newRememberMeTestEvent(t *testing.T, s *session.Session, cookie string) *wo.Event {
+	t.Helper()
+
+	e := newSessionTestEvent(http.MethodGet, "/", map[string]string{"Cookie": cookie})
+	r, err := s.ReadSessionCookie(e.Request())
+	require.NoError(t, err)
+	e.SetRequest(r)
+	return e
+}
+
+func TestRememberMe(t *testing.T) {
+	store := &mockStore{}
+	store.On("Find", mock.Anything, mock.Anything).Return(nil, false, nil)
+
+	s := session.New(session.Config{}, store)
+	rm := session.NewRemember(session.RememberConfig{}, &memoryRememberStore{})
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, rm.Issue(context.Background(), rec, "42"))
+	cookie := rec.Result().Cookies()[0]
+
+	var restored string
+	middleware := RememberMe(RememberMeConfig[*wo.Event]{
+		OnRestore: func(_ *wo.Event, userID string) error {
+			restored = userID
+			return nil
+		},
+	}, s, rm)
+
+	e := newRememberMeTestEvent(t, s, "session=expired; "+cookie.String())
+	s.SetReadOnly(e.Context(), true)
+	require.NoError(t, middleware(e))
+
+	ctx := e.Context()
+	assert.Equal(t, "42", restored)
+	assert.Equal(t, "42", s.GetString(ctx, "userID"))
+	assert.NotEmpty(t, s.Token(ctx))
+	assert.Equal(t, session.Modified, s.Status(ctx))
+	assert.True(t, s.IsReadOnly(ctx))
+	assert.Contains(t, e.Response().Header().Get(wo.HeaderSetCookie), "remember=")
+}
+
+func TestRememberMe_Skips(t *testing.T) {
+	store := &mockStore{}
+	store.On("Find", mock.Anything, mock.Anything).Return(nil, false, nil)
+
+	s := session.New(session.Config{}, store)
+	rm := session.NewRemember(session.RememberConfig{}, &memoryRememberStore{})
+
+	tests := []struct {
+		name   string
+		cookie string
+		setup  func(ctx context.Context)
+	}{
+		{name: "no remember cookie"},
+		{name: "invalid remember cookie", cookie: "remember=unknown:verifier"},
+		{name: "authenticated session", cookie: "remember=unknown:verifier", setup: func(ctx context.Context) {
+			s.Put(ctx, "userID", "7")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newRememberMeTestEvent(t, s, tt.cookie)
+			if tt.setup != nil {
+				tt.setup(e.Context())
+			}
+			status := s.Status(e.Context())
+
+			require.NoError(t, RememberMe(RememberMeConfig[*wo.Event]{}, s, rm)(e))
+			assert.Equal(t, status, s.Status(e.Context()))
+		})
+	}
+}
+
+func TestRememberMe_OnRestoreError(t *testing.T) {
+	store := &mockStore{}
+	store.On("Find", mock.Anything, mock.Anything).Return(nil, false, nil)
+
+	s := session.New(session.Config{}, store)
+	rm := session.NewRemember(session.RememberConfig{}, &memoryRememberStore{})
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, rm.Issue(context.Background(), rec, "42"))
+
+	errRestore := errors.New("user disabled")
+	middleware := RememberMe(RememberMeConfig[*wo.Event]{
+		OnRestore: func(*wo.Event, string) error { return errRestore },
+	}, s, rm)
+
+	e := newRememberMeTestEvent(t, s, rec.Result().Cookies()[0].String())
+	assert.ErrorIs(t, middleware(e), errRestore)
+}
+
+func TestRememberMe_Panics(t *testing.T) {
+	s := session.New(session.Config{}, &mockStore{})
+	rm := session.NewRemember(session.RememberConfig{}, &memoryRememberStore{})
+
+	assert.Panics(t, func() { RememberMe[*wo.Event](RememberMeConfig[*wo.Event]{}, nil, rm) })
+	assert.Panics(t, func() { RememberMe[*wo.Event](RememberMeConfig[*wo.Event]{}, s, nil) })
+}
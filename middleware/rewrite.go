@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gowool/wo"
+)
+
+// RewriteConstraints are the ordering constraints of the Rewrite middleware registered as
+// "rewrite": it runs inside Compress, so it sees the uncompressed body and Compress compresses
+// the rewritten one.
+//
+//	r.Bind(&hook.Handler[*wo.Event]{ID: "rewrite", Func: middleware.Rewrite[*wo.Event](cfg)})
+//	r.Constrain("rewrite", middleware.RewriteConstraints)
+var RewriteConstraints = wo.MiddlewareConstraints{After: []string{"compress"}}
+
+type RewriteConfig[T wo.Resolver] struct {
+	// Header modifies the response headers right before they are written, ex. rewrites the
+	// absolute Location behind the proxy. It's called for all the responses of the handler.
+	//
+	// Optional.
+	Header func(e T, status int, h http.Header) `json:"-" yaml:"-"`
+
+	// Body rewrites the buffered response body, ex. injects the analytics snippet into the HTML.
+	// It's called for the responses of ContentTypes only, before Header, the bodies larger than
+	// MaxBodySize, the encoded ones (see Content-Encoding), the flushed and the empty ones are
+	// written as is. The error is returned by the middleware, nothing is written.
+	//
+	// Optional.
+	Body func(e T, status int, body []byte) ([]byte, error) `json:"-" yaml:"-"`
+
+	// ContentTypes are the media types of the responses whose body is rewritten.
+	//
+	// Default: ["text/html"]
+	ContentTypes []string `env:"CONTENT_TYPES" json:"contentTypes,omitempty" yaml:"contentTypes,omitempty"`
+
+	// MaxBodySize is the maximum size of the buffered body in bytes.
+	//
+	// Default: 4MB
+	MaxBodySize int `env:"MAX_BODY_SIZE" json:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+}
+
+func (c *RewriteConfig[T]) SetDefaults() {
+	if len(c.ContentTypes) == 0 {
+		c.ContentTypes = []string{wo.MIMETextHTML}
+	}
+	if c.MaxBodySize == 0 {
+		c.MaxBodySize = 4 << 20
+	}
+}
+
+func (c *RewriteConfig[T]) Validate() error {
+	if c.Header == nil && c.Body == nil {
+		return errors.New("rewrite: header or body func is required")
+	}
+	if c.MaxBodySize < 0 {
+		return errors.New("rewrite: max body size must not be negative")
+	}
+	return nil
+}
+
+// Rewrite modifies the response headers and bodies of the handler via the callbacks, see
+// [RewriteConfig]. The bodies are buffered, the stale Content-Length of the rewritten ones is
+// removed. Register it inside Compress, see [RewriteConstraints], otherwise the compressed
+// bodies are written as is. The responses written by the error handler aren't rewritten.
+//
+//	middleware.Rewrite[*wo.Event](middleware.RewriteConfig[*wo.Event]{
+//		Body: func(_ *wo.Event, _ int, body []byte) ([]byte, error) {
+//			return bytes.Replace(body, []byte("</body>"), []byte(snippet+"</body>"), 1), nil
+//		},
+//	})
+func Rewrite[T wo.Resolver](cfg RewriteConfig[T], skippers ...Skipper[T]) func(T) error {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	contentTypes := make([]string, len(cfg.ContentTypes))
+	for i, ct := range cfg.ContentTypes {
+		contentTypes[i] = strings.ToLower(strings.TrimSpace(ct))
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		rw := e.Response()
+		w := &rewriteResponseWriter[T]{
+			ResponseWriter: rw,
+			event:          e,
+			config:         &cfg,
+			contentTypes:   contentTypes,
+			code:           http.StatusOK,
+		}
+		e.SetResponse(w)
+
+		err := e.Next()
+		e.SetResponse(rw)
+
+		if err != nil || !w.buffering {
+			if err == nil && w.wroteHeader && !w.committed {
+				// the status without the body, ex. the redirect
+				w.commit()
+			}
+			return err
+		}
+
+		body := w.buffer.Bytes()
+		if len(body) > 0 {
+			if body, err = cfg.Body(e, w.code, body); err != nil {
+				return err
+			}
+			w.Header().Del(wo.HeaderContentLength)
+		}
+
+		w.commit()
+		_, err = rw.Write(body)
+		return err
+	}
+}
+
+type rewriteResponseWriter[T wo.Resolver] struct {
+	http.ResponseWriter
+	event        T
+	config       *RewriteConfig[T]
+	contentTypes []string
+	buffer       bytes.Buffer
+	code         int
+	wroteHeader  bool
+	decided      bool
+	buffering    bool
+	committed    bool
+}
+
+func (w *rewriteResponseWriter[T]) WriteHeader(code int) {
+	if w.committed {
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		// the informational responses, ex. 103 Early Hints, are written at once
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.wroteHeader = true
+	w.code = code
+}
+
+func (w *rewriteResponseWriter[T]) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.decide(b)
+	}
+
+	if !w.buffering {
+		w.commit()
+		return w.ResponseWriter.Write(b)
+	}
+
+	if w.buffer.Len()+len(b) > w.config.MaxBodySize {
+		// too large to be rewritten, the buffered part is written as is
+		w.passthrough()
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buffer.Write(b)
+}
+
+func (w *rewriteResponseWriter[T]) Flush() {
+	if w.buffering {
+		// the streamed body isn't rewritten
+		w.passthrough()
+	} else {
+		w.commit()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// decide reports whether the body is buffered for the rewrite, by the first written chunk.
+func (w *rewriteResponseWriter[T]) decide(b []byte) {
+	w.decided = true
+
+	h := w.Header()
+	if h.Get(wo.HeaderContentType) == "" {
+		h.Set(wo.HeaderContentType, http.DetectContentType(b))
+	}
+	if w.config.Body == nil || h.Get(wo.HeaderContentEncoding) != "" || !bodyAllowedForStatus(w.code) {
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(h.Get(wo.HeaderContentType))
+	w.buffering = err == nil && slices.Contains(w.contentTypes, mediaType)
+}
+
+// passthrough gives up the rewrite and writes the buffered part of the body as is.
+func (w *rewriteResponseWriter[T]) passthrough() {
+	w.buffering = false
+	w.commit()
+	_, _ = w.buffer.WriteTo(w.ResponseWriter)
+}
+
+// commit calls the Header func and writes the response header once.
+func (w *rewriteResponseWriter[T]) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+
+	if w.config.Header != nil {
+		w.config.Header(w.event, w.code, w.Header())
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+func (w *rewriteResponseWriter[T]) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *rewriteResponseWriter[T]) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *rewriteResponseWriter[T]) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// bodyAllowedForStatus reports whether the status permits the response body, see RFC 9110.
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func newRewriteTestRouter(t *testing.T, handler func(e *wo.Event) error, middlewares ...*hook.Handler[*wo.Event]) http.Handler {
+	t.Helper()
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+	router.Bind(middlewares...)
+	router.GET("/", handler)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+	return h
+}
+
+func injectSnippet(_ *wo.Event, _ int, body []byte) ([]byte, error) {
+	return bytes.Replace(body, []byte("</body>"), []byte("<script>track()</script></body>"), 1), nil
+}
+
+func TestRewrite(t *testing.T) {
+	const page = "<html><body>hello</body></html>"
+
+	tests := []struct {
+		name           string
+		config         RewriteConfig[*wo.Event]
+		handler        func(e *wo.Event) error
+		expectedStatus int
+		expectedBody   string
+		expectedHeader http.Header
+	}{
+		{
+			name:   "html body",
+			config: RewriteConfig[*wo.Event]{Body: injectSnippet},
+			handler: func(e *wo.Event) error {
+				return e.HTML(http.StatusOK, page)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<html><body>hello<script>track()</script></body></html>",
+			expectedHeader: http.Header{"Content-Length": nil},
+		},
+		{
+			name:   "other content type",
+			config: RewriteConfig[*wo.Event]{Body: injectSnippet},
+			handler: func(e *wo.Event) error {
+				return e.String(http.StatusOK, page)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   page,
+		},
+		{
+			name:   "too large body",
+			config: RewriteConfig[*wo.Event]{Body: injectSnippet, MaxBodySize: 10},
+			handler: func(e *wo.Event) error {
+				return e.HTML(http.StatusOK, page)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   page,
+		},
+		{
+			name:   "encoded body",
+			config: RewriteConfig[*wo.Event]{Body: injectSnippet},
+			handler: func(e *wo.Event) error {
+				e.Response().Header().Set(wo.HeaderContentEncoding, "br")
+				return e.HTML(http.StatusOK, page)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   page,
+		},
+		{
+			name:   "streamed body",
+			config: RewriteConfig[*wo.Event]{Body: injectSnippet},
+			handler: func(e *wo.Event) error {
+				e.Response().Header().Set(wo.HeaderContentType, wo.MIMETextHTMLCharsetUTF8)
+				_, _ = io.WriteString(e.Response(), "<html><body>")
+				_ = http.NewResponseController(e.Response()).Flush()
+				_, err := io.WriteString(e.Response(), "hello</body></html>")
+				return err
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   page,
+		},
+		{
+			name: "header",
+			config: RewriteConfig[*wo.Event]{
+				Header: func(_ *wo.Event, status int, h http.Header) {
+					if status == http.StatusFound {
+						h.Set(wo.HeaderLocation, strings.Replace(h.Get(wo.HeaderLocation), "http://backend:8080", "https://example.com", 1))
+					}
+				},
+			},
+			handler: func(e *wo.Event) error {
+				return e.Redirect(http.StatusFound, "http://backend:8080/login")
+			},
+			expectedStatus: http.StatusFound,
+			expectedHeader: http.Header{"Location": {"https://example.com/login"}},
+		},
+		{
+			name: "header and body",
+			config: RewriteConfig[*wo.Event]{
+				Header: func(_ *wo.Event, _ int, h http.Header) {
+					h.Set("X-Body-Length", h.Get(wo.HeaderContentLength))
+				},
+				Body: injectSnippet,
+			},
+			handler: func(e *wo.Event) error {
+				e.Response().Header().Set(wo.HeaderContentLength, "31")
+				return e.HTML(http.StatusCreated, page)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   "<html><body>hello<script>track()</script></body></html>",
+			expectedHeader: http.Header{"X-Body-Length": {""}},
+		},
+		{
+			name: "body error",
+			config: RewriteConfig[*wo.Event]{
+				Body: func(*wo.Event, int, []byte) ([]byte, error) {
+					return nil, wo.ErrBadGateway
+				},
+			},
+			handler: func(e *wo.Event) error {
+				return e.HTML(http.StatusOK, page)
+			},
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			name:   "handler error",
+			config: RewriteConfig[*wo.Event]{Body: injectSnippet},
+			handler: func(e *wo.Event) error {
+				_, _ = io.WriteString(e.Response(), page)
+				return errors.New("boom")
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newRewriteTestRouter(t, tt.handler, &hook.Handler[*wo.Event]{Func: Rewrite[*wo.Event](tt.config)})
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+			for k, v := range tt.expectedHeader {
+				assert.Equal(t, v, rec.Header().Values(k), k)
+			}
+		})
+	}
+}
+
+func TestRewrite_InsideCompress(t *testing.T) {
+	page := "<html><body>" + strings.Repeat("hello ", 500) + "</body></html>"
+
+	newRouter := func(middlewares ...*hook.Handler[*wo.Event]) (http.Handler, error) {
+		router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+			e := new(wo.Event)
+			e.Reset(w, r)
+			return e, nil
+		}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+		router.Constrain("rewrite", RewriteConstraints)
+		router.Bind(middlewares...)
+		router.GET("/", func(e *wo.Event) error {
+			return e.HTML(http.StatusOK, page)
+		})
+		return router.Build(nil)
+	}
+
+	compress := &hook.Handler[*wo.Event]{ID: "compress", Func: Compress[*wo.Event](CompressConfig{})}
+	rewrite := &hook.Handler[*wo.Event]{ID: "rewrite", Func: Rewrite[*wo.Event](RewriteConfig[*wo.Event]{Body: injectSnippet})}
+
+	t.Run("misordered", func(t *testing.T) {
+		_, err := newRouter(rewrite, compress)
+		var orderErr *wo.MiddlewareOrderError
+		require.ErrorAs(t, err, &orderErr)
+	})
+
+	t.Run("ordered", func(t *testing.T) {
+		h, err := newRouter(compress, rewrite)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(wo.HeaderAcceptEncoding, gzipScheme)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, gzipScheme, rec.Header().Get(wo.HeaderContentEncoding))
+		assert.Empty(t, rec.Header().Get(wo.HeaderContentLength))
+
+		r, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(string(body), "<script>track()</script></body></html>"))
+	})
+}
+
+func TestRewrite_InvalidConfig(t *testing.T) {
+	assert.Panics(t, func() {
+		Rewrite[*wo.Event](RewriteConfig[*wo.Event]{})
+	})
+	assert.Panics(t, func() {
+		Rewrite[*wo.Event](RewriteConfig[*wo.Event]{Body: injectSnippet, MaxBodySize: -1})
+	})
+}
@@ -0,0 +1,292 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gowool/wo"
+)
+
+// Rule is the URL rewrite or redirect rule, see [Rules].
+type Rule struct {
+	// Match is the pattern of the request path: the path pattern with the {name} wildcards
+	// matching a segment and the trailing {name...} one matching the rest of the path, ex.
+	// "/blog/{year}/{slug...}", or the regular expression if Regexp, ex. "^/old/(.*)$".
+	Match string `json:"match" yaml:"match"`
+
+	// Regexp reports whether Match is the regular expression.
+	Regexp bool `json:"regexp,omitempty" yaml:"regexp,omitempty"`
+
+	// Host is the host the rule is limited to, ex. "old.example.com", the port is ignored.
+	//
+	// Optional.
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+
+	// Target is the rewritten path or the redirect URL, with the {name} wildcards of the path
+	// pattern, or the $1 and ${name} submatches of the regular expression, ex.
+	// "https://example.com/posts/{slug...}". The query of the target is kept.
+	Target string `json:"target" yaml:"target"`
+
+	// Status is the redirect status: 301, 302, 303, 307 or 308, the request is rewritten
+	// internally if zero.
+	//
+	// Default: 0
+	Status int `json:"status,omitempty" yaml:"status,omitempty"`
+
+	// PreserveQuery appends the query of the request to the target.
+	PreserveQuery bool `json:"preserveQuery,omitempty" yaml:"preserveQuery,omitempty"`
+}
+
+type RulesConfig struct {
+	// Rules are the rules tried in order, the first matching one applies.
+	Rules []Rule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+func (c *RulesConfig) Validate() error {
+	_, err := compileRules(c.Rules)
+	return err
+}
+
+// RuleSet holds the compiled rules of the [Rules] middleware, they are replaced at runtime by
+// Load, ex. on the config file change, without rebuilding the router.
+type RuleSet struct {
+	rules atomic.Pointer[[]compiledRule]
+}
+
+func NewRuleSet(cfg RulesConfig) *RuleSet {
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	s := new(RuleSet)
+	_ = s.Load(cfg)
+	return s
+}
+
+// Load replaces the rules, the current ones are kept if the new ones are invalid.
+//
+//	if err := config.Load(&cfg, config.File("rules.yaml")); err == nil {
+//		err = rules.Load(cfg)
+//	}
+func (s *RuleSet) Load(cfg RulesConfig) error {
+	rules, err := compileRules(cfg.Rules)
+	if err != nil {
+		return err
+	}
+	s.rules.Store(&rules)
+	return nil
+}
+
+// Apply applies the first rule matching the request: it returns the redirect URL and status,
+// or the rewritten request with the zero status. It reports whether a rule matched.
+func (s *RuleSet) Apply(r *http.Request) (*http.Request, string, int, bool) {
+	rules := s.rules.Load()
+	if rules == nil {
+		return r, "", 0, false
+	}
+
+	host := strings.ToLower(r.Host)
+	if name, _, ok := splitHost(host); ok {
+		host = name
+	}
+
+	for _, rule := range *rules {
+		if rule.host != "" && rule.host != host {
+			continue
+		}
+
+		match := rule.match.FindStringSubmatchIndex(r.URL.Path)
+		if match == nil {
+			continue
+		}
+
+		target := string(rule.match.ExpandString(nil, rule.target, r.URL.Path, match))
+		if rule.preserveQuery && r.URL.RawQuery != "" {
+			if strings.Contains(target, "?") {
+				target += "&" + r.URL.RawQuery
+			} else {
+				target += "?" + r.URL.RawQuery
+			}
+		}
+
+		if rule.status != 0 {
+			return r, target, rule.status, true
+		}
+
+		u := *r.URL
+		u.Path, u.RawQuery, _ = strings.Cut(target, "?")
+		u.RawPath = ""
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = &u
+		r2.RequestURI = u.RequestURI()
+		return r2, "", 0, true
+	}
+	return r, "", 0, false
+}
+
+// Rules rewrites the request URLs or redirects the requests per the rules of the set, ex. the
+// legacy URLs, instead of the scattered redirect handlers. Bind it as the Router.Pre middleware,
+// so the rewritten requests are routed by the new paths. The redirects are returned as
+// [wo.RedirectError].
+//
+//	rules := middleware.NewRuleSet(middleware.RulesConfig{Rules: []middleware.Rule{
+//		{Match: "/blog/{slug...}", Target: "/posts/{slug...}", Status: http.StatusMovedPermanently, PreserveQuery: true},
+//		{Match: `^/u/(\d+)$`, Regexp: true, Target: "/users/$1"},
+//	}})
+//	r.Pre(&hook.Handler[*wo.Event]{Func: middleware.Rules[*wo.Event](rules)})
+func Rules[T wo.Resolver](rules *RuleSet, skippers ...Skipper[T]) func(T) error {
+	if rules == nil {
+		panic("rules: rule set is required")
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		r, target, status, ok := rules.Apply(e.Request())
+		if !ok {
+			return e.Next()
+		}
+		if status != 0 {
+			return &wo.RedirectError{Status: status, URL: target}
+		}
+
+		e.SetRequest(r)
+		return e.Next()
+	}
+}
+
+type compiledRule struct {
+	match         *regexp.Regexp
+	host          string
+	target        string
+	status        int
+	preserveQuery bool
+}
+
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rules: rule %d: %w", i, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+func compileRule(rule Rule) (compiledRule, error) {
+	if rule.Match == "" {
+		return compiledRule{}, errors.New("match is required")
+	}
+	if rule.Target == "" {
+		return compiledRule{}, errors.New("target is required")
+	}
+	switch rule.Status {
+	case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return compiledRule{}, fmt.Errorf("invalid redirect status %d", rule.Status)
+	}
+
+	c := compiledRule{
+		host:          strings.ToLower(rule.Host),
+		target:        rule.Target,
+		status:        rule.Status,
+		preserveQuery: rule.PreserveQuery,
+	}
+	if c.status == 0 && !strings.HasPrefix(c.target, "/") {
+		return compiledRule{}, fmt.Errorf("rewrite target %q must be the path", rule.Target)
+	}
+
+	var err error
+	if rule.Regexp {
+		c.match, err = regexp.Compile(rule.Match)
+	} else {
+		var expr string
+		if expr, c.target, err = pathPatternRegexp(rule.Match, rule.Target); err == nil {
+			c.match, err = regexp.Compile(expr)
+		}
+	}
+	if err != nil {
+		return compiledRule{}, err
+	}
+	return c, nil
+}
+
+// pathPatternRegexp returns the regular expression of the path pattern and the target with
+// the wildcards replaced by the submatches, ex. "/blog/{slug...}" is "^/blog/(?P<slug>.*)$".
+func pathPatternRegexp(pattern, target string) (string, string, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return "", "", fmt.Errorf("pattern %q must start with /", pattern)
+	}
+
+	// the dollars of the target are literal in the path pattern rules
+	target = strings.ReplaceAll(target, "$", "$$")
+
+	var b strings.Builder
+	b.WriteByte('^')
+
+	for rest := pattern; rest != ""; {
+		i := strings.IndexByte(rest, '{')
+		if i < 0 {
+			b.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		b.WriteString(regexp.QuoteMeta(rest[:i]))
+
+		j := strings.IndexByte(rest, '}')
+		if j < i {
+			return "", "", fmt.Errorf("pattern %q has unbalanced braces", pattern)
+		}
+		name := rest[i+1 : j]
+		rest = rest[j+1:]
+
+		multi := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+		if !isPatternName(name) {
+			return "", "", fmt.Errorf("pattern %q has invalid wildcard name %q", pattern, name)
+		}
+		if !strings.HasSuffix(b.String(), "/") {
+			return "", "", fmt.Errorf("pattern %q wildcard %q must be the whole segment", pattern, name)
+		}
+
+		switch {
+		case multi && rest != "":
+			return "", "", fmt.Errorf("pattern %q wildcard %q must be at the end", pattern, name)
+		case multi:
+			b.WriteString("(?P<" + name + ">.*)")
+		case rest != "" && rest[0] != '/':
+			return "", "", fmt.Errorf("pattern %q wildcard %q must be the whole segment", pattern, name)
+		default:
+			b.WriteString("(?P<" + name + ">[^/]+)")
+		}
+		target = strings.ReplaceAll(target, "{"+name+"}", "${"+name+"}")
+		target = strings.ReplaceAll(target, "{"+name+"...}", "${"+name+"}")
+	}
+	b.WriteByte('$')
+
+	return b.String(), target, nil
+}
+
+func isPatternName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
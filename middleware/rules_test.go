@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestRules(t *testing.T) {
+	rules := NewRuleSet(RulesConfig{Rules: []Rule{
+		{Match: "/blog/{year}/{slug...}", Target: "/posts/{slug...}?year={year}", Status: http.StatusMovedPermanently, PreserveQuery: true},
+		{Match: `^/u/(\d+)$`, Regexp: true, Target: "/users/$1"},
+		{Match: "/docs/{page}", Target: "/help/{page}", PreserveQuery: true},
+		{Match: "/old", Host: "old.example.com", Target: "https://example.com/new", Status: http.StatusFound},
+		{Match: "/price", Target: "/cost?currency=$"},
+	}})
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+	router.Pre(&hook.Handler[*wo.Event]{Func: Rules[*wo.Event](rules)})
+	router.GET("/{path...}", func(e *wo.Event) error {
+		return e.String(http.StatusOK, e.Request().URL.RequestURI())
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		host             string
+		target           string
+		expectedStatus   int
+		expectedLocation string
+		expectedBody     string
+	}{
+		{
+			name:             "pattern redirect",
+			target:           "/blog/2024/hello/world?ref=feed",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "/posts/hello/world?year=2024&ref=feed",
+		},
+		{name: "regexp rewrite", target: "/u/42?tab=posts", expectedStatus: http.StatusOK, expectedBody: "/users/42"},
+		{name: "regexp mismatch", target: "/u/bob", expectedStatus: http.StatusOK, expectedBody: "/u/bob"},
+		{name: "pattern rewrite", target: "/docs/intro?lang=en", expectedStatus: http.StatusOK, expectedBody: "/help/intro?lang=en"},
+		{name: "pattern segment", target: "/docs/intro/more", expectedStatus: http.StatusOK, expectedBody: "/docs/intro/more"},
+		{
+			name:             "host redirect",
+			host:             "OLD.example.com:8080",
+			target:           "/old",
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "https://example.com/new",
+		},
+		{name: "other host", target: "/old", expectedStatus: http.StatusOK, expectedBody: "/old"},
+		{name: "literal dollar", target: "/price", expectedStatus: http.StatusOK, expectedBody: "/cost?currency=$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedLocation, rec.Header().Get(wo.HeaderLocation))
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRuleSet_Load(t *testing.T) {
+	rules := NewRuleSet(RulesConfig{Rules: []Rule{{Match: "/a", Target: "/b"}}})
+
+	apply := func(path string) string {
+		r, _, _, _ := rules.Apply(httptest.NewRequest(http.MethodGet, path, nil))
+		return r.URL.Path
+	}
+	assert.Equal(t, "/b", apply("/a"))
+
+	require.NoError(t, rules.Load(RulesConfig{Rules: []Rule{{Match: "/a", Target: "/c"}}}))
+	assert.Equal(t, "/c", apply("/a"))
+
+	require.Error(t, rules.Load(RulesConfig{Rules: []Rule{{Match: "/a", Target: "/d", Status: http.StatusOK}}}))
+	assert.Equal(t, "/c", apply("/a"), "the invalid rules must not replace the current ones")
+}
+
+func TestRulesConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{name: "empty match", rule: Rule{Target: "/b"}},
+		{name: "empty target", rule: Rule{Match: "/a"}},
+		{name: "status", rule: Rule{Match: "/a", Target: "/b", Status: http.StatusOK}},
+		{name: "rewrite to url", rule: Rule{Match: "/a", Target: "https://example.com/b"}},
+		{name: "relative pattern", rule: Rule{Match: "a", Target: "/b"}},
+		{name: "unbalanced braces", rule: Rule{Match: "/a/{id", Target: "/b"}},
+		{name: "invalid name", rule: Rule{Match: "/a/{1d}", Target: "/b"}},
+		{name: "partial segment", rule: Rule{Match: "/a/x{id}", Target: "/b"}},
+		{name: "partial segment suffix", rule: Rule{Match: "/a/{id}x", Target: "/b"}},
+		{name: "multi not at end", rule: Rule{Match: "/a/{rest...}/b", Target: "/b"}},
+		{name: "invalid regexp", rule: Rule{Match: "(", Regexp: true, Target: "/b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := RulesConfig{Rules: []Rule{tt.rule}}
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gowool/wo"
+)
+
+type ServerTimingConfig[T wo.Resolver] struct {
+	// Total adds the "total" metric with the time elapsed until the response headers are written.
+	//
+	// Default: false
+	Total bool `env:"TOTAL" json:"total,omitempty" yaml:"total,omitempty"`
+
+	// DebugOnly emits the header only for debug requests, see [wo.Debug].
+	//
+	// Default: false
+	DebugOnly bool `env:"DEBUG_ONLY" json:"debugOnly,omitempty" yaml:"debugOnly,omitempty"`
+
+	// Allow reports whether the header is emitted for the request, ex. only for authenticated staff.
+	// It is evaluated right before the response headers are written.
+	//
+	// Default: nil (always)
+	Allow func(T) bool `json:"-" yaml:"-"`
+}
+
+// ServerTiming collects the metrics recorded via [wo.Event.Timing] or [wo.ServerTiming]
+// and emits the Server-Timing header before the response is written.
+func ServerTiming[T wo.Resolver](cfg ServerTimingConfig[T], skippers ...Skipper[T]) func(T) error {
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		start := time.Now()
+
+		st := wo.ServerTimingFromContext(e.Request().Context())
+		if st == nil {
+			st = new(wo.ServerTiming)
+			e.SetRequest(e.Request().WithContext(wo.WithServerTiming(e.Request().Context(), st)))
+		}
+
+		res := wo.MustUnwrapResponse(e.Response())
+		res.Before(func() {
+			if cfg.DebugOnly && !wo.Debug(e.Request().Context()) {
+				return
+			}
+			if cfg.Allow != nil && !cfg.Allow(e) {
+				return
+			}
+			if cfg.Total {
+				st.Add("total", time.Since(start), "")
+			}
+			if value := st.String(); value != "" {
+				res.Header().Add(wo.HeaderServerTiming, value)
+			}
+		})
+
+		return e.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestServerTiming(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    ServerTimingConfig[*wo.Event]
+		debug  bool
+		expect string
+	}{
+		{
+			name:   "always",
+			expect: `db;dur=5;desc="Query"`,
+		},
+		{
+			name:   "debug only without debug",
+			cfg:    ServerTimingConfig[*wo.Event]{DebugOnly: true},
+			expect: "",
+		},
+		{
+			name:   "debug only with debug",
+			cfg:    ServerTimingConfig[*wo.Event]{DebugOnly: true},
+			debug:  true,
+			expect: `db;dur=5;desc="Query"`,
+		},
+		{
+			name: "not allowed",
+			cfg: ServerTimingConfig[*wo.Event]{Allow: func(e *wo.Event) bool {
+				return e.Request().Header.Get(wo.HeaderAuthorization) != ""
+			}},
+			expect: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newRLEvent()
+			e.SetDebug(tt.debug)
+
+			require.NoError(t, ServerTiming[*wo.Event](tt.cfg)(e))
+
+			e.Timing("db", 5*time.Millisecond, "Query")
+			e.Response().WriteHeader(http.StatusOK)
+
+			assert.Equal(t, tt.expect, e.Response().Header().Get(wo.HeaderServerTiming))
+		})
+	}
+}
+
+func TestServerTiming_Total(t *testing.T) {
+	e := newRLEvent()
+
+	require.NoError(t, ServerTiming[*wo.Event](ServerTimingConfig[*wo.Event]{Total: true})(e))
+
+	e.Response().WriteHeader(http.StatusOK)
+
+	assert.True(t, strings.HasPrefix(e.Response().Header().Get(wo.HeaderServerTiming), "total;dur="))
+}
+
+func TestServerTiming_Skipper(t *testing.T) {
+	e := newRLEvent()
+
+	skipper := func(*wo.Event) bool { return true }
+	require.NoError(t, ServerTiming[*wo.Event](ServerTimingConfig[*wo.Event]{}, skipper)(e))
+
+	assert.Nil(t, wo.ServerTimingFromContext(e.Context()))
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gowool/wo"
@@ -11,7 +12,19 @@ type ErrorLogger interface {
 	Error(msg string, keysAndValues ...any)
 }
 
+type SessionConfig struct {
+	// ReadOnlyGET marks the sessions of GET and HEAD requests as read-only, so accidental
+	// writes don't force a commit on every page view. See [session.Session.SetReadOnly].
+	ReadOnlyGET bool `env:"READ_ONLY_GET" json:"readOnlyGet,omitempty" yaml:"readOnlyGet,omitempty"`
+}
+
 func Session[T wo.Resolver](s *session.Session, logger ErrorLogger, skippers ...Skipper[T]) func(T) error {
+	return SessionWithConfig(SessionConfig{}, s, logger, skippers...)
+}
+
+// SessionWithConfig is the [Session] middleware with the config, ex. to mark the sessions
+// of the GET requests as read-only.
+func SessionWithConfig[T wo.Resolver](cfg SessionConfig, s *session.Session, logger ErrorLogger, skippers ...Skipper[T]) func(T) error {
 	if s == nil {
 		panic("session middleware: session is nil")
 	}
@@ -28,10 +41,24 @@ func Session[T wo.Resolver](s *session.Session, logger ErrorLogger, skippers ...
 			return err
 		}
 
+		if cfg.ReadOnlyGET && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			s.SetReadOnly(r.Context(), true)
+		}
+
 		e.SetRequest(r)
 		wo.MustUnwrapResponse(e.Response()).Before(func() {
 			ctx := e.Request().Context()
 
+			// the privilege change or the rotation interval renews the token before the commit
+			if _, err := s.RenewTokenIfNeeded(ctx); err != nil && logger != nil {
+				logger.Error("failed to renew session token", "error", err)
+			}
+
+			// the lazy loaded session hasn't been accessed
+			if !s.Loaded(ctx) {
+				return
+			}
+
 			switch s.Status(ctx) {
 			case session.Modified:
 				token, expiry, err := s.Commit(ctx)
@@ -440,3 +440,118 @@ func TestSession_ChainSkipper(t *testing.T) {
 	err := middleware(e)
 	assert.NoError(t, err)
 }
+
+func TestSession_Lazy(t *testing.T) {
+	codec := session.NewGobCodec()
+	encodedData, err := codec.Encode(time.Now().Add(time.Hour), map[string]any{"user": "testuser"})
+	require.NoError(t, err)
+
+	t.Run("untouched session skips the store", func(t *testing.T) {
+		mockStore := &mockStore{}
+
+		s := session.New(session.Config{Lazy: true}, mockStore)
+		middleware := Session[*wo.Event](s, nil)
+
+		e := newSessionTestEvent(http.MethodGet, "/test", map[string]string{"Cookie": "session=valid-token"})
+
+		require.NoError(t, middleware(e))
+		e.Response().WriteHeader(http.StatusOK)
+
+		assert.False(t, s.Loaded(e.Context()))
+		assert.Empty(t, e.Response().Header().Get(wo.HeaderSetCookie))
+		mockStore.AssertNotCalled(t, "Find", mock.Anything, mock.Anything)
+	})
+
+	t.Run("first access loads the session", func(t *testing.T) {
+		mockStore := &mockStore{}
+		mockStore.On("Find", mock.Anything, "valid-token").Return(encodedData, true, nil).Once()
+
+		s := session.New(session.Config{Lazy: true}, mockStore)
+		middleware := Session[*wo.Event](s, nil)
+
+		e := newSessionTestEvent(http.MethodGet, "/test", map[string]string{"Cookie": "session=valid-token"})
+
+		require.NoError(t, middleware(e))
+		assert.Equal(t, "testuser", s.GetString(e.Context(), "user"))
+		assert.Equal(t, "valid-token", s.Token(e.Context()))
+		assert.True(t, s.Loaded(e.Context()))
+
+		mockStore.AssertExpectations(t)
+	})
+
+	t.Run("store error is reported on commit", func(t *testing.T) {
+		mockStore := &mockStore{}
+		mockStore.On("Find", mock.Anything, "valid-token").Return(nil, false, errors.New("store down"))
+
+		s := session.New(session.Config{Lazy: true}, mockStore)
+		logger := &mockErrorLogger{}
+		middleware := Session[*wo.Event](s, logger)
+
+		e := newSessionTestEvent(http.MethodGet, "/test", map[string]string{"Cookie": "session=valid-token"})
+
+		require.NoError(t, middleware(e))
+		s.Put(e.Context(), "user", "other")
+		e.Response().WriteHeader(http.StatusOK)
+
+		assert.Equal(t, []string{"failed to commit session"}, logger.errors)
+		assert.Empty(t, e.Response().Header().Get(wo.HeaderSetCookie))
+		mockStore.AssertNotCalled(t, "Commit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSession_PrivilegeChange(t *testing.T) {
+	store := session.NewMemoryStore(session.MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	s := session.New(session.Config{}, store)
+
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+	s.Put(ctx, "cart", "1")
+	token, _, err := s.Commit(ctx)
+	require.NoError(t, err)
+
+	middleware := Session[*wo.Event](s, nil)
+
+	e := newSessionTestEvent(http.MethodPost, "/login", map[string]string{"Cookie": "session=" + token})
+	require.NoError(t, middleware(e))
+
+	session.MarkPrivilegeChange(e.Context())
+	s.Put(e.Context(), "user", "ann")
+	e.Response().WriteHeader(http.StatusOK)
+
+	cookie := e.Response().Header().Get(wo.HeaderSetCookie)
+	require.NotEmpty(t, cookie)
+	assert.NotContains(t, cookie, "session="+token)
+	assert.NotEqual(t, token, s.Token(e.Context()))
+
+	_, found, err := store.Find(context.Background(), token)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestSession_ReadOnlyGET(t *testing.T) {
+	store := session.NewMemoryStore(session.MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	s := session.New(session.Config{}, store)
+	middleware := SessionWithConfig[*wo.Event](SessionConfig{ReadOnlyGET: true}, s, nil)
+
+	tests := []struct {
+		method   string
+		readOnly bool
+	}{
+		{method: http.MethodGet, readOnly: true},
+		{method: http.MethodHead, readOnly: true},
+		{method: http.MethodPost, readOnly: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			e := newSessionTestEvent(tt.method, "/", nil)
+			require.NoError(t, middleware(e))
+
+			assert.Equal(t, tt.readOnly, s.IsReadOnly(e.Context()))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/signedurl"
+)
+
+// SignedURL allows only the requests of the valid signed URLs (see [signedurl.Signer]), ex. to protect
+// the download routes of the private files. The invalid or expired URLs get [wo.ErrForbidden].
+func SignedURL[T wo.Resolver](signer *signedurl.Signer, skippers ...Skipper[T]) func(T) error {
+	if signer == nil {
+		panic("signed url middleware: signer is nil")
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		if err := signer.VerifyRequest(e.Request()); err != nil {
+			return wo.ErrForbidden.WithInternal(err)
+		}
+		return e.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/signedurl"
+)
+
+func TestSignedURL(t *testing.T) {
+	signer := signedurl.New(signedurl.Config{}, signedurl.Key{ID: "1", Secret: []byte("secret")})
+
+	signed, err := signer.Sign(http.MethodGet, "/files/report.pdf", time.Hour)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		url            string
+		skip           bool
+		expectedStatus int
+	}{
+		{name: "signed", url: signed},
+		{name: "unsigned", url: "/files/report.pdf", expectedStatus: http.StatusForbidden},
+		{name: "skipped", url: "/files/report.pdf", skip: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := SignedURL(signer, func(*wo.Event) bool { return tt.skip })
+
+			err := mw(newSessionTestEvent(http.MethodGet, tt.url, nil))
+			if tt.expectedStatus == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedStatus, wo.AsHTTPError(err).Status)
+		})
+	}
+}
+
+func TestSignedURL_NilSigner(t *testing.T) {
+	assert.Panics(t, func() { SignedURL[*wo.Event](nil) })
+}
@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http/httpguts"
+
+	"github.com/gowool/wo"
+)
+
+type SingleflightConfig struct {
+	// Headers lists request headers whose values are part of the deduplication key,
+	// in addition to the request method, host and URL.
+	// Optional. Default value nil.
+	Headers []string `env:"HEADERS" json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+// Singleflight deduplicates identical concurrent GET requests. The first request
+// (the leader) executes the handler while the response is recorded, all identical
+// requests arriving before it completes wait and receive a copy of the recorded
+// status, headers and body.
+//
+// The requests with the credentials (Cookie or Authorization) are never deduplicated, and
+// only the cacheable responses are shared: the cacheable status, no "private" or "no-store"
+// Cache-Control and no Vary beyond the key headers. The waiters run the handler themselves
+// otherwise. Set-Cookie is never replayed.
+func Singleflight[T wo.Resolver](cfg SingleflightConfig, skippers ...Skipper[T]) func(T) error {
+	skip := ChainSkipper[T](skippers...)
+
+	headers := make([]string, len(cfg.Headers))
+	for i, name := range cfg.Headers {
+		headers[i] = http.CanonicalHeaderKey(name)
+	}
+
+	group := &singleflightGroup{calls: make(map[string]*singleflightCall), headers: headers}
+
+	return func(e T) error {
+		r := e.Request()
+		if skip(e) || r.Method != http.MethodGet || r.Header.Get(wo.HeaderAuthorization) != "" || r.Header.Get(wo.HeaderCookie) != "" {
+			return e.Next()
+		}
+
+		key := singleflightKey(r, headers)
+
+		c, leader := group.acquire(key)
+		if !leader {
+			select {
+			case <-c.done:
+			case <-r.Context().Done():
+				return r.Context().Err()
+			}
+
+			if !c.shared {
+				return e.Next()
+			}
+			return c.replay(e.Response())
+		}
+
+		return group.run(key, c, e)
+	}
+}
+
+func singleflightKey(r *http.Request, headers []string) string {
+	var sb strings.Builder
+	sb.WriteString(r.Method)
+	sb.WriteByte(' ')
+	sb.WriteString(r.Host)
+	sb.WriteString(r.URL.RequestURI())
+	for _, name := range headers {
+		sb.WriteByte('\n')
+		sb.WriteString(name)
+		sb.WriteByte(':')
+		sb.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return sb.String()
+}
+
+type singleflightCall struct {
+	done   chan struct{}
+	shared bool
+	status int
+	header http.Header
+	body   []byte
+	err    error
+}
+
+func (c *singleflightCall) replay(w http.ResponseWriter) error {
+	if c.header != nil {
+		h := w.Header()
+		for k, v := range c.header {
+			h[k] = append([]string(nil), v...)
+		}
+		h.Del(wo.HeaderSetCookie)
+	}
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+	}
+	if len(c.body) > 0 {
+		if _, err := w.Write(c.body); err != nil {
+			return err
+		}
+	}
+	return c.err
+}
+
+type singleflightGroup struct {
+	mu      sync.Mutex
+	calls   map[string]*singleflightCall
+	headers []string
+}
+
+func (g *singleflightGroup) acquire(key string) (*singleflightCall, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if c, ok := g.calls[key]; ok {
+		return c, false
+	}
+
+	c := &singleflightCall{done: make(chan struct{})}
+	g.calls[key] = c
+	return c, true
+}
+
+func (g *singleflightGroup) run(key string, c *singleflightCall, e wo.Resolver) (err error) {
+	res := e.Response()
+	rec := &singleflightWriter{ResponseWriter: res}
+	e.SetResponse(rec)
+
+	completed := false
+
+	defer func() {
+		e.SetResponse(res)
+
+		if !completed {
+			// the leader panicked, waiters must not replay a partial response
+			c.status, c.header, c.body = 0, nil, nil
+			c.err = wo.ErrInternalServerError
+			c.shared = true
+		} else {
+			c.status = rec.status
+			c.header = rec.header
+			if c.header == nil {
+				c.header = res.Header().Clone()
+			}
+			c.body = rec.body.Bytes()
+			c.shared = singleflightShared(c.status, c.header, g.headers)
+		}
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+
+		close(c.done)
+	}()
+
+	err = e.Next()
+	c.err = err
+	completed = true
+
+	return err
+}
+
+// singleflightShared reports whether the response may be replayed to the other clients,
+// the error returned without the response is.
+func singleflightShared(status int, h http.Header, headers []string) bool {
+	switch status {
+	case 0, http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusPermanentRedirect,
+		http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusGone,
+		http.StatusRequestURITooLong, http.StatusNotImplemented:
+	default:
+		return false
+	}
+
+	cc := h.Values(wo.HeaderCacheControl)
+	if httpguts.HeaderValuesContainsToken(cc, "no-store") || httpguts.HeaderValuesContainsToken(cc, "private") {
+		return false
+	}
+
+	for _, value := range h.Values(wo.HeaderVary) {
+		for name := range strings.SplitSeq(value, ",") {
+			name = http.CanonicalHeaderKey(textproto.TrimString(name))
+			if name != "" && !slices.Contains(headers, name) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type singleflightWriter struct {
+	http.ResponseWriter
+	body   bytes.Buffer
+	header http.Header
+	status int
+}
+
+func (w *singleflightWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+		w.header = w.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *singleflightWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *singleflightWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *singleflightWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,303 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func newSingleflightHandler(t *testing.T, cfg SingleflightConfig, action func(*wo.Event) error) http.Handler {
+	t.Helper()
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, func(e *wo.Event, err error) {
+		if res := wo.MustUnwrapResponse(e.Response()); !res.Written {
+			he := wo.AsHTTPError(err)
+			if he == nil {
+				he = wo.MapError(err)
+			}
+			e.Response().WriteHeader(he.Status)
+		}
+	})
+	router.BindFunc(Singleflight[*wo.Event](cfg))
+	router.Any("/items", action)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+	return h
+}
+
+func TestSingleflight_CoalescesConcurrentRequests(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	h := newSingleflightHandler(t, SingleflightConfig{}, func(e *wo.Event) error {
+		calls.Add(1)
+		<-release
+		e.Response().Header().Set("X-Test", "value")
+		return e.String(http.StatusOK, "shared body")
+	})
+
+	const n = 5
+	recorders := make([]*httptest.ResponseRecorder, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		recorders[i] = httptest.NewRecorder()
+		wg.Go(func() {
+			h.ServeHTTP(recorders[i], httptest.NewRequest(http.MethodGet, "/items?page=1", nil))
+		})
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for _, rec := range recorders {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "value", rec.Header().Get("X-Test"))
+		assert.Equal(t, "shared body", rec.Body.String())
+	}
+}
+
+func TestSingleflight_SharesOnlyPublicResponses(t *testing.T) {
+	tests := []struct {
+		name           string
+		header         http.Header
+		handler        func(e *wo.Event) error
+		expectedCalls  int32
+		expectedCookie bool
+	}{
+		{
+			name:          "public",
+			handler:       func(e *wo.Event) error { return e.String(http.StatusOK, "body") },
+			expectedCalls: 1,
+		},
+		{
+			name:          "cookie",
+			header:        http.Header{wo.HeaderCookie: {"session=1"}},
+			handler:       func(e *wo.Event) error { return e.String(http.StatusOK, "body") },
+			expectedCalls: 3,
+		},
+		{
+			name:          "authorization",
+			header:        http.Header{wo.HeaderAuthorization: {"Bearer token"}},
+			handler:       func(e *wo.Event) error { return e.String(http.StatusOK, "body") },
+			expectedCalls: 3,
+		},
+		{
+			name: "private",
+			handler: func(e *wo.Event) error {
+				e.Response().Header().Set(wo.HeaderCacheControl, "private, max-age=60")
+				return e.String(http.StatusOK, "body")
+			},
+			expectedCalls: 3,
+		},
+		{
+			name: "vary",
+			handler: func(e *wo.Event) error {
+				e.Response().Header().Set(wo.HeaderVary, "Accept-Language")
+				return e.String(http.StatusOK, "body")
+			},
+			expectedCalls: 3,
+		},
+		{
+			name:          "not cacheable status",
+			handler:       func(e *wo.Event) error { return e.String(http.StatusCreated, "body") },
+			expectedCalls: 3,
+		},
+		{
+			name: "set cookie",
+			handler: func(e *wo.Event) error {
+				e.SetCookie(&http.Cookie{Name: "visitor", Value: "1"})
+				return e.String(http.StatusOK, "body")
+			},
+			expectedCalls:  1,
+			expectedCookie: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			release := make(chan struct{})
+
+			h := newSingleflightHandler(t, SingleflightConfig{}, func(e *wo.Event) error {
+				if calls.Add(1) == 1 {
+					<-release
+				}
+				return tt.handler(e)
+			})
+
+			recorders := make([]*httptest.ResponseRecorder, 3)
+
+			var wg sync.WaitGroup
+			for i := range recorders {
+				recorders[i] = httptest.NewRecorder()
+				wg.Go(func() {
+					req := httptest.NewRequest(http.MethodGet, "/items", nil)
+					maps.Copy(req.Header, tt.header)
+					h.ServeHTTP(recorders[i], req)
+				})
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, tt.expectedCalls, calls.Load())
+
+			cookies := 0
+			for _, rec := range recorders {
+				assert.Equal(t, "body", rec.Body.String())
+				if rec.Header().Get(wo.HeaderSetCookie) != "" {
+					cookies++
+				}
+			}
+			if tt.expectedCookie {
+				assert.Equal(t, 1, cookies, "only the leader gets its cookie")
+			}
+		})
+	}
+}
+
+func TestSingleflight_WaiterContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var calls atomic.Int32
+	h := newSingleflightHandler(t, SingleflightConfig{}, func(e *wo.Event) error {
+		calls.Add(1)
+		<-release
+		return e.String(http.StatusOK, "body")
+	})
+
+	go h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil).WithContext(ctx))
+
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestSingleflight_KeyIncludesHeaders(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	h := newSingleflightHandler(t, SingleflightConfig{Headers: []string{"accept-language"}}, func(e *wo.Event) error {
+		calls.Add(1)
+		<-release
+		return e.String(http.StatusOK, e.AcceptLanguage())
+	})
+
+	langs := []string{"en", "de"}
+	recorders := make([]*httptest.ResponseRecorder, len(langs))
+
+	var wg sync.WaitGroup
+	for i, lang := range langs {
+		recorders[i] = httptest.NewRecorder()
+		wg.Go(func() {
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			req.Header.Set(wo.HeaderAcceptLanguage, lang)
+			h.ServeHTTP(recorders[i], req)
+		})
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, "en", recorders[0].Body.String())
+	assert.Equal(t, "de", recorders[1].Body.String())
+}
+
+func TestSingleflight_NonGETPassesThrough(t *testing.T) {
+	var calls atomic.Int32
+
+	h := newSingleflightHandler(t, SingleflightConfig{}, func(e *wo.Event) error {
+		calls.Add(1)
+		return e.NoContent(http.StatusNoContent)
+	})
+
+	for range 3 {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", nil))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestSingleflight_ErrorIsSharedWithWaiters(t *testing.T) {
+	release := make(chan struct{})
+
+	h := newSingleflightHandler(t, SingleflightConfig{}, func(e *wo.Event) error {
+		<-release
+		return wo.ErrNotFound
+	})
+
+	recorders := []*httptest.ResponseRecorder{httptest.NewRecorder(), httptest.NewRecorder()}
+
+	var wg sync.WaitGroup
+	for _, rec := range recorders {
+		wg.Go(func() {
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+		})
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, rec := range recorders {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestSingleflightCall_Replay(t *testing.T) {
+	c := &singleflightCall{
+		status: http.StatusCreated,
+		header: http.Header{"X-A": {"1"}, wo.HeaderSetCookie: {"session=1"}},
+		body:   []byte("body"),
+		err:    errors.New("boom"),
+	}
+
+	rec := httptest.NewRecorder()
+	err := c.replay(rec)
+
+	require.EqualError(t, err, "boom")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-A"))
+	assert.Empty(t, rec.Header().Get(wo.HeaderSetCookie))
+	assert.Equal(t, "body", rec.Body.String())
+}
+
+func TestSingleflightKey(t *testing.T) {
+	r1 := httptest.NewRequest(http.MethodGet, "http://example.com/a?x=1", nil)
+	r1.Header.Set("Accept", "application/json")
+	r2 := httptest.NewRequest(http.MethodGet, "http://example.com/a?x=1", nil)
+	r2.Header.Set("Accept", "text/html")
+
+	assert.Equal(t, singleflightKey(r1, nil), singleflightKey(r2, nil))
+	assert.NotEqual(t, singleflightKey(r1, []string{"Accept"}), singleflightKey(r2, []string{"Accept"}))
+}
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"net/http"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/gowool/wo"
@@ -23,6 +25,134 @@ func ChainSkipper[T wo.Resolver](skippers ...Skipper[T]) Skipper[T] {
 	}
 }
 
+// AndSkipper skips when all the skippers skip, it never skips without the skippers.
+func AndSkipper[T wo.Resolver](skippers ...Skipper[T]) Skipper[T] {
+	return func(e T) bool {
+		for _, skipper := range skippers {
+			if !skipper(e) {
+				return false
+			}
+		}
+		return len(skippers) > 0
+	}
+}
+
+// OrSkipper skips when any of the skippers skips, like ChainSkipper.
+func OrSkipper[T wo.Resolver](skippers ...Skipper[T]) Skipper[T] {
+	return ChainSkipper[T](skippers...)
+}
+
+// NotSkipper skips when the skipper doesn't, ex. to apply the middleware to the API only:
+//
+//	middleware.CORS[*wo.Event](cfg, middleware.NotSkipper(middleware.PrefixPathSkipper[*wo.Event]("/api/")))
+func NotSkipper[T wo.Resolver](skipper Skipper[T]) Skipper[T] {
+	return func(e T) bool {
+		return !skipper(e)
+	}
+}
+
+// When runs the middleware only for the requests the condition is true for, the other ones
+// are passed to the next handler, ex. when the middleware doesn't take the skippers:
+//
+//	r.BindFunc(middleware.When(middleware.MethodSkipper[*wo.Event](http.MethodPost, http.MethodPut), audit))
+func When[T wo.Resolver](cond Skipper[T], mw func(T) error) func(T) error {
+	return func(e T) error {
+		if !cond(e) {
+			return e.Next()
+		}
+		return mw(e)
+	}
+}
+
+// Unless runs the middleware except for the requests the condition is true for.
+func Unless[T wo.Resolver](cond Skipper[T], mw func(T) error) func(T) error {
+	return When(NotSkipper(cond), mw)
+}
+
+// MethodSkipper skips the requests of the methods.
+func MethodSkipper[T wo.Resolver](methods ...string) Skipper[T] {
+	methods = arr.Map(methods, strings.ToUpper)
+	return func(e T) bool {
+		return slices.Contains(methods, e.Request().Method)
+	}
+}
+
+// HostSkipper skips the requests addressed to the hosts, the exact ones, ex. "example.com",
+// or the wildcard ones matching any subdomain, ex. "*.example.com", the hosts without the port
+// match any port. It panics if a host is malformed.
+func HostSkipper[T wo.Resolver](hosts ...string) Skipper[T] {
+	patterns := make([]hostPattern, 0, len(hosts))
+	for _, host := range hosts {
+		p, err := parseHostPattern(host)
+		if err != nil {
+			panic(err)
+		}
+		patterns = append(patterns, p)
+	}
+
+	return func(e T) bool {
+		name, port, ok := splitHost(strings.ToLower(e.Request().Host))
+		if !ok {
+			return false
+		}
+		for _, p := range patterns {
+			if p.match(name, port) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HeaderSkipper skips the requests with the header, or with any of the values of the header
+// if given, ex. HeaderSkipper("Upgrade", "websocket"). The values are compared case-insensitively.
+func HeaderSkipper[T wo.Resolver](name string, values ...string) Skipper[T] {
+	name = http.CanonicalHeaderKey(name)
+	return func(e T) bool {
+		header := e.Request().Header[name]
+		if len(values) == 0 {
+			return len(header) > 0
+		}
+		for _, h := range header {
+			for _, v := range values {
+				if strings.EqualFold(strings.TrimSpace(h), v) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// RegexPathSkipper skips the requests whose path matches any of the regular expressions,
+// optionally prefixed by the method like the other path skippers, ex. `GET ^/assets/.+\.js$`.
+// It panics if an expression is invalid.
+func RegexPathSkipper[T wo.Resolver](exprs ...string) Skipper[T] {
+	type pathRegexp struct {
+		method string
+		re     *regexp.Regexp
+	}
+
+	res := make([]pathRegexp, 0, len(exprs))
+	for _, expr := range exprs {
+		var method string
+		if matches := methodRe.FindStringSubmatch(expr); len(matches) > 2 && strings.Trim(matches[1], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == "" {
+			method, expr = matches[1], matches[2]
+		}
+		res = append(res, pathRegexp{method: method, re: regexp.MustCompile(expr)})
+	}
+
+	return func(e T) bool {
+		r := e.Request()
+		for _, re := range res {
+			if (re.method == "" || re.method == r.Method) && re.re.MatchString(r.URL.Path) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 func PrefixPathSkipper[T wo.Resolver](prefixes ...string) Skipper[T] {
 	prefixes = arr.Map(prefixes, strings.ToLower)
 	return func(e T) bool {
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
@@ -743,3 +744,91 @@ func BenchmarkEqualPathSkipper(b *testing.B) {
 		skipper(resolver)
 	}
 }
+
+func TestSkipperCombinators(t *testing.T) {
+	yes := func(*wo.Event) bool { return true }
+	no := func(*wo.Event) bool { return false }
+
+	tests := []struct {
+		name    string
+		skipper Skipper[*wo.Event]
+		want    bool
+	}{
+		{name: "and empty", skipper: AndSkipper[*wo.Event](), want: false},
+		{name: "and all", skipper: AndSkipper[*wo.Event](yes, yes), want: true},
+		{name: "and some", skipper: AndSkipper[*wo.Event](yes, no), want: false},
+		{name: "or empty", skipper: OrSkipper[*wo.Event](), want: false},
+		{name: "or some", skipper: OrSkipper[*wo.Event](no, yes), want: true},
+		{name: "not", skipper: NotSkipper[*wo.Event](yes), want: false},
+		{name: "not not", skipper: NotSkipper(NotSkipper[*wo.Event](yes)), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.skipper(newSkipperTestEvent()))
+		})
+	}
+}
+
+func TestRequestSkippers(t *testing.T) {
+	tests := []struct {
+		name    string
+		skipper Skipper[*wo.Event]
+		method  string
+		target  string
+		header  http.Header
+		want    bool
+	}{
+		{name: "method", skipper: MethodSkipper[*wo.Event]("post", http.MethodPut), method: http.MethodPost, target: "/", want: true},
+		{name: "other method", skipper: MethodSkipper[*wo.Event](http.MethodPost), method: http.MethodGet, target: "/", want: false},
+		{name: "host", skipper: HostSkipper[*wo.Event]("example.com"), target: "http://Example.com:8080/", want: true},
+		{name: "host port", skipper: HostSkipper[*wo.Event]("example.com:443"), target: "http://example.com:8080/", want: false},
+		{name: "wildcard host", skipper: HostSkipper[*wo.Event]("*.example.com"), target: "http://api.example.com/", want: true},
+		{name: "wildcard apex", skipper: HostSkipper[*wo.Event]("*.example.com"), target: "http://example.com/", want: false},
+		{name: "header", skipper: HeaderSkipper[*wo.Event]("x-internal"), target: "/", header: http.Header{"X-Internal": {"1"}}, want: true},
+		{name: "missing header", skipper: HeaderSkipper[*wo.Event]("X-Internal"), target: "/", want: false},
+		{name: "header value", skipper: HeaderSkipper[*wo.Event]("Upgrade", "websocket"), target: "/", header: http.Header{"Upgrade": {"WebSocket"}}, want: true},
+		{name: "other header value", skipper: HeaderSkipper[*wo.Event]("Upgrade", "websocket"), target: "/", header: http.Header{"Upgrade": {"h2c"}}, want: false},
+		{name: "regex path", skipper: RegexPathSkipper[*wo.Event](`^/assets/.+\.js$`), target: "/assets/app.js", want: true},
+		{name: "regex path mismatch", skipper: RegexPathSkipper[*wo.Event](`^/assets/.+\.js$`), target: "/assets/app.css", want: false},
+		{name: "regex path method", skipper: RegexPathSkipper[*wo.Event](`GET ^/users/\d+$`), target: "/users/1", want: true},
+		{name: "regex path other method", skipper: RegexPathSkipper[*wo.Event](`GET ^/users/\d+$`), method: http.MethodDelete, target: "/users/1", want: false},
+		{name: "regex path with space", skipper: RegexPathSkipper[*wo.Event](`^/a b$`), target: "/a%20b", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, tt.target, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			e := new(wo.Event)
+			e.Reset(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, tt.skipper(e))
+		})
+	}
+
+	assert.Panics(t, func() { HostSkipper[*wo.Event]("exa mple.com") })
+	assert.Panics(t, func() { RegexPathSkipper[*wo.Event]("(") })
+}
+
+func TestWhen(t *testing.T) {
+	var ran []string
+	mw := func(e *wo.Event) error {
+		ran = append(ran, e.Request().Method)
+		return nil
+	}
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		e := new(wo.Event)
+		e.Reset(httptest.NewRecorder(), httptest.NewRequest(method, "/", nil))
+		assert.NoError(t, When(MethodSkipper[*wo.Event](http.MethodPost), mw)(e))
+		assert.NoError(t, Unless(MethodSkipper[*wo.Event](http.MethodPost), mw)(e))
+	}
+	assert.Equal(t, []string{http.MethodGet, http.MethodPost}, ran)
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gowool/wo"
+)
+
+// The reasons of the rejected requests, see [SmugglingConfig.OnReject].
+var (
+	ErrAmbiguousLength             = errors.New("smuggling: ambiguous request body length")
+	ErrUnsupportedTransferEncoding = errors.New("smuggling: unsupported transfer encoding")
+	ErrMalformedChunked            = errors.New("smuggling: malformed chunked encoding")
+	ErrUnexpectedBody              = errors.New("smuggling: unexpected request body")
+	ErrDisallowedMethod            = errors.New("smuggling: disallowed method")
+	ErrDisallowedProtocol          = errors.New("smuggling: disallowed protocol")
+)
+
+type SmugglingConfig[T wo.Resolver] struct {
+	// AllowedMethods are the methods the application accepts, the others are rejected with
+	// [wo.ErrNotImplemented], ex. the TRACE or the made-up ones the proxies may forward as is.
+	//
+	// Default: [GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS]
+	AllowedMethods []string `env:"ALLOWED_METHODS" json:"allowedMethods,omitempty" yaml:"allowedMethods,omitempty"`
+
+	// AllowedProtocols are the protocol versions the application accepts, the others are
+	// rejected with [wo.ErrHTTPVersionNotSupported], ex. HTTP/1.0 behind the proxy speaking
+	// HTTP/1.1 only.
+	//
+	// Default: [HTTP/1.0, HTTP/1.1, HTTP/2.0, HTTP/3.0]
+	AllowedProtocols []string `env:"ALLOWED_PROTOCOLS" json:"allowedProtocols,omitempty" yaml:"allowedProtocols,omitempty"`
+
+	// BodylessMethods are the methods whose requests must not have a body, the body of the
+	// GET request ignored by the proxy would be read as the next request by the application.
+	//
+	// Default: [GET, HEAD]
+	BodylessMethods []string `env:"BODYLESS_METHODS" json:"bodylessMethods,omitempty" yaml:"bodylessMethods,omitempty"`
+
+	// OnReject is called with the rejected request and the reason, one of ErrAmbiguousLength,
+	// ErrUnsupportedTransferEncoding, ErrMalformedChunked, ErrUnexpectedBody, ErrDisallowedMethod
+	// and ErrDisallowedProtocol, ex. to log the attempts or to ban the clients.
+	OnReject func(e T, reason error) `json:"-" yaml:"-"`
+}
+
+func (c *SmugglingConfig[T]) SetDefaults() {
+	if len(c.AllowedMethods) == 0 {
+		c.AllowedMethods = []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+			http.MethodOptions,
+		}
+	}
+	if len(c.AllowedProtocols) == 0 {
+		c.AllowedProtocols = []string{"HTTP/1.0", "HTTP/1.1", "HTTP/2.0", "HTTP/3.0"}
+	}
+	if c.BodylessMethods == nil {
+		c.BodylessMethods = []string{http.MethodGet, http.MethodHead}
+	}
+}
+
+// Smuggling rejects the requests which may be framed differently by the proxies in front of
+// the application and by the application itself, the request smuggling, ex. behind the older
+// proxies: the ones with both Transfer-Encoding and Content-Length, with the transfer encodings
+// other than chunked, with the conflicting Content-Length values, with the body of the bodyless
+// methods or with the malformed chunked body, the latter when it's read. The requests with
+// the disallowed methods or protocols are rejected too.
+//
+// Note, net/http rejects the multiple and the unsupported transfer encodings itself, and removes
+// Content-Length of the chunked requests before the handler, so the middleware catches those
+// with the other servers and the proxies passing the requests through, ex. the adapters.
+//
+//	r.Pre(&hook.Handler[*wo.Event]{Func: middleware.Smuggling[*wo.Event](middleware.SmugglingConfig[*wo.Event]{
+//		OnReject: func(e *wo.Event, reason error) {
+//			logger.Warn("request smuggling", "reason", reason, "ip", e.RealIP())
+//		},
+//	})})
+func Smuggling[T wo.Resolver](cfg SmugglingConfig[T], skippers ...Skipper[T]) func(T) error {
+	cfg.SetDefaults()
+
+	skip := ChainSkipper[T](skippers...)
+
+	reject := func(e T, httpErr *wo.HTTPError, reason error) error {
+		if cfg.OnReject != nil {
+			cfg.OnReject(e, reason)
+		}
+		return httpErr.WithInternal(reason)
+	}
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		r := e.Request()
+
+		if !slices.Contains(cfg.AllowedProtocols, r.Proto) {
+			return reject(e, wo.ErrHTTPVersionNotSupported, fmt.Errorf("%w %q", ErrDisallowedProtocol, r.Proto))
+		}
+		if !slices.Contains(cfg.AllowedMethods, r.Method) {
+			return reject(e, wo.ErrNotImplemented, fmt.Errorf("%w %q", ErrDisallowedMethod, r.Method))
+		}
+
+		te := r.TransferEncoding
+		if raw := r.Header.Values(wo.HeaderTransferEncoding); len(raw) > 0 {
+			te = nil
+			for _, value := range raw {
+				for coding := range strings.SplitSeq(value, ",") {
+					te = append(te, strings.TrimSpace(coding))
+				}
+			}
+		}
+		contentLength := r.Header.Values(wo.HeaderContentLength)
+
+		switch {
+		case len(te) > 0 && len(contentLength) > 0:
+			return reject(e, wo.ErrBadRequest, fmt.Errorf("%w: both Transfer-Encoding and Content-Length", ErrAmbiguousLength))
+		case len(contentLength) > 1 || (len(contentLength) == 1 && strings.Contains(contentLength[0], ",")):
+			return reject(e, wo.ErrBadRequest, fmt.Errorf("%w: multiple Content-Length %q", ErrAmbiguousLength, contentLength))
+		case len(te) > 0 && !r.ProtoAtLeast(1, 1):
+			return reject(e, wo.ErrBadRequest, fmt.Errorf("%w: Transfer-Encoding of %s", ErrUnsupportedTransferEncoding, r.Proto))
+		case len(te) > 1 || (len(te) == 1 && !strings.EqualFold(te[0], "chunked")):
+			return reject(e, wo.ErrNotImplemented, fmt.Errorf("%w %q", ErrUnsupportedTransferEncoding, te))
+		case (r.ContentLength > 0 || len(te) > 0) && slices.Contains(cfg.BodylessMethods, r.Method):
+			return reject(e, wo.ErrBadRequest, fmt.Errorf("%w of %s", ErrUnexpectedBody, r.Method))
+		}
+
+		if len(te) == 0 || r.Body == nil || r.Body == http.NoBody {
+			return e.Next()
+		}
+
+		body := &chunkedReader{ReadCloser: r.Body}
+		r.Body = body
+
+		err := e.Next()
+		if body.err != nil {
+			return reject(e, wo.ErrBadRequest, body.err)
+		}
+		return err
+	}
+}
+
+type chunkedReader struct {
+	io.ReadCloser
+	err error
+}
+
+func (r *chunkedReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	if err != nil && isChunkedError(err) {
+		r.err = fmt.Errorf("%w: %w", ErrMalformedChunked, err)
+		return n, wo.ErrBadRequest.WithInternal(r.err)
+	}
+	return n, err
+}
+
+func (r *chunkedReader) Reread() {
+	if rr, ok := r.ReadCloser.(interface{ Reread() }); ok {
+		rr.Reread()
+	}
+}
+
+// isChunkedError reports whether the error is of the chunked encoding, the errors of
+// net/http/internal aren't exported, so they are matched by the message.
+func isChunkedError(err error) bool {
+	return errors.Is(err, http.ErrLineTooLong) || strings.Contains(err.Error(), "chunk")
+}
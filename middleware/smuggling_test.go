@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestSmuggling(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		proto          string
+		header         http.Header
+		te             []string
+		contentLength  int64
+		expectedStatus int
+		expectedReason error
+	}{
+		{name: "get", method: http.MethodGet},
+		{name: "post", method: http.MethodPost, contentLength: 5},
+		{name: "chunked post", method: http.MethodPost, te: []string{"chunked"}, contentLength: -1},
+		{
+			name:           "transfer encoding and content length",
+			method:         http.MethodPost,
+			header:         http.Header{"Transfer-Encoding": {"chunked"}, "Content-Length": {"5"}},
+			te:             []string{"chunked"},
+			contentLength:  -1,
+			expectedStatus: http.StatusBadRequest,
+			expectedReason: ErrAmbiguousLength,
+		},
+		{
+			name:           "chunked with content length",
+			method:         http.MethodPost,
+			header:         http.Header{"Content-Length": {"5"}},
+			te:             []string{"chunked"},
+			contentLength:  -1,
+			expectedStatus: http.StatusBadRequest,
+			expectedReason: ErrAmbiguousLength,
+		},
+		{
+			name:           "multiple content length",
+			method:         http.MethodPost,
+			header:         http.Header{"Content-Length": {"5", "6"}},
+			contentLength:  5,
+			expectedStatus: http.StatusBadRequest,
+			expectedReason: ErrAmbiguousLength,
+		},
+		{
+			name:           "content length list",
+			method:         http.MethodPost,
+			header:         http.Header{"Content-Length": {"5, 6"}},
+			contentLength:  5,
+			expectedStatus: http.StatusBadRequest,
+			expectedReason: ErrAmbiguousLength,
+		},
+		{
+			name:           "obfuscated transfer encoding",
+			method:         http.MethodPost,
+			header:         http.Header{"Transfer-Encoding": {"chunked", "identity"}},
+			contentLength:  -1,
+			expectedStatus: http.StatusNotImplemented,
+			expectedReason: ErrUnsupportedTransferEncoding,
+		},
+		{
+			name:           "gzip transfer encoding",
+			method:         http.MethodPost,
+			header:         http.Header{"Transfer-Encoding": {"gzip, chunked"}},
+			contentLength:  -1,
+			expectedStatus: http.StatusNotImplemented,
+			expectedReason: ErrUnsupportedTransferEncoding,
+		},
+		{
+			name:           "transfer encoding of http 1.0",
+			method:         http.MethodPost,
+			proto:          "HTTP/1.0",
+			header:         http.Header{"Transfer-Encoding": {"chunked"}},
+			contentLength:  -1,
+			expectedStatus: http.StatusBadRequest,
+			expectedReason: ErrUnsupportedTransferEncoding,
+		},
+		{
+			name:           "get with body",
+			method:         http.MethodGet,
+			contentLength:  5,
+			expectedStatus: http.StatusBadRequest,
+			expectedReason: ErrUnexpectedBody,
+		},
+		{
+			name:           "trace",
+			method:         http.MethodTrace,
+			expectedStatus: http.StatusNotImplemented,
+			expectedReason: ErrDisallowedMethod,
+		},
+		{
+			name:           "made-up method",
+			method:         "GETS",
+			expectedStatus: http.StatusNotImplemented,
+			expectedReason: ErrDisallowedMethod,
+		},
+		{
+			name:           "protocol",
+			method:         http.MethodGet,
+			proto:          "HTTP/1.2",
+			expectedStatus: http.StatusHTTPVersionNotSupported,
+			expectedReason: ErrDisallowedProtocol,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reason error
+			mw := Smuggling[*wo.Event](SmugglingConfig[*wo.Event]{
+				OnReject: func(_ *wo.Event, err error) { reason = err },
+			})
+
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader("hello"))
+			if tt.proto != "" {
+				req.Proto = tt.proto
+				req.ProtoMajor, req.ProtoMinor, _ = http.ParseHTTPVersion(tt.proto)
+			}
+			req.Header = tt.header
+			if req.Header == nil {
+				req.Header = make(http.Header)
+			}
+			req.TransferEncoding = tt.te
+			req.ContentLength = tt.contentLength
+			e := new(wo.Event)
+			e.Reset(httptest.NewRecorder(), req)
+
+			err := mw(e)
+			if tt.expectedStatus == 0 {
+				assert.NoError(t, err)
+				assert.NoError(t, reason)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedStatus, wo.AsHTTPError(err).Status)
+			assert.ErrorIs(t, err, tt.expectedReason)
+			assert.ErrorIs(t, reason, tt.expectedReason)
+		})
+	}
+}
+
+func TestSmuggling_MalformedChunked(t *testing.T) {
+	var reason error
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+	router.BindFunc(Smuggling[*wo.Event](SmugglingConfig[*wo.Event]{
+		OnReject: func(_ *wo.Event, err error) { reason = err },
+	}))
+	router.POST("/", func(e *wo.Event) error {
+		if _, err := io.ReadAll(e.Request().Body); err != nil {
+			return wo.ErrInternalServerError.WithInternal(err)
+		}
+		return e.NoContent(http.StatusNoContent)
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "valid", body: "5\r\nhello\r\n0\r\n\r\n", expectedStatus: http.StatusNoContent},
+		{name: "invalid length", body: "5x\r\nhello\r\n0\r\n\r\n", expectedStatus: http.StatusBadRequest},
+		{name: "missing crlf", body: "5\r\nhelloX0\r\n\r\n", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason = nil
+
+			conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+			require.NoError(t, err)
+			defer func() {
+				_ = conn.Close()
+			}()
+
+			_, err = io.WriteString(conn, "POST / HTTP/1.1\r\nHost: example.com\r\nTransfer-Encoding: chunked\r\n\r\n"+tt.body)
+			require.NoError(t, err)
+
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			assert.Equal(t, tt.expectedStatus, res.StatusCode)
+			if tt.expectedStatus == http.StatusBadRequest {
+				assert.ErrorIs(t, reason, ErrMalformedChunked)
+			}
+		})
+	}
+}
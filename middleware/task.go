@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/task"
+)
+
+// Task makes the runner available to handlers through the request context,
+// see [task.FromContext].
+func Task[T wo.Resolver](r *task.Runner, skippers ...Skipper[T]) func(T) error {
+	if r == nil {
+		panic("task middleware: runner is nil")
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		e.SetRequest(e.Request().WithContext(task.WithRunner(e.Request().Context(), r)))
+
+		return e.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/task"
+)
+
+func TestTask_PanicsOnNilRunner(t *testing.T) {
+	assert.Panics(t, func() {
+		Task[*wo.Event](nil)
+	})
+}
+
+func TestTask_StoresRunnerInContext(t *testing.T) {
+	r := task.NewRunner(task.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	e := new(wo.Event)
+	e.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	err := Task[*wo.Event](r)(e)
+	assert.NoError(t, err)
+
+	actual, ok := task.FromContext(e.Context())
+	assert.True(t, ok)
+	assert.Same(t, r, actual)
+}
+
+func TestTask_Skipper(t *testing.T) {
+	r := task.NewRunner(task.Config{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	e := new(wo.Event)
+	e.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	err := Task[*wo.Event](r, func(*wo.Event) bool { return true })(e)
+	assert.NoError(t, err)
+
+	_, ok := task.FromContext(e.Context())
+	assert.False(t, ok)
+}
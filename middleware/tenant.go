@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/tenant"
+)
+
+var (
+	// ErrTenantRequired is returned when the request doesn't resolve the tenant and it's required.
+	ErrTenantRequired = wo.ErrBadRequest.WithMessage("tenant is required")
+
+	// ErrTenantNotFound is returned when the resolved tenant doesn't exist.
+	ErrTenantNotFound = wo.ErrNotFound.WithMessage("tenant not found")
+)
+
+type TenantConfig struct {
+	// Sources resolve the tenant ID from the request, they are tried in order.
+	//
+	// Required.
+	Sources []tenant.Source `json:"-" yaml:"-"`
+
+	// Lookup returns the tenant of the resolved ID, ex. from the database, or [tenant.ErrNotFound],
+	// so the IDs of the requests are never trusted as is, see [tenant.StaticLookup].
+	//
+	// Required.
+	Lookup tenant.Lookup `json:"-" yaml:"-"`
+
+	// Optional allows the requests not resolving the tenant, ex. the landing pages of the base domain.
+	//
+	// Default: false
+	Optional bool `env:"OPTIONAL" json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+func (c *TenantConfig) Validate() error {
+	if len(c.Sources) == 0 {
+		return errors.New("tenant middleware: sources are required")
+	}
+	if c.Lookup == nil {
+		return errors.New("tenant middleware: lookup is required")
+	}
+	return nil
+}
+
+// Tenant resolves the tenant of the request and stores it in the request context, see [tenant.FromContext].
+// The requests without the tenant get [ErrTenantRequired] unless it's optional, the unknown tenants
+// get [ErrTenantNotFound].
+//
+// The other middlewares consume the tenant with the per-tenant hooks, ex. [TenantIdentifier] for the rate
+// limiter and the quota subjects, [TenantMax] for the rate limiter, quota.Config.MaxFunc with [tenant.Int]
+// and session.Config.CookieDomain with [tenant.CookieDomain].
+func Tenant[T wo.Resolver](cfg TenantConfig, skippers ...Skipper[T]) func(T) error {
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	skip := ChainSkipper[T](skippers...)
+
+	return func(e T) error {
+		if skip(e) {
+			return e.Next()
+		}
+
+		r := e.Request()
+
+		var id string
+		for _, source := range cfg.Sources {
+			if id = source(r); id != "" {
+				break
+			}
+		}
+
+		if id == "" {
+			if cfg.Optional {
+				return e.Next()
+			}
+			return ErrTenantRequired
+		}
+
+		t, err := cfg.Lookup(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, tenant.ErrNotFound) {
+				return ErrTenantNotFound.WithInternal(err)
+			}
+			return fmt.Errorf("tenant middleware: failed to lookup tenant %q: %w", id, err)
+		}
+		if t == nil {
+			return ErrTenantNotFound
+		}
+
+		e.SetRequest(r.WithContext(tenant.WithTenant(r.Context(), t)))
+		return e.Next()
+	}
+}
+
+// TenantIdentifier prefixes the identifier with the tenant ID of the request, so the tenants
+// have their own rate limits or quotas, ex. RateLimiterConfig.IdentifierExtractor or QuotaConfig.Subject.
+// The identifier is left unchanged without the tenant.
+func TenantIdentifier[T wo.Resolver](identifier func(T) (string, error)) func(T) (string, error) {
+	return func(e T) (string, error) {
+		id, err := identifier(e)
+		if err != nil {
+			return "", err
+		}
+		if t, ok := tenant.FromContext(e.Request().Context()); ok {
+			return t.ID + ":" + id, nil
+		}
+		return id, nil
+	}
+}
+
+// TenantMax returns the RateLimiterConfig.MaxFunc reading the limit from the tenant setting of the key,
+// the tenants without the setting fall back to RateLimiterConfig.Max.
+func TenantMax[T wo.Resolver](key string) func(T) uint {
+	return func(e T) uint {
+		if m, ok := tenant.Int(e.Request().Context(), key); ok && m > 0 {
+			return uint(m)
+		}
+		return 0
+	}
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/tenant"
+)
+
+func TestTenant(t *testing.T) {
+	lookup := tenant.StaticLookup(&tenant.Tenant{ID: "acme", Domain: "acme.example.com"}, &tenant.Tenant{ID: "globex"})
+
+	tests := []struct {
+		name           string
+		cfg            TenantConfig
+		headers        map[string]string
+		expectedTenant string
+		expectedStatus int
+	}{
+		{
+			name:           "resolved",
+			cfg:            TenantConfig{Sources: []tenant.Source{tenant.FromHeader("X-Tenant")}, Lookup: lookup},
+			headers:        map[string]string{"X-Tenant": "acme"},
+			expectedTenant: "acme",
+		},
+		{
+			name: "first source wins",
+			cfg: TenantConfig{Sources: []tenant.Source{
+				tenant.FromHeader("X-Org"),
+				tenant.FromHeader("X-Tenant"),
+			}, Lookup: lookup},
+			headers:        map[string]string{"X-Tenant": "globex"},
+			expectedTenant: "globex",
+		},
+		{
+			name:           "required",
+			cfg:            TenantConfig{Sources: []tenant.Source{tenant.FromHeader("X-Tenant")}, Lookup: lookup},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "optional",
+			cfg:  TenantConfig{Sources: []tenant.Source{tenant.FromHeader("X-Tenant")}, Lookup: lookup, Optional: true},
+		},
+		{
+			name:           "unknown",
+			cfg:            TenantConfig{Sources: []tenant.Source{tenant.FromHeader("X-Tenant")}, Lookup: lookup},
+			headers:        map[string]string{"X-Tenant": "initech"},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newSessionTestEvent(http.MethodGet, "/", tt.headers)
+
+			err := Tenant[*wo.Event](tt.cfg)(e)
+			if tt.expectedStatus != 0 {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedStatus, wo.AsHTTPError(err).Status)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTenant, tenant.ID(e.Request().Context()))
+		})
+	}
+}
+
+func TestTenant_LookupError(t *testing.T) {
+	mw := Tenant[*wo.Event](TenantConfig{
+		Sources: []tenant.Source{tenant.FromHeader("X-Tenant")},
+		Lookup: func(context.Context, string) (*tenant.Tenant, error) {
+			return nil, errors.New("db is down")
+		},
+	})
+
+	err := mw(newSessionTestEvent(http.MethodGet, "/", map[string]string{"X-Tenant": "acme"}))
+	require.ErrorContains(t, err, "db is down")
+	assert.Nil(t, wo.AsHTTPError(err))
+}
+
+func TestTenant_NoSources(t *testing.T) {
+	assert.Panics(t, func() { Tenant[*wo.Event](TenantConfig{Lookup: tenant.StaticLookup()}) })
+}
+
+func TestTenant_NoLookup(t *testing.T) {
+	assert.Panics(t, func() {
+		Tenant[*wo.Event](TenantConfig{Sources: []tenant.Source{tenant.FromHeader("X-Tenant")}})
+	})
+}
+
+func TestTenantIdentifier(t *testing.T) {
+	identifier := TenantIdentifier(func(e *wo.Event) (string, error) {
+		return e.Request().Header.Get("X-Account"), nil
+	})
+
+	e := newSessionTestEvent(http.MethodGet, "/", map[string]string{"X-Account": "42"})
+
+	id, err := identifier(e)
+	require.NoError(t, err)
+	assert.Equal(t, "42", id)
+
+	e.SetRequest(e.Request().WithContext(tenant.WithTenant(e.Request().Context(), &tenant.Tenant{ID: "acme"})))
+
+	id, err = identifier(e)
+	require.NoError(t, err)
+	assert.Equal(t, "acme:42", id)
+}
+
+func TestTenantMax(t *testing.T) {
+	maxFunc := TenantMax[*wo.Event]("rateLimit")
+
+	e := newSessionTestEvent(http.MethodGet, "/", nil)
+	assert.Equal(t, uint(0), maxFunc(e))
+
+	e.SetRequest(e.Request().WithContext(tenant.WithTenant(e.Request().Context(), &tenant.Tenant{
+		ID:       "acme",
+		Settings: map[string]any{"rateLimit": 100},
+	})))
+	assert.Equal(t, uint(100), maxFunc(e))
+}
@@ -0,0 +1,215 @@
+package wo
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gowool/hook"
+)
+
+// MiddlewareConstraints declares the ordering requirements of a middleware identified by its
+// handler ID, which [Router.Build] validates for every route instead of letting the misordered
+// middlewares silently misbehave. The IDs of the middlewares which are not bound to a route
+// are ignored, except for Requires.
+type MiddlewareConstraints struct {
+	// Before are the middlewares which must run after this one, ex. "compress" must run before
+	// the middlewares writing the body.
+	Before []string
+
+	// After are the middlewares which must run before this one.
+	After []string
+
+	// Requires are the middlewares which must be bound to the route and run before this one,
+	// ex. the access logger requires "request-id".
+	Requires []string
+}
+
+// Constrain declares the ordering requirements of the middleware with the ID, replacing
+// the previously declared ones. The [Router.Pre] middlewares run before all route middlewares.
+//
+//	r.Constrain("etag", wo.MiddlewareConstraints{After: []string{"compress"}})
+//	r.Constrain("access-log", wo.MiddlewareConstraints{Requires: []string{"request-id"}})
+func (r *Router[T]) Constrain(id string, c MiddlewareConstraints) {
+	if id == "" {
+		panic("wo: middleware constraints must have a middleware id")
+	}
+
+	r.constraints[id] = MiddlewareConstraints{
+		Before:   slices.Clone(c.Before),
+		After:    slices.Clone(c.After),
+		Requires: slices.Clone(c.Requires),
+	}
+}
+
+// MiddlewareOrderViolation is a declared ordering requirement the middlewares of some routes break.
+type MiddlewareOrderViolation struct {
+	// Middleware is the ID of the middleware which declared the constraint.
+	Middleware string
+
+	// Other is the ID of the middleware the constraint refers to.
+	Other string
+
+	Reason string
+
+	// Routes are the patterns of the routes breaking the constraint.
+	Routes []string
+}
+
+func (v MiddlewareOrderViolation) String() string {
+	routes := make([]string, len(v.Routes))
+	for i, route := range v.Routes {
+		routes[i] = fmt.Sprintf("%q", route)
+	}
+	return fmt.Sprintf("%q %s %q (routes: %s)", v.Middleware, v.Reason, v.Other, strings.Join(routes, ", "))
+}
+
+// MiddlewareOrderError is returned by [Router.Build] when the middlewares of some routes
+// break the declared ordering requirements, see [Router.Constrain].
+type MiddlewareOrderError struct {
+	Violations []MiddlewareOrderViolation
+}
+
+func (e *MiddlewareOrderError) Error() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "router: %d middleware order violation(s):", len(e.Violations))
+	for _, v := range e.Violations {
+		b.WriteString("\n\t")
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+// middlewareChain mirrors the order of the middlewares bound to a route hook: the handlers with
+// the same ID replace each other and the handlers are stable sorted by priority.
+type middlewareChain[T Resolver] []*hook.Handler[T]
+
+func (c middlewareChain[T]) add(h *hook.Handler[T]) middlewareChain[T] {
+	if h.ID != "" {
+		if i := slices.IndexFunc(c, func(existing *hook.Handler[T]) bool { return existing.ID == h.ID }); i >= 0 {
+			c[i] = h
+			return c
+		}
+	}
+	return append(c, h)
+}
+
+func (c middlewareChain[T]) sorted() middlewareChain[T] {
+	sorted := slices.Clone(c)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+	return sorted
+}
+
+func (c middlewareChain[T]) ids() []string {
+	sorted := c.sorted()
+
+	ids := make([]string, 0, len(sorted))
+	for _, h := range sorted {
+		ids = append(ids, h.ID)
+	}
+	return ids
+}
+
+// names returns the IDs of the middlewares in the execution order, the function names of the anonymous ones.
+func (c middlewareChain[T]) names() []string {
+	sorted := c.sorted()
+
+	names := make([]string, 0, len(sorted))
+	for _, h := range sorted {
+		if h.ID != "" {
+			names = append(names, h.ID)
+		} else {
+			names = append(names, funcName(h.Func))
+		}
+	}
+	return names
+}
+
+// middlewareOrder collects the violations of the middleware constraints of the built routes.
+type middlewareOrder struct {
+	constraints map[string]MiddlewareConstraints
+	violations  []MiddlewareOrderViolation
+	index       map[[3]string]int
+}
+
+func newMiddlewareOrder(constraints map[string]MiddlewareConstraints) *middlewareOrder {
+	return &middlewareOrder{constraints: constraints, index: make(map[[3]string]int)}
+}
+
+// check validates the middleware IDs of the route in the execution order.
+func (o *middlewareOrder) check(pattern string, ids []string) {
+	if len(o.constraints) == 0 {
+		return
+	}
+
+	position := make(map[string]int, len(ids))
+	for i, id := range ids {
+		if _, ok := position[id]; !ok && id != "" {
+			position[id] = i
+		}
+	}
+
+	for id, i := range position {
+		c, ok := o.constraints[id]
+		if !ok {
+			continue
+		}
+
+		for _, other := range c.Before {
+			if j, ok := position[other]; ok && j < i {
+				o.add(pattern, id, other, "must run before")
+			}
+		}
+		for _, other := range c.After {
+			if j, ok := position[other]; ok && j > i {
+				o.add(pattern, id, other, "must run after")
+			}
+		}
+		for _, other := range c.Requires {
+			j, ok := position[other]
+			switch {
+			case !ok:
+				o.add(pattern, id, other, "requires")
+			case j > i:
+				o.add(pattern, id, other, "must run after the required")
+			}
+		}
+	}
+}
+
+func (o *middlewareOrder) add(pattern, id, other, reason string) {
+	key := [3]string{id, other, reason}
+	if i, ok := o.index[key]; ok {
+		o.violations[i].Routes = append(o.violations[i].Routes, pattern)
+		return
+	}
+
+	o.index[key] = len(o.violations)
+	o.violations = append(o.violations, MiddlewareOrderViolation{
+		Middleware: id,
+		Other:      other,
+		Reason:     reason,
+		Routes:     []string{pattern},
+	})
+}
+
+func (o *middlewareOrder) err() error {
+	if len(o.violations) == 0 {
+		return nil
+	}
+
+	violations := slices.Clone(o.violations)
+	slices.SortStableFunc(violations, func(a, b MiddlewareOrderViolation) int {
+		if c := strings.Compare(a.Middleware, b.Middleware); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Other, b.Other); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Reason, b.Reason)
+	})
+	return &MiddlewareOrderError{Violations: violations}
+}
@@ -0,0 +1,120 @@
+package wo
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func idMiddleware(id string, priority int) *hook.Handler[*Event] {
+	return &hook.Handler[*Event]{ID: id, Priority: priority, Func: func(e *Event) error { return e.Next() }}
+}
+
+func TestRouterConstrain(t *testing.T) {
+	action := func(e *Event) error { return e.NoContent(http.StatusNoContent) }
+
+	tests := []struct {
+		name     string
+		setup    func(r *Router[*Event])
+		expected []MiddlewareOrderViolation
+	}{
+		{
+			name: "satisfied",
+			setup: func(r *Router[*Event]) {
+				r.Constrain("compress", MiddlewareConstraints{Before: []string{"etag"}})
+				r.Constrain("access-log", MiddlewareConstraints{Requires: []string{"request-id"}})
+				r.Pre(idMiddleware("request-id", 0))
+				r.Bind(idMiddleware("compress", 0), idMiddleware("access-log", 0), idMiddleware("etag", 0))
+				r.GET("/", action)
+			},
+		},
+		{
+			name: "before",
+			setup: func(r *Router[*Event]) {
+				r.Constrain("compress", MiddlewareConstraints{Before: []string{"etag"}})
+				r.Bind(idMiddleware("etag", 0))
+				r.Group("/api").Bind(idMiddleware("compress", 0)).GET("/users", action)
+				r.GET("/", action)
+			},
+			expected: []MiddlewareOrderViolation{
+				{Middleware: "compress", Other: "etag", Reason: "must run before", Routes: []string{"GET /api/users"}},
+			},
+		},
+		{
+			name: "after",
+			setup: func(r *Router[*Event]) {
+				r.Constrain("etag", MiddlewareConstraints{After: []string{"compress"}})
+				r.Bind(idMiddleware("etag", 0), idMiddleware("compress", 0))
+				r.GET("/a", action)
+				r.GET("/b", action)
+			},
+			expected: []MiddlewareOrderViolation{
+				{Middleware: "etag", Other: "compress", Reason: "must run after", Routes: []string{"GET /a", "GET /b"}},
+			},
+		},
+		{
+			name: "priority",
+			setup: func(r *Router[*Event]) {
+				r.Constrain("etag", MiddlewareConstraints{After: []string{"compress"}})
+				r.Bind(idMiddleware("etag", 0), idMiddleware("compress", -1))
+				r.GET("/", action)
+			},
+		},
+		{
+			name: "requires",
+			setup: func(r *Router[*Event]) {
+				r.Constrain("access-log", MiddlewareConstraints{Requires: []string{"request-id"}})
+				r.Bind(idMiddleware("access-log", 0))
+				r.GET("/", action)
+				r.GET("/ok", action).Bind(idMiddleware("request-id", -1))
+				r.GET("/late", action).Bind(idMiddleware("request-id", 0))
+			},
+			expected: []MiddlewareOrderViolation{
+				{Middleware: "access-log", Other: "request-id", Reason: "must run after the required", Routes: []string{"GET /late"}},
+				{Middleware: "access-log", Other: "request-id", Reason: "requires", Routes: []string{"GET /"}},
+			},
+		},
+		{
+			name: "excluded",
+			setup: func(r *Router[*Event]) {
+				r.Constrain("compress", MiddlewareConstraints{Before: []string{"etag"}})
+				r.Bind(idMiddleware("etag", 0), idMiddleware("compress", 0))
+				r.GET("/", action).Unbind("etag")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New[*Event](eventFactory, errorHandler)
+			tt.setup(router)
+
+			_, err := router.Build(nil)
+			if tt.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var orderErr *MiddlewareOrderError
+			require.ErrorAs(t, err, &orderErr)
+			assert.Equal(t, tt.expected, orderErr.Violations)
+		})
+	}
+}
+
+func TestMiddlewareOrderError_Error(t *testing.T) {
+	err := &MiddlewareOrderError{Violations: []MiddlewareOrderViolation{
+		{Middleware: "compress", Other: "etag", Reason: "must run before", Routes: []string{"GET /a", "GET /b"}},
+	}}
+
+	assert.Equal(t, "router: 1 middleware order violation(s):\n\t\"compress\" must run before \"etag\" (routes: \"GET /a\", \"GET /b\")", err.Error())
+}
+
+func TestRouterConstrain_EmptyID(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+
+	assert.Panics(t, func() { router.Constrain("", MiddlewareConstraints{}) })
+}
@@ -0,0 +1,95 @@
+package wo
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Mount registers the http.Handler (ex. a third-party mux or a sub-application) for all
+// methods under the prefix, so it runs within the group middlewares chain. The handler
+// receives the request with the mounted prefix (including the parent groups prefixes)
+// stripped from the URL path.
+//
+//	api.Mount("/legacy", legacyMux)
+func (group *RouterGroup[T]) Mount(prefix string, h http.Handler) *Route[T] {
+	if h == nil {
+		panic("Mount: the provided http.Handler is nil")
+	}
+
+	return group.Any(strings.TrimSuffix(prefix, "/")+"/", httpAction[T](h, true))
+}
+
+// Handle registers the http.Handler for all methods at the path, so it runs within
+// the group middlewares chain, ex. pprof or Prometheus handlers.
+func (group *RouterGroup[T]) Handle(path string, h http.Handler) *Route[T] {
+	if h == nil {
+		panic("Handle: the provided http.Handler is nil")
+	}
+
+	return group.Any(path, httpAction[T](h, false))
+}
+
+// HandleFunc is a shorthand for [RouterGroup.Handle] with a http.HandlerFunc.
+func (group *RouterGroup[T]) HandleFunc(path string, fn http.HandlerFunc) *Route[T] {
+	if fn == nil {
+		panic("HandleFunc: the provided http.HandlerFunc is nil")
+	}
+
+	return group.Handle(path, fn)
+}
+
+func httpAction[T any](h http.Handler, strip bool) func(T) error {
+	return func(e T) error {
+		re, ok := any(e).(interface {
+			Request() *http.Request
+			Response() http.ResponseWriter
+		})
+		if !ok {
+			panic("the event must implement the wo.Resolver interface")
+		}
+
+		r := re.Request()
+		if strip {
+			r = stripPattern(r)
+		}
+
+		h.ServeHTTP(re.Response(), r)
+		return nil
+	}
+}
+
+// stripPattern returns a shallow copy of the request with the path segments matched
+// by the request pattern removed from the URL path, like http.StripPrefix does.
+func stripPattern(r *http.Request) *http.Request {
+	pattern := r.Pattern
+	if _, p, ok := strings.Cut(pattern, " "); ok {
+		pattern = p // strip the method
+	}
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:] // strip the host
+	}
+
+	segments := strings.Count(strings.TrimSuffix(pattern, "/"), "/")
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = stripSegments(r.URL.Path, segments)
+	if r.URL.RawPath != "" {
+		r2.URL.RawPath = stripSegments(r.URL.RawPath, segments)
+	}
+	return r2
+}
+
+func stripSegments(path string, n int) string {
+	for ; n > 0 && path != ""; n-- {
+		i := strings.IndexByte(path[1:], '/')
+		if i < 0 {
+			return "/"
+		}
+		path = path[i+1:]
+	}
+	return path
+}
@@ -0,0 +1,81 @@
+package wo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterGroup_Mount(t *testing.T) {
+	sub := http.NewServeMux()
+	sub.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("user " + r.PathValue("id") + " " + r.URL.Path))
+	})
+	sub.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("root " + r.URL.Path))
+	})
+
+	router := New[*Event](eventFactory, errorHandler)
+	api := router.Group("/{tenant}/api")
+	api.BindFunc(func(e *Event) error {
+		e.Response().Header().Set("X-Tenant", e.Param("tenant"))
+		return e.Next()
+	})
+	api.Mount("/legacy/", sub)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		target string
+		body   string
+	}{
+		{"/acme/api/legacy/users/7", "user 7 /users/7"},
+		{"/acme/api/legacy/", "root /"},
+		{"/acme/api/legacy/other", "root /other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.body, rec.Body.String())
+			assert.Equal(t, "acme", rec.Header().Get("X-Tenant"))
+		})
+	}
+}
+
+func TestRouterGroup_Handle(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.BindFunc(func(e *Event) error {
+		e.Response().Header().Set("X-Middleware", "1")
+		return e.Next()
+	})
+	router.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path))
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+
+	assert.Equal(t, "POST /metrics", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get("X-Middleware"))
+
+	assert.Panics(t, func() { router.Mount("/x", nil) })
+	assert.Panics(t, func() { router.Handle("/x", nil) })
+	assert.Panics(t, func() { router.HandleFunc("/x", nil) })
+}
+
+func TestStripSegments(t *testing.T) {
+	assert.Equal(t, "/b/c", stripSegments("/a/b/c", 1))
+	assert.Equal(t, "/c", stripSegments("/a/b/c", 2))
+	assert.Equal(t, "/", stripSegments("/a/b", 2))
+	assert.Equal(t, "/a", stripSegments("/a", 0))
+}
@@ -0,0 +1,208 @@
+package wo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gowool/wo/internal/encode"
+)
+
+// MaxNDJSONLineSize is the maximum size of a line of the NDJSON request body.
+const MaxNDJSONLineSize = 1 << 20 // 1mb
+
+// NDJSONLineError is the error of a malformed line of the NDJSON request body.
+type NDJSONLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *NDJSONLineError) Error() string {
+	return fmt.Sprintf("ndjson: line %d: %v", e.Line, e.Err)
+}
+
+func (e *NDJSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// NDJSON streams the values as the newline delimited JSON, one value per line, each line is
+// flushed to the client. The stream is aborted on the client disconnect. See [SeqOf] to pass
+// the typed sequences.
+func (e *Event) NDJSON(status int, values iter.Seq[any]) error {
+	SetHeaderIfMissing(e.response, HeaderContentType, MIMEApplicationNDJSON)
+	e.response.WriteHeader(status)
+
+	rc := http.NewResponseController(e.response)
+
+	var buf bytes.Buffer
+	for v := range values {
+		if err := e.Context().Err(); err != nil {
+			return err
+		}
+
+		buf.Reset()
+		if err := encode.MarshalJSON(&buf, v, ""); err != nil {
+			return err
+		}
+		if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+
+		if _, err := e.response.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+	}
+	return nil
+}
+
+// SeqOf returns the sequence of the values of seq as any, ex. for [Event.NDJSON].
+func SeqOf[V any](seq iter.Seq[V]) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// BindNDJSON decodes the NDJSON request body line by line, for the bulk imports which don't
+// fit in the memory. The malformed line yields [NDJSONLineError] and the decoding goes on,
+// the blank lines are skipped. The other errors, ex. of the unsupported media type or the
+// line longer than [MaxNDJSONLineSize], are yielded as [HTTPError] and stop the decoding.
+func BindNDJSON[V any](e *Event) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		base, _, _ := strings.Cut(e.request.Header.Get(HeaderContentType), ";")
+		if strings.TrimSpace(base) != MIMEApplicationNDJSON {
+			var zero V
+			yield(zero, ErrUnsupportedMediaType)
+			return
+		}
+
+		for v, err := range DecodeNDJSON[V](e.request.Body) {
+			if err != nil {
+				var lineErr *NDJSONLineError
+				if !errors.As(err, &lineErr) {
+					err = ndjsonReadError(err)
+				}
+			}
+			if !yield(v, err) {
+				return
+			}
+		}
+	}
+}
+
+// DecodeNDJSON decodes the newline delimited JSON values of r like [BindNDJSON].
+func DecodeNDJSON[V any](r io.Reader) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		stopped := false
+		err := scanNDJSON(r, func(line int, b []byte) bool {
+			var v V
+			if err := encode.UnmarshalJSON(bytes.NewReader(b), &v); err != nil {
+				stopped = !yield(v, &NDJSONLineError{Line: line, Err: err})
+			} else {
+				stopped = !yield(v, nil)
+			}
+			return !stopped
+		})
+		if err != nil && !stopped {
+			var zero V
+			yield(zero, err)
+		}
+	}
+}
+
+// scanNDJSON calls fn with the number and the content of each non-blank line of r until
+// fn returns false.
+func scanNDJSON(r io.Reader, fn func(line int, b []byte) bool) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, MaxNDJSONLineSize)
+
+	for line := 1; sc.Scan(); line++ {
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		if !fn(line, b) {
+			return nil
+		}
+	}
+	return sc.Err()
+}
+
+// bindNDJSON binds the NDJSON body to dst, a pointer to iter.Seq2[V, error], which decodes
+// the body line by line while it's ranged over like [BindNDJSON], so the body isn't buffered:
+//
+//	var items iter.Seq2[Item, error]
+//	if err := e.BindBody(&items); err != nil {
+//		return err
+//	}
+//	for item, err := range items {
+//		...
+//	}
+func (e *Event) bindNDJSON(dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || !isNDJSONSeq(rv.Elem().Type()) {
+		return fmt.Errorf("wo: ndjson body must be bound to a pointer to iter.Seq2[V, error], got %T", dst)
+	}
+
+	seq := rv.Elem().Type()
+	elem := seq.In(0).In(0)
+	body := e.request.Body
+
+	rv.Elem().Set(reflect.MakeFunc(seq, func(args []reflect.Value) []reflect.Value {
+		yield := func(v reflect.Value, err error) bool {
+			errValue := reflect.Zero(errorType)
+			if err != nil {
+				errValue = reflect.ValueOf(&err).Elem()
+			}
+			return args[0].Call([]reflect.Value{v, errValue})[0].Bool()
+		}
+
+		stopped := false
+		err := scanNDJSON(body, func(line int, b []byte) bool {
+			v := reflect.New(elem)
+			if err := encode.UnmarshalJSON(bytes.NewReader(b), v.Interface()); err != nil {
+				stopped = !yield(reflect.Zero(elem), &NDJSONLineError{Line: line, Err: err})
+			} else {
+				stopped = !yield(v.Elem(), nil)
+			}
+			return !stopped
+		})
+		if err != nil && !stopped {
+			yield(reflect.Zero(elem), ndjsonReadError(err))
+		}
+		return nil
+	}))
+	return nil
+}
+
+var errorType = reflect.TypeFor[error]()
+
+// isNDJSONSeq reports whether t is iter.Seq2[V, error].
+func isNDJSONSeq(t reflect.Type) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+	yield := t.In(0)
+	return yield.Kind() == reflect.Func && yield.NumIn() == 2 && yield.NumOut() == 1 &&
+		yield.In(1) == errorType && yield.Out(0).Kind() == reflect.Bool
+}
+
+func ndjsonReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) || errors.Is(err, bufio.ErrTooLong) {
+		return ErrStatusRequestEntityTooLarge.WithInternal(err)
+	}
+	return ErrBadRequest.WithInternal(err)
+}
@@ -0,0 +1,160 @@
+package wo
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ndjsonItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newNDJSONEvent(body string) *Event {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(HeaderContentType, MIMEApplicationNDJSON+"; charset=utf-8")
+
+	e := new(Event)
+	e.Reset(httptest.NewRecorder(), req)
+	return e
+}
+
+func TestEvent_NDJSON(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	e := new(Event)
+	e.Reset(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	items := slices.Values([]ndjsonItem{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}})
+	require.NoError(t, e.NDJSON(http.StatusOK, SeqOf(items)))
+
+	assert.Equal(t, MIMEApplicationNDJSON, w.Header().Get(HeaderContentType))
+	assert.Equal(t, "{\"id\":1,\"name\":\"a\"}\n{\"id\":2,\"name\":\"b\"}\n", w.Body.String())
+	assert.Equal(t, 2, w.flushCount())
+}
+
+func TestEvent_NDJSON_ClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rec := httptest.NewRecorder()
+	e := new(Event)
+	e.Reset(rec, httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+
+	values := func(yield func(any) bool) {
+		for i := 0; ; i++ {
+			if i == 2 {
+				cancel()
+			}
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	require.ErrorIs(t, e.NDJSON(http.StatusOK, values), context.Canceled)
+	assert.Equal(t, "0\n1\n", rec.Body.String())
+}
+
+func TestBindNDJSON(t *testing.T) {
+	e := newNDJSONEvent("{\"id\":1,\"name\":\"a\"}\n\n{\"id\":\"x\"}\n  {\"id\":3,\"name\":\"c\"}  \r\nnot json\n")
+
+	var (
+		items []ndjsonItem
+		lines []int
+	)
+	for item, err := range BindNDJSON[ndjsonItem](e) {
+		if err != nil {
+			var lineErr *NDJSONLineError
+			require.ErrorAs(t, err, &lineErr)
+			lines = append(lines, lineErr.Line)
+			continue
+		}
+		items = append(items, item)
+	}
+
+	assert.Equal(t, []ndjsonItem{{ID: 1, Name: "a"}, {ID: 3, Name: "c"}}, items)
+	assert.Equal(t, []int{3, 5}, lines)
+}
+
+func TestBindNDJSON_Errors(t *testing.T) {
+	t.Run("media type", func(t *testing.T) {
+		e := newNDJSONEvent("{}\n")
+		e.Request().Header.Set(HeaderContentType, MIMEApplicationJSON)
+
+		for _, err := range BindNDJSON[ndjsonItem](e) {
+			require.ErrorIs(t, err, ErrUnsupportedMediaType)
+		}
+	})
+
+	t.Run("line too long", func(t *testing.T) {
+		e := newNDJSONEvent("{}\n\"" + strings.Repeat("x", MaxNDJSONLineSize) + "\"\n{}\n")
+
+		var errs []error
+		for _, err := range BindNDJSON[map[string]any](e) {
+			errs = append(errs, err)
+		}
+
+		require.Len(t, errs, 2)
+		assert.NoError(t, errs[0])
+		assert.Equal(t, http.StatusRequestEntityTooLarge, AsHTTPError(errs[1]).Status)
+	})
+
+	t.Run("early stop", func(t *testing.T) {
+		e := newNDJSONEvent("1\n2\n3\n")
+
+		var got []int
+		for v := range BindNDJSON[int](e) {
+			if got = append(got, v); len(got) == 2 {
+				break
+			}
+		}
+		assert.Equal(t, []int{1, 2}, got)
+	})
+}
+
+func TestEvent_BindBody_NDJSON(t *testing.T) {
+	var items iter.Seq2[ndjsonItem, error]
+	require.NoError(t, newNDJSONEvent("{\"id\":1}\n{\"id\":true}\n{\"id\":3}\n").BindBody(&items))
+
+	var (
+		got   []ndjsonItem
+		lines []int
+	)
+	for item, err := range items {
+		if err != nil {
+			var lineErr *NDJSONLineError
+			require.ErrorAs(t, err, &lineErr)
+			lines = append(lines, lineErr.Line)
+			continue
+		}
+		got = append(got, item)
+	}
+	assert.Equal(t, []ndjsonItem{{ID: 1}, {ID: 3}}, got)
+	assert.Equal(t, []int{2}, lines)
+
+	// the body is read while the iterator is ranged over, not by BindBody
+	e := newNDJSONEvent("1\n2\n3\n")
+	var values iter.Seq2[int, error]
+	require.NoError(t, e.BindBody(&values))
+	unread, err := io.ReadAll(e.Request().Body)
+	require.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n", string(unread))
+
+	var tooLong iter.Seq2[string, error]
+	require.NoError(t, newNDJSONEvent("\""+strings.Repeat("x", MaxNDJSONLineSize)+"\"\n").BindBody(&tooLong))
+	for _, err := range tooLong {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, AsHTTPError(err).Status)
+	}
+
+	var slice []ndjsonItem
+	err = newNDJSONEvent("{\"id\":1}\n").BindBody(&slice)
+	require.EqualError(t, err, "wo: ndjson body must be bound to a pointer to iter.Seq2[V, error], got *[]wo.ndjsonItem")
+	assert.Nil(t, AsHTTPError(err))
+}
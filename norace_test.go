@@ -0,0 +1,5 @@
+//go:build !race
+
+package wo
+
+const raceEnabled = false
@@ -0,0 +1,190 @@
+package wo
+
+import (
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+)
+
+// The trailing slash policies, see [PathConfig.TrailingSlash].
+const (
+	TrailingSlashStrict   = "strict"
+	TrailingSlashRedirect = "redirect"
+	TrailingSlashRewrite  = "rewrite"
+)
+
+// PathConfig configures the normalization of the request paths applied before the routes are
+// matched, see [Router.SetPathConfig], so the route patterns aren't duplicated per spelling.
+type PathConfig struct {
+	// Clean collapses the repeated slashes and resolves the "." and ".." segments of the path,
+	// ex. "/a//b/../c" is matched as "/a/c".
+	//
+	// Default: false
+	Clean bool `env:"CLEAN" json:"clean,omitempty" yaml:"clean,omitempty"`
+
+	// CaseInsensitive matches the lowercase path, so the route patterns must be lowercase.
+	// Note, the path values of the wildcards are lowercase too.
+	//
+	// Default: false
+	CaseInsensitive bool `env:"CASE_INSENSITIVE" json:"caseInsensitive,omitempty" yaml:"caseInsensitive,omitempty"`
+
+	// TrailingSlash is the policy of the paths with the trailing slash: "strict" matches them
+	// as is, "redirect" redirects them to the path without the trailing slash, 301 Moved
+	// Permanently for GET and HEAD, 308 Permanent Redirect otherwise, "rewrite" matches the path
+	// without the trailing slash. The routes are registered without the trailing slash then,
+	// ex. "/users" serves "/users/" too, the subtrees with the "{path...}" wildcard. The roots
+	// of the subtrees keep the trailing slash, ex. "/static/" of "/static/{path...}", since the
+	// matcher redirects "/static" to it.
+	//
+	// Default: "strict"
+	TrailingSlash string `env:"TRAILING_SLASH" json:"trailingSlash,omitempty" yaml:"trailingSlash,omitempty"`
+}
+
+func (c *PathConfig) SetDefaults() {
+	if c.TrailingSlash == "" {
+		c.TrailingSlash = TrailingSlashStrict
+	}
+}
+
+func (c *PathConfig) Validate() error {
+	switch c.TrailingSlash {
+	case TrailingSlashStrict, TrailingSlashRedirect, TrailingSlashRewrite:
+		return nil
+	default:
+		return fmt.Errorf("router: unknown trailing slash policy %q", c.TrailingSlash)
+	}
+}
+
+// SetPathConfig sets the normalization of the request paths, it must be called before
+// [Router.Build]. The [Router.Pre] middlewares see the normalized request,
+// [IncomingRequest] is the one the router received.
+//
+//	r.SetPathConfig(wo.PathConfig{Clean: true, TrailingSlash: wo.TrailingSlashRedirect})
+func (r *Router[T]) SetPathConfig(cfg PathConfig) {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	if !cfg.Clean && !cfg.CaseInsensitive && cfg.TrailingSlash == TrailingSlashStrict {
+		r.paths = nil
+		return
+	}
+	r.paths = &cfg
+}
+
+// normalize returns the request with the normalized path, or the redirect to it.
+// The subtrees are the segments of the subtree roots, see subtreeRoots.
+func (c *PathConfig) normalize(req *http.Request, subtrees [][]string) (*http.Request, *RedirectError) {
+	escaped := req.URL.EscapedPath()
+
+	p := escaped
+	if c.Clean {
+		p = cleanPath(p)
+	}
+	if c.CaseInsensitive {
+		p = strings.ToLower(p)
+	}
+	if c.TrailingSlash != TrailingSlashStrict && len(p) > 1 && strings.HasSuffix(p, "/") {
+		trimmed := strings.TrimRight(p, "/")
+		if trimmed == "" {
+			trimmed = "/"
+		}
+
+		if isSubtreeRoot(subtrees, trimmed) {
+			// the matcher redirects the root of the subtree back to the trailing slash
+			trimmed = p
+		} else if c.TrailingSlash == TrailingSlashRedirect {
+			// the leading slashes are collapsed, "//host/" isn't the protocol-relative redirect
+			trimmed = "/" + strings.TrimLeft(trimmed, "/")
+
+			status := http.StatusPermanentRedirect
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+			if req.URL.RawQuery != "" {
+				trimmed += "?" + req.URL.RawQuery
+			}
+			return req, &RedirectError{Status: status, URL: trimmed}
+		}
+		p = trimmed
+	}
+
+	if p == escaped {
+		return req, nil
+	}
+
+	unescaped, err := url.PathUnescape(p)
+	if err != nil {
+		return req, nil
+	}
+
+	u := *req.URL
+	u.Path, u.RawPath = unescaped, p
+
+	r2 := new(http.Request)
+	*r2 = *req
+	r2.URL = &u
+	r2.RequestURI = u.RequestURI()
+	return r2, nil
+}
+
+// cleanPath returns the canonical path of p, keeping the trailing slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+
+	np := path.Clean(p)
+	if p[len(p)-1] == '/' && np != "/" {
+		np += "/"
+	}
+	return np
+}
+
+// subtreeRoots returns the segments of the roots of the subtree patterns without the trailing
+// slash, ex. ["static"] of "GET /static/{path...}" and "/static/".
+func subtreeRoots(patterns iter.Seq[string]) [][]string {
+	var roots [][]string
+	for pattern := range patterns {
+		// the method and the host are not the part of the path
+		if i := strings.IndexByte(pattern, '/'); i >= 0 {
+			pattern = pattern[i:]
+		}
+
+		var root string
+		switch {
+		case strings.HasSuffix(pattern, "...}"):
+			root = pattern[:strings.LastIndexByte(pattern, '/')]
+		case strings.HasSuffix(pattern, "/"):
+			root = pattern[:len(pattern)-1]
+		}
+		if root != "" {
+			roots = append(roots, strings.Split(root[1:], "/"))
+		}
+	}
+	return roots
+}
+
+// isSubtreeRoot reports whether the path without the trailing slash is the root of a subtree,
+// the wildcards of the roots match any segment.
+func isSubtreeRoot(roots [][]string, p string) bool {
+	if len(roots) == 0 || p == "/" {
+		return false
+	}
+
+	segments := strings.Split(p[1:], "/")
+	return slices.ContainsFunc(roots, func(root []string) bool {
+		return slices.EqualFunc(root, segments, func(s, segment string) bool {
+			return s == segment || (strings.HasPrefix(s, "{") && segment != "")
+		})
+	})
+}
@@ -0,0 +1,190 @@
+package wo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_SetPathConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           PathConfig
+		method           string
+		target           string
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{name: "strict", target: "/users/", expectedStatus: http.StatusNotFound},
+		{name: "strict match", target: "/users", expectedStatus: http.StatusOK, expectedBody: "/users"},
+		{
+			name:           "clean",
+			config:         PathConfig{Clean: true},
+			target:         "/x//../users",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/users",
+		},
+		{
+			name:           "case insensitive",
+			config:         PathConfig{CaseInsensitive: true},
+			target:         "/Users/BOB",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/users/bob bob",
+		},
+		{
+			name:           "escaped value",
+			config:         PathConfig{CaseInsensitive: true},
+			target:         "/users/A%2FB",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/users/a%2fb a/b",
+		},
+		{
+			name:           "rewrite",
+			config:         PathConfig{TrailingSlash: TrailingSlashRewrite},
+			target:         "/users/?page=2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/users?page=2",
+		},
+		{
+			name:             "redirect",
+			config:           PathConfig{TrailingSlash: TrailingSlashRedirect},
+			target:           "/users//?page=2",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "/users?page=2",
+		},
+		{
+			name:             "redirect post",
+			config:           PathConfig{TrailingSlash: TrailingSlashRedirect},
+			method:           http.MethodPost,
+			target:           "/users/",
+			expectedStatus:   http.StatusPermanentRedirect,
+			expectedLocation: "/users",
+		},
+		{
+			name:             "redirect leading slashes",
+			config:           PathConfig{TrailingSlash: TrailingSlashRedirect},
+			target:           "//evil.com/",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "/evil.com",
+		},
+		{
+			name:           "root",
+			config:         PathConfig{TrailingSlash: TrailingSlashRedirect},
+			target:         "/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/",
+		},
+		{
+			name:           "rewrite subtree root",
+			config:         PathConfig{TrailingSlash: TrailingSlashRewrite},
+			target:         "/static/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/static/ ",
+		},
+		{
+			name:           "redirect subtree root",
+			config:         PathConfig{TrailingSlash: TrailingSlashRedirect},
+			target:         "/files/1/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/files/1/ ",
+		},
+		{
+			name:             "redirect subtree",
+			config:           PathConfig{TrailingSlash: TrailingSlashRedirect},
+			target:           "/static/css/",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "/static/css",
+		},
+		{
+			name:             "all",
+			config:           PathConfig{Clean: true, CaseInsensitive: true, TrailingSlash: TrailingSlashRedirect},
+			target:           "/USERS/./bob/",
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "/users/bob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New[*Event](func(w http.ResponseWriter, r *http.Request) (*Event, EventCleanupFunc) {
+				e := new(Event)
+				e.Reset(w, r)
+				return e, nil
+			}, ErrorHandler[*Event](nil, nil, nil))
+			r.SetPathConfig(tt.config)
+
+			var incoming string
+			r.PreFunc(func(e *Event) error {
+				in, _ := IncomingRequest(e.Request().Context())
+				incoming = in.URL.RequestURI()
+				return e.Next()
+			})
+			r.Any("/{$}", func(e *Event) error {
+				return e.String(http.StatusOK, e.Request().URL.RequestURI())
+			})
+			r.Any("/users", func(e *Event) error {
+				return e.String(http.StatusOK, e.Request().URL.RequestURI())
+			})
+			r.Any("/users/{name}", func(e *Event) error {
+				return e.String(http.StatusOK, e.Request().URL.RequestURI()+" "+e.Request().PathValue("name"))
+			})
+			r.GET("/static/{path...}", func(e *Event) error {
+				return e.String(http.StatusOK, e.Request().URL.RequestURI()+" "+e.Request().PathValue("path"))
+			})
+			r.GET("/files/{id}/{path...}", func(e *Event) error {
+				return e.String(http.StatusOK, e.Request().URL.RequestURI()+" "+e.Request().PathValue("path"))
+			})
+
+			h, err := r.Build(nil)
+			require.NoError(t, err)
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedLocation, rec.Header().Get(HeaderLocation))
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+				assert.Equal(t, tt.target, incoming)
+			}
+		})
+	}
+}
+
+func TestSubtreeRoots(t *testing.T) {
+	roots := subtreeRoots(slices.Values([]string{
+		"GET /static/{path...}",
+		"example.com/assets/",
+		"/files/{id}/{path...}",
+		"/users/{$}",
+		"/",
+	}))
+	assert.Equal(t, [][]string{{"static"}, {"assets"}, {"files", "{id}"}}, roots)
+
+	assert.True(t, isSubtreeRoot(roots, "/static"))
+	assert.True(t, isSubtreeRoot(roots, "/files/1"))
+	assert.False(t, isSubtreeRoot(roots, "/files"))
+	assert.False(t, isSubtreeRoot(roots, "/static/css"))
+	assert.False(t, isSubtreeRoot(roots, "/users"))
+	assert.False(t, isSubtreeRoot(roots, "/"))
+}
+
+func TestPathConfig_Validate(t *testing.T) {
+	cfg := PathConfig{TrailingSlash: "ignore"}
+	cfg.SetDefaults()
+	assert.Error(t, cfg.Validate())
+
+	cfg = PathConfig{}
+	cfg.SetDefaults()
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, TrailingSlashStrict, cfg.TrailingSlash)
+}
@@ -0,0 +1,98 @@
+package wo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Plugin is a cohesive bundle of routes, middlewares and hooks
+// (ex. an auth or metrics plugin) registered on a Router.
+type Plugin[T Resolver] interface {
+	// Name returns the unique plugin name, also used to look up its configuration.
+	Name() string
+
+	// Configure receives the plugin configuration (nil if none was provided).
+	Configure(cfg any) error
+
+	// Register binds the plugin routes, middlewares and hooks to the router.
+	Register(r *Router[T]) error
+
+	// Shutdown releases the plugin resources.
+	Shutdown(ctx context.Context) error
+}
+
+// PluginRegistry keeps plugins in their registration order and manages their lifecycle.
+//
+// Example:
+//
+//	plugins := new(wo.PluginRegistry[*wo.Event])
+//	_ = plugins.Add(authPlugin, metricsPlugin)
+//	_ = plugins.Setup(router, map[string]any{"auth": authCfg})
+//	srv.RegisterOnShutdown(plugins.Shutdown)
+type PluginRegistry[T Resolver] struct {
+	plugins    []Plugin[T]
+	registered []Plugin[T]
+	mu         sync.Mutex
+}
+
+// Add appends plugins to the registry. Plugin names must be unique.
+func (p *PluginRegistry[T]) Add(plugins ...Plugin[T]) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, plugin := range plugins {
+		if plugin == nil {
+			return errors.New("plugin: nil plugin")
+		}
+		for _, existing := range p.plugins {
+			if existing.Name() == plugin.Name() {
+				return fmt.Errorf("plugin %q: already added", plugin.Name())
+			}
+		}
+		p.plugins = append(p.plugins, plugin)
+	}
+	return nil
+}
+
+// Plugins returns the added plugins in registration order.
+func (p *PluginRegistry[T]) Plugins() []Plugin[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Plugin[T](nil), p.plugins...)
+}
+
+// Setup configures and registers the plugins in order. It stops at the first failure,
+// the plugins registered so far are still shut down by [PluginRegistry.Shutdown].
+func (p *PluginRegistry[T]) Setup(r *Router[T], configs map[string]any) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, plugin := range p.plugins[len(p.registered):] {
+		if err := plugin.Configure(configs[plugin.Name()]); err != nil {
+			return fmt.Errorf("plugin %q: configure: %w", plugin.Name(), err)
+		}
+		if err := plugin.Register(r); err != nil {
+			return fmt.Errorf("plugin %q: register: %w", plugin.Name(), err)
+		}
+		p.registered = append(p.registered, plugin)
+	}
+	return nil
+}
+
+// Shutdown shuts down the registered plugins in reverse order and joins their errors.
+func (p *PluginRegistry[T]) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	for i := len(p.registered) - 1; i >= 0; i-- {
+		if err1 := p.registered[i].Shutdown(ctx); err1 != nil {
+			err = errors.Join(err, fmt.Errorf("plugin %q: shutdown: %w", p.registered[i].Name(), err1))
+		}
+	}
+	p.registered = nil
+	return err
+}
@@ -0,0 +1,101 @@
+package wo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPlugin struct {
+	name        string
+	calls       *[]string
+	cfg         any
+	registerErr error
+	shutdownErr error
+}
+
+func (p *testPlugin) Name() string { return p.name }
+
+func (p *testPlugin) Configure(cfg any) error {
+	p.cfg = cfg
+	*p.calls = append(*p.calls, "configure:"+p.name)
+	return nil
+}
+
+func (p *testPlugin) Register(r *Router[*Event]) error {
+	*p.calls = append(*p.calls, "register:"+p.name)
+	if p.registerErr != nil {
+		return p.registerErr
+	}
+	r.GET("/"+p.name, func(e *Event) error { return e.String(http.StatusOK, p.name) })
+	return nil
+}
+
+func (p *testPlugin) Shutdown(context.Context) error {
+	*p.calls = append(*p.calls, "shutdown:"+p.name)
+	return p.shutdownErr
+}
+
+func TestPluginRegistry_Lifecycle(t *testing.T) {
+	var calls []string
+
+	auth := &testPlugin{name: "auth", calls: &calls}
+	metrics := &testPlugin{name: "metrics", calls: &calls, shutdownErr: errors.New("flush failed")}
+
+	registry := new(PluginRegistry[*Event])
+	require.NoError(t, registry.Add(auth, metrics))
+	assert.Len(t, registry.Plugins(), 2)
+
+	router := New[*Event](eventFactory, errorHandler)
+	require.NoError(t, registry.Setup(router, map[string]any{"auth": "secret"}))
+	assert.Equal(t, "secret", auth.cfg)
+	assert.Nil(t, metrics.cfg)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, "metrics", rec.Body.String())
+
+	err = registry.Shutdown(context.Background())
+	assert.ErrorContains(t, err, `plugin "metrics": shutdown: flush failed`)
+
+	assert.Equal(t, []string{
+		"configure:auth", "register:auth",
+		"configure:metrics", "register:metrics",
+		"shutdown:metrics", "shutdown:auth",
+	}, calls)
+}
+
+func TestPluginRegistry_AddErrors(t *testing.T) {
+	var calls []string
+
+	registry := new(PluginRegistry[*Event])
+	require.NoError(t, registry.Add(&testPlugin{name: "auth", calls: &calls}))
+
+	assert.Error(t, registry.Add(&testPlugin{name: "auth", calls: &calls}))
+	assert.Error(t, registry.Add(nil))
+}
+
+func TestPluginRegistry_SetupFailure(t *testing.T) {
+	var calls []string
+
+	registry := new(PluginRegistry[*Event])
+	require.NoError(t, registry.Add(
+		&testPlugin{name: "a", calls: &calls},
+		&testPlugin{name: "b", calls: &calls, registerErr: errors.New("boom")},
+		&testPlugin{name: "c", calls: &calls},
+	))
+
+	err := registry.Setup(New[*Event](eventFactory, errorHandler), nil)
+	assert.EqualError(t, err, `plugin "b": register: boom`)
+
+	require.NoError(t, registry.Shutdown(context.Background()))
+	assert.Equal(t, []string{"configure:a", "register:a", "configure:b", "register:b", "shutdown:a"}, calls)
+}
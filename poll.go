@@ -0,0 +1,47 @@
+package wo
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type ctxPollerKey struct{}
+
+// Poller waits for the events of the topics, ex. the broker of the longpoll package.
+type Poller interface {
+	// Poll waits until the topic has the events after the cursor since, the timeout elapses or
+	// ctx is done, and returns the response encoded as JSON. The timeout yields the response
+	// without the events, not an error.
+	Poll(ctx context.Context, topic string, since uint64, timeout time.Duration) (any, error)
+}
+
+// WithPoller returns a copy of ctx which carries the poller used by [Event.LongPoll].
+func WithPoller(ctx context.Context, p Poller) context.Context {
+	return context.WithValue(ctx, ctxPollerKey{}, p)
+}
+
+// PollerFromContext returns the poller stored in ctx.
+func PollerFromContext(ctx context.Context) (Poller, bool) {
+	p, ok := ctx.Value(ctxPollerKey{}).(Poller)
+	return p, ok && p != nil
+}
+
+// LongPoll waits for the events of the topic after the cursor since, up to the timeout, and
+// sends them as JSON, for the clients which can't use the streaming responses. The poller is
+// taken from the request context, see [WithPoller]; without it [ErrPollerNotRegistered]
+// is returned.
+func (e *Event) LongPoll(topic string, since uint64, timeout time.Duration) error {
+	p, ok := PollerFromContext(e.Context())
+	if !ok {
+		return ErrPollerNotRegistered
+	}
+
+	res, err := p.Poll(e.Context(), topic, since, timeout)
+	if err != nil {
+		return err
+	}
+
+	SetHeaderIfMissing(e.response, HeaderCacheControl, "no-store")
+	return e.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,56 @@
+package wo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pollerFunc func(ctx context.Context, topic string, since uint64, timeout time.Duration) (any, error)
+
+func (f pollerFunc) Poll(ctx context.Context, topic string, since uint64, timeout time.Duration) (any, error) {
+	return f(ctx, topic, since, timeout)
+}
+
+func TestEvent_LongPoll(t *testing.T) {
+	poller := pollerFunc(func(_ context.Context, topic string, since uint64, timeout time.Duration) (any, error) {
+		if topic == "broken" {
+			return nil, errors.New("poll error")
+		}
+		return map[string]any{"topic": topic, "cursor": since + 1, "timeout": timeout.String()}, nil
+	})
+
+	t.Run("events", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		e := new(Event)
+		e.Reset(rec, req.WithContext(WithPoller(req.Context(), poller)))
+
+		require.NoError(t, e.LongPoll("orders", 5, time.Second))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get(HeaderCacheControl))
+		assert.JSONEq(t, `{"topic":"orders","cursor":6,"timeout":"1s"}`, rec.Body.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		e := new(Event)
+		e.Reset(httptest.NewRecorder(), req.WithContext(WithPoller(req.Context(), poller)))
+
+		require.EqualError(t, e.LongPoll("broken", 0, time.Second), "poll error")
+	})
+
+	t.Run("no poller", func(t *testing.T) {
+		e := new(Event)
+		e.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.ErrorIs(t, e.LongPoll("orders", 0, time.Second), ErrPollerNotRegistered)
+	})
+}
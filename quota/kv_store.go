@@ -0,0 +1,56 @@
+package quota
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gowool/wo/kv"
+)
+
+var _ Store = (*KVStore)(nil)
+
+// KVStore is the [Store] of the shared [kv.Store], ex. the Redis one, the counters are consumed
+// holding the lock of the key, so the limit is kept across the instances.
+//
+//	store := quota.NewKVStore(redis, kv.NewLocker(kv.LockerConfig{TTL: 5 * time.Second}, redis))
+type KVStore struct {
+	store  kv.Store
+	locker *kv.Locker
+}
+
+func NewKVStore(store kv.Store, locker *kv.Locker) *KVStore {
+	if store == nil || locker == nil {
+		panic("quota: kv store or locker is nil")
+	}
+	return &KVStore{store: store, locker: locker}
+}
+
+func (s *KVStore) Consume(ctx context.Context, key string, n, limit int64, exp time.Time) (used int64, ok bool, err error) {
+	err = s.locker.Do(ctx, "quota:"+key, func(ctx context.Context, _ uint64) error {
+		if used, err = s.Usage(ctx, key); err != nil {
+			return err
+		}
+		if used+n > limit {
+			return nil
+		}
+
+		used, ok = used+n, true
+
+		// the counter of the past period isn't kept
+		ttl := time.Until(exp)
+		if ttl <= 0 {
+			return nil
+		}
+		return s.store.Set(ctx, key, strconv.AppendInt(nil, used, 10), ttl)
+	})
+	return used, ok, err
+}
+
+func (s *KVStore) Usage(ctx context.Context, key string) (int64, error) {
+	value, found, err := s.store.Get(ctx, key)
+	if err != nil || !found {
+		return 0, err
+	}
+	return strconv.ParseInt(string(value), 10, 64)
+}
@@ -0,0 +1,41 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/kv"
+)
+
+func TestKVStore(t *testing.T) {
+	ctx := context.Background()
+
+	store := kv.NewMemoryStore(kv.MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	q := New(Config{Name: "api", Max: 5, Period: Day}, NewKVStore(store, kv.NewLocker(kv.LockerConfig{RetryInterval: time.Millisecond}, store)))
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			_, _ = q.Consume(ctx, "acme", 1)
+		})
+	}
+	wg.Wait()
+
+	res, err := q.Usage(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), res.Used)
+
+	_, err = q.Consume(ctx, "acme", 1)
+	require.ErrorIs(t, err, ErrExceeded)
+
+	res, err = q.Usage(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), res.Used)
+}
@@ -0,0 +1,73 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo/clock"
+)
+
+var _ Store = (*MemoryStore)(nil)
+
+type memCounter struct {
+	used int64
+	exp  time.Time
+}
+
+// MemoryStore is the in-memory [Store] for a single instance deployment, the usage is lost on restart.
+type MemoryStore struct {
+	// Clock is the clock of the expiry.
+	//
+	// Default: clock.System
+	Clock clock.Clock
+
+	data      map[string]memCounter
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{Clock: clock.System, data: make(map[string]memCounter)}
+}
+
+func (s *MemoryStore) Consume(_ context.Context, key string, n, limit int64, exp time.Time) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := clock.Or(s.Clock).Now()
+
+	// drop the counters of the past periods periodically
+	if now.Sub(s.lastSweep) >= time.Minute {
+		for k, c := range s.data {
+			if !now.Before(c.exp) {
+				delete(s.data, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	c, ok := s.data[key]
+	if !ok || !now.Before(c.exp) {
+		c = memCounter{exp: exp}
+	}
+
+	if c.used+n > limit {
+		return c.used, false, nil
+	}
+
+	c.used += n
+	s.data[key] = c
+	return c.used, true, nil
+}
+
+func (s *MemoryStore) Usage(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.data[key]
+	if !ok || !clock.Or(s.Clock).Now().Before(c.exp) {
+		return 0, nil
+	}
+	return c.used, nil
+}
@@ -0,0 +1,279 @@
+// Package quota implements the long-horizon usage limits, ex. 10000 requests per month or
+// 1 GiB of downloads per day, which unlike the rate limits are counted over the calendar periods
+// and usually persisted in a shared store:
+//
+//	q := quota.New(quota.Config{Name: "api", Max: 10000, Period: quota.Month}, store)
+//
+//	res, err := q.Consume(ctx, accountID, 1)
+//	res.SetHeaders(w.Header())
+//	if err != nil {
+//		return err // 429 Too Many Requests, see ErrExceeded
+//	}
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/clock"
+)
+
+// ErrExceeded is returned when the quota is exhausted.
+var ErrExceeded = wo.ErrTooManyRequests.WithMessage("quota exceeded")
+
+// Store persists the usage counters.
+type Store interface {
+	// Consume atomically adds n to the counter stored under key unless the result exceeds limit,
+	// in which case the counter is left unchanged. It returns the counter value and whether n was added.
+	// The counter expires at exp.
+	Consume(ctx context.Context, key string, n, limit int64, exp time.Time) (used int64, ok bool, err error)
+
+	// Usage returns the counter stored under key, zero if it doesn't exist or has expired.
+	Usage(ctx context.Context, key string) (int64, error)
+}
+
+// Period is the calendar period the usage is counted over, in UTC.
+type Period string
+
+const (
+	Hour  Period = "hour"
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+)
+
+// Start returns the start of the period containing t.
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	switch p {
+	case Hour:
+		return t.Truncate(time.Hour)
+	case Week:
+		// the weeks start on Monday (ISO 8601)
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// End returns the end of the period containing t, which is the start of the next period.
+func (p Period) End(t time.Time) time.Time {
+	start := p.Start(t)
+	switch p {
+	case Hour:
+		return start.Add(time.Hour)
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func (p Period) valid() bool {
+	switch p {
+	case Hour, Day, Week, Month:
+		return true
+	default:
+		return false
+	}
+}
+
+type Config struct {
+	// Name identifies the quota in the store keys, so several quotas share the same store.
+	//
+	// Required.
+	Name string `env:"NAME" json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Max is the usage allowed per period, ex. the number of requests or bytes.
+	//
+	// Required.
+	Max int64 `env:"MAX" json:"max,omitempty" yaml:"max,omitempty"`
+
+	// MaxFunc returns the usage allowed per period of the subject, ex. the per-tenant quota
+	// (see tenant.Int). Zero or negative falls back to Max.
+	//
+	// Default: nil
+	MaxFunc func(ctx context.Context, subject string) int64 `json:"-" yaml:"-"`
+
+	// Period is the calendar period the usage is counted over.
+	//
+	// Default: Month
+	Period Period `env:"PERIOD" json:"period,omitempty" yaml:"period,omitempty"`
+
+	// Grace is the share of Max allowed as the overage, ex. 0.1 allows 10% more usage,
+	// which is flagged with Result.Overage. A negative value turns the quota into a soft limit:
+	// the usage is never denied, only flagged.
+	//
+	// Default: 0
+	Grace float64 `env:"GRACE" json:"grace,omitempty" yaml:"grace,omitempty"`
+
+	// OnOverage is called when the consumed usage exceeds Max, ex. to notify the account owner
+	// or to bill the overage.
+	//
+	// Default: nil
+	OnOverage func(ctx context.Context, subject string, res Result) `json:"-" yaml:"-"`
+
+	// Clock is the clock of the periods and the reset headers.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.Period == "" {
+		c.Period = Month
+	}
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("quota: name is required")
+	}
+	if c.Max <= 0 {
+		return errors.New("quota: max must be positive")
+	}
+	if !c.Period.valid() {
+		return fmt.Errorf("quota: invalid period %q", c.Period)
+	}
+	return nil
+}
+
+// Result is the quota state of a subject.
+type Result struct {
+	// Limit is the usage allowed per period, without the grace overage.
+	Limit int64
+
+	// Used is the usage within the current period.
+	Used int64
+
+	// Remaining is the usage left within the current period, without the grace overage.
+	Remaining int64
+
+	// Reset is the end of the current period.
+	Reset time.Time
+
+	// Overage reports whether the usage exceeds Limit.
+	Overage bool
+
+	// Exceeded reports whether the usage was denied, see [ErrExceeded].
+	Exceeded bool
+
+	// now is the time of the result by the quota clock.
+	now time.Time
+}
+
+// SetHeaders sets the X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset (seconds until the reset)
+// headers, and Retry-After when the usage was denied.
+func (r Result) SetHeaders(h http.Header) {
+	now := r.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	reset := strconv.FormatInt(max(0, int64(math.Ceil(r.Reset.Sub(now).Seconds()))), 10)
+
+	h.Set(wo.HeaderXQuotaLimit, strconv.FormatInt(r.Limit, 10))
+	h.Set(wo.HeaderXQuotaRemaining, strconv.FormatInt(r.Remaining, 10))
+	h.Set(wo.HeaderXQuotaReset, reset)
+	if r.Exceeded {
+		h.Set(wo.HeaderRetryAfter, reset)
+	}
+}
+
+// Quota counts the usage of the subjects.
+type Quota struct {
+	config Config
+	store  Store
+}
+
+func New(cfg Config, store Store) *Quota {
+	if store == nil {
+		panic("quota: store is nil")
+	}
+
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &Quota{config: cfg, store: store}
+}
+
+// Consume atomically adds n to the usage of the subject unless the usage would exceed the quota
+// (with the grace overage), in which case [ErrExceeded] is returned with the unchanged state.
+func (q *Quota) Consume(ctx context.Context, subject string, n int64) (Result, error) {
+	now := q.config.Clock.Now()
+	reset := q.config.Period.End(now)
+
+	maxUsage := q.max(ctx, subject)
+	limit := maxUsage + int64(float64(maxUsage)*q.config.Grace)
+	if q.config.Grace < 0 {
+		limit = math.MaxInt64
+	}
+
+	used, ok, err := q.store.Consume(ctx, q.key(subject, now), n, limit, reset)
+	if err != nil {
+		return Result{}, err
+	}
+
+	res := q.result(maxUsage, used, now)
+	if !ok {
+		res.Exceeded = true
+		return res, ErrExceeded
+	}
+	if res.Overage && q.config.OnOverage != nil {
+		q.config.OnOverage(ctx, subject, res)
+	}
+	return res, nil
+}
+
+// Usage returns the quota state of the subject without consuming it.
+func (q *Quota) Usage(ctx context.Context, subject string) (Result, error) {
+	now := q.config.Clock.Now()
+
+	used, err := q.store.Usage(ctx, q.key(subject, now))
+	if err != nil {
+		return Result{}, err
+	}
+	return q.result(q.max(ctx, subject), used, now), nil
+}
+
+// max returns the usage allowed per period of the subject.
+func (q *Quota) max(ctx context.Context, subject string) int64 {
+	if q.config.MaxFunc != nil {
+		if m := q.config.MaxFunc(ctx, subject); m > 0 {
+			return m
+		}
+	}
+	return q.config.Max
+}
+
+func (q *Quota) result(limit, used int64, now time.Time) Result {
+	return Result{
+		Limit:     limit,
+		Used:      used,
+		Remaining: max(0, limit-used),
+		Reset:     q.config.Period.End(now),
+		Overage:   used > limit,
+		now:       now,
+	}
+}
+
+// key returns the store key of the subject usage within the period containing t.
+func (q *Quota) key(subject string, t time.Time) string {
+	return q.config.Name + ":" + strconv.FormatInt(q.config.Period.Start(t).Unix(), 10) + ":" + subject
+}
@@ -0,0 +1,225 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/clock"
+)
+
+func TestPeriod(t *testing.T) {
+	at := time.Date(2024, time.February, 29, 13, 45, 10, 0, time.UTC) // Thursday
+
+	tests := []struct {
+		period        Period
+		expectedStart time.Time
+		expectedEnd   time.Time
+	}{
+		{Hour, time.Date(2024, time.February, 29, 13, 0, 0, 0, time.UTC), time.Date(2024, time.February, 29, 14, 0, 0, 0, time.UTC)},
+		{Day, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{Week, time.Date(2024, time.February, 26, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)},
+		{Month, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			assert.Equal(t, tt.expectedStart, tt.period.Start(at))
+			assert.Equal(t, tt.expectedEnd, tt.period.End(at))
+		})
+	}
+
+	// Sunday belongs to the week started on Monday
+	assert.Equal(t, time.Date(2024, time.February, 26, 0, 0, 0, 0, time.UTC), Week.Start(time.Date(2024, time.March, 3, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestQuota_Consume(t *testing.T) {
+	ctx := context.Background()
+	q := New(Config{Name: "api", Max: 3, Period: Day}, NewMemoryStore())
+
+	for i := range 3 {
+		res, err := q.Consume(ctx, "acme", 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), res.Used)
+		assert.Equal(t, int64(2-i), res.Remaining)
+		assert.False(t, res.Overage)
+	}
+
+	res, err := q.Consume(ctx, "acme", 1)
+	require.ErrorIs(t, err, ErrExceeded)
+	assert.Equal(t, http.StatusTooManyRequests, wo.AsHTTPError(err).Status)
+	assert.Equal(t, int64(3), res.Used)
+	assert.Equal(t, Day.End(time.Now()), res.Reset)
+
+	// the subjects have their own usage
+	res, err = q.Consume(ctx, "other", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), res.Remaining)
+
+	res, err = q.Usage(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Used)
+}
+
+func TestQuota_Grace(t *testing.T) {
+	ctx := context.Background()
+
+	var overages []int64
+	q := New(Config{
+		Name:  "bytes",
+		Max:   100,
+		Grace: 0.1,
+		OnOverage: func(_ context.Context, _ string, res Result) {
+			overages = append(overages, res.Used)
+		},
+	}, NewMemoryStore())
+
+	res, err := q.Consume(ctx, "acme", 100)
+	require.NoError(t, err)
+	assert.False(t, res.Overage)
+
+	res, err = q.Consume(ctx, "acme", 10)
+	require.NoError(t, err)
+	assert.True(t, res.Overage)
+	assert.Equal(t, int64(0), res.Remaining)
+
+	_, err = q.Consume(ctx, "acme", 1)
+	require.ErrorIs(t, err, ErrExceeded)
+
+	assert.Equal(t, []int64{110}, overages)
+}
+
+func TestQuota_SoftLimit(t *testing.T) {
+	q := New(Config{Name: "api", Max: 1, Grace: -1}, NewMemoryStore())
+
+	for range 5 {
+		_, err := q.Consume(context.Background(), "acme", 1)
+		require.NoError(t, err)
+	}
+
+	res, err := q.Usage(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), res.Used)
+	assert.True(t, res.Overage)
+}
+
+type planKey struct{}
+
+func TestQuota_MaxFunc(t *testing.T) {
+	q := New(Config{
+		Name: "api",
+		Max:  1,
+		MaxFunc: func(ctx context.Context, _ string) int64 {
+			n, _ := ctx.Value(planKey{}).(int64)
+			return n
+		},
+	}, NewMemoryStore())
+
+	ctx := context.WithValue(context.Background(), planKey{}, int64(3))
+
+	res, err := q.Consume(ctx, "acme", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), res.Limit)
+	assert.Equal(t, int64(1), res.Remaining)
+
+	// falls back to Max
+	_, err = q.Consume(context.Background(), "globex", 2)
+	require.ErrorIs(t, err, ErrExceeded)
+}
+
+type failingStore struct{}
+
+func (failingStore) Consume(context.Context, string, int64, int64, time.Time) (int64, bool, error) {
+	return 0, false, errors.New("store error")
+}
+
+func (failingStore) Usage(context.Context, string) (int64, error) {
+	return 0, errors.New("store error")
+}
+
+func TestQuota_StoreError(t *testing.T) {
+	q := New(Config{Name: "api", Max: 1}, failingStore{})
+
+	_, err := q.Consume(context.Background(), "acme", 1)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrExceeded)
+
+	_, err = q.Usage(context.Background(), "acme")
+	require.Error(t, err)
+}
+
+func TestResult_SetHeaders(t *testing.T) {
+	h := make(http.Header)
+	Result{Limit: 10, Used: 4, Remaining: 6, Reset: time.Now().Add(90 * time.Second)}.SetHeaders(h)
+
+	assert.Equal(t, "10", h.Get(wo.HeaderXQuotaLimit))
+	assert.Equal(t, "6", h.Get(wo.HeaderXQuotaRemaining))
+	assert.Equal(t, "90", h.Get(wo.HeaderXQuotaReset))
+	assert.Empty(t, h.Get(wo.HeaderRetryAfter))
+
+	Result{Limit: 10, Used: 10, Reset: time.Now().Add(time.Minute)}.SetHeaders(h)
+	assert.Empty(t, h.Get(wo.HeaderRetryAfter))
+
+	Result{Limit: 10, Used: 10, Reset: time.Now().Add(time.Minute), Exceeded: true}.SetHeaders(h)
+	assert.Equal(t, "60", h.Get(wo.HeaderRetryAfter))
+}
+
+func TestQuota_Clock(t *testing.T) {
+	clk := clock.NewMock(time.Date(2025, 1, 1, 23, 59, 0, 0, time.UTC))
+	store := NewMemoryStore()
+	store.Clock = clk
+
+	q := New(Config{Name: "api", Max: 1, Period: Day, Clock: clk}, store)
+
+	res, err := q.Consume(context.Background(), "acme", 1)
+	require.NoError(t, err)
+	assert.False(t, res.Exceeded)
+
+	h := make(http.Header)
+	res.SetHeaders(h)
+	assert.Equal(t, "60", h.Get(wo.HeaderXQuotaReset))
+	assert.Empty(t, h.Get(wo.HeaderRetryAfter))
+
+	res, err = q.Consume(context.Background(), "acme", 1)
+	require.ErrorIs(t, err, ErrExceeded)
+	assert.True(t, res.Exceeded)
+
+	res.SetHeaders(h)
+	assert.Equal(t, "60", h.Get(wo.HeaderRetryAfter))
+
+	clk.Add(time.Minute)
+	res, err = q.Consume(context.Background(), "acme", 1)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), res.Reset)
+}
+
+func TestNew_Panics(t *testing.T) {
+	assert.Panics(t, func() { New(Config{Name: "api", Max: 1}, nil) })
+	assert.Panics(t, func() { New(Config{Max: 1}, NewMemoryStore()) })
+	assert.Panics(t, func() { New(Config{Name: "api"}, NewMemoryStore()) })
+	assert.Panics(t, func() { New(Config{Name: "api", Max: 1, Period: "year"}, NewMemoryStore()) })
+}
+
+func TestMemoryStore_Clock(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewMock(now)
+
+	s := NewMemoryStore()
+	s.Clock = clk
+
+	used, ok, err := s.Consume(context.Background(), "k", 2, 5, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), used)
+
+	clk.Add(time.Hour)
+	used, err = s.Usage(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Zero(t, used)
+}
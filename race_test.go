@@ -0,0 +1,6 @@
+//go:build race
+
+package wo
+
+// raceEnabled reports whether the tests run with the race detector, which drops the pooled values.
+const raceEnabled = true
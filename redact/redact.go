@@ -0,0 +1,341 @@
+// Package redact scrubs the sensitive data, ex. the credentials and the personal data, before
+// it reaches the logs, the request recordings or any other sink:
+//
+//	r := redact.New(redact.Config{
+//		Fields:   []string{"password", "user.ssn", "cards.*.number"},
+//		Query:    []string{"token"},
+//		Patterns: []string{redact.Email, redact.CreditCard},
+//	})
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: r.ReplaceAttr}))
+//
+// The headers are scrubbed by the deny list, or by the allow list if it's set. The fields of
+// the JSON and the form bodies are scrubbed by name at any depth, or by the dotted path from the
+// root, where "*" matches any field or array item. The patterns are scrubbed in all the string
+// values and in the text bodies.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// Email is the pattern of the email addresses.
+	Email = `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`
+
+	// CreditCard is the pattern of the payment card numbers, 13 to 19 digits optionally
+	// separated by spaces or dashes.
+	CreditCard = `\b(?:\d[ \-]?){12,18}\d\b`
+)
+
+type Config struct {
+	// Mask is the replacement of the scrubbed values.
+	//
+	// Default: [REDACTED]
+	Mask string `env:"MASK" json:"mask,omitempty" yaml:"mask,omitempty"`
+
+	// AllowHeaders are the headers which are kept, the values of all the other headers are
+	// replaced with the Mask. If empty, DenyHeaders are used.
+	AllowHeaders []string `env:"ALLOW_HEADERS" json:"allowHeaders,omitempty" yaml:"allowHeaders,omitempty"`
+
+	// DenyHeaders are the headers the values of which are replaced with the Mask.
+	//
+	// Default: [Authorization, Cookie, Proxy-Authorization, Set-Cookie, X-Api-Key]
+	DenyHeaders []string `env:"DENY_HEADERS" json:"denyHeaders,omitempty" yaml:"denyHeaders,omitempty"`
+
+	// Query are the query parameters the values of which are replaced with the Mask.
+	Query []string `env:"QUERY" json:"query,omitempty" yaml:"query,omitempty"`
+
+	// Fields are the names, or the dotted paths, of the fields of the JSON and the form bodies
+	// and of the log attributes the values of which are replaced with the Mask. The names are
+	// matched case-insensitively at any depth, ex. "password", the paths from the root, ex.
+	// "user.password" or "cards.*.number".
+	Fields []string `env:"FIELDS" json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// Patterns are the regular expressions the matches of which are replaced with the Mask
+	// in the string values and the text bodies, ex. [Email] and [CreditCard].
+	Patterns []string `env:"PATTERNS" json:"patterns,omitempty" yaml:"patterns,omitempty"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.Mask == "" {
+		c.Mask = "[REDACTED]"
+	}
+	if len(c.DenyHeaders) == 0 {
+		c.DenyHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-Api-Key"}
+	}
+}
+
+func (c *Config) Validate() error {
+	for _, p := range c.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("redact: invalid pattern %q: %w", p, err)
+		}
+	}
+	for _, f := range c.Fields {
+		if f == "" || strings.HasPrefix(f, ".") || strings.HasSuffix(f, ".") || strings.Contains(f, "..") {
+			return errors.New("redact: invalid field path " + f)
+		}
+	}
+	return nil
+}
+
+// Redactor scrubs the sensitive data, it's safe for the concurrent use.
+type Redactor struct {
+	mask     string
+	allow    map[string]struct{}
+	deny     map[string]struct{}
+	query    []string
+	names    []string   // the fields matched at any depth
+	paths    [][]string // the fields matched from the root
+	patterns []*regexp.Regexp
+}
+
+// New returns the redactor, it panics if the config is invalid.
+func New(cfg Config) *Redactor {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	r := &Redactor{
+		mask:  cfg.Mask,
+		allow: canonicalSet(cfg.AllowHeaders),
+		deny:  canonicalSet(cfg.DenyHeaders),
+		query: cfg.Query,
+	}
+	for _, f := range cfg.Fields {
+		if strings.Contains(f, ".") {
+			r.paths = append(r.paths, strings.Split(f, "."))
+		} else {
+			r.names = append(r.names, f)
+		}
+	}
+	for _, p := range cfg.Patterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(p))
+	}
+	return r
+}
+
+// Mask returns the replacement of the scrubbed values.
+func (r *Redactor) Mask() string {
+	return r.mask
+}
+
+// Header returns the copy of the header with the values of the denied, or not allowed, headers
+// replaced with the Mask.
+func (r *Redactor) Header(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+
+	scrubbed := make(http.Header, len(header))
+	for name, values := range header {
+		if r.headerAllowed(name) {
+			scrubbed[name] = append([]string(nil), values...)
+		} else {
+			scrubbed[name] = []string{r.mask}
+		}
+	}
+	return scrubbed
+}
+
+// URL returns the request URI of the URL with the values of the scrubbed query parameters
+// replaced with the Mask.
+func (r *Redactor) URL(u *url.URL) string {
+	if len(r.query) == 0 || u.RawQuery == "" {
+		return u.RequestURI()
+	}
+
+	query := u.Query()
+	for _, name := range r.query {
+		if query.Has(name) {
+			query.Set(name, r.mask)
+		}
+	}
+
+	scrubbed := *u
+	scrubbed.RawQuery = query.Encode()
+	return scrubbed.RequestURI()
+}
+
+// Body returns the body of the content type scrubbed: the fields of the JSON and the form
+// bodies and the patterns of the text bodies. The bodies which can't be parsed are returned
+// as nil, so they never reach the sink unscrubbed. If the fields are set, the bodies the fields
+// of which can't be scrubbed, ex. multipart, XML or binary, are returned as nil too, except the
+// plain text ones. Otherwise the other bodies are returned as is.
+func (r *Redactor) Body(contentType string, body []byte) []byte {
+	fields := len(r.names) > 0 || len(r.paths) > 0
+	if len(body) == 0 || (!fields && len(r.patterns) == 0) {
+		return body
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		data, err := r.JSON(body)
+		if err != nil {
+			return nil
+		}
+		return data
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil
+		}
+		for name, values := range form {
+			if r.field([]string{name}) {
+				form[name] = []string{r.mask}
+				continue
+			}
+			for i, v := range values {
+				values[i] = r.String(v)
+			}
+		}
+		return []byte(form.Encode())
+	case fields && mediaType != "text/plain":
+		return nil
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "":
+		return []byte(r.String(string(body)))
+	default:
+		return body
+	}
+}
+
+// JSON returns the JSON document with the values of the fields replaced with the Mask
+// and the patterns scrubbed in the string values. The numbers are preserved as is.
+func (r *Redactor) JSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(r.Value(v))
+}
+
+// Value scrubs the decoded JSON value in place, ex. map[string]any, and returns it.
+func (r *Redactor) Value(v any) any {
+	return r.value(v, nil)
+}
+
+// String returns s with the matches of the patterns replaced with the Mask.
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		s = p.ReplaceAllLiteralString(s, r.mask)
+	}
+	return s
+}
+
+// ReplaceAttr scrubs the log attributes, it's meant for [slog.HandlerOptions]. The values of
+// the attributes matching the fields are replaced with the Mask, the string values are scrubbed
+// by the patterns.
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup {
+		return a
+	}
+
+	if r.field(append(groups[:len(groups):len(groups)], a.Key)) {
+		return slog.String(a.Key, r.mask)
+	}
+	if len(r.patterns) == 0 {
+		return a
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.String(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, r.String(err.Error()))
+		}
+	default:
+	}
+	return a
+}
+
+func (r *Redactor) value(v any, path []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			p := append(path[:len(path):len(path)], key)
+			if r.field(p) {
+				v[key] = r.mask
+				continue
+			}
+			v[key] = r.value(value, p)
+		}
+		return v
+	case []any:
+		// the array items are matched by "*"
+		p := append(path[:len(path):len(path)], "*")
+		for i, item := range v {
+			v[i] = r.value(item, p)
+		}
+		return v
+	case string:
+		return r.String(v)
+	default:
+		return v
+	}
+}
+
+// field reports whether the value at the path is scrubbed.
+func (r *Redactor) field(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	last := path[len(path)-1]
+	for _, name := range r.names {
+		if strings.EqualFold(name, last) {
+			return true
+		}
+	}
+
+	for _, p := range r.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range p {
+			if segment != "*" && !strings.EqualFold(segment, path[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) headerAllowed(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	if len(r.allow) > 0 {
+		_, ok := r.allow[name]
+		return ok
+	}
+	_, ok := r.deny[name]
+	return !ok
+}
+
+func canonicalSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+	return set
+}
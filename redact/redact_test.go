@@ -0,0 +1,178 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor_Header(t *testing.T) {
+	header := http.Header{
+		"Authorization": {"Bearer abc"},
+		"Cookie":        {"a=1", "b=2"},
+		"Accept":        {"text/html"},
+	}
+
+	t.Run("deny", func(t *testing.T) {
+		scrubbed := New(Config{}).Header(header)
+		assert.Equal(t, http.Header{
+			"Authorization": {"[REDACTED]"},
+			"Cookie":        {"[REDACTED]"},
+			"Accept":        {"text/html"},
+		}, scrubbed)
+		assert.Equal(t, "Bearer abc", header.Get("Authorization"))
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		scrubbed := New(Config{AllowHeaders: []string{"accept"}, Mask: "***"}).Header(header)
+		assert.Equal(t, http.Header{
+			"Authorization": {"***"},
+			"Cookie":        {"***"},
+			"Accept":        {"text/html"},
+		}, scrubbed)
+	})
+}
+
+func TestRedactor_URL(t *testing.T) {
+	u, err := url.Parse("/reset?token=abc&page=2")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/reset?token=abc&page=2", New(Config{}).URL(u))
+	assert.Equal(t, "/reset?page=2&token=%5BREDACTED%5D", New(Config{Query: []string{"token"}}).URL(u))
+}
+
+func TestRedactor_Body(t *testing.T) {
+	r := New(Config{
+		Fields:   []string{"password", "user.ssn", "cards.*.number"},
+		Patterns: []string{Email, CreditCard},
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"user":{"ssn":"1","password":"x","email":"ann@example.com"},"ssn":"2","n":1.50}`,
+			expected:    `{"n":1.50,"ssn":"2","user":{"email":"[REDACTED]","password":"[REDACTED]","ssn":"[REDACTED]"}}`,
+		},
+		{
+			name:        "json array paths",
+			contentType: "application/vnd.api+json",
+			body:        `{"cards":[{"number":"x","brand":"visa"}],"number":"y"}`,
+			expected:    `{"cards":[{"brand":"visa","number":"[REDACTED]"}],"number":"y"}`,
+		},
+		{
+			name:        "invalid json",
+			contentType: "application/json",
+			body:        `{"password":`,
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "PASSWORD=x&note=card+4111+1111+1111+1111",
+			expected:    "PASSWORD=%5BREDACTED%5D&note=card+%5BREDACTED%5D",
+		},
+		{
+			name:        "text",
+			contentType: "text/plain",
+			body:        "contact ann@example.com, card 4111-1111-1111-1111",
+			expected:    "contact [REDACTED], card [REDACTED]",
+		},
+		{
+			name:        "multipart",
+			contentType: "multipart/form-data; boundary=x",
+			body:        "--x\r\nContent-Disposition: form-data; name=\"password\"\r\n\r\nx\r\n--x--\r\n",
+		},
+		{
+			name:        "xml",
+			contentType: "text/xml",
+			body:        "<user><password>x</password></user>",
+		},
+		{
+			name:        "binary",
+			contentType: "application/octet-stream",
+			body:        "ann@example.com",
+		},
+		{
+			name: "no content type",
+			body: `{"password":"x"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, string(r.Body(tt.contentType, []byte(tt.body))))
+		})
+	}
+}
+
+func TestRedactor_Body_Noop(t *testing.T) {
+	body := []byte(`{"b":1.50,"a":2}`)
+	assert.Equal(t, body, New(Config{}).Body("application/json", body))
+}
+
+func TestRedactor_Body_Patterns(t *testing.T) {
+	r := New(Config{Patterns: []string{Email}})
+
+	assert.Equal(t, "[REDACTED]", string(r.Body("", []byte("ann@example.com"))))
+	assert.Equal(t, "<to>[REDACTED]</to>", string(r.Body("text/xml", []byte("<to>ann@example.com</to>"))))
+	assert.Equal(t, "ann@example.com", string(r.Body("application/octet-stream", []byte("ann@example.com"))))
+}
+
+func TestRedactor_ReplaceAttr(t *testing.T) {
+	r := New(Config{Fields: []string{"password", "req.token"}, Patterns: []string{Email}})
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return r.ReplaceAttr(groups, a)
+		},
+	}))
+
+	logger.Info("login",
+		slog.String("password", "x"),
+		slog.Int("attempt", 2),
+		slog.String("token", "kept"),
+		slog.Group("req", slog.String("token", "abc"), slog.String("email", "ann@example.com")),
+		slog.Any("error", errors.New("unknown user ann@example.com")),
+	)
+
+	assert.Equal(t, `level=INFO msg=login password=[REDACTED] attempt=2 token=kept req.token=[REDACTED] req.email=[REDACTED] error="unknown user [REDACTED]"`+"\n", buf.String())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "valid", cfg: Config{Fields: []string{"a", "a.*.b"}, Patterns: []string{Email}}},
+		{name: "invalid pattern", cfg: Config{Patterns: []string{"("}}, err: "redact: invalid pattern"},
+		{name: "invalid path", cfg: Config{Fields: []string{"a..b"}}, err: "redact: invalid field path a..b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+			assert.Panics(t, func() { New(tt.cfg) })
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package wo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+)
+
+// Renderer encodes the response data of a media type, ex. a JSON:API or HAL document,
+// see [Router.SetRenderer].
+type Renderer interface {
+	Render(w io.Writer, data any) error
+}
+
+// RendererFunc is an adapter to allow the use of ordinary functions as [Renderer].
+type RendererFunc func(w io.Writer, data any) error
+
+func (f RendererFunc) Render(w io.Writer, data any) error {
+	return f(w, data)
+}
+
+// SetRenderer registers the renderer of the media type used by [Event.Render] and
+// [Event.Negotiate] of all routes, it must be called before [Router.Build]. The nil
+// renderer removes the registered one.
+func (r *Router[T]) SetRenderer(mediaType string, renderer Renderer) {
+	mediaType = baseMediaType(mediaType)
+
+	if renderer == nil {
+		delete(r.renderers, mediaType)
+		return
+	}
+	if r.renderers == nil {
+		r.renderers = make(map[string]Renderer)
+	}
+	r.renderers[mediaType] = renderer
+}
+
+// Render sends the data encoded by the renderer of the media type, see [Router.SetRenderer].
+// Without the renderer [ErrRendererNotRegistered] is returned.
+func (e *Event) Render(status int, mediaType string, data any) error {
+	renderer, ok := rendererOf(e.Context(), mediaType)
+	if !ok {
+		return ErrRendererNotRegistered
+	}
+	return e.render(status, mediaType, renderer, data)
+}
+
+// render encodes the data into a buffer first, so the renderer errors are handled
+// by the error handler before the status is written.
+func (e *Event) render(status int, mediaType string, renderer Renderer, data any) error {
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, data); err != nil {
+		return err
+	}
+
+	SetHeaderIfMissing(e.response, HeaderContentType, mediaType)
+	e.response.WriteHeader(status)
+
+	_, err := buf.WriteTo(e.response)
+	return err
+}
+
+func rendererOf(ctx context.Context, mediaType string) (Renderer, bool) {
+	rc, ok := ctx.Value(ctxRouterKey{}).(*routerContext)
+	if !ok || len(rc.renderers) == 0 {
+		return nil, false
+	}
+	renderer, ok := rc.renderers[baseMediaType(mediaType)]
+	return renderer, ok
+}
+
+// baseMediaType returns the media type without the parameters, ex. the charset.
+func baseMediaType(mediaType string) string {
+	if base, _, err := mime.ParseMediaType(mediaType); err == nil {
+		return base
+	}
+	return mediaType
+}
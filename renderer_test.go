@@ -0,0 +1,92 @@
+package wo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_SetRenderer(t *testing.T) {
+	const mimeCSV = "text/csv"
+
+	router := New[*Event](eventFactory, func(e *Event, err error) {
+		e.Response().WriteHeader(http.StatusInternalServerError)
+	})
+	router.SetRenderer(mimeCSV+"; charset=utf-8", RendererFunc(func(w io.Writer, data any) error {
+		_, err := fmt.Fprintf(w, "csv:%v", data)
+		return err
+	}))
+	router.SetRenderer(MIMEApplicationXML, RendererFunc(func(w io.Writer, data any) error {
+		_, err := fmt.Fprintf(w, "xml:%v", data)
+		return err
+	}))
+	router.SetRenderer(MIMEApplicationXML, nil)
+	router.SetRenderer("text/failing", RendererFunc(func(w io.Writer, data any) error {
+		_, _ = io.WriteString(w, "partial")
+		return errors.New("render failed")
+	}))
+
+	router.GET("/negotiate", func(e *Event) error {
+		return e.Negotiate(http.StatusOK, "data", mimeCSV, MIMEApplicationXML, MIMEApplicationJSON)
+	})
+	router.GET("/render", func(e *Event) error {
+		return e.Render(http.StatusCreated, mimeCSV, "data")
+	})
+	router.GET("/failing", func(e *Event) error {
+		return e.Render(http.StatusOK, "text/failing", "data")
+	})
+	router.GET("/missing", func(e *Event) error {
+		return e.Render(http.StatusOK, "application/hal+json", "data")
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		status int
+		ctype  string
+		body   string
+	}{
+		{name: "negotiated renderer", target: "/negotiate", accept: mimeCSV, status: http.StatusOK, ctype: mimeCSV, body: "csv:data"},
+		{name: "removed renderer", target: "/negotiate", accept: MIMEApplicationXML, status: http.StatusOK, ctype: MIMEApplicationXML, body: "<string>data</string>"},
+		{name: "built-in", target: "/negotiate", accept: MIMEApplicationJSON, status: http.StatusOK, ctype: MIMEApplicationJSON, body: "\"data\"\n"},
+		{name: "render", target: "/render", status: http.StatusCreated, ctype: mimeCSV, body: "csv:data"},
+		{name: "not registered", target: "/missing", status: http.StatusInternalServerError},
+		{name: "renderer error", target: "/failing", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set(HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.ctype != "" {
+				assert.Equal(t, tt.ctype, rec.Header().Get(HeaderContentType))
+				assert.Contains(t, rec.Body.String(), tt.body)
+			} else {
+				assert.Empty(t, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestEvent_Render_WithoutRouter(t *testing.T) {
+	e := new(Event)
+	e.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.ErrorIs(t, e.Render(http.StatusOK, "text/csv", nil), ErrRendererNotRegistered)
+}
@@ -0,0 +1,89 @@
+// Package report sends the errors and the recovered panics to the error aggregation services,
+// ex. Sentry or an incident webhook, through a single [ErrorReporter] plugged into the Recover
+// middleware, the router error handler (see wo.ReportErrors) and the task runner:
+//
+//	reporter := report.NewWebhook(report.WebhookConfig{URL: "https://errors.example.com/hook"})
+//	defer reporter.Close(context.Background())
+//
+//	router := wo.New[*wo.Event](factory, wo.ReportErrors(wo.ErrorHandler[*wo.Event](nil, nil, logger), reporter))
+//	router.BindFunc(middleware.Recover[*wo.Event](middleware.RecoverConfig{Reporter: reporter}))
+package report
+
+import (
+	"context"
+	"errors"
+)
+
+// Tags are the key-value pairs attached to the reported events, ex. the route pattern.
+type Tags map[string]string
+
+// ErrorReporter captures the errors and the messages for an error aggregation service.
+// The implementations must be safe for the concurrent use and must not block the caller
+// for long, ex. they should send the events in the background.
+type ErrorReporter interface {
+	// CaptureException reports the error.
+	CaptureException(ctx context.Context, err error, tags Tags)
+
+	// CaptureMessage reports the message, ex. a notable condition which isn't an error.
+	CaptureMessage(ctx context.Context, msg string, tags Tags)
+}
+
+// Nop is the [ErrorReporter] discarding all the events.
+var Nop ErrorReporter = nop{}
+
+type nop struct{}
+
+func (nop) CaptureException(context.Context, error, Tags) {}
+
+func (nop) CaptureMessage(context.Context, string, Tags) {}
+
+// Or returns r, or [Nop] if r is nil.
+func Or(r ErrorReporter) ErrorReporter {
+	if r == nil {
+		return Nop
+	}
+	return r
+}
+
+// Multi returns the [ErrorReporter] reporting to all the reporters, ex. to the aggregation
+// service and the in-memory buffer of the admin pages.
+func Multi(reporters ...ErrorReporter) ErrorReporter {
+	return multi(reporters)
+}
+
+type multi []ErrorReporter
+
+func (m multi) CaptureException(ctx context.Context, err error, tags Tags) {
+	for _, r := range m {
+		r.CaptureException(ctx, err, tags)
+	}
+}
+
+func (m multi) CaptureMessage(ctx context.Context, msg string, tags Tags) {
+	for _, r := range m {
+		r.CaptureMessage(ctx, msg, tags)
+	}
+}
+
+type reportedError struct {
+	error
+}
+
+func (e reportedError) Unwrap() error {
+	return e.error
+}
+
+// Reported wraps the reported error, so the callers up the stack don't report it again,
+// see [IsReported].
+func Reported(err error) error {
+	if err == nil || IsReported(err) {
+		return err
+	}
+	return reportedError{err}
+}
+
+// IsReported reports whether the error, or any error it wraps, has been marked by [Reported].
+func IsReported(err error) bool {
+	var reported reportedError
+	return errors.As(err, &reported)
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReported(t *testing.T) {
+	err := errors.New("failed")
+
+	assert.False(t, IsReported(err))
+	assert.Nil(t, Reported(nil))
+
+	reported := Reported(err)
+	assert.True(t, IsReported(reported))
+	assert.True(t, IsReported(fmt.Errorf("wrapped: %w", reported)))
+	assert.ErrorIs(t, reported, err)
+	assert.Equal(t, "failed", reported.Error())
+	assert.Equal(t, reported, Reported(reported))
+}
+
+func TestOr(t *testing.T) {
+	assert.Equal(t, Nop, Or(nil))
+
+	w := NewWebhook(WebhookConfig{URL: "http://localhost"})
+	defer func() { _ = w.Close(t.Context()) }()
+	assert.Equal(t, ErrorReporter(w), Or(w))
+}
+
+type recordingReporter struct {
+	errors   []error
+	messages []string
+}
+
+func (r *recordingReporter) CaptureException(_ context.Context, err error, _ Tags) {
+	r.errors = append(r.errors, err)
+}
+
+func (r *recordingReporter) CaptureMessage(_ context.Context, msg string, _ Tags) {
+	r.messages = append(r.messages, msg)
+}
+
+func TestMulti(t *testing.T) {
+	a, b := new(recordingReporter), new(recordingReporter)
+	m := Multi(a, b)
+
+	err := errors.New("failed")
+	m.CaptureException(t.Context(), err, nil)
+	m.CaptureMessage(t.Context(), "notable", nil)
+
+	for _, r := range []*recordingReporter{a, b} {
+		assert.Equal(t, []error{err}, r.errors)
+		assert.Equal(t, []string{"notable"}, r.messages)
+	}
+}
@@ -0,0 +1,235 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ ErrorReporter = (*Webhook)(nil)
+
+const (
+	LevelError = "error"
+	LevelInfo  = "info"
+)
+
+// Event is the event posted by the [Webhook] as JSON.
+type Event struct {
+	Time        time.Time `json:"time"`
+	Level       string    `json:"level"`
+	Message     string    `json:"message"`
+	ErrorType   string    `json:"errorType,omitempty"`
+	Tags        Tags      `json:"tags,omitempty"`
+	Environment string    `json:"environment,omitempty"`
+	Release     string    `json:"release,omitempty"`
+}
+
+type WebhookConfig struct {
+	// URL is the endpoint the events are posted to.
+	URL string `env:"URL" json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Headers are the headers of the posted requests, ex. the authorization token.
+	Headers map[string]string `env:"HEADERS" json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// Environment is the environment of the events, ex. "production".
+	Environment string `env:"ENVIRONMENT" json:"environment,omitempty" yaml:"environment,omitempty"`
+
+	// Release is the release of the events, ex. the version of the application.
+	Release string `env:"RELEASE" json:"release,omitempty" yaml:"release,omitempty"`
+
+	// Timeout is the timeout of a posted request.
+	//
+	// Default: 5s
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// QueueSize is the capacity of the queue of the events waiting to be posted, the events are
+	// dropped once it's full.
+	//
+	// Default: 256
+	QueueSize int `env:"QUEUE_SIZE" json:"queueSize,omitempty" yaml:"queueSize,omitempty"`
+
+	// Client is the client posting the events.
+	//
+	// Default: http.DefaultClient
+	Client *http.Client `json:"-" yaml:"-"`
+
+	// OnError is called when an event can't be posted or is dropped.
+	OnError func(err error) `json:"-" yaml:"-"`
+}
+
+func (c *WebhookConfig) SetDefaults() {
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 256
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+}
+
+func (c *WebhookConfig) Validate() error {
+	if c.URL == "" {
+		return errors.New("report: webhook URL is required")
+	}
+	if u, err := url.Parse(c.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("report: invalid webhook URL %q", c.URL)
+	}
+	return nil
+}
+
+// Webhook is the generic [ErrorReporter] posting the events as JSON, see [Event], to the URL
+// in the background. Close it to post the queued events on shutdown.
+type Webhook struct {
+	cfg     WebhookConfig
+	queue   chan Event
+	dropped atomic.Uint64
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+}
+
+// NewWebhook returns the webhook reporter, it panics if the config is invalid.
+func NewWebhook(cfg WebhookConfig) *Webhook {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	w := &Webhook{
+		cfg:   cfg,
+		queue: make(chan Event, cfg.QueueSize),
+		done:  make(chan struct{}),
+	}
+	go w.post()
+	return w
+}
+
+func (w *Webhook) CaptureException(_ context.Context, err error, tags Tags) {
+	if err == nil {
+		return
+	}
+	w.enqueue(Event{
+		Level:     LevelError,
+		Message:   err.Error(),
+		ErrorType: fmt.Sprintf("%T", errorCause(err)),
+		Tags:      tags,
+	})
+}
+
+func (w *Webhook) CaptureMessage(_ context.Context, msg string, tags Tags) {
+	w.enqueue(Event{Level: LevelInfo, Message: msg, Tags: tags})
+}
+
+// Dropped returns the number of the events dropped because the queue was full or the
+// webhook was closed.
+func (w *Webhook) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close stops accepting the events and waits until the queued ones are posted.
+// If ctx is done before that, ctx error is returned.
+func (w *Webhook) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Webhook) enqueue(event Event) {
+	event.Time = time.Now().UTC()
+	event.Environment = w.cfg.Environment
+	event.Release = w.cfg.Release
+	event.Tags = maps.Clone(event.Tags)
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		w.drop(errors.New("report: webhook is closed"))
+		return
+	}
+
+	select {
+	case w.queue <- event:
+	default:
+		w.drop(errors.New("report: webhook queue is full"))
+	}
+}
+
+func (w *Webhook) drop(err error) {
+	w.dropped.Add(1)
+	if w.cfg.OnError != nil {
+		w.cfg.OnError(err)
+	}
+}
+
+func (w *Webhook) post() {
+	defer close(w.done)
+
+	for event := range w.queue {
+		if err := w.send(event); err != nil && w.cfg.OnError != nil {
+			w.cfg.OnError(err)
+		}
+	}
+}
+
+func (w *Webhook) send(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("report: encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("report: new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := w.cfg.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("report: post event: %w", err)
+	}
+	_ = res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("report: post event: unexpected status %d", res.StatusCode)
+	}
+	return nil
+}
+
+// errorCause returns the innermost error of the chain.
+func errorCause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
@@ -0,0 +1,127 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookError struct{}
+
+func (webhookError) Error() string { return "db down" }
+
+func TestWebhook(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []Event
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var event Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	w := NewWebhook(WebhookConfig{
+		URL:         srv.URL,
+		Headers:     map[string]string{"Authorization": "Bearer secret"},
+		Environment: "production",
+		Release:     "1.2.3",
+	})
+
+	ctx := context.Background()
+	w.CaptureException(ctx, fmt.Errorf("handler: %w", webhookError{}), Tags{"pattern": "GET /"})
+	w.CaptureException(ctx, nil, nil)
+	w.CaptureMessage(ctx, "cache cold", nil)
+	require.NoError(t, w.Close(ctx))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, LevelError, events[0].Level)
+	assert.Equal(t, "handler: db down", events[0].Message)
+	assert.Equal(t, "report.webhookError", events[0].ErrorType)
+	assert.Equal(t, Tags{"pattern": "GET /"}, events[0].Tags)
+	assert.Equal(t, "production", events[0].Environment)
+	assert.Equal(t, "1.2.3", events[0].Release)
+	assert.False(t, events[0].Time.IsZero())
+
+	assert.Equal(t, LevelInfo, events[1].Level)
+	assert.Equal(t, "cache cold", events[1].Message)
+
+	// the closed webhook drops the events
+	w.CaptureMessage(ctx, "late", nil)
+	assert.Equal(t, uint64(1), w.Dropped())
+	require.NoError(t, w.Close(ctx))
+}
+
+func TestWebhook_Errors(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	var (
+		mu   sync.Mutex
+		errs []string
+	)
+	w := NewWebhook(WebhookConfig{URL: srv.URL, QueueSize: 1, OnError: func(err error) {
+		mu.Lock()
+		errs = append(errs, err.Error())
+		mu.Unlock()
+	}})
+
+	ctx := context.Background()
+	for range 3 {
+		w.CaptureException(ctx, errors.New("failed"), nil)
+	}
+
+	// at most one event is being posted and one is queued, the rest are dropped
+	assert.Positive(t, w.Dropped())
+	close(release)
+	require.NoError(t, w.Close(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, errs, "report: webhook queue is full")
+	assert.Contains(t, errs, "report: post event: unexpected status 502")
+}
+
+func TestWebhookConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		err  string
+	}{
+		{name: "valid", url: "https://errors.example.com/hook"},
+		{name: "empty", err: "report: webhook URL is required"},
+		{name: "relative", url: "/hook", err: `report: invalid webhook URL "/hook"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := WebhookConfig{URL: tt.url}
+			err := cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+			assert.Panics(t, func() { NewWebhook(cfg) })
+		})
+	}
+}
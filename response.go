@@ -45,26 +45,63 @@ func NewResponse(w http.ResponseWriter) *Response {
 	return &Response{ResponseWriter: w, buffer: bytes.NewBuffer(nil)}
 }
 
+// BytesWritten returns the number of the body bytes written to the wrapped writer,
+// ex. the compressed bytes when the compress middleware is used.
+func (r *Response) BytesWritten() int64 {
+	return r.Size
+}
+
 func (r *Response) Buffer() []byte {
 	return r.buffer.Bytes()
 }
 
-// Before registers a function which is called just before the response is Written.
+// Before registers a function which is called just before the status and headers
+// are written (on the first WriteHeader, Write or Flush), which is the last chance
+// to modify headers and cookies.
 func (r *Response) Before(fn func()) {
 	r.beforeFuncs = append(r.beforeFuncs, fn)
 }
 
-// After registers a function which is called just after the response is Written.
-// If the `Content-Length` is unknown, none of the after function is executed.
+// After registers a function which is called once the response is completed,
+// see [Response.Complete].
 func (r *Response) After(fn func()) {
 	r.afterFuncs = append(r.afterFuncs, fn)
 }
 
+// Complete executes the registered after functions. It is called by the [Router]
+// once the request has been handled; the functions are executed only once.
+func (r *Response) Complete() {
+	fns := r.afterFuncs
+	r.afterFuncs = nil
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func (r *Response) before() {
+	fns := r.beforeFuncs
+	r.beforeFuncs = nil
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
 // WriteHeader sends an HTTP response header with Status code. If WriteHeader is
 // not called explicitly, the first call to Write will trigger an implicit
 // WriteHeader(http.StatusOK). Thus explicit calls to WriteHeader are mainly
 // used to send error codes.
 func (r *Response) WriteHeader(status int) {
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		// informational responses (ex. 103 Early Hints) are sent as is and
+		// may be followed by the final response
+		if !r.Written && !r.Buffering {
+			r.ResponseWriter.WriteHeader(status)
+		}
+		return
+	}
+
 	if r.Written {
 		return
 	}
@@ -78,9 +115,7 @@ func (r *Response) WriteHeader(status int) {
 		return
 	}
 
-	for _, fn := range r.beforeFuncs {
-		fn()
-	}
+	r.before()
 	r.ResponseWriter.WriteHeader(status)
 	r.Written = true
 }
@@ -98,9 +133,6 @@ func (r *Response) Write(b []byte) (n int, err error) {
 
 	n, err = r.ResponseWriter.Write(b)
 	r.Size += int64(n)
-	for _, fn := range r.afterFuncs {
-		fn()
-	}
 	return
 }
 
@@ -116,6 +148,14 @@ func (r *Response) Flush() {
 // FlushError is similar to [Flush] but returns [http.ErrNotSupported]
 // if the wrapped writer doesn't support it.
 func (r *Response) FlushError() error {
+	if !r.Written && !r.Buffering {
+		// the implicit status is sent with the first flush
+		r.before()
+		if r.Status == 0 {
+			r.Status = http.StatusOK
+		}
+	}
+
 	err := http.NewResponseController(r.ResponseWriter).Flush()
 	if err == nil || !errors.Is(err, http.ErrNotSupported) {
 		r.Written = true
@@ -152,6 +192,10 @@ func (r *Response) ReadFrom(reader io.Reader) (n int64, err error) {
 		r.WriteHeader(http.StatusOK)
 	}
 
+	defer func() {
+		r.Size += n
+	}()
+
 	w := r.ResponseWriter
 	for {
 		switch rf := w.(type) {
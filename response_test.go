@@ -201,6 +201,27 @@ func TestResponse_WriteHeader(t *testing.T) {
 
 		assert.True(t, called)
 	})
+
+	t.Run("informational status", func(t *testing.T) {
+		mockRW := httptest.NewRecorder()
+		resp := NewResponse(mockRW)
+
+		called := false
+		resp.Before(func() {
+			called = true
+		})
+
+		resp.WriteHeader(http.StatusEarlyHints)
+
+		assert.False(t, resp.Written)
+		assert.False(t, called)
+		assert.Zero(t, resp.Status)
+
+		resp.WriteHeader(http.StatusCreated)
+
+		assert.True(t, called)
+		assert.Equal(t, http.StatusCreated, resp.Status)
+	})
 }
 
 func TestResponse_Write(t *testing.T) {
@@ -235,18 +256,22 @@ func TestResponse_Write(t *testing.T) {
 		assert.Equal(t, data, resp.Buffer()) // Data is in buffer
 	})
 
-	t.Run("executes after functions", func(t *testing.T) {
+	t.Run("executes after functions on complete", func(t *testing.T) {
 		mockRW := httptest.NewRecorder()
 		resp := NewResponse(mockRW)
 
-		called := false
+		calls := 0
 		resp.After(func() {
-			called = true
+			calls++
 		})
 
 		_, _ = resp.Write([]byte("test"))
+		_, _ = resp.Write([]byte("test"))
+		assert.Equal(t, 0, calls)
 
-		assert.True(t, called)
+		resp.Complete()
+		resp.Complete()
+		assert.Equal(t, 1, calls)
 	})
 
 	t.Run("multiple after functions", func(t *testing.T) {
@@ -259,6 +284,7 @@ func TestResponse_Write(t *testing.T) {
 		resp.After(func() { order = append(order, 3) })
 
 		_, _ = resp.Write([]byte("test"))
+		resp.Complete()
 
 		assert.Equal(t, []int{1, 2, 3}, order)
 	})
@@ -400,6 +426,7 @@ func TestResponse_ReadFrom(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, int64(len(data)), n)
 		assert.Equal(t, data, mockRW.Body.String())
+		assert.Equal(t, int64(len(data)), resp.BytesWritten())
 	})
 
 	t.Run("read from through unwrapper", func(t *testing.T) {
@@ -495,6 +522,8 @@ func TestResponse_ComplexWorkflow(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, len(data), n)
 
+	resp.Complete()
+
 	// Verify state
 	assert.Equal(t, http.StatusCreated, resp.Status)
 	assert.Equal(t, int64(len(data)), resp.Size)
@@ -667,3 +696,38 @@ func TestMustUnwrapResponse(t *testing.T) {
 		})
 	})
 }
+
+func TestResponse_FlushRunsBeforeFuncs(t *testing.T) {
+	resp := NewResponse(httptest.NewRecorder())
+
+	calls := 0
+	resp.Before(func() { calls++ })
+
+	require.NoError(t, resp.FlushError())
+	resp.WriteHeader(http.StatusCreated)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusOK, resp.Status)
+}
+
+func TestRouter_ResponseAfterRunsOnCompletion(t *testing.T) {
+	var order []string
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/", func(e *Event) error {
+		res := MustUnwrapResponse(e.Response())
+		res.Before(func() { order = append(order, "before") })
+		res.After(func() { order = append(order, "after") })
+
+		_, _ = e.Response().Write([]byte("a"))
+		_, _ = e.Response().Write([]byte("b"))
+		order = append(order, "action")
+		return nil
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, []string{"before", "action", "after"}, order)
+}
@@ -0,0 +1,109 @@
+package wo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrRouteNotFound is returned when there is no route of the name, see [Route.Named].
+var ErrRouteNotFound = errors.New("wo: route not found")
+
+// routerContext is the router state shared with the requests through the request context.
+type routerContext struct {
+	envelope   *EnvelopeConfig
+	names      map[string]string
+	renderers  map[string]Renderer
+	xmlDecoder *XMLDecoderOptions
+}
+
+// URL builds the path of the route of the name (see [Route.Named]) registered by the last
+// successful [Router.Build], the path params are given as name and value pairs:
+//
+//	r.GET("/users/{id}/posts/{slug}", showPost).Named("post")
+//	u, err := r.URL("post", "id", "42", "slug", "hello-world") // "/users/42/posts/hello-world"
+func (r *Router[T]) URL(name string, params ...string) (string, error) {
+	return reverse(r.routeTable().names, name, params)
+}
+
+// URL builds the path of the route of the name like [Router.URL].
+func (e *Event) URL(name string, params ...string) (string, error) {
+	return URL(e.Context(), name, params...)
+}
+
+// URL builds the path of the route of the name like [Router.URL] with the router handling the request of ctx.
+func URL(ctx context.Context, name string, params ...string) (string, error) {
+	rc, ok := ctx.Value(ctxRouterKey{}).(*routerContext)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+	}
+	return reverse(rc.names, name, params)
+}
+
+func reverse(names map[string]string, name string, params []string) (string, error) {
+	pattern, ok := names[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("wo: route %q: params must be name and value pairs", name)
+	}
+
+	// strip the method and the host of the pattern
+	path := pattern
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		path = path[i:]
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(path, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(path[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(path[:start])
+		param := path[start+1 : end]
+		path = path[end+1:]
+
+		// the end of the path anchor
+		if param == "$" {
+			continue
+		}
+
+		param, wildcard := strings.CutSuffix(param, "...")
+		value, ok := paramValue(params, param)
+		if !ok {
+			return "", fmt.Errorf("wo: route %q: missing param %q", name, param)
+		}
+
+		if wildcard {
+			segments := strings.Split(value, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+	}
+	b.WriteString(path)
+
+	return b.String(), nil
+}
+
+func paramValue(params []string, name string) (string, bool) {
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i] == name {
+			return params[i+1], true
+		}
+	}
+	return "", false
+}
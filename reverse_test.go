@@ -0,0 +1,119 @@
+package wo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_URL(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	api := router.Group("/api")
+	api.GET("/users/{id}/posts/{slug}", func(e *Event) error { return nil }).Named("post")
+	api.GET("/files/{path...}", func(e *Event) error { return nil }).Named("file")
+	router.GET("example.com/{$}", func(e *Event) error { return nil }).Named("home")
+
+	_, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		route    string
+		params   []string
+		expected string
+		err      string
+	}{
+		{"params", "post", []string{"id", "42", "slug", "hello world"}, "/api/users/42/posts/hello%20world", ""},
+		{"wildcard", "file", []string{"path", "docs/a b.pdf"}, "/api/files/docs/a%20b.pdf", ""},
+		{"anchor and host", "home", nil, "/", ""},
+		{"missing param", "post", []string{"id", "42"}, "", `missing param "slug"`},
+		{"odd params", "post", []string{"id"}, "", "name and value pairs"},
+		{"unknown route", "unknown", nil, "", "route not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := router.URL(tt.route, tt.params...)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, u)
+		})
+	}
+
+	infos := router.Routes()
+	require.Len(t, infos, 3)
+	assert.Equal(t, "post", infos[0].Name)
+}
+
+func TestRouter_DuplicateRouteName(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/a", func(e *Event) error { return nil }).Named("page")
+	router.GET("/b", func(e *Event) error { return nil }).Named("page")
+
+	_, err := router.Build(nil)
+	require.ErrorContains(t, err, `name "page" is already used by "GET /a"`)
+}
+
+func TestRouter_URL_Rebuild(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/a", func(e *Event) error { return nil }).Named("a")
+
+	_, err := router.Build(nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for range 100 {
+			u, err := router.URL("a")
+			assert.NoError(t, err)
+			assert.Equal(t, "/a", u)
+			assert.NotEmpty(t, router.Routes())
+		}
+	})
+	for range 10 {
+		_, err = router.Build(nil)
+		require.NoError(t, err)
+	}
+	wg.Wait()
+
+	// the aborted build keeps the route table of the last one
+	router.GET("/b", func(e *Event) error { return nil }).Named("a")
+	_, err = router.Build(nil)
+	require.Error(t, err)
+
+	u, err := router.URL("a")
+	require.NoError(t, err)
+	assert.Equal(t, "/a", u)
+	assert.Len(t, router.Routes(), 1)
+	assert.Equal(t, []string{"GET /a"}, slices.Collect(router.Patterns()))
+}
+
+func TestEvent_URL(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/users/{id}", func(e *Event) error {
+		u, err := e.URL("user", "id", "7")
+		if err != nil {
+			return err
+		}
+		return e.String(http.StatusOK, u)
+	}).Named("user")
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	assert.Equal(t, "/users/7", rec.Body.String())
+
+	_, err = URL(context.Background(), "user")
+	require.ErrorIs(t, err, ErrRouteNotFound)
+}
@@ -20,17 +20,45 @@ package wo
 // DEALINGS IN THE SOFTWARE.
 // -------------------------------------------------------------------
 
-import "github.com/gowool/hook"
+import (
+	"time"
+
+	"github.com/gowool/hook"
+)
+
+// budgetWriteGrace is the time past the route budget to write the response, see [Route.Budget].
+const budgetWriteGrace = time.Second
 
 type Route[T hook.Resolver] struct {
 	excludedMiddlewares map[string]struct{}
+	stacks              []string
+	budget              time.Duration
 
 	Method      string
 	Path        string
+	Name        string
 	Action      func(T) error
 	Middlewares []*hook.Handler[T]
 }
 
+// Named sets the name of the route, which builds the route URLs by the name,
+// see [Router.URL] and [Event.URL].
+func (route *Route[T]) Named(name string) *Route[T] {
+	route.Name = name
+	return route
+}
+
+// Budget sets the time budget of the route handling, the request context is canceled once it's
+// exceeded. The read and the write deadlines of the connection follow the budget, so they
+// override the read and the write timeouts of the server for the route, ex. to give the export
+// more time, or the login less, the write deadline is a second past the budget, so the error
+// response can still be written. The deadlines of the server timeouts are restored once the
+// route returns. The zero budget removes it.
+func (route *Route[T]) Budget(budget time.Duration) *Route[T] {
+	route.budget = budget
+	return route
+}
+
 // BindFunc registers one or multiple middleware functions to the current route.
 //
 // The registered middleware functions are "anonymous" and with default priority,
@@ -0,0 +1,160 @@
+package wo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RouteRegistration describes where a route pattern was registered.
+type RouteRegistration struct {
+	// Pattern is the full route pattern, ex. "GET /api/users/{id}".
+	Pattern string
+
+	// Groups are the prefixes of the groups chain the route was registered in, from the root group.
+	Groups []string
+}
+
+func (r RouteRegistration) String() string {
+	groups := make([]string, len(r.Groups))
+	for i, prefix := range r.Groups {
+		groups[i] = fmt.Sprintf("%q", prefix)
+	}
+	return fmt.Sprintf("%q (groups: %s)", r.Pattern, strings.Join(groups, " > "))
+}
+
+// RouteConflict is a route registration which can't be served as intended.
+type RouteConflict struct {
+	Route RouteRegistration
+
+	// With is the conflicting registration, nil if the route conflicts with a handler
+	// registered in the mux before the build or the route pattern is invalid.
+	With *RouteRegistration
+
+	Reason string
+}
+
+func (c RouteConflict) String() string {
+	if c.With == nil {
+		return fmt.Sprintf("%s: %s", c.Route, c.Reason)
+	}
+	return fmt.Sprintf("%s conflicts with %s: %s", c.Route, c.With, c.Reason)
+}
+
+// RouteConflictError is returned by [Router.Build] when some routes are duplicated (the patterns
+// differing only in the wildcard names included) or shadow each other, ie. match some requests
+// while none is more specific (see the [http.ServeMux] precedence rules).
+type RouteConflictError struct {
+	Conflicts []RouteConflict
+}
+
+func (e *RouteConflictError) Error() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "router: %d route conflict(s):", len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		b.WriteString("\n\t")
+		b.WriteString(c.String())
+	}
+	return b.String()
+}
+
+// routeRegistry registers the route patterns in the matcher, collecting the conflicts instead of panicking.
+type routeRegistry struct {
+	matcher    Matcher
+	newMatcher func() Matcher
+	registered []RouteRegistration
+	shapes     map[string]int
+	conflicts  []RouteConflict
+}
+
+func newRouteRegistry(matcher Matcher, newMatcher func() Matcher) *routeRegistry {
+	return &routeRegistry{matcher: matcher, newMatcher: newMatcher, shapes: make(map[string]int)}
+}
+
+func (reg *routeRegistry) register(route RouteRegistration, handler http.HandlerFunc) bool {
+	shape := patternShape(route.Pattern)
+	if i, ok := reg.shapes[shape]; ok {
+		reg.conflict(route, &reg.registered[i], "duplicate pattern, both match the same requests")
+		return false
+	}
+
+	if err := tryHandle(reg.matcher, route.Pattern, handler); err != "" {
+		// find the conflicting registration for a descriptive error
+		for i, other := range reg.registered {
+			m := reg.newMatcher()
+			m.Handle(other.Pattern, handler)
+			if reason := tryHandle(m, route.Pattern, handler); reason != "" {
+				reg.conflict(route, &reg.registered[i], reason)
+				return false
+			}
+		}
+		reg.conflict(route, nil, err)
+		return false
+	}
+
+	reg.shapes[shape] = len(reg.registered)
+	reg.registered = append(reg.registered, route)
+	return true
+}
+
+func (reg *routeRegistry) conflict(route RouteRegistration, with *RouteRegistration, reason string) {
+	if with != nil {
+		w := *with
+		with = &w
+	}
+	reg.conflicts = append(reg.conflicts, RouteConflict{Route: route, With: with, Reason: reason})
+}
+
+func (reg *routeRegistry) err() error {
+	if len(reg.conflicts) == 0 {
+		return nil
+	}
+	return &RouteConflictError{Conflicts: reg.conflicts}
+}
+
+// tryHandle registers the handler in the mux and returns the reason of the registration panic, if any.
+func tryHandle(m Matcher, pattern string, handler http.HandlerFunc) (reason string) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			reason = fmt.Sprint(rec)
+			// skip the "registered at" source locations of the mux message
+			if _, after, ok := strings.Cut(reason, "):\n"); ok {
+				reason = after
+			}
+			reason = strings.Join(strings.Fields(reason), " ")
+		}
+	}()
+
+	m.Handle(pattern, handler)
+	return ""
+}
+
+// patternShape returns the pattern with the wildcard names removed, so the patterns
+// which differ only in the wildcard names are equal.
+func patternShape(pattern string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(pattern[:start])
+		switch name := pattern[start+1 : end]; {
+		case name == "$":
+			b.WriteString("{$}")
+		case strings.HasSuffix(name, "..."):
+			b.WriteString("{...}")
+		default:
+			b.WriteString("{}")
+		}
+		pattern = pattern[end+1:]
+	}
+	b.WriteString(pattern)
+	return b.String()
+}
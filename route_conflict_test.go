@@ -0,0 +1,167 @@
+package wo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterBuildConflicts(t *testing.T) {
+	action := func(e *Event) error { return nil }
+
+	tests := []struct {
+		name      string
+		setup     func(r *Router[*Event])
+		conflicts []RouteConflict
+	}{
+		{
+			name: "no conflicts",
+			setup: func(r *Router[*Event]) {
+				r.GET("/users/{id}", action)
+				r.GET("/users/new", action)
+				r.POST("/users/{id}", action)
+			},
+		},
+		{
+			name: "duplicate pattern",
+			setup: func(r *Router[*Event]) {
+				r.Group("/api").GET("/users", action)
+				r.GET("/api/users", action)
+			},
+			conflicts: []RouteConflict{
+				{
+					Route:  RouteRegistration{Pattern: "GET /api/users", Groups: []string{""}},
+					With:   &RouteRegistration{Pattern: "GET /api/users", Groups: []string{"", "/api"}},
+					Reason: "duplicate pattern, both match the same requests",
+				},
+			},
+		},
+		{
+			name: "duplicate pattern with different wildcard names",
+			setup: func(r *Router[*Event]) {
+				r.GET("/users/{id}", action)
+				r.Group("/users").GET("/{name}", action)
+			},
+			conflicts: []RouteConflict{
+				{
+					Route:  RouteRegistration{Pattern: "GET /users/{name}", Groups: []string{"", "/users"}},
+					With:   &RouteRegistration{Pattern: "GET /users/{id}", Groups: []string{""}},
+					Reason: "duplicate pattern, both match the same requests",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New[*Event](eventFactory, errorHandler)
+			tt.setup(router)
+
+			h, err := router.Build(nil)
+			if tt.conflicts == nil {
+				require.NoError(t, err)
+				assert.NotNil(t, h)
+				return
+			}
+
+			var conflictErr *RouteConflictError
+			require.True(t, errors.As(err, &conflictErr))
+			assert.Nil(t, h)
+			assert.Equal(t, tt.conflicts, conflictErr.Conflicts)
+		})
+	}
+}
+
+func TestRouterBuildShadowedPatterns(t *testing.T) {
+	action := func(e *Event) error { return nil }
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/users/{id}/posts", action)
+	v1 := router.Group("/v1")
+	v1.GET("/ok", action)
+	router.GET("/{name}/new/posts", action)
+
+	_, err := router.Build(nil)
+
+	var conflictErr *RouteConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	require.Len(t, conflictErr.Conflicts, 1)
+
+	conflict := conflictErr.Conflicts[0]
+	assert.Equal(t, "GET /{name}/new/posts", conflict.Route.Pattern)
+	require.NotNil(t, conflict.With)
+	assert.Equal(t, "GET /users/{id}/posts", conflict.With.Pattern)
+	assert.Contains(t, conflict.Reason, "neither is more specific")
+	assert.NotContains(t, conflict.Reason, "registered at")
+
+	assert.Contains(t, err.Error(), `"GET /{name}/new/posts" (groups: "") conflicts with "GET /users/{id}/posts" (groups: "")`)
+	// the failed build doesn't replace the route table
+	assert.Empty(t, collectPatterns(router))
+}
+
+func TestRouterBuildConflictKeepsTable(t *testing.T) {
+	action := func(e *Event) error { return nil }
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/users/{id}/posts", action).Named("posts")
+
+	_, err := router.Build(nil)
+	require.NoError(t, err)
+
+	router.GET("/{name}/new/posts", action)
+	_, err = router.Build(nil)
+
+	var conflictErr *RouteConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, []string{"GET /users/{id}/posts"}, collectPatterns(router))
+
+	url, err := router.URL("posts", "id", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "/users/1/posts", url)
+}
+
+func TestRouterBuildConflictWithMuxHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(http.ResponseWriter, *http.Request) {})
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/health", func(e *Event) error { return nil })
+
+	_, err := router.Build(mux)
+
+	var conflictErr *RouteConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	require.Len(t, conflictErr.Conflicts, 1)
+	assert.Nil(t, conflictErr.Conflicts[0].With)
+	assert.NotEmpty(t, conflictErr.Conflicts[0].Reason)
+}
+
+func collectPatterns(r *Router[*Event]) []string {
+	var patterns []string
+	for p := range r.Patterns() {
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+func TestPatternShape(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"/users", "/users"},
+		{"GET /users/{id}", "GET /users/{}"},
+		{"/files/{path...}", "/files/{...}"},
+		{"/{$}", "/{$}"},
+		{"example.com/{a}/{b}/x", "example.com/{}/{}/x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.expected, patternShape(tt.pattern))
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package wo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RouteFormat is the format of the route table exported by [Router.ExportRoutes].
+type RouteFormat string
+
+const (
+	RouteFormatJSON     RouteFormat = "json"
+	RouteFormatMarkdown RouteFormat = "markdown"
+	RouteFormatMermaid  RouteFormat = "mermaid"
+)
+
+type exportedRoute struct {
+	Method      string   `json:"method,omitempty"`
+	Path        string   `json:"path"`
+	Pattern     string   `json:"pattern"`
+	Name        string   `json:"name,omitempty"`
+	Groups      []string `json:"groups"`
+	Stacks      []string `json:"stacks,omitempty"`
+	Middlewares []string `json:"middlewares,omitempty"`
+	Handler     string   `json:"handler"`
+	Budget      string   `json:"budget,omitempty"`
+}
+
+// ExportRoutes writes the route table of the last successful [Router.Build] in the format, ex. for the API
+// docs or the security reviews: the methods, the patterns, the middleware stacks and the middlewares
+// protecting each route and the function names of the handlers.
+//
+//	if _, err := r.Build(nil); err != nil {
+//		return err
+//	}
+//	return r.ExportRoutes(os.Stdout, wo.RouteFormatMarkdown)
+func (r *Router[T]) ExportRoutes(w io.Writer, format RouteFormat) error {
+	table := r.routeTable()
+
+	routes := make([]exportedRoute, 0, len(table.routes))
+	for _, route := range table.routes {
+		method, path := splitPattern(route.Pattern)
+		var budget string
+		if route.Budget > 0 {
+			budget = route.Budget.String()
+		}
+		routes = append(routes, exportedRoute{
+			Method:      method,
+			Path:        path,
+			Pattern:     route.Pattern,
+			Name:        route.Name,
+			Groups:      route.Groups,
+			Stacks:      route.Stacks,
+			Middlewares: route.Middlewares,
+			Handler:     route.Handler,
+			Budget:      budget,
+		})
+	}
+
+	switch format {
+	case RouteFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(routes)
+	case RouteFormatMarkdown:
+		return exportMarkdown(w, routes)
+	case RouteFormatMermaid:
+		return exportMermaid(w, routes)
+	default:
+		return fmt.Errorf("wo: unsupported route format %q", format)
+	}
+}
+
+func exportMarkdown(w io.Writer, routes []exportedRoute) error {
+	var b strings.Builder
+	b.WriteString("| Method | Path | Name | Stacks | Middlewares | Handler |\n")
+	b.WriteString("|--------|------|------|--------|-------------|---------|\n")
+
+	cell := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+	}
+	cells := func(items []string) string {
+		if len(items) == 0 {
+			return "-"
+		}
+		quoted := make([]string, len(items))
+		for i, item := range items {
+			quoted[i] = cell(item)
+		}
+		return strings.Join(quoted, ", ")
+	}
+
+	for _, route := range routes {
+		method := route.Method
+		if method == "" {
+			method = "ANY"
+		}
+		_, _ = fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			method, cell(route.Path), cell(route.Name), cells(route.Stacks), cells(route.Middlewares), cell(route.Handler))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func exportMermaid(w io.Writer, routes []exportedRoute) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	label := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+	}
+
+	for i, route := range routes {
+		_, _ = fmt.Fprintf(&b, "    r%d[%s]", i, label(route.Pattern))
+		for j, m := range route.Middlewares {
+			_, _ = fmt.Fprintf(&b, " --> r%dm%d(%s)", i, j, label(m))
+		}
+		_, _ = fmt.Fprintf(&b, " --> r%dh[[%s]]\n", i, label(route.Handler))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// splitPattern splits the route pattern into the method and the path, the host included.
+func splitPattern(pattern string) (method, path string) {
+	if m, p, ok := strings.Cut(pattern, " "); ok {
+		return m, strings.TrimLeft(p, " \t")
+	}
+	return "", pattern
+}
+
+// funcName returns the name of the function, ex. "github.com/acme/app.(*Handlers).List-fm".
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package wo
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportedAction(e *Event) error { return nil }
+
+func exportRouter(t *testing.T) *Router[*Event] {
+	t.Helper()
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.Pre(&hook.Handler[*Event]{ID: "recover", Func: func(e *Event) error { return e.Next() }})
+	router.Stack("authenticated", &hook.Handler[*Event]{ID: "auth", Func: func(e *Event) error { return e.Next() }})
+
+	api := router.Group("/api").UseStack("authenticated")
+	api.GET("/users/{id}", exportedAction).Named("user")
+	api.Any("/legacy|old", exportedAction)
+
+	_, err := router.Build(nil)
+	require.NoError(t, err)
+	return router
+}
+
+func TestRouter_ExportRoutes(t *testing.T) {
+	router := exportRouter(t)
+	handler := "github.com/gowool/wo.exportedAction"
+
+	tests := []struct {
+		format   RouteFormat
+		expected string
+	}{
+		{
+			format: RouteFormatMarkdown,
+			expected: "| Method | Path | Name | Stacks | Middlewares | Handler |\n" +
+				"|--------|------|------|--------|-------------|---------|\n" +
+				"| GET | `/api/users/{id}` | `user` | `authenticated` | `recover`, `auth` | `" + handler + "` |\n" +
+				"| ANY | `/api/legacy\\|old` | - | `authenticated` | `recover`, `auth` | `" + handler + "` |\n",
+		},
+		{
+			format: RouteFormatMermaid,
+			expected: "flowchart LR\n" +
+				`    r0["GET /api/users/{id}"] --> r0m0("recover") --> r0m1("auth") --> r0h[["` + handler + `"]]` + "\n" +
+				`    r1["/api/legacy|old"] --> r1m0("recover") --> r1m1("auth") --> r1h[["` + handler + `"]]` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, router.ExportRoutes(&buf, tt.format))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, router.ExportRoutes(&buf, RouteFormatJSON))
+
+		var routes []exportedRoute
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &routes))
+		require.Len(t, routes, 2)
+		assert.Equal(t, exportedRoute{
+			Method:      "GET",
+			Path:        "/api/users/{id}",
+			Pattern:     "GET /api/users/{id}",
+			Name:        "user",
+			Groups:      []string{"", "/api"},
+			Stacks:      []string{"authenticated"},
+			Middlewares: []string{"recover", "auth"},
+			Handler:     handler,
+		}, routes[0])
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		assert.EqualError(t, router.ExportRoutes(&bytes.Buffer{}, "yaml"), `wo: unsupported route format "yaml"`)
+	})
+}
+
+func TestRouteInfo_AnonymousMiddlewares(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.PreFunc(exportedAction)
+	router.Pre(&hook.Handler[*Event]{Func: tagMiddleware("pre")})
+	router.BindFunc(tagMiddleware("a"))
+	router.GET("/", exportedAction)
+
+	_, err := router.Build(nil)
+	require.NoError(t, err)
+
+	routes := router.Routes()
+	require.Len(t, routes, 1)
+	assert.Equal(t, []string{
+		"github.com/gowool/wo.exportedAction",
+		"github.com/gowool/wo.tagMiddleware.func1",
+		"github.com/gowool/wo.tagMiddleware.func1",
+	}, routes[0].Middlewares)
+}
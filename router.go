@@ -23,19 +23,48 @@ package wo
 import (
 	"context"
 	"errors"
+	"fmt"
 	"iter"
 	"maps"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gowool/hook"
 )
 
 type (
-	ctxEventKey struct{}
-	ctxErrorKey struct{}
+	ctxRequestKey  struct{}
+	ctxRouterKey   struct{}
+	ctxIncomingKey struct{}
 )
 
+// requestContext carries the state of the request in a single context value,
+// instead of a context.WithValue chain allocated per request.
+type requestContext[T Resolver] struct {
+	context.Context
+
+	router *routerContext
+	req    *http.Request
+	event  T
+	err    error
+	chain  chainErrors
+}
+
+func (c *requestContext[T]) Value(key any) any {
+	switch key.(type) {
+	case ctxRequestKey:
+		return c
+	case ctxRouterKey:
+		return c.router
+	case ctxIncomingKey:
+		return c.req
+	case ctxChainErrorsKey:
+		return &c.chain
+	}
+	return c.Context.Value(key)
+}
+
 type Resolver interface {
 	hook.Resolver
 
@@ -54,13 +83,64 @@ type EventCleanupFunc func()
 // Optionally return a cleanup function that will be invoked right after the route execution.
 type EventFactoryFunc[T Resolver] func(w http.ResponseWriter, r *http.Request) (T, EventCleanupFunc)
 
+// PooledEventFactory returns the event factory reusing the events of type E from a pool
+// of its own, so the events are not allocated per request:
+//
+//	r := wo.New(wo.PooledEventFactory[wo.Event](), errorHandler)
+//
+// The event is put back to the pool once the request is completed, so it must not be
+// retained, ex. by the goroutines outliving the request.
+func PooledEventFactory[E any, T interface {
+	*E
+	Resolver
+	Reset(w http.ResponseWriter, r *http.Request)
+}]() EventFactoryFunc[T] {
+	type pooled struct {
+		event   T
+		cleanup EventCleanupFunc
+	}
+
+	var pool sync.Pool
+	pool.New = func() any {
+		p := &pooled{event: T(new(E))}
+		// the cleanup is bound once, so it is not allocated per request
+		p.cleanup = func() {
+			p.event.Reset(nil, nil)
+			pool.Put(p)
+		}
+		return p
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) (T, EventCleanupFunc) {
+		p := pool.Get().(*pooled)
+		p.event.Reset(w, r)
+		return p.event, p.cleanup
+	}
+}
+
 type Router[T Resolver] struct {
 	*RouterGroup[T]
 
-	patterns     map[string]struct{}
+	middlewares  map[string]*hook.Handler[T]
+	stacks       map[string][]*hook.Handler[T]
+	constraints  map[string]MiddlewareConstraints
+	preChain     middlewareChain[T]
+	envelope     *EnvelopeConfig
+	renderers    map[string]Renderer
+	xmlDecoder   *XMLDecoderOptions
+	paths        *PathConfig
+	acme         *ACMEChallenges
+	table        *routeTable
+	tableMu      sync.RWMutex
 	eventFactory EventFactoryFunc[T]
 	errorHandler HTTPErrorHandler[T]
 	preHook      *hook.Hook[T]
+	onRequest    *hook.Hook[T]
+	onResponse   *hook.Hook[T]
+	onComplete   *hook.Hook[T]
+	onError      *hook.Hook[*ErrorEvent[T]]
+	onPanic      *hook.Hook[*PanicEvent[T]]
+	newMatcher   func() Matcher
 	responsePool sync.Pool
 }
 
@@ -68,42 +148,155 @@ func New[T Resolver](eventFactory EventFactoryFunc[T], errorHandler HTTPErrorHan
 	return &Router[T]{
 		RouterGroup:  new(RouterGroup[T]),
 		preHook:      new(hook.Hook[T]),
-		patterns:     make(map[string]struct{}),
+		onRequest:    new(hook.Hook[T]),
+		onResponse:   new(hook.Hook[T]),
+		onComplete:   new(hook.Hook[T]),
+		onError:      new(hook.Hook[*ErrorEvent[T]]),
+		onPanic:      new(hook.Hook[*PanicEvent[T]]),
+		table:        newRouteTable(),
+		middlewares:  make(map[string]*hook.Handler[T]),
+		stacks:       make(map[string][]*hook.Handler[T]),
+		constraints:  make(map[string]MiddlewareConstraints),
 		eventFactory: eventFactory,
 		errorHandler: errorHandler,
+		newMatcher:   newServeMux,
 		responsePool: sync.Pool{
 			New: func() any { return NewResponse(nil) },
 		},
 	}
 }
 
+// Patterns returns the route patterns registered by the last successful [Router.Build].
 func (r *Router[T]) Patterns() iter.Seq[string] {
-	return maps.Keys(r.patterns)
+	return maps.Keys(r.routeTable().patterns)
+}
+
+// routeTable is the route table of a [Router.Build]. It's replaced as a whole by the next
+// build and never modified, so it's read concurrently with the builds.
+type routeTable struct {
+	patterns map[string]struct{}
+	names    map[string]string
+	routes   []RouteInfo
+
+	// maxBodySize is the largest body limit of the routes, see RouterGroup.SetMaxRequestBodySize,
+	// it's applied before the pre middlewares unless some route is unlimited.
+	maxBodySize int64
+	unlimited   bool
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{
+		patterns: make(map[string]struct{}),
+		names:    make(map[string]string),
+	}
+}
+
+// routeTable returns the route table of the last successful [Router.Build].
+func (r *Router[T]) routeTable() *routeTable {
+	r.tableMu.RLock()
+	defer r.tableMu.RUnlock()
+
+	return r.table
 }
 
 func (r *Router[T]) PreFunc(middlewareFuncs ...func(e T) error) {
 	for _, middlewareFunc := range middlewareFuncs {
-		r.preHook.BindFunc(middlewareFunc)
+		r.Pre(&hook.Handler[T]{Func: middlewareFunc})
 	}
 }
 
 func (r *Router[T]) Pre(middlewares ...*hook.Handler[T]) {
 	for _, middleware := range middlewares {
-		r.preHook.Bind(middleware)
+		if middleware.ID != "" {
+			r.preHook.Bind(attributed(middleware))
+			r.preChain = r.preChain.add(middleware)
+			continue
+		}
+
+		// the chain keeps the anonymous middleware without the generated ID,
+		// so it's exported by the function name, see middlewareChain.names
+		r.preChain = r.preChain.add(&hook.Handler[T]{Func: middleware.Func, Priority: middleware.Priority})
+		middleware.ID = r.preHook.Bind(attributed(middleware))
 	}
 }
 
 // Build constructs a new [http.Handler] instance from the current router configurations.
+// The routes are registered in the mux, if given, otherwise in a new matcher, see [Router.SetMatcher].
+//
+// It returns a [RouteConflictError] listing all duplicated or conflicting route registrations
+// and a [MiddlewareOrderError] listing the violations of the middleware constraints.
 func (r *Router[T]) Build(mux *http.ServeMux) (http.Handler, error) {
-	if mux == nil {
-		mux = http.NewServeMux()
+	newMatcher := r.newMatcher
+	if newMatcher == nil {
+		newMatcher = newServeMux
+	}
+
+	var m Matcher
+	if mux != nil {
+		m = mux
+	} else {
+		m = newMatcher()
 	}
 
-	if err := r.build(mux, r.RouterGroup, nil); err != nil {
+	// the route table is built aside and swapped once the build succeeds, so the readers,
+	// ex. Router.URL, never see a partial one and keep the table of the serving handler on the error.
+	table := newRouteTable()
+	reg := newRouteRegistry(m, newMatcher)
+	order := newMiddlewareOrder(r.constraints)
+	if err := r.build(table, reg, order, r.RouterGroup, nil); err != nil {
+		return nil, err
+	}
+	if err := reg.err(); err != nil {
 		return nil, err
 	}
+	if err := order.err(); err != nil {
+		return nil, err
+	}
+
+	r.tableMu.Lock()
+	r.table = table
+	r.tableMu.Unlock()
+
+	rc := &routerContext{
+		envelope:   r.envelope,
+		names:      table.names,
+		renderers:  maps.Clone(r.renderers),
+		xmlDecoder: r.xmlDecoder,
+	}
+	paths := r.paths
+	acme := r.acme
+
+	var maxBodySize int64
+	if !table.unlimited {
+		maxBodySize = table.maxBodySize
+	}
+
+	var subtrees [][]string
+	if paths != nil && paths.TrailingSlash != TrailingSlashStrict {
+		subtrees = subtreeRoots(maps.Keys(table.patterns))
+	}
+
+	// the chains are compiled once, the empty hooks are skipped on the request path
+	serve := func(e T) error {
+		m.ServeHTTP(e.Response(), e.Request())
+
+		if c, ok := e.Request().Context().Value(ctxRequestKey{}).(*requestContext[T]); ok {
+			return c.err
+		}
+		return nil
+	}
+	pre := func(e T) error {
+		if r.preHook.Length() == 0 {
+			return serve(e)
+		}
+		return r.preHook.Trigger(e, serve)
+	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if acme != nil && acme.serveHTTP01(w, req) {
+			return
+		}
+
 		// wrap the response to add write and status tracking
 		resp := r.responsePool.Get().(*Response)
 		resp.Reset(w)
@@ -113,45 +306,107 @@ func (r *Router[T]) Build(mux *http.ServeMux) (http.Handler, error) {
 			r.responsePool.Put(resp)
 		}()
 
+		// a single context value carries the router, the event and the errors of the request
+		c := &requestContext[T]{Context: req.Context(), router: rc}
+		req = req.WithContext(c)
+		c.req = req
+
+		// the route limits are applied after the routing, the pre middlewares read the body
+		// within the largest one
+		var tooLarge error
+		if maxBodySize > 0 && req.Body != nil && req.Body != http.NoBody {
+			if req.ContentLength > maxBodySize {
+				tooLarge = &http.MaxBytesError{Limit: maxBodySize}
+			} else {
+				req.Body = http.MaxBytesReader(w, req.Body, maxBodySize)
+			}
+		}
+
+		var redirect *RedirectError
+		if paths != nil {
+			req, redirect = paths.normalize(req, subtrees)
+		}
+
 		event, cleanupFunc := r.eventFactory(resp, req)
 		if cleanupFunc != nil {
 			defer cleanupFunc()
 		}
+		c.event = event
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec != http.ErrAbortHandler {
+					r.handlePanic(event, rec)
+				}
+				panic(rec)
+			}
+		}()
+
+		if r.onResponse.Length() > 0 {
+			resp.Before(func() {
+				_ = r.onResponse.Trigger(event)
+			})
+		}
 
-		if err := r.preHook.Trigger(event, func(e T) error {
-			ctx := context.WithValue(e.Request().Context(), ctxEventKey{}, e)
-			e.SetRequest(e.Request().WithContext(ctx))
+		var err error
+		if redirect != nil {
+			err = redirect
+		} else if tooLarge != nil {
+			err = tooLarge
+		} else if r.onRequest.Length() == 0 {
+			err = pre(event)
+		} else {
+			err = r.onRequest.Trigger(event, pre)
+		}
+		if err != nil {
+			r.handleError(event, c.chain.join(err))
+		}
 
-			mux.ServeHTTP(e.Response(), e.Request())
+		// the event factory may wrap resp into its own Response
+		if res, err := UnwrapResponse(event.Response()); err == nil && res != resp {
+			res.Complete()
+		}
+		resp.Complete()
 
-			err, _ := e.Request().Context().Value(ctxErrorKey{}).(error)
-			return err
-		}); err != nil && r.errorHandler != nil {
-			r.errorHandler(event, err)
+		if r.onComplete.Length() > 0 {
+			_ = r.onComplete.Trigger(event)
 		}
 	}), nil
 }
 
-func (r *Router[T]) build(mux *http.ServeMux, group *RouterGroup[T], parents []*RouterGroup[T]) error {
+func (r *Router[T]) build(table *routeTable, reg *routeRegistry, order *middlewareOrder, group *RouterGroup[T], parents []*RouterGroup[T]) error {
 	for _, child := range group.children {
 		switch v := child.(type) {
 		case *RouterGroup[T]:
-			if err := r.build(mux, v, append(parents, group)); err != nil {
+			if err := r.build(table, reg, order, v, append(parents, group)); err != nil {
 				return err
 			}
 		case *Route[T]:
 			routeHook := new(hook.Hook[T])
 
-			var pattern string
+			var (
+				pattern string
+				stacks  []string
+				chain   middlewareChain[T]
+			)
+			bind := func(h *hook.Handler[T]) {
+				routeHook.Bind(attributed(h))
+				chain = chain.add(h)
+			}
 
 			// add parent groups middlewares
 			for _, p := range parents {
 				pattern += p.Prefix
-				for _, h := range p.Middlewares {
+				handlers, err := r.stackMiddlewares(p.stacks)
+				if err != nil {
+					return fmt.Errorf("group %q: %w", p.Prefix, err)
+				}
+				stacks = append(stacks, p.stacks...)
+				for _, h := range append(handlers, p.Middlewares...) {
 					if _, ok := p.excludedMiddlewares[h.ID]; !ok {
 						if _, ok = group.excludedMiddlewares[h.ID]; !ok {
 							if _, ok = v.excludedMiddlewares[h.ID]; !ok {
-								routeHook.Bind(h)
+								bind(h)
 							}
 						}
 					}
@@ -160,19 +415,29 @@ func (r *Router[T]) build(mux *http.ServeMux, group *RouterGroup[T], parents []*
 
 			// add current groups middlewares
 			pattern += group.Prefix
-			for _, h := range group.Middlewares {
+			handlers, err := r.stackMiddlewares(group.stacks)
+			if err != nil {
+				return fmt.Errorf("group %q: %w", group.Prefix, err)
+			}
+			stacks = append(stacks, group.stacks...)
+			for _, h := range append(handlers, group.Middlewares...) {
 				if _, ok := group.excludedMiddlewares[h.ID]; !ok {
 					if _, ok = v.excludedMiddlewares[h.ID]; !ok {
-						routeHook.Bind(h)
+						bind(h)
 					}
 				}
 			}
 
 			// add current route middlewares
 			pattern += v.Path
-			for _, h := range v.Middlewares {
+			handlers, err = r.stackMiddlewares(v.stacks)
+			if err != nil {
+				return fmt.Errorf("route %q: %w", v.Path, err)
+			}
+			stacks = append(stacks, v.stacks...)
+			for _, h := range append(handlers, v.Middlewares...) {
 				if _, ok := v.excludedMiddlewares[h.ID]; !ok {
-					routeHook.Bind(h)
+					bind(h)
 				}
 			}
 
@@ -180,16 +445,95 @@ func (r *Router[T]) build(mux *http.ServeMux, group *RouterGroup[T], parents []*
 				pattern = v.Method + " " + pattern
 			}
 
-			r.patterns[pattern] = struct{}{}
+			registration := RouteRegistration{Pattern: pattern, Groups: make([]string, 0, len(parents)+1)}
+			for _, p := range parents {
+				registration.Groups = append(registration.Groups, p.Prefix)
+			}
+			registration.Groups = append(registration.Groups, group.Prefix)
+
+			action := func(e T) error {
+				err := v.Action(e)
+				if err != nil {
+					recordChainError(e, pattern, err)
+				}
+				return err
+			}
+			compiled := routeHook.Length() > 0
+
+			// the innermost group limit applies
+			var maxBodySize int64
+			for _, p := range append(parents, group) {
+				if p.maxBodySize != 0 {
+					maxBodySize = p.maxBodySize
+				}
+			}
+			if maxBodySize > 0 {
+				table.maxBodySize = max(table.maxBodySize, maxBodySize)
+			} else {
+				table.unlimited = true
+			}
+
+			if !reg.register(registration, func(w http.ResponseWriter, req *http.Request) {
+				c := req.Context().Value(ctxRequestKey{}).(*requestContext[T])
+
+				if maxBodySize > 0 && req.Body != nil && req.Body != http.NoBody {
+					if req.ContentLength > maxBodySize {
+						c.err = &http.MaxBytesError{Limit: maxBodySize}
+						return
+					}
+					req.Body = http.MaxBytesReader(w, req.Body, maxBodySize)
+				}
+
+				if v.budget > 0 {
+					readDeadline, writeDeadline := serverDeadlines(req)
+
+					ctx, cancel := context.WithTimeout(req.Context(), v.budget)
+					defer cancel()
+					req = req.WithContext(ctx)
+
+					deadline, _ := ctx.Deadline()
+					rc := http.NewResponseController(w)
+					_ = rc.SetReadDeadline(deadline)
+					_ = rc.SetWriteDeadline(deadline.Add(budgetWriteGrace))
+					defer func() {
+						// the deadlines outlive the route, so the ones of the server timeouts are restored,
+						// the expired read deadline is kept, so the server doesn't wait for the rest of the body
+						if ctx.Err() == nil {
+							_ = rc.SetReadDeadline(readDeadline)
+						}
+						_ = rc.SetWriteDeadline(writeDeadline)
+					}()
+				}
 
-			mux.HandleFunc(pattern, func(_ http.ResponseWriter, req *http.Request) {
-				event := req.Context().Value(ctxEventKey{}).(T)
+				event := c.event
 				event.SetRequest(req)
 
-				if err := routeHook.Trigger(event, v.Action); err != nil {
-					ctx := context.WithValue(req.Context(), ctxErrorKey{}, err)
-					event.SetRequest(event.Request().WithContext(ctx))
+				if compiled {
+					c.err = routeHook.Trigger(event, action)
+				} else {
+					c.err = action(event)
 				}
+			}) {
+				continue
+			}
+
+			order.check(pattern, append(r.preChain.ids(), chain.ids()...))
+
+			if v.Name != "" {
+				if other, ok := table.names[v.Name]; ok {
+					return fmt.Errorf("route %q: name %q is already used by %q", pattern, v.Name, other)
+				}
+				table.names[v.Name] = pattern
+			}
+
+			table.patterns[pattern] = struct{}{}
+			table.routes = append(table.routes, RouteInfo{
+				RouteRegistration: registration,
+				Name:              v.Name,
+				Stacks:            stacks,
+				Middlewares:       append(r.preChain.names(), chain.names()...),
+				Handler:           funcName(v.Action),
+				Budget:            v.budget,
 			})
 		default:
 			return errors.New("invalid RouterGroup item type")
@@ -197,3 +541,22 @@ func (r *Router[T]) build(mux *http.ServeMux, group *RouterGroup[T], parents []*
 	}
 	return nil
 }
+
+// serverDeadlines returns the read and the write deadlines of the server timeouts of the request,
+// the zero ones if the timeouts aren't set. The server sets them once the request is read, which
+// time isn't known, so they are counted from now.
+func serverDeadlines(r *http.Request) (read, write time.Time) {
+	srv, ok := r.Context().Value(http.ServerContextKey).(*http.Server)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if srv.ReadTimeout > 0 {
+		read = now.Add(srv.ReadTimeout)
+	}
+	if srv.WriteTimeout > 0 {
+		write = now.Add(srv.WriteTimeout)
+	}
+	return
+}
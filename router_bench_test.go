@@ -0,0 +1,168 @@
+package wo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// discardWriter is the allocation free response writer of the benchmarks.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w *discardWriter) Header() http.Header         { return w.header }
+func (w *discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *discardWriter) WriteHeader(int)             {}
+
+func benchRouter(tb testing.TB, register func(r *Router[*Event])) http.Handler {
+	tb.Helper()
+
+	r := New(PooledEventFactory[Event](), errorHandler)
+	register(r)
+
+	h, err := r.Build(nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return h
+}
+
+func benchServe(b *testing.B, h http.Handler, req *http.Request) {
+	b.Helper()
+
+	w := &discardWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		h.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkRouter_StaticGET(b *testing.B) {
+	h := benchRouter(b, func(r *Router[*Event]) {
+		r.GET("/ping", func(e *Event) error {
+			e.Response().WriteHeader(http.StatusNoContent)
+			return nil
+		})
+	})
+
+	benchServe(b, h, httptest.NewRequest(http.MethodGet, "/ping", nil))
+}
+
+func BenchmarkRouter_ParamGET(b *testing.B) {
+	h := benchRouter(b, func(r *Router[*Event]) {
+		r.GET("/users/{id}/posts/{post}", func(e *Event) error {
+			_ = e.Param("id")
+			_ = e.Param("post")
+			e.Response().WriteHeader(http.StatusNoContent)
+			return nil
+		})
+	})
+
+	benchServe(b, h, httptest.NewRequest(http.MethodGet, "/users/1/posts/2", nil))
+}
+
+func BenchmarkRouter_Middlewares(b *testing.B) {
+	h := benchRouter(b, func(r *Router[*Event]) {
+		next := func(e *Event) error { return e.Next() }
+		r.BindFunc(next, next)
+
+		g := r.Group("/api")
+		g.BindFunc(next)
+		g.GET("/ping", func(e *Event) error {
+			e.Response().WriteHeader(http.StatusNoContent)
+			return nil
+		})
+	})
+
+	benchServe(b, h, httptest.NewRequest(http.MethodGet, "/api/ping", nil))
+}
+
+func BenchmarkRouter_Error(b *testing.B) {
+	h := benchRouter(b, func(r *Router[*Event]) {
+		r.GET("/fail", func(*Event) error {
+			return ErrNotFound
+		})
+	})
+
+	benchServe(b, h, httptest.NewRequest(http.MethodGet, "/fail", nil))
+}
+
+func BenchmarkRouter_NotFound(b *testing.B) {
+	h := benchRouter(b, func(r *Router[*Event]) {
+		r.GET("/ping", func(*Event) error { return nil })
+	})
+
+	benchServe(b, h, httptest.NewRequest(http.MethodGet, "/missing", nil))
+}
+
+func BenchmarkRouter_LargeTable(b *testing.B) {
+	matchers := []struct {
+		name       string
+		newMatcher func() Matcher
+	}{
+		{name: "servemux", newMatcher: newServeMux},
+		{name: "radix", newMatcher: func() Matcher { return NewRadixMatcher() }},
+	}
+
+	for _, m := range matchers {
+		b.Run(m.name, func(b *testing.B) {
+			h := benchRouter(b, func(r *Router[*Event]) {
+				r.SetMatcher(m.newMatcher)
+				for i := range 500 {
+					r.GET(fmt.Sprintf("/resource%d/{id}/items/{item}", i), func(e *Event) error {
+						e.Response().WriteHeader(http.StatusNoContent)
+						return nil
+					})
+				}
+			})
+
+			benchServe(b, h, httptest.NewRequest(http.MethodGet, "/resource499/1/items/2", nil))
+		})
+	}
+}
+
+func BenchmarkRouter_Parallel(b *testing.B) {
+	h := benchRouter(b, func(r *Router[*Event]) {
+		r.GET("/ping", func(e *Event) error {
+			e.Response().WriteHeader(http.StatusNoContent)
+			return nil
+		})
+	})
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		w := &discardWriter{header: make(http.Header)}
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		for pb.Next() {
+			h.ServeHTTP(w, req)
+		}
+	})
+}
+
+func TestRouter_Allocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the pools are not reliable with the race detector")
+	}
+
+	h := benchRouter(t, func(r *Router[*Event]) {
+		r.GET("/ping", func(e *Event) error {
+			e.Response().WriteHeader(http.StatusNoContent)
+			return nil
+		})
+	})
+
+	w := &discardWriter{header: make(http.Header)}
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+
+	// the request context and the request carrying it are the only allocations
+	allocs := testing.AllocsPerRun(100, func() {
+		h.ServeHTTP(w, req)
+	})
+	if allocs > 2 {
+		t.Errorf("expected at most 2 allocations per request, got %v", allocs)
+	}
+}
@@ -1,11 +1,15 @@
 package wo
 
 import (
+	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/gowool/hook"
 	"github.com/stretchr/testify/assert"
@@ -27,12 +31,12 @@ func TestRouterCreation(t *testing.T) {
 	require.NotNil(t, router)
 	assert.NotNil(t, router.RouterGroup)
 	assert.NotNil(t, router.preHook)
-	assert.NotNil(t, router.patterns)
+	assert.NotNil(t, router.table)
 	assert.NotNil(t, router.eventFactory)
 	assert.NotNil(t, router.errorHandler)
 
 	// Check that patterns map is empty
-	assert.Empty(t, router.patterns)
+	assert.Empty(t, router.table.patterns)
 
 	// Check response pool
 	resp := router.responsePool.Get().(*Response)
@@ -533,3 +537,221 @@ func TestRouterPatternGeneration(t *testing.T) {
 	// Check that we have some patterns
 	assert.NotEmpty(t, patterns, "Should have generated some patterns")
 }
+
+func TestIncomingRequest(t *testing.T) {
+	_, ok := IncomingRequest(t.Context())
+	assert.False(t, ok)
+
+	router := New[*Event](eventFactory, errorHandler)
+	router.GET("/users", func(e *Event) error {
+		e.SetRequest(e.Request().Clone(e.Context()))
+
+		in, ok := IncomingRequest(e.Context())
+		require.True(t, ok)
+		assert.Equal(t, "req-1", in.Header.Get(HeaderXRequestID))
+		assert.Equal(t, "/users", in.URL.Path)
+		return nil
+	})
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(HeaderXRequestID, "req-1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestRouterMaxRequestBodySize(t *testing.T) {
+	router := New[*Event](eventFactory, ErrorHandler[*Event](nil, nil, nil))
+	router.SetMaxRequestBodySize(4)
+
+	read := func(e *Event) error {
+		b, err := io.ReadAll(e.Request().Body)
+		if err != nil {
+			return err
+		}
+		return e.String(http.StatusOK, string(b))
+	}
+
+	router.POST("/root", read)
+	router.Group("/uploads").SetMaxRequestBodySize(8).POST("/", read)
+	unlimited := router.Group("/import").SetMaxRequestBodySize(-1)
+	unlimited.POST("/", read)
+	unlimited.Group("/inherited").POST("/", read)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{name: "within the router limit", path: "/root", body: "1234", expectedStatus: http.StatusOK},
+		{name: "content length over the router limit", path: "/root", body: "12345", expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "chunked over the router limit", path: "/root", body: "12345", chunked: true, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "within the group limit", path: "/uploads/", body: "12345678", expectedStatus: http.StatusOK},
+		{name: "over the group limit", path: "/uploads/", body: "123456789", chunked: true, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "unlimited group", path: "/import/", body: "123456789", expectedStatus: http.StatusOK},
+		{name: "inherited from the parent group", path: "/import/inherited/", body: "123456789", chunked: true, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, io.NopCloser(strings.NewReader(tt.body)))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouterMaxRequestBodySize_Pre(t *testing.T) {
+	router := New[*Event](eventFactory, ErrorHandler[*Event](nil, nil, nil))
+	router.SetMaxRequestBodySize(4)
+
+	var preCalled bool
+	router.PreFunc(func(e *Event) error {
+		preCalled = true
+		if _, err := io.ReadAll(e.Request().Body); err != nil {
+			return err
+		}
+		return e.NoContent(http.StatusNoContent)
+	})
+	router.POST("/root", func(e *Event) error { return nil })
+	router.Group("/uploads").SetMaxRequestBodySize(8).POST("/", func(e *Event) error { return nil })
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		body           string
+		chunked        bool
+		expectedStatus int
+		expectedPre    bool
+	}{
+		{name: "within the largest limit", body: "12345678", expectedStatus: http.StatusNoContent, expectedPre: true},
+		{name: "content length over the largest limit", body: "123456789", expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "chunked over the largest limit", body: "123456789", chunked: true, expectedStatus: http.StatusRequestEntityTooLarge, expectedPre: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preCalled = false
+
+			req := httptest.NewRequest(http.MethodPost, "/root", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedPre, preCalled)
+		})
+	}
+}
+
+func TestRouterRouteBudget(t *testing.T) {
+	router := New[*Event](eventFactory, ErrorHandler[*Event](nil, nil, nil))
+
+	deadline := func(e *Event) error {
+		d, ok := e.Request().Context().Deadline()
+		if !ok {
+			return e.NoContent(http.StatusOK)
+		}
+		return e.String(http.StatusOK, time.Until(d).Round(time.Second).String())
+	}
+
+	router.GET("/export", deadline).Budget(time.Minute)
+	router.GET("/login", deadline)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+	assert.Equal(t, "1m0s", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	assert.Empty(t, rec.Body.String())
+
+	routes := router.Routes()
+	require.Len(t, routes, 2)
+	assert.Equal(t, time.Minute, routes[0].Budget)
+	assert.Zero(t, routes[1].Budget)
+}
+
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func (w *deadlineRecorder) SetReadDeadline(deadline time.Time) error {
+	w.readDeadline = deadline
+	return nil
+}
+
+func (w *deadlineRecorder) SetWriteDeadline(deadline time.Time) error {
+	w.writeDeadline = deadline
+	return nil
+}
+
+func TestRouterRouteBudget_Deadlines(t *testing.T) {
+	router := New[*Event](eventFactory, ErrorHandler[*Event](nil, nil, nil))
+
+	var w *deadlineRecorder
+	router.GET("/export", func(e *Event) error {
+		assert.WithinDuration(t, time.Now().Add(time.Minute), w.readDeadline, time.Second)
+		assert.WithinDuration(t, time.Now().Add(time.Minute+budgetWriteGrace), w.writeDeadline, time.Second)
+		return e.NoContent(http.StatusOK)
+	}).Budget(time.Minute)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		server        *http.Server
+		readDeadline  time.Duration
+		writeDeadline time.Duration
+	}{
+		{name: "no server timeouts", server: &http.Server{}},
+		{name: "server timeouts", server: &http.Server{ReadTimeout: 10 * time.Second, WriteTimeout: 30 * time.Second}, readDeadline: 10 * time.Second, writeDeadline: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w = &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+			req := httptest.NewRequest(http.MethodGet, "/export", nil)
+			req = req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, tt.server))
+
+			h.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			// the deadlines of the server timeouts are restored
+			if tt.readDeadline == 0 {
+				assert.True(t, w.readDeadline.IsZero())
+			} else {
+				assert.WithinDuration(t, time.Now().Add(tt.readDeadline), w.readDeadline, time.Second)
+			}
+			if tt.writeDeadline == 0 {
+				assert.True(t, w.writeDeadline.IsZero())
+			} else {
+				assert.WithinDuration(t, time.Now().Add(tt.writeDeadline), w.writeDeadline, time.Second)
+			}
+		})
+	}
+}
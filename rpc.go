@@ -0,0 +1,159 @@
+package wo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// Validator is implemented by the request types which validate themselves once bound,
+// ex. using github.com/invopop/validation.
+type Validator interface {
+	Validate() error
+}
+
+// StatusCoder is implemented by the response types which define the response status code.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Handle adapts the typed handler to a route action. The request is bound from the path
+// values (`param` tag), the query parameters (`query` tag) and the body (see [Event.BindBody]),
+// then validated if it implements [Validator]; binding and validation failures result in
+// [ErrUnprocessableEntity]. The response is negotiated (JSON or XML) according to the Accept
+// header with the status 200, or the one of [StatusCoder]; a nil response results in 204.
+// The response is not encoded if the handler has already written it using the event.
+//
+//	g.POST("/users/{id}", wo.Handle(func(e *wo.Event, req UpdateUser) (*User, error) {
+//		...
+//	}))
+func Handle[Req, Resp any](fn func(e *Event, req Req) (Resp, error)) func(*Event) error {
+	if fn == nil {
+		panic("Handle: the provided handler is nil")
+	}
+
+	return func(e *Event) error {
+		var req Req
+		if err := bindRequest(e, &req); err != nil {
+			return err
+		}
+
+		resp, err := fn(e, req)
+		if err != nil {
+			return err
+		}
+
+		if res, err := UnwrapResponse(e.Response()); err == nil && res.Written {
+			return nil
+		}
+
+		return writeResponse(e, resp)
+	}
+}
+
+// RPC adapts the RPC-like handler to a route action, see [Handle] for the binding and
+// the response encoding.
+//
+//	g.POST("/users/{id}", wo.RPC(svc.UpdateUser))
+func RPC[Req, Resp any](fn func(context.Context, Req) (Resp, error)) func(*Event) error {
+	if fn == nil {
+		panic("RPC: the provided handler is nil")
+	}
+
+	return Handle(func(e *Event, req Req) (Resp, error) {
+		return fn(e.Context(), req)
+	})
+}
+
+// bindRequest binds the path values, the query parameters and the body into dst and validates it.
+func bindRequest(e *Event, dst any) error {
+	if err := e.BindPathParams(dst); err != nil {
+		return unprocessable(err)
+	}
+	if err := e.BindQueryParams(dst); err != nil {
+		return unprocessable(err)
+	}
+	if err := e.BindBody(dst); err != nil {
+		return unprocessable(err)
+	}
+
+	if v, ok := dst.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return ErrUnprocessableEntity.WithInternal(err).SetMessage(err)
+		}
+	}
+	return nil
+}
+
+// unprocessable converts the bad request binding errors to [ErrUnprocessableEntity],
+// the other errors (ex. unsupported media type) are kept.
+func unprocessable(err error) error {
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		return ErrUnprocessableEntity.WithInternal(err)
+	}
+	if he.Status != http.StatusBadRequest {
+		return err
+	}
+
+	result := ErrUnprocessableEntity.WithInternal(he.Internal)
+	if he.Message != http.StatusText(http.StatusBadRequest) {
+		result.Message = he.Message
+	}
+	return result
+}
+
+// bindPathValues binds the path values of the request into the struct fields tagged with `param`.
+func bindPathValues(r *http.Request, dst any) error {
+	typ := reflect.TypeOf(dst)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	data := make(map[string][]string)
+	collectPathValues(r, typ, data)
+
+	return BindData(dst, data, "param", nil)
+}
+
+func collectPathValues(r *http.Request, typ reflect.Type, data map[string][]string) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+
+		if name := field.Tag.Get("param"); name != "" && name != "-" {
+			if value := r.PathValue(name); value != "" {
+				data[name] = []string{value}
+			}
+			continue
+		}
+
+		// the untagged structs may contain tagged fields, like BindData handles them
+		ft := field.Type
+		if field.Anonymous && ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			collectPathValues(r, ft, data)
+		}
+	}
+}
+
+func writeResponse(e *Event, resp any) error {
+	if resp == nil {
+		return e.NoContent(http.StatusNoContent)
+	}
+	if v := reflect.ValueOf(resp); (v.Kind() == reflect.Pointer || v.Kind() == reflect.Map || v.Kind() == reflect.Slice) && v.IsNil() {
+		return e.NoContent(http.StatusNoContent)
+	}
+
+	status := http.StatusOK
+	if sc, ok := resp.(StatusCoder); ok {
+		status = sc.StatusCode()
+	}
+
+	return e.Negotiate(status, resp, MIMEApplicationJSON, MIMEApplicationXML)
+}
@@ -0,0 +1,171 @@
+package wo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rpcRequest struct {
+	ID     int    `param:"id"`
+	Fields string `query:"fields"`
+	Name   string `json:"name"`
+}
+
+func (r rpcRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name: cannot be blank")
+	}
+	return nil
+}
+
+type rpcResponse struct {
+	ID     int    `json:"id"`
+	Fields string `json:"fields"`
+	Name   string `json:"name"`
+}
+
+type rpcCreated struct {
+	ID int `json:"id"`
+}
+
+func (rpcCreated) StatusCode() int {
+	return http.StatusCreated
+}
+
+func serveRPC(t *testing.T, method, pattern, target, body string, action func(*Event) error) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var handled error
+	router := New[*Event](eventFactory, func(_ *Event, err error) { handled = err })
+	router.Route(method, pattern, action)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if handled != nil {
+		he := AsHTTPError(handled)
+		require.NotNil(t, he, handled.Error())
+		rec.Code = he.Status
+	}
+	return rec
+}
+
+func TestRPC(t *testing.T) {
+	update := RPC(func(_ context.Context, req rpcRequest) (*rpcResponse, error) {
+		return &rpcResponse{ID: req.ID, Fields: req.Fields, Name: req.Name}, nil
+	})
+
+	t.Run("binds path, query and body", func(t *testing.T) {
+		rec := serveRPC(t, http.MethodPut, "/users/{id}", "/users/7?fields=name", `{"name":"John"}`, update)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"id":7,"fields":"name","name":"John"}`, rec.Body.String())
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		rec := serveRPC(t, http.MethodPut, "/users/{id}", "/users/7", `{"name":""}`, update)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("bind error", func(t *testing.T) {
+		rec := serveRPC(t, http.MethodPut, "/users/{id}", "/users/abc", `{"name":"John"}`, update)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+		rec = serveRPC(t, http.MethodPut, "/users/{id}", "/users/7", `{"name":`, update)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("status coder", func(t *testing.T) {
+		create := RPC(func(context.Context, struct{}) (rpcCreated, error) {
+			return rpcCreated{ID: 1}, nil
+		})
+
+		rec := serveRPC(t, http.MethodPost, "/users", "/users", "", create)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"id":1}`, rec.Body.String())
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		remove := RPC(func(context.Context, struct{}) (*rpcResponse, error) {
+			return nil, nil
+		})
+
+		rec := serveRPC(t, http.MethodDelete, "/users/{id}", "/users/1", "", remove)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		fail := RPC(func(context.Context, struct{}) (any, error) {
+			return nil, ErrNotFound
+		})
+
+		rec := serveRPC(t, http.MethodGet, "/users/{id}", "/users/1", "", fail)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	assert.Panics(t, func() {
+		RPC[struct{}, struct{}](nil)
+	})
+}
+
+func TestHandle(t *testing.T) {
+	t.Run("event is accessible", func(t *testing.T) {
+		action := Handle(func(e *Event, req rpcRequest) (rpcResponse, error) {
+			e.Response().Header().Set("X-User", e.Param("id"))
+			return rpcResponse{ID: req.ID, Name: req.Name}, nil
+		})
+
+		rec := serveRPC(t, http.MethodPost, "/users/{id}", "/users/3", `{"name":"Jane"}`, action)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("X-User"))
+		assert.JSONEq(t, `{"id":3,"fields":"","name":"Jane"}`, rec.Body.String())
+	})
+
+	t.Run("response written by the handler", func(t *testing.T) {
+		action := Handle(func(e *Event, _ struct{}) (any, error) {
+			return "ignored", e.String(http.StatusAccepted, "accepted")
+		})
+
+		rec := serveRPC(t, http.MethodGet, "/", "/", "", action)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "accepted", rec.Body.String())
+	})
+
+	t.Run("xml negotiation", func(t *testing.T) {
+		router := New[*Event](eventFactory, errorHandler)
+		router.GET("/", Handle(func(*Event, struct{}) (rpcCreated, error) {
+			return rpcCreated{ID: 1}, nil
+		}))
+
+		h, err := router.Build(nil)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderAccept, MIMEApplicationXML)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Header().Get(HeaderContentType), MIMEApplicationXML)
+	})
+
+	assert.Panics(t, func() {
+		Handle[struct{}, struct{}](nil)
+	})
+}
@@ -0,0 +1,160 @@
+package serializer
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// HALItems is the relation of the embedded items of the top-level collection, see [HAL].
+const HALItems = "items"
+
+// halMaxDepth is the maximum nesting of the embedded resources, it stops the cyclic ones.
+const halMaxDepth = 32
+
+// HALLink is the HAL link object.
+type HALLink struct {
+	Href string `json:"href"`
+}
+
+// HAL returns the HAL document of the struct, the pointer to it, or the slice of them, which
+// is embedded under the [HALItems] relation. The fields are annotated with the hal tag:
+//
+//	`hal:"link,self"`         the link, the field is a URL string or a slice of them
+//	`hal:"embedded,author"`   the embedded resource (struct) or resources (slice)
+//	`hal:"-"`                 skipped
+//
+// The other fields are the resource properties named and omitted per the json tag.
+// The embedded resources nested deeper than 32 levels, ex. the cyclic ones, are an error.
+func HAL(data any) (any, error) {
+	v, ok := indirect(reflect.ValueOf(data))
+	switch {
+	case !ok:
+		return nil, nil
+	case isCollection(v):
+		items, err := halCollection(v, 0)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"_embedded": map[string]any{HALItems: items}}, nil
+	default:
+		return halResource(v, 0)
+	}
+}
+
+func halCollection(v reflect.Value, depth int) ([]map[string]any, error) {
+	items := make([]map[string]any, 0, v.Len())
+	for i := range v.Len() {
+		item, ok := indirect(v.Index(i))
+		if !ok {
+			continue
+		}
+		doc, err := halResource(item, depth)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, doc)
+	}
+	return items, nil
+}
+
+func halResource(v reflect.Value, depth int) (map[string]any, error) {
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("serializer: unsupported resource type %s", v.Type())
+	}
+	if depth > halMaxDepth {
+		return nil, fmt.Errorf("serializer: %s: embedded resources are nested deeper than %d levels", v.Type(), halMaxDepth)
+	}
+
+	var (
+		doc      = make(map[string]any)
+		links    map[string]any
+		embedded map[string]any
+	)
+
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue
+		}
+
+		value, ok := f.Tag.Lookup("hal")
+		if !ok {
+			name, omitempty := jsonName(f)
+			if name == "" || (omitempty && fv.IsZero()) {
+				continue
+			}
+			doc[name] = fv.Interface()
+			continue
+		}
+
+		t := parseTag(value)
+		switch t.kind {
+		case "-":
+		case "link":
+			link, ok := halLink(fv)
+			if !ok {
+				continue
+			}
+			if links == nil {
+				links = make(map[string]any)
+			}
+			links[t.name] = link
+		case "embedded":
+			ev, ok := indirect(fv)
+			if !ok {
+				continue
+			}
+
+			var res any
+			if isCollection(ev) {
+				res, err = halCollection(ev, depth+1)
+			} else {
+				res, err = halResource(ev, depth+1)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if embedded == nil {
+				embedded = make(map[string]any)
+			}
+			embedded[t.name] = res
+		default:
+			return nil, fmt.Errorf("serializer: %s.%s: unknown hal tag %q", v.Type(), f.Name, t.kind)
+		}
+	}
+
+	if links != nil {
+		doc["_links"] = links
+	}
+	if embedded != nil {
+		doc["_embedded"] = embedded
+	}
+	return doc, nil
+}
+
+// halLink returns the link object of the URL string or the link objects of the URL strings,
+// it reports false for the empty ones.
+func halLink(fv reflect.Value) (any, bool) {
+	switch {
+	case fv.Kind() == reflect.String:
+		if fv.String() == "" {
+			return nil, false
+		}
+		return HALLink{Href: fv.String()}, true
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+		if fv.Len() == 0 {
+			return nil, false
+		}
+		out := make([]HALLink, 0, fv.Len())
+		for i := range fv.Len() {
+			out = append(out, HALLink{Href: fv.Index(i).String()})
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,59 @@
+package serializer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHAL(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, HALRenderer.Render(&buf, testArticle()))
+
+	assert.JSONEq(t, `{
+		"id": "1",
+		"title": "JSON:API paints my bikeshed!",
+		"_links": {"self": {"href": "/articles/1"}, "tags": [{"href": "/tags/go"}]},
+		"_embedded": {
+			"author": {"ID": 9, "name": "Dan", "_links": {"self": {"href": "/people/9"}}},
+			"comments": [
+				{"id": "5", "body": "First!", "_embedded": {"author": {"ID": 2, "name": "Ann"}}},
+				{"id": "12", "body": "I like XML better", "_embedded": {"author": {"ID": 9, "name": "Dan", "_links": {"self": {"href": "/people/9"}}}}}
+			]
+		}
+	}`, buf.String())
+}
+
+func TestHAL_Collection(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, HALRenderer.Render(&buf, []*person{{ID: 1, Name: "Ann"}, nil}))
+
+	assert.JSONEq(t, `{"_embedded": {"items": [{"ID": 1, "name": "Ann"}]}}`, buf.String())
+}
+
+func TestHAL_Invalid(t *testing.T) {
+	type unknownTag struct {
+		Name string `hal:"property"`
+	}
+
+	_, err := HAL(unknownTag{})
+	require.EqualError(t, err, `serializer: serializer.unknownTag.Name: unknown hal tag "property"`)
+
+	_, err = HAL(42)
+	require.EqualError(t, err, "serializer: unsupported resource type int")
+}
+
+func TestHAL_Cyclic(t *testing.T) {
+	type node struct {
+		Name string `json:"name"`
+		Next *node  `hal:"embedded,next"`
+	}
+
+	n := &node{Name: "a"}
+	n.Next = n
+
+	_, err := HAL(n)
+	require.ErrorContains(t, err, "nested deeper than 32 levels")
+}
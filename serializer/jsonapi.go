@@ -0,0 +1,318 @@
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/gowool/wo"
+)
+
+// Document is the JSON:API top-level document.
+type Document struct {
+	// Data is the primary data: a *Resource, a []*Resource, or null.
+	Data     any            `json:"data,omitempty"`
+	Included []*Resource    `json:"included,omitempty"`
+	Errors   []*Error       `json:"errors,omitempty"`
+	Meta     map[string]any `json:"meta,omitempty"`
+}
+
+// Resource is the JSON:API resource object.
+type Resource struct {
+	Type          string                   `json:"type"`
+	ID            string                   `json:"id,omitempty"`
+	Attributes    map[string]any           `json:"attributes,omitempty"`
+	Relationships map[string]*Relationship `json:"relationships,omitempty"`
+	Links         map[string]string        `json:"links,omitempty"`
+}
+
+// Relationship is the JSON:API relationship object.
+type Relationship struct {
+	// Data is the resource linkage: a *Identifier, a []*Identifier, or null.
+	Data any `json:"data"`
+}
+
+// Identifier is the JSON:API resource identifier object.
+type Identifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Error is the JSON:API error object.
+type Error struct {
+	ID     string         `json:"id,omitempty"`
+	Status string         `json:"status,omitempty"`
+	Code   string         `json:"code,omitempty"`
+	Title  string         `json:"title,omitempty"`
+	Detail string         `json:"detail,omitempty"`
+	Source *ErrorSource   `json:"source,omitempty"`
+	Meta   map[string]any `json:"meta,omitempty"`
+}
+
+// ErrorSource is the reference to the source of the JSON:API error.
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+var jsonNull = json.RawMessage("null")
+
+// JSONAPI returns the JSON:API document of the struct, the pointer to it, or the slice of them.
+// The fields are annotated with the jsonapi tag:
+//
+//	`jsonapi:"primary,articles"`         the resource ID and type, required
+//	`jsonapi:"attr,title[,omitempty]"`   the attribute, the name defaults to the JSON name
+//	`jsonapi:"relation,author[,omitempty]"` the to-one (struct) or to-many (slice) relationship,
+//	                                     the related resources are added to the included ones
+//	`jsonapi:"link,self"`                the link of the resource, the field is a URL string
+//
+// The fields without the tag are skipped. The *Document is returned as is.
+func JSONAPI(data any) (*Document, error) {
+	if doc, ok := data.(*Document); ok {
+		return doc, nil
+	}
+
+	b := &jsonapiBuilder{seen: make(map[Identifier]struct{})}
+
+	v, ok := indirect(reflect.ValueOf(data))
+	switch {
+	case !ok:
+		return &Document{Data: jsonNull}, nil
+	case isCollection(v):
+		resources := make([]*Resource, 0, v.Len())
+		for i := range v.Len() {
+			item, ok := indirect(v.Index(i))
+			if !ok {
+				continue
+			}
+			res, err := b.primary(item)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, res)
+		}
+		if err := b.include(); err != nil {
+			return nil, err
+		}
+		return &Document{Data: resources, Included: b.included}, nil
+	default:
+		res, err := b.primary(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.include(); err != nil {
+			return nil, err
+		}
+		return &Document{Data: res, Included: b.included}, nil
+	}
+}
+
+// JSONAPIErrors returns the JSON:API document of the errors, the status, the message and the code
+// are taken from [wo.HTTPError], the other errors are reported as the internal server errors
+// without the details.
+func JSONAPIErrors(errs ...error) *Document {
+	doc := &Document{Errors: make([]*Error, 0, len(errs))}
+	for _, err := range errs {
+		var e *Error
+		if errors.As(err, &e) {
+			doc.Errors = append(doc.Errors, e)
+			continue
+		}
+
+		status, detail, code := http.StatusInternalServerError, "", ""
+		if he := wo.AsHTTPError(err); he != nil {
+			status, code = he.Status, he.Code
+			if he.Message != nil {
+				detail = fmt.Sprint(he.Message)
+			}
+		}
+
+		title := http.StatusText(status)
+		if detail == title {
+			detail = ""
+		}
+		doc.Errors = append(doc.Errors, &Error{Status: strconv.Itoa(status), Code: code, Title: title, Detail: detail})
+	}
+	return doc
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return e.Title + ": " + e.Detail
+	}
+	return e.Title
+}
+
+type jsonapiBuilder struct {
+	seen     map[Identifier]struct{}
+	pending  []reflect.Value
+	included []*Resource
+}
+
+func (b *jsonapiBuilder) primary(v reflect.Value) (*Resource, error) {
+	res, err := b.resource(v)
+	if err != nil {
+		return nil, err
+	}
+	b.seen[Identifier{Type: res.Type, ID: res.ID}] = struct{}{}
+	return res, nil
+}
+
+// include adds the pending related resources, and the ones related to them, to the included
+// resources once.
+func (b *jsonapiBuilder) include() error {
+	for len(b.pending) > 0 {
+		v := b.pending[0]
+		b.pending = b.pending[1:]
+
+		id, err := identifier(v)
+		if err != nil {
+			return err
+		}
+		if _, ok := b.seen[*id]; ok {
+			continue
+		}
+		b.seen[*id] = struct{}{}
+
+		res, err := b.resource(v)
+		if err != nil {
+			return err
+		}
+		b.included = append(b.included, res)
+	}
+	return nil
+}
+
+func (b *jsonapiBuilder) resource(v reflect.Value) (*Resource, error) {
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("serializer: unsupported resource type %s", v.Type())
+	}
+
+	res := new(Resource)
+	primary := false
+
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		value, ok := f.Tag.Lookup("jsonapi")
+		if !ok || !f.IsExported() {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(f.Index)
+		if err != nil {
+			continue
+		}
+
+		t := parseTag(value)
+		switch t.kind {
+		case "primary":
+			if res.ID, err = formatID(fv); err != nil {
+				return nil, err
+			}
+			res.Type, primary = t.name, true
+		case "attr":
+			if t.omitempty && fv.IsZero() {
+				continue
+			}
+			name := t.name
+			if name == "" {
+				if name, _ = jsonName(f); name == "" {
+					continue
+				}
+			}
+			if res.Attributes == nil {
+				res.Attributes = make(map[string]any)
+			}
+			res.Attributes[name] = fv.Interface()
+		case "relation":
+			rel, err := b.relationship(fv)
+			if err != nil {
+				return nil, err
+			}
+			if rel == nil {
+				if t.omitempty {
+					continue
+				}
+				rel = &Relationship{Data: nil}
+			}
+			if res.Relationships == nil {
+				res.Relationships = make(map[string]*Relationship)
+			}
+			res.Relationships[t.name] = rel
+		case "link":
+			if fv.Kind() != reflect.String || fv.String() == "" {
+				continue
+			}
+			if res.Links == nil {
+				res.Links = make(map[string]string)
+			}
+			res.Links[t.name] = fv.String()
+		default:
+			return nil, fmt.Errorf("serializer: %s.%s: unknown jsonapi tag %q", v.Type(), f.Name, t.kind)
+		}
+	}
+
+	if !primary {
+		return nil, fmt.Errorf("serializer: %s has no primary field", v.Type())
+	}
+	return res, nil
+}
+
+// relationship returns the resource linkage of the related resources and queues them for
+// the inclusion, it's nil for the nil to-one relationship.
+func (b *jsonapiBuilder) relationship(fv reflect.Value) (*Relationship, error) {
+	v, ok := indirect(fv)
+	if !ok {
+		return nil, nil
+	}
+
+	if !isCollection(v) {
+		id, err := identifier(v)
+		if err != nil {
+			return nil, err
+		}
+		b.pending = append(b.pending, v)
+		return &Relationship{Data: id}, nil
+	}
+
+	ids := make([]*Identifier, 0, v.Len())
+	for i := range v.Len() {
+		item, ok := indirect(v.Index(i))
+		if !ok {
+			continue
+		}
+		id, err := identifier(item)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+		b.pending = append(b.pending, item)
+	}
+	return &Relationship{Data: ids}, nil
+}
+
+// identifier returns the identifier of the resource from its primary field.
+func identifier(v reflect.Value) (*Identifier, error) {
+	if v.Kind() == reflect.Struct {
+		for _, f := range reflect.VisibleFields(v.Type()) {
+			value, ok := f.Tag.Lookup("jsonapi")
+			if !ok || !f.IsExported() {
+				continue
+			}
+			if t := parseTag(value); t.kind == "primary" {
+				fv, err := v.FieldByIndexErr(f.Index)
+				if err != nil {
+					break
+				}
+				id, err := formatID(fv)
+				if err != nil {
+					return nil, err
+				}
+				return &Identifier{Type: t.name, ID: id}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("serializer: %s has no primary field", v.Type())
+}
@@ -0,0 +1,111 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestJSONAPI(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, JSONAPIRenderer.Render(&buf, testArticle()))
+
+	assert.JSONEq(t, `{
+		"data": {
+			"type": "articles",
+			"id": "1",
+			"attributes": {"title": "JSON:API paints my bikeshed!"},
+			"relationships": {
+				"author": {"data": {"type": "people", "id": "9"}},
+				"comments": {"data": [{"type": "comments", "id": "5"}, {"type": "comments", "id": "12"}]}
+			},
+			"links": {"self": "/articles/1"}
+		},
+		"included": [
+			{"type": "people", "id": "9", "attributes": {"name": "Dan"}, "links": {"self": "/people/9"}},
+			{
+				"type": "comments", "id": "5", "attributes": {"body": "First!"},
+				"relationships": {"author": {"data": {"type": "people", "id": "2"}}}
+			},
+			{
+				"type": "comments", "id": "12", "attributes": {"body": "I like XML better"},
+				"relationships": {"author": {"data": {"type": "people", "id": "9"}}}
+			},
+			{"type": "people", "id": "2", "attributes": {"name": "Ann"}}
+		]
+	}`, buf.String())
+}
+
+func TestJSONAPI_Collection(t *testing.T) {
+	doc, err := JSONAPI([]person{{ID: 1, Name: "Ann"}, {ID: 2, Name: "Dan"}})
+	require.NoError(t, err)
+
+	resources, ok := doc.Data.([]*Resource)
+	require.True(t, ok)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "2", resources[1].ID)
+	assert.Empty(t, doc.Included)
+}
+
+func TestJSONAPI_NullRelationship(t *testing.T) {
+	doc, err := JSONAPI(&comment{ID: "1", Body: "anonymous"})
+	require.NoError(t, err)
+
+	res := doc.Data.(*Resource)
+	require.Contains(t, res.Relationships, "author")
+	assert.Nil(t, res.Relationships["author"].Data)
+}
+
+func TestJSONAPI_Null(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, JSONAPIRenderer.Render(&buf, (*article)(nil)))
+	assert.JSONEq(t, `{"data": null}`, buf.String())
+}
+
+func TestJSONAPI_Invalid(t *testing.T) {
+	type noPrimary struct {
+		Name string `jsonapi:"attr,name"`
+	}
+	type unknownTag struct {
+		ID   string `jsonapi:"primary,things"`
+		Name string `jsonapi:"attribute,name"`
+	}
+
+	_, err := JSONAPI(noPrimary{Name: "x"})
+	require.EqualError(t, err, "serializer: serializer.noPrimary has no primary field")
+
+	_, err = JSONAPI(unknownTag{ID: "1"})
+	require.EqualError(t, err, `serializer: serializer.unknownTag.Name: unknown jsonapi tag "attribute"`)
+
+	_, err = JSONAPI("text")
+	require.EqualError(t, err, "serializer: unsupported resource type string")
+}
+
+func TestJSONAPIErrors(t *testing.T) {
+	custom := &Error{Status: "422", Title: "Invalid Attribute", Source: &ErrorSource{Pointer: "/data/attributes/title"}}
+
+	doc := JSONAPIErrors(
+		wo.NewHTTPError(http.StatusNotFound, "article not found").WithCode("article_not_found"),
+		wo.ErrForbidden,
+		errors.New("database is down"),
+		custom,
+	)
+
+	assert.Nil(t, doc.Data)
+	assert.Equal(t, []*Error{
+		{Status: "404", Code: "article_not_found", Title: "Not Found", Detail: "article not found"},
+		{Status: "403", Title: "Forbidden"},
+		{Status: "500", Title: "Internal Server Error"},
+		custom,
+	}, doc.Errors)
+
+	doc2, err := JSONAPI(doc)
+	require.NoError(t, err)
+	assert.Same(t, doc, doc2)
+}
@@ -0,0 +1,140 @@
+// Package serializer produces the JSON:API (https://jsonapi.org) and the HAL
+// (https://datatracker.ietf.org/doc/html/draft-kelly-json-hal) documents from the annotated
+// structs. Register the renderers and let [wo.Event.Negotiate] pick the media type per the
+// Accept header:
+//
+//	type Article struct {
+//		ID     string  `jsonapi:"primary,articles"`
+//		Title  string  `jsonapi:"attr,title" json:"title"`
+//		Author *Person `jsonapi:"relation,author" hal:"embedded,author"`
+//		Self   string  `hal:"link,self"`
+//	}
+//
+//	serializer.Register(router)
+//
+//	router.GET("/articles/{id}", func(e *wo.Event) error {
+//		return e.Negotiate(http.StatusOK, article, serializer.MIMEJSONAPI, serializer.MIMEHAL, wo.MIMEApplicationJSON)
+//	})
+package serializer
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/internal/encode"
+)
+
+const (
+	MIMEJSONAPI = "application/vnd.api+json"
+	MIMEHAL     = "application/hal+json"
+)
+
+var (
+	// JSONAPIRenderer renders the data as the JSON:API document, see [JSONAPI].
+	JSONAPIRenderer wo.Renderer = wo.RendererFunc(func(w io.Writer, data any) error {
+		doc, err := JSONAPI(data)
+		if err != nil {
+			return err
+		}
+		return encode.MarshalJSON(w, doc, "")
+	})
+
+	// HALRenderer renders the data as the HAL document, see [HAL].
+	HALRenderer wo.Renderer = wo.RendererFunc(func(w io.Writer, data any) error {
+		doc, err := HAL(data)
+		if err != nil {
+			return err
+		}
+		return encode.MarshalJSON(w, doc, "")
+	})
+)
+
+// Register registers the JSON:API and the HAL renderers of the router.
+func Register[T wo.Resolver](r *wo.Router[T]) {
+	r.SetRenderer(MIMEJSONAPI, JSONAPIRenderer)
+	r.SetRenderer(MIMEHAL, HALRenderer)
+}
+
+// tag is the parsed struct tag: the kind, the name and the options.
+type tag struct {
+	kind      string
+	name      string
+	omitempty bool
+}
+
+func parseTag(value string) tag {
+	parts := strings.Split(value, ",")
+	t := tag{kind: parts[0]}
+	if len(parts) > 1 {
+		t.name = parts[1]
+	}
+	for _, opt := range parts[min(2, len(parts)):] {
+		if opt == "omitempty" {
+			t.omitempty = true
+		}
+	}
+	return t
+}
+
+// jsonName returns the JSON name of the field and whether it's omitted when empty,
+// the name is empty for the skipped fields.
+func jsonName(f reflect.StructField) (string, bool) {
+	value, ok := f.Tag.Lookup("json")
+	if !ok {
+		return f.Name, false
+	}
+	if value == "-" {
+		return "", false
+	}
+
+	name, opts, _ := strings.Cut(value, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(","+opts+",", ",omitempty,")
+}
+
+// indirect dereferences the pointers, it reports false for the nil ones.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+// isCollection reports whether the value is a slice or an array, except the bytes.
+func isCollection(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+func formatID(v reflect.Value) (string, error) {
+	v, ok := indirect(v)
+	if !ok {
+		return "", nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+	return "", fmt.Errorf("serializer: unsupported id type %s", v.Type())
+}
@@ -0,0 +1,91 @@
+package serializer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+type person struct {
+	ID   int    `jsonapi:"primary,people"`
+	Name string `jsonapi:"attr,name" json:"name"`
+	Self string `jsonapi:"link,self" hal:"link,self"`
+}
+
+type comment struct {
+	ID     string  `jsonapi:"primary,comments" json:"id"`
+	Body   string  `jsonapi:"attr" json:"body"`
+	Author *person `jsonapi:"relation,author" hal:"embedded,author"`
+}
+
+type article struct {
+	ID       string     `jsonapi:"primary,articles" json:"id"`
+	Title    string     `jsonapi:"attr,title" json:"title"`
+	Draft    bool       `jsonapi:"attr,draft,omitempty" json:"draft,omitempty"`
+	Secret   string     `json:"-"`
+	Author   *person    `jsonapi:"relation,author" hal:"embedded,author"`
+	Editor   *person    `jsonapi:"relation,editor,omitempty" hal:"embedded,editor"`
+	Comments []*comment `jsonapi:"relation,comments" hal:"embedded,comments"`
+	Self     string     `jsonapi:"link,self" hal:"link,self"`
+	Tags     []string   `hal:"link,tags"`
+}
+
+func testArticle() *article {
+	author := &person{ID: 9, Name: "Dan", Self: "/people/9"}
+	return &article{
+		ID:     "1",
+		Title:  "JSON:API paints my bikeshed!",
+		Secret: "s3cr3t",
+		Author: author,
+		Comments: []*comment{
+			{ID: "5", Body: "First!", Author: &person{ID: 2, Name: "Ann"}},
+			{ID: "12", Body: "I like XML better", Author: author},
+		},
+		Self: "/articles/1",
+		Tags: []string{"/tags/go"},
+	}
+}
+
+func TestRegister(t *testing.T) {
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, func(e *wo.Event, _ error) {
+		e.Response().WriteHeader(http.StatusInternalServerError)
+	})
+	Register(router)
+	router.GET("/articles/1", func(e *wo.Event) error {
+		return e.Negotiate(http.StatusOK, testArticle(), MIMEJSONAPI, MIMEHAL, wo.MIMEApplicationJSON)
+	})
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		accept   string
+		ctype    string
+		expected string
+	}{
+		{accept: MIMEJSONAPI, ctype: MIMEJSONAPI, expected: `"type":"articles"`},
+		{accept: MIMEHAL, ctype: MIMEHAL, expected: `"_embedded"`},
+		{accept: wo.MIMEApplicationJSON, ctype: wo.MIMEApplicationJSON, expected: `"title":"JSON:API paints my bikeshed!"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/articles/1", nil)
+			req.Header.Set(wo.HeaderAccept, tt.accept)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.ctype, rec.Header().Get(wo.HeaderContentType))
+			assert.Contains(t, rec.Body.String(), tt.expected)
+		})
+	}
+}
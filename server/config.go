@@ -3,6 +3,7 @@ package server
 import (
 	"crypto/tls"
 	"errors"
+	"net/http"
 	"os"
 	"time"
 
@@ -24,6 +25,9 @@ type Config struct {
 	Transport TransportConfig `envPrefix:"TRANSPORT_" json:"transport,omitempty" yaml:"transport,omitempty"`
 
 	TLS *TLSConfig `envPrefix:"TLS_" json:"tls,omitempty" yaml:"tls,omitempty"`
+
+	// Listeners are the additional listeners serving the same handler, ex. the unix sockets.
+	Listeners []ListenerConfig `envPrefix:"LISTENERS_" json:"listeners,omitempty" yaml:"listeners,omitempty"`
 }
 
 func (c *Config) SetDefaults() {
@@ -45,10 +49,18 @@ func (c *Config) SetDefaults() {
 	}
 
 	c.HTTP2.SetDefaults()
+	c.Transport.SetDefaults()
+
+	for i := range c.Listeners {
+		c.Listeners[i].SetDefaults()
+	}
 }
 
 func (c *Config) Validate() error {
-	return validation.ValidateStruct(c)
+	return validation.ValidateStruct(c,
+		validation.Field(&c.Transport),
+		validation.Field(&c.Listeners),
+	)
 }
 
 type HTTP2Config struct {
@@ -79,7 +91,9 @@ type TransportConfig struct {
 	// Because ReadTimeout does not let Handlers make per-request
 	// decisions on each request body's acceptable deadline or
 	// upload rate, most users will prefer to use
-	// ReadHeaderTimeout. It is valid to use them both.
+	// ReadHeaderTimeout. It is valid to use them both. The routes
+	// may override it per request with wo.Route.Budget, and the
+	// middleware.MinBodyRate aborts the bodies trickling too slowly.
 	ReadTimeout time.Duration `env:"READ_TIMEOUT" json:"readTimeout,omitempty,format:units" yaml:"readTimeout,omitempty"`
 
 	// ReadHeaderTimeout is the amount of time allowed to read
@@ -93,7 +107,8 @@ type TransportConfig struct {
 	// WriteTimeout is the maximum duration before timing out
 	// writes of the response. It is reset whenever a new
 	// request's header is read. Like ReadTimeout, it does not
-	// let Handlers make decisions on a per-request basis.
+	// let Handlers make decisions on a per-request basis, except
+	// the routes with wo.Route.Budget.
 	// A zero or negative value means there will be no timeout.
 	WriteTimeout time.Duration `env:"WRITE_TIMEOUT" json:"writeTimeout,omitempty,format:units" yaml:"writeTimeout,omitempty"`
 
@@ -109,6 +124,33 @@ type TransportConfig struct {
 	// size of the request body.
 	// If zero, http.DefaultMaxHeaderBytes is used.
 	MaxHeaderBytes int `env:"MAX_HEADER_BYTES" json:"maxHeaderBytes,omitempty" yaml:"maxHeaderBytes,omitempty"`
+
+	// DisableKeepAlives closes the connections after each request, ex. behind
+	// the load balancers which don't balance the persistent connections.
+	DisableKeepAlives bool `env:"DISABLE_KEEP_ALIVES" json:"disableKeepAlives,omitempty" yaml:"disableKeepAlives,omitempty"`
+}
+
+// SetDefaults sets the timeouts protecting from the slow clients, ex. slowloris, which
+// keep the connections open. ReadTimeout and WriteTimeout are left unset, since they
+// limit the long-lived responses and uploads, use negative values to disable the others.
+func (c *TransportConfig) SetDefaults() {
+	if c.ReadHeaderTimeout == 0 {
+		c.ReadHeaderTimeout = 10 * time.Second
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = 2 * time.Minute
+	}
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes = http.DefaultMaxHeaderBytes
+	}
+}
+
+func (c TransportConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxHeaderBytes, validation.Min(0)),
+		validation.Field(&c.ReadHeaderTimeout, validation.When(c.ReadTimeout > 0 && c.ReadHeaderTimeout > 0,
+			validation.Max(c.ReadTimeout).Error("must be no greater than the read timeout"))),
+	)
 }
 
 type TLSConfig struct {
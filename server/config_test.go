@@ -2,6 +2,7 @@ package server
 
 import (
 	"crypto/tls"
+	"net/http"
 	"testing"
 	"time"
 
@@ -444,6 +445,52 @@ func TestTransportConfig(t *testing.T) {
 	assert.Equal(t, 8192, config.MaxHeaderBytes, "MaxHeaderBytes should be set")
 }
 
+func TestTransportConfig_SetDefaults(t *testing.T) {
+	var config TransportConfig
+	config.SetDefaults()
+
+	assert.Equal(t, TransportConfig{
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+	}, config)
+
+	// the explicit values, including the negative "no timeout", are kept
+	config = TransportConfig{ReadHeaderTimeout: -1, IdleTimeout: time.Second, MaxHeaderBytes: 4096}
+	config.SetDefaults()
+
+	assert.Equal(t, time.Duration(-1), config.ReadHeaderTimeout)
+	assert.Equal(t, time.Second, config.IdleTimeout)
+	assert.Equal(t, 4096, config.MaxHeaderBytes)
+}
+
+func TestTransportConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      TransportConfig
+		expectError bool
+	}{
+		{"defaults", TransportConfig{ReadHeaderTimeout: 10 * time.Second, MaxHeaderBytes: 1 << 20}, false},
+		{"header timeout within read timeout", TransportConfig{ReadTimeout: time.Minute, ReadHeaderTimeout: 10 * time.Second}, false},
+		{"header timeout exceeds read timeout", TransportConfig{ReadTimeout: time.Second, ReadHeaderTimeout: 10 * time.Second}, true},
+		{"negative max header bytes", TransportConfig{MaxHeaderBytes: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			config := Config{Address: ":8080", Transport: tt.config}
+			assert.Equal(t, tt.expectError, config.Validate() != nil)
+		})
+	}
+}
+
 // TestHTTP3Config tests that HTTP3Config has the expected fields
 func TestHTTP3Config(t *testing.T) {
 	config := HTTP3Config{
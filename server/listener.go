@@ -0,0 +1,120 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/invopop/validation"
+)
+
+// ListenerConfig is the additional listener serving the same handler as the main address,
+// ex. the unix socket of the sidecar or the local IPC.
+type ListenerConfig struct {
+	// Network is the listener network: tcp, tcp4, tcp6 or unix.
+	//
+	// Default: tcp
+	Network string `env:"NETWORK" json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Address is the host and port of the tcp listeners or the socket path of the unix listeners.
+	//
+	// Required.
+	Address string `env:"ADDRESS" json:"address,omitempty" yaml:"address,omitempty"`
+
+	// Mode is the octal permissions of the unix socket file, ex. "0660".
+	//
+	// Default: the umask permissions
+	Mode string `env:"MODE" json:"mode,omitempty" yaml:"mode,omitempty"`
+
+	// TLS serves the listener over TLS with its own certificates, the main TLS config is not inherited.
+	TLS *TLSConfig `envPrefix:"TLS_" json:"tls,omitempty" yaml:"tls,omitempty"`
+}
+
+func (c *ListenerConfig) SetDefaults() {
+	if c.Network == "" {
+		c.Network = "tcp"
+	}
+}
+
+func (c ListenerConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Network, validation.Required, validation.In("tcp", "tcp4", "tcp6", "unix")),
+		validation.Field(&c.Address, validation.Required),
+		validation.Field(&c.Mode, validation.When(c.Mode != "", validation.By(func(any) error {
+			_, err := c.fileMode()
+			return err
+		}))),
+		validation.Field(&c.TLS),
+	)
+}
+
+func (c ListenerConfig) fileMode() (fs.FileMode, error) {
+	mode, err := strconv.ParseUint(c.Mode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid file mode %q", c.Mode)
+	}
+	return fs.FileMode(mode), nil
+}
+
+// listen creates the listener with the listen function, the stale socket file of the unix listener is removed
+// unless the listener is inherited.
+func (c ListenerConfig) listen(listen listenFunc) (net.Listener, error) {
+	l, err := listen(c.Network, c.Address, func() error {
+		if c.Network != "unix" {
+			return nil
+		}
+		if info, err := os.Stat(c.Address); err == nil {
+			if info.Mode().Type() != fs.ModeSocket {
+				return fmt.Errorf("server: %s is not a socket", c.Address)
+			}
+			return os.Remove(c.Address)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Network == "unix" && c.Mode != "" {
+		mode, err := c.fileMode()
+		if err == nil {
+			err = os.Chmod(c.Address, mode)
+		}
+		if err != nil {
+			_ = l.Close()
+			return nil, err
+		}
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.tls()
+		if err != nil {
+			_ = l.Close()
+			return nil, err
+		}
+		tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+		l = tls.NewListener(l, tlsConfig)
+	}
+	return l, nil
+}
+
+// listenAll creates the listeners, the created ones are closed on error.
+func listenAll(configs []ListenerConfig, listen listenFunc) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(configs))
+	for _, c := range configs {
+		l, err := c.listen(listen)
+		if err != nil {
+			for _, l := range slices.Backward(listeners) {
+				_ = l.Close()
+			}
+			return nil, errors.Join(fmt.Errorf("server: listen %s %s", c.Network, c.Address), err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      ListenerConfig
+		expectError bool
+	}{
+		{"tcp", ListenerConfig{Network: "tcp", Address: ":8081"}, false},
+		{"unix with mode", ListenerConfig{Network: "unix", Address: "/run/app.sock", Mode: "0660"}, false},
+		{"missing address", ListenerConfig{Network: "tcp"}, true},
+		{"unknown network", ListenerConfig{Network: "udp", Address: ":8081"}, true},
+		{"invalid mode", ListenerConfig{Network: "unix", Address: "/run/app.sock", Mode: "rw"}, true},
+		{"mode out of range", ListenerConfig{Network: "unix", Address: "/run/app.sock", Mode: "7777"}, true},
+		{"invalid tls", ListenerConfig{Network: "tcp", Address: ":8081", TLS: &TLSConfig{}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	cfg := Config{Listeners: []ListenerConfig{{Address: "/run/app.sock", Network: "unix"}, {}}}
+	cfg.SetDefaults()
+	assert.Equal(t, "tcp", cfg.Listeners[1].Network)
+	assert.Error(t, cfg.Validate())
+}
+
+func TestServerMultipleListeners(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+
+	// the stale socket file is replaced
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	cfg := Config{
+		Address:   "127.0.0.1:0",
+		Listeners: []ListenerConfig{{Network: "unix", Address: socket, Mode: "0600"}},
+	}
+	cfg.SetDefaults()
+	require.NoError(t, cfg.Validate())
+
+	server := New(cfg, &mockHandler{}, slog.Default())
+
+	premade, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server.AddListener(premade)
+
+	server.Start()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.NoError(t, server.Stop(ctx))
+	}()
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		resp, err := unixClient.Get("http://unix/")
+		require.NoError(c, err)
+		_ = resp.Body.Close()
+		assert.Equal(c, http.StatusOK, resp.StatusCode)
+
+		resp, err = http.Get("http://" + premade.Addr().String() + "/")
+		require.NoError(c, err)
+		_ = resp.Body.Close()
+		assert.Equal(c, http.StatusOK, resp.StatusCode)
+	}, 2*time.Second, 20*time.Millisecond)
+
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestListenAll_Error(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+	require.NoError(t, os.WriteFile(socket, nil, 0o600))
+
+	_, err := listenAll([]ListenerConfig{
+		{Network: "tcp", Address: "127.0.0.1:0"},
+		{Network: "unix", Address: socket},
+	}, (&Server{}).listen)
+	require.ErrorContains(t, err, "is not a socket")
+}
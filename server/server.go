@@ -5,12 +5,16 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"slices"
 	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/quic-go/quic-go/http3"
 	"golang.org/x/net/http2"
@@ -44,8 +48,18 @@ type Server struct {
 	http2    *http.Server
 	redirect *http.Server
 	chErr    chan error
-	wg       sync.WaitGroup
-	mu       sync.Mutex
+	shutdown []func(context.Context) error
+	start    []func()
+	onConn   []func(net.Conn, http.ConnState)
+	configs  []ListenerConfig
+	extra    []net.Listener
+
+	inherited map[string]*os.File
+	bound     []boundSocket
+	upgrading atomic.Bool
+	command   func() (string, []string, error)
+	wg        sync.WaitGroup
+	mu        sync.Mutex
 }
 
 func New(cfg Config, handler http.Handler, logger *slog.Logger) *Server {
@@ -103,12 +117,16 @@ func New(cfg Config, handler http.Handler, logger *slog.Logger) *Server {
 		logger.Warn("TLS configuration is missing, starting server without TLS")
 	}
 
-	return &Server{
-		logger:   logger,
-		cancel:   cancel,
-		chErr:    make(chan error, 6),
-		redirect: redirect,
-		http3:    h3,
+	s := &Server{
+		logger:  logger,
+		cancel:  cancel,
+		chErr:   make(chan error, 6+len(cfg.Listeners)),
+		configs: cfg.Listeners,
+
+		inherited: inheritedFiles(),
+		command:   defaultCommand,
+		redirect:  redirect,
+		http3:     h3,
 		http2: &http.Server{
 			TLSConfig:         tlsConfig,
 			Addr:              cfg.Address,
@@ -131,40 +149,178 @@ func New(cfg Config, handler http.Handler, logger *slog.Logger) *Server {
 			}),
 		},
 	}
+	s.http2.ConnState = s.connState
+	if redirect != nil {
+		redirect.ConnState = s.connState
+	}
+	if cfg.Transport.DisableKeepAlives {
+		s.http2.SetKeepAlivesEnabled(false)
+	}
+	return s
 }
 
-func (s *Server) Start() {
+// OnConnState registers a function to call when a client connection of the HTTP/1 and HTTP/2
+// listeners changes the state, ex. to count the open connections. It must be called before Start.
+func (s *Server) OnConnState(fn func(net.Conn, http.ConnState)) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.redirect != nil {
-		s.wg.Go(func() {
-			s.logger.Info("start redirect http", slog.String("address", s.redirect.Addr))
+	s.onConn = append(s.onConn, fn)
+}
 
-			s.chErr <- s.redirect.ListenAndServe()
-		})
+// AddListener adds the pre-made listener serving the handler like the main address, ex. the listener
+// inherited from the parent process or created by the systemd socket activation. It must be called
+// before Start, the listener is closed on Stop.
+func (s *Server) AddListener(l net.Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.extra = append(s.extra, l)
+}
+
+func (s *Server) connState(conn net.Conn, state http.ConnState) {
+	for _, fn := range s.onConn {
+		fn(conn, state)
 	}
+}
 
-	s.wg.Go(func() {
-		s.logger.Info("start http2", slog.String("address", s.http2.Addr))
+// RegisterOnShutdown registers a function to call on Stop after the listeners
+// are shut down, e.g. to drain background jobs. Functions are called in reverse order,
+// also when ctx of Stop is done.
+func (s *Server) RegisterOnShutdown(fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shutdown = append(s.shutdown, fn)
+}
 
-		if s.http2.TLSConfig == nil {
-			s.chErr <- s.http2.ListenAndServe()
-			return
+// RegisterOnStart registers a function to call on Start after the listeners
+// are started, e.g. to start background jobs stopped with [Server.RegisterOnShutdown].
+func (s *Server) RegisterOnStart(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.start = append(s.start, fn)
+}
+
+// Start binds the listeners and starts serving, the listeners inherited from the parent process
+// are used instead of the new ones, see [Server.Upgrade]. If a listener can't be bound, the bound
+// ones are closed and the error is returned, the server doesn't serve.
+func (s *Server) Start() error {
+	s.mu.Lock()
+
+	var (
+		serve  []func() error
+		closer []io.Closer
+		errs   []error
+		bound  = len(s.bound)
+	)
+
+	if s.redirect != nil {
+		if l, err := s.listen("tcp", addrOrDefault(s.redirect.Addr, ":http"), nil); err != nil {
+			errs = append(errs, err)
+		} else {
+			closer = append(closer, l)
+			serve = append(serve, func() error {
+				s.logger.Info("start redirect http", slog.String("address", s.redirect.Addr))
+
+				return s.redirect.Serve(l)
+			})
 		}
+	}
 
-		s.chErr <- s.http2.ListenAndServeTLS("", "")
-	})
+	if s.http2.TLSConfig == nil {
+		if l, err := s.listen("tcp", addrOrDefault(s.http2.Addr, ":http"), nil); err != nil {
+			errs = append(errs, err)
+		} else {
+			closer = append(closer, l)
+			serve = append(serve, func() error {
+				s.logger.Info("start http2", slog.String("address", s.http2.Addr))
+
+				return s.http2.Serve(l)
+			})
+		}
+	} else {
+		if l, err := s.listen("tcp", addrOrDefault(s.http2.Addr, ":https"), nil); err != nil {
+			errs = append(errs, err)
+		} else {
+			closer = append(closer, l)
+			serve = append(serve, func() error {
+				s.logger.Info("start http2", slog.String("address", s.http2.Addr))
+
+				return s.http2.ServeTLS(l, "", "")
+			})
+		}
+	}
 
 	if s.http3 != nil {
-		s.wg.Go(func() {
-			s.logger.Info("start http3", slog.String("address", s.http3.Addr))
+		if conn, err := s.listenPacket("udp", addrOrDefault(s.http3.Addr, ":https")); err != nil {
+			errs = append(errs, err)
+		} else {
+			closer = append(closer, conn)
+			serve = append(serve, func() error {
+				s.logger.Info("start http3", slog.String("address", s.http3.Addr))
+
+				return s.http3.Serve(conn)
+			})
+		}
+	}
+
+	listeners, err := listenAll(s.configs, s.listen)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, l := range append(listeners, s.extra...) {
+		closer = append(closer, l)
+		serve = append(serve, func() error {
+			s.logger.Info("start http2", slog.String("network", l.Addr().Network()), slog.String("address", l.Addr().String()))
 
-			s.chErr <- s.http3.ListenAndServe()
+			return s.http2.Serve(l)
+		})
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		for _, c := range slices.Backward(closer) {
+			_ = c.Close()
+		}
+		s.bound = s.bound[:bound]
+		s.mu.Unlock()
+
+		s.logger.Error("start listeners", "error", err)
+		return err
+	}
+
+	for _, fn := range serve {
+		s.wg.Go(func() {
+			s.chErr <- fn()
 		})
 	}
+
+	start := slices.Clone(s.start)
+	s.mu.Unlock()
+
+	// the start functions run without the lock, so they may register the hooks or stop the server
+	for _, fn := range start {
+		fn()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ready()
+	return nil
+}
+
+func addrOrDefault(addr, def string) string {
+	if addr == "" {
+		return def
+	}
+	return addr
 }
 
+// Stop gracefully shuts down the listeners, then calls the shutdown functions. If ctx is done
+// before the listeners are shut down, the shutdown functions are still called and ctx error is returned.
 func (s *Server) Stop(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -203,13 +359,10 @@ func (s *Server) Stop(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
-			return nil
+			return s.onShutdown(ctx, errors.Join(ctx.Err(), err))
 		case err1, ok := <-s.chErr:
 			if !ok {
-				if err != nil {
-					s.logger.Error("shutdown", "error", err)
-				}
-				return err
+				return s.onShutdown(ctx, err)
 			}
 			if !errors.Is(err1, http.ErrServerClosed) {
 				err = errors.Join(err, err1)
@@ -217,3 +370,15 @@ func (s *Server) Stop(ctx context.Context) error {
 		}
 	}
 }
+
+// onShutdown calls the shutdown functions in reverse order, they are called even if ctx is done,
+// so they release the resources with the expired deadline.
+func (s *Server) onShutdown(ctx context.Context, err error) error {
+	for i := len(s.shutdown) - 1; i >= 0; i-- {
+		err = errors.Join(err, s.shutdown[i](ctx))
+	}
+	if err != nil {
+		s.logger.Error("shutdown", "error", err)
+	}
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"log/slog"
 	"math/big"
 	"net"
@@ -655,3 +656,165 @@ func (h *testSlogHandler) WithGroup(name string) slog.Handler {
 		checkFn: h.checkFn,
 	}
 }
+
+func TestServerOnConnState(t *testing.T) {
+	cfg := Config{Address: ":https", Transport: TransportConfig{DisableKeepAlives: true}}
+	cfg.SetDefaults()
+	cfg.TLS = nil
+
+	server := New(cfg, &mockHandler{}, slog.Default())
+
+	assert.Equal(t, 10*time.Second, server.http2.ReadHeaderTimeout)
+	assert.Equal(t, http.DefaultMaxHeaderBytes, server.http2.MaxHeaderBytes)
+
+	var states []http.ConnState
+	server.OnConnState(func(_ net.Conn, state http.ConnState) {
+		states = append(states, state)
+	})
+	server.OnConnState(func(_ net.Conn, state http.ConnState) {
+		states = append(states, state)
+	})
+
+	require.NotNil(t, server.http2.ConnState)
+	server.http2.ConnState(nil, http.StateNew)
+	require.NotNil(t, server.redirect)
+	server.redirect.ConnState(nil, http.StateClosed)
+
+	assert.Equal(t, []http.ConnState{http.StateNew, http.StateNew, http.StateClosed, http.StateClosed}, states)
+}
+
+func TestServerRegisterOnShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	cfg := Config{Address: addr}
+	cfg.SetDefaults()
+
+	server := New(cfg, &mockHandler{}, slog.Default())
+
+	var calls []string
+	server.RegisterOnShutdown(func(context.Context) error {
+		calls = append(calls, "first")
+		return nil
+	})
+	server.RegisterOnShutdown(func(context.Context) error {
+		calls = append(calls, "second")
+		return errors.New("drain failed")
+	})
+
+	server.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = server.Stop(ctx)
+	assert.EqualError(t, err, "drain failed")
+	assert.Equal(t, []string{"second", "first"}, calls)
+}
+
+func TestServerRegisterOnShutdown_Timeout(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	cfg := Config{Address: addr}
+	cfg.SetDefaults()
+
+	served, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	server := New(cfg, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		close(served)
+		<-release
+	}), slog.Default())
+
+	var called bool
+	server.RegisterOnShutdown(func(context.Context) error {
+		called = true
+		return nil
+	})
+
+	server.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	go func() {
+		if res, err := http.Get("http://" + addr); err == nil {
+			_ = res.Body.Close()
+		}
+	}()
+	<-served
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err = server.Stop(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, called)
+}
+
+func TestServerRegisterOnStart(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	cfg := Config{Address: addr}
+	cfg.SetDefaults()
+
+	server := New(cfg, &mockHandler{}, slog.Default())
+
+	var calls []string
+	server.RegisterOnStart(func() { calls = append(calls, "first") })
+	server.RegisterOnStart(func() { calls = append(calls, "second") })
+
+	server.RegisterOnStart(func() {
+		server.RegisterOnShutdown(func(context.Context) error {
+			calls = append(calls, "shutdown")
+			return nil
+		})
+	})
+
+	server.Start()
+	assert.Equal(t, []string{"first", "second"}, calls)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, server.Stop(ctx))
+	assert.Equal(t, []string{"first", "second", "shutdown"}, calls)
+}
+
+func TestServerStart_BindError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	freeAddr := free.Addr().String()
+	_ = free.Close()
+
+	cfg := Config{
+		Address:   listener.Addr().String(),
+		Listeners: []ListenerConfig{{Network: "tcp", Address: freeAddr}},
+	}
+	cfg.SetDefaults()
+
+	server := New(cfg, &mockHandler{}, slog.Default())
+
+	var started bool
+	server.RegisterOnStart(func() { started = true })
+
+	require.Error(t, server.Start())
+	assert.False(t, started)
+	assert.Empty(t, server.bound)
+
+	// the listeners bound before the failure are closed
+	l, err := net.Listen("tcp", freeAddr)
+	require.NoError(t, err)
+	_ = l.Close()
+}
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// envInheritedListeners lists the "network:address" keys of the inherited listeners,
+	// which are passed as the files starting from the file descriptor 3.
+	envInheritedListeners = "WO_INHERITED_LISTENERS"
+
+	// envUpgradeReadyFD is the file descriptor of the pipe the new process reports the readiness to.
+	envUpgradeReadyFD = "WO_UPGRADE_READY_FD"
+)
+
+// ErrUpgradeInProgress is returned by [Server.Upgrade] when the upgrade is already in progress.
+var ErrUpgradeInProgress = errors.New("server: upgrade in progress")
+
+// listenFunc creates the listener of the network and the address, prepare is called before
+// the new listener is created, ex. to remove the stale socket file.
+type listenFunc func(network, address string, prepare func() error) (net.Listener, error)
+
+// filer is implemented by the listeners and the packet connections passed to the new process.
+type filer interface {
+	File() (*os.File, error)
+}
+
+type boundSocket struct {
+	key    string
+	socket filer
+}
+
+func socketKey(network, address string) string {
+	return network + ":" + address
+}
+
+// inheritedFiles returns the files of the listeners inherited from the parent process.
+func inheritedFiles() map[string]*os.File {
+	keys := os.Getenv(envInheritedListeners)
+	if keys == "" {
+		return nil
+	}
+	_ = os.Unsetenv(envInheritedListeners)
+
+	files := make(map[string]*os.File)
+	for i, key := range strings.Split(keys, ",") {
+		files[key] = os.NewFile(uintptr(3+i), key)
+	}
+	return files
+}
+
+// listen returns the inherited listener of the network and the address or creates a new one.
+func (s *Server) listen(network, address string, prepare func() error) (net.Listener, error) {
+	key := socketKey(network, address)
+
+	var (
+		l   net.Listener
+		err error
+	)
+	if f, ok := s.inherited[key]; ok {
+		delete(s.inherited, key)
+		l, err = net.FileListener(f)
+		_ = f.Close()
+	} else {
+		if prepare != nil {
+			if err = prepare(); err != nil {
+				return nil, err
+			}
+		}
+		l, err = net.Listen(network, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if f, ok := l.(filer); ok {
+		s.bound = append(s.bound, boundSocket{key: key, socket: f})
+	}
+	return l, nil
+}
+
+// listenPacket returns the inherited packet connection of the network and the address or creates a new one.
+func (s *Server) listenPacket(network, address string) (net.PacketConn, error) {
+	key := socketKey(network, address)
+
+	var (
+		conn net.PacketConn
+		err  error
+	)
+	if f, ok := s.inherited[key]; ok {
+		delete(s.inherited, key)
+		conn, err = net.FilePacketConn(f)
+		_ = f.Close()
+	} else {
+		conn, err = net.ListenPacket(network, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if f, ok := conn.(filer); ok {
+		s.bound = append(s.bound, boundSocket{key: key, socket: f})
+	}
+	return conn, nil
+}
+
+// ready reports the readiness to the parent process once the listeners are created.
+func (s *Server) ready() {
+	for key, f := range s.inherited {
+		s.logger.Warn("inherited listener is not used", slog.String("listener", key))
+		_ = f.Close()
+	}
+	s.inherited = nil
+
+	fd, err := strconv.Atoi(os.Getenv(envUpgradeReadyFD))
+	if err != nil {
+		return
+	}
+	_ = os.Unsetenv(envUpgradeReadyFD)
+
+	f := os.NewFile(uintptr(fd), "upgrade-ready")
+	_, _ = f.Write([]byte{1})
+	_ = f.Close()
+}
+
+// Upgrade starts the new process of the executable (ex. the new binary) with the same arguments,
+// which inherits the listeners and takes over serving them. It returns once the new process
+// started the server, then the current process should drain the requests and exit with [Server.Stop].
+// The new process is killed if it doesn't start the server before ctx is done.
+func (s *Server) Upgrade(ctx context.Context) error {
+	if !s.upgrading.CompareAndSwap(false, true) {
+		return ErrUpgradeInProgress
+	}
+	defer s.upgrading.Store(false)
+
+	s.mu.Lock()
+	bound := s.bound
+	s.mu.Unlock()
+
+	keys := make([]string, 0, len(bound))
+	files := make([]*os.File, 0, len(bound)+1)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	for _, b := range bound {
+		f, err := b.socket.File()
+		if err != nil {
+			return fmt.Errorf("server: upgrade: %s: %w", b.key, err)
+		}
+		keys = append(keys, b.key)
+		files = append(files, f)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("server: upgrade: %w", err)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	files = append(files, w)
+
+	name, args, err := s.command()
+	if err != nil {
+		return fmt.Errorf("server: upgrade: %w", err)
+	}
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		envInheritedListeners+"="+strings.Join(keys, ","),
+		envUpgradeReadyFD+"="+strconv.Itoa(3+len(keys)),
+	)
+
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("server: upgrade: %w", err)
+	}
+	// the parent copy of the write end must be closed to get EOF when the child exits
+	_ = w.Close()
+	files = files[:len(files)-1]
+
+	exited := make(chan error, 1)
+	readyCh := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		_, err := io.ReadFull(r, buf)
+		readyCh <- err
+	}()
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-readyCh:
+		if err != nil {
+			_ = cmd.Process.Kill()
+			return fmt.Errorf("server: upgrade: new process is not ready: %w", err)
+		}
+	case err = <-exited:
+		return fmt.Errorf("server: upgrade: new process exited: %w", err)
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("server: upgrade: %w", ctx.Err())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the socket files are used by the new process now
+	for _, b := range bound {
+		if ul, ok := b.socket.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	s.logger.Info("upgraded", slog.Int("pid", cmd.Process.Pid))
+	return nil
+}
+
+// UpgradeOnSignal upgrades the server (see [Server.Upgrade]) on the signals, ex. SIGHUP, and stops
+// it draining the requests for the drain timeout. The returned channel is closed once the server is
+// stopped after the upgrade, so the process can exit. The failed upgrades are logged and the server
+// keeps running.
+//
+//	<-srv.UpgradeOnSignal(30*time.Second, syscall.SIGHUP)
+func (s *Server) UpgradeOnSignal(drain time.Duration, sig ...os.Signal) <-chan struct{} {
+	done := make(chan struct{})
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+
+	go func() {
+		defer close(done)
+		defer signal.Stop(ch)
+
+		for range ch {
+			ctx, cancel := context.WithTimeout(context.Background(), drain)
+			if err := s.Upgrade(ctx); err != nil {
+				cancel()
+				s.logger.Error("upgrade", "error", err)
+				continue
+			}
+			if err := s.Stop(ctx); err != nil {
+				s.logger.Error("stop after upgrade", "error", err)
+			}
+			cancel()
+			return
+		}
+	}()
+
+	return done
+}
+
+func defaultCommand() (string, []string, error) {
+	name, err := os.Executable()
+	if err != nil {
+		return "", nil, err
+	}
+	return name, os.Args[1:], nil
+}
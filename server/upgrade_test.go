@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envUpgradeHelper = "WO_TEST_UPGRADE_HELPER"
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return addr
+}
+
+// discardOutput keeps the output of the new process out of the test output.
+func discardOutput(t *testing.T) {
+	t.Helper()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = devNull, devNull
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		_ = devNull.Close()
+	})
+}
+
+func get(t require.TestingT, url string) string {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// TestUpgradeHelperProcess is the new process started by TestServerUpgrade.
+func TestUpgradeHelperProcess(t *testing.T) {
+	addr := os.Getenv(envUpgradeHelper)
+	if addr == "" {
+		t.Skip("helper process")
+	}
+
+	served := make(chan struct{}, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("upgraded"))
+		select {
+		case served <- struct{}{}:
+		default:
+		}
+	})
+
+	cfg := Config{Address: addr}
+	cfg.SetDefaults()
+
+	server := New(cfg, handler, slog.New(slog.DiscardHandler))
+	require.Len(t, server.inherited, 1)
+
+	server.Start()
+
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = server.Stop(ctx)
+}
+
+func TestServerUpgrade(t *testing.T) {
+	addr := freeAddr(t)
+	t.Setenv(envUpgradeHelper, addr)
+	discardOutput(t)
+
+	cfg := Config{Address: addr}
+	cfg.SetDefaults()
+
+	server := New(cfg, &mockHandler{}, slog.New(slog.DiscardHandler))
+	server.command = func() (string, []string, error) {
+		return os.Args[0], []string{"-test.run=^TestUpgradeHelperProcess$"}, nil
+	}
+
+	server.Start()
+
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.Equal(c, "OK", get(c, "http://"+addr+"/"))
+	}, 2*time.Second, 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	require.NoError(t, server.Upgrade(ctx))
+	require.NoError(t, server.Stop(ctx))
+
+	// the listener is kept open by the new process
+	assert.Equal(t, "upgraded", get(t, "http://"+addr+"/"))
+}
+
+func TestServerUpgrade_Errors(t *testing.T) {
+	discardOutput(t)
+
+	cfg := Config{Address: freeAddr(t)}
+	cfg.SetDefaults()
+
+	t.Run("in progress", func(t *testing.T) {
+		server := New(cfg, &mockHandler{}, slog.New(slog.DiscardHandler))
+		server.upgrading.Store(true)
+
+		assert.ErrorIs(t, server.Upgrade(context.Background()), ErrUpgradeInProgress)
+	})
+
+	t.Run("new process is not ready", func(t *testing.T) {
+		server := New(cfg, &mockHandler{}, slog.New(slog.DiscardHandler))
+		server.command = func() (string, []string, error) {
+			return os.Args[0], []string{"-test.run=^$"}, nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		assert.ErrorContains(t, server.Upgrade(ctx), "server: upgrade: new process")
+		assert.False(t, server.upgrading.Load())
+	})
+}
+
+func TestServerInheritedListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+
+	f, err := l.(*net.TCPListener).File()
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	cfg := Config{Address: addr}
+	cfg.SetDefaults()
+
+	server := New(cfg, &mockHandler{}, slog.New(slog.DiscardHandler))
+	server.inherited = map[string]*os.File{socketKey("tcp", addr): f}
+
+	server.Start()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.NoError(t, server.Stop(ctx))
+	}()
+
+	assert.Equal(t, "OK", get(t, "http://"+addr+"/"))
+	assert.Nil(t, server.inherited)
+	require.Len(t, server.bound, 1)
+	assert.Equal(t, "tcp:"+addr, server.bound[0].key)
+}
+
+func TestServerReady(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer func() {
+		_ = r.Close()
+	}()
+
+	// ready closes the file descriptor, so the duplicate is passed
+	fd, err := syscall.Dup(int(w.Fd()))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	t.Setenv(envUpgradeReadyFD, strconv.Itoa(fd))
+
+	unused, err := os.CreateTemp(t.TempDir(), "socket")
+	require.NoError(t, err)
+
+	server := &Server{
+		logger:    slog.New(slog.DiscardHandler),
+		inherited: map[string]*os.File{"tcp:127.0.0.1:1": unused},
+	}
+	server.ready()
+
+	buf := make([]byte, 1)
+	_, err = io.ReadFull(r, buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, buf)
+	assert.Nil(t, server.inherited)
+	assert.Error(t, unused.Close(), "unused inherited file must be closed")
+
+	_, ok := os.LookupEnv(envUpgradeReadyFD)
+	assert.False(t, ok)
+}
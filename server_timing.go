@@ -0,0 +1,75 @@
+package wo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ctxServerTimingKey struct{}
+
+// ServerTimingMetric is a single Server-Timing metric.
+type ServerTimingMetric struct {
+	Name        string
+	Duration    time.Duration
+	Description string
+}
+
+// ServerTiming accumulates the Server-Timing metrics of a request (DB time, template
+// rendering, external calls, etc). It is safe for concurrent use.
+//
+// See: https://www.w3.org/TR/server-timing/
+type ServerTiming struct {
+	metrics []ServerTimingMetric
+	mu      sync.Mutex
+}
+
+// Add records a metric. The description is optional.
+func (t *ServerTiming) Add(name string, dur time.Duration, desc string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.metrics = append(t.metrics, ServerTimingMetric{Name: name, Duration: dur, Description: desc})
+}
+
+// Metrics returns a copy of the recorded metrics.
+func (t *ServerTiming) Metrics() []ServerTimingMetric {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]ServerTimingMetric(nil), t.metrics...)
+}
+
+// String returns the Server-Timing header value, ex. `db;dur=53.2;desc="Query", app;dur=120`.
+func (t *ServerTiming) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var sb strings.Builder
+	for i, m := range t.metrics {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(m.Name)
+		sb.WriteString(";dur=")
+		sb.WriteString(strconv.FormatFloat(float64(m.Duration)/float64(time.Millisecond), 'f', -1, 64))
+		if m.Description != "" {
+			sb.WriteString(";desc=")
+			sb.WriteString(strconv.Quote(m.Description))
+		}
+	}
+	return sb.String()
+}
+
+// WithServerTiming returns a copy of ctx which carries the Server-Timing accumulator.
+func WithServerTiming(ctx context.Context, t *ServerTiming) context.Context {
+	return context.WithValue(ctx, ctxServerTimingKey{}, t)
+}
+
+// ServerTimingFromContext returns the Server-Timing accumulator stored in ctx or nil.
+func ServerTimingFromContext(ctx context.Context) *ServerTiming {
+	t, _ := ctx.Value(ctxServerTimingKey{}).(*ServerTiming)
+	return t
+}
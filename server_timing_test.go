@@ -0,0 +1,40 @@
+package wo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerTiming_String(t *testing.T) {
+	st := new(ServerTiming)
+	assert.Empty(t, st.String())
+
+	st.Add("db", 53200*time.Microsecond, "Query")
+	st.Add("cache", 0, "")
+	st.Add("app", 120*time.Millisecond, `say "hi"`)
+
+	assert.Equal(t, `db;dur=53.2;desc="Query", cache;dur=0, app;dur=120;desc="say \"hi\""`, st.String())
+	assert.Len(t, st.Metrics(), 3)
+}
+
+func TestServerTimingFromContext(t *testing.T) {
+	assert.Nil(t, ServerTimingFromContext(context.Background()))
+
+	st := new(ServerTiming)
+	assert.Same(t, st, ServerTimingFromContext(WithServerTiming(context.Background(), st)))
+}
+
+func TestEvent_Timing(t *testing.T) {
+	e, _, _ := newTestEventForEventTest()
+
+	e.Timing("db", time.Millisecond, "")
+	e.Timing("tpl", 2*time.Millisecond, "render")
+
+	st := ServerTimingFromContext(e.Context())
+	if assert.NotNil(t, st) {
+		assert.Equal(t, `db;dur=1, tpl;dur=2;desc="render"`, st.String())
+	}
+}
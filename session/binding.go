@@ -0,0 +1,69 @@
+package session
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gowool/wo/fingerprint"
+)
+
+const (
+	bindIPKey        = InternalKeyPrefix + "bindIP"
+	bindUserAgentKey = InternalKeyPrefix + "bindUserAgent"
+)
+
+// Mismatch describes the request attributes which differ from the ones the session is bound to,
+// see Config.BindToIP and Config.BindToUserAgent.
+type Mismatch struct {
+	IP        bool
+	UserAgent bool
+}
+
+// bindings returns the fingerprints of the request attributes the session is bound to.
+func (s *Session) bindings(r *http.Request) map[string]string {
+	if !s.config.BindToIP && !s.config.BindToUserAgent {
+		return nil
+	}
+
+	bindings := make(map[string]string, 2)
+	if s.config.BindToIP {
+		bindings[bindIPKey] = fingerprint.Of(r, fingerprint.IP)
+	}
+	if s.config.BindToUserAgent {
+		bindings[bindUserAgentKey] = fingerprint.Of(r, fingerprint.UserAgent)
+	}
+	return bindings
+}
+
+// verifyBindings compares the request attributes with the ones the session is bound to. On mismatch
+// the session is destroyed, or config.OnMismatch is called if set. The request attributes are bound
+// to the session when it's committed.
+func (s *Session) verifyBindings(ctx context.Context, r *http.Request) error {
+	bindings := s.bindings(r)
+	if bindings == nil {
+		return nil
+	}
+
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	sd.bindings = bindings
+
+	var m Mismatch
+	if bound, ok := sd.values[bindIPKey].(string); ok && bindings[bindIPKey] != "" && bound != bindings[bindIPKey] {
+		m.IP = true
+	}
+	if bound, ok := sd.values[bindUserAgentKey].(string); ok && bindings[bindUserAgentKey] != "" && bound != bindings[bindUserAgentKey] {
+		m.UserAgent = true
+	}
+	sd.mu.Unlock()
+
+	if !m.IP && !m.UserAgent {
+		return nil
+	}
+
+	if s.config.OnMismatch != nil {
+		return s.config.OnMismatch(ctx, r, m)
+	}
+	return s.Destroy(ctx)
+}
@@ -0,0 +1,166 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMemoryStore is a minimal in-memory Store for the request round-trip tests.
+type testMemoryStore struct {
+	data map[string][]byte
+	mu   sync.Mutex
+}
+
+func (m *testMemoryStore) Delete(_ context.Context, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.data, token)
+	return nil
+}
+
+func (m *testMemoryStore) Find(_ context.Context, token string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.data[token]
+	return b, ok, nil
+}
+
+func (m *testMemoryStore) Commit(_ context.Context, token string, b []byte, _ time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data == nil {
+		m.data = make(map[string][]byte)
+	}
+	m.data[token] = b
+	return nil
+}
+
+func newBindingRequest(token, remoteAddr, ua string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = remoteAddr
+	r.Header.Set("User-Agent", ua)
+	if token != "" {
+		r.AddCookie(&http.Cookie{Name: "session", Value: token})
+	}
+	return r
+}
+
+// commitBoundSession creates the session bound to the client 192.0.2.1 / "agent".
+func commitBoundSession(t *testing.T, s *Session) string {
+	t.Helper()
+
+	r, err := s.ReadSessionCookie(newBindingRequest("", "192.0.2.1:1234", "agent"))
+	require.NoError(t, err)
+
+	s.Put(r.Context(), "user", "alice")
+	token, _, err := s.Commit(r.Context())
+	require.NoError(t, err)
+	return token
+}
+
+func TestSession_Bindings(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		remoteAddr string
+		ua         string
+		kept       bool
+	}{
+		{"same client", Config{BindToIP: true, BindToUserAgent: true}, "192.0.2.1:4321", "agent", true},
+		{"other IP", Config{BindToIP: true, BindToUserAgent: true}, "192.0.2.2:1234", "agent", false},
+		{"other user agent", Config{BindToIP: true, BindToUserAgent: true}, "192.0.2.1:1234", "other", false},
+		{"other IP not bound", Config{BindToUserAgent: true}, "192.0.2.2:1234", "agent", true},
+		{"other user agent not bound", Config{BindToIP: true}, "192.0.2.1:1234", "other", true},
+		{"not bound", Config{}, "192.0.2.2:1234", "other", true},
+		{"lazy is disabled", Config{BindToIP: true, Lazy: true}, "192.0.2.2:1234", "agent", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := new(testMemoryStore)
+			s := New(tt.config, store)
+			token := commitBoundSession(t, s)
+
+			r, err := s.ReadSessionCookie(newBindingRequest(token, tt.remoteAddr, tt.ua))
+			require.NoError(t, err)
+
+			if tt.kept {
+				assert.Equal(t, "alice", s.GetString(r.Context(), "user"))
+				assert.Equal(t, token, s.Token(r.Context()))
+				return
+			}
+
+			assert.Empty(t, s.GetString(r.Context(), "user"))
+			assert.Equal(t, Destroyed, s.Status(r.Context()))
+			_, found, _ := store.Find(context.Background(), token)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestSession_Bindings_OnMismatch(t *testing.T) {
+	errChallenge := errors.New("challenge")
+
+	var mismatch Mismatch
+	s := New(Config{
+		BindToIP:        true,
+		BindToUserAgent: true,
+		OnMismatch: func(_ context.Context, _ *http.Request, m Mismatch) error {
+			mismatch = m
+			return errChallenge
+		},
+	}, new(testMemoryStore))
+	token := commitBoundSession(t, s)
+
+	_, err := s.ReadSessionCookie(newBindingRequest(token, "192.0.2.2:1234", "agent"))
+	assert.ErrorIs(t, err, errChallenge)
+	assert.Equal(t, Mismatch{IP: true}, mismatch)
+}
+
+func TestSession_Bindings_Rebind(t *testing.T) {
+	s := New(Config{
+		BindToIP: true,
+		OnMismatch: func(context.Context, *http.Request, Mismatch) error {
+			return nil
+		},
+	}, new(testMemoryStore))
+	token := commitBoundSession(t, s)
+
+	// the mismatched session is kept and bound to the new address on commit
+	r, err := s.ReadSessionCookie(newBindingRequest(token, "192.0.2.2:1234", "agent"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", s.GetString(r.Context(), "user"))
+
+	s.Put(r.Context(), "user", "bob")
+	_, _, err = s.Commit(r.Context())
+	require.NoError(t, err)
+
+	s.config.OnMismatch = nil
+	r, err = s.ReadSessionCookie(newBindingRequest(token, "192.0.2.2:1234", "agent"))
+	require.NoError(t, err)
+	assert.Equal(t, "bob", s.GetString(r.Context(), "user"))
+}
+
+func TestSession_Bindings_Unbound(t *testing.T) {
+	store := new(testMemoryStore)
+
+	// the session committed before the binding was enabled is bound on the next commit
+	token := commitBoundSession(t, New(Config{}, store))
+
+	s := New(Config{BindToIP: true}, store)
+	r, err := s.ReadSessionCookie(newBindingRequest(token, "192.0.2.2:1234", "agent"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", s.GetString(r.Context(), "user"))
+	assert.False(t, s.Has(r.Context(), bindIPKey))
+}
@@ -1,8 +1,11 @@
 package session
 
 import (
+	"context"
 	"net/http"
 	"time"
+
+	"github.com/gowool/wo/clock"
 )
 
 type SameSite string
@@ -71,13 +74,62 @@ type Config struct {
 	// HashTokenInStore controls to store the session token or a hashed version in the store.
 	HashTokenInStore bool `env:"HASH_TOKEN_IN_STORE" json:"hashTokenInStore,omitempty" yaml:"hashTokenInStore,omitempty"`
 
+	// Lazy defers the session store lookup of the session cookie until the first
+	// session access (ex. by the session middleware), see [Session.LoadLazy].
+	Lazy bool `env:"LAZY" json:"lazy,omitempty" yaml:"lazy,omitempty"`
+
+	// BindToIP binds the session to the client IP address, so the session replayed from another
+	// network is destroyed when read from the session cookie, see OnMismatch. The address is
+	// bound (hashed) when the session is committed.
+	//
+	// It disables the lazy loading, since the binding is verified before the handlers run.
+	BindToIP bool `env:"BIND_TO_IP" json:"bindToIp,omitempty" yaml:"bindToIp,omitempty"`
+
+	// BindToUserAgent binds the session to the client User-Agent, like BindToIP.
+	BindToUserAgent bool `env:"BIND_TO_USER_AGENT" json:"bindToUserAgent,omitempty" yaml:"bindToUserAgent,omitempty"`
+
+	// OnMismatch is called instead of destroying the session when the request doesn't match
+	// the bound attributes, ex. to challenge the user. The returned error is returned by
+	// [Session.ReadSessionCookie], nil keeps the session, which is bound to the new
+	// attributes on the next commit.
+	OnMismatch func(ctx context.Context, r *http.Request, m Mismatch) error `json:"-" yaml:"-"`
+
+	// CookieDomain returns the domain of the session cookie of the request, ex. the tenant domain
+	// (see tenant.CookieDomain). Empty falls back to Cookie.Domain.
+	//
+	// Default: nil
+	CookieDomain func(ctx context.Context) string `json:"-" yaml:"-"`
+
+	// StrictCommit enables the optimistic locking of the sessions: [Session.Commit] returns
+	// [ErrConflict] if the session has been committed by another request cycle since it was
+	// loaded, instead of overwriting its changes. The store must implement [VersionedStore].
+	//
+	// Note that with IdleTimeout every request cycle commits the session, so the concurrent
+	// requests of the same session conflict even if they don't change it.
+	StrictCommit bool `env:"STRICT_COMMIT" json:"strictCommit,omitempty" yaml:"strictCommit,omitempty"`
+
+	// RotateInterval renews the session token once it's older than the interval, keeping the
+	// session data and deadline, so the leaked tokens are short-lived, see
+	// [Session.RenewTokenIfNeeded]. The session middleware rotates the tokens transparently.
+	// By default the tokens are renewed on the privilege changes only.
+	RotateInterval time.Duration `env:"ROTATE_INTERVAL" json:"rotateInterval,omitempty,format:units" yaml:"rotateInterval,omitempty"`
+
 	// Cookie contains the configuration settings for session cookies.
 	Cookie Cookie `envPrefix:"COOKIE_" json:"cookie,omitempty" yaml:"cookie,omitempty"`
+
+	// Clock is the clock of the session deadlines and the cookie expiry.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
 }
 
 func (c *Config) SetDefaults() {
 	c.Cookie.SetDefaults()
 
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+
 	if c.Lifetime == 0 {
 		c.Lifetime = 24 * time.Hour
 	}
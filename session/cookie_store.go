@@ -0,0 +1,215 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gowool/wo/clock"
+)
+
+var _ StatelessStore = (*CookieStore)(nil)
+
+// ErrCookieTooLarge is returned by [Session.Commit] when the sealed session data doesn't fit
+// in the session cookies of the [CookieStore], see CookieStoreConfig.MaxChunks.
+var ErrCookieTooLarge = errors.New("session: session data is too large for the cookies")
+
+// cookieChunkSize is the maximum size of the session token in a single cookie, below the
+// 4096 bytes browsers allow for the cookie with its name and attributes.
+const cookieChunkSize = 3800
+
+type CookieStoreConfig struct {
+	// MaxChunks is the maximum number of the cookies the session token is split across,
+	// each up to 3800 bytes.
+	//
+	// Default: 4
+	MaxChunks int `env:"MAX_CHUNKS" json:"maxChunks,omitempty" yaml:"maxChunks,omitempty"`
+
+	// Clock is the clock of the expiry.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+func (c *CookieStoreConfig) SetDefaults() {
+	if c.MaxChunks == 0 {
+		c.MaxChunks = 4
+	}
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+}
+
+func (c *CookieStoreConfig) Validate() error {
+	if c.MaxChunks < 1 {
+		return errors.New("session: max chunks must be positive")
+	}
+	return nil
+}
+
+// CookieStore keeps the whole session data in the session cookie, encrypted and authenticated
+// with AES-GCM, for the stateless deployments without the shared store. The keys are rotated by
+// adding the new key in front of the old ones: the first key seals, all of them open.
+//
+// The sessions can't be revoked on the server: Delete is a no-op, so the destroyed session and
+// the old token of the renewed one stay valid until they expire, keep Lifetime and IdleTimeout
+// short. The session data must fit in the cookies, see CookieStoreConfig.MaxChunks.
+//
+//	store := session.NewCookieStore(session.CookieStoreConfig{}, key) // 32 bytes for AES-256
+//	s := session.New(session.Config{Lifetime: 12 * time.Hour, Cookie: session.Cookie{Secure: true}}, store)
+type CookieStore struct {
+	config CookieStoreConfig
+	aeads  []cipher.AEAD
+}
+
+func NewCookieStore(cfg CookieStoreConfig, keys ...[]byte) *CookieStore {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+	if len(keys) == 0 {
+		panic("session: cookie store requires at least one key")
+	}
+
+	aeads := make([]cipher.AEAD, 0, len(keys))
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			panic("session: cookie store key: " + err.Error())
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic("session: cookie store key: " + err.Error())
+		}
+		aeads = append(aeads, aead)
+	}
+
+	return &CookieStore{config: cfg, aeads: aeads}
+}
+
+// Seal encrypts the session data and the expiry time into the session token.
+func (s *CookieStore) Seal(_ context.Context, data []byte, expiry time.Time) (string, error) {
+	aead := s.aeads[0]
+
+	plaintext := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(plaintext, uint64(expiry.UnixNano()))
+	plaintext = append(plaintext, data...)
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil))
+	if len(token) > s.config.MaxChunks*cookieChunkSize {
+		return "", ErrCookieTooLarge
+	}
+	return token, nil
+}
+
+// Find decrypts the session data of the session token, the tampered, the malformed and
+// the expired tokens aren't found.
+func (s *CookieStore) Find(_ context.Context, token string) ([]byte, bool, error) {
+	if token == "" || len(token) > s.config.MaxChunks*cookieChunkSize {
+		return nil, false, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	for _, aead := range s.aeads {
+		if len(sealed) < aead.NonceSize()+aead.Overhead()+8 {
+			return nil, false, nil
+		}
+
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+		if err != nil {
+			continue
+		}
+
+		expiry := time.Unix(0, int64(binary.BigEndian.Uint64(plaintext)))
+		if !s.config.Clock.Now().Before(expiry) {
+			return nil, false, nil
+		}
+		return plaintext[8:], true, nil
+	}
+	return nil, false, nil
+}
+
+// Commit is a no-op, the session data is kept in the session token, see Seal.
+func (s *CookieStore) Commit(context.Context, string, []byte, time.Time) error {
+	return nil
+}
+
+// Delete is a no-op, the sealed session tokens can't be revoked.
+func (s *CookieStore) Delete(context.Context, string) error {
+	return nil
+}
+
+type cookieChunksKey struct{}
+
+// readCookieChunks returns the session token split across the cookie and its chunks,
+// "session", "session.1", "session.2" and so on, and the number of the chunks.
+func readCookieChunks(r *http.Request, name string) (string, int) {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return "", 0
+	}
+
+	var b strings.Builder
+	b.WriteString(cookie.Value)
+
+	n := 1
+	for ; ; n++ {
+		chunk, err := r.Cookie(name + "." + strconv.Itoa(n))
+		if err != nil {
+			break
+		}
+		b.WriteString(chunk.Value)
+	}
+	return b.String(), n
+}
+
+// writeCookieChunks writes the cookie with the token split into the chunks, the chunks
+// read from the request which aren't needed anymore are deleted.
+func writeCookieChunks(ctx context.Context, w http.ResponseWriter, cookie *http.Cookie) {
+	token, name := cookie.Value, cookie.Name
+
+	n := 0
+	for {
+		chunk := *cookie
+		if n > 0 {
+			chunk.Name = name + "." + strconv.Itoa(n)
+		}
+		chunk.Value = token[:min(len(token), cookieChunkSize)]
+		token = token[len(chunk.Value):]
+		http.SetCookie(w, &chunk)
+
+		n++
+		if token == "" {
+			break
+		}
+	}
+
+	read, _ := ctx.Value(cookieChunksKey{}).(int)
+	for ; n < read; n++ {
+		chunk := *cookie
+		chunk.Name = name + "." + strconv.Itoa(n)
+		chunk.Value = ""
+		chunk.Expires = time.Unix(1, 0)
+		chunk.MaxAge = -1
+		http.SetCookie(w, &chunk)
+	}
+}
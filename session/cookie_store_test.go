@@ -0,0 +1,151 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+var (
+	testCookieKey    = bytes.Repeat([]byte("k"), 32)
+	testCookieOldKey = bytes.Repeat([]byte("o"), 32)
+)
+
+// roundTrip commits the session and returns the request carrying the written session cookies.
+func roundTrip(t *testing.T, s *Session, ctx context.Context) (*http.Request, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	token, expiry, err := s.Commit(ctx)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	s.WriteSessionCookie(ctx, rec, token, expiry)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.MaxAge >= 0 {
+			r.AddCookie(cookie)
+		}
+	}
+	return r, rec
+}
+
+func TestCookieStore(t *testing.T) {
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	s := New(Config{Lifetime: time.Hour, Clock: clk}, NewCookieStore(CookieStoreConfig{Clock: clk}, testCookieKey))
+
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+	s.Put(ctx, "user", "ann")
+
+	req, rec := roundTrip(t, s, ctx)
+	require.Len(t, rec.Result().Cookies(), 1)
+
+	r, err := s.ReadSessionCookie(req)
+	require.NoError(t, err)
+	assert.Equal(t, "ann", s.GetString(r.Context(), "user"))
+
+	// tampered
+	cookie, err := r.Cookie("session")
+	require.NoError(t, err)
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	require.NoError(t, err)
+	sealed[len(sealed)-1] ^= 1
+	tampered := httptest.NewRequest(http.MethodGet, "/", nil)
+	tampered.AddCookie(&http.Cookie{Name: "session", Value: base64.RawURLEncoding.EncodeToString(sealed)})
+	tampered, err = s.ReadSessionCookie(tampered)
+	require.NoError(t, err)
+	assert.Empty(t, s.GetString(tampered.Context(), "user"))
+
+	// expired
+	clk.Add(time.Hour)
+	r, err = s.ReadSessionCookie(req)
+	require.NoError(t, err)
+	assert.Empty(t, s.GetString(r.Context(), "user"))
+}
+
+func TestCookieStore_KeyRotation(t *testing.T) {
+	old := New(Config{}, NewCookieStore(CookieStoreConfig{}, testCookieOldKey))
+
+	ctx, err := old.Load(context.Background(), "")
+	require.NoError(t, err)
+	old.Put(ctx, "user", "ann")
+	r, _ := roundTrip(t, old, ctx)
+
+	s := New(Config{}, NewCookieStore(CookieStoreConfig{}, testCookieKey, testCookieOldKey))
+	r, err = s.ReadSessionCookie(r)
+	require.NoError(t, err)
+	assert.Equal(t, "ann", s.GetString(r.Context(), "user"))
+
+	// sealed with the new key only
+	r, _ = roundTrip(t, s, r.Context())
+	current := New(Config{}, NewCookieStore(CookieStoreConfig{}, testCookieKey))
+	r, err = current.ReadSessionCookie(r)
+	require.NoError(t, err)
+	assert.Equal(t, "ann", current.GetString(r.Context(), "user"))
+}
+
+func TestCookieStore_Chunks(t *testing.T) {
+	s := New(Config{}, NewCookieStore(CookieStoreConfig{}, testCookieKey))
+
+	large := make([]byte, 6000)
+	_, _ = rand.Read(large)
+
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+	s.Put(ctx, "blob", large)
+
+	r, rec := roundTrip(t, s, ctx)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 3)
+	assert.Equal(t, []string{"session", "session.1", "session.2"}, []string{cookies[0].Name, cookies[1].Name, cookies[2].Name})
+	for _, cookie := range cookies {
+		assert.LessOrEqual(t, len(cookie.Value), cookieChunkSize)
+	}
+
+	r, err = s.ReadSessionCookie(r)
+	require.NoError(t, err)
+	assert.Equal(t, large, s.GetBytes(r.Context(), "blob"))
+
+	// the chunks not needed anymore are deleted
+	s.Remove(r.Context(), "blob")
+	_, rec = roundTrip(t, s, r.Context())
+	cookies = rec.Result().Cookies()
+	require.Len(t, cookies, 3)
+	assert.Equal(t, "session", cookies[0].Name)
+	assert.GreaterOrEqual(t, cookies[0].MaxAge, 0)
+	assert.Equal(t, "session.1", cookies[1].Name)
+	assert.Equal(t, -1, cookies[1].MaxAge)
+	assert.Equal(t, "session.2", cookies[2].Name)
+	assert.Equal(t, -1, cookies[2].MaxAge)
+}
+
+func TestCookieStore_TooLarge(t *testing.T) {
+	s := New(Config{}, NewCookieStore(CookieStoreConfig{MaxChunks: 1}, testCookieKey))
+
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+	s.Put(ctx, "blob", make([]byte, cookieChunkSize))
+
+	_, _, err = s.Commit(ctx)
+	assert.ErrorIs(t, err, ErrCookieTooLarge)
+}
+
+func TestNewCookieStore_Invalid(t *testing.T) {
+	assert.Panics(t, func() { NewCookieStore(CookieStoreConfig{}) })
+	assert.Panics(t, func() { NewCookieStore(CookieStoreConfig{}, []byte("short")) })
+	assert.Panics(t, func() { NewCookieStore(CookieStoreConfig{MaxChunks: -1}, testCookieKey) })
+	assert.Panics(t, func() {
+		New(Config{HashTokenInStore: true}, NewCookieStore(CookieStoreConfig{}, testCookieKey))
+	})
+}
@@ -5,8 +5,8 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
-	"maps"
 	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -32,17 +32,40 @@ const (
 	Destroyed
 )
 
+// InternalKeyPrefix prefixes the keys of the values stored in the session data for the internal
+// use, ex. the session binding or the OAuth flow state.
+const InternalKeyPrefix = "__"
+
+const rememberMeKey = InternalKeyPrefix + "rememberMe"
+
 type sessionData struct {
 	deadline time.Time
 	status   Status
 	token    string
 	values   map[string]any
+	readOnly bool
 	mu       sync.Mutex
+
+	// version is the version of the stored session data, see Config.StrictCommit.
+	version uint64
+
+	// bindings are the request attributes fingerprints written on commit, see Config.BindToIP.
+	bindings map[string]string
+
+	// lazy is set for the session data which is loaded from the store on the first access.
+	lazy     *lazyLoad
+	loadErr  error
+	loadOnce sync.Once
+}
+
+type lazyLoad struct {
+	session *Session
+	token   string
 }
 
-func newSessionData(lifetime time.Duration) *sessionData {
+func newSessionData(now time.Time, lifetime time.Duration) *sessionData {
 	return &sessionData{
-		deadline: time.Now().Add(lifetime).UTC(),
+		deadline: now.Add(lifetime).UTC(),
 		status:   Unmodified,
 		values:   make(map[string]any),
 	}
@@ -79,20 +102,60 @@ func (s *Session) Load(ctx context.Context, token string) (context.Context, erro
 		return ctx, nil
 	}
 
+	sd, err := s.find(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.addSessionDataToContext(ctx, sd), nil
+}
+
+// LoadLazy acts like Load, but the session data is retrieved from the session store
+// on the first access, so the request cycles which never touch the session skip
+// the store round-trip. If the store fails, the session is empty and Commit returns
+// the store error.
+func (s *Session) LoadLazy(ctx context.Context, token string) context.Context {
+	if _, ok := ctx.Value(s.contextKey).(*sessionData); ok {
+		return ctx
+	}
+
 	if token == "" {
-		return s.addSessionDataToContext(ctx, newSessionData(s.config.Lifetime)), nil
+		return s.addSessionDataToContext(ctx, newSessionData(s.config.Clock.Now(), s.config.Lifetime))
 	}
 
-	b, found, err := s.doStoreFind(ctx, token)
+	return s.addSessionDataToContext(ctx, &sessionData{lazy: &lazyLoad{session: s, token: token}})
+}
+
+// Loaded reports whether the session data of the current request cycle has been
+// retrieved from the store, which is false only for the untouched lazy loaded sessions.
+func (s *Session) Loaded(ctx context.Context) bool {
+	sd, ok := ctx.Value(s.contextKey).(*sessionData)
+	if !ok {
+		return false
+	}
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	return sd.lazy == nil
+}
+
+func (s *Session) find(ctx context.Context, token string) (*sessionData, error) {
+	if token == "" {
+		return newSessionData(s.config.Clock.Now(), s.config.Lifetime), nil
+	}
+
+	b, version, found, err := s.doStoreFindVersion(ctx, token)
 	if err != nil {
 		return nil, err
 	} else if !found {
-		return s.addSessionDataToContext(ctx, newSessionData(s.config.Lifetime)), nil
+		return newSessionData(s.config.Clock.Now(), s.config.Lifetime), nil
 	}
 
 	sd := &sessionData{
-		status: Unmodified,
-		token:  token,
+		status:  Unmodified,
+		token:   token,
+		version: version,
 	}
 	if sd.deadline, sd.values, err = s.codec.Decode(b); err != nil {
 		return nil, err
@@ -100,29 +163,67 @@ func (s *Session) Load(ctx context.Context, token string) (context.Context, erro
 
 	// Mark the session data as modified if an idle timeout is being used. This
 	// will force the session data to be re-committed to the session store with
-	// a new expiry time.
-	if s.config.IdleTimeout > 0 {
+	// a new expiry time. The same applies to the expired values, see PutWithTTL.
+	if sd.deleteExpired(s.config.Clock.Now()) || s.config.IdleTimeout > 0 {
 		sd.status = Modified
 	}
 
-	return s.addSessionDataToContext(ctx, sd), nil
+	return sd, nil
+}
+
+// resolve retrieves the lazy loaded session data from the store.
+func (sd *sessionData) resolve(ctx context.Context) {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if sd.lazy == nil {
+		return
+	}
+
+	s := sd.lazy.session
+	found, err := s.find(ctx, sd.lazy.token)
+	if err != nil {
+		found = newSessionData(s.config.Clock.Now(), s.config.Lifetime)
+		sd.loadErr = err
+	}
+
+	sd.deadline = found.deadline
+	sd.status = found.status
+	sd.token = found.token
+	sd.values = found.values
+	sd.version = found.version
+	sd.lazy = nil
 }
 
 // Commit saves the session data to the session store and returns the session
-// token and expiry time.
+// token and expiry time. In the strict commit mode (see Config.StrictCommit) it
+// returns ErrConflict if the session has been committed by another request cycle
+// since it was loaded. The token of the StatelessStore is the sealed session data.
 func (s *Session) Commit(ctx context.Context) (string, time.Time, error) {
 	sd := s.getSessionDataFromContext(ctx)
 
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
+	if sd.loadErr != nil {
+		return "", time.Time{}, sd.loadErr
+	}
+
 	if sd.token == "" {
 		var err error
 		if sd.token, err = generateToken(); err != nil {
 			return "", time.Time{}, err
 		}
+		if s.config.RotateInterval > 0 {
+			sd.values[rotatedAtKey] = s.config.Clock.Now().UnixNano()
+		}
 	}
 
+	for k, v := range sd.bindings {
+		sd.values[k] = v
+	}
+	sd.deleteExpired(s.config.Clock.Now())
+
 	b, err := s.codec.Encode(sd.deadline, sd.values)
 	if err != nil {
 		return "", time.Time{}, err
@@ -130,13 +231,25 @@ func (s *Session) Commit(ctx context.Context) (string, time.Time, error) {
 
 	expiry := sd.deadline
 	if s.config.IdleTimeout > 0 {
-		ie := time.Now().Add(s.config.IdleTimeout).UTC()
+		ie := s.config.Clock.Now().Add(s.config.IdleTimeout).UTC()
 		if ie.Before(expiry) {
 			expiry = ie
 		}
 	}
 
-	if err := s.doStoreCommit(ctx, sd.token, b, expiry); err != nil {
+	if ss, ok := s.store.(StatelessStore); ok {
+		token, err := ss.Seal(ctx, b, expiry)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		sd.token = token
+	} else if s.config.StrictCommit {
+		version, err := s.doStoreCommitVersion(ctx, sd.token, b, expiry, sd.version)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		sd.version = version
+	} else if err := s.doStoreCommit(ctx, sd.token, b, expiry); err != nil {
 		return "", time.Time{}, err
 	}
 
@@ -152,6 +265,10 @@ func (s *Session) Destroy(ctx context.Context) error {
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
+	if !writable(ctx, sd) {
+		return ErrReadOnly
+	}
+
 	err := s.doStoreDelete(ctx, sd.token)
 	if err != nil {
 		return err
@@ -161,7 +278,8 @@ func (s *Session) Destroy(ctx context.Context) error {
 
 	// Reset everything else to defaults.
 	sd.token = ""
-	sd.deadline = time.Now().Add(s.config.Lifetime).UTC()
+	sd.version = 0
+	sd.deadline = s.config.Clock.Now().Add(s.config.Lifetime).UTC()
 	clear(sd.values)
 	return nil
 }
@@ -187,6 +305,10 @@ func (s *Session) SetDeadline(ctx context.Context, expire time.Time) {
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
+	if !writable(ctx, sd) {
+		return
+	}
+
 	sd.deadline = expire
 	sd.status = Modified
 }
@@ -208,24 +330,34 @@ func (s *Session) Get(ctx context.Context, key string) any {
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
+	if sd.expired(key, s.config.Clock.Now()) {
+		return nil
+	}
 	return sd.values[key]
 }
 
 // Pop acts like a one-time Get. It returns the value for a given key from the
 // session data and deletes the key and value from the session data. The
 // session data status will be set to Modified. The return value has the type
-// any so will usually need to be type asserted before you can use it.
+// any so will usually need to be type asserted before you can use it. Pop returns
+// nil for the read-only session, so the one-time values, ex. the flashes, are
+// never returned without being consumed.
 func (s *Session) Pop(ctx context.Context, key string) any {
 	sd := s.getSessionDataFromContext(ctx)
 
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
+	if sd.expired(key, s.config.Clock.Now()) {
+		return nil
+	}
+
 	val, exists := sd.values[key]
-	if !exists {
+	if !exists || !writable(ctx, sd) {
 		return nil
 	}
 	delete(sd.values, key)
+	delete(sd.values, ttlKeyPrefix+key)
 	sd.status = Modified
 
 	return val
@@ -240,11 +372,12 @@ func (s *Session) Remove(ctx context.Context, key string) {
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
-	if _, exists := sd.values[key]; !exists {
+	if _, exists := sd.values[key]; !exists || !writable(ctx, sd) {
 		return
 	}
 
 	delete(sd.values, key)
+	delete(sd.values, ttlKeyPrefix+key)
 	sd.status = Modified
 }
 
@@ -260,6 +393,9 @@ func (s *Session) Clear(ctx context.Context) error {
 	if len(sd.values) == 0 {
 		return nil
 	}
+	if !writable(ctx, sd) {
+		return ErrReadOnly
+	}
 
 	clear(sd.values)
 	sd.status = Modified
@@ -272,9 +408,10 @@ func (s *Session) Has(ctx context.Context, key string) bool {
 
 	sd.mu.Lock()
 	_, exists := sd.values[key]
+	expired := exists && sd.expired(key, s.config.Clock.Now())
 	sd.mu.Unlock()
 
-	return exists
+	return exists && !expired
 }
 
 // Keys returns a slice of all key names present in the session data, sorted
@@ -286,7 +423,15 @@ func (s *Session) Keys(ctx context.Context) []string {
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
-	return slices.Sorted(maps.Keys(sd.values))
+	now := s.config.Clock.Now()
+	keys := make([]string, 0, len(sd.values))
+	for key := range sd.values {
+		if !sd.expired(strings.TrimPrefix(key, ttlKeyPrefix), now) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
 }
 
 // Put adds a key and corresponding value to the session data. Any existing
@@ -296,16 +441,22 @@ func (s *Session) Put(ctx context.Context, key string, val any) {
 	sd := s.getSessionDataFromContext(ctx)
 
 	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if !writable(ctx, sd) {
+		return
+	}
+
 	sd.values[key] = val
+	delete(sd.values, ttlKeyPrefix+key)
 	sd.status = Modified
-	sd.mu.Unlock()
 }
 
 // RememberMe controls whether the session cookie is persistent (i.e  whether it
 // is retained after a user closes their browser). RememberMe only has an effect
 // if you have set config.Cookie.Persist = false.
 func (s *Session) RememberMe(ctx context.Context, val bool) {
-	s.Put(ctx, "__rememberMe", val)
+	s.Put(ctx, rememberMeKey, val)
 }
 
 // Token returns the session token. Please note that this will return the
@@ -326,7 +477,12 @@ func (s *Session) SetToken(ctx context.Context, token string) {
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
+	if !writable(ctx, sd) {
+		return
+	}
+
 	sd.token = token
+	sd.version = 0
 	sd.status = Modified
 }
 
@@ -346,6 +502,10 @@ func (s *Session) RenewToken(ctx context.Context) error {
 	sd.mu.Lock()
 	defer sd.mu.Unlock()
 
+	if !writable(ctx, sd) {
+		return ErrReadOnly
+	}
+
 	if sd.token != "" {
 		err := s.doStoreDelete(ctx, sd.token)
 		if err != nil {
@@ -358,9 +518,15 @@ func (s *Session) RenewToken(ctx context.Context) error {
 		return err
 	}
 
+	now := s.config.Clock.Now()
+
 	sd.token = newToken
-	sd.deadline = time.Now().Add(s.config.Lifetime).UTC()
+	sd.version = 0
+	sd.deadline = now.Add(s.config.Lifetime).UTC()
 	sd.status = Modified
+	if s.config.RotateInterval > 0 {
+		sd.values[rotatedAtKey] = now.UnixNano()
+	}
 
 	return nil
 }
@@ -390,6 +556,9 @@ func (s *Session) MergeSession(ctx context.Context, token string) error {
 	if sd.token == token {
 		return nil
 	}
+	if !writable(ctx, sd) {
+		return ErrReadOnly
+	}
 
 	if deadline.After(sd.deadline) {
 		sd.deadline = deadline
@@ -748,6 +917,7 @@ func (s *Session) getSessionDataFromContext(ctx context.Context) *sessionData {
 	if !ok {
 		panic("scs: no session data in context")
 	}
+	c.loadOnce.Do(func() { c.resolve(ctx) })
 	return c
 }
 
@@ -765,6 +935,24 @@ func (s *Session) doStoreFind(ctx context.Context, token string) (b []byte, foun
 	return s.store.Find(ctx, token)
 }
 
+func (s *Session) doStoreFindVersion(ctx context.Context, token string) (b []byte, version uint64, found bool, err error) {
+	if !s.config.StrictCommit {
+		b, found, err = s.doStoreFind(ctx, token)
+		return b, 0, found, err
+	}
+	if s.config.HashTokenInStore {
+		token = hashToken(token)
+	}
+	return s.store.(VersionedStore).FindVersion(ctx, token)
+}
+
+func (s *Session) doStoreCommitVersion(ctx context.Context, token string, b []byte, expiry time.Time, version uint64) (uint64, error) {
+	if s.config.HashTokenInStore {
+		token = hashToken(token)
+	}
+	return s.store.(VersionedStore).CommitVersion(ctx, token, b, expiry, version)
+}
+
 func (s *Session) doStoreCommit(ctx context.Context, token string, b []byte, expiry time.Time) (err error) {
 	if s.config.HashTokenInStore {
 		token = hashToken(token)
@@ -8,6 +8,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
 )
 
 // Test setup helpers
@@ -52,7 +54,7 @@ func setupTestSessionWithData() (*Session, context.Context, error) {
 // Utility function tests
 func TestNewSessionData(t *testing.T) {
 	lifetime := 2 * time.Hour
-	sd := newSessionData(lifetime)
+	sd := newSessionData(time.Now(), lifetime)
 
 	assert.NotNil(t, sd)
 	assert.Equal(t, Unmodified, sd.status)
@@ -806,3 +808,106 @@ func TestDoStoreMethods(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadLazy(t *testing.T) {
+	mockStore := &MockStore{}
+	mockCodec := &MockCodec{}
+	session := NewWithCodec(Config{}, mockStore, mockCodec)
+
+	deadline := time.Now().Add(time.Hour)
+	mockStore.On("Find", mock.Anything, "test-token").Return([]byte("data"), true, nil).Once()
+	mockCodec.On("Decode", []byte("data")).Return(deadline, map[string]any{"key": "value"}, nil).Once()
+
+	ctx := session.LoadLazy(context.Background(), "test-token")
+	assert.False(t, session.Loaded(ctx))
+	mockStore.AssertNotCalled(t, "Find", mock.Anything, mock.Anything)
+
+	assert.Equal(t, "value", session.Get(ctx, "key"))
+	assert.True(t, session.Loaded(ctx))
+	assert.Equal(t, "test-token", session.Token(ctx))
+	assert.Equal(t, deadline, session.Deadline(ctx))
+
+	// already loaded
+	assert.Equal(t, ctx, session.LoadLazy(ctx, "other-token"))
+
+	mockStore.AssertExpectations(t)
+	mockCodec.AssertExpectations(t)
+}
+
+func TestLoadLazy_NoToken(t *testing.T) {
+	session := New(Config{}, &MockStore{})
+
+	ctx := session.LoadLazy(context.Background(), "")
+
+	assert.True(t, session.Loaded(ctx))
+	assert.Empty(t, session.Keys(ctx))
+}
+
+func TestSession_Clock(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewMock(now)
+
+	mockStore := &MockStore{}
+	mockCodec := &MockCodec{}
+	session := NewWithCodec(Config{Lifetime: time.Hour, IdleTimeout: 10 * time.Minute, Clock: clk}, mockStore, mockCodec)
+
+	ctx, err := session.Load(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), session.Deadline(ctx))
+
+	mockCodec.On("Encode", mock.Anything, mock.Anything).Return([]byte("data"), nil)
+	mockStore.On("Commit", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	clk.Add(55 * time.Minute)
+	_, expiry, err := session.Commit(ctx)
+	require.NoError(t, err)
+	// the idle timeout is capped by the deadline
+	assert.Equal(t, now.Add(time.Hour), expiry)
+
+	clk.Set(now)
+	_, expiry, err = session.Commit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(10*time.Minute), expiry)
+	assert.Equal(t, now, session.Now())
+}
+
+func TestSession_StrictCommit(t *testing.T) {
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	session := New(Config{StrictCommit: true}, store)
+
+	ctx, err := session.Load(context.Background(), "")
+	require.NoError(t, err)
+	session.Put(ctx, "n", 1)
+	token, _, err := session.Commit(ctx)
+	require.NoError(t, err)
+
+	// two request cycles load the same session concurrently
+	ctx1, err := session.Load(context.Background(), token)
+	require.NoError(t, err)
+	ctx2, err := session.Load(context.Background(), token)
+	require.NoError(t, err)
+
+	session.Put(ctx1, "n", 2)
+	_, _, err = session.Commit(ctx1)
+	require.NoError(t, err)
+
+	// the committed session can be committed again
+	_, _, err = session.Commit(ctx1)
+	require.NoError(t, err)
+
+	session.Put(ctx2, "n", 3)
+	_, _, err = session.Commit(ctx2)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	ctx3, err := session.Load(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, 2, session.GetInt(ctx3, "n"))
+}
+
+func TestSession_StrictCommit_Store(t *testing.T) {
+	assert.PanicsWithValue(t, "session: strict commit requires a versioned store", func() {
+		New(Config{StrictCommit: true}, &MockStore{})
+	})
+}
@@ -0,0 +1,177 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// CleanupStore is the optional interface of the stores deleting the expired sessions on demand,
+// ex. the SQL stores, so a single managed [Janitor] cleans them all up.
+type CleanupStore interface {
+	Store
+
+	// Cleanup deletes the expired sessions.
+	Cleanup(ctx context.Context) error
+}
+
+type JanitorConfig struct {
+	// Interval is the interval of the cleanups.
+	//
+	// Default: 5m
+	Interval time.Duration `env:"INTERVAL" json:"interval,omitempty,format:units" yaml:"interval,omitempty"`
+
+	// Jitter is the maximum random delay added to each interval, so the nodes of the cluster
+	// don't clean up at once.
+	//
+	// Default: Interval / 10
+	Jitter time.Duration `env:"JITTER" json:"jitter,omitempty,format:units" yaml:"jitter,omitempty"`
+
+	// Timeout is the timeout of a single cleanup of all the stores.
+	//
+	// Default: Interval
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// Leader reports whether the node is the leader of the cluster, ex. by the advisory lock
+	// of the database or kv.Locker.Leader, only the leader cleans up the shared stores.
+	// The periodic cleanups call it with the context canceled by [Janitor.Stop], not with
+	// the one of the cleanup, so the leadership spans them until the janitor is stopped.
+	// By default every node does.
+	Leader func(ctx context.Context) (bool, error) `json:"-" yaml:"-"`
+
+	// OnError is called with the cleanup errors, ex. to log them.
+	OnError func(err error) `json:"-" yaml:"-"`
+}
+
+func (c *JanitorConfig) SetDefaults() {
+	if c.Interval == 0 {
+		c.Interval = 5 * time.Minute
+	}
+	if c.Jitter == 0 {
+		c.Jitter = c.Interval / 10
+	}
+	if c.Timeout == 0 {
+		c.Timeout = c.Interval
+	}
+}
+
+func (c *JanitorConfig) Validate() error {
+	if c.Interval < 0 || c.Jitter < 0 || c.Timeout < 0 {
+		return errors.New("session: janitor durations must not be negative")
+	}
+	return nil
+}
+
+// Janitor deletes the expired sessions of the stores periodically, instead of each store
+// running its own goroutine. Start and stop it with the server:
+//
+//	janitor := session.NewJanitor(session.JanitorConfig{OnError: logCleanupError}, store)
+//	srv.RegisterOnStart(janitor.Start)
+//	srv.RegisterOnShutdown(janitor.Stop)
+type Janitor struct {
+	config JanitorConfig
+	stores []CleanupStore
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewJanitor(cfg JanitorConfig, stores ...CleanupStore) *Janitor {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &Janitor{config: cfg, stores: stores}
+}
+
+// Start starts the periodic cleanups, it's a no-op if they are already started.
+func (j *Janitor) Start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel, j.done = cancel, make(chan struct{})
+
+	go j.run(ctx, j.done)
+}
+
+// Stop stops the periodic cleanups and waits for the running one to return, or the context
+// to be done. The janitor can be started again.
+func (j *Janitor) Stop(ctx context.Context) error {
+	j.mu.Lock()
+	cancel, done := j.cancel, j.done
+	j.cancel, j.done = nil, nil
+	j.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Cleanup cleans up the stores once if the node is the leader, see JanitorConfig.Leader.
+func (j *Janitor) Cleanup(ctx context.Context) error {
+	return j.cleanup(ctx, ctx)
+}
+
+// cleanup cleans up the stores with ctx if the node is the leader of leaderCtx.
+func (j *Janitor) cleanup(leaderCtx, ctx context.Context) error {
+	if j.config.Leader != nil {
+		leader, err := j.config.Leader(leaderCtx)
+		if err != nil || !leader {
+			return err
+		}
+	}
+
+	var err error
+	for _, store := range j.stores {
+		err = errors.Join(err, store.Cleanup(ctx))
+	}
+	return err
+}
+
+func (j *Janitor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	timer := time.NewTimer(j.next())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		cleanupCtx, cancel := context.WithTimeout(ctx, j.config.Timeout)
+		if err := j.cleanup(ctx, cleanupCtx); err != nil && j.config.OnError != nil && ctx.Err() == nil {
+			j.config.OnError(err)
+		}
+		cancel()
+
+		timer.Reset(j.next())
+	}
+}
+
+func (j *Janitor) next() time.Duration {
+	if j.config.Jitter <= 0 {
+		return j.config.Interval
+	}
+	return j.config.Interval + rand.N(j.config.Jitter)
+}
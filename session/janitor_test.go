@@ -0,0 +1,119 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+type countingStore struct {
+	*MemoryStore
+	calls atomic.Int32
+	err   error
+}
+
+func (s *countingStore) Cleanup(ctx context.Context) error {
+	s.calls.Add(1)
+	if s.err != nil {
+		return s.err
+	}
+	return s.MemoryStore.Cleanup(ctx)
+}
+
+func TestJanitor(t *testing.T) {
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	memory := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	failing := &countingStore{MemoryStore: NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1}), err: errors.New("db down")}
+
+	ctx := context.Background()
+	require.NoError(t, memory.Commit(ctx, "a", []byte("a"), clk.Now().Add(time.Minute)))
+	clk.Add(time.Minute)
+
+	errs := make(chan error, 10)
+	j := NewJanitor(JanitorConfig{
+		Interval: 10 * time.Millisecond,
+		OnError:  func(err error) { errs <- err },
+	}, memory, failing)
+
+	j.Start()
+	j.Start()
+
+	select {
+	case err := <-errs:
+		assert.EqualError(t, err, "db down")
+	case <-time.After(5 * time.Second):
+		t.Fatal("cleanup is not run")
+	}
+
+	require.NoError(t, j.Stop(ctx))
+	require.NoError(t, j.Stop(ctx))
+
+	assert.Equal(t, MemoryStoreStats{Expirations: 1}, memory.Stats())
+
+	calls := failing.calls.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, calls, failing.calls.Load())
+}
+
+func TestJanitor_Leader(t *testing.T) {
+	store := &countingStore{MemoryStore: NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})}
+
+	var leader atomic.Bool
+	j := NewJanitor(JanitorConfig{
+		Leader: func(context.Context) (bool, error) { return leader.Load(), nil },
+	}, store)
+
+	require.NoError(t, j.Cleanup(context.Background()))
+	assert.Zero(t, store.calls.Load())
+
+	leader.Store(true)
+	require.NoError(t, j.Cleanup(context.Background()))
+	assert.Equal(t, int32(1), store.calls.Load())
+
+	j = NewJanitor(JanitorConfig{
+		Leader: func(context.Context) (bool, error) { return false, errors.New("lock failed") },
+	}, store)
+	assert.EqualError(t, j.Cleanup(context.Background()), "lock failed")
+}
+
+func TestJanitor_LeaderContext(t *testing.T) {
+	store := &countingStore{MemoryStore: NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})}
+
+	leaderCtx := make(chan context.Context, 1)
+	j := NewJanitor(JanitorConfig{
+		Interval: 10 * time.Millisecond,
+		Timeout:  time.Millisecond,
+		Leader: func(ctx context.Context) (bool, error) {
+			select {
+			case leaderCtx <- ctx:
+			default:
+			}
+			return true, nil
+		},
+	}, store)
+
+	j.Start()
+	ctx := <-leaderCtx
+
+	// the leadership spans the cleanups until the janitor is stopped
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, ctx.Err())
+
+	require.NoError(t, j.Stop(context.Background()))
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestJanitorConfig(t *testing.T) {
+	cfg := JanitorConfig{Interval: time.Minute}
+	cfg.SetDefaults()
+	assert.Equal(t, JanitorConfig{Interval: time.Minute, Jitter: 6 * time.Second, Timeout: time.Minute}, cfg)
+
+	assert.Panics(t, func() { NewJanitor(JanitorConfig{Interval: -1}) })
+}
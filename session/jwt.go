@@ -0,0 +1,298 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gowool/wo/clock"
+)
+
+var _ StatelessStore = (*JWTStore)(nil)
+
+// deadlineClaim is the claim of the absolute session deadline (unix seconds), the exp claim is
+// the expiry of the token, which is earlier with Config.IdleTimeout.
+const deadlineClaim = "deadline"
+
+// internalClaim is the claim of the internal values, see [InternalKeyPrefix], so they don't
+// mix with the claims of the session values read by the other services.
+const internalClaim = InternalKeyPrefix + "internal"
+
+// jwtClaims are the registered claims, which the session values can't use.
+var jwtClaims = []string{"exp", "iat", "nbf", "iss", "aud", "sub", "jti", deadlineClaim}
+
+// JWTCodec encodes the session data as the JSON claims of the JWT, see [JWTStore]. The values
+// must be the JSON ones, and are decoded as such: strings, bools, ints, float64s, []any and
+// map[string]any, ex. time.Time is decoded as the RFC 3339 string, and []byte as the base64 one.
+// The keys of the values can't be the registered claims, ex. "exp". The internal values are
+// kept in the "__internal" claim by the keys without [InternalKeyPrefix].
+type JWTCodec struct{}
+
+func NewJWTCodec() JWTCodec {
+	return JWTCodec{}
+}
+
+func (JWTCodec) Encode(deadline time.Time, values map[string]any) ([]byte, error) {
+	claims := make(map[string]any, len(values)+1)
+	internal := make(map[string]any)
+	for k, v := range values {
+		if key, ok := strings.CutPrefix(k, InternalKeyPrefix); ok {
+			internal[key] = v
+			continue
+		}
+		if slices.Contains(jwtClaims, k) {
+			return nil, fmt.Errorf("session: %q is the registered jwt claim", k)
+		}
+		claims[k] = v
+	}
+	if len(internal) > 0 {
+		claims[internalClaim] = internal
+	}
+	claims[deadlineClaim] = deadline.Unix()
+
+	return json.Marshal(claims)
+}
+
+func (JWTCodec) Decode(b []byte) (time.Time, map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var claims map[string]any
+	if err := dec.Decode(&claims); err != nil {
+		return time.Time{}, nil, err
+	}
+
+	deadline, ok := claims[deadlineClaim].(json.Number)
+	if !ok {
+		return time.Time{}, nil, errors.New("session: missing deadline claim")
+	}
+	sec, err := deadline.Int64()
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	for _, claim := range jwtClaims {
+		delete(claims, claim)
+	}
+	internal, _ := claims[internalClaim].(map[string]any)
+	delete(claims, internalClaim)
+	for k, v := range internal {
+		claims[InternalKeyPrefix+k] = v
+	}
+	for k, v := range claims {
+		claims[k] = jsonValue(v)
+	}
+	return time.Unix(sec, 0).UTC(), claims, nil
+}
+
+// jsonValue replaces the JSON numbers with the ints, or the float64s if they aren't integers.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil && i >= math.MinInt && i <= math.MaxInt {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = jsonValue(v[k])
+		}
+	}
+	return v
+}
+
+// JWTKey is the HS256 signing key of the [JWTStore], the ID is the kid header of the tokens,
+// so the key is found on verification.
+type JWTKey struct {
+	ID     string
+	Secret []byte
+}
+
+type JWTStoreConfig struct {
+	// Issuer is the iss claim of the tokens, verified if set.
+	Issuer string `env:"ISSUER" json:"issuer,omitempty" yaml:"issuer,omitempty"`
+
+	// Audience is the aud claim of the tokens, verified if set.
+	Audience string `env:"AUDIENCE" json:"audience,omitempty" yaml:"audience,omitempty"`
+
+	// MaxChunks is the maximum number of the cookies the token is split across,
+	// each up to 3800 bytes.
+	//
+	// Default: 4
+	MaxChunks int `env:"MAX_CHUNKS" json:"maxChunks,omitempty" yaml:"maxChunks,omitempty"`
+
+	// Clock is the clock of the expiry.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+func (c *JWTStoreConfig) SetDefaults() {
+	if c.MaxChunks == 0 {
+		c.MaxChunks = 4
+	}
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+}
+
+func (c *JWTStoreConfig) Validate() error {
+	if c.MaxChunks < 1 {
+		return errors.New("session: max chunks must be positive")
+	}
+	return nil
+}
+
+// JWTStore keeps the session data in the session token, the HS256 signed JWT whose claims are
+// the session values encoded by [JWTCodec], for the stateless sessions readable by the other
+// services sharing the key. The exp claim is the session expiry, the absolute deadline or the
+// idle timeout (see Config.IdleTimeout), whichever is earlier. The claims are signed, not
+// encrypted, use [CookieStore] for the secret values. The keys are rotated by adding the new key
+// in front of the old ones: the first key signs, all of them verify.
+//
+// Like [CookieStore], the sessions can't be revoked on the server.
+//
+//	store := session.NewJWTStore(session.JWTStoreConfig{Issuer: "app"}, session.JWTKey{ID: "2024", Secret: secret})
+//	s := session.NewWithCodec(session.Config{IdleTimeout: 30 * time.Minute}, store, session.NewJWTCodec())
+type JWTStore struct {
+	config JWTStoreConfig
+	keys   []JWTKey
+}
+
+func NewJWTStore(cfg JWTStoreConfig, keys ...JWTKey) *JWTStore {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+	if len(keys) == 0 {
+		panic("session: jwt store requires at least one key")
+	}
+	for _, key := range keys {
+		if len(key.Secret) < 32 {
+			panic("session: jwt store key must be at least 32 bytes")
+		}
+	}
+
+	return &JWTStore{config: cfg, keys: keys}
+}
+
+// Seal signs the claims encoded by [JWTCodec] with the expiry as the exp claim.
+func (s *JWTStore) Seal(_ context.Context, data []byte, expiry time.Time) (string, error) {
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return "", fmt.Errorf("session: jwt store requires the jwt codec: %w", err)
+	}
+
+	claims["exp"], _ = json.Marshal(expiry.Unix())
+	claims["iat"], _ = json.Marshal(s.config.Clock.Now().Unix())
+	if s.config.Issuer != "" {
+		claims["iss"], _ = json.Marshal(s.config.Issuer)
+	}
+	if s.config.Audience != "" {
+		claims["aud"], _ = json.Marshal(s.config.Audience)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	key := s.keys[0]
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT", "kid": key.ID})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(key.Secret, unsigned))
+	if len(token) > s.config.MaxChunks*cookieChunkSize {
+		return "", ErrCookieTooLarge
+	}
+	return token, nil
+}
+
+// Find verifies the token and returns its claims, the tokens with the invalid signature,
+// the malformed, the expired ones, or the ones of the other issuer or audience aren't found.
+func (s *JWTStore) Find(_ context.Context, token string) ([]byte, bool, error) {
+	if token == "" || len(token) > s.config.MaxChunks*cookieChunkSize {
+		return nil, false, nil
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false, nil
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if b, err := base64.RawURLEncoding.DecodeString(parts[0]); err != nil || json.Unmarshal(b, &header) != nil || header.Alg != "HS256" {
+		return nil, false, nil
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, false, nil
+	}
+
+	unsigned := parts[0] + "." + parts[1]
+	verified := slices.ContainsFunc(s.keys, func(key JWTKey) bool {
+		return key.ID == header.Kid && hmac.Equal(signature, sign(key.Secret, unsigned))
+	})
+	if !verified {
+		return nil, false, nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, false, nil
+	}
+
+	var claims struct {
+		Exp int64  `json:"exp"`
+		Iss string `json:"iss"`
+		Aud string `json:"aud"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, false, nil
+	}
+	if s.config.Clock.Now().Unix() >= claims.Exp ||
+		(s.config.Issuer != "" && claims.Iss != s.config.Issuer) ||
+		(s.config.Audience != "" && claims.Aud != s.config.Audience) {
+		return nil, false, nil
+	}
+
+	return payload, true, nil
+}
+
+// Commit is a no-op, the session data is kept in the session token, see Seal.
+func (s *JWTStore) Commit(context.Context, string, []byte, time.Time) error {
+	return nil
+}
+
+// Delete is a no-op, the signed session tokens can't be revoked.
+func (s *JWTStore) Delete(context.Context, string) error {
+	return nil
+}
+
+func sign(secret []byte, unsigned string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
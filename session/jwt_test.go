@@ -0,0 +1,205 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+var testJWTKey = JWTKey{ID: "1", Secret: bytes.Repeat([]byte("s"), 32)}
+
+func TestJWTCodec(t *testing.T) {
+	codec := NewJWTCodec()
+	deadline := time.Unix(1_700_000_000, 0).UTC()
+
+	b, err := codec.Encode(deadline, map[string]any{
+		"user":              "ann",
+		"id":                42,
+		"ratio":             0.5,
+		"admin":             true,
+		"roles":             []string{"a", "b"},
+		bindIPKey:           "192.0.2.1",
+		ttlKeyPrefix + "id": int64(1_700_000_060_000_000_000),
+	})
+	require.NoError(t, err)
+
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(b, &claims))
+	assert.NotContains(t, claims, bindIPKey)
+	assert.Equal(t, map[string]any{"bindIP": "192.0.2.1", "ttl.id": 1.70000006e+18}, claims["__internal"])
+
+	got, values, err := codec.Decode(b)
+	require.NoError(t, err)
+	assert.Equal(t, deadline, got)
+	assert.Equal(t, map[string]any{
+		"user":              "ann",
+		"id":                42,
+		"ratio":             0.5,
+		"admin":             true,
+		"roles":             []any{"a", "b"},
+		bindIPKey:           "192.0.2.1",
+		ttlKeyPrefix + "id": 1_700_000_060_000_000_000,
+	}, values)
+
+	_, err = codec.Encode(deadline, map[string]any{"exp": 1})
+	assert.Error(t, err)
+
+	_, _, err = codec.Decode([]byte(`{"user":"ann"}`))
+	assert.Error(t, err)
+}
+
+func TestJWTStore(t *testing.T) {
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	store := NewJWTStore(JWTStoreConfig{Issuer: "app", Clock: clk}, testJWTKey)
+	s := NewWithCodec(Config{Lifetime: time.Hour, IdleTimeout: 10 * time.Minute, Clock: clk}, store, NewJWTCodec())
+
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+	s.Put(ctx, "user", "ann")
+	s.Put(ctx, "id", 42)
+
+	token, expiry, err := s.Commit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, clk.Now().Add(10*time.Minute).UTC(), expiry)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	assert.Equal(t, "ann", claims["user"])
+	assert.Equal(t, "app", claims["iss"])
+	assert.InDelta(t, expiry.Unix(), claims["exp"], 0)
+	assert.InDelta(t, clk.Now().Add(time.Hour).Unix(), claims["deadline"], 0)
+
+	// the same Session API
+	clk.Add(5 * time.Minute)
+	ctx, err = s.Load(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "ann", s.GetString(ctx, "user"))
+	assert.Equal(t, 42, s.GetInt(ctx, "id"))
+	assert.Equal(t, Modified, s.Status(ctx))
+
+	// the idle timeout is exp
+	clk.Add(10 * time.Minute)
+	ctx, err = s.Load(context.Background(), token)
+	require.NoError(t, err)
+	assert.Empty(t, s.GetString(ctx, "user"))
+
+	// tampered
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"user":"admin","exp":9999999999,"deadline":9999999999}`)) + "." + parts[2]
+	_, found, err := store.Find(context.Background(), tampered)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestJWTStore_Verification(t *testing.T) {
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	oldKey := JWTKey{ID: "0", Secret: bytes.Repeat([]byte("o"), 32)}
+	data, err := NewJWTCodec().Encode(clk.Now().Add(time.Hour), map[string]any{"user": "ann"})
+	require.NoError(t, err)
+
+	seal := func(store *JWTStore) string {
+		token, err := store.Seal(context.Background(), data, clk.Now().Add(time.Hour))
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		store    *JWTStore
+		expected bool
+	}{
+		{
+			name:     "valid",
+			token:    seal(NewJWTStore(JWTStoreConfig{Clock: clk}, testJWTKey)),
+			store:    NewJWTStore(JWTStoreConfig{Clock: clk}, testJWTKey),
+			expected: true,
+		},
+		{
+			name:     "rotated key",
+			token:    seal(NewJWTStore(JWTStoreConfig{Clock: clk}, oldKey)),
+			store:    NewJWTStore(JWTStoreConfig{Clock: clk}, testJWTKey, oldKey),
+			expected: true,
+		},
+		{
+			name:  "unknown key",
+			token: seal(NewJWTStore(JWTStoreConfig{Clock: clk}, oldKey)),
+			store: NewJWTStore(JWTStoreConfig{Clock: clk}, testJWTKey),
+		},
+		{
+			name:  "other issuer",
+			token: seal(NewJWTStore(JWTStoreConfig{Issuer: "other", Clock: clk}, testJWTKey)),
+			store: NewJWTStore(JWTStoreConfig{Issuer: "app", Clock: clk}, testJWTKey),
+		},
+		{
+			name:  "other audience",
+			token: seal(NewJWTStore(JWTStoreConfig{Audience: "other", Clock: clk}, testJWTKey)),
+			store: NewJWTStore(JWTStoreConfig{Audience: "app", Clock: clk}, testJWTKey),
+		},
+		{
+			name:  "none algorithm",
+			token: base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"1"}`)) + "." + base64.RawURLEncoding.EncodeToString(data) + ".",
+			store: NewJWTStore(JWTStoreConfig{Clock: clk}, testJWTKey),
+		},
+		{
+			name:  "malformed",
+			token: "a.b",
+			store: NewJWTStore(JWTStoreConfig{Clock: clk}, testJWTKey),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, found, err := tt.store.Find(context.Background(), tt.token)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, found)
+		})
+	}
+}
+
+func TestJWTStore_Cookie(t *testing.T) {
+	s := NewWithCodec(Config{RotateInterval: time.Minute}, NewJWTStore(JWTStoreConfig{}, testJWTKey), NewJWTCodec())
+
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+	s.PutWithTTL(ctx, "otp", "123456", time.Hour)
+
+	r, _ := roundTrip(t, s, ctx)
+	r, err = s.ReadSessionCookie(r)
+	require.NoError(t, err)
+	assert.Equal(t, "123456", s.GetString(r.Context(), "otp"))
+
+	// the internal values survive the JSON round trip
+	renewed, err := s.RenewTokenIfNeeded(r.Context())
+	require.NoError(t, err)
+	assert.False(t, renewed)
+
+	_, _, err = s.Commit(r.Context())
+	require.NoError(t, err)
+
+	// the gob codec is not supported
+	gob := New(Config{}, NewJWTStore(JWTStoreConfig{}, testJWTKey))
+	ctx, err = gob.Load(context.Background(), "")
+	require.NoError(t, err)
+	gob.Put(ctx, "user", "ann")
+	_, _, err = gob.Commit(ctx)
+	assert.Error(t, err)
+}
+
+func TestNewJWTStore_Invalid(t *testing.T) {
+	assert.Panics(t, func() { NewJWTStore(JWTStoreConfig{}) })
+	assert.Panics(t, func() { NewJWTStore(JWTStoreConfig{}, JWTKey{ID: "1", Secret: []byte("short")}) })
+	assert.Panics(t, func() { NewJWTStore(JWTStoreConfig{MaxChunks: -1}, testJWTKey) })
+}
@@ -0,0 +1,377 @@
+package session
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gowool/wo/clock"
+	"github.com/gowool/wo/internal/arr"
+)
+
+var (
+	_ VersionedStore = (*MemoryStore)(nil)
+	_ CleanupStore   = (*MemoryStore)(nil)
+)
+
+type MemoryStoreConfig struct {
+	// Shards is the number of the independently locked shards the sessions are spread across.
+	//
+	// Default: 32
+	Shards int `env:"SHARDS" json:"shards,omitempty" yaml:"shards,omitempty"`
+
+	// MaxEntries is the maximum number of the stored sessions, the least recently used
+	// sessions are evicted once it's exceeded, the anonymous ones first, see Anonymous.
+	// The order is least recently used per shard, the shards are visited in turn.
+	// If zero, the store is unbounded.
+	MaxEntries int `env:"MAX_ENTRIES" json:"maxEntries,omitempty" yaml:"maxEntries,omitempty"`
+
+	// Anonymous reports whether the session data is of an anonymous session, ex. without
+	// the user ID, see [AnonymousWithout]. The anonymous sessions are evicted first once
+	// MaxEntries is exceeded.
+	//
+	// Default: nil, the sessions are evicted in the least recently used order only
+	Anonymous func(data []byte) bool `json:"-" yaml:"-"`
+
+	// CleanupInterval is the interval of the own janitor goroutine of the store deleting
+	// the expired sessions. If not positive, the janitor is not started and the expired sessions
+	// are deleted only when they are found or evicted, or by the managed [Janitor], which
+	// is preferred.
+	//
+	// Optional.
+	CleanupInterval time.Duration `env:"CLEANUP_INTERVAL" json:"cleanupInterval,omitempty,format:units" yaml:"cleanupInterval,omitempty"`
+
+	// Clock is the clock of the expiry.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+func (c *MemoryStoreConfig) SetDefaults() {
+	if c.Shards <= 0 {
+		c.Shards = 32
+	}
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+}
+
+func (c *MemoryStoreConfig) Validate() error {
+	if c.MaxEntries < 0 {
+		return errors.New("session: max entries must not be negative")
+	}
+	return nil
+}
+
+// AnonymousWithout returns the MemoryStoreConfig.Anonymous reporting the session data without
+// the key as anonymous, ex. the user ID put on the login. The data the codec fails to decode is
+// anonymous.
+//
+//	session.NewMemoryStore(session.MemoryStoreConfig{
+//		MaxEntries: 100_000,
+//		Anonymous:  session.AnonymousWithout(session.NewGobCodec(), "userID"),
+//	})
+func AnonymousWithout(codec Codec, key string) func(data []byte) bool {
+	return func(data []byte) bool {
+		_, values, err := codec.Decode(data)
+		if err != nil {
+			return true
+		}
+		_, ok := values[key]
+		return !ok
+	}
+}
+
+// MemoryStoreStats are the counters of the [MemoryStore], ex. for the metrics.
+type MemoryStoreStats struct {
+	// Active is the number of the stored sessions, the expired ones not yet deleted included.
+	Active int64 `json:"active"`
+
+	// Evictions is the number of the sessions evicted because of the MaxEntries bound.
+	Evictions uint64 `json:"evictions"`
+
+	// Expirations is the number of the expired sessions deleted.
+	Expirations uint64 `json:"expirations"`
+}
+
+type memEntry struct {
+	token     string
+	data      []byte
+	expiry    time.Time
+	version   uint64
+	anonymous bool
+}
+
+type memShard struct {
+	mu    sync.Mutex
+	items map[string]*list.Element
+	lru   *list.List // front is the most recently used
+	anon  *list.List // the anonymous sessions, front is the most recently used
+}
+
+// list returns the LRU list of the entry.
+func (sh *memShard) list(entry *memEntry) *list.List {
+	if entry.anonymous {
+		return sh.anon
+	}
+	return sh.lru
+}
+
+// MemoryStore is the in-memory [Store] for a single instance deployment, the sessions are lost on restart.
+// The sessions are spread across the shards to reduce the lock contention, bounded by MaxEntries
+// with the LRU eviction and deleted by the managed [Janitor], see [MemoryStore.Cleanup], or by
+// the own janitor of the store once expired, see MemoryStoreConfig.CleanupInterval. Stop the own
+// janitor when the store is no longer used.
+type MemoryStore struct {
+	cfg    MemoryStoreConfig
+	seed   maphash.Seed
+	shards []*memShard
+
+	active      atomic.Int64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func NewMemoryStore(cfg MemoryStoreConfig) *MemoryStore {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	s := &MemoryStore{
+		cfg:    cfg,
+		seed:   maphash.MakeSeed(),
+		shards: make([]*memShard, cfg.Shards),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i := range s.shards {
+		s.shards[i] = &memShard{items: make(map[string]*list.Element), lru: list.New(), anon: list.New()}
+	}
+
+	if cfg.CleanupInterval > 0 {
+		go s.janitor(cfg.CleanupInterval)
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+func (s *MemoryStore) Find(ctx context.Context, token string) ([]byte, bool, error) {
+	data, _, found, err := s.FindVersion(ctx, token)
+	return data, found, err
+}
+
+func (s *MemoryStore) FindVersion(_ context.Context, token string) ([]byte, uint64, bool, error) {
+	sh := s.shard(token)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	el, ok := s.lookup(sh, token)
+	if !ok {
+		return nil, 0, false, nil
+	}
+
+	entry := el.Value.(*memEntry)
+	sh.list(entry).MoveToFront(el)
+	return arr.Copy(entry.data), entry.version, true, nil
+}
+
+func (s *MemoryStore) Commit(_ context.Context, token string, data []byte, expiry time.Time) error {
+	i := s.shardIndex(token)
+	sh := s.shards[i]
+
+	sh.mu.Lock()
+	s.commit(sh, token, data, expiry)
+	sh.mu.Unlock()
+
+	s.evict(i)
+	return nil
+}
+
+func (s *MemoryStore) CommitVersion(_ context.Context, token string, data []byte, expiry time.Time, version uint64) (uint64, error) {
+	i := s.shardIndex(token)
+	sh := s.shards[i]
+
+	sh.mu.Lock()
+
+	var current uint64
+	if el, ok := s.lookup(sh, token); ok {
+		current = el.Value.(*memEntry).version
+	}
+	if current != version {
+		sh.mu.Unlock()
+		return 0, ErrConflict
+	}
+
+	version = s.commit(sh, token, data, expiry)
+	sh.mu.Unlock()
+
+	s.evict(i)
+	return version, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, token string) error {
+	sh := s.shard(token)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if el, ok := sh.items[token]; ok {
+		s.remove(sh, el)
+	}
+	return nil
+}
+
+// Stats returns the current counters of the store.
+func (s *MemoryStore) Stats() MemoryStoreStats {
+	return MemoryStoreStats{
+		Active:      s.active.Load(),
+		Evictions:   s.evictions.Load(),
+		Expirations: s.expirations.Load(),
+	}
+}
+
+// Cleanup deletes the expired sessions, it's called by the janitor periodically, see
+// MemoryStoreConfig.CleanupInterval and [Janitor].
+func (s *MemoryStore) Cleanup(ctx context.Context) error {
+	now := s.cfg.Clock.Now()
+
+	for _, sh := range s.shards {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		sh.mu.Lock()
+		for _, l := range []*list.List{sh.anon, sh.lru} {
+			for el := l.Front(); el != nil; {
+				next := el.Next()
+				if !now.Before(el.Value.(*memEntry).expiry) {
+					s.remove(sh, el)
+					s.expirations.Add(1)
+				}
+				el = next
+			}
+		}
+		sh.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop stops the janitor and waits for it to return. It's safe to call Stop more than once,
+// the store remains usable after it.
+func (s *MemoryStore) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+}
+
+func (s *MemoryStore) janitor(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			_ = s.Cleanup(context.Background())
+		}
+	}
+}
+
+func (s *MemoryStore) shard(token string) *memShard {
+	return s.shards[s.shardIndex(token)]
+}
+
+func (s *MemoryStore) shardIndex(token string) int {
+	return int(maphash.String(s.seed, token) % uint64(len(s.shards)))
+}
+
+// lookup returns the unexpired session of the token, the expired one is deleted.
+func (s *MemoryStore) lookup(sh *memShard, token string) (*list.Element, bool) {
+	el, ok := sh.items[token]
+	if !ok {
+		return nil, false
+	}
+
+	if !s.cfg.Clock.Now().Before(el.Value.(*memEntry).expiry) {
+		s.remove(sh, el)
+		s.expirations.Add(1)
+		return nil, false
+	}
+	return el, true
+}
+
+func (s *MemoryStore) commit(sh *memShard, token string, data []byte, expiry time.Time) uint64 {
+	anonymous := s.cfg.Anonymous != nil && s.cfg.Anonymous(data)
+
+	if el, ok := sh.items[token]; ok {
+		entry := el.Value.(*memEntry)
+		entry.data, entry.expiry = arr.Copy(data), expiry
+		entry.version++
+		if entry.anonymous == anonymous {
+			sh.list(entry).MoveToFront(el)
+			return entry.version
+		}
+
+		sh.list(entry).Remove(el)
+		entry.anonymous = anonymous
+		sh.items[token] = sh.list(entry).PushFront(entry)
+		return entry.version
+	}
+
+	entry := &memEntry{token: token, data: arr.Copy(data), expiry: expiry, version: 1, anonymous: anonymous}
+	sh.items[token] = sh.list(entry).PushFront(entry)
+	s.active.Add(1)
+	return 1
+}
+
+// evict evicts the least recently used sessions, the anonymous ones first, until the store
+// is within MaxEntries. The shard i of the committed session is visited last, so the session
+// isn't evicted while the other shards have the candidates.
+func (s *MemoryStore) evict(i int) {
+	if s.cfg.MaxEntries <= 0 {
+		return
+	}
+
+	for _, anonymous := range []bool{true, false} {
+		for j := range s.shards {
+			if s.active.Load() <= int64(s.cfg.MaxEntries) {
+				return
+			}
+
+			sh := s.shards[(i+1+j)%len(s.shards)]
+			l := sh.lru
+			if anonymous {
+				l = sh.anon
+			}
+
+			sh.mu.Lock()
+			for l.Len() > 0 && s.active.Load() > int64(s.cfg.MaxEntries) {
+				s.remove(sh, l.Back())
+				s.evictions.Add(1)
+			}
+			sh.mu.Unlock()
+		}
+	}
+}
+
+func (s *MemoryStore) remove(sh *memShard, el *list.Element) {
+	entry := el.Value.(*memEntry)
+	delete(sh.items, entry.token)
+	sh.list(entry).Remove(el)
+	s.active.Add(-1)
+}
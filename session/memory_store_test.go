@@ -0,0 +1,243 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+
+	s := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	defer s.Stop()
+
+	data := []byte("data")
+	require.NoError(t, s.Commit(ctx, "token", data, clk.Now().Add(time.Minute)))
+	data[0] = 'D'
+
+	found, ok, err := s.Find(ctx, "token")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("data"), found)
+
+	require.NoError(t, s.Commit(ctx, "token", []byte("new"), clk.Now().Add(time.Minute)))
+	found, ok, err = s.Find(ctx, "token")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("new"), found)
+	assert.Equal(t, MemoryStoreStats{Active: 1}, s.Stats())
+
+	require.NoError(t, s.Delete(ctx, "token"))
+	require.NoError(t, s.Delete(ctx, "token"))
+	_, ok, err = s.Find(ctx, "token")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, MemoryStoreStats{}, s.Stats())
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+
+	s := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	defer s.Stop()
+
+	require.NoError(t, s.Commit(ctx, "a", []byte("a"), clk.Now().Add(time.Minute)))
+	require.NoError(t, s.Commit(ctx, "b", []byte("b"), clk.Now().Add(time.Minute)))
+	require.NoError(t, s.Commit(ctx, "c", []byte("c"), clk.Now().Add(time.Hour)))
+
+	clk.Add(time.Minute)
+
+	_, ok, err := s.Find(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, MemoryStoreStats{Active: 2, Expirations: 1}, s.Stats())
+
+	require.NoError(t, s.Cleanup(ctx))
+	assert.Equal(t, MemoryStoreStats{Active: 1, Expirations: 2}, s.Stats())
+
+	_, ok, err = s.Find(ctx, "c")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestMemoryStore_MaxEntries(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	s := NewMemoryStore(MemoryStoreConfig{Shards: 1, MaxEntries: 2, CleanupInterval: -1})
+	defer s.Stop()
+
+	require.NoError(t, s.Commit(ctx, "a", []byte("a"), expiry))
+	require.NoError(t, s.Commit(ctx, "b", []byte("b"), expiry))
+
+	// "a" becomes the most recently used, so "b" is evicted
+	_, ok, _ := s.Find(ctx, "a")
+	require.True(t, ok)
+	require.NoError(t, s.Commit(ctx, "c", []byte("c"), expiry))
+
+	for token, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		_, ok, err := s.Find(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, want, ok, token)
+	}
+	assert.Equal(t, MemoryStoreStats{Active: 2, Evictions: 1}, s.Stats())
+}
+
+func TestMemoryStore_MaxEntriesShards(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	s := NewMemoryStore(MemoryStoreConfig{Shards: 32, MaxEntries: 10, CleanupInterval: -1})
+	defer s.Stop()
+
+	for i := range 100 {
+		token := fmt.Sprintf("token-%d", i)
+		require.NoError(t, s.Commit(ctx, token, []byte(token), expiry))
+
+		_, ok, err := s.Find(ctx, token)
+		require.NoError(t, err)
+		assert.True(t, ok, token)
+	}
+	assert.Equal(t, MemoryStoreStats{Active: 10, Evictions: 90}, s.Stats())
+}
+
+func TestMemoryStore_MaxEntriesAnonymous(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+	codec := NewGobCodec()
+
+	encode := func(values map[string]any) []byte {
+		data, err := codec.Encode(expiry, values)
+		require.NoError(t, err)
+		return data
+	}
+
+	s := NewMemoryStore(MemoryStoreConfig{
+		Shards:          1,
+		MaxEntries:      2,
+		CleanupInterval: -1,
+		Anonymous:       AnonymousWithout(codec, "userID"),
+	})
+	defer s.Stop()
+
+	require.NoError(t, s.Commit(ctx, "user", encode(map[string]any{"userID": "1"}), expiry))
+	require.NoError(t, s.Commit(ctx, "anonymous", encode(map[string]any{"cart": "1"}), expiry))
+	require.NoError(t, s.Commit(ctx, "login", encode(map[string]any{"userID": "2"}), expiry))
+
+	// the least recently used session is kept, the anonymous one is evicted
+	for token, want := range map[string]bool{"user": true, "anonymous": false, "login": true} {
+		_, ok, err := s.Find(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, want, ok, token)
+	}
+
+	// the session becoming anonymous is evicted first
+	require.NoError(t, s.Commit(ctx, "user", encode(map[string]any{}), expiry))
+	require.NoError(t, s.Commit(ctx, "new", encode(map[string]any{"userID": "3"}), expiry))
+
+	for token, want := range map[string]bool{"user": false, "login": true, "new": true} {
+		_, ok, err := s.Find(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, want, ok, token)
+	}
+	assert.Equal(t, MemoryStoreStats{Active: 2, Evictions: 2}, s.Stats())
+
+	assert.True(t, AnonymousWithout(codec, "userID")([]byte("malformed")))
+}
+
+func TestMemoryStore_Janitor(t *testing.T) {
+	s := NewMemoryStore(MemoryStoreConfig{CleanupInterval: time.Millisecond})
+
+	require.NoError(t, s.Commit(context.Background(), "token", []byte("data"), time.Now().Add(-time.Second)))
+
+	assert.Eventually(t, func() bool {
+		return s.Stats().Active == 0
+	}, time.Second, time.Millisecond)
+
+	s.Stop()
+	s.Stop()
+	assert.Equal(t, uint64(1), s.Stats().Expirations)
+}
+
+func TestMemoryStore_NoJanitorByDefault(t *testing.T) {
+	s := NewMemoryStore(MemoryStoreConfig{})
+
+	select {
+	case <-s.done:
+	default:
+		assert.Fail(t, "the janitor is started")
+	}
+
+	require.NoError(t, s.Commit(context.Background(), "token", []byte("data"), time.Now().Add(-time.Second)))
+	require.NoError(t, s.Cleanup(context.Background()))
+	assert.Equal(t, MemoryStoreStats{Expirations: 1}, s.Stats())
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	s := NewMemoryStore(MemoryStoreConfig{MaxEntries: 64})
+	defer s.Stop()
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 100 {
+				token := fmt.Sprintf("%d-%d", i, j)
+				assert.NoError(t, s.Commit(ctx, token, []byte(token), expiry))
+				_, _, err := s.Find(ctx, token)
+				assert.NoError(t, err)
+			}
+		})
+	}
+	wg.Wait()
+
+	stats := s.Stats()
+	assert.LessOrEqual(t, stats.Active, int64(64))
+	assert.Equal(t, uint64(800), uint64(stats.Active)+stats.Evictions)
+}
+
+func TestMemoryStoreConfig_Validate(t *testing.T) {
+	cfg := MemoryStoreConfig{MaxEntries: -1}
+	assert.EqualError(t, cfg.Validate(), "session: max entries must not be negative")
+	assert.Panics(t, func() { NewMemoryStore(cfg) })
+}
+
+func TestMemoryStore_CommitVersion(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	s := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer s.Stop()
+
+	_, err := s.CommitVersion(ctx, "token", []byte("a"), expiry, 1)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	version, err := s.CommitVersion(ctx, "token", []byte("a"), expiry, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+
+	_, err = s.CommitVersion(ctx, "token", []byte("b"), expiry, 0)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	version, err = s.CommitVersion(ctx, "token", []byte("b"), expiry, version)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+
+	data, version, found, err := s.FindVersion(ctx, "token")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(2), version)
+	assert.Equal(t, []byte("b"), data)
+}
@@ -0,0 +1,158 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gowool/wo"
+)
+
+// ErrReadOnly is returned (or panicked with in debug mode) when a read-only session is mutated.
+var ErrReadOnly = errors.New("session: read-only")
+
+// SetReadOnly marks the session of the current request cycle as read-only. Mutations of
+// a read-only session panic in debug mode (see [wo.Debug]), otherwise the methods which
+// return an error return [ErrReadOnly] and the others are no-ops.
+//
+// The session is still committed when an idle timeout is used, so the expiry is extended.
+func (s *Session) SetReadOnly(ctx context.Context, readOnly bool) {
+	sd, ok := ctx.Value(s.contextKey).(*sessionData)
+	if !ok {
+		panic("scs: no session data in context")
+	}
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	sd.readOnly = readOnly
+}
+
+// IsReadOnly reports whether the session of the current request cycle is read-only.
+func (s *Session) IsReadOnly(ctx context.Context) bool {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	return sd.readOnly
+}
+
+// writable reports whether the session data may be mutated, it panics in debug mode
+// for the read-only session data. The session data must be locked.
+func writable(ctx context.Context, sd *sessionData) bool {
+	if !sd.readOnly {
+		return true
+	}
+	if wo.Debug(ctx) {
+		panic(ErrReadOnly)
+	}
+	return false
+}
+
+// ReadOnly returns a view of the session for handlers which must not mutate it.
+func (s *Session) ReadOnly(ctx context.Context) *View {
+	return &View{session: s, ctx: ctx}
+}
+
+// View is a read-only view of the session of a request cycle. Its mutating methods
+// return [ErrReadOnly], or panic with it in debug mode (see [wo.Debug]).
+type View struct {
+	session *Session
+	ctx     context.Context
+}
+
+func (v *View) Get(key string) any {
+	return v.session.Get(v.ctx, key)
+}
+
+func (v *View) GetString(key string) string {
+	return v.session.GetString(v.ctx, key)
+}
+
+func (v *View) GetBool(key string) bool {
+	return v.session.GetBool(v.ctx, key)
+}
+
+func (v *View) GetInt(key string) int {
+	return v.session.GetInt(v.ctx, key)
+}
+
+func (v *View) GetInt64(key string) int64 {
+	return v.session.GetInt64(v.ctx, key)
+}
+
+func (v *View) GetFloat64(key string) float64 {
+	return v.session.GetFloat64(v.ctx, key)
+}
+
+func (v *View) GetBytes(key string) []byte {
+	return v.session.GetBytes(v.ctx, key)
+}
+
+func (v *View) GetTime(key string) time.Time {
+	return v.session.GetTime(v.ctx, key)
+}
+
+func (v *View) Has(key string) bool {
+	return v.session.Has(v.ctx, key)
+}
+
+func (v *View) Keys() []string {
+	return v.session.Keys(v.ctx)
+}
+
+func (v *View) Token() string {
+	return v.session.Token(v.ctx)
+}
+
+func (v *View) Deadline() time.Time {
+	return v.session.Deadline(v.ctx)
+}
+
+func (v *View) Status() Status {
+	return v.session.Status(v.ctx)
+}
+
+func (v *View) Put(string, any) error {
+	return v.denied()
+}
+
+func (v *View) PutWithTTL(string, any, time.Duration) error {
+	return v.denied()
+}
+
+func (v *View) Pop(string) (any, error) {
+	return nil, v.denied()
+}
+
+func (v *View) Remove(string) error {
+	return v.denied()
+}
+
+func (v *View) Clear() error {
+	return v.denied()
+}
+
+func (v *View) SetDeadline(time.Time) error {
+	return v.denied()
+}
+
+func (v *View) RememberMe(bool) error {
+	return v.denied()
+}
+
+func (v *View) RenewToken() error {
+	return v.denied()
+}
+
+func (v *View) Destroy() error {
+	return v.denied()
+}
+
+func (v *View) denied() error {
+	if wo.Debug(v.ctx) {
+		panic(ErrReadOnly)
+	}
+	return ErrReadOnly
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+)
+
+func TestSession_SetReadOnly(t *testing.T) {
+	session, ctx, err := setupTestSessionWithData()
+	require.NoError(t, err)
+
+	status := session.Status(ctx)
+	session.SetReadOnly(ctx, true)
+	assert.True(t, session.IsReadOnly(ctx))
+
+	session.Put(ctx, "stringKey", "changed")
+	session.Remove(ctx, "intKey")
+	session.SetDeadline(ctx, time.Now())
+	assert.Nil(t, session.Pop(ctx, "stringKey"))
+	assert.ErrorIs(t, session.Clear(ctx), ErrReadOnly)
+	assert.ErrorIs(t, session.RenewToken(ctx), ErrReadOnly)
+	assert.ErrorIs(t, session.Destroy(ctx), ErrReadOnly)
+
+	assert.Equal(t, "stringValue", session.GetString(ctx, "stringKey"))
+	assert.Equal(t, 42, session.GetInt(ctx, "intKey"))
+	assert.Equal(t, status, session.Status(ctx))
+
+	session.SetReadOnly(ctx, false)
+	session.Put(ctx, "stringKey", "changed")
+	assert.Equal(t, "changed", session.GetString(ctx, "stringKey"))
+}
+
+func TestSession_SetReadOnly_Debug(t *testing.T) {
+	session, ctx, err := setupTestSessionWithData()
+	require.NoError(t, err)
+
+	ctx = wo.WithDebug(ctx, true)
+	session.SetReadOnly(ctx, true)
+
+	assert.PanicsWithValue(t, ErrReadOnly, func() {
+		session.Put(ctx, "stringKey", "changed")
+	})
+}
+
+func TestSession_ReadOnly(t *testing.T) {
+	session, ctx, err := setupTestSessionWithData()
+	require.NoError(t, err)
+
+	view := session.ReadOnly(ctx)
+
+	assert.Equal(t, "stringValue", view.GetString("stringKey"))
+	assert.Equal(t, 42, view.GetInt("intKey"))
+	assert.True(t, view.GetBool("boolKey"))
+	assert.True(t, view.Has("intKey"))
+	assert.Equal(t, session.Keys(ctx), view.Keys())
+
+	assert.ErrorIs(t, view.Put("stringKey", "changed"), ErrReadOnly)
+	assert.ErrorIs(t, view.PutWithTTL("stringKey", "changed", time.Minute), ErrReadOnly)
+	_, err = view.Pop("stringKey")
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.ErrorIs(t, view.Remove("stringKey"), ErrReadOnly)
+	assert.ErrorIs(t, view.Clear(), ErrReadOnly)
+	assert.ErrorIs(t, view.Destroy(), ErrReadOnly)
+	assert.Equal(t, "stringValue", view.GetString("stringKey"))
+
+	debugView := session.ReadOnly(wo.WithDebug(ctx, true))
+	assert.PanicsWithValue(t, ErrReadOnly, func() {
+		_ = debugView.Put("stringKey", "changed")
+	})
+}
@@ -0,0 +1,268 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/clock"
+)
+
+// ErrRememberTokenInvalid is returned when the remember-me cookie is malformed, unknown, expired
+// or its verifier doesn't match. The token of a known selector with the mismatching verifier is
+// revoked, the other remember-me tokens of the user are kept.
+var ErrRememberTokenInvalid = errors.New("session: invalid remember-me token")
+
+// RememberToken is a persisted remember-me token. Only the hash of the verifier is stored,
+// so the leaked store content can't be used to authenticate.
+type RememberToken struct {
+	Selector     string
+	VerifierHash []byte
+	UserID       string
+	Expiry       time.Time
+
+	// Rotated is set once the token is consumed, the rotated token is kept until the end
+	// of the rotation grace window, see [RememberConfig.RotationGrace].
+	Rotated bool
+}
+
+// RememberStore persists the remember-me tokens.
+type RememberStore interface {
+	// Save adds the token or replaces the one with the same selector.
+	Save(ctx context.Context, token RememberToken) error
+
+	// Find returns the token with the selector, found is false if it doesn't exist.
+	Find(ctx context.Context, selector string) (token RememberToken, found bool, err error)
+
+	// Delete removes the token with the selector, it doesn't fail if the token doesn't exist.
+	Delete(ctx context.Context, selector string) error
+
+	// DeleteUser removes all tokens of the user.
+	DeleteUser(ctx context.Context, userID string) error
+}
+
+type RememberConfig struct {
+	// Lifetime is the maximum length of time a remember-me token is valid for.
+	//
+	// Default: 30 days
+	Lifetime time.Duration `env:"LIFETIME" json:"lifetime,omitempty,format:units" yaml:"lifetime,omitempty"`
+
+	// Cookie contains the configuration settings for the remember-me cookie, which is always persistent.
+	//
+	// Default: the name is "remember"
+	Cookie Cookie `envPrefix:"COOKIE_" json:"cookie,omitempty" yaml:"cookie,omitempty"`
+
+	// RotationGrace is the length of time the consumed token is still accepted for, so the
+	// concurrent requests with the same cookie, ex. of several tabs, aren't signed out by the
+	// rotation. The consumed token isn't rotated again. A negative value disables it.
+	//
+	// Default: 30s
+	RotationGrace time.Duration `env:"ROTATION_GRACE" json:"rotationGrace,omitempty,format:units" yaml:"rotationGrace,omitempty"`
+
+	// Clock is the clock of the token expiry.
+	//
+	// Default: clock.System
+	Clock clock.Clock `json:"-" yaml:"-"`
+}
+
+func (c *RememberConfig) SetDefaults() {
+	if c.Cookie.Name == "" {
+		c.Cookie.Name = "remember"
+	}
+	c.Cookie.SetDefaults()
+
+	if c.Lifetime == 0 {
+		c.Lifetime = 30 * 24 * time.Hour
+	}
+	if c.RotationGrace == 0 {
+		c.RotationGrace = 30 * time.Second
+	}
+	if c.Clock == nil {
+		c.Clock = clock.System
+	}
+}
+
+// Remember manages the long-lived remember-me tokens, which re-create the authenticated
+// session once it expired. The tokens are split into a selector, which looks the token up,
+// and a verifier, which is compared to the stored hash in constant time. The tokens are
+// single-use: each successful [Remember.Consume] rotates the token, the consumed one is
+// accepted within the rotation grace window only.
+type Remember struct {
+	config RememberConfig
+	store  RememberStore
+}
+
+func NewRemember(cfg RememberConfig, store RememberStore) *Remember {
+	if store == nil {
+		panic("session: remember store is nil")
+	}
+
+	cfg.SetDefaults()
+
+	return &Remember{config: cfg, store: store}
+}
+
+// Issue creates a remember-me token for the user and writes the remember-me cookie, ex. on login
+// with the "remember me" option checked.
+func (m *Remember) Issue(ctx context.Context, w http.ResponseWriter, userID string) error {
+	selector, err := randomString(16)
+	if err != nil {
+		return err
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return err
+	}
+
+	token := RememberToken{
+		Selector:     selector,
+		VerifierHash: hashVerifier(verifier),
+		UserID:       userID,
+		Expiry:       m.config.Clock.Now().Add(m.config.Lifetime).UTC(),
+	}
+	if err = m.store.Save(ctx, token); err != nil {
+		return err
+	}
+
+	m.writeCookie(w, selector+":"+verifier, token.Expiry)
+	return nil
+}
+
+// Consume verifies the remember-me cookie of the request and returns the user ID. The used
+// token is replaced with a new one, unless it was already rotated by a concurrent request
+// within the grace window, in which case the cookie is left as is. The cookie is removed if the token is invalid, in which
+// case [ErrRememberTokenInvalid] is returned, and [http.ErrNoCookie] if there is no cookie.
+func (m *Remember) Consume(ctx context.Context, w http.ResponseWriter, r *http.Request) (string, error) {
+	token, err := m.verify(ctx, r)
+	if err != nil {
+		if errors.Is(err, ErrRememberTokenInvalid) {
+			m.writeCookie(w, "", time.Time{})
+		}
+		return "", err
+	}
+
+	if token.Rotated {
+		// the concurrent request rotated the token and set the new cookie
+		return token.UserID, nil
+	}
+
+	if m.config.RotationGrace > 0 {
+		token.Rotated = true
+		if expiry := m.config.Clock.Now().Add(m.config.RotationGrace).UTC(); expiry.Before(token.Expiry) {
+			token.Expiry = expiry
+		}
+		err = m.store.Save(ctx, token)
+	} else {
+		err = m.store.Delete(ctx, token.Selector)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err = m.Issue(ctx, w, token.UserID); err != nil {
+		return "", err
+	}
+	return token.UserID, nil
+}
+
+func (m *Remember) verify(ctx context.Context, r *http.Request) (RememberToken, error) {
+	cookie, err := r.Cookie(m.config.Cookie.Name)
+	if err != nil {
+		return RememberToken{}, err
+	}
+
+	selector, verifier, ok := strings.Cut(cookie.Value, ":")
+	if !ok || selector == "" || verifier == "" {
+		return RememberToken{}, ErrRememberTokenInvalid
+	}
+
+	token, found, err := m.store.Find(ctx, selector)
+	if err != nil {
+		return RememberToken{}, err
+	}
+	if !found {
+		return RememberToken{}, ErrRememberTokenInvalid
+	}
+
+	if subtle.ConstantTimeCompare(token.VerifierHash, hashVerifier(verifier)) != 1 {
+		// the selector is known, but the verifier isn't: the selector leaked or is guessed, so revoke
+		// its token only, since the used tokens are rotated and aren't found, and the mismatch
+		// mustn't let anyone knowing a selector log the user out of all the devices
+		if err = m.store.Delete(ctx, selector); err != nil {
+			return RememberToken{}, err
+		}
+		return RememberToken{}, ErrRememberTokenInvalid
+	}
+
+	if !m.config.Clock.Now().Before(token.Expiry) {
+		if err = m.store.Delete(ctx, selector); err != nil {
+			return RememberToken{}, err
+		}
+		return RememberToken{}, ErrRememberTokenInvalid
+	}
+
+	return token, nil
+}
+
+// Revoke removes the remember-me token of the request and its cookie, ex. on logout.
+func (m *Remember) Revoke(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	if cookie, err := r.Cookie(m.config.Cookie.Name); err == nil {
+		if selector, _, ok := strings.Cut(cookie.Value, ":"); ok && selector != "" {
+			if err = m.store.Delete(ctx, selector); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.writeCookie(w, "", time.Time{})
+	return nil
+}
+
+// RevokeAll removes all remember-me tokens of the user, ex. on password change.
+func (m *Remember) RevokeAll(ctx context.Context, userID string) error {
+	return m.store.DeleteUser(ctx, userID)
+}
+
+func (m *Remember) writeCookie(w http.ResponseWriter, value string, expiry time.Time) {
+	cookie := &http.Cookie{
+		HttpOnly:    true,
+		Value:       value,
+		Name:        m.config.Cookie.Name,
+		Path:        m.config.Cookie.Path,
+		Domain:      m.config.Cookie.Domain,
+		Secure:      m.config.Cookie.Secure,
+		Partitioned: m.config.Cookie.Partitioned,
+		SameSite:    m.config.Cookie.SameSite.HTTP(),
+	}
+
+	if expiry.IsZero() {
+		cookie.Expires = time.Unix(1, 0)
+		cookie.MaxAge = -1
+	} else {
+		cookie.Expires = time.Unix(expiry.Unix()+1, 0)                         // Round up to the nearest second.
+		cookie.MaxAge = int(clock.Until(m.config.Clock, expiry).Seconds() + 1) // Round up to the nearest second.
+	}
+
+	w.Header().Add(wo.HeaderCacheControl, `no-cache="Set-Cookie"`)
+
+	http.SetCookie(w, cookie)
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashVerifier(verifier string) []byte {
+	hash := sha256.Sum256([]byte(verifier))
+	return hash[:]
+}
@@ -0,0 +1,278 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+// testRememberStore is a minimal in-memory RememberStore.
+type testRememberStore struct {
+	tokens map[string]RememberToken
+	mu     sync.Mutex
+}
+
+func newTestRememberStore() *testRememberStore {
+	return &testRememberStore{tokens: make(map[string]RememberToken)}
+}
+
+func (s *testRememberStore) Save(_ context.Context, token RememberToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[token.Selector] = token
+	return nil
+}
+
+func (s *testRememberStore) Find(_ context.Context, selector string) (RememberToken, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[selector]
+	return token, ok, nil
+}
+
+func (s *testRememberStore) Delete(_ context.Context, selector string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, selector)
+	return nil
+}
+
+func (s *testRememberStore) DeleteUser(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for selector, token := range s.tokens {
+		if token.UserID == userID {
+			delete(s.tokens, selector)
+		}
+	}
+	return nil
+}
+
+func issueRememberCookie(t *testing.T, m *Remember, userID string) *http.Cookie {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, m.Issue(context.Background(), rec, userID))
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	return cookies[0]
+}
+
+func requestWithCookie(cookie *http.Cookie) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+func TestRememberConfig_SetDefaults(t *testing.T) {
+	var cfg RememberConfig
+	cfg.SetDefaults()
+
+	assert.Equal(t, "remember", cfg.Cookie.Name)
+	assert.Equal(t, "/", cfg.Cookie.Path)
+	assert.Equal(t, 30*24*time.Hour, cfg.Lifetime)
+	assert.Equal(t, 30*time.Second, cfg.RotationGrace)
+}
+
+func TestRemember_Issue(t *testing.T) {
+	store := newTestRememberStore()
+	m := NewRemember(RememberConfig{Cookie: Cookie{Secure: true}}, store)
+
+	cookie := issueRememberCookie(t, m, "42")
+
+	assert.Equal(t, "remember", cookie.Name)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.Positive(t, cookie.MaxAge)
+
+	selector, verifier, ok := strings.Cut(cookie.Value, ":")
+	require.True(t, ok)
+
+	token, found, _ := store.Find(context.Background(), selector)
+	require.True(t, found)
+	assert.Equal(t, "42", token.UserID)
+	assert.Equal(t, hashVerifier(verifier), token.VerifierHash)
+	assert.NotContains(t, string(token.VerifierHash), verifier)
+}
+
+func TestRemember_Consume(t *testing.T) {
+	store := newTestRememberStore()
+	m := NewRemember(RememberConfig{}, store)
+	cookie := issueRememberCookie(t, m, "42")
+
+	rec := httptest.NewRecorder()
+	userID, err := m.Consume(context.Background(), rec, requestWithCookie(cookie))
+	require.NoError(t, err)
+	assert.Equal(t, "42", userID)
+
+	// the token is rotated
+	rotated := rec.Result().Cookies()
+	require.Len(t, rotated, 1)
+	assert.NotEqual(t, cookie.Value, rotated[0].Value)
+	assert.Len(t, store.tokens, 2)
+
+	userID, err = m.Consume(context.Background(), httptest.NewRecorder(), requestWithCookie(rotated[0]))
+	require.NoError(t, err)
+	assert.Equal(t, "42", userID)
+}
+
+func TestRemember_Consume_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cookie func(t *testing.T, m *Remember, store *testRememberStore) *http.Cookie
+		tokens int
+	}{
+		{
+			name: "malformed",
+			cookie: func(t *testing.T, m *Remember, _ *testRememberStore) *http.Cookie {
+				issueRememberCookie(t, m, "42")
+				return &http.Cookie{Name: "remember", Value: "malformed"}
+			},
+			tokens: 1,
+		},
+		{
+			name: "unknown selector",
+			cookie: func(t *testing.T, m *Remember, _ *testRememberStore) *http.Cookie {
+				issueRememberCookie(t, m, "42")
+				return &http.Cookie{Name: "remember", Value: "unknown:verifier"}
+			},
+			tokens: 1,
+		},
+		{
+			name: "expired",
+			cookie: func(t *testing.T, m *Remember, store *testRememberStore) *http.Cookie {
+				cookie := issueRememberCookie(t, m, "42")
+				selector, _, _ := strings.Cut(cookie.Value, ":")
+				token := store.tokens[selector]
+				token.Expiry = time.Now().Add(-time.Second)
+				store.tokens[selector] = token
+				return cookie
+			},
+			tokens: 0,
+		},
+		{
+			name: "forged verifier revokes the token",
+			cookie: func(t *testing.T, m *Remember, _ *testRememberStore) *http.Cookie {
+				issueRememberCookie(t, m, "42")
+				issueRememberCookie(t, m, "7")
+				cookie := issueRememberCookie(t, m, "42")
+				selector, _, _ := strings.Cut(cookie.Value, ":")
+				return &http.Cookie{Name: "remember", Value: selector + ":forged"}
+			},
+			tokens: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestRememberStore()
+			m := NewRemember(RememberConfig{}, store)
+			cookie := tt.cookie(t, m, store)
+
+			rec := httptest.NewRecorder()
+			_, err := m.Consume(context.Background(), rec, requestWithCookie(cookie))
+			assert.ErrorIs(t, err, ErrRememberTokenInvalid)
+			assert.Len(t, store.tokens, tt.tokens)
+
+			// the cookie is removed
+			cookies := rec.Result().Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, -1, cookies[0].MaxAge)
+		})
+	}
+}
+
+func TestRemember_Consume_NoCookie(t *testing.T) {
+	m := NewRemember(RememberConfig{}, newTestRememberStore())
+
+	rec := httptest.NewRecorder()
+	_, err := m.Consume(context.Background(), rec, requestWithCookie(nil))
+	assert.ErrorIs(t, err, http.ErrNoCookie)
+	assert.Empty(t, rec.Result().Cookies())
+}
+
+func TestRemember_Revoke(t *testing.T) {
+	store := newTestRememberStore()
+	m := NewRemember(RememberConfig{}, store)
+	cookie := issueRememberCookie(t, m, "42")
+	issueRememberCookie(t, m, "42")
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, m.Revoke(context.Background(), rec, requestWithCookie(cookie)))
+	assert.Len(t, store.tokens, 1)
+	require.Len(t, rec.Result().Cookies(), 1)
+	assert.Equal(t, -1, rec.Result().Cookies()[0].MaxAge)
+
+	require.NoError(t, m.RevokeAll(context.Background(), "42"))
+	assert.Empty(t, store.tokens)
+}
+
+func TestNewRemember_NilStore(t *testing.T) {
+	assert.Panics(t, func() { NewRemember(RememberConfig{}, nil) })
+}
+
+func TestRemember_Clock(t *testing.T) {
+	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := NewRemember(RememberConfig{Lifetime: time.Hour, Clock: clk}, newTestRememberStore())
+	cookie := issueRememberCookie(t, m, "42")
+	assert.Equal(t, 3601, cookie.MaxAge)
+
+	clk.Add(time.Hour)
+	_, err := m.Consume(context.Background(), httptest.NewRecorder(), requestWithCookie(cookie))
+	assert.ErrorIs(t, err, ErrRememberTokenInvalid)
+}
+
+func TestRemember_Consume_RotationGrace(t *testing.T) {
+	clk := clock.NewMock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := newTestRememberStore()
+	m := NewRemember(RememberConfig{RotationGrace: time.Minute, Clock: clk}, store)
+	cookie := issueRememberCookie(t, m, "42")
+
+	rec := httptest.NewRecorder()
+	userID, err := m.Consume(context.Background(), rec, requestWithCookie(cookie))
+	require.NoError(t, err)
+	assert.Equal(t, "42", userID)
+	require.Len(t, rec.Result().Cookies(), 1)
+
+	// the concurrent request with the consumed token isn't signed out nor rotates it again
+	rec = httptest.NewRecorder()
+	userID, err = m.Consume(context.Background(), rec, requestWithCookie(cookie))
+	require.NoError(t, err)
+	assert.Equal(t, "42", userID)
+	assert.Empty(t, rec.Result().Cookies())
+	assert.Len(t, store.tokens, 2)
+
+	clk.Add(time.Minute)
+	_, err = m.Consume(context.Background(), httptest.NewRecorder(), requestWithCookie(cookie))
+	assert.ErrorIs(t, err, ErrRememberTokenInvalid)
+	assert.Len(t, store.tokens, 1)
+}
+
+func TestRemember_Consume_NoRotationGrace(t *testing.T) {
+	store := newTestRememberStore()
+	m := NewRemember(RememberConfig{RotationGrace: -1}, store)
+	cookie := issueRememberCookie(t, m, "42")
+
+	_, err := m.Consume(context.Background(), httptest.NewRecorder(), requestWithCookie(cookie))
+	require.NoError(t, err)
+	assert.Len(t, store.tokens, 1)
+
+	_, err = m.Consume(context.Background(), httptest.NewRecorder(), requestWithCookie(cookie))
+	assert.ErrorIs(t, err, ErrRememberTokenInvalid)
+}
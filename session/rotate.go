@@ -0,0 +1,91 @@
+package session
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// rotatedAtKey is the key of the time (unix nanoseconds) the session token was issued at,
+// see Config.RotateInterval.
+const rotatedAtKey = InternalKeyPrefix + "rotatedAt"
+
+type privilegeChangeKey struct{}
+
+// withPrivilegeChange returns the context the privilege change of the request cycle can be
+// marked in, see MarkPrivilegeChange.
+func withPrivilegeChange(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(privilegeChangeKey{}).(*atomic.Bool); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, privilegeChangeKey{}, new(atomic.Bool))
+}
+
+// MarkPrivilegeChange marks the privilege level change of the request cycle, ex. the login, the
+// logout or the role change, so the session token is renewed before the session is committed
+// (see [Session.RenewTokenIfNeeded]), against the session fixation. The context must be the one
+// read by [Session.ReadSessionCookie], ex. by the session middleware, otherwise it's a no-op.
+func MarkPrivilegeChange(ctx context.Context) {
+	if marked, ok := ctx.Value(privilegeChangeKey{}).(*atomic.Bool); ok {
+		marked.Store(true)
+	}
+}
+
+// PrivilegeChangeMarked reports whether the privilege change of the request cycle is marked,
+// see MarkPrivilegeChange.
+func PrivilegeChangeMarked(ctx context.Context) bool {
+	marked, ok := ctx.Value(privilegeChangeKey{}).(*atomic.Bool)
+	return ok && marked.Load()
+}
+
+// RenewTokenIfNeeded renews the session token if the privilege change is marked (see
+// MarkPrivilegeChange), like RenewToken, or if the token is older than Config.RotateInterval,
+// keeping the session deadline. It reports whether the token has been renewed. The untouched
+// lazy loaded, the read-only and the destroyed sessions are left as is.
+//
+// Note, the concurrent requests of the old token start the new sessions once it's renewed.
+func (s *Session) RenewTokenIfNeeded(ctx context.Context) (bool, error) {
+	if PrivilegeChangeMarked(ctx) {
+		if s.Status(ctx) == Destroyed {
+			return false, nil
+		}
+		if err := s.RenewToken(ctx); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if s.config.RotateInterval <= 0 || !s.Loaded(ctx) {
+		return false, nil
+	}
+
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if sd.token == "" || sd.readOnly || sd.status == Destroyed {
+		return false, nil
+	}
+
+	now := s.config.Clock.Now()
+	if rotatedAt, ok := int64Value(sd.values[rotatedAtKey]); ok && now.Before(time.Unix(0, rotatedAt).Add(s.config.RotateInterval)) {
+		return false, nil
+	}
+
+	if err := s.doStoreDelete(ctx, sd.token); err != nil {
+		return false, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return false, err
+	}
+
+	sd.token = token
+	sd.version = 0
+	sd.values[rotatedAtKey] = now.UnixNano()
+	sd.status = Modified
+
+	return true, nil
+}
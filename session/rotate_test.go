@@ -0,0 +1,135 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+func TestSession_RenewTokenIfNeeded_PrivilegeChange(t *testing.T) {
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	session := New(Config{}, store)
+
+	ctx, err := session.Load(context.Background(), "")
+	require.NoError(t, err)
+	session.Put(ctx, "cart", "1")
+	token, _, err := session.Commit(ctx)
+	require.NoError(t, err)
+
+	r, err := session.ReadSessionCookie(newCookieRequest(token))
+	require.NoError(t, err)
+	ctx = r.Context()
+
+	renewed, err := session.RenewTokenIfNeeded(ctx)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+
+	MarkPrivilegeChange(ctx)
+	assert.True(t, PrivilegeChangeMarked(ctx))
+	session.Put(ctx, "user", "ann")
+
+	renewed, err = session.RenewTokenIfNeeded(ctx)
+	require.NoError(t, err)
+	assert.True(t, renewed)
+	assert.NotEqual(t, token, session.Token(ctx))
+	assert.Equal(t, "1", session.GetString(ctx, "cart"))
+
+	_, found, err := store.Find(context.Background(), token)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// the context not read from the cookie
+	MarkPrivilegeChange(context.Background())
+	assert.False(t, PrivilegeChangeMarked(context.Background()))
+}
+
+func TestSession_RenewTokenIfNeeded_Destroyed(t *testing.T) {
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1})
+	defer store.Stop()
+
+	session := New(Config{}, store)
+
+	r, err := session.ReadSessionCookie(newCookieRequest(""))
+	require.NoError(t, err)
+	ctx := r.Context()
+
+	MarkPrivilegeChange(ctx)
+	require.NoError(t, session.Destroy(ctx))
+
+	renewed, err := session.RenewTokenIfNeeded(ctx)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+	assert.Equal(t, Destroyed, session.Status(ctx))
+}
+
+func TestSession_RenewTokenIfNeeded_RotateInterval(t *testing.T) {
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	defer store.Stop()
+
+	session := New(Config{RotateInterval: 10 * time.Minute, Clock: clk}, store)
+
+	ctx, err := session.Load(context.Background(), "")
+	require.NoError(t, err)
+	session.Put(ctx, "user", "ann")
+	token, _, err := session.Commit(ctx)
+	require.NoError(t, err)
+	deadline := session.Deadline(ctx)
+
+	clk.Add(5 * time.Minute)
+
+	ctx, err = session.Load(context.Background(), token)
+	require.NoError(t, err)
+	renewed, err := session.RenewTokenIfNeeded(ctx)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+	assert.Equal(t, Unmodified, session.Status(ctx))
+
+	clk.Add(5 * time.Minute)
+
+	ctx, err = session.Load(context.Background(), token)
+	require.NoError(t, err)
+	renewed, err = session.RenewTokenIfNeeded(ctx)
+	require.NoError(t, err)
+	assert.True(t, renewed)
+	assert.Equal(t, Modified, session.Status(ctx))
+	assert.Equal(t, deadline, session.Deadline(ctx))
+	assert.Equal(t, "ann", session.GetString(ctx, "user"))
+
+	rotated, _, err := session.Commit(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, rotated)
+
+	// the rotated token isn't renewed until the interval elapses again
+	ctx, err = session.Load(context.Background(), rotated)
+	require.NoError(t, err)
+	renewed, err = session.RenewTokenIfNeeded(ctx)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+
+	// the read-only session is left as is
+	clk.Add(time.Hour)
+	ctx, err = session.Load(context.Background(), rotated)
+	require.NoError(t, err)
+	session.SetReadOnly(ctx, true)
+	renewed, err = session.RenewTokenIfNeeded(ctx)
+	require.NoError(t, err)
+	assert.False(t, renewed)
+}
+
+func newCookieRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	if token != "" {
+		r.AddCookie(&http.Cookie{Name: "session", Value: token})
+	}
+	return r
+}
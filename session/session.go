@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gowool/wo"
+	"github.com/gowool/wo/clock"
 )
 
 type Session struct {
@@ -25,6 +26,13 @@ func New(cfg Config, store Store) *Session {
 func NewWithCodec(cfg Config, store Store, codec Codec) *Session {
 	cfg.SetDefaults()
 
+	if _, ok := store.(VersionedStore); cfg.StrictCommit && !ok {
+		panic("session: strict commit requires a versioned store")
+	}
+	if _, ok := store.(StatelessStore); cfg.HashTokenInStore && ok {
+		panic("session: hashed tokens are not supported by the stateless store")
+	}
+
 	return &Session{
 		config:     cfg,
 		store:      store,
@@ -37,15 +45,34 @@ func NewWithCodec(cfg Config, store Store, codec Codec) *Session {
 // loads the session data into the request context. If the cookie is
 // invalid, it returns an error. The session data is stored in the
 // request context under the key defined by the session's contextKey.
+// The session data is loaded on the first access if config.Lazy is set.
+// The session bound to the client attributes (see config.BindToIP) is
+// verified against the request. The privilege
+// change of the request cycle can be marked in the returned request context,
+// see MarkPrivilegeChange.
 func (s *Session) ReadSessionCookie(r *http.Request) (*http.Request, error) {
 	var token string
-	if cookie, err := r.Cookie(s.config.Cookie.Name); err == nil {
+	ctx := withPrivilegeChange(r.Context())
+
+	if _, ok := s.store.(StatelessStore); ok {
+		var chunks int
+		if token, chunks = readCookieChunks(r, s.config.Cookie.Name); chunks > 1 {
+			ctx = context.WithValue(ctx, cookieChunksKey{}, chunks)
+		}
+	} else if cookie, err := r.Cookie(s.config.Cookie.Name); err == nil {
 		token = cookie.Value
 	}
 
-	ctx, err := s.Load(r.Context(), token)
-	if err != nil {
-		return r, err
+	if s.config.Lazy && !s.config.BindToIP && !s.config.BindToUserAgent {
+		ctx = s.LoadLazy(ctx, token)
+	} else {
+		var err error
+		if ctx, err = s.Load(ctx, token); err != nil {
+			return r, err
+		}
+		if err = s.verifyBindings(ctx, r); err != nil {
+			return r, err
+		}
 	}
 
 	return r.WithContext(ctx), nil
@@ -57,14 +84,15 @@ func (s *Session) ReadSessionCookie(r *http.Request) (*http.Request, error) {
 // or has had RememberMe(true) called on it. If expiry is an empty time.Time
 // struct (so that it's IsZero() method returns true) the cookie will be
 // marked with a historical expiry time and negative max-age (so the browser
-// deletes it).
+// deletes it). The token of the StatelessStore is split across multiple
+// cookies if it's too large for one.
 func (s *Session) WriteSessionCookie(ctx context.Context, w http.ResponseWriter, token string, expiry time.Time) {
 	cookie := &http.Cookie{
 		HttpOnly:    true,
 		Value:       token,
 		Name:        s.config.Cookie.Name,
 		Path:        s.config.Cookie.Path,
-		Domain:      s.config.Cookie.Domain,
+		Domain:      s.cookieDomain(ctx),
 		Secure:      s.config.Cookie.Secure,
 		Partitioned: s.config.Cookie.Partitioned,
 		SameSite:    s.config.Cookie.SameSite.HTTP(),
@@ -73,13 +101,31 @@ func (s *Session) WriteSessionCookie(ctx context.Context, w http.ResponseWriter,
 	if expiry.IsZero() {
 		cookie.Expires = time.Unix(1, 0)
 		cookie.MaxAge = -1
-	} else if s.config.Cookie.Persist || s.GetBool(ctx, "__rememberMe") {
-		cookie.Expires = time.Unix(expiry.Unix()+1, 0)        // Round up to the nearest second.
-		cookie.MaxAge = int(time.Until(expiry).Seconds() + 1) // Round up to the nearest second.
+	} else if s.config.Cookie.Persist || s.GetBool(ctx, rememberMeKey) {
+		cookie.Expires = time.Unix(expiry.Unix()+1, 0)                         // Round up to the nearest second.
+		cookie.MaxAge = int(clock.Until(s.config.Clock, expiry).Seconds() + 1) // Round up to the nearest second.
 	}
 
-	w.Header().Set(wo.HeaderVary, "Cookie")
+	wo.AddVary(w.Header(), wo.HeaderCookie)
 	w.Header().Add(wo.HeaderCacheControl, `no-cache="Set-Cookie"`)
 
+	if _, ok := s.store.(StatelessStore); ok {
+		writeCookieChunks(ctx, w, cookie)
+		return
+	}
 	http.SetCookie(w, cookie)
 }
+
+// Now returns the current time of the session clock, see Config.Clock.
+func (s *Session) Now() time.Time {
+	return s.config.Clock.Now()
+}
+
+func (s *Session) cookieDomain(ctx context.Context) string {
+	if s.config.CookieDomain != nil {
+		if domain := s.config.CookieDomain(ctx); domain != "" {
+			return domain
+		}
+	}
+	return s.config.Cookie.Domain
+}
@@ -283,6 +283,41 @@ func TestWriteSessionCookie_CustomConfig(t *testing.T) {
 	assert.Equal(t, config.Cookie.SameSite.HTTP(), cookie.SameSite)
 }
 
+type cookieDomainKey struct{}
+
+func TestWriteSessionCookie_CookieDomain(t *testing.T) {
+	session := New(Config{
+		Cookie: Cookie{Domain: "example.com"},
+		CookieDomain: func(ctx context.Context) string {
+			domain, _ := ctx.Value(cookieDomainKey{}).(string)
+			return domain
+		},
+	}, &MockStore{})
+
+	tests := []struct {
+		name     string
+		domain   string
+		expected string
+	}{
+		{"per request", "acme.example.com", "acme.example.com"},
+		{"fallback", "", "example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := session.Load(context.WithValue(context.Background(), cookieDomainKey{}, tt.domain), "")
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			session.WriteSessionCookie(ctx, w, "test-token", time.Now().Add(time.Hour))
+
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+			assert.Equal(t, tt.expected, cookies[0].Domain)
+		})
+	}
+}
+
 func TestWriteSessionCookie_DefaultConfig(t *testing.T) {
 	mockStore := &MockStore{}
 	config := Config{} // Empty config should use defaults
@@ -338,3 +373,40 @@ func TestSessionContextKeyGeneration(t *testing.T) {
 
 	assert.NotEqual(t, session1.contextKey, session2.contextKey, "Each session should have unique context key")
 }
+
+func FuzzCookieParse(f *testing.F) {
+	f.Add("session=abc; remember=sel:verifier", []byte{})
+	f.Add(`session="quoted"; session=dup; remember=sel:`, []byte("garbage"))
+	f.Add("session=; remember=:; ;;=; remember=a:b:c", []byte{0x0d, 0xff, 0x81, 0x03, 0x01})
+
+	f.Fuzz(func(t *testing.T, cookie string, stored []byte) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Cookie", cookie)
+
+		store := &testMemoryStore{}
+		if c, err := r.Cookie("session"); err == nil {
+			store.data = map[string][]byte{c.Value: stored}
+		}
+
+		// the stored data of the untrusted token is decoded, the malformed data must be an error
+		s := New(Config{}, store)
+		if r1, err := s.ReadSessionCookie(r); err == nil {
+			ctx := r1.Context()
+			_ = s.Keys(ctx)
+			_ = s.Get(ctx, "user")
+			_, _, _ = s.Commit(ctx)
+		}
+
+		rememberStore := newTestRememberStore()
+		rememberStore.tokens["sel"] = RememberToken{
+			Selector:     "sel",
+			VerifierHash: hashVerifier("verifier"),
+			UserID:       "user",
+			Expiry:       time.Now().Add(time.Hour),
+		}
+
+		m := NewRemember(RememberConfig{}, rememberStore)
+		_, _ = m.Consume(context.Background(), httptest.NewRecorder(), r)
+		_ = m.Revoke(context.Background(), httptest.NewRecorder(), r)
+	})
+}
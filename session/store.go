@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -24,3 +25,34 @@ type Store interface {
 	// expiry time should be overwritten.
 	Commit(ctx context.Context, token string, data []byte, expiry time.Time) (err error)
 }
+
+// ErrConflict is returned by [Session.Commit] in the strict commit mode (see Config.StrictCommit)
+// when the session has been committed by another request cycle since it was loaded.
+var ErrConflict = errors.New("session: concurrent modification")
+
+// VersionedStore is the optional interface of the stores supporting the optimistic locking
+// of the sessions, required by Config.StrictCommit.
+type VersionedStore interface {
+	Store
+
+	// FindVersion acts like Find, and also returns the version of the session
+	// data, which is changed by every commit.
+	FindVersion(ctx context.Context, token string) (data []byte, version uint64, found bool, err error)
+
+	// CommitVersion acts like Commit, but only if the version of the stored
+	// session data is still the given one, where 0 means the session token must
+	// not exist, and returns the new version. Otherwise, it should return
+	// ErrConflict and leave the stored session data intact.
+	CommitVersion(ctx context.Context, token string, data []byte, expiry time.Time, version uint64) (newVersion uint64, err error)
+}
+
+// StatelessStore is the optional interface of the stores keeping the session data in the
+// session cookie itself, ex. [CookieStore]. The session token is the sealed session data,
+// which is split across multiple cookies if it's too large for one.
+type StatelessStore interface {
+	Store
+
+	// Seal returns the session token holding the session data and the expiry time,
+	// which Find opens.
+	Seal(ctx context.Context, data []byte, expiry time.Time) (token string, err error)
+}
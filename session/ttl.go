@@ -0,0 +1,65 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ttlKeyPrefix prefixes the keys of the expiry times (unix nanoseconds) of the values put with a TTL.
+const ttlKeyPrefix = InternalKeyPrefix + "ttl."
+
+// PutWithTTL adds a key and corresponding value to the session data like Put,
+// but the value expires after the ttl independently of the session, ex. for the
+// one-time codes and the nonces. The expired values are treated as absent and
+// are deleted when the session is loaded or committed. If the ttl is not
+// positive, PutWithTTL acts like Put.
+func (s *Session) PutWithTTL(ctx context.Context, key string, val any, ttl time.Duration) {
+	sd := s.getSessionDataFromContext(ctx)
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if !writable(ctx, sd) {
+		return
+	}
+
+	sd.values[key] = val
+	if ttl > 0 {
+		sd.values[ttlKeyPrefix+key] = s.config.Clock.Now().Add(ttl).UnixNano()
+	} else {
+		delete(sd.values, ttlKeyPrefix+key)
+	}
+	sd.status = Modified
+}
+
+// expired reports whether the value of the key has been put with a TTL which has elapsed.
+func (sd *sessionData) expired(key string, now time.Time) bool {
+	exp, ok := int64Value(sd.values[ttlKeyPrefix+key])
+	return ok && exp <= now.UnixNano()
+}
+
+// int64Value returns the int64 of the internal values, which are decoded as ints by [JWTCodec].
+func int64Value(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// deleteExpired deletes the expired values and reports whether any have been deleted.
+func (sd *sessionData) deleteExpired(now time.Time) bool {
+	var deleted bool
+	for k := range sd.values {
+		if key, ok := strings.CutPrefix(k, ttlKeyPrefix); ok && sd.expired(key, now) {
+			delete(sd.values, key)
+			delete(sd.values, k)
+			deleted = true
+		}
+	}
+	return deleted
+}
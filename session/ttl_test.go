@@ -0,0 +1,73 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/clock"
+)
+
+func TestSession_PutWithTTL(t *testing.T) {
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	store := NewMemoryStore(MemoryStoreConfig{CleanupInterval: -1, Clock: clk})
+	defer store.Stop()
+
+	session := New(Config{Clock: clk}, store)
+
+	ctx, err := session.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	session.Put(ctx, "user", "ann")
+	session.PutWithTTL(ctx, "otp", "123456", time.Minute)
+	session.PutWithTTL(ctx, "nonce", "abc", time.Hour)
+	assert.Equal(t, "123456", session.GetString(ctx, "otp"))
+	assert.Equal(t, []string{"__ttl.nonce", "__ttl.otp", "nonce", "otp", "user"}, session.Keys(ctx))
+
+	clk.Add(time.Minute)
+
+	// the expired value is absent before the commit
+	assert.Nil(t, session.Get(ctx, "otp"))
+	assert.False(t, session.Has(ctx, "otp"))
+	assert.Nil(t, session.Pop(ctx, "otp"))
+	assert.Equal(t, []string{"__ttl.nonce", "nonce", "user"}, session.Keys(ctx))
+
+	token, _, err := session.Commit(ctx)
+	require.NoError(t, err)
+
+	clk.Add(time.Hour)
+
+	// the expired value is deleted on load
+	ctx, err = session.Load(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, Modified, session.Status(ctx))
+	assert.Equal(t, []string{"user"}, session.Keys(ctx))
+}
+
+func TestSession_PutWithTTL_Put(t *testing.T) {
+	clk := clock.NewMock(time.Unix(1_700_000_000, 0))
+	session := New(Config{Clock: clk}, &MockStore{})
+
+	ctx, err := session.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	// Put drops the TTL of the value
+	session.PutWithTTL(ctx, "a", 1, time.Minute)
+	session.Put(ctx, "a", 2)
+
+	// the non-positive TTL acts like Put
+	session.PutWithTTL(ctx, "b", 1, time.Minute)
+	session.PutWithTTL(ctx, "b", 2, 0)
+
+	// Remove drops the TTL of the value
+	session.PutWithTTL(ctx, "c", 1, time.Minute)
+	session.Remove(ctx, "c")
+
+	clk.Add(time.Hour)
+	assert.Equal(t, 2, session.GetInt(ctx, "a"))
+	assert.Equal(t, 2, session.GetInt(ctx, "b"))
+	assert.Equal(t, []string{"a", "b"}, session.Keys(ctx))
+}
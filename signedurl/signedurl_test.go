@@ -0,0 +1,110 @@
+package signedurl
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldKey = Key{ID: "old", Secret: []byte("old-secret")}
+	newKey = Key{ID: "new", Secret: []byte("new-secret")}
+)
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u
+}
+
+func TestSigner_Verify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := New(Config{TimeFunc: func() time.Time { return now }}, newKey, oldKey)
+
+	signed, err := s.Sign(http.MethodGet, "/files/report.pdf?inline=1", time.Hour)
+	require.NoError(t, err)
+
+	u := mustParse(t, signed)
+	assert.Equal(t, "/files/report.pdf", u.Path)
+	assert.Equal(t, "1", u.Query().Get("inline"))
+	assert.Equal(t, "new", u.Query().Get("kid"))
+	assert.Equal(t, "1700003600", u.Query().Get("expires"))
+
+	tamper := func(fn func(q url.Values)) string {
+		u := mustParse(t, signed)
+		q := u.Query()
+		fn(q)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		expected error
+	}{
+		{name: "valid", method: http.MethodGet, url: signed},
+		{name: "head of get", method: http.MethodHead, url: signed},
+		{name: "other method", method: http.MethodDelete, url: signed, expected: ErrInvalidSignature},
+		{name: "other path", method: http.MethodGet, url: "/files/secret.pdf?" + u.RawQuery, expected: ErrInvalidSignature},
+		{name: "added query", method: http.MethodGet, url: tamper(func(q url.Values) { q.Set("download", "1") }), expected: ErrInvalidSignature},
+		{name: "extended expiry", method: http.MethodGet, url: tamper(func(q url.Values) { q.Set("expires", "1800000000") }), expected: ErrInvalidSignature},
+		{name: "unknown key", method: http.MethodGet, url: tamper(func(q url.Values) { q.Set("kid", "unknown") }), expected: ErrInvalidSignature},
+		{name: "malformed signature", method: http.MethodGet, url: tamper(func(q url.Values) { q.Set("signature", "!") }), expected: ErrInvalidSignature},
+		{name: "missing signature", method: http.MethodGet, url: "/files/report.pdf?inline=1", expected: ErrMissingSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Verify(tt.method, mustParse(t, tt.url))
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestSigner_Expired(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := New(Config{TimeFunc: func() time.Time { return now }}, newKey)
+
+	signed, err := s.Sign(http.MethodGet, "/files/report.pdf", time.Minute)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	assert.ErrorIs(t, s.Verify(http.MethodGet, mustParse(t, signed)), ErrExpired)
+}
+
+func TestSigner_KeyRotation(t *testing.T) {
+	signed, err := New(Config{}, oldKey).Sign(http.MethodGet, "/files/report.pdf", time.Hour)
+	require.NoError(t, err)
+
+	assert.NoError(t, New(Config{}, newKey, oldKey).Verify(http.MethodGet, mustParse(t, signed)))
+	assert.ErrorIs(t, New(Config{}, newKey).Verify(http.MethodGet, mustParse(t, signed)), ErrInvalidSignature)
+}
+
+func TestSigner_Resign(t *testing.T) {
+	s := New(Config{}, newKey)
+
+	signed, err := s.Sign(http.MethodGet, "/files/report.pdf", time.Hour)
+	require.NoError(t, err)
+
+	// the previous signature is replaced
+	resigned, err := s.Sign(http.MethodGet, signed, 2*time.Hour)
+	require.NoError(t, err)
+	assert.NoError(t, s.Verify(http.MethodGet, mustParse(t, resigned)))
+}
+
+func TestNew_Panics(t *testing.T) {
+	assert.Panics(t, func() { New(Config{}) })
+	assert.Panics(t, func() { New(Config{}, Key{ID: "empty"}) })
+}
@@ -0,0 +1,109 @@
+package wo
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gowool/hook"
+)
+
+// RouteInfo describes a route registered by the last successful [Router.Build].
+type RouteInfo struct {
+	RouteRegistration
+
+	// Name is the name of the route, see [Route.Named].
+	Name string
+
+	// Stacks are the names of the middleware stacks protecting the route, in the execution order.
+	Stacks []string
+
+	// Middlewares are the IDs of the middlewares of the route (the pre middlewares included) in
+	// the execution order, the function names in case of the anonymous middlewares.
+	Middlewares []string
+
+	// Handler is the function name of the route action.
+	Handler string
+
+	// Budget is the time budget of the route, see [Route.Budget].
+	Budget time.Duration
+}
+
+// Stack registers a named middleware stack, which groups and routes reference by name
+// (see [RouterGroup.UseStack] and [Route.UseStack]), so the same middlewares are reused
+// consistently. A stack with the same name replaces the previously registered one.
+//
+// The stacks are resolved by [Router.Build], the middlewares of a stack run before the
+// middlewares bound to the group (or the route) referencing it and can be excluded
+// by their ID with Unbind, like the parent groups middlewares.
+//
+//	r.Stack("authenticated", sessionMiddleware, authMiddleware)
+//	r.Group("/account").UseStack("authenticated")
+func (r *Router[T]) Stack(name string, middlewares ...*hook.Handler[T]) {
+	if name == "" {
+		panic("wo: middleware stack must have a name")
+	}
+	for _, m := range middlewares {
+		if m == nil {
+			panic(fmt.Sprintf("wo: middleware stack %q: the provided middleware is nil", name))
+		}
+	}
+
+	r.stacks[name] = slices.Clone(middlewares)
+}
+
+// StackFunc registers a named stack of anonymous middleware functions, see [Router.Stack].
+func (r *Router[T]) StackFunc(name string, middlewareFuncs ...func(e T) error) {
+	middlewares := make([]*hook.Handler[T], 0, len(middlewareFuncs))
+	for _, m := range middlewareFuncs {
+		if m == nil {
+			panic(fmt.Sprintf("wo: middleware stack %q: the provided middleware is nil", name))
+		}
+		middlewares = append(middlewares, &hook.Handler[T]{Func: m})
+	}
+
+	r.Stack(name, middlewares...)
+}
+
+// Stacks returns the names of the registered middleware stacks.
+func (r *Router[T]) Stacks() []string {
+	names := make([]string, 0, len(r.stacks))
+	for name := range r.stacks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Routes returns the routes registered by the last successful [Router.Build] in the registration order.
+func (r *Router[T]) Routes() []RouteInfo {
+	return slices.Clone(r.routeTable().routes)
+}
+
+func (r *Router[T]) stackMiddlewares(names []string) ([]*hook.Handler[T], error) {
+	var middlewares []*hook.Handler[T]
+	for _, name := range names {
+		stack, ok := r.stacks[name]
+		if !ok {
+			return nil, fmt.Errorf("middleware stack %q is not registered", name)
+		}
+		middlewares = append(middlewares, stack...)
+	}
+	return middlewares, nil
+}
+
+// UseStack references the named middleware stacks registered with [Router.Stack],
+// their middlewares run before the group middlewares.
+func (group *RouterGroup[T]) UseStack(names ...string) *RouterGroup[T] {
+	group.stacks = append(group.stacks, names...)
+
+	return group
+}
+
+// UseStack references the named middleware stacks registered with [Router.Stack],
+// their middlewares run before the route middlewares.
+func (route *Route[T]) UseStack(names ...string) *Route[T] {
+	route.stacks = append(route.stacks, names...)
+
+	return route
+}
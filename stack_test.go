@@ -0,0 +1,133 @@
+package wo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tagMiddleware(tag string) func(*Event) error {
+	return func(e *Event) error {
+		e.Response().Header().Add("X-Tag", tag)
+		return e.Next()
+	}
+}
+
+func TestRouterStack(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.StackFunc("authenticated", tagMiddleware("session"), tagMiddleware("auth"))
+	router.Stack("audited", &hook.Handler[*Event]{ID: "audit", Func: tagMiddleware("audit")})
+
+	action := func(e *Event) error { return e.NoContent(http.StatusNoContent) }
+
+	account := router.Group("/account").UseStack("authenticated").BindFunc(tagMiddleware("group"))
+	account.GET("/profile", action)
+	account.DELETE("/profile", action).UseStack("audited").BindFunc(tagMiddleware("route"))
+	account.GET("/public", action).Unbind("audit")
+	router.GET("/ping", action)
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		method   string
+		target   string
+		expected string
+	}{
+		{http.MethodGet, "/account/profile", "session,auth,group"},
+		{http.MethodDelete, "/account/profile", "session,auth,group,audit,route"},
+		{http.MethodGet, "/ping", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, tt.expected, strings.Join(rec.Header().Values("X-Tag"), ","))
+		})
+	}
+
+	routes := router.Routes()
+	require.Len(t, routes, 4)
+	assert.Equal(t, "GET /account/profile", routes[0].Pattern)
+	assert.Equal(t, []string{"authenticated"}, routes[0].Stacks)
+	assert.Equal(t, "DELETE /account/profile", routes[1].Pattern)
+	assert.Equal(t, []string{"authenticated", "audited"}, routes[1].Stacks)
+	assert.Equal(t, []string{"", "/account"}, routes[1].Groups)
+	assert.Equal(t, "GET /ping", routes[3].Pattern)
+	assert.Empty(t, routes[3].Stacks)
+
+	assert.Equal(t, []string{"audited", "authenticated"}, router.Stacks())
+}
+
+func TestRouterStack_Exclude(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.Stack("audited",
+		&hook.Handler[*Event]{ID: "audit", Func: tagMiddleware("audit")},
+		&hook.Handler[*Event]{Func: tagMiddleware("log")},
+	)
+
+	g := router.Group("/admin").UseStack("audited")
+	g.GET("/health", func(e *Event) error { return e.NoContent(http.StatusNoContent) }).Unbind("audit")
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/health", nil))
+	assert.Equal(t, []string{"log"}, rec.Header().Values("X-Tag"))
+}
+
+func TestRouterStack_Unknown(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.Group("/api").UseStack("missing").GET("/users", func(e *Event) error { return nil })
+
+	_, err := router.Build(nil)
+	assert.ErrorContains(t, err, `group "/api": middleware stack "missing" is not registered`)
+}
+
+func TestRouterStack_Replace(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.StackFunc("tagged", tagMiddleware("old"))
+	router.GET("/", func(e *Event) error { return e.NoContent(http.StatusNoContent) }).UseStack("tagged")
+	router.StackFunc("tagged", tagMiddleware("new"))
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, []string{"new"}, rec.Header().Values("X-Tag"))
+}
+
+func TestRouterStack_Panics(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+
+	assert.Panics(t, func() { router.Stack("") })
+	assert.Panics(t, func() { router.Stack("a", nil) })
+	assert.Panics(t, func() { router.StackFunc("a", nil) })
+}
+
+func TestRouterApplyGroups_Stacks(t *testing.T) {
+	router := New[*Event](eventFactory, errorHandler)
+	router.StackFunc("tagged", tagMiddleware("stack"))
+
+	groups, err := router.ApplyGroups(GroupConfig{Name: "api", Prefix: "/api", Stacks: []string{"tagged"}})
+	require.NoError(t, err)
+
+	groups["api"].GET("/users", func(e *Event) error { return e.NoContent(http.StatusNoContent) })
+
+	h, err := router.Build(nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	assert.Equal(t, []string{"stack"}, rec.Header().Values("X-Tag"))
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gowool/wo/signedurl"
+)
+
+var _ Blob = (*FS)(nil)
+
+type FSConfig struct {
+	// Root is the directory of the objects, it's created unless it exists.
+	Root string `env:"ROOT" json:"root,omitempty" yaml:"root,omitempty"`
+
+	// BaseURL is the URL the objects are served at, ex. by the route with the
+	// [wo.Event.StaticFS] handler, the signed URLs are built upon it.
+	//
+	// Default: "/"
+	BaseURL string `env:"BASE_URL" json:"baseURL,omitempty" yaml:"baseURL,omitempty"`
+
+	// DirPerm is the permission of the created directories.
+	//
+	// Default: 0o750
+	DirPerm fs.FileMode `env:"DIR_PERM" json:"dirPerm,omitempty" yaml:"dirPerm,omitempty"`
+
+	// FilePerm is the permission of the created files.
+	//
+	// Default: 0o640
+	FilePerm fs.FileMode `env:"FILE_PERM" json:"filePerm,omitempty" yaml:"filePerm,omitempty"`
+}
+
+func (c *FSConfig) SetDefaults() {
+	if c.BaseURL == "" {
+		c.BaseURL = "/"
+	}
+	if c.DirPerm == 0 {
+		c.DirPerm = 0o750
+	}
+	if c.FilePerm == 0 {
+		c.FilePerm = 0o640
+	}
+}
+
+func (c *FSConfig) Validate() error {
+	if c.Root == "" {
+		return errors.New("storage: root is required")
+	}
+	return nil
+}
+
+// FS is the blob of the directory, the keys are the paths relative to it. The access is
+// confined to the directory, see [os.Root].
+//
+// The signed URLs are the ones of the [signedurl.Signer], the route serving them should
+// verify them, ex. with the [middleware.SignedURL]:
+//
+//	router.GET("/files/{path...}", wo.StaticFS[*wo.Event](os.DirFS("./uploads"), false)).
+//		Use(middleware.SignedURL[*wo.Event](signer))
+type FS struct {
+	config FSConfig
+	root   *os.Root
+	signer *signedurl.Signer
+}
+
+// NewFS returns the blob of the directory, the signer may be nil when the signed URLs aren't
+// used.
+func NewFS(cfg FSConfig, signer *signedurl.Signer) (*FS, error) {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	if err := os.MkdirAll(cfg.Root, cfg.DirPerm); err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(cfg.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FS{config: cfg, root: root, signer: signer}, nil
+}
+
+// Close releases the directory.
+func (b *FS) Close() error {
+	return b.root.Close()
+}
+
+func (b *FS) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*Object, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	if dir := path.Dir(key); dir != "." {
+		if err := b.root.MkdirAll(dir, b.config.DirPerm); err != nil {
+			return nil, err
+		}
+	}
+
+	// the content is written aside and renamed, so the readers never see it partially written
+	tmp, tmpName, err := b.createTemp(key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if tmp != nil {
+			_ = tmp.Close()
+			_ = b.root.Remove(tmpName)
+		}
+	}()
+
+	n, err := io.Copy(tmp, contextReader{ctx: ctx, r: r})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Size > 0 && n != opts.Size {
+		return nil, fmt.Errorf("storage: %s: got %d bytes, want %d: %w", key, n, opts.Size, io.ErrUnexpectedEOF)
+	}
+	if err = tmp.Close(); err != nil {
+		_ = b.root.Remove(tmpName)
+		tmp = nil
+		return nil, err
+	}
+	tmp = nil
+	if err = b.root.Rename(tmpName, key); err != nil {
+		_ = b.root.Remove(tmpName)
+		return nil, err
+	}
+
+	fi, err := b.root.Stat(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ct := opts.ContentType
+	if ct == "" {
+		ct = contentType(key)
+	}
+	return &Object{Key: key, Size: fi.Size(), ContentType: ct, ModTime: fi.ModTime()}, nil
+}
+
+func (b *FS) Get(_ context.Context, key string) (io.ReadCloser, *Object, error) {
+	if !validKey(key) {
+		return nil, nil, ErrInvalidKey
+	}
+
+	f, err := b.root.Open(key)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	if fi.IsDir() {
+		_ = f.Close()
+		return nil, nil, ErrNotFound
+	}
+
+	return f, &Object{Key: key, Size: fi.Size(), ContentType: contentType(key), ModTime: fi.ModTime()}, nil
+}
+
+func (b *FS) Delete(_ context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+
+	if err := b.root.Remove(key); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL returns the URL of the object under the base URL signed for GET, or HEAD, which
+// are the only methods allowed.
+func (b *FS) SignedURL(_ context.Context, key, method string, ttl time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	if b.signer == nil {
+		return "", errors.New("storage: signer is nil")
+	}
+	if method != http.MethodGet && method != http.MethodHead {
+		return "", fmt.Errorf("storage: signed url for %s: %w", method, errors.ErrUnsupported)
+	}
+
+	return b.signer.Sign(method, strings.TrimSuffix(b.config.BaseURL, "/")+"/"+uriEncode(key, true), ttl)
+}
+
+// createTemp creates the temporary file next to the key, it returns the file and its name.
+func (b *FS) createTemp(key string) (*os.File, string, error) {
+	var suffix [8]byte
+	for range 10 {
+		_, _ = rand.Read(suffix[:])
+		name := path.Join(path.Dir(key), "."+path.Base(key)+"."+hex.EncodeToString(suffix[:])+".tmp")
+
+		f, err := b.root.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, b.config.FilePerm)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return f, name, err
+	}
+	return nil, "", errors.New("storage: failed to create a temporary file")
+}
+
+// contextReader fails the reads once the context is done, so the canceled upload stops.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/signedurl"
+)
+
+func newTestFS(t *testing.T) *FS {
+	t.Helper()
+
+	signer := signedurl.New(signedurl.Config{}, signedurl.Key{ID: "1", Secret: []byte("secret")})
+	b, err := NewFS(FSConfig{Root: filepath.Join(t.TempDir(), "blobs"), BaseURL: "/files/"}, signer)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+func TestFS_PutGetDelete(t *testing.T) {
+	b := newTestFS(t)
+	ctx := context.Background()
+
+	obj, err := b.Put(ctx, "docs/report.json", strings.NewReader(`{"a":1}`), PutOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "docs/report.json", obj.Key)
+	assert.Equal(t, int64(7), obj.Size)
+	assert.Equal(t, "application/json", obj.ContentType)
+
+	// replaced
+	_, err = b.Put(ctx, "docs/report.json", strings.NewReader(`{}`), PutOptions{ContentType: "text/plain", Size: 2})
+	require.NoError(t, err)
+
+	rc, obj, err := b.Get(ctx, "docs/report.json")
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, `{}`, string(data))
+	assert.Equal(t, int64(2), obj.Size)
+
+	// no temporary files are left
+	entries, err := os.ReadDir(filepath.Join(b.config.Root, "docs"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, b.Delete(ctx, "docs/report.json"))
+	require.NoError(t, b.Delete(ctx, "docs/report.json"))
+
+	_, _, err = b.Get(ctx, "docs/report.json")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, _, err = b.Get(ctx, "docs")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFS_PutFailed(t *testing.T) {
+	b := newTestFS(t)
+	ctx := context.Background()
+
+	_, err := b.Put(ctx, "a.txt", strings.NewReader("original"), PutOptions{Size: -1})
+	require.NoError(t, err)
+
+	// the truncated content
+	_, err = b.Put(ctx, "a.txt", strings.NewReader("new"), PutOptions{Size: 10})
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// the failed read
+	_, err = b.Put(ctx, "a.txt", io.MultiReader(strings.NewReader("new"), iotestErrReader{}), PutOptions{Size: -1})
+	assert.Error(t, err)
+
+	// the canceled context
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = b.Put(canceled, "a.txt", strings.NewReader("new"), PutOptions{Size: -1})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	rc, _, err := b.Get(ctx, "a.txt")
+	require.NoError(t, err)
+	data, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	assert.Equal(t, "original", string(data))
+
+	entries, err := os.ReadDir(b.config.Root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFS_InvalidKey(t *testing.T) {
+	b := newTestFS(t)
+
+	for _, key := range []string{"", ".", "../x", "/abs", "a//b", "a/../b"} {
+		_, err := b.Put(context.Background(), key, strings.NewReader("x"), PutOptions{Size: -1})
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+		_, _, err = b.Get(context.Background(), key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestFS_SignedURL(t *testing.T) {
+	b := newTestFS(t)
+
+	link, err := b.SignedURL(context.Background(), "docs/a b.pdf", http.MethodGet, time.Hour)
+	require.NoError(t, err)
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/files/docs/a%20b.pdf", u.EscapedPath())
+	assert.NoError(t, b.signer.Verify(http.MethodGet, u))
+
+	_, err = b.SignedURL(context.Background(), "docs/a.pdf", http.MethodPut, time.Hour)
+	assert.ErrorIs(t, err, errors.ErrUnsupported)
+}
+
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
@@ -0,0 +1,368 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Blob = (*S3)(nil)
+
+// minPartSize is the minimum size of the multipart upload parts, except the last one.
+const minPartSize = 5 << 20
+
+type S3Config struct {
+	// Endpoint is the URL of the S3-compatible service, ex. "https://s3.eu-west-1.amazonaws.com"
+	// or "http://localhost:9000" of MinIO.
+	Endpoint string `env:"ENDPOINT" json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+
+	// Region is the region of the bucket.
+	//
+	// Default: "us-east-1"
+	Region string `env:"REGION" json:"region,omitempty" yaml:"region,omitempty"`
+
+	Bucket          string `env:"BUCKET" json:"bucket,omitempty" yaml:"bucket,omitempty"`
+	AccessKeyID     string `env:"ACCESS_KEY_ID" json:"accessKeyID,omitempty" yaml:"accessKeyID,omitempty"`
+	SecretAccessKey string `env:"SECRET_ACCESS_KEY" json:"-" yaml:"-"`
+	SessionToken    string `env:"SESSION_TOKEN" json:"-" yaml:"-"`
+
+	// PathStyle addresses the bucket in the path instead of the host name, ex. for MinIO.
+	PathStyle bool `env:"PATH_STYLE" json:"pathStyle,omitempty" yaml:"pathStyle,omitempty"`
+
+	// PartSize is the size of the parts of the uploads of the unknown or the large size,
+	// each part is buffered in the memory, at least 5mb.
+	//
+	// Default: 8mb
+	PartSize int64 `env:"PART_SIZE" json:"partSize,omitempty" yaml:"partSize,omitempty"`
+
+	// Client is the HTTP client of the requests.
+	//
+	// Default: http.DefaultClient
+	Client *http.Client `json:"-" yaml:"-"`
+
+	// TimeFunc returns the current time.
+	//
+	// Default: time.Now
+	TimeFunc func() time.Time `json:"-" yaml:"-"`
+}
+
+func (c *S3Config) SetDefaults() {
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	if c.PartSize == 0 {
+		c.PartSize = 8 << 20
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	if c.TimeFunc == nil {
+		c.TimeFunc = time.Now
+	}
+}
+
+func (c *S3Config) Validate() error {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("storage: invalid s3 endpoint")
+	}
+	if c.Bucket == "" {
+		return errors.New("storage: s3 bucket is required")
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return errors.New("storage: s3 credentials are required")
+	}
+	if c.PartSize < minPartSize {
+		return errors.New("storage: s3 part size must be at least 5mb")
+	}
+	return nil
+}
+
+// S3Error is the error response of the S3 service.
+type S3Error struct {
+	Status    int    `xml:"-"`
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+	RequestID string `xml:"RequestId"`
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("storage: s3: %d %s", e.Status, http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("storage: s3: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// S3 is the blob of the bucket of the S3-compatible object storage. The uploads of the known
+// size up to the part size are put at once, the others are streamed as the multipart uploads
+// part by part.
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	creds    credentials
+}
+
+func NewS3(cfg S3Config) *S3 {
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	endpoint, _ := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+
+	return &S3{
+		config:   cfg,
+		endpoint: endpoint,
+		creds: credentials{
+			accessKeyID:  cfg.AccessKeyID,
+			secret:       cfg.SecretAccessKey,
+			sessionToken: cfg.SessionToken,
+			region:       cfg.Region,
+			service:      "s3",
+		},
+	}
+}
+
+func (b *S3) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*Object, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+	if opts.ContentType == "" {
+		opts.ContentType = contentType(key)
+	}
+
+	if opts.Size > 0 && opts.Size <= b.config.PartSize {
+		return b.putObject(ctx, key, r, opts.Size, opts.ContentType)
+	}
+
+	// the first part tells whether the content fits in a single put
+	buf := make([]byte, b.config.PartSize)
+	n, err := io.ReadFull(r, buf)
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		if opts.Size > 0 && int64(n) != opts.Size {
+			return nil, fmt.Errorf("storage: %s: got %d bytes, want %d: %w", key, n, opts.Size, io.ErrUnexpectedEOF)
+		}
+		return b.putObject(ctx, key, bytes.NewReader(buf[:n]), int64(n), opts.ContentType)
+	case err != nil:
+		return nil, err
+	}
+
+	return b.putMultipart(ctx, key, r, buf, opts)
+}
+
+func (b *S3) putObject(ctx context.Context, key string, r io.Reader, size int64, contentType string) (*Object, error) {
+	header := http.Header{"Content-Type": {contentType}}
+
+	res, err := b.do(ctx, http.MethodPut, key, nil, r, size, header)
+	if err != nil {
+		return nil, err
+	}
+	_ = res.Body.Close()
+
+	return &Object{Key: key, Size: size, ContentType: contentType, ETag: res.Header.Get("ETag")}, nil
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// putMultipart uploads the first part read into buf and the rest of r part by part, the
+// upload is aborted on failure.
+func (b *S3) putMultipart(ctx context.Context, key string, r io.Reader, buf []byte, opts PutOptions) (_ *Object, err error) {
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err = b.doXML(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, http.Header{"Content-Type": {opts.ContentType}}, &initiated); err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			// the parts of the abandoned upload are billed until it's aborted
+			res, abortErr := b.do(context.WithoutCancel(ctx), http.MethodDelete, key, url.Values{"uploadId": {initiated.UploadID}}, nil, 0, nil)
+			if abortErr == nil {
+				_ = res.Body.Close()
+			}
+		}
+	}()
+
+	var (
+		parts []completedPart
+		size  int64
+		n     = len(buf)
+	)
+	for n > 0 {
+		query := url.Values{"partNumber": {strconv.Itoa(len(parts) + 1)}, "uploadId": {initiated.UploadID}}
+
+		res, err := b.do(ctx, http.MethodPut, key, query, bytes.NewReader(buf[:n]), int64(n), nil)
+		if err != nil {
+			return nil, err
+		}
+		_ = res.Body.Close()
+
+		parts = append(parts, completedPart{PartNumber: len(parts) + 1, ETag: res.Header.Get("ETag")})
+		size += int64(n)
+
+		n, err = io.ReadFull(r, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+	}
+	if opts.Size > 0 && size != opts.Size {
+		return nil, fmt.Errorf("storage: %s: got %d bytes, want %d: %w", key, size, opts.Size, io.ErrUnexpectedEOF)
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return nil, err
+	}
+
+	var completed struct {
+		ETag string `xml:"ETag"`
+	}
+	if err = b.doXML(ctx, http.MethodPost, key, url.Values{"uploadId": {initiated.UploadID}}, body, nil, &completed); err != nil {
+		return nil, err
+	}
+
+	return &Object{Key: key, Size: size, ContentType: opts.ContentType, ETag: completed.ETag}, nil
+}
+
+func (b *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	if !validKey(key) {
+		return nil, nil, ErrInvalidKey
+	}
+
+	res, err := b.do(ctx, http.MethodGet, key, nil, nil, 0, nil)
+	if err != nil {
+		var s3Err *S3Error
+		if errors.As(err, &s3Err) && s3Err.Status == http.StatusNotFound {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	obj := &Object{
+		Key:         key,
+		Size:        res.ContentLength,
+		ContentType: res.Header.Get("Content-Type"),
+		ETag:        res.Header.Get("ETag"),
+	}
+	obj.ModTime, _ = http.ParseTime(res.Header.Get("Last-Modified"))
+
+	return res.Body, obj, nil
+}
+
+func (b *S3) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+
+	res, err := b.do(ctx, http.MethodDelete, key, nil, nil, 0, nil)
+	if err != nil {
+		var s3Err *S3Error
+		if errors.As(err, &s3Err) && s3Err.Status == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+	return res.Body.Close()
+}
+
+// SignedURL returns the presigned URL of the object, ex. GET to download the private object,
+// or PUT to upload it from the browser directly. The ttl is at most 7 days.
+func (b *S3) SignedURL(_ context.Context, key, method string, ttl time.Duration) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	if ttl < time.Second || ttl > 7*24*time.Hour {
+		return "", errors.New("storage: s3 signed url ttl must be between 1s and 7 days")
+	}
+
+	return b.creds.presign(method, b.objectURL(key, nil), b.config.TimeFunc(), ttl), nil
+}
+
+func (b *S3) objectURL(key string, query url.Values) *url.URL {
+	u := *b.endpoint
+	if b.config.PathStyle {
+		u.Path += "/" + b.config.Bucket + "/" + key
+	} else {
+		u.Host = b.config.Bucket + "." + u.Host
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, true)
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// do sends the signed request, the error responses are returned as [S3Error].
+func (b *S3) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	if body == nil {
+		body = http.NoBody
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.objectURL(key, query).String(), io.NopCloser(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	b.creds.signRequest(req, unsignedPayload, b.config.TimeFunc())
+
+	res, err := b.config.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= http.StatusMultipleChoices {
+		defer func() {
+			_ = res.Body.Close()
+		}()
+		return nil, s3Error(res.StatusCode, res.Body)
+	}
+	return res, nil
+}
+
+// doXML sends the request with the XML body and decodes the XML response to dst. The error
+// may come with the status 200 once the response started, ex. of the completed upload.
+func (b *S3) doXML(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header, dst any) error {
+	res, err := b.do(ctx, method, key, query, bytes.NewReader(body), int64(len(body)), header)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(data, []byte("<Error>")) {
+		return s3Error(res.StatusCode, bytes.NewReader(data))
+	}
+	return xml.Unmarshal(data, dst)
+}
+
+func s3Error(status int, body io.Reader) error {
+	e := &S3Error{Status: status}
+	_ = xml.NewDecoder(io.LimitReader(body, 64<<10)).Decode(e)
+	return e
+}
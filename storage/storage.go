@@ -0,0 +1,90 @@
+// Package storage stores the blobs, ex. the uploaded files, in the file system or in the
+// S3-compatible object storage behind the same [Blob] interface, so the upload endpoints
+// don't depend on a storage SDK:
+//
+//	blob, err := storage.NewFS(storage.FSConfig{Root: "./uploads", BaseURL: "/files"}, signer)
+//	// or
+//	blob := storage.NewS3(storage.S3Config{Endpoint: "https://s3.eu-west-1.amazonaws.com", Region: "eu-west-1", Bucket: "uploads", ...})
+//
+//	router.PUT("/avatars/{id}", func(e *wo.Event) error {
+//		obj, err := storage.PutBody(e, blob, "avatars/"+e.Param("id"))
+//		if err != nil {
+//			return err
+//		}
+//		return e.JSON(http.StatusCreated, obj)
+//	})
+//
+// The uploads are streamed to the blob as they are read, see [PutBody] and [PutMultipart].
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"path"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the object doesn't exist.
+	ErrNotFound = errors.New("storage: object not found")
+
+	// ErrInvalidKey is returned for the empty keys and the ones which aren't the clean
+	// slash separated relative paths, ex. "../secret" or "/a//b".
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// Object is the metadata of the stored object.
+type Object struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"contentType,omitempty"`
+	ETag        string    `json:"etag,omitempty"`
+	ModTime     time.Time `json:"modTime,omitzero"`
+}
+
+// PutOptions are the options of [Blob.Put].
+type PutOptions struct {
+	// ContentType is the media type of the object, it defaults to the one of the key
+	// extension.
+	ContentType string
+
+	// Size is the size of the content when known in advance, zero or negative is unknown,
+	// so the zero value streams the content of any size. The content shorter than the known
+	// size fails the put.
+	Size int64
+}
+
+// Blob is the storage of the objects addressed by the keys, the slash separated relative
+// paths like "avatars/42.png".
+type Blob interface {
+	// Put stores the content read from r under the key, it replaces the existing object.
+	// The object is stored once the content is read to the end, the failed put leaves the
+	// existing object intact.
+	Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (*Object, error)
+
+	// Get returns the content and the metadata of the object, or [ErrNotFound].
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+
+	// Delete removes the object, the missing object isn't an error.
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL returns the URL which allows the method on the object for the ttl
+	// without the credentials, ex. to download the private file.
+	SignedURL(ctx context.Context, key, method string, ttl time.Duration) (string, error)
+}
+
+// validKey reports whether the key is a clean relative path.
+func validKey(key string) bool {
+	return key != "" && key != "." && fs.ValidPath(key)
+}
+
+// contentType returns the media type of the key extension, or the generic binary one.
+func contentType(key string) string {
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gowool/wo"
+)
+
+// PutBody streams the request body to the blob under the key, the content type and the size
+// are the ones of the request headers. Limit the body size beforehand, ex. with
+// [http.MaxBytesReader], the exceeded limit is reported as [wo.ErrStatusRequestEntityTooLarge].
+func PutBody(e *wo.Event, b Blob, key string) (*Object, error) {
+	r := e.Request()
+
+	obj, err := b.Put(e.Context(), key, bodyReader(r), PutOptions{
+		ContentType: r.Header.Get(wo.HeaderContentType),
+		Size:        r.ContentLength,
+	})
+	if err != nil {
+		return nil, uploadError(err)
+	}
+	return obj, nil
+}
+
+// PutMultipart streams the parts of the multipart form to the blob as they are read, unlike
+// [http.Request.ParseMultipartForm] which buffers the large files to the temporary files.
+// The key func returns the key of the part, or the empty key to skip it, ex. the non-file
+// fields which it may read itself:
+//
+//	objs, err := storage.PutMultipart(e, blob, func(p *multipart.Part) (string, error) {
+//		if p.FormName() != "files" || p.FileName() == "" {
+//			return "", nil
+//		}
+//		return "uploads/" + uuid.NewString() + path.Ext(p.FileName()), nil
+//	})
+//
+// The objects stored before a failure are returned along with the error.
+func PutMultipart(e *wo.Event, b Blob, key func(*multipart.Part) (string, error)) ([]*Object, error) {
+	mr, err := e.Request().MultipartReader()
+	if err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			return nil, wo.ErrUnsupportedMediaType.WithInternal(err)
+		}
+		return nil, wo.ErrBadRequest.WithInternal(err)
+	}
+
+	var objs []*Object
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return objs, uploadError(err)
+		}
+
+		name, err := key(part)
+		if err != nil {
+			_ = part.Close()
+			return objs, err
+		}
+		if name == "" {
+			_ = part.Close()
+			continue
+		}
+
+		obj, err := b.Put(e.Context(), name, part, PutOptions{ContentType: part.Header.Get(wo.HeaderContentType)})
+		_ = part.Close()
+		if err != nil {
+			return objs, uploadError(err)
+		}
+		objs = append(objs, obj)
+	}
+}
+
+func bodyReader(r *http.Request) io.Reader {
+	if r.Body == nil {
+		return http.NoBody
+	}
+	return r.Body
+}
+
+// uploadError returns the HTTP error of the client's fault, or the error as is.
+func uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return wo.ErrStatusRequestEntityTooLarge.WithInternal(err)
+	case errors.Is(err, ErrInvalidKey):
+		return wo.ErrBadRequest.WithInternal(err)
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, multipart.ErrMessageTooLarge):
+		return wo.ErrBadRequest.WithInternal(err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/wotest"
+)
+
+func readObject(t *testing.T, b Blob, key string) string {
+	t.Helper()
+
+	rc, _, err := b.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPutBody(t *testing.T) {
+	b := newTestFS(t)
+
+	e, _ := wotest.NewEvent(http.MethodPut, "/avatars/1", wotest.WithBody(strings.NewReader("image"), "image/png"))
+	obj, err := PutBody(e, b, "avatars/1.png")
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", obj.ContentType)
+	assert.Equal(t, "image", readObject(t, b, "avatars/1.png"))
+
+	e, rec := wotest.NewEvent(http.MethodPut, "/avatars/1", wotest.WithBody(strings.NewReader("too large"), "image/png"))
+	e.Request().Body = http.MaxBytesReader(rec, e.Request().Body, 3)
+	_, err = PutBody(e, b, "avatars/2.png")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, wo.AsHTTPError(err).Status)
+
+	e, _ = wotest.NewEvent(http.MethodPut, "/avatars/1", wotest.WithBody(strings.NewReader("x"), "image/png"))
+	_, err = PutBody(e, b, "../escape")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, wo.AsHTTPError(err).Status)
+}
+
+func TestPutMultipart(t *testing.T) {
+	b := newTestFS(t)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("album", "summer"))
+	fw, err := mw.CreateFormFile("files", "a.txt")
+	require.NoError(t, err)
+	_, _ = io.WriteString(fw, "first")
+	fw, err = mw.CreateFormFile("files", "b.txt")
+	require.NoError(t, err)
+	_, _ = io.WriteString(fw, "second")
+	require.NoError(t, mw.Close())
+
+	e, _ := wotest.NewEvent(http.MethodPost, "/upload", wotest.WithBody(&body, mw.FormDataContentType()))
+
+	var album string
+	objs, err := PutMultipart(e, b, func(p *multipart.Part) (string, error) {
+		if p.FileName() == "" {
+			data, err := io.ReadAll(p)
+			album = string(data)
+			return "", err
+		}
+		return album + "/" + p.FileName(), nil
+	})
+	require.NoError(t, err)
+	require.Len(t, objs, 2)
+	assert.Equal(t, "summer/a.txt", objs[0].Key)
+	assert.Equal(t, "first", readObject(t, b, "summer/a.txt"))
+	assert.Equal(t, "second", readObject(t, b, "summer/b.txt"))
+
+	e, _ = wotest.NewEvent(http.MethodPost, "/upload", wotest.WithBody(strings.NewReader("{}"), wo.MIMEApplicationJSON))
+	_, err = PutMultipart(e, b, func(*multipart.Part) (string, error) { return "x", nil })
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, wo.AsHTTPError(err).Status)
+}
@@ -0,0 +1,113 @@
+package wo
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Key is the typed key of the [Store] values. The keys are compared by identity,
+// so the keys with the same name don't collide:
+//
+//	var userKey = wo.NewKey[*User]("user")
+//
+//	wo.Set(e.Store(), userKey, user)
+//	user, ok := wo.Get(e.Store(), userKey)
+type Key[V any] struct {
+	name string
+}
+
+// NewKey returns a new key of the values of type V, the name is used by [Store.Keys]
+// and in the error messages.
+func NewKey[V any](name string) *Key[V] {
+	return &Key[V]{name: name}
+}
+
+func (k *Key[V]) String() string {
+	return k.name
+}
+
+type storeEntry struct {
+	key   any
+	name  string
+	value any
+}
+
+// Store is the per-request value bag living on the [Event], a cheaper alternative to [Event.SetValue]
+// for passing the data between the handlers, since it doesn't allocate a new request context on every call.
+// The values are dropped when the event is reset, so unlike the context values they are not visible
+// to the code holding the request only.
+//
+// It's not safe for concurrent use, as the event itself.
+type Store struct {
+	entries []storeEntry
+}
+
+func (s *Store) index(key any) int {
+	for i := range s.entries {
+		if s.entries[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Has reports whether the key is set.
+func (s *Store) Has(key fmt.Stringer) bool {
+	return s.index(key) >= 0
+}
+
+// Delete removes the value of the key.
+func (s *Store) Delete(key fmt.Stringer) {
+	if i := s.index(key); i >= 0 {
+		s.entries = slices.Delete(s.entries, i, i+1)
+	}
+}
+
+// Keys returns the names of the set keys in the insertion order.
+func (s *Store) Keys() []string {
+	names := make([]string, len(s.entries))
+	for i, entry := range s.entries {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// Len returns the number of the set keys.
+func (s *Store) Len() int {
+	return len(s.entries)
+}
+
+// Reset removes all values keeping the allocated capacity.
+func (s *Store) Reset() {
+	clear(s.entries)
+	s.entries = s.entries[:0]
+}
+
+// Set stores the value of the key, replacing the previous one.
+func Set[V any](s *Store, key *Key[V], value V) {
+	if i := s.index(key); i >= 0 {
+		s.entries[i].value = value
+		return
+	}
+	s.entries = append(s.entries, storeEntry{key: key, name: key.name, value: value})
+}
+
+// Get returns the value of the key.
+func Get[V any](s *Store, key *Key[V]) (V, bool) {
+	if i := s.index(key); i >= 0 {
+		// the nil interface value doesn't assert to the interface V
+		value, _ := s.entries[i].value.(V)
+		return value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// MustGet returns the value of the key, it panics if the key is not set.
+func MustGet[V any](s *Store, key *Key[V]) V {
+	value, ok := Get(s, key)
+	if !ok {
+		panic(fmt.Sprintf("wo: store key %q is not set", key.name))
+	}
+	return value
+}
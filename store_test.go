@@ -0,0 +1,77 @@
+package wo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	userKey := NewKey[string]("user")
+	otherUserKey := NewKey[string]("user")
+	countKey := NewKey[int]("count")
+
+	var s Store
+
+	_, ok := Get(&s, userKey)
+	assert.False(t, ok)
+
+	Set(&s, userKey, "alice")
+	Set(&s, countKey, 1)
+	Set(&s, countKey, 2)
+
+	user, ok := Get(&s, userKey)
+	require.True(t, ok)
+	assert.Equal(t, "alice", user)
+	assert.Equal(t, 2, MustGet(&s, countKey))
+
+	// the keys are compared by identity
+	assert.False(t, s.Has(otherUserKey))
+	assert.Equal(t, []string{"user", "count"}, s.Keys())
+	assert.Equal(t, 2, s.Len())
+
+	s.Delete(userKey)
+	assert.False(t, s.Has(userKey))
+	assert.Equal(t, []string{"count"}, s.Keys())
+
+	assert.PanicsWithValue(t, `wo: store key "user" is not set`, func() { MustGet(&s, userKey) })
+
+	s.Reset()
+	assert.Empty(t, s.Keys())
+}
+
+func TestStore_NilInterface(t *testing.T) {
+	errKey := NewKey[error]("err")
+
+	var s Store
+	Set(&s, errKey, nil)
+
+	err, ok := Get(&s, errKey)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Nil(t, MustGet(&s, errKey))
+}
+
+func TestEvent_Store(t *testing.T) {
+	key := NewKey[int]("key")
+	event, resp, req := newTestEventForEventTest()
+
+	Set(event.Store(), key, 42)
+	assert.Equal(t, 42, MustGet(event.Store(), key))
+
+	event.Reset(resp, req)
+	assert.False(t, event.Store().Has(key))
+}
+
+func BenchmarkStore(b *testing.B) {
+	key := NewKey[int]("key")
+	var s Store
+
+	b.ReportAllocs()
+	for b.Loop() {
+		Set(&s, key, 42)
+		_, _ = Get(&s, key)
+		s.Reset()
+	}
+}
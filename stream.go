@@ -0,0 +1,204 @@
+package wo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const streamBufferSize = 32 * 1024
+
+var streamBufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, streamBufferSize)
+		return &b
+	},
+}
+
+// CopyStream copies the reader to the response writer until EOF or the context is done,
+// ex. on the client disconnect, in which case the context error is returned. The reader
+// implementing [io.Closer] is closed on the context done to abort the blocked read, ex.
+// of the upstream response body.
+//
+// The flush interval controls how the written data is flushed to the client: the negative
+// value flushes after each write, the positive value flushes periodically and zero leaves
+// the flushing to the response writer buffering. It's meant for the long-lived streams, ex.
+// the logs or the LLM tokens, which shouldn't wait in the buffers.
+func CopyStream(ctx context.Context, w http.ResponseWriter, reader io.Reader, flushInterval time.Duration) (int64, error) {
+	if c, ok := reader.(io.Closer); ok {
+		stop := context.AfterFunc(ctx, func() {
+			_ = c.Close()
+		})
+		defer stop()
+	}
+
+	dst := io.Writer(w)
+	if flushInterval != 0 {
+		fw := &flushWriter{w: w, rc: http.NewResponseController(w), interval: flushInterval}
+		defer fw.stop()
+		dst = fw
+	}
+
+	bufp := streamBufferPool.Get().(*[]byte)
+	defer streamBufferPool.Put(bufp)
+	buf := *bufp
+
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+
+		n, rerr := reader.Read(buf)
+		if n > 0 {
+			m, werr := dst.Write(buf[:n])
+			written += int64(m)
+			if werr != nil {
+				return written, werr
+			}
+		}
+		if rerr != nil {
+			if errors.Is(rerr, io.EOF) {
+				return written, nil
+			}
+			if err := ctx.Err(); err != nil {
+				return written, err
+			}
+			return written, rerr
+		}
+	}
+}
+
+// flushWriter flushes the written data after each write or at most once per interval,
+// like the max latency writer of [httputil.ReverseProxy].
+type flushWriter struct {
+	w        io.Writer
+	rc       *http.ResponseController
+	interval time.Duration
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending bool
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	n, err := fw.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	if fw.interval < 0 {
+		fw.flush()
+		return n, nil
+	}
+
+	if fw.pending {
+		return n, nil
+	}
+	fw.pending = true
+
+	if fw.timer == nil {
+		fw.timer = time.AfterFunc(fw.interval, fw.delayedFlush)
+	} else {
+		fw.timer.Reset(fw.interval)
+	}
+	return n, nil
+}
+
+func (fw *flushWriter) delayedFlush() {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	// stopped or already flushed
+	if !fw.pending {
+		return
+	}
+	fw.flush()
+	fw.pending = false
+}
+
+// stop stops the periodic flushes and flushes the pending data.
+func (fw *flushWriter) stop() {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.timer != nil {
+		fw.timer.Stop()
+	}
+	if fw.pending {
+		fw.flush()
+		fw.pending = false
+	}
+}
+
+func (fw *flushWriter) flush() {
+	_ = fw.rc.Flush()
+}
+
+// StreamWriter writes the streaming response of [Event.StreamFunc].
+type StreamWriter struct {
+	ctx     context.Context
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	written int64
+}
+
+func newStreamWriter(ctx context.Context, w http.ResponseWriter) *StreamWriter {
+	return &StreamWriter{ctx: ctx, w: w, rc: http.NewResponseController(w)}
+}
+
+// Write writes the data to the response, it blocks while the client doesn't keep up. Once
+// the client is gone, the context error is returned.
+func (sw *StreamWriter) Write(p []byte) (int, error) {
+	if err := sw.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := sw.w.Write(p)
+	sw.written += int64(n)
+	if err != nil {
+		if cerr := sw.ctx.Err(); cerr != nil {
+			return n, cerr
+		}
+	}
+	return n, err
+}
+
+// WriteString writes the string to the response like [StreamWriter.Write].
+func (sw *StreamWriter) WriteString(s string) (int, error) {
+	return sw.Write([]byte(s))
+}
+
+// Flush sends the buffered data to the client. The response writers not supporting
+// the flushing are ignored. Once the client is gone, the context error is returned.
+func (sw *StreamWriter) Flush() error {
+	if err := sw.ctx.Err(); err != nil {
+		return err
+	}
+	if err := sw.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// ClientGone returns the channel closed when the client disconnects or the request context
+// is done otherwise, ex. on the server shutdown or the timeout.
+func (sw *StreamWriter) ClientGone() <-chan struct{} {
+	return sw.ctx.Done()
+}
+
+// Context returns the request context.
+func (sw *StreamWriter) Context() context.Context {
+	return sw.ctx
+}
+
+// Written returns the number of the bytes written.
+func (sw *StreamWriter) Written() int64 {
+	return sw.written
+}
@@ -0,0 +1,185 @@
+package wo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	mu      sync.Mutex
+	flushes int
+}
+
+func (r *flushRecorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushes++
+}
+
+func (r *flushRecorder) flushCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flushes
+}
+
+func TestCopyStream(t *testing.T) {
+	tests := []struct {
+		name            string
+		flushInterval   time.Duration
+		expectedFlushes int
+	}{
+		{"no flush", 0, 0},
+		{"flush each write", -1, 2},
+		{"periodic flush", time.Hour, 1}, // the pending data is flushed at the end
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+			r := io.MultiReader(strings.NewReader("hello "), strings.NewReader("world"))
+
+			n, err := CopyStream(context.Background(), w, r, tt.flushInterval)
+			require.NoError(t, err)
+			assert.Equal(t, int64(11), n)
+			assert.Equal(t, "hello world", w.Body.String())
+			assert.Equal(t, tt.expectedFlushes, w.flushCount())
+		})
+	}
+}
+
+func TestCopyStream_PeriodicFlush(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	pr, pw := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := CopyStream(context.Background(), w, pr, 10*time.Millisecond)
+		done <- err
+	}()
+
+	_, _ = pw.Write([]byte("token"))
+	assert.Eventually(t, func() bool { return w.flushCount() == 1 }, time.Second, 5*time.Millisecond)
+
+	_ = pw.Close()
+	require.NoError(t, <-done)
+}
+
+func TestCopyStream_ClientDisconnect(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := CopyStream(ctx, w, pr, -1)
+		done <- err
+	}()
+
+	_, _ = pw.Write([]byte("first"))
+	cancel()
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the blocked read was not aborted")
+	}
+	assert.Equal(t, "first", w.Body.String())
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("upstream error")
+}
+
+func TestCopyStream_ReadError(t *testing.T) {
+	_, err := CopyStream(context.Background(), httptest.NewRecorder(), failingReader{}, 0)
+	require.EqualError(t, err, "upstream error")
+}
+
+func TestEvent_StreamFlush(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	event := new(Event)
+	event.Reset(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	err := event.StreamFlush(http.StatusOK, "text/event-stream", strings.NewReader("data: x\n\n"), -1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "text/event-stream", w.Header().Get(HeaderContentType))
+	assert.Equal(t, "data: x\n\n", w.Body.String())
+	assert.Equal(t, 1, w.flushCount())
+}
+
+func TestEvent_StreamFunc(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	event := new(Event)
+	event.Reset(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	err := event.StreamFunc(http.StatusAccepted, "text/plain", func(sw *StreamWriter) error {
+		for _, step := range []string{"1\n", "2\n", "3\n"} {
+			if _, err := sw.WriteString(step); err != nil {
+				return err
+			}
+			if err := sw.Flush(); err != nil {
+				return err
+			}
+		}
+		assert.Equal(t, int64(6), sw.Written())
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get(HeaderContentType))
+	assert.Equal(t, "1\n2\n3\n", w.Body.String())
+	assert.Equal(t, 4, w.flushCount())
+}
+
+func TestEvent_StreamFunc_ClientGone(t *testing.T) {
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	ctx, cancel := context.WithCancel(context.Background())
+	event := new(Event)
+	event.Reset(w, httptest.NewRequestWithContext(ctx, http.MethodGet, "/", nil))
+
+	err := event.StreamFunc(http.StatusOK, "text/plain", func(sw *StreamWriter) error {
+		_, _ = sw.WriteString("progress")
+		cancel()
+
+		select {
+		case <-sw.ClientGone():
+		case <-time.After(time.Second):
+			t.Fatal("the client disconnect was not signaled")
+		}
+
+		_, err := sw.WriteString("more")
+		require.ErrorIs(t, err, context.Canceled)
+		require.ErrorIs(t, sw.Flush(), context.Canceled)
+		return err
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "progress", w.Body.String())
+}
+
+func TestEvent_StreamFunc_Error(t *testing.T) {
+	event := new(Event)
+	event.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	err := event.StreamFunc(http.StatusOK, "text/plain", func(*StreamWriter) error {
+		return errors.New("producer error")
+	})
+	require.EqualError(t, err, "producer error")
+}
@@ -0,0 +1,294 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/gowool/wo/report"
+)
+
+var (
+	ErrQueueFull     = errors.New("task: queue is full")
+	ErrRunnerStopped = errors.New("task: runner is stopped")
+)
+
+// Func is a unit of background work. The context is canceled when the job
+// timeout elapses or the runner is forced to stop.
+type Func func(ctx context.Context) error
+
+type Config struct {
+	// Workers is the number of goroutines executing queued jobs.
+	// Optional. Default value 4.
+	Workers int `env:"WORKERS" json:"workers,omitempty" yaml:"workers,omitempty"`
+
+	// QueueSize is the capacity of the job queue. Enqueue fails with ErrQueueFull
+	// when the queue is exhausted.
+	// Optional. Default value 256.
+	QueueSize int `env:"QUEUE_SIZE" json:"queueSize,omitempty" yaml:"queueSize,omitempty"`
+
+	// Timeout is the default per-job timeout. A negative value disables it.
+	// Optional. Default value 1m.
+	Timeout time.Duration `env:"TIMEOUT" json:"timeout,omitempty,format:units" yaml:"timeout,omitempty"`
+
+	// Reporter reports the failed jobs and the recovered panics, ex. to Sentry.
+	// Optional. Default value report.Nop.
+	Reporter report.ErrorReporter `json:"-" yaml:"-"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.Workers <= 0 {
+		c.Workers = 4
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 256
+	}
+	if c.Timeout == 0 {
+		c.Timeout = time.Minute
+	}
+	if c.Reporter == nil {
+		c.Reporter = report.Nop
+	}
+}
+
+type Option func(*job)
+
+// WithTimeout overrides the default job timeout. A negative value disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(j *job) {
+		j.timeout = timeout
+	}
+}
+
+type job struct {
+	name    string
+	fn      Func
+	timeout time.Duration
+}
+
+// Runner executes fire-and-forget and scheduled jobs on a fixed pool of workers.
+type Runner struct {
+	cfg       Config
+	logger    *slog.Logger
+	queue     chan job
+	ctx       context.Context
+	cancel    context.CancelFunc
+	schedules []*schedule
+	workers   sync.WaitGroup
+	timers    sync.WaitGroup
+	stop      chan struct{}
+	mu        sync.RWMutex
+	started   bool
+	stopped   bool
+}
+
+func NewRunner(cfg Config, logger *slog.Logger) *Runner {
+	if logger == nil {
+		panic("task: logger is nil")
+	}
+
+	cfg.SetDefaults()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Runner{
+		cfg:    cfg,
+		logger: logger,
+		queue:  make(chan job, cfg.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start launches the workers and the registered schedules.
+func (r *Runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started || r.stopped {
+		return
+	}
+	r.started = true
+
+	for range r.cfg.Workers {
+		r.workers.Go(r.work)
+	}
+
+	for _, s := range r.schedules {
+		r.startSchedule(s)
+	}
+}
+
+// Stop stops accepting new jobs and waits until the queued and running jobs are drained,
+// also the ones enqueued to the runner which was never started.
+// If ctx is done before that, running jobs are canceled and ctx error is returned.
+func (r *Runner) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return nil
+	}
+	r.stopped = true
+	close(r.stop)
+	if !r.started {
+		// the jobs enqueued before Start are drained too
+		for range r.cfg.Workers {
+			r.workers.Go(r.work)
+		}
+	}
+	r.mu.Unlock()
+
+	r.timers.Wait()
+	close(r.queue)
+
+	done := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		r.cancel()
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Enqueue adds a fire-and-forget job to the queue.
+func (r *Runner) Enqueue(name string, fn Func, opts ...Option) error {
+	j := job{name: name, fn: fn, timeout: r.cfg.Timeout}
+	for _, opt := range opts {
+		opt(&j)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.stopped {
+		return ErrRunnerStopped
+	}
+
+	select {
+	case r.queue <- j:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Schedule registers a job executed according to the cron-like spec,
+// see [ParseSchedule] for the supported syntax.
+func (r *Runner) Schedule(name, spec string, fn Func, opts ...Option) error {
+	next, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	s := &schedule{name: name, next: next, fn: fn, opts: opts}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return ErrRunnerStopped
+	}
+
+	r.schedules = append(r.schedules, s)
+	if r.started {
+		r.startSchedule(s)
+	}
+	return nil
+}
+
+func (r *Runner) startSchedule(s *schedule) {
+	r.timers.Go(func() {
+		for {
+			now := time.Now()
+			timer := time.NewTimer(s.next(now).Sub(now))
+
+			select {
+			case <-r.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				if err := r.Enqueue(s.name, s.fn, s.opts...); err != nil {
+					r.logger.Warn("task: skip scheduled job", slog.String("task", s.name), slog.Any("error", err))
+				}
+			}
+		}
+	})
+}
+
+func (r *Runner) work() {
+	for j := range r.queue {
+		r.run(j)
+	}
+}
+
+func (r *Runner) run(j job) {
+	ctx := r.ctx
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			stack := make([]byte, 4<<10)
+			stack = stack[:runtime.Stack(stack, false)]
+
+			r.logger.Error("task: panic recovered",
+				slog.String("task", j.name),
+				slog.String("panic", fmt.Sprint(rec)),
+				slog.String("stack", string(stack)),
+			)
+			r.cfg.Reporter.CaptureException(ctx, fmt.Errorf("task: panic: %v\n%s", rec, stack), report.Tags{
+				"source": "task",
+				"task":   j.name,
+			})
+		}
+	}()
+
+	if err := j.fn(ctx); err != nil {
+		r.logger.Error("task: job failed",
+			slog.String("task", j.name),
+			slog.Duration("latency", time.Since(start)),
+			slog.Any("error", err),
+		)
+		r.cfg.Reporter.CaptureException(ctx, err, report.Tags{
+			"source": "task",
+			"task":   j.name,
+		})
+		return
+	}
+
+	r.logger.Debug("task: job completed",
+		slog.String("task", j.name),
+		slog.Duration("latency", time.Since(start)),
+	)
+}
+
+type ctxRunnerKey struct{}
+
+// WithRunner returns a copy of ctx which carries the Runner.
+func WithRunner(ctx context.Context, r *Runner) context.Context {
+	return context.WithValue(ctx, ctxRunnerKey{}, r)
+}
+
+// FromContext returns the Runner stored in ctx, if any.
+func FromContext(ctx context.Context) (*Runner, bool) {
+	r, ok := ctx.Value(ctxRunnerKey{}).(*Runner)
+	return r, ok
+}
@@ -0,0 +1,206 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/report"
+)
+
+func newTestRunner(cfg Config) *Runner {
+	return NewRunner(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestConfig_SetDefaults(t *testing.T) {
+	cfg := Config{}
+	cfg.SetDefaults()
+
+	assert.Equal(t, 4, cfg.Workers)
+	assert.Equal(t, 256, cfg.QueueSize)
+	assert.Equal(t, time.Minute, cfg.Timeout)
+
+	cfg = Config{Workers: 1, QueueSize: 2, Timeout: -1}
+	cfg.SetDefaults()
+
+	assert.Equal(t, 1, cfg.Workers)
+	assert.Equal(t, 2, cfg.QueueSize)
+	assert.Equal(t, time.Duration(-1), cfg.Timeout)
+}
+
+func TestNewRunner_PanicsOnNilLogger(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRunner(Config{}, nil)
+	})
+}
+
+func TestRunner_EnqueueAndDrain(t *testing.T) {
+	r := newTestRunner(Config{Workers: 2})
+	r.Start()
+
+	var count atomic.Int32
+	for range 10 {
+		require.NoError(t, r.Enqueue("count", func(context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			count.Add(1)
+			return nil
+		}))
+	}
+
+	require.NoError(t, r.Stop(context.Background()))
+	assert.Equal(t, int32(10), count.Load())
+
+	assert.ErrorIs(t, r.Enqueue("late", func(context.Context) error { return nil }), ErrRunnerStopped)
+}
+
+func TestRunner_StopNotStarted(t *testing.T) {
+	r := newTestRunner(Config{Workers: 2})
+
+	var count atomic.Int32
+	for range 3 {
+		require.NoError(t, r.Enqueue("count", func(context.Context) error {
+			count.Add(1)
+			return nil
+		}))
+	}
+
+	require.NoError(t, r.Stop(context.Background()))
+	assert.Equal(t, int32(3), count.Load())
+	assert.ErrorIs(t, r.Enqueue("late", func(context.Context) error { return nil }), ErrRunnerStopped)
+}
+
+func TestRunner_EnqueueQueueFull(t *testing.T) {
+	r := newTestRunner(Config{QueueSize: 1})
+
+	require.NoError(t, r.Enqueue("a", func(context.Context) error { return nil }))
+	assert.ErrorIs(t, r.Enqueue("b", func(context.Context) error { return nil }), ErrQueueFull)
+}
+
+func TestRunner_RecoversPanicsAndErrors(t *testing.T) {
+	r := newTestRunner(Config{Workers: 1})
+	r.Start()
+
+	var done atomic.Bool
+	require.NoError(t, r.Enqueue("panic", func(context.Context) error { panic("boom") }))
+	require.NoError(t, r.Enqueue("error", func(context.Context) error { return errors.New("failed") }))
+	require.NoError(t, r.Enqueue("ok", func(context.Context) error {
+		done.Store(true)
+		return nil
+	}))
+
+	require.NoError(t, r.Stop(context.Background()))
+	assert.True(t, done.Load())
+}
+
+func TestRunner_JobTimeout(t *testing.T) {
+	r := newTestRunner(Config{Workers: 1, Timeout: 10 * time.Millisecond})
+	r.Start()
+
+	errCh := make(chan error, 2)
+	job := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			errCh <- ctx.Err()
+		case <-time.After(time.Second):
+			errCh <- nil
+		}
+		return nil
+	}
+
+	require.NoError(t, r.Enqueue("default", job))
+	require.NoError(t, r.Enqueue("override", job, WithTimeout(5*time.Millisecond)))
+	require.NoError(t, r.Stop(context.Background()))
+
+	assert.ErrorIs(t, <-errCh, context.DeadlineExceeded)
+	assert.ErrorIs(t, <-errCh, context.DeadlineExceeded)
+}
+
+func TestRunner_StopContextDone(t *testing.T) {
+	r := newTestRunner(Config{Workers: 1, Timeout: -1})
+	r.Start()
+
+	started := make(chan struct{})
+	require.NoError(t, r.Enqueue("long", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, r.Stop(ctx), context.DeadlineExceeded)
+	assert.NoError(t, r.Stop(context.Background()))
+}
+
+func TestRunner_Schedule(t *testing.T) {
+	r := newTestRunner(Config{})
+
+	assert.Error(t, r.Schedule("invalid", "* *", func(context.Context) error { return nil }))
+
+	var count atomic.Int32
+	require.NoError(t, r.Schedule("every", "@every 1s", func(context.Context) error {
+		count.Add(1)
+		return nil
+	}))
+
+	r.Start()
+	time.Sleep(1100 * time.Millisecond)
+	require.NoError(t, r.Stop(context.Background()))
+
+	assert.Equal(t, int32(1), count.Load())
+	assert.ErrorIs(t, r.Schedule("late", "@hourly", func(context.Context) error { return nil }), ErrRunnerStopped)
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+
+	r := newTestRunner(Config{})
+	actual, ok := FromContext(WithRunner(ctx, r))
+	assert.True(t, ok)
+	assert.Same(t, r, actual)
+}
+
+type testReporter struct {
+	mu   sync.Mutex
+	errs []string
+	tags []report.Tags
+}
+
+func (r *testReporter) CaptureException(_ context.Context, err error, tags report.Tags) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs = append(r.errs, err.Error())
+	r.tags = append(r.tags, tags)
+}
+
+func (r *testReporter) CaptureMessage(context.Context, string, report.Tags) {}
+
+func TestRunner_Reporter(t *testing.T) {
+	reporter := new(testReporter)
+	r := newTestRunner(Config{Workers: 1, Reporter: reporter})
+	r.Start()
+
+	require.NoError(t, r.Enqueue("panic", func(context.Context) error { panic("boom") }))
+	require.NoError(t, r.Enqueue("error", func(context.Context) error { return errors.New("failed") }))
+	require.NoError(t, r.Enqueue("ok", func(context.Context) error { return nil }))
+	require.NoError(t, r.Stop(context.Background()))
+
+	require.Len(t, reporter.errs, 2)
+	assert.Contains(t, reporter.errs[0], "task: panic: boom\n")
+	assert.Equal(t, "failed", reporter.errs[1])
+	assert.Equal(t, []report.Tags{{"source": "task", "task": "panic"}, {"source": "task", "task": "error"}}, reporter.tags)
+}
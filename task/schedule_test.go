@@ -0,0 +1,65 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	base := time.Date(2024, time.January, 15, 10, 30, 45, 0, time.UTC) // Monday
+
+	tests := []struct {
+		name     string
+		spec     string
+		expected time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"fixed minute", "5 * * * *", time.Date(2024, 1, 15, 11, 5, 0, 0, time.UTC)},
+		{"step", "*/20 * * * *", time.Date(2024, 1, 15, 10, 40, 0, 0, time.UTC)},
+		{"range with step", "10-50/15 * * * *", time.Date(2024, 1, 15, 10, 40, 0, 0, time.UTC)},
+		{"list", "0 8,12 * * *", time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{"daily", "@daily", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"hourly", "@hourly", time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"monthly", "@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"weekly on sunday", "@weekly", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"dom or dow", "0 0 1 * 3", time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"every duration", "@every 90s", base.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, next(base))
+		})
+	}
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every nope",
+		"@every 10ms",
+	}
+
+	for _, spec := range specs {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseSchedule(spec)
+			assert.Error(t, err)
+		})
+	}
+}
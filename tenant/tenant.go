@@ -0,0 +1,212 @@
+// Package tenant resolves the tenant of the multi-tenant applications and carries it in the
+// request context, so the handlers and the other middlewares use the per-tenant settings:
+//
+//	r.Use(middleware.Tenant[*wo.Event](middleware.TenantConfig{
+//		Sources: []tenant.Source{tenant.FromSubdomain("example.com"), tenant.FromHeader("X-Tenant")},
+//		Lookup:  tenants.Find,
+//	}))
+//
+//	t, _ := tenant.FromContext(e.Request().Context())
+//
+// The sources are tried in order, the first one resolving the tenant ID wins.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned by [Lookup] when the tenant doesn't exist.
+var ErrNotFound = errors.New("tenant: not found")
+
+// Tenant is the resolved tenant of the request.
+type Tenant struct {
+	// ID identifies the tenant, ex. the subdomain or the account ID.
+	ID string `json:"id" yaml:"id"`
+
+	// Name is the display name of the tenant.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Domain is the cookie domain of the tenant, see [CookieDomain].
+	Domain string `json:"domain,omitempty" yaml:"domain,omitempty"`
+
+	// Settings contains the per-tenant configuration, ex. the rate limits or the quotas,
+	// see [Setting].
+	Settings map[string]any `json:"settings,omitempty" yaml:"settings,omitempty"`
+}
+
+// Setting returns the tenant setting of the key.
+func (t *Tenant) Setting(key string) (any, bool) {
+	if t == nil {
+		return nil, false
+	}
+	v, ok := t.Settings[key]
+	return v, ok
+}
+
+type contextKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant.
+func WithTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the tenant carried by ctx.
+func FromContext(ctx context.Context) (*Tenant, bool) {
+	t, ok := ctx.Value(contextKey{}).(*Tenant)
+	return t, ok && t != nil
+}
+
+// ID returns the ID of the tenant carried by ctx, empty if there is none.
+func ID(ctx context.Context) string {
+	if t, ok := FromContext(ctx); ok {
+		return t.ID
+	}
+	return ""
+}
+
+// CookieDomain returns the cookie domain of the tenant carried by ctx, empty if there is none.
+// It's meant for session.Config.CookieDomain.
+func CookieDomain(ctx context.Context) string {
+	if t, ok := FromContext(ctx); ok {
+		return t.Domain
+	}
+	return ""
+}
+
+// Setting returns the setting of the tenant carried by ctx when it has the type V.
+func Setting[V any](ctx context.Context, key string) (V, bool) {
+	t, _ := FromContext(ctx)
+	v, ok := t.Setting(key)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	value, ok := v.(V)
+	return value, ok
+}
+
+// Int returns the integer setting of the tenant carried by ctx. Unlike [Setting] it accepts
+// all the integer and float types, and the numeric strings, since the settings decoded
+// from JSON or YAML have various types.
+func Int(ctx context.Context, key string) (int64, bool) {
+	t, _ := FromContext(ctx)
+	v, ok := t.Setting(key)
+	if !ok {
+		return 0, false
+	}
+
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case float32:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Lookup returns the tenant of the ID, or [ErrNotFound] if it doesn't exist.
+type Lookup func(ctx context.Context, id string) (*Tenant, error)
+
+// StaticLookup returns the [Lookup] of the fixed set of tenants.
+func StaticLookup(tenants ...*Tenant) Lookup {
+	m := make(map[string]*Tenant, len(tenants))
+	for _, t := range tenants {
+		m[t.ID] = t
+	}
+
+	return func(_ context.Context, id string) (*Tenant, error) {
+		if t, ok := m[id]; ok {
+			return t, nil
+		}
+		return nil, ErrNotFound
+	}
+}
+
+// Source extracts the tenant ID from the request, empty if the request doesn't carry it.
+type Source func(r *http.Request) string
+
+// FromSubdomain resolves the tenant ID from the subdomain of the base domain,
+// ex. "acme" of "acme.example.com". The base domain itself and the nested subdomains
+// ("www.acme.example.com") don't resolve the tenant.
+func FromSubdomain(baseDomain string) Source {
+	suffix := "." + strings.ToLower(strings.Trim(baseDomain, "."))
+
+	return func(r *http.Request) string {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+		sub, ok := strings.CutSuffix(host, suffix)
+		if !ok || sub == "" || strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// FromHeader resolves the tenant ID from the request header.
+func FromHeader(name string) Source {
+	return func(r *http.Request) string {
+		return strings.TrimSpace(r.Header.Get(name))
+	}
+}
+
+// FromPathPrefix resolves the tenant ID from the first segment of the request path,
+// ex. "acme" of "/acme/orders". Use [FromPathValue] when the routes declare the tenant
+// path parameter.
+func FromPathPrefix() Source {
+	return func(r *http.Request) string {
+		id, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		return id
+	}
+}
+
+// FromPathValue resolves the tenant ID from the path parameter of the matched route,
+// ex. "tenant" of "/{tenant}/orders".
+func FromPathValue(name string) Source {
+	return func(r *http.Request) string {
+		return r.PathValue(name)
+	}
+}
+
+// FromClaim resolves the tenant ID from the claim of the verified token claims, ex. the JWT
+// claims set by the authentication middleware. The string and numeric claims are supported.
+func FromClaim(claims func(r *http.Request) map[string]any, claim string) Source {
+	return func(r *http.Request) string {
+		switch v := claims(r)[claim].(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case int64:
+			return strconv.FormatInt(v, 10)
+		case int:
+			return strconv.Itoa(v)
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources(t *testing.T) {
+	claims := func(r *http.Request) map[string]any {
+		if r.Header.Get("Authorization") == "" {
+			return nil
+		}
+		return map[string]any{"tid": "acme", "org": float64(42)}
+	}
+
+	tests := []struct {
+		name     string
+		source   Source
+		target   string
+		host     string
+		headers  map[string]string
+		expected string
+	}{
+		{"subdomain", FromSubdomain("example.com"), "/", "acme.example.com", nil, "acme"},
+		{"subdomain with port", FromSubdomain(".example.com."), "/", "ACME.example.com:8080", nil, "acme"},
+		{"base domain", FromSubdomain("example.com"), "/", "example.com", nil, ""},
+		{"nested subdomain", FromSubdomain("example.com"), "/", "www.acme.example.com", nil, ""},
+		{"other domain", FromSubdomain("example.com"), "/", "acme.example.org", nil, ""},
+		{"header", FromHeader("X-Tenant"), "/", "", map[string]string{"X-Tenant": " acme "}, "acme"},
+		{"missing header", FromHeader("X-Tenant"), "/", "", nil, ""},
+		{"path prefix", FromPathPrefix(), "/acme/orders", "", nil, "acme"},
+		{"root path", FromPathPrefix(), "/", "", nil, ""},
+		{"string claim", FromClaim(claims, "tid"), "/", "", map[string]string{"Authorization": "Bearer x"}, "acme"},
+		{"numeric claim", FromClaim(claims, "org"), "/", "", map[string]string{"Authorization": "Bearer x"}, "42"},
+		{"no claims", FromClaim(claims, "tid"), "/", "", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.expected, tt.source(r))
+		})
+	}
+}
+
+func TestFromPathValue(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/acme/orders", nil)
+	r.SetPathValue("tenant", "acme")
+
+	assert.Equal(t, "acme", FromPathValue("tenant")(r))
+	assert.Empty(t, FromPathValue("other")(r))
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+	assert.Empty(t, ID(ctx))
+	assert.Empty(t, CookieDomain(ctx))
+
+	_, ok = Setting[string](ctx, "plan")
+	assert.False(t, ok)
+
+	ctx = WithTenant(ctx, &Tenant{
+		ID:     "acme",
+		Domain: "acme.example.com",
+		Settings: map[string]any{
+			"plan":      "pro",
+			"rateLimit": float64(100),
+			"quota":     "5000",
+		},
+	})
+
+	tnt, ok := FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "acme", tnt.ID)
+	assert.Equal(t, "acme", ID(ctx))
+	assert.Equal(t, "acme.example.com", CookieDomain(ctx))
+
+	plan, ok := Setting[string](ctx, "plan")
+	assert.True(t, ok)
+	assert.Equal(t, "pro", plan)
+
+	_, ok = Setting[int](ctx, "plan")
+	assert.False(t, ok)
+
+	n, ok := Int(ctx, "rateLimit")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), n)
+
+	n, ok = Int(ctx, "quota")
+	assert.True(t, ok)
+	assert.Equal(t, int64(5000), n)
+
+	_, ok = Int(ctx, "plan")
+	assert.False(t, ok)
+
+	_, ok = Int(ctx, "missing")
+	assert.False(t, ok)
+}
+
+func TestStaticLookup(t *testing.T) {
+	lookup := StaticLookup(&Tenant{ID: "acme"}, &Tenant{ID: "globex"})
+
+	tnt, err := lookup(context.Background(), "globex")
+	require.NoError(t, err)
+	assert.Equal(t, "globex", tnt.ID)
+
+	_, err = lookup(context.Background(), "initech")
+	require.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,76 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gowool/wo/session"
+)
+
+const pendingUserKey = session.InternalKeyPrefix + "twofactorUser"
+
+// ErrNoPending is returned when the session has no (or an expired) pending second factor verification.
+var ErrNoPending = errors.New("twofactor: no pending verification")
+
+// Pending keeps the "pending 2FA" state in the session: the user passed the first factor
+// (ex. the password), but is not authenticated until the second factor is verified.
+//
+//	// the password is valid
+//	_ = pending.Begin(ctx, user.ID)
+//	// the TOTP code is valid for pending.UserID(ctx)
+//	userID, err := pending.Complete(ctx)
+type Pending struct {
+	session *session.Session
+	timeout time.Duration
+}
+
+// NewPending returns the pending state of the session, which expires after the timeout
+// (5 minutes if zero).
+func NewPending(s *session.Session, timeout time.Duration) *Pending {
+	if s == nil {
+		panic("twofactor: session is nil")
+	}
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+
+	return &Pending{session: s, timeout: timeout}
+}
+
+// Begin marks the session as pending the second factor verification of the user.
+// The session token is renewed to prevent the session fixation.
+func (p *Pending) Begin(ctx context.Context, userID string) error {
+	if err := p.session.RenewToken(ctx); err != nil {
+		return err
+	}
+
+	p.session.PutWithTTL(ctx, pendingUserKey, userID, p.timeout)
+	return nil
+}
+
+// UserID returns the user pending the second factor verification.
+func (p *Pending) UserID(ctx context.Context) (string, bool) {
+	userID := p.session.GetString(ctx, pendingUserKey)
+	return userID, userID != ""
+}
+
+// Complete ends the pending state once the second factor is verified and returns the user,
+// who should be stored in the session as authenticated. The session token is renewed.
+func (p *Pending) Complete(ctx context.Context) (string, error) {
+	userID, ok := p.UserID(ctx)
+	if !ok {
+		return "", ErrNoPending
+	}
+
+	p.Cancel(ctx)
+	if err := p.session.RenewToken(ctx); err != nil {
+		return "", err
+	}
+	return userID, nil
+}
+
+// Cancel ends the pending state, ex. when the user gives up or fails too many times.
+func (p *Pending) Cancel(ctx context.Context) {
+	p.session.Remove(ctx, pendingUserKey)
+}
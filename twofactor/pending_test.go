@@ -0,0 +1,117 @@
+package twofactor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo/session"
+)
+
+type nopStore struct{}
+
+func (nopStore) Delete(context.Context, string) error { return nil }
+
+func (nopStore) Find(context.Context, string) ([]byte, bool, error) { return nil, false, nil }
+
+func (nopStore) Commit(context.Context, string, []byte, time.Time) error { return nil }
+
+func newPendingTestContext(t *testing.T, timeout time.Duration) (*Pending, *session.Session, context.Context) {
+	t.Helper()
+
+	s := session.New(session.Config{}, nopStore{})
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+
+	return NewPending(s, timeout), s, ctx
+}
+
+func TestPending(t *testing.T) {
+	p, s, ctx := newPendingTestContext(t, 0)
+
+	_, ok := p.UserID(ctx)
+	assert.False(t, ok)
+
+	require.NoError(t, p.Begin(ctx, "42"))
+	token := s.Token(ctx)
+	assert.NotEmpty(t, token)
+
+	userID, ok := p.UserID(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "42", userID)
+
+	userID, err := p.Complete(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "42", userID)
+	assert.NotEqual(t, token, s.Token(ctx))
+
+	_, err = p.Complete(ctx)
+	assert.ErrorIs(t, err, ErrNoPending)
+}
+
+func TestPending_Expired(t *testing.T) {
+	p, _, ctx := newPendingTestContext(t, time.Nanosecond)
+
+	require.NoError(t, p.Begin(ctx, "42"))
+	time.Sleep(time.Millisecond)
+
+	_, ok := p.UserID(ctx)
+	assert.False(t, ok)
+
+	_, err := p.Complete(ctx)
+	assert.ErrorIs(t, err, ErrNoPending)
+}
+
+type memoryStore map[string][]byte
+
+func (m memoryStore) Delete(_ context.Context, token string) error {
+	delete(m, token)
+	return nil
+}
+
+func (m memoryStore) Find(_ context.Context, token string) ([]byte, bool, error) {
+	b, ok := m[token]
+	return b, ok, nil
+}
+
+func (m memoryStore) Commit(_ context.Context, token string, b []byte, _ time.Time) error {
+	m[token] = b
+	return nil
+}
+
+func TestPending_JWTCodec(t *testing.T) {
+	// the JSON numbers of the JWT claims are decoded as ints
+	s := session.NewWithCodec(session.Config{}, memoryStore{}, session.NewJWTCodec())
+	p := NewPending(s, 0)
+
+	ctx, err := s.Load(context.Background(), "")
+	require.NoError(t, err)
+	require.NoError(t, p.Begin(ctx, "42"))
+
+	token, _, err := s.Commit(ctx)
+	require.NoError(t, err)
+
+	ctx, err = s.Load(context.Background(), token)
+	require.NoError(t, err)
+
+	userID, ok := p.UserID(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "42", userID)
+}
+
+func TestPending_Cancel(t *testing.T) {
+	p, _, ctx := newPendingTestContext(t, 0)
+
+	require.NoError(t, p.Begin(ctx, "42"))
+	p.Cancel(ctx)
+
+	_, ok := p.UserID(ctx)
+	assert.False(t, ok)
+}
+
+func TestNewPending_NilSession(t *testing.T) {
+	assert.Panics(t, func() { NewPending(nil, 0) })
+}
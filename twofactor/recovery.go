@@ -0,0 +1,66 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// recoveryAlphabet excludes the characters which are easy to confuse (0/O, 1/I/L).
+const recoveryAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// GenerateRecoveryCodes returns n single-use recovery codes, formatted as "XXXXX-XXXXX",
+// to be shown to the user once, and their hashes to be stored, see [UseRecoveryCode].
+func GenerateRecoveryCodes(n int) (codes, hashes []string, err error) {
+	codes = make([]string, n)
+	hashes = make([]string, n)
+
+	b := make([]byte, 10)
+	for i := range n {
+		if _, err = rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		var code strings.Builder
+		for j, c := range b {
+			if j == 5 {
+				code.WriteByte('-')
+			}
+			code.WriteByte(recoveryAlphabet[int(c)%len(recoveryAlphabet)])
+		}
+
+		codes[i] = code.String()
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode returns the hash of the recovery code to be stored. The code is
+// normalized, so the dashes, the spaces and the case don't matter.
+func HashRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// UseRecoveryCode looks the recovery code up in the stored hashes and returns the remaining
+// hashes without the used one, which must replace the stored hashes. It reports false if
+// the code doesn't match any hash.
+func UseRecoveryCode(hashes []string, code string) ([]string, bool) {
+	hash := []byte(HashRecoveryCode(code))
+
+	index := -1
+	for i, h := range hashes {
+		// compare all hashes, so the timing doesn't disclose the position
+		if subtle.ConstantTimeCompare([]byte(h), hash) == 1 && index < 0 {
+			index = i
+		}
+	}
+	if index < 0 {
+		return hashes, false
+	}
+	return slices.Delete(slices.Clone(hashes), index, index+1), true
+}
@@ -0,0 +1,39 @@
+package twofactor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	require.Len(t, hashes, 10)
+
+	for i, code := range codes {
+		assert.Regexp(t, `^[2-9A-HJKMNP-Z]{5}-[2-9A-HJKMNP-Z]{5}$`, code)
+		assert.Equal(t, HashRecoveryCode(code), hashes[i])
+		assert.NotContains(t, hashes[i], code)
+	}
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(3)
+	require.NoError(t, err)
+
+	remaining, ok := UseRecoveryCode(hashes, strings.ToLower(strings.ReplaceAll(codes[1], "-", " ")))
+	require.True(t, ok)
+	assert.Equal(t, []string{hashes[0], hashes[2]}, remaining)
+	assert.Len(t, hashes, 3, "the stored hashes are not modified")
+
+	// the used code is single-use
+	_, ok = UseRecoveryCode(remaining, codes[1])
+	assert.False(t, ok)
+
+	_, ok = UseRecoveryCode(hashes, "AAAAA-AAAAA")
+	assert.False(t, ok)
+}
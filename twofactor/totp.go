@@ -0,0 +1,193 @@
+// Package twofactor implements the time-based one-time passwords (TOTP, RFC 6238) compatible
+// with the authenticator apps, the hashed recovery codes and the "pending 2FA" session state
+// between the password and the second factor verification.
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // RFC 6238 default, supported by all authenticator apps
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSecret is returned when the secret is not a valid base32 string.
+var ErrInvalidSecret = errors.New("twofactor: invalid secret")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type Config struct {
+	// Digits is the number of the code digits, from 6 to 8.
+	//
+	// Default: 6
+	Digits int `env:"DIGITS" json:"digits,omitempty" yaml:"digits,omitempty"`
+
+	// Period is the time step of the codes, a whole number of seconds.
+	//
+	// Default: 30s
+	Period time.Duration `env:"PERIOD" json:"period,omitempty,format:units" yaml:"period,omitempty"`
+
+	// Skew is the number of the time steps before and after the current one
+	// which codes are accepted, so the clock drift of the devices is tolerated.
+	// A negative value accepts the codes of the current time step only.
+	//
+	// Default: 1
+	Skew int `env:"SKEW" json:"skew,omitempty" yaml:"skew,omitempty"`
+
+	// TimeFunc returns the current time.
+	//
+	// Default: time.Now
+	TimeFunc func() time.Time `json:"-" yaml:"-"`
+}
+
+func (c *Config) SetDefaults() {
+	if c.Digits == 0 {
+		c.Digits = 6
+	}
+	if c.Period == 0 {
+		c.Period = 30 * time.Second
+	}
+	if c.Skew == 0 {
+		c.Skew = 1
+	}
+	if c.TimeFunc == nil {
+		c.TimeFunc = time.Now
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.Digits < 6 || c.Digits > 8 {
+		return fmt.Errorf("twofactor: digits must be from 6 to 8, got %d", c.Digits)
+	}
+	if c.Period < time.Second || c.Period%time.Second != 0 {
+		return fmt.Errorf("twofactor: period must be a whole number of seconds, got %s", c.Period)
+	}
+	return nil
+}
+
+// TOTP generates and verifies the time-based one-time passwords (HMAC-SHA1).
+type TOTP struct {
+	config Config
+}
+
+func New(cfg Config) *TOTP {
+	cfg.SetDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		panic(err)
+	}
+
+	return &TOTP{config: cfg}
+}
+
+// GenerateSecret returns a new random 160-bit secret encoded in base32, as expected by
+// the authenticator apps. The secret must be stored encrypted, ex. using the server key.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// URL returns the otpauth:// key URI of the secret, usually rendered as a QR code for
+// the authenticator apps to scan.
+//
+// See: https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func (t *TOTP) URL(issuer, account, secret string) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(t.config.Digits))
+	query.Set("period", strconv.Itoa(int(t.config.Period/time.Second)))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Code returns the code of the secret at the time.
+func (t *TOTP) Code(secret string, at time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return t.code(key, t.step(at)), nil
+}
+
+// Verify reports whether the code is valid for the secret at the current time
+// within the skew window.
+func (t *TOTP) Verify(secret, code string) bool {
+	_, ok := t.VerifyStep(secret, code, -1)
+	return ok
+}
+
+// VerifyStep acts like Verify, but only accepts the codes of the time steps after
+// the last used one and returns the matched time step, which should be stored
+// (ex. with the user) to prevent the code replay. Use -1 if there is no last step.
+func (t *TOTP) VerifyStep(secret, code string, last int64) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != t.config.Digits {
+		return 0, false
+	}
+
+	current := t.step(t.config.TimeFunc())
+	skew := int64(max(t.config.Skew, 0))
+
+	for step := current - skew; step <= current+skew; step++ {
+		if step <= last {
+			continue
+		}
+		if hmac.Equal([]byte(t.code(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func (t *TOTP) step(at time.Time) int64 {
+	return at.Unix() / int64(t.config.Period/time.Second)
+}
+
+// code returns the HOTP value (RFC 4226) of the counter.
+func (t *TOTP) code(key []byte, counter int64) string {
+	mac := hmac.New(sha1.New, key)
+	_ = binary.Write(mac, binary.BigEndian, uint64(counter))
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range t.config.Digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", t.config.Digits, value%mod)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := encoding.DecodeString(strings.TrimRight(secret, "="))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
@@ -0,0 +1,123 @@
+package twofactor
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTP_Code_RFC6238(t *testing.T) {
+	totp := New(Config{Digits: 8})
+
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			code, err := totp.Code(rfcSecret, time.Unix(tt.unix, 0))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, code)
+		})
+	}
+}
+
+func TestTOTP_Verify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	totp := New(Config{TimeFunc: func() time.Time { return now }})
+
+	code := func(at time.Time) string {
+		c, err := totp.Code(rfcSecret, at)
+		require.NoError(t, err)
+		return c
+	}
+
+	assert.True(t, totp.Verify(rfcSecret, code(now)))
+	assert.True(t, totp.Verify(rfcSecret, code(now.Add(-30*time.Second))))
+	assert.True(t, totp.Verify(rfcSecret, code(now.Add(30*time.Second))))
+	assert.False(t, totp.Verify(rfcSecret, code(now.Add(-90*time.Second))))
+	assert.False(t, totp.Verify(rfcSecret, "12345"))
+	assert.False(t, totp.Verify("not base32!", code(now)))
+
+	strict := New(Config{Skew: -1, TimeFunc: func() time.Time { return now }})
+	assert.True(t, strict.Verify(rfcSecret, code(now)))
+	assert.False(t, strict.Verify(rfcSecret, code(now.Add(-30*time.Second))))
+}
+
+func TestTOTP_VerifyStep(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	totp := New(Config{TimeFunc: func() time.Time { return now }})
+
+	c, err := totp.Code(rfcSecret, now)
+	require.NoError(t, err)
+
+	step, ok := totp.VerifyStep(rfcSecret, c, -1)
+	require.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	// the used code can't be replayed
+	_, ok = totp.VerifyStep(rfcSecret, c, step)
+	assert.False(t, ok)
+}
+
+func TestTOTP_URL(t *testing.T) {
+	totp := New(Config{})
+
+	u, err := url.Parse(totp.URL("Example Co", "alice@example.com", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Example Co:alice@example.com", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "Example Co", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+	assert.Equal(t, "30", u.Query().Get("period"))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "sub-second period", config: Config{Period: time.Millisecond}},
+		{name: "negative period", config: Config{Period: -time.Second}},
+		{name: "fractional period", config: Config{Period: 1500 * time.Millisecond}},
+		{name: "few digits", config: Config{Digits: 4}},
+		{name: "many digits", config: Config{Digits: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Panics(t, func() { New(tt.config) })
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	other, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+
+	_, err = New(Config{}).Code(secret, time.Now())
+	assert.NoError(t, err)
+}
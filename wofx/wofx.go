@@ -0,0 +1,290 @@
+// Package wofx provides the [fx] module of the router, the server, the session and the common
+// middlewares of the wo framework:
+//
+//	fx.New(
+//		wofx.Module,
+//		fx.Supply(serverConfig, wofx.Pipeline{{Name: "recover"}, {Name: "compress"}}),
+//		fx.Provide(wofx.AsRoutes(func(h *Handlers) wofx.Routes {
+//			return func(r *wo.Router[*wo.Event]) {
+//				r.GET("/items", h.List)
+//			}
+//		})),
+//	).Run()
+//
+// The server is started with the application and stopped gracefully, draining the requests,
+// when the application stops.
+package wofx
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gowool/hook"
+	"go.uber.org/fx"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/kv"
+	"github.com/gowool/wo/middleware"
+	"github.com/gowool/wo/report"
+	"github.com/gowool/wo/server"
+	"github.com/gowool/wo/session"
+)
+
+const (
+	routesGroup      = `group:"wo.routes"`
+	middlewaresGroup = `group:"wo.middlewares"`
+	shutdownGroup    = `group:"wo.shutdown"`
+)
+
+// Module provides the router of [*wo.Event], its HTTP handler, the middleware registry and the
+// server bound to the application lifecycle. It requires the [server.Config].
+var Module = fx.Module("wo",
+	fx.Provide(
+		NewEventFactory,
+		NewErrorHandler,
+		NewRegistry,
+		NewRouter,
+		NewHandler,
+		NewServer,
+	),
+	fx.Invoke(RegisterServer),
+)
+
+// SessionModule provides the session manager, it requires the [session.Config] and the [session.Store].
+// The registry provided by [Module] registers the "session" middleware once the session is provided.
+// The expired sessions of the [session.CleanupStore] are deleted by the janitor running with the server,
+// configured by the optional [session.JanitorConfig].
+var SessionModule = fx.Module("wo-session",
+	fx.Provide(NewSession, NewJanitor),
+	fx.Invoke(RegisterJanitor),
+)
+
+// Routes registers the routes, ex. of a feature module.
+type Routes func(r *wo.Router[*wo.Event])
+
+// Pipeline is the middleware pipeline bound to the router, see [middleware.Registry].
+type Pipeline []middleware.PipelineEntry
+
+// ShutdownFunc is called on the server stop after the listeners are shut down, see [server.Server.RegisterOnShutdown].
+type ShutdownFunc func(ctx context.Context) error
+
+// AsRoutes annotates the constructor of [Routes] to add them to the router.
+func AsRoutes(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(routesGroup))
+}
+
+// AsMiddleware annotates the constructor of the [*hook.Handler] to bind it to the router,
+// the handlers are ordered by their priority.
+func AsMiddleware(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(middlewaresGroup))
+}
+
+// AsShutdown annotates the constructor of [ShutdownFunc] to call it on the server stop.
+func AsShutdown(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(shutdownGroup))
+}
+
+// NewEventFactory returns the factory of the pooled events.
+func NewEventFactory() wo.EventFactoryFunc[*wo.Event] {
+	return wo.PooledEventFactory[wo.Event]()
+}
+
+type ErrorHandlerParams struct {
+	fx.In
+
+	Logger   *slog.Logger                   `optional:"true"`
+	Render   func(*wo.Event, *wo.HTTPError) `optional:"true"`
+	Mapper   func(err error) *wo.HTTPError  `optional:"true"`
+	Reporter report.ErrorReporter           `optional:"true"`
+}
+
+// NewErrorHandler returns the [wo.ErrorHandler] with the optional render and mapper functions,
+// reporting the server errors to the optional reporter, see [wo.ReportErrors].
+func NewErrorHandler(p ErrorHandlerParams) wo.HTTPErrorHandler[*wo.Event] {
+	return wo.ReportErrors(wo.ErrorHandler[*wo.Event](p.Render, p.Mapper, p.Logger), p.Reporter)
+}
+
+type RegistryParams struct {
+	fx.In
+
+	Logger   *slog.Logger         `optional:"true"`
+	Session  *session.Session     `optional:"true"`
+	Reporter report.ErrorReporter `optional:"true"`
+}
+
+// NewRegistry returns the middleware registry, see [middleware.NewRegistry]. The "session"
+// middleware is registered when the session is provided, ex. by [SessionModule]. The "recover"
+// middleware reports the panics to the reporter when it's provided.
+func NewRegistry(p RegistryParams) *middleware.Registry[*wo.Event] {
+	reg := middleware.NewRegistry[*wo.Event]()
+
+	if p.Reporter != nil {
+		middleware.RegisterFunc(reg, "recover", func(cfg middleware.RecoverConfig, _ ...middleware.Skipper[*wo.Event]) func(*wo.Event) error {
+			cfg.Reporter = p.Reporter
+			return middleware.Recover[*wo.Event](cfg)
+		})
+	}
+
+	if p.Session != nil {
+		logger := p.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+
+		middleware.RegisterFunc(reg, "session", func(cfg middleware.SessionConfig, skippers ...middleware.Skipper[*wo.Event]) func(*wo.Event) error {
+			return middleware.SessionWithConfig(cfg, p.Session, logger, skippers...)
+		})
+	}
+	return reg
+}
+
+type RouterParams struct {
+	fx.In
+
+	EventFactory wo.EventFactoryFunc[*wo.Event]
+	ErrorHandler wo.HTTPErrorHandler[*wo.Event]
+	Registry     *middleware.Registry[*wo.Event]
+	Envelope     *wo.EnvelopeConfig         `optional:"true"`
+	Paths        *wo.PathConfig             `optional:"true"`
+	ACME         *wo.ACMEChallenges         `optional:"true"`
+	Pipeline     Pipeline                   `optional:"true"`
+	Middlewares  []*hook.Handler[*wo.Event] `group:"wo.middlewares"`
+	Routes       []Routes                   `group:"wo.routes"`
+}
+
+// NewRouter returns the router with the pipeline middlewares, then the middlewares provided with
+// [AsMiddleware] bound, and the routes provided with [AsRoutes] registered.
+func NewRouter(p RouterParams) (*wo.Router[*wo.Event], error) {
+	r := wo.New(p.EventFactory, p.ErrorHandler)
+
+	if p.Envelope != nil {
+		r.SetEnvelope(*p.Envelope)
+	}
+	if p.Paths != nil {
+		r.SetPathConfig(*p.Paths)
+	}
+	if p.ACME != nil {
+		r.SetACMEChallenges(p.ACME)
+	}
+
+	if err := p.Registry.Bind(r.RouterGroup, p.Pipeline); err != nil {
+		return nil, err
+	}
+
+	r.Bind(p.Middlewares...)
+
+	for _, routes := range p.Routes {
+		if routes != nil {
+			routes(r)
+		}
+	}
+	return r, nil
+}
+
+// NewHandler builds the router, see [wo.Router.Build].
+func NewHandler(r *wo.Router[*wo.Event]) (http.Handler, error) {
+	return r.Build(nil)
+}
+
+type ServerParams struct {
+	fx.In
+
+	Config  server.Config
+	Handler http.Handler
+	Logger  *slog.Logger   `optional:"true"`
+	Stop    []ShutdownFunc `group:"wo.shutdown"`
+}
+
+// NewServer returns the server of the handler, the config defaults are set and the config is validated.
+func NewServer(p ServerParams) (*server.Server, error) {
+	cfg := p.Config
+	cfg.SetDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	logger := p.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	srv := server.New(cfg, p.Handler, logger)
+	for _, fn := range p.Stop {
+		if fn != nil {
+			srv.RegisterOnShutdown(fn)
+		}
+	}
+	return srv, nil
+}
+
+// RegisterServer starts the server on the application start and stops it gracefully on the application stop,
+// the application fails to start if the listeners can't be bound.
+func RegisterServer(lc fx.Lifecycle, srv *server.Server) {
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			return srv.Start()
+		},
+		OnStop: srv.Stop,
+	})
+}
+
+type SessionParams struct {
+	fx.In
+
+	Config session.Config
+	Store  session.Store
+	Codec  session.Codec `optional:"true"`
+}
+
+// NewSession returns the session manager, see [session.New].
+func NewSession(p SessionParams) *session.Session {
+	if p.Codec != nil {
+		return session.NewWithCodec(p.Config, p.Store, p.Codec)
+	}
+	return session.New(p.Config, p.Store)
+}
+
+type JanitorParams struct {
+	fx.In
+
+	Config session.JanitorConfig `optional:"true"`
+	Store  session.Store
+	Locker *kv.Locker   `optional:"true"`
+	Logger *slog.Logger `optional:"true"`
+}
+
+// NewJanitor returns the janitor of the session store, the cleanup errors are logged. With the locker,
+// only the instance holding the "session-janitor" lock cleans up the store. It returns nil
+// if the store doesn't implement [session.CleanupStore].
+func NewJanitor(p JanitorParams) *session.Janitor {
+	store, ok := p.Store.(session.CleanupStore)
+	if !ok {
+		return nil
+	}
+
+	logger := p.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	cfg := p.Config
+	if cfg.Leader == nil && p.Locker != nil {
+		cfg.Leader = p.Locker.Leader("session-janitor")
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) {
+			logger.Error("session cleanup", "error", err)
+		}
+	}
+	return session.NewJanitor(cfg, store)
+}
+
+// RegisterJanitor starts the janitor with the server and stops it on the server stop.
+func RegisterJanitor(srv *server.Server, janitor *session.Janitor) {
+	if janitor == nil {
+		return
+	}
+	srv.RegisterOnStart(janitor.Start)
+	srv.RegisterOnShutdown(janitor.Stop)
+}
@@ -0,0 +1,151 @@
+package wofx
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gowool/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/kv"
+	"github.com/gowool/wo/middleware"
+	"github.com/gowool/wo/server"
+	"github.com/gowool/wo/session"
+)
+
+func TestModule(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	var stopped bool
+
+	app := fxtest.New(t,
+		Module,
+		fx.NopLogger,
+		fx.Supply(
+			server.Config{Address: addr},
+			Pipeline{{Name: "security", Config: middleware.NewPipelineConfig(map[string]any{"xFrameOptions": "DENY"})}},
+		),
+		fx.Provide(
+			AsRoutes(func() Routes {
+				return func(r *wo.Router[*wo.Event]) {
+					r.GET("/items", func(e *wo.Event) error {
+						return e.String(http.StatusOK, "items")
+					})
+				}
+			}),
+			AsMiddleware(func() *hook.Handler[*wo.Event] {
+				return &hook.Handler[*wo.Event]{ID: "version", Func: func(e *wo.Event) error {
+					e.Response().Header().Set("X-Version", "1")
+					return e.Next()
+				}}
+			}),
+			AsShutdown(func() ShutdownFunc {
+				return func(context.Context) error {
+					stopped = true
+					return nil
+				}
+			}),
+		),
+	)
+	app.RequireStart()
+
+	resp, err := http.Get("http://" + addr + "/items")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "items", string(body))
+	assert.Equal(t, "DENY", resp.Header.Get(wo.HeaderXFrameOptions))
+	assert.Equal(t, "1", resp.Header.Get("X-Version"))
+
+	app.RequireStop()
+	assert.True(t, stopped)
+}
+
+func TestModule_InvalidPipeline(t *testing.T) {
+	app := fx.New(
+		Module,
+		fx.NopLogger,
+		fx.Supply(
+			server.Config{Address: "127.0.0.1:0"},
+			Pipeline{{Name: "unknown"}},
+		),
+	)
+	assert.ErrorContains(t, app.Err(), `middleware: pipeline[0] "unknown": factory is not registered`)
+}
+
+func TestModule_BindError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	app := fx.New(
+		Module,
+		fx.NopLogger,
+		fx.Supply(server.Config{Address: l.Addr().String()}),
+	)
+	require.NoError(t, app.Err())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.ErrorContains(t, app.Start(ctx), "address already in use")
+}
+
+func TestNewRegistry(t *testing.T) {
+	reg := NewRegistry(RegistryParams{})
+	assert.Equal(t, middleware.NewRegistry[*wo.Event]().Names(), reg.Names())
+}
+
+func TestSessionModule_Janitor(t *testing.T) {
+	store := session.NewMemoryStore(session.MemoryStoreConfig{CleanupInterval: -1})
+
+	var janitor *session.Janitor
+	app := fxtest.New(t,
+		Module,
+		SessionModule,
+		fx.NopLogger,
+		fx.Supply(
+			server.Config{Address: "127.0.0.1:0"},
+			session.Config{},
+			session.JanitorConfig{Interval: time.Hour},
+			fx.Annotate(store, fx.As(new(session.Store))),
+		),
+		fx.Populate(&janitor),
+	)
+	app.RequireStart()
+	require.NotNil(t, janitor)
+	app.RequireStop()
+
+	assert.Nil(t, NewJanitor(JanitorParams{Store: session.NewCookieStore(session.CookieStoreConfig{}, make([]byte, 32))}))
+
+	// only the instance holding the lock cleans up the store
+	kvStore := kv.NewMemoryStore(kv.MemoryStoreConfig{CleanupInterval: -1})
+	defer kvStore.Stop()
+
+	locker := kv.NewLocker(kv.LockerConfig{}, kvStore)
+	lock, err := locker.TryAcquire(context.Background(), "session-janitor")
+	require.NoError(t, err)
+	defer func() {
+		_ = lock.Release(context.Background())
+	}()
+
+	require.NoError(t, store.Commit(context.Background(), "token", []byte("data"), time.Now().Add(-time.Second)))
+
+	janitor = NewJanitor(JanitorParams{Store: store, Locker: locker})
+	require.NoError(t, janitor.Cleanup(context.Background()))
+	assert.Equal(t, int64(1), store.Stats().Active)
+}
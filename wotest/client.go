@@ -0,0 +1,110 @@
+package wotest
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gowool/wo"
+)
+
+// Client runs the requests through the built router in memory, without the network listener.
+// The response cookies are kept and sent with the following requests, like the browser does,
+// so the session flows are tested across the requests.
+type Client struct {
+	t       testing.TB
+	handler http.Handler
+	jar     http.CookieJar
+
+	// Header is added to every request, ex. the authorization.
+	Header http.Header
+}
+
+// NewClient builds the router and returns its client, the test fails if the router can't be built.
+func NewClient[T wo.Resolver](t testing.TB, r *wo.Router[T]) *Client {
+	t.Helper()
+
+	h, err := r.Build(nil)
+	if err != nil {
+		t.Fatalf("wotest: build router: %v", err)
+	}
+	return NewHandlerClient(t, h)
+}
+
+// NewHandlerClient returns the client of the handler.
+func NewHandlerClient(t testing.TB, h http.Handler) *Client {
+	jar, _ := cookiejar.New(nil)
+
+	return &Client{t: t, handler: h, jar: jar, Header: make(http.Header)}
+}
+
+// Do runs the request and returns the assertions of its response.
+func (c *Client) Do(r *http.Request) *Response {
+	c.t.Helper()
+
+	for key, values := range c.Header {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	u := cookieURL(r)
+	for _, cookie := range c.jar.Cookies(u) {
+		r.AddCookie(cookie)
+	}
+
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, r)
+
+	if cookies := rec.Result().Cookies(); len(cookies) > 0 {
+		c.jar.SetCookies(u, cookies)
+	}
+	return Assert(c.t, rec)
+}
+
+// cookieURL returns the absolute URL of the server request, which has the path only.
+func cookieURL(r *http.Request) *url.URL {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return &url.URL{Scheme: scheme, Host: r.Host, Path: r.URL.Path}
+}
+
+// Request runs the request of the method and the target, see [NewRequest].
+func (c *Client) Request(method, target string, opts ...Option) *Response {
+	c.t.Helper()
+
+	return c.Do(NewRequest(method, target, opts...))
+}
+
+func (c *Client) Get(target string, opts ...Option) *Response {
+	c.t.Helper()
+
+	return c.Request(http.MethodGet, target, opts...)
+}
+
+func (c *Client) Post(target string, opts ...Option) *Response {
+	c.t.Helper()
+
+	return c.Request(http.MethodPost, target, opts...)
+}
+
+func (c *Client) Put(target string, opts ...Option) *Response {
+	c.t.Helper()
+
+	return c.Request(http.MethodPut, target, opts...)
+}
+
+func (c *Client) Patch(target string, opts ...Option) *Response {
+	c.t.Helper()
+
+	return c.Request(http.MethodPatch, target, opts...)
+}
+
+func (c *Client) Delete(target string, opts ...Option) *Response {
+	c.t.Helper()
+
+	return c.Request(http.MethodDelete, target, opts...)
+}
@@ -0,0 +1,213 @@
+package wotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Response asserts the recorded response, the failed assertions are reported to the test
+// and the assertions are chained:
+//
+//	wotest.Assert(t, rec).
+//		Status(http.StatusCreated).
+//		Header("Location", "/items/1").
+//		JSON(`{"data": {"name": "item"}}`)
+type Response struct {
+	t   testing.TB
+	rec *httptest.ResponseRecorder
+}
+
+// Assert returns the assertions of the recorded response.
+func Assert(t testing.TB, rec *httptest.ResponseRecorder) *Response {
+	return &Response{t: t, rec: rec}
+}
+
+// Recorder returns the recorded response.
+func (r *Response) Recorder() *httptest.ResponseRecorder {
+	return r.rec
+}
+
+// Status asserts the response status code.
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+
+	assert.Equal(r.t, code, r.rec.Code, "status of the response %q", r.rec.Body.String())
+	return r
+}
+
+// Header asserts the response header value.
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+
+	assert.Equal(r.t, value, r.rec.Result().Header.Get(key), "header %q", key)
+	return r
+}
+
+// NoHeader asserts the response header is not set.
+func (r *Response) NoHeader(key string) *Response {
+	r.t.Helper()
+
+	assert.NotContains(r.t, r.rec.Result().Header, http.CanonicalHeaderKey(key), "header %q", key)
+	return r
+}
+
+// Body asserts the response body.
+func (r *Response) Body(body string) *Response {
+	r.t.Helper()
+
+	assert.Equal(r.t, body, r.rec.Body.String())
+	return r
+}
+
+// BodyContains asserts the response body contains the substring.
+func (r *Response) BodyContains(s string) *Response {
+	r.t.Helper()
+
+	assert.Contains(r.t, r.rec.Body.String(), s)
+	return r
+}
+
+// JSON asserts the JSON response body contains the expected JSON subtree: the objects must have
+// the expected keys with the matching values, the other keys are ignored, and the arrays must
+// have the same length with the matching elements.
+func (r *Response) JSON(expected string) *Response {
+	r.t.Helper()
+
+	var want any
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		r.t.Errorf("wotest: invalid expected JSON: %v", err)
+		return r
+	}
+
+	got, ok := r.decode()
+	if !ok {
+		return r
+	}
+
+	if path, ok := matchJSON(want, got, ""); !ok {
+		r.t.Errorf("wotest: JSON mismatch at %q\nexpected: %s\nactual:   %s", path, expected, r.rec.Body.String())
+	}
+	return r
+}
+
+// JSONPath asserts the value of the dot separated path of the JSON response body, ex. "data.0.id".
+// The expected value is compared after the JSON round trip, so 1 matches the decoded 1.0.
+func (r *Response) JSONPath(path string, expected any) *Response {
+	r.t.Helper()
+
+	got, ok := r.decode()
+	if !ok {
+		return r
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch v := got.(type) {
+		case map[string]any:
+			got, ok = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			ok = err == nil && i >= 0 && i < len(v)
+			if ok {
+				got = v[i]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			r.t.Errorf("wotest: JSON path %q not found in %s", path, r.rec.Body.String())
+			return r
+		}
+	}
+
+	want, err := roundTrip(expected)
+	if err != nil {
+		r.t.Errorf("wotest: invalid expected value: %v", err)
+		return r
+	}
+
+	assert.Equal(r.t, want, got, "JSON path %q", path)
+	return r
+}
+
+// DecodeJSON decodes the JSON response body into v.
+func (r *Response) DecodeJSON(v any) *Response {
+	r.t.Helper()
+
+	if err := json.Unmarshal(r.rec.Body.Bytes(), v); err != nil {
+		r.t.Errorf("wotest: decode JSON response: %v", err)
+	}
+	return r
+}
+
+func (r *Response) decode() (any, bool) {
+	r.t.Helper()
+
+	var got any
+	if err := json.Unmarshal(r.rec.Body.Bytes(), &got); err != nil {
+		r.t.Errorf("wotest: invalid JSON response %q: %v", r.rec.Body.String(), err)
+		return nil, false
+	}
+	return got, true
+}
+
+func roundTrip(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+// matchJSON reports whether got contains the want subtree and the path of the first mismatch.
+func matchJSON(want, got any, path string) (string, bool) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return path, false
+		}
+		for key, value := range w {
+			v, ok := g[key]
+			if !ok {
+				return joinPath(path, key), false
+			}
+			if p, ok := matchJSON(value, v, joinPath(path, key)); !ok {
+				return p, false
+			}
+		}
+		return "", true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return path, false
+		}
+		for i := range w {
+			if p, ok := matchJSON(w[i], g[i], joinPath(path, strconv.Itoa(i))); !ok {
+				return p, false
+			}
+		}
+		return "", true
+	default:
+		if !reflect.DeepEqual(want, got) {
+			return path, false
+		}
+		return "", true
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return fmt.Sprintf("%s.%s", path, key)
+}
@@ -0,0 +1,128 @@
+// Package wotest provides the helpers to test the handlers and the middlewares: the event
+// builder, the response assertions and the router client running the requests in memory:
+//
+//	e, rec := wotest.NewEvent(http.MethodPost, "/items/1",
+//		wotest.WithJSON(item),
+//		wotest.WithPathValue("id", "1"),
+//	)
+//	require.NoError(t, h.Update(e))
+//	wotest.Assert(t, rec).Status(http.StatusOK).JSON(`{"data": {"id": 1}}`)
+//
+//	c := wotest.NewClient(t, router)
+//	c.Get("/items", wotest.WithHeader("Accept", "application/json")).
+//		Status(http.StatusOK).
+//		JSONPath("data.0.id", 1)
+package wotest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/session"
+)
+
+// Option modifies the test request.
+type Option func(r *http.Request) *http.Request
+
+// NewRequest returns the test request, see [httptest.NewRequest].
+func NewRequest(method, target string, opts ...Option) *http.Request {
+	r := httptest.NewRequest(method, target, nil)
+	for _, opt := range opts {
+		r = opt(r)
+	}
+	return r
+}
+
+// NewEvent returns the event of the test request and the recorder of its response.
+func NewEvent(method, target string, opts ...Option) (*wo.Event, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+
+	e := new(wo.Event)
+	e.Reset(rec, NewRequest(method, target, opts...))
+	return e, rec
+}
+
+// WithBody sets the request body and its content type, unless it's empty.
+func WithBody(body io.Reader, contentType string) Option {
+	return func(r *http.Request) *http.Request {
+		switch b := body.(type) {
+		case *bytes.Buffer:
+			r.ContentLength = int64(b.Len())
+		case *bytes.Reader:
+			r.ContentLength = int64(b.Len())
+		case *strings.Reader:
+			r.ContentLength = int64(b.Len())
+		default:
+			r.ContentLength = -1
+		}
+		r.Body = io.NopCloser(body)
+		if contentType != "" {
+			r.Header.Set(wo.HeaderContentType, contentType)
+		}
+		return r
+	}
+}
+
+// WithJSON sets the JSON encoded value as the request body, it panics if the value can't be encoded.
+func WithJSON(v any) Option {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("wotest: " + err.Error())
+	}
+	return WithBody(bytes.NewReader(data), wo.MIMEApplicationJSON)
+}
+
+// WithForm sets the URL encoded form as the request body.
+func WithForm(form url.Values) Option {
+	return WithBody(strings.NewReader(form.Encode()), wo.MIMEApplicationForm)
+}
+
+// WithHeader adds the request header.
+func WithHeader(key, value string) Option {
+	return func(r *http.Request) *http.Request {
+		r.Header.Add(key, value)
+		return r
+	}
+}
+
+// WithCookie adds the request cookie.
+func WithCookie(c *http.Cookie) Option {
+	return func(r *http.Request) *http.Request {
+		r.AddCookie(c)
+		return r
+	}
+}
+
+// WithPathValue sets the path value, like the one of the matched route pattern.
+func WithPathValue(name, value string) Option {
+	return func(r *http.Request) *http.Request {
+		r.SetPathValue(name, value)
+		return r
+	}
+}
+
+// WithContext replaces the request context with the one returned by fn.
+func WithContext(fn func(ctx context.Context) context.Context) Option {
+	return func(r *http.Request) *http.Request {
+		return r.WithContext(fn(r.Context()))
+	}
+}
+
+// WithSession loads the new session of the request context with the values, like the session
+// middleware does, so the handlers are tested without the session cookie and the store.
+func WithSession(s *session.Session, values map[string]any) Option {
+	return func(r *http.Request) *http.Request {
+		ctx := s.LoadLazy(r.Context(), "")
+		for key, value := range values {
+			s.Put(ctx, key, value)
+		}
+		return r.WithContext(ctx)
+	}
+}
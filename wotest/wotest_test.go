@@ -0,0 +1,163 @@
+package wotest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gowool/wo"
+	"github.com/gowool/wo/middleware"
+	"github.com/gowool/wo/session"
+)
+
+// recordingT records the failed assertions instead of failing the test.
+type recordingT struct {
+	testing.TB
+
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+type memoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryStore) Delete(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, token)
+	return nil
+}
+
+func (s *memoryStore) Find(_ context.Context, token string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.data[token]
+	return b, ok, nil
+}
+
+func (s *memoryStore) Commit(_ context.Context, token string, data []byte, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[token] = data
+	return nil
+}
+
+func TestNewEvent(t *testing.T) {
+	s := session.New(session.Config{}, &memoryStore{data: make(map[string][]byte)})
+
+	e, rec := NewEvent(http.MethodPut, "/items/1?draft=true",
+		WithJSON(map[string]any{"name": "item"}),
+		WithHeader("X-Request-ID", "abc"),
+		WithPathValue("id", "1"),
+		WithSession(s, map[string]any{"user": "alice"}),
+	)
+
+	var dst struct {
+		ID    int    `param:"id"`
+		Draft bool   `query:"draft"`
+		Name  string `json:"name"`
+	}
+	require.NoError(t, e.Bind(&dst))
+	assert.Equal(t, 1, dst.ID)
+	assert.True(t, dst.Draft)
+	assert.Equal(t, "item", dst.Name)
+	assert.Equal(t, "abc", e.Request().Header.Get("X-Request-ID"))
+	assert.Equal(t, "alice", s.Get(e.Request().Context(), "user"))
+
+	require.NoError(t, e.String(http.StatusAccepted, "ok"))
+	Assert(t, rec).Status(http.StatusAccepted).Body("ok")
+}
+
+func TestNewEvent_Form(t *testing.T) {
+	e, _ := NewEvent(http.MethodPost, "/", WithForm(url.Values{"name": {"item"}}))
+
+	assert.Equal(t, "item", e.Request().FormValue("name"))
+}
+
+func TestResponse_JSON(t *testing.T) {
+	_, rec := NewEvent(http.MethodGet, "/")
+	rec.Header().Set(wo.HeaderContentType, wo.MIMEApplicationJSON)
+	_, _ = rec.WriteString(`{"data": [{"id": 1, "name": "a", "tags": ["x"]}], "meta": {"total": 1}}`)
+
+	tests := []struct {
+		name     string
+		expected string
+		mismatch string
+	}{
+		{name: "subtree", expected: `{"data": [{"id": 1}]}`},
+		{name: "nested", expected: `{"meta": {"total": 1}, "data": [{"tags": ["x"]}]}`},
+		{name: "value mismatch", expected: `{"meta": {"total": 2}}`, mismatch: `"meta.total"`},
+		{name: "missing key", expected: `{"data": [{"email": "a"}]}`, mismatch: `"data.0.email"`},
+		{name: "length mismatch", expected: `{"data": []}`, mismatch: `"data"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordingT{TB: t}
+			Assert(rt, rec).JSON(tt.expected)
+
+			if tt.mismatch == "" {
+				assert.Empty(t, rt.errors)
+				return
+			}
+			require.Len(t, rt.errors, 1)
+			assert.Contains(t, rt.errors[0], "JSON mismatch at "+tt.mismatch)
+		})
+	}
+
+	Assert(t, rec).
+		Header(wo.HeaderContentType, wo.MIMEApplicationJSON).
+		NoHeader("X-Missing").
+		JSONPath("data.0.id", 1).
+		JSONPath("data.0.tags", []string{"x"}).
+		JSONPath("meta", map[string]int{"total": 1})
+
+	rt := &recordingT{TB: t}
+	Assert(rt, rec).JSONPath("data.1.id", 1)
+	assert.Equal(t, []string{`wotest: JSON path "data.1.id" not found in ` + rec.Body.String()}, rt.errors)
+}
+
+func TestClient(t *testing.T) {
+	s := session.New(session.Config{}, &memoryStore{data: make(map[string][]byte)})
+
+	router := wo.New[*wo.Event](func(w http.ResponseWriter, r *http.Request) (*wo.Event, wo.EventCleanupFunc) {
+		e := new(wo.Event)
+		e.Reset(w, r)
+		return e, nil
+	}, wo.ErrorHandler[*wo.Event](nil, nil, nil))
+	router.BindFunc(middleware.Session[*wo.Event](s, nil))
+	router.POST("/login", func(e *wo.Event) error {
+		s.Put(e.Request().Context(), "user", e.Request().FormValue("user"))
+		return e.NoContent(http.StatusNoContent)
+	})
+	router.GET("/me", func(e *wo.Event) error {
+		return e.JSON(http.StatusOK, map[string]any{
+			"user":  s.GetString(e.Request().Context(), "user"),
+			"token": e.Request().Header.Get("Authorization"),
+		})
+	})
+
+	c := NewClient(t, router)
+	c.Header.Set("Authorization", "Bearer token")
+
+	c.Post("/login", WithForm(url.Values{"user": {"alice"}})).Status(http.StatusNoContent)
+	c.Get("/me").Status(http.StatusOK).JSON(`{"user": "alice", "token": "Bearer token"}`)
+	c.Delete("/me").Status(http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,158 @@
+package wo
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type ctxXMLDecoderKey struct{}
+
+var (
+	ErrXMLDTDNotAllowed = errors.New("xml: DTD is not allowed")
+	ErrXMLTooDeep       = errors.New("xml: nesting is too deep")
+)
+
+// XMLDecoderOptions configures the XML decoding of [Event.BindBody], see [Router.SetXMLDecoderOptions].
+// The defaults are safe for the untrusted input: the DTDs are rejected, so no entities can be
+// declared or expanded (the external entities are never resolved by [encoding/xml] anyway),
+// and the size and the nesting of the documents are limited.
+type XMLDecoderOptions struct {
+	// Lenient disables the strict mode of the decoder, see [xml.Decoder.Strict]. The lenient
+	// decoder accepts the unclosed and the unknown entities like the HTML parsers do.
+	//
+	// Default: false
+	Lenient bool `env:"LENIENT" json:"lenient,omitempty" yaml:"lenient,omitempty"`
+
+	// AutoClose are the elements closed automatically by the lenient decoder, see [xml.HTMLAutoClose].
+	AutoClose []string `env:"AUTO_CLOSE" json:"autoClose,omitempty" yaml:"autoClose,omitempty"`
+
+	// Entity maps the names of the non-standard entities to their replacements, see [xml.HTMLEntity].
+	Entity map[string]string `json:"-" yaml:"-"`
+
+	// CharsetReader converts the documents of the non-UTF-8 charsets declared by the XML
+	// declaration to UTF-8, ex. by golang.org/x/net/html/charset.NewReaderLabel. Without it
+	// such documents are rejected.
+	CharsetReader func(charset string, input io.Reader) (io.Reader, error) `json:"-" yaml:"-"`
+
+	// AllowDTD allows the document type declarations, the entities they declare are not expanded.
+	//
+	// Default: false
+	AllowDTD bool `env:"ALLOW_DTD" json:"allowDTD,omitempty" yaml:"allowDTD,omitempty"`
+
+	// MaxDepth is the maximum nesting depth of the elements.
+	//
+	// Default: 100
+	MaxDepth int `env:"MAX_DEPTH" json:"maxDepth,omitempty" yaml:"maxDepth,omitempty"`
+
+	// MaxBytes is the maximum size of the document.
+	//
+	// Default: DefaultMaxBodyBytes
+	MaxBytes int64 `env:"MAX_BYTES" json:"maxBytes,omitempty" yaml:"maxBytes,omitempty"`
+}
+
+func (o *XMLDecoderOptions) SetDefaults() {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = 100
+	}
+	if o.MaxBytes <= 0 {
+		o.MaxBytes = DefaultMaxBodyBytes
+	}
+}
+
+var defaultXMLDecoderOptions = func() *XMLDecoderOptions {
+	opts := new(XMLDecoderOptions)
+	opts.SetDefaults()
+	return opts
+}()
+
+// SetXMLDecoderOptions sets the XML decoding options of [Event.BindBody] of all routes,
+// it must be called before [Router.Build].
+func (r *Router[T]) SetXMLDecoderOptions(opts XMLDecoderOptions) {
+	opts.SetDefaults()
+	r.xmlDecoder = &opts
+}
+
+// WithXMLDecoderOptions returns a copy of ctx which carries the XML decoding options of [Event.BindBody].
+func WithXMLDecoderOptions(ctx context.Context, opts XMLDecoderOptions) context.Context {
+	opts.SetDefaults()
+	return context.WithValue(ctx, ctxXMLDecoderKey{}, &opts)
+}
+
+func xmlDecoderOptions(ctx context.Context) *XMLDecoderOptions {
+	if opts, ok := ctx.Value(ctxXMLDecoderKey{}).(*XMLDecoderOptions); ok {
+		return opts
+	}
+	if rc, ok := ctx.Value(ctxRouterKey{}).(*routerContext); ok && rc.xmlDecoder != nil {
+		return rc.xmlDecoder
+	}
+	return defaultXMLDecoderOptions
+}
+
+// decodeXML decodes the XML document of r to dst per the options.
+func decodeXML(r io.Reader, dst any, opts *XMLDecoderOptions) error {
+	raw := xml.NewDecoder(io.LimitReader(r, opts.MaxBytes+1))
+	guard := &xmlGuard{raw: raw, opts: opts}
+
+	d := xml.NewTokenDecoder(guard)
+	for _, dec := range []*xml.Decoder{raw, d} {
+		dec.Strict = !opts.Lenient
+		dec.AutoClose = opts.AutoClose
+		dec.Entity = opts.Entity
+		dec.CharsetReader = opts.CharsetReader
+	}
+
+	err := d.Decode(dst)
+	if guard.err != nil {
+		return guard.err
+	}
+	if raw.InputOffset() > opts.MaxBytes {
+		return ErrStatusRequestEntityTooLarge
+	}
+	return err
+}
+
+// xmlGuard passes the raw tokens to the decoder, which translates the namespaces and checks
+// the elements nesting, and rejects the DTDs and the too deep documents.
+type xmlGuard struct {
+	raw   *xml.Decoder
+	opts  *XMLDecoderOptions
+	depth int
+	err   error
+}
+
+func (g *xmlGuard) Token() (xml.Token, error) {
+	t, err := g.raw.RawToken()
+	if err != nil {
+		return t, err
+	}
+
+	switch t := t.(type) {
+	case xml.Directive:
+		if !g.opts.AllowDTD && isDTD(t) {
+			g.err = ErrBadRequest.WithInternal(ErrXMLDTDNotAllowed).SetMessage("XML DTD is not allowed")
+			return nil, g.err
+		}
+	case xml.StartElement:
+		if g.depth++; g.depth > g.opts.MaxDepth {
+			g.err = ErrBadRequest.WithInternal(ErrXMLTooDeep).SetMessage(fmt.Sprintf("XML nesting is deeper than %d", g.opts.MaxDepth))
+			return nil, g.err
+		}
+	case xml.EndElement:
+		g.depth--
+	}
+	return t, nil
+}
+
+func isDTD(d xml.Directive) bool {
+	d = bytes.TrimSpace(d)
+	for _, prefix := range [][]byte{[]byte("DOCTYPE"), []byte("ENTITY"), []byte("ELEMENT"), []byte("ATTLIST")} {
+		if len(d) >= len(prefix) && bytes.EqualFold(d[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package wo
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type xmlNote struct {
+	XMLName xml.Name `xml:"note"`
+	To      string   `xml:"to"`
+	Body    string   `xml:"body"`
+}
+
+func TestEvent_BindBody_XMLHardening(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		opts   *XMLDecoderOptions
+		status int
+		note   xmlNote
+	}{
+		{
+			name: "plain",
+			body: `<?xml version="1.0"?><note><to>Ann</to><body>hi</body></note>`,
+			note: xmlNote{XMLName: xml.Name{Local: "note"}, To: "Ann", Body: "hi"},
+		},
+		{
+			name:   "billion laughs",
+			body:   `<?xml version="1.0"?><!DOCTYPE lolz [<!ENTITY lol "lol"><!ENTITY lol2 "&lol;&lol;">]><note><to>&lol2;</to></note>`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "external entity",
+			body:   `<?xml version="1.0"?><!DOCTYPE note [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><note><to>&xxe;</to></note>`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "allowed DTD",
+			body: `<!DOCTYPE note SYSTEM "note.dtd"><note><to>Ann</to></note>`,
+			opts: &XMLDecoderOptions{AllowDTD: true},
+			note: xmlNote{XMLName: xml.Name{Local: "note"}, To: "Ann"},
+		},
+		{
+			name:   "too deep",
+			body:   "<note>" + strings.Repeat("<a>", 10) + strings.Repeat("</a>", 10) + "</note>",
+			opts:   &XMLDecoderOptions{MaxDepth: 5},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "too large",
+			body:   `<note><to>` + strings.Repeat("x", 100) + `</to></note>`,
+			opts:   &XMLDecoderOptions{MaxBytes: 50},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "unknown entity",
+			body:   `<note><to>&nbsp;Ann</to></note>`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "lenient",
+			body: `<note><to>&nbsp;Ann</to><body>hi<br></body></note>`,
+			opts: &XMLDecoderOptions{Lenient: true, Entity: xml.HTMLEntity, AutoClose: xml.HTMLAutoClose},
+			note: xmlNote{XMLName: xml.Name{Local: "note"}, To: " Ann", Body: "hi"},
+		},
+		{
+			name:   "unsupported charset",
+			body:   `<?xml version="1.0" encoding="ISO-8859-1"?><note><to>Ann</to></note>`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "charset reader",
+			body: `<?xml version="1.0" encoding="ISO-8859-1"?><note><to>Ann</to></note>`,
+			opts: &XMLDecoderOptions{CharsetReader: func(_ string, input io.Reader) (io.Reader, error) {
+				return input, nil
+			}},
+			note: xmlNote{XMLName: xml.Name{Local: "note"}, To: "Ann"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New[*Event](eventFactory, func(e *Event, err error) {
+				e.Response().WriteHeader(AsHTTPError(err).Status)
+			})
+			if tt.opts != nil {
+				router.SetXMLDecoderOptions(*tt.opts)
+			}
+
+			var note xmlNote
+			router.POST("/notes", func(e *Event) error {
+				return e.BindBody(&note)
+			})
+			h, err := router.Build(nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/notes", strings.NewReader(tt.body))
+			req.Header.Set(HeaderContentType, MIMEApplicationXML)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if tt.status != 0 {
+				assert.Equal(t, tt.status, rec.Code)
+				return
+			}
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.note, note)
+		})
+	}
+}
+
+func TestWithXMLDecoderOptions(t *testing.T) {
+	body := `<note>` + strings.Repeat("<a>", 3) + strings.Repeat("</a>", 3) + `</note>`
+	event, _, req := newTestEventWithBody(http.MethodPost, "/", strings.NewReader(body), MIMEApplicationXML)
+	event.SetRequest(req.WithContext(WithXMLDecoderOptions(req.Context(), XMLDecoderOptions{MaxDepth: 2})))
+
+	var note xmlNote
+	err := event.BindBody(&note)
+	require.ErrorIs(t, err, ErrXMLTooDeep)
+	assert.Equal(t, "XML nesting is deeper than 2", AsHTTPError(err).Message)
+}